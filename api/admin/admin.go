@@ -0,0 +1,369 @@
+// Package admin implements runtime admin API of the node. It is served on the separate listener
+// and requires authentication by the bearer token, by the client TLS certificate (mTLS) or both
+package admin
+
+import (
+	"context"
+	"crypto/subtle"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/lunfardo314/proxima/api"
+	"github.com/lunfardo314/proxima/global"
+	"github.com/lunfardo314/proxima/ledger/base"
+	"github.com/lunfardo314/proxima/util"
+)
+
+type (
+	environment interface {
+		global.Logging
+		StopTracingTag(tag string)
+		TraceTags() []string
+		GetPeersInfo() *api.PeersInfo
+		StaticPeers() map[string]string
+		AddStaticPeer(name, addrString string) error
+		RemoveStaticPeer(id peer.ID) error
+		AddToBlacklist(id peer.ID, reason string) error
+		RemoveFromBlacklist(id peer.ID) error
+		TakeSnapshotNow() (string, error)
+		// SequencerStatus returns nil if sequencer is not configured
+		SequencerStatus() (seqID *base.ChainID, running bool)
+		StartSequencer() error
+		StopSequencer() error
+		SaveMemDAGGraph() (string, error)
+	}
+
+	Config struct {
+		// listen address, for example '127.0.0.1:8002'
+		Addr string
+		// bearer token. Empty means token is not required
+		Token string
+		// server certificate and key. If empty, plain HTTP is served
+		CertFile string
+		KeyFile  string
+		// CA to verify client certificates. If not empty, client certificate is required (mTLS)
+		ClientCAFile string
+	}
+
+	server struct {
+		*http.Server
+		environment
+		token string
+	}
+)
+
+const TraceTag = "adminServer"
+
+const shutdownTimeout = 5 * time.Second
+
+// Run serves admin API until the context is cancelled. Returns nil after graceful shutdown
+func Run(ctx context.Context, cfg *Config, env environment) error {
+	srv, err := newServer(cfg, env)
+	if err != nil {
+		return err
+	}
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+		defer cancel()
+		_ = srv.Shutdown(shutdownCtx)
+	}()
+
+	if cfg.CertFile != "" {
+		err = srv.ListenAndServeTLS(cfg.CertFile, cfg.KeyFile)
+	} else {
+		if !strings.HasPrefix(cfg.Addr, "127.0.0.1:") && !strings.HasPrefix(cfg.Addr, "localhost:") {
+			env.Log().Warnf("[admin] admin API is served on %s without TLS. Bearer token is sent in clear text", cfg.Addr)
+		}
+		err = srv.ListenAndServe()
+	}
+	if errors.Is(err, http.ErrServerClosed) {
+		return nil
+	}
+	return err
+}
+
+func newServer(cfg *Config, env environment) (*server, error) {
+	if cfg.Token == "" && cfg.ClientCAFile == "" {
+		return nil, fmt.Errorf("admin API requires bearer token or client CA (mTLS) to be configured")
+	}
+	if cfg.ClientCAFile != "" && (cfg.CertFile == "" || cfg.KeyFile == "") {
+		return nil, fmt.Errorf("admin API: mTLS requires server certificate and key")
+	}
+	srv := &server{
+		Server: &http.Server{
+			Addr:         cfg.Addr,
+			ReadTimeout:  10 * time.Second,
+			WriteTimeout: time.Minute, // snapshot may take time
+			IdleTimeout:  10 * time.Second,
+		},
+		environment: env,
+		token:       cfg.Token,
+	}
+	if cfg.ClientCAFile != "" {
+		caPEM, err := os.ReadFile(cfg.ClientCAFile)
+		if err != nil {
+			return nil, fmt.Errorf("admin API: can't read client CA: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(caPEM) {
+			return nil, fmt.Errorf("admin API: can't parse client CA from %s", cfg.ClientCAFile)
+		}
+		srv.TLSConfig = &tls.Config{
+			ClientCAs:  pool,
+			ClientAuth: tls.RequireAndVerifyClientCert,
+			MinVersion: tls.VersionTLS12,
+		}
+	}
+	mux := http.NewServeMux()
+	srv.registerHandlers(mux)
+	srv.Handler = mux
+	return srv, nil
+}
+
+func (srv *server) registerHandlers(mux *http.ServeMux) {
+	// GET request format: '/admin/v1/trace_tags'
+	// POST request format: '/admin/v1/trace_tags?enable=<comma separated tags>&disable=<comma separated tags>'
+	srv.addHandler(mux, api.PathAdminTraceTags, srv.traceTags)
+	// GET request format: '/admin/v1/peers'
+	srv.addHandler(mux, api.PathAdminPeers, srv.getPeers)
+	// POST request format: '/admin/v1/add_static_peer?name=<peer name>&maddr=<multiaddress>'
+	srv.addHandler(mux, api.PathAdminAddStaticPeer, srv.addStaticPeer)
+	// POST request format: '/admin/v1/remove_static_peer?id=<peer id>'
+	srv.addHandler(mux, api.PathAdminRemoveStaticPeer, srv.removeStaticPeer)
+	// POST request format: '/admin/v1/blacklist_peer?id=<peer id>[&reason=<reason>]'
+	srv.addHandler(mux, api.PathAdminBlacklistPeer, srv.blacklistPeer)
+	// POST request format: '/admin/v1/unblacklist_peer?id=<peer id>'
+	srv.addHandler(mux, api.PathAdminUnblacklistPeer, srv.unblacklistPeer)
+	// POST request format: '/admin/v1/snapshot'
+	srv.addHandler(mux, api.PathAdminSnapshot, srv.takeSnapshot)
+	// GET request format: '/admin/v1/sequencer'
+	// POST request format: '/admin/v1/sequencer?action=<start|stop>'
+	srv.addHandler(mux, api.PathAdminSequencer, srv.sequencer)
+	// POST request format: '/admin/v1/save_graph'
+	srv.addHandler(mux, api.PathAdminSaveGraph, srv.saveGraph)
+}
+
+func (srv *server) addHandler(mux *http.ServeMux, pattern string, handler func(http.ResponseWriter, *http.Request)) {
+	mux.HandleFunc(pattern, func(w http.ResponseWriter, r *http.Request) {
+		if !srv.authorized(r) {
+			srv.Log().Warnf("[admin] unauthorized request %s from %s", r.URL.Path, r.RemoteAddr)
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		srv.Tracef(TraceTag, "admin API request: %s %s from %s", r.Method, r.URL.String(), r.RemoteAddr)
+		api.SetHeader(w)
+		handler(w, r)
+	})
+}
+
+// authorized checks bearer token if it is configured. Client certificate, if required, is verified by TLS
+func (srv *server) authorized(r *http.Request) bool {
+	if srv.token == "" {
+		return true
+	}
+	token, found := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !found {
+		return false
+	}
+	return subtle.ConstantTimeCompare([]byte(token), []byte(srv.token)) == 1
+}
+
+func requirePost(w http.ResponseWriter, r *http.Request) bool {
+	if r.Method == http.MethodPost {
+		return true
+	}
+	w.WriteHeader(http.StatusMethodNotAllowed)
+	api.WriteErr(w, "POST method is required")
+	return false
+}
+
+func writeJSON(w http.ResponseWriter, resp any) {
+	respBin, err := json.MarshalIndent(resp, "", "  ")
+	if err != nil {
+		api.WriteErr(w, err.Error())
+		return
+	}
+	_, err = w.Write(respBin)
+	util.AssertNoError(err)
+}
+
+func splitList(s string) []string {
+	ret := make([]string, 0)
+	for _, el := range strings.Split(s, ",") {
+		if el = strings.TrimSpace(el); el != "" {
+			ret = append(ret, el)
+		}
+	}
+	return ret
+}
+
+func (srv *server) traceTags(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		if !requirePost(w, r) {
+			return
+		}
+		if enable := splitList(r.URL.Query().Get("enable")); len(enable) > 0 {
+			srv.StartTracingTags(enable...)
+			srv.Log().Infof("[admin] enabled trace tags: %v", enable)
+		}
+		if disable := splitList(r.URL.Query().Get("disable")); len(disable) > 0 {
+			for _, tag := range disable {
+				srv.StopTracingTag(tag)
+			}
+			srv.Log().Infof("[admin] disabled trace tags: %v", disable)
+		}
+	}
+	writeJSON(w, &api.AdminTraceTags{Tags: srv.TraceTags()})
+}
+
+func (srv *server) peersResponse() *api.AdminPeers {
+	return &api.AdminPeers{
+		StaticPeers: srv.StaticPeers(),
+		Blacklist:   srv.GetPeersInfo().Blacklist,
+	}
+}
+
+func (srv *server) getPeers(w http.ResponseWriter, _ *http.Request) {
+	writeJSON(w, srv.peersResponse())
+}
+
+func peerIDFromRequest(w http.ResponseWriter, r *http.Request) (peer.ID, bool) {
+	idStr := r.URL.Query().Get("id")
+	if idStr == "" {
+		api.WriteErr(w, "parameter 'id' is required")
+		return "", false
+	}
+	id, err := peer.Decode(idStr)
+	if err != nil {
+		api.WriteErr(w, fmt.Sprintf("can't decode peer id: %v", err))
+		return "", false
+	}
+	return id, true
+}
+
+func (srv *server) addStaticPeer(w http.ResponseWriter, r *http.Request) {
+	if !requirePost(w, r) {
+		return
+	}
+	name, maddr := r.URL.Query().Get("name"), r.URL.Query().Get("maddr")
+	if name == "" || maddr == "" {
+		api.WriteErr(w, "parameters 'name' and 'maddr' are required")
+		return
+	}
+	if err := srv.AddStaticPeer(name, maddr); err != nil {
+		api.WriteErr(w, err.Error())
+		return
+	}
+	writeJSON(w, srv.peersResponse())
+}
+
+func (srv *server) removeStaticPeer(w http.ResponseWriter, r *http.Request) {
+	if !requirePost(w, r) {
+		return
+	}
+	id, ok := peerIDFromRequest(w, r)
+	if !ok {
+		return
+	}
+	if err := srv.RemoveStaticPeer(id); err != nil {
+		api.WriteErr(w, err.Error())
+		return
+	}
+	writeJSON(w, srv.peersResponse())
+}
+
+func (srv *server) blacklistPeer(w http.ResponseWriter, r *http.Request) {
+	if !requirePost(w, r) {
+		return
+	}
+	id, ok := peerIDFromRequest(w, r)
+	if !ok {
+		return
+	}
+	reason := r.URL.Query().Get("reason")
+	if reason == "" {
+		reason = "blacklisted by admin"
+	}
+	if err := srv.AddToBlacklist(id, reason); err != nil {
+		api.WriteErr(w, err.Error())
+		return
+	}
+	writeJSON(w, srv.peersResponse())
+}
+
+func (srv *server) unblacklistPeer(w http.ResponseWriter, r *http.Request) {
+	if !requirePost(w, r) {
+		return
+	}
+	id, ok := peerIDFromRequest(w, r)
+	if !ok {
+		return
+	}
+	if err := srv.RemoveFromBlacklist(id); err != nil {
+		api.WriteErr(w, err.Error())
+		return
+	}
+	writeJSON(w, srv.peersResponse())
+}
+
+func (srv *server) takeSnapshot(w http.ResponseWriter, r *http.Request) {
+	if !requirePost(w, r) {
+		return
+	}
+	fname, err := srv.TakeSnapshotNow()
+	if err != nil {
+		api.WriteErr(w, err.Error())
+		return
+	}
+	writeJSON(w, &api.AdminSnapshot{File: fname})
+}
+
+func (srv *server) sequencer(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		if !requirePost(w, r) {
+			return
+		}
+		var err error
+		switch action := r.URL.Query().Get("action"); action {
+		case "start":
+			err = srv.StartSequencer()
+		case "stop":
+			err = srv.StopSequencer()
+		default:
+			err = fmt.Errorf("wrong action '%s'. Must be 'start' or 'stop'", action)
+		}
+		if err != nil {
+			api.WriteErr(w, err.Error())
+			return
+		}
+	}
+	resp := &api.AdminSequencer{}
+	seqID, running := srv.SequencerStatus()
+	if seqID != nil {
+		resp.SequencerID = seqID.StringHex()
+		resp.Running = running
+	}
+	writeJSON(w, resp)
+}
+
+func (srv *server) saveGraph(w http.ResponseWriter, r *http.Request) {
+	if !requirePost(w, r) {
+		return
+	}
+	fname, err := srv.SaveMemDAGGraph()
+	if err != nil {
+		api.WriteErr(w, err.Error())
+		return
+	}
+	writeJSON(w, &api.AdminSaveGraph{File: fname})
+}
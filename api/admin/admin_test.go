@@ -0,0 +1,175 @@
+package admin
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/lunfardo314/proxima/api"
+	"github.com/lunfardo314/proxima/global"
+	"github.com/lunfardo314/proxima/ledger/base"
+	"github.com/stretchr/testify/require"
+)
+
+type mockEnvironment struct {
+	*global.Global
+	seqID   base.ChainID
+	running bool
+}
+
+func (m *mockEnvironment) GetPeersInfo() *api.PeersInfo {
+	return &api.PeersInfo{}
+}
+
+func (m *mockEnvironment) StaticPeers() map[string]string {
+	return map[string]string{}
+}
+
+func (m *mockEnvironment) AddStaticPeer(_, _ string) error {
+	return nil
+}
+
+func (m *mockEnvironment) RemoveStaticPeer(_ peer.ID) error {
+	return nil
+}
+
+func (m *mockEnvironment) AddToBlacklist(_ peer.ID, _ string) error {
+	return nil
+}
+
+func (m *mockEnvironment) RemoveFromBlacklist(_ peer.ID) error {
+	return nil
+}
+
+func (m *mockEnvironment) TakeSnapshotNow() (string, error) {
+	return "", fmt.Errorf("not supported")
+}
+
+func (m *mockEnvironment) SequencerStatus() (*base.ChainID, bool) {
+	return &m.seqID, m.running
+}
+
+func (m *mockEnvironment) StartSequencer() error {
+	m.running = true
+	return nil
+}
+
+func (m *mockEnvironment) StopSequencer() error {
+	m.running = false
+	return nil
+}
+
+func (m *mockEnvironment) SaveMemDAGGraph() (string, error) {
+	return "", fmt.Errorf("not supported")
+}
+
+const testToken = "secret-test-token"
+
+func newTestServer(t *testing.T) (*server, *mockEnvironment) {
+	env := &mockEnvironment{Global: global.NewDefault(), seqID: base.RandomChainID(), running: true}
+	srv, err := newServer(&Config{Addr: "127.0.0.1:0", Token: testToken}, env)
+	require.NoError(t, err)
+	return srv, env
+}
+
+func request(srv *server, method, path, token string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, nil)
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	w := httptest.NewRecorder()
+	srv.Handler.ServeHTTP(w, req)
+	return w
+}
+
+func TestConfig(t *testing.T) {
+	env := &mockEnvironment{Global: global.NewDefault()}
+	_, err := newServer(&Config{Addr: "127.0.0.1:0"}, env)
+	require.Error(t, err)
+	_, err = newServer(&Config{Addr: "127.0.0.1:0", ClientCAFile: "ca.crt"}, env)
+	require.Error(t, err)
+}
+
+func TestAuth(t *testing.T) {
+	srv, _ := newTestServer(t)
+
+	w := request(srv, http.MethodGet, api.PathAdminSequencer, "")
+	require.Equal(t, http.StatusUnauthorized, w.Code)
+	require.Equal(t, "Bearer", w.Header().Get("WWW-Authenticate"))
+
+	w = request(srv, http.MethodGet, api.PathAdminSequencer, "wrong-token")
+	require.Equal(t, http.StatusUnauthorized, w.Code)
+
+	// token is accepted only as bearer
+	req := httptest.NewRequest(http.MethodGet, api.PathAdminSequencer, nil)
+	req.Header.Set("Authorization", testToken)
+	w = httptest.NewRecorder()
+	srv.Handler.ServeHTTP(w, req)
+	require.Equal(t, http.StatusUnauthorized, w.Code)
+
+	w = request(srv, http.MethodGet, api.PathAdminSequencer, testToken)
+	require.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestSequencer(t *testing.T) {
+	srv, env := newTestServer(t)
+
+	var resp api.AdminSequencer
+	w := request(srv, http.MethodGet, api.PathAdminSequencer, testToken)
+	require.Equal(t, http.StatusOK, w.Code)
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	require.Equal(t, env.seqID.StringHex(), resp.SequencerID)
+	require.True(t, resp.Running)
+
+	w = request(srv, http.MethodPost, api.PathAdminSequencer+"?action=stop", testToken)
+	require.Equal(t, http.StatusOK, w.Code)
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	require.False(t, resp.Running)
+	require.False(t, env.running)
+
+	w = request(srv, http.MethodPost, api.PathAdminSequencer+"?action=restart", testToken)
+	var errResp api.Error
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &errResp))
+	require.NotEmpty(t, errResp.Error)
+	require.False(t, env.running)
+
+	// state changing requests require POST
+	w = request(srv, http.MethodPut, api.PathAdminSnapshot, testToken)
+	require.Equal(t, http.StatusMethodNotAllowed, w.Code)
+}
+
+func TestShutdown(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	addr := l.Addr().String()
+	require.NoError(t, l.Close())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- Run(ctx, &Config{Addr: addr, Token: testToken}, &mockEnvironment{Global: global.NewDefault()})
+	}()
+	require.Eventually(t, func() bool {
+		conn, err := net.Dial("tcp", addr)
+		if err == nil {
+			_ = conn.Close()
+		}
+		return err == nil
+	}, 5*time.Second, 10*time.Millisecond)
+
+	cancel()
+	select {
+	case err = <-done:
+		require.NoError(t, err)
+	case <-time.After(2 * shutdownTimeout):
+		t.Fatalf("admin server did not stop")
+	}
+	_, err = net.Dial("tcp", addr)
+	require.Error(t, err)
+}
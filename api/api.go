@@ -17,8 +17,10 @@ const (
 	PrefixAPIV1       = "/api/v1"
 	PrefixTxAPIV1     = "/txapi/v1"
 	PrefixWebSocketV1 = "/wsapi/v1"
+	PrefixAdminV1     = "/admin/v1"
 
 	PathGetLedgerIDData                  = PrefixAPIV1 + "/get_ledger_id_data"
+	PathGetLibraryUpgrades               = PrefixAPIV1 + "/get_library_upgrades"
 	PathGetAccountOutputs                = PrefixAPIV1 + "/get_account_outputs"
 	PathGetAccountParsedOutputs          = PrefixAPIV1 + "/get_account_parsed_outputs"
 	PathGetAccountSimpleSiglockedOutputs = PrefixAPIV1 + "/get_account_simple_siglocked"
@@ -38,8 +40,24 @@ const (
 	PathGetAllChains                     = PrefixAPIV1 + "/get_all_chains"
 	// PathGetDelegationsBySequencer returns summarized delegation data in the form of DelegationsBySequencer
 	PathGetDelegationsBySequencer = PrefixAPIV1 + "/get_delegations_by_sequencer"
+	// PathGetDelegationHistory returns history of earnings of the delegation chain in the form of DelegationHistory
+	PathGetDelegationHistory = PrefixAPIV1 + "/delegation_history"
+	// PathGetTxRejections returns latest rejected transactions or rejections of the particular transaction in the form of TxRejections
+	PathGetTxRejections = PrefixAPIV1 + "/tx_rejections"
+	// PathGetAccountHistory returns transactions touching the account in the form of AccountHistory
+	PathGetAccountHistory = PrefixAPIV1 + "/account_history"
+	// PathGetSequencerStats returns counters of the sequencer running on the node in the form of SequencerStats
+	PathGetSequencerStats = PrefixAPIV1 + "/sequencer_stats"
+	// PathGetChainHistory returns transitions of the chain, walking it backwards from the tip, in the form of ChainHistory
+	PathGetChainHistory = PrefixAPIV1 + "/chain_history"
 	// PathGetDashboard returns dashboard
 	PathGetDashboard = "/dashboard"
+	// PathGetDashboardAssets returns static files of the dashboard
+	PathGetDashboardAssets = PathGetDashboard + "/"
+	// PathGetOpenAPI returns OpenAPI document of the API
+	PathGetOpenAPI = PrefixAPIV1 + "/openapi.json"
+	// PathGetAPIExplorer returns API explorer page based on the OpenAPI document
+	PathGetAPIExplorer = PrefixAPIV1 + "/explorer"
 
 	// Transaction API calls
 
@@ -53,6 +71,20 @@ const (
 
 	// WebSocket API
 	PathDAGVertexStream = PrefixWebSocketV1 + "/dag_vertex_stream"
+	// PathForkEventStream sends ForkInfo when fork is detected or resolved
+	PathForkEventStream = PrefixWebSocketV1 + "/fork_event_stream"
+
+	// Admin API calls. Served on the separate listener
+
+	PathAdminTraceTags        = PrefixAdminV1 + "/trace_tags"
+	PathAdminPeers            = PrefixAdminV1 + "/peers"
+	PathAdminAddStaticPeer    = PrefixAdminV1 + "/add_static_peer"
+	PathAdminRemoveStaticPeer = PrefixAdminV1 + "/remove_static_peer"
+	PathAdminBlacklistPeer    = PrefixAdminV1 + "/blacklist_peer"
+	PathAdminUnblacklistPeer  = PrefixAdminV1 + "/unblacklist_peer"
+	PathAdminSnapshot         = PrefixAdminV1 + "/snapshot"
+	PathAdminSequencer        = PrefixAdminV1 + "/sequencer"
+	PathAdminSaveGraph        = PrefixAdminV1 + "/save_graph"
 )
 
 type (
@@ -113,6 +145,32 @@ type (
 		LrbSlot        uint32                       `json:"lrb_slot"`
 		LedgerCoverage uint64                       `json:"ledger_coverage"`
 		PerSequencer   map[string]SequencerSyncInfo `json:"per_sequencer,omitempty"`
+		// status of the fork monitor. Nil if the monitor is disabled
+		Fork *ForkInfo `json:"fork,omitempty"`
+	}
+
+	// ForkInfo is reported in the sync info and sent to fork event stream when fork is detected or resolved
+	ForkInfo struct {
+		// true if competing branches persist for the configured number of slots
+		Detected bool `json:"detected"`
+		// true if the heaviest branch tip is not in the same lineage with the latest reliable branch of the node
+		OnMinority bool `json:"on_minority"`
+		// slot when competing branches were seen first, 0 if there are none
+		SinceSlot uint32 `json:"since_slot"`
+		// number of slots competing branches persist
+		NumSlots uint32 `json:"num_slots"`
+		// the heaviest branch tip with its ledger coverage
+		MainTip         string `json:"main_tip,omitempty"`
+		MainTipCoverage uint64 `json:"main_tip_coverage"`
+		// branch tips with significant coverage, not in the same lineage with the main tip
+		Competing []ForkBranch `json:"competing,omitempty"`
+	}
+
+	ForkBranch struct {
+		ID       string `json:"id"`
+		Coverage uint64 `json:"coverage"`
+		// 'store' if it is among the latest branches in the state store, 'tippool' if it is baseline of a sequencer tip
+		Source string `json:"source"`
 	}
 
 	SequencerSyncInfo struct {
@@ -136,6 +194,9 @@ type (
 		MultiAddresses            []string `json:"multiAddresses,omitempty"`
 		IsStatic                  bool     `json:"is_static"`
 		RespondsToPull            bool     `json:"responds_to_pull"`
+		SupportsPullBatch         bool     `json:"supports_pull_batch"`
+		SupportsAnnounce          bool     `json:"supports_announce"`
+		SupportsCompression       bool     `json:"supports_compression"`
 		IsAlive                   bool     `json:"is_alive"`
 		WhenAdded                 int64    `json:"when_added"`
 		LastHeartbeatReceived     int64    `json:"last_heartbeat_received"`
@@ -144,6 +205,66 @@ type (
 		NumIncomingHB             int      `json:"num_incoming_hb"`
 		NumIncomingPull           int      `json:"num_incoming_pull"`
 		NumIncomingTx             int      `json:"num_incoming_tx"`
+		// reputation of the peer as seen by the node
+		Reputation *PeerReputation `json:"reputation,omitempty"`
+		// traffic with the peer since it was added
+		BytesIn  uint64 `json:"bytes_in"`
+		BytesOut uint64 `json:"bytes_out"`
+		// number of incoming messages delayed or dropped because of rate limits
+		NumThrottled int `json:"num_throttled"`
+	}
+
+	// PeerReputation components of the reputation score. Score 0 is neutral, negative score is worse.
+	// Reliability is bounded from below, so unreliable peers are only less preferred. Peers are banned
+	// only when misbehaviour (invalid transactions and protocol violations) falls below the ban threshold
+	PeerReputation struct {
+		Score            float64 `json:"score"`
+		Reliability      float64 `json:"reliability"`
+		Misbehaviour     float64 `json:"misbehaviour"`
+		ClockPenalty     float64 `json:"clock_penalty"`
+		NumUsefulTx      int     `json:"num_useful_tx"`
+		NumInvalidTx     int     `json:"num_invalid_tx"`
+		NumViolations    int     `json:"num_violations"`
+		NumPullResponded int     `json:"num_pull_responded"`
+		NumPullMissed    int     `json:"num_pull_missed"`
+		// number of bans of the peer within last 24 hours
+		NumBans int `json:"num_bans"`
+	}
+
+	// AdminTraceTags returned by admin 'trace_tags'
+	AdminTraceTags struct {
+		Error
+		// currently enabled trace tags
+		Tags []string `json:"tags"`
+	}
+
+	// AdminPeers returned by admin 'peers' and calls which modify static peers or the blacklist
+	AdminPeers struct {
+		Error
+		// map: name -> multiaddress
+		StaticPeers map[string]string `json:"static_peers"`
+		// map: peerID -> reason why it is in the blacklist
+		Blacklist map[string]string `json:"blacklist"`
+	}
+
+	// AdminSnapshot returned by admin 'snapshot'
+	AdminSnapshot struct {
+		Error
+		File string `json:"file,omitempty"`
+	}
+
+	// AdminSequencer returned by admin 'sequencer'
+	AdminSequencer struct {
+		Error
+		// empty if sequencer is not configured
+		SequencerID string `json:"sequencer_id,omitempty"`
+		Running     bool   `json:"running"`
+	}
+
+	// AdminSaveGraph returned by admin 'save_graph'
+	AdminSaveGraph struct {
+		Error
+		File string `json:"file,omitempty"`
 	}
 
 	// LatestReliableBranch returned by get_latest_reliable_branch
@@ -160,6 +281,27 @@ type (
 		FoundAtDepth int    `json:"found_at_depth"`
 	}
 
+	// TxRejection is a record about transaction rejected by the node
+	TxRejection struct {
+		TxID   string `json:"txid"`
+		Reason string `json:"reason"`
+		// slot of the ledger time when the transaction was rejected
+		Slot uint32 `json:"slot"`
+		// source type of the transaction: 'API', 'peer', 'sequencer', 'pulled', 'txStore' or 'undef'
+		Source string `json:"source"`
+		// unix time in seconds when the transaction was rejected
+		Time int64 `json:"time"`
+	}
+
+	// TxRejections is returned by 'tx_rejections'
+	TxRejections struct {
+		Error
+		// total number of rejections recorded by the node, including those already overwritten in the ring
+		Total uint64 `json:"total"`
+		// the latest first
+		Rejections []TxRejection `json:"rejections"`
+	}
+
 	TxBytes struct {
 		TxBytes    string                                  `json:"tx_bytes"`
 		TxMetadata *txmetadata.TransactionMetadataJSONAble `json:"tx_metadata,omitempty"`
@@ -286,10 +428,132 @@ type (
 		LRBID      string                            `json:"lrbid"`
 		Sequencers map[string]DelegationsOnSequencer `json:"sequencers"`
 	}
+
+	DelegationTransition struct {
+		// hex-encoded ID of the produced delegation chain output
+		OutputID string `json:"output_id"`
+		Slot     uint32 `json:"slot"`
+		// amount on the produced chain output
+		Amount uint64 `json:"amount"`
+		// inflation generated on the chain by the transition
+		Inflation uint64 `json:"inflation"`
+		// part of the inflation which went to the delegation
+		Earned uint64 `json:"earned"`
+		// part of the inflation kept by the sequencer
+		Margin uint64 `json:"margin"`
+		// sequencer which made the transition. Omitted if transition was made by the owner
+		SequencerID string `json:"sequencer_id,omitempty"`
+	}
+
+	// DelegationHistory is returned by 'delegation_history'
+	DelegationHistory struct {
+		Error
+		ChainID string `json:"chain_id"`
+		// owner and target locks. Empty if the chain is not delegation-locked anymore
+		OwnerLock   string `json:"owner_lock,omitempty"`
+		TargetLock  string `json:"target_lock,omitempty"`
+		StartSlot   uint32 `json:"start_slot,omitempty"`
+		StartAmount uint64 `json:"start_amount,omitempty"`
+		TotalEarned uint64 `json:"total_earned"`
+		TotalMargin uint64 `json:"total_margin"`
+		// true if history does not reach the origin of the chain
+		Truncated bool `json:"truncated"`
+		// transitions in ascending order of time
+		Transitions []DelegationTransition `json:"transitions"`
+		// earnings summed up per slot in ascending order of slots. Slots without earnings are omitted
+		EarnedPerSlot []DelegationSlotEarnings `json:"earned_per_slot"`
+	}
+
+	DelegationSlotEarnings struct {
+		Slot   uint32 `json:"slot"`
+		Earned uint64 `json:"earned"`
+	}
+
+	AccountTx struct {
+		TxID string `json:"txid"`
+		Slot uint32 `json:"slot"`
+		// 'in', 'out' or 'self'
+		Direction string `json:"direction"`
+		// amount received by the account for 'in' and 'self'. Amount sent to counterparties, without fee, for 'out'
+		Amount uint64 `json:"amount"`
+		// locks of outputs produced for counterparties for 'out', sender address for 'in'
+		Counterparties []string `json:"counterparties"`
+		// tag-along fee paid to sequencers by the outgoing transaction
+		Fee uint64 `json:"fee"`
+		// 'final' if transaction is in the latest reliable branch, 'pending' if it is only in the latest heaviest branch
+		Status string `json:"status"`
+	}
+
+	// AccountHistory is returned by 'account_history'
+	AccountHistory struct {
+		Error
+		Account string `json:"account"`
+		LRBID   string `json:"lrbid"`
+		// the earliest slot of the scan
+		SinceSlot uint32 `json:"since_slot"`
+		// true if there were more transactions than returned
+		Truncated bool `json:"truncated"`
+		// the latest first
+		Transactions []AccountTx `json:"transactions"`
+	}
+
+	// SequencerStats is returned by 'sequencer_stats'. Counters are since the start of the node
+	SequencerStats struct {
+		Error
+		// empty if the sequencer is not configured on the node
+		SequencerID string `json:"sequencer_id,omitempty"`
+		// submitted milestones, including branches
+		Milestones uint64 `json:"milestones"`
+		Branches   uint64 `json:"branches"`
+		Targets    uint64 `json:"targets"`
+		// number of submitted proposals by the short name of the proposer strategy
+		Proposals map[string]uint64 `json:"proposals"`
+		// number of best proposals for the target by the short name of the proposer strategy
+		BestProposals map[string]uint64 `json:"best_proposals"`
+		// number of outputs in the backlog
+		BacklogSize int `json:"backlog_size"`
+		// number of own milestones kept by the sequencer
+		OwnMilestones int `json:"own_milestones"`
+	}
+
+	ChainTransition struct {
+		// hex-encoded ID of the transaction which produced the chain output
+		TxID string `json:"txid"`
+		// hex-encoded ID of the produced chain output
+		OutputID  string `json:"output_id"`
+		Slot      uint32 `json:"slot"`
+		Amount    uint64 `json:"amount"`
+		Inflation uint64 `json:"inflation"`
+		// lock of the chain output in EasyFL source form
+		Lock string `json:"lock"`
+		// milestone data of the sequencer output. Omitted if the output is not a sequencer output
+		MilestoneData *MilestoneData `json:"milestone_data,omitempty"`
+	}
+
+	// ChainHistory is returned by 'chain_history'
+	ChainHistory struct {
+		Error
+		ChainID string `json:"chain_id"`
+		LRBID   string `json:"lrbid"`
+		// true if history does not reach the origin of the chain,
+		// because of the maximum number of transitions or because older transactions are not in the tx store
+		Truncated bool `json:"truncated"`
+		// the latest first, starting from the chain output in the LRB
+		Transitions []ChainTransition `json:"transitions"`
+	}
 )
 
 const ErrGetOutputNotFound = "output not found"
 
+// values of AccountTx fields
+const (
+	AccountTxIn      = "in"
+	AccountTxOut     = "out"
+	AccountTxSelf    = "self"
+	AccountTxFinal   = "final"
+	AccountTxPending = "pending"
+)
+
 func JSONAbleFromTransaction(tx *transaction.Transaction) *TransactionJSONAble {
 	ret := &TransactionJSONAble{
 		ID:             tx.IDStringHex(),
@@ -0,0 +1,180 @@
+package client
+
+import (
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/lunfardo314/proxima/api"
+)
+
+const adminDefaultClientTimeout = time.Minute
+
+// AdminClient is a client of the node's admin API
+type AdminClient struct {
+	c      http.Client
+	prefix string
+	token  string
+}
+
+// NewAdminClient creates admin API client. tlsConfig is needed for TLS with the client certificate (mTLS), may be nil
+func NewAdminClient(serverURL, token string, tlsConfig *tls.Config, timeout ...time.Duration) *AdminClient {
+	to := adminDefaultClientTimeout
+	if len(timeout) > 0 {
+		to = timeout[0]
+	}
+	ret := &AdminClient{
+		c:      http.Client{Timeout: to},
+		prefix: serverURL,
+		token:  token,
+	}
+	if tlsConfig != nil {
+		ret.c.Transport = &http.Transport{TLSClientConfig: tlsConfig}
+	}
+	return ret
+}
+
+func (c *AdminClient) do(method, path string, params url.Values, res any) error {
+	u := c.prefix + path
+	if len(params) > 0 {
+		u += "?" + params.Encode()
+	}
+	req, err := http.NewRequest(method, u, nil)
+	if err != nil {
+		return err
+	}
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+	resp, err := c.c.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode == http.StatusUnauthorized {
+		return fmt.Errorf("from server: unauthorized")
+	}
+	if err = json.Unmarshal(body, res); err != nil {
+		return fmt.Errorf("%s: %s", resp.Status, strings.TrimSpace(string(body)))
+	}
+	return nil
+}
+
+func (c *AdminClient) GetTraceTags() ([]string, error) {
+	return c.traceTags(http.MethodGet, nil)
+}
+
+// SetTraceTags enables and disables trace tags. Returns enabled tags after the change
+func (c *AdminClient) SetTraceTags(enable, disable []string) ([]string, error) {
+	params := url.Values{}
+	if len(enable) > 0 {
+		params.Set("enable", strings.Join(enable, ","))
+	}
+	if len(disable) > 0 {
+		params.Set("disable", strings.Join(disable, ","))
+	}
+	return c.traceTags(http.MethodPost, params)
+}
+
+func (c *AdminClient) traceTags(method string, params url.Values) ([]string, error) {
+	var res api.AdminTraceTags
+	if err := c.do(method, api.PathAdminTraceTags, params, &res); err != nil {
+		return nil, err
+	}
+	if res.Error.Error != "" {
+		return nil, fmt.Errorf("from server: %s", res.Error.Error)
+	}
+	return res.Tags, nil
+}
+
+func (c *AdminClient) GetPeers() (*api.AdminPeers, error) {
+	return c.peers(http.MethodGet, api.PathAdminPeers, nil)
+}
+
+func (c *AdminClient) AddStaticPeer(name, maddr string) (*api.AdminPeers, error) {
+	return c.peers(http.MethodPost, api.PathAdminAddStaticPeer, url.Values{"name": {name}, "maddr": {maddr}})
+}
+
+func (c *AdminClient) RemoveStaticPeer(peerID string) (*api.AdminPeers, error) {
+	return c.peers(http.MethodPost, api.PathAdminRemoveStaticPeer, url.Values{"id": {peerID}})
+}
+
+func (c *AdminClient) BlacklistPeer(peerID, reason string) (*api.AdminPeers, error) {
+	params := url.Values{"id": {peerID}}
+	if reason != "" {
+		params.Set("reason", reason)
+	}
+	return c.peers(http.MethodPost, api.PathAdminBlacklistPeer, params)
+}
+
+func (c *AdminClient) UnblacklistPeer(peerID string) (*api.AdminPeers, error) {
+	return c.peers(http.MethodPost, api.PathAdminUnblacklistPeer, url.Values{"id": {peerID}})
+}
+
+func (c *AdminClient) peers(method, path string, params url.Values) (*api.AdminPeers, error) {
+	var res api.AdminPeers
+	if err := c.do(method, path, params, &res); err != nil {
+		return nil, err
+	}
+	if res.Error.Error != "" {
+		return nil, fmt.Errorf("from server: %s", res.Error.Error)
+	}
+	return &res, nil
+}
+
+// TakeSnapshot triggers snapshot on the node. Returns snapshot file name on the node
+func (c *AdminClient) TakeSnapshot() (string, error) {
+	var res api.AdminSnapshot
+	if err := c.do(http.MethodPost, api.PathAdminSnapshot, nil, &res); err != nil {
+		return "", err
+	}
+	if res.Error.Error != "" {
+		return "", fmt.Errorf("from server: %s", res.Error.Error)
+	}
+	return res.File, nil
+}
+
+func (c *AdminClient) GetSequencerStatus() (*api.AdminSequencer, error) {
+	return c.sequencer(http.MethodGet, nil)
+}
+
+func (c *AdminClient) StartSequencer() (*api.AdminSequencer, error) {
+	return c.sequencer(http.MethodPost, url.Values{"action": {"start"}})
+}
+
+func (c *AdminClient) StopSequencer() (*api.AdminSequencer, error) {
+	return c.sequencer(http.MethodPost, url.Values{"action": {"stop"}})
+}
+
+func (c *AdminClient) sequencer(method string, params url.Values) (*api.AdminSequencer, error) {
+	var res api.AdminSequencer
+	if err := c.do(method, api.PathAdminSequencer, params, &res); err != nil {
+		return nil, err
+	}
+	if res.Error.Error != "" {
+		return nil, fmt.Errorf("from server: %s", res.Error.Error)
+	}
+	return &res, nil
+}
+
+// SaveGraph saves memDAG graph on the node. Returns file name on the node
+func (c *AdminClient) SaveGraph() (string, error) {
+	var res api.AdminSaveGraph
+	if err := c.do(http.MethodPost, api.PathAdminSaveGraph, nil, &res); err != nil {
+		return "", err
+	}
+	if res.Error.Error != "" {
+		return "", fmt.Errorf("from server: %s", res.Error.Error)
+	}
+	return res.File, nil
+}
@@ -31,6 +31,7 @@ const apiDefaultClientTimeout = 7 * time.Second
 type APIClient struct {
 	c      http.Client
 	prefix string
+	apiKey string
 }
 
 // not useful, too big delays with DNS names
@@ -87,6 +88,18 @@ func NewWithGoogleDNS(serverURL string, timeout ...time.Duration) *APIClient {
 	}
 }
 
+// WithAPIKey sets API key sent with each request in the 'X-API-Key' header. Empty key means no API key
+func (c *APIClient) WithAPIKey(apiKey string) *APIClient {
+	c.apiKey = apiKey
+	return c
+}
+
+func (c *APIClient) setAPIKey(req *http.Request) {
+	if c.apiKey != "" {
+		req.Header.Set("X-API-Key", c.apiKey)
+	}
+}
+
 // GetLedgerIdentityData retrieves raw ledger identity YAML from server
 func (c *APIClient) GetLedgerIdentityData() ([]byte, error) {
 	body, err := c.getBody(api.PathGetLedgerIDData)
@@ -96,6 +109,22 @@ func (c *APIClient) GetLedgerIdentityData() ([]byte, error) {
 	return body, nil
 }
 
+// GetLibraryUpgrades retrieves schedule of ledger library upgrades committed in the state. Returns nil if there are no upgrades
+func (c *APIClient) GetLibraryUpgrades() (*ledger.LibraryUpgrades, error) {
+	body, err := c.getBody(api.PathGetLibraryUpgrades)
+	if err != nil {
+		return nil, err
+	}
+	ret, err := ledger.ParseLibraryUpgradesYAML(body)
+	if err != nil {
+		return nil, err
+	}
+	if len(ret.Upgrades) == 0 {
+		return nil, nil
+	}
+	return ret, nil
+}
+
 // getAccountOutputs fetches all outputs of the account. Optionally sorts them on the server
 func (c *APIClient) getAccountOutputs(accountable ledger.Accountable, maxOutputs int, sort ...string) ([]*ledger.OutputDataWithID, *base.TransactionID, error) {
 	if maxOutputs < 0 {
@@ -429,6 +458,7 @@ func (c *APIClient) SubmitTransaction(txBytes []byte) error {
 	if err != nil {
 		return err
 	}
+	c.setAPIKey(req)
 	resp, err := c.c.Do(req)
 	if err != nil {
 		return err
@@ -619,6 +649,105 @@ func (c *APIClient) GetDelegationsBySequencer() (map[string]api.DelegationsOnSeq
 	return res.Sequencers, &lrbid, nil
 }
 
+// GetDelegationHistory returns history of earnings of the delegation chain
+func (c *APIClient) GetDelegationHistory(chainID base.ChainID) (*api.DelegationHistory, error) {
+	body, err := c.getBody(fmt.Sprintf(api.PathGetDelegationHistory+"?chainid=%s", chainID.StringHex()))
+	if err != nil {
+		return nil, err
+	}
+
+	var res api.DelegationHistory
+	err = json.Unmarshal(body, &res)
+	if err != nil {
+		return nil, err
+	}
+	if res.Error.Error != "" {
+		return nil, fmt.Errorf("%s", res.Error.Error)
+	}
+	return &res, nil
+}
+
+// GetChainHistory returns at most maxRecords latest transitions of the chain
+func (c *APIClient) GetChainHistory(chainID base.ChainID, maxRecords int) (*api.ChainHistory, error) {
+	body, err := c.getBody(fmt.Sprintf(api.PathGetChainHistory+"?chainid=%s&max=%d", chainID.StringHex(), maxRecords))
+	if err != nil {
+		return nil, err
+	}
+
+	var res api.ChainHistory
+	err = json.Unmarshal(body, &res)
+	if err != nil {
+		return nil, err
+	}
+	if res.Error.Error != "" {
+		return nil, fmt.Errorf("%s", res.Error.Error)
+	}
+	return &res, nil
+}
+
+// GetAccountHistory returns at most maxRecords latest transactions touching the address since the slot
+func (c *APIClient) GetAccountHistory(addr ledger.AddressED25519, since base.Slot, maxRecords int) (*api.AccountHistory, error) {
+	body, err := c.getBody(fmt.Sprintf(api.PathGetAccountHistory+"?addr=%s&since=%d&max=%d", addr.Source(), since, maxRecords))
+	if err != nil {
+		return nil, err
+	}
+
+	var res api.AccountHistory
+	err = json.Unmarshal(body, &res)
+	if err != nil {
+		return nil, err
+	}
+	if res.Error.Error != "" {
+		return nil, fmt.Errorf("%s", res.Error.Error)
+	}
+	return &res, nil
+}
+
+// GetSequencerStats returns counters of the sequencer running on the node
+func (c *APIClient) GetSequencerStats() (*api.SequencerStats, error) {
+	body, err := c.getBody(api.PathGetSequencerStats)
+	if err != nil {
+		return nil, err
+	}
+
+	var res api.SequencerStats
+	err = json.Unmarshal(body, &res)
+	if err != nil {
+		return nil, err
+	}
+	if res.Error.Error != "" {
+		return nil, fmt.Errorf("%s", res.Error.Error)
+	}
+	return &res, nil
+}
+
+// GetTxRejections returns rejections of the transaction recorded by the node, the latest first
+func (c *APIClient) GetTxRejections(txid base.TransactionID) (*api.TxRejections, error) {
+	return c.getTxRejections(api.PathGetTxRejections + "?txid=" + txid.StringHex())
+}
+
+// GetLatestTxRejections returns at most maxRecords latest rejections recorded by the node
+func (c *APIClient) GetLatestTxRejections(maxRecords int) (*api.TxRejections, error) {
+	return c.getTxRejections(fmt.Sprintf(api.PathGetTxRejections+"?max=%d", maxRecords))
+}
+
+func (c *APIClient) getTxRejections(path string) (*api.TxRejections, error) {
+	body, err := c.getBody(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var res api.TxRejections
+	err = json.Unmarshal(body, &res)
+	if err != nil {
+		return nil, err
+	}
+	if res.Error.Error != "" {
+		return nil, fmt.Errorf("%s", res.Error.Error)
+	}
+	return &res, nil
+}
+
 // GetTransferableOutputs returns reasonable maximum number of outputs owned by accountable with only 2 constraints and returns total
 func (c *APIClient) GetTransferableOutputs(account ledger.Accountable, maxOutputs ...int) ([]*ledger.OutputWithID, *base.TransactionID, uint64, error) {
 	maxO := 256
@@ -726,8 +855,12 @@ func (c *APIClient) Get(path string) ([]byte, error) {
 }
 
 func (c *APIClient) getBody(path string) ([]byte, error) {
-	url := c.prefix + path
-	resp, err := c.c.Get(url)
+	req, err := http.NewRequest(http.MethodGet, c.prefix+path, nil)
+	if err != nil {
+		return nil, err
+	}
+	c.setAPIKey(req)
+	resp, err := c.c.Do(req)
 	if err != nil {
 		return nil, fmt.Errorf("GET returned: %v", err)
 	}
@@ -0,0 +1,61 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/websocket"
+	"github.com/lunfardo314/proxima/api"
+)
+
+// StreamDAGVertices connects to the DAG vertex stream of the node and calls fun for each new vertex
+// until context is cancelled, connection is closed or fun returns false.
+// Vertex deletion messages are skipped
+func (c *APIClient) StreamDAGVertices(ctx context.Context, fun func(v *api.VertexWithDependencies) bool) error {
+	var url string
+	switch {
+	case strings.HasPrefix(c.prefix, "https://"):
+		url = "wss://" + strings.TrimPrefix(c.prefix, "https://")
+	case strings.HasPrefix(c.prefix, "http://"):
+		url = "ws://" + strings.TrimPrefix(c.prefix, "http://")
+	default:
+		return fmt.Errorf("StreamDAGVertices: unsupported scheme of the endpoint '%s'", c.prefix)
+	}
+	header := http.Header{}
+	if c.apiKey != "" {
+		header.Set("X-API-Key", c.apiKey)
+	}
+	conn, _, err := websocket.DefaultDialer.DialContext(ctx, url+api.PathDAGVertexStream, header)
+	if err != nil {
+		return fmt.Errorf("StreamDAGVertices: %w", err)
+	}
+	go func() {
+		<-ctx.Done()
+		_ = conn.Close()
+	}()
+	defer func() { _ = conn.Close() }()
+
+	for {
+		_, msg, err := conn.ReadMessage()
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil
+			}
+			return fmt.Errorf("StreamDAGVertices: %w", err)
+		}
+		var v api.VertexWithDependencies
+		if err = json.Unmarshal(msg, &v); err != nil {
+			return fmt.Errorf("StreamDAGVertices: %w", err)
+		}
+		// only deletion message has no inputs
+		if len(v.Inputs) == 0 {
+			continue
+		}
+		if !fun(&v) {
+			return nil
+		}
+	}
+}
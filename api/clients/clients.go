@@ -0,0 +1,16 @@
+// Package clients contains API clients of the Proxima node generated from its OpenAPI document:
+// Python client in python/proxima_api.py and TypeScript client in typescript/proxima_api.ts.
+// Clients must be regenerated each time the API changes, otherwise tests fail
+package clients
+
+//go:generate go run ./generate
+
+import (
+	"github.com/lunfardo314/proxima/api/server"
+)
+
+// Files are generated client files by language, relative to the package directory
+var Files = map[string]string{
+	server.APIClientPython:     "python/proxima_api.py",
+	server.APIClientTypeScript: "typescript/proxima_api.ts",
+}
@@ -0,0 +1,21 @@
+package clients
+
+import (
+	"os"
+	"testing"
+
+	"github.com/lunfardo314/proxima/api/server"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClientsUpToDate(t *testing.T) {
+	for lang, fname := range Files {
+		src, err := server.GenerateAPIClient(lang)
+		require.NoError(t, err)
+		committed, err := os.ReadFile(fname)
+		require.NoError(t, err)
+		require.Equal(t, string(src), string(committed), "%s client is outdated. Run 'go generate ./api/clients'", lang)
+	}
+	_, err := server.GenerateAPIClient("cobol")
+	require.Error(t, err)
+}
@@ -0,0 +1,23 @@
+// Command generate writes API clients generated from the OpenAPI document of the node.
+// Run with 'go generate ./api/clients'
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/lunfardo314/proxima/api/clients"
+	"github.com/lunfardo314/proxima/api/server"
+	"github.com/lunfardo314/proxima/util"
+)
+
+func main() {
+	for lang, fname := range clients.Files {
+		src, err := server.GenerateAPIClient(lang)
+		util.AssertNoError(err)
+		util.AssertNoError(os.MkdirAll(filepath.Dir(fname), 0755))
+		util.AssertNoError(os.WriteFile(fname, src, 0644))
+		fmt.Printf("%s client has been written to %s\n", lang, fname)
+	}
+}
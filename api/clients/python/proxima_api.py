@@ -0,0 +1,645 @@
+# Code generated from the OpenAPI document of the Proxima node. DO NOT EDIT.
+# Regenerate with 'go generate ./api/clients'
+"""Client of the Proxima node API. Requires only the Python standard library"""
+
+import json
+import urllib.error
+import urllib.parse
+import urllib.request
+from typing import Any, Dict, List, Optional, TypedDict, Union
+
+
+class ProximaAPIError(Exception):
+    """Error returned by the node: HTTP status other than 200 or non-empty 'error' field of the response"""
+
+    def __init__(self, message: str, status: int = 200):
+        super().__init__(message)
+        self.status = status
+
+
+# Schemas of responses. All fields are optional because the node omits empty ones
+
+AccountHistory = TypedDict("AccountHistory", {
+    "account": "str",
+    "error": "str",
+    "lrbid": "str",
+    "since_slot": "int",
+    "transactions": "List[AccountTx]",
+    "truncated": "bool",
+}, total=False)
+
+AccountTx = TypedDict("AccountTx", {
+    "amount": "int",
+    "counterparties": "List[str]",
+    "direction": "str",
+    "fee": "int",
+    "slot": "int",
+    "status": "str",
+    "txid": "str",
+}, total=False)
+
+Balance = TypedDict("Balance", {
+    "amount": "int",
+    "error": "str",
+    "lrbid": "str",
+}, total=False)
+
+BranchData = TypedDict("BranchData", {
+    "data": "BranchDataJSONAble",
+    "id": "str",
+}, total=False)
+
+BranchDataJSONAble = TypedDict("BranchDataJSONAble", {
+    "branch_inflation": "int",
+    "on_chain_amount": "int",
+    "root": "RootRecordJSONAble",
+    "sequencer_output_index": "int",
+    "stem_output_index": "int",
+}, total=False)
+
+Bytecode = TypedDict("Bytecode", {
+    "bytecode": "str",
+}, total=False)
+
+ChainHistory = TypedDict("ChainHistory", {
+    "chain_id": "str",
+    "error": "str",
+    "lrbid": "str",
+    "transitions": "List[ChainTransition]",
+    "truncated": "bool",
+}, total=False)
+
+ChainOutput = TypedDict("ChainOutput", {
+    "data": "str",
+    "error": "str",
+    "id": "str",
+    "lrbid": "str",
+}, total=False)
+
+ChainTransition = TypedDict("ChainTransition", {
+    "amount": "int",
+    "inflation": "int",
+    "lock": "str",
+    "milestone_data": "MilestoneData",
+    "output_id": "str",
+    "slot": "int",
+    "txid": "str",
+}, total=False)
+
+ChainedOutputs = TypedDict("ChainedOutputs", {
+    "error": "str",
+    "lrbid": "str",
+    "outputs": "Dict[str, str]",
+}, total=False)
+
+Chains = TypedDict("Chains", {
+    "chains": "Dict[str, OutputDataWithID]",
+    "error": "str",
+    "lrbid": "str",
+}, total=False)
+
+CheckTxIDInLRB = TypedDict("CheckTxIDInLRB", {
+    "error": "str",
+    "found_at_depth": "int",
+    "lrbid": "str",
+    "txid": "str",
+}, total=False)
+
+DelegationData = TypedDict("DelegationData", {
+    "amount": "int",
+    "since_slot": "int",
+    "start_amount": "int",
+}, total=False)
+
+DelegationHistory = TypedDict("DelegationHistory", {
+    "chain_id": "str",
+    "earned_per_slot": "List[DelegationSlotEarnings]",
+    "error": "str",
+    "owner_lock": "str",
+    "start_amount": "int",
+    "start_slot": "int",
+    "target_lock": "str",
+    "total_earned": "int",
+    "total_margin": "int",
+    "transitions": "List[DelegationTransition]",
+    "truncated": "bool",
+}, total=False)
+
+DelegationSlotEarnings = TypedDict("DelegationSlotEarnings", {
+    "earned": "int",
+    "slot": "int",
+}, total=False)
+
+DelegationTransition = TypedDict("DelegationTransition", {
+    "amount": "int",
+    "earned": "int",
+    "inflation": "int",
+    "margin": "int",
+    "output_id": "str",
+    "sequencer_id": "str",
+    "slot": "int",
+}, total=False)
+
+DelegationsBySequencer = TypedDict("DelegationsBySequencer", {
+    "error": "str",
+    "lrbid": "str",
+    "sequencers": "Dict[str, DelegationsOnSequencer]",
+}, total=False)
+
+DelegationsOnSequencer = TypedDict("DelegationsOnSequencer", {
+    "balance": "int",
+    "delegations": "Dict[str, DelegationData]",
+    "seq_name": "str",
+    "seq_output_id": "str",
+}, total=False)
+
+Error = TypedDict("Error", {
+    "error": "str",
+}, total=False)
+
+ForkBranch = TypedDict("ForkBranch", {
+    "coverage": "int",
+    "id": "str",
+    "source": "str",
+}, total=False)
+
+ForkInfo = TypedDict("ForkInfo", {
+    "competing": "List[ForkBranch]",
+    "detected": "bool",
+    "main_tip": "str",
+    "main_tip_coverage": "int",
+    "num_slots": "int",
+    "on_minority": "bool",
+    "since_slot": "int",
+}, total=False)
+
+Input = TypedDict("Input", {
+    "output_id": "str",
+    "unlock_data": "str",
+}, total=False)
+
+KnownLatestMilestones = TypedDict("KnownLatestMilestones", {
+    "error": "str",
+    "sequencers": "Dict[str, LatestSequencerTipDataJSONAble]",
+}, total=False)
+
+LatestReliableBranch = TypedDict("LatestReliableBranch", {
+    "branch_id": "str",
+    "error": "str",
+    "root_record": "RootRecordJSONAble",
+}, total=False)
+
+LatestSequencerTipDataJSONAble = TypedDict("LatestSequencerTipDataJSONAble", {
+    "last_activity_unix_nano": "int",
+    "last_branch_txid": "str",
+    "latest_milestone_txid": "str",
+    "milestone_count": "int",
+}, total=False)
+
+MainChain = TypedDict("MainChain", {
+    "branches": "List[BranchData]",
+    "error": "str",
+}, total=False)
+
+MilestoneData = TypedDict("MilestoneData", {
+    "branch_height": "int",
+    "chain_height": "int",
+    "minimum_fee": "int",
+    "name": "str",
+}, total=False)
+
+NodeInfo = TypedDict("NodeInfo", {
+    "clock_correction_ns": "int",
+    "clock_offset_ns": "int",
+    "clock_skew_too_big": "bool",
+    "commit_hash": "str",
+    "commit_time": "str",
+    "id": "str",
+    "num_dynamic_alive": "int",
+    "num_static_peers": "int",
+    "sequencers": "str",
+    "version": "str",
+}, total=False)
+
+OutputData = TypedDict("OutputData", {
+    "error": "str",
+    "lrbid": "str",
+    "output_data": "str",
+}, total=False)
+
+OutputDataWithID = TypedDict("OutputDataWithID", {
+    "data": "str",
+    "id": "str",
+}, total=False)
+
+OutputList = TypedDict("OutputList", {
+    "error": "str",
+    "lrbid": "str",
+    "outputs": "Dict[str, str]",
+}, total=False)
+
+ParsedOutput = TypedDict("ParsedOutput", {
+    "amount": "int",
+    "chain_id": "str",
+    "constraints": "List[str]",
+    "data": "str",
+    "lock_name": "str",
+}, total=False)
+
+ParsedOutputList = TypedDict("ParsedOutputList", {
+    "error": "str",
+    "lrbid": "str",
+    "outputs": "Dict[str, ParsedOutput]",
+}, total=False)
+
+PeerInfo = TypedDict("PeerInfo", {
+    "bytes_in": "int",
+    "bytes_out": "int",
+    "clock_differences_quartiles": "List[int]",
+    "hb_differences_quartiles": "List[int]",
+    "id": "str",
+    "is_alive": "bool",
+    "is_static": "bool",
+    "last_heartbeat_received": "int",
+    "multiAddresses": "List[str]",
+    "num_incoming_hb": "int",
+    "num_incoming_pull": "int",
+    "num_incoming_tx": "int",
+    "num_throttled": "int",
+    "reputation": "PeerReputation",
+    "responds_to_pull": "bool",
+    "supports_announce": "bool",
+    "supports_compression": "bool",
+    "supports_pull_batch": "bool",
+    "when_added": "int",
+}, total=False)
+
+PeerReputation = TypedDict("PeerReputation", {
+    "clock_penalty": "float",
+    "misbehaviour": "float",
+    "num_bans": "int",
+    "num_invalid_tx": "int",
+    "num_pull_missed": "int",
+    "num_pull_responded": "int",
+    "num_useful_tx": "int",
+    "num_violations": "int",
+    "reliability": "float",
+    "score": "float",
+}, total=False)
+
+PeersInfo = TypedDict("PeersInfo", {
+    "blacklist": "Dict[str, str]",
+    "error": "str",
+    "host_id": "str",
+    "peers": "List[PeerInfo]",
+}, total=False)
+
+RootRecordJSONAble = TypedDict("RootRecordJSONAble", {
+    "coverage_delta": "int",
+    "root": "str",
+    "sequencer_id": "str",
+    "slot_inflation": "int",
+    "supply": "int",
+}, total=False)
+
+ScriptSource = TypedDict("ScriptSource", {
+    "source": "str",
+}, total=False)
+
+SequencerStats = TypedDict("SequencerStats", {
+    "backlog_size": "int",
+    "best_proposals": "Dict[str, int]",
+    "branches": "int",
+    "error": "str",
+    "milestones": "int",
+    "own_milestones": "int",
+    "proposals": "Dict[str, int]",
+    "sequencer_id": "str",
+    "targets": "int",
+}, total=False)
+
+SequencerSyncInfo = TypedDict("SequencerSyncInfo", {
+    "latest_committed_slot": "int",
+    "latest_healthy_slot": "int",
+    "ledger_coverage": "int",
+    "synced": "bool",
+}, total=False)
+
+SequencerTxData = TypedDict("SequencerTxData", {
+    "milestone_data": "MilestoneData",
+    "sequencer_id": "str",
+    "sequencer_output_index": "int",
+    "stem_output_index": "int",
+}, total=False)
+
+SyncInfo = TypedDict("SyncInfo", {
+    "current_slot": "int",
+    "error": "str",
+    "fork": "ForkInfo",
+    "ledger_coverage": "int",
+    "lrb_slot": "int",
+    "per_sequencer": "Dict[str, SequencerSyncInfo]",
+    "synced": "bool",
+}, total=False)
+
+TransactionJSONAble = TypedDict("TransactionJSONAble", {
+    "endorsements": "List[str]",
+    "id": "str",
+    "inputs": "List[Input]",
+    "is_branch": "bool",
+    "outputs": "List[ParsedOutput]",
+    "sender": "str",
+    "sequencer_tx_data": "SequencerTxData",
+    "signature": "str",
+    "total_amount": "int",
+    "total_inflation": "int",
+    "tx_metadata": "TransactionMetadataJSONAble",
+}, total=False)
+
+TransactionMetadataJSONAble = TypedDict("TransactionMetadataJSONAble", {
+    "coverage_delta": "int",
+    "ledger_coverage": "int",
+    "slot_inflation": "int",
+    "state_root": "str",
+    "supply": "int",
+}, total=False)
+
+TxBytes = TypedDict("TxBytes", {
+    "tx_bytes": "str",
+    "tx_metadata": "TransactionMetadataJSONAble",
+}, total=False)
+
+TxRejection = TypedDict("TxRejection", {
+    "reason": "str",
+    "slot": "int",
+    "source": "str",
+    "time": "int",
+    "txid": "str",
+}, total=False)
+
+TxRejections = TypedDict("TxRejections", {
+    "error": "str",
+    "rejections": "List[TxRejection]",
+    "total": "int",
+}, total=False)
+
+VertexWithDependencies = TypedDict("VertexWithDependencies", {
+    "a": "int",
+    "endorse": "List[str]",
+    "explicit_baseline": "str",
+    "i": "int",
+    "id": "str",
+    "in": "List[str]",
+    "seqid": "str",
+    "seqidx": "int",
+    "stemidx": "int",
+}, total=False)
+
+
+class ProximaAPI:
+    """Client of the Proxima node API. API key, if provided, is sent in the 'X-API-Key' header"""
+
+    def __init__(self, base_url: str, api_key: Optional[str] = None, timeout: float = 10.0):
+        self.base_url = base_url.rstrip("/")
+        self.api_key = api_key
+        self.timeout = timeout
+
+    def _request(self, method: str, path: str, params: Dict[str, Any], body: Optional[bytes] = None) -> bytes:
+        query = urllib.parse.urlencode({k: v for k, v in params.items() if v is not None})
+        req = urllib.request.Request(self.base_url + path + ("?" + query if query else ""), data=body, method=method)
+        if self.api_key:
+            req.add_header("X-API-Key", self.api_key)
+        if body is not None:
+            req.add_header("Content-Type", "application/octet-stream")
+        try:
+            with urllib.request.urlopen(req, timeout=self.timeout) as resp:
+                return resp.read()
+        except urllib.error.HTTPError as e:
+            message = str(e)
+            try:
+                message = json.loads(e.read()).get("error") or message
+            except ValueError:
+                pass
+            raise ProximaAPIError(message, e.code) from None
+
+    def _json(self, method: str, path: str, params: Dict[str, Any], body: Optional[bytes] = None) -> Any:
+        ret = json.loads(self._request(method, path, params, body))
+        if isinstance(ret, dict) and ret.get("error"):
+            raise ProximaAPIError(ret["error"])
+        return ret
+
+    def account_history(self, addr: str, since: Optional[int] = None, max: Optional[int] = None) -> "AccountHistory":
+        """transactions touching the address, the latest first. Sequencer transactions are not included
+
+        :param addr: ED25519 address in EasyFL source form 'a(0x....)'
+        :param since: the earliest slot of returned transactions
+        :param max: maximum number of returned transactions
+        """
+        return self._json("GET", "/api/v1/account_history", {"addr": addr, "since": since, "max": max})
+
+    def chain_history(self, chainid: str, max: Optional[int] = None) -> "ChainHistory":
+        """transitions of the chain, the latest first. The chain is walked backwards from its output in the LRB through predecessor inputs
+
+        :param chainid: hex-encoded chain ID
+        :param max: maximum number of returned transitions
+        """
+        return self._json("GET", "/api/v1/chain_history", {"chainid": chainid, "max": max})
+
+    def check_txid_in_lrb(self, txid: str, max_depth: Optional[int] = None) -> "CheckTxIDInLRB":
+        """checks if transaction is included in the latest reliable branch
+
+        :param txid: hex-encoded transaction ID
+        :param max_depth: maximum depth of the search in the LRB
+        """
+        return self._json("GET", "/api/v1/check_txid_in_lrb", {"txid": txid, "max_depth": max_depth})
+
+    def delegation_history(self, chainid: str) -> "DelegationHistory":
+        """history of earnings of the delegation chain
+
+        :param chainid: hex-encoded chain ID
+        """
+        return self._json("GET", "/api/v1/delegation_history", {"chainid": chainid})
+
+    def get_account_outputs(self, accountable: str, max_outputs: Optional[int] = None, sort: Optional[str] = None) -> "OutputList":
+        """outputs of the account in the latest reliable branch
+
+        :param accountable: EasyFL source form of the accountable lock constraint, for example 'a(0x....)'
+        :param max_outputs: maximum number of returned outputs
+        :param sort: sort outputs by output ID
+        """
+        return self._json("GET", "/api/v1/get_account_outputs", {"accountable": accountable, "max_outputs": max_outputs, "sort": sort})
+
+    def get_account_parsed_outputs(self, accountable: str, max_outputs: Optional[int] = None, sort: Optional[str] = None) -> "ParsedOutputList":
+        """parsed outputs of the account in the latest reliable branch
+
+        :param accountable: EasyFL source form of the accountable lock constraint, for example 'a(0x....)'
+        :param max_outputs: maximum number of returned outputs
+        :param sort: sort outputs by output ID
+        """
+        return self._json("GET", "/api/v1/get_account_parsed_outputs", {"accountable": accountable, "max_outputs": max_outputs, "sort": sort})
+
+    def get_account_simple_siglocked(self, addr: str, max_outputs: Optional[int] = None, sort: Optional[str] = None) -> "OutputList":
+        """outputs locked in the address with the simple signature lock
+
+        :param addr: ED25519 address in EasyFL source form 'a(0x....)'
+        :param max_outputs: maximum number of returned outputs
+        :param sort: sort outputs by output ID
+        """
+        return self._json("GET", "/api/v1/get_account_simple_siglocked", {"addr": addr, "max_outputs": max_outputs, "sort": sort})
+
+    def get_all_chains(self) -> "Chains":
+        """all chains in the latest reliable branch
+        """
+        return self._json("GET", "/api/v1/get_all_chains", {})
+
+    def get_chain_output(self, chainid: str) -> "ChainOutput":
+        """output of the chain in the latest reliable branch
+
+        :param chainid: hex-encoded chain ID
+        """
+        return self._json("GET", "/api/v1/get_chain_output", {"chainid": chainid})
+
+    def get_chain_outputs(self, accountable: str) -> "ChainedOutputs":
+        """chain outputs controlled by the account
+
+        :param accountable: EasyFL source form of the accountable lock constraint, for example 'a(0x....)'
+        """
+        return self._json("GET", "/api/v1/get_chain_outputs", {"accountable": accountable})
+
+    def get_delegations_by_sequencer(self) -> "DelegationsBySequencer":
+        """delegations in the latest reliable branch summarized by sequencer
+        """
+        return self._json("GET", "/api/v1/get_delegations_by_sequencer", {})
+
+    def get_latest_reliable_branch(self) -> "LatestReliableBranch":
+        """latest reliable branch (LRB)
+        """
+        return self._json("GET", "/api/v1/get_latest_reliable_branch", {})
+
+    def get_ledger_id_data(self) -> "str":
+        """ledger definitions in YAML format
+        """
+        return self._request("GET", "/api/v1/get_ledger_id_data", {}).decode()
+
+    def get_mainchain(self, max: Optional[int] = None) -> "MainChain":
+        """main chain of branches
+
+        :param max: maximum number of branches
+        """
+        return self._json("GET", "/api/v1/get_mainchain", {"max": max})
+
+    def get_nonchain_balance(self, addr: str) -> "Balance":
+        """balance of non-chain outputs of the address
+
+        :param addr: ED25519 address in EasyFL source form 'a(0x....)'
+        """
+        return self._json("GET", "/api/v1/get_nonchain_balance", {"addr": addr})
+
+    def get_output(self, id: str) -> "OutputData":
+        """output by output ID
+
+        :param id: hex-encoded output ID
+        """
+        return self._json("GET", "/api/v1/get_output", {"id": id})
+
+    def get_outputs_for_amount(self, addr: str, amount: int) -> "OutputList":
+        """outputs of the address enough to cover the amount
+
+        :param addr: ED25519 address in EasyFL source form 'a(0x....)'
+        :param amount: amount to cover
+        """
+        return self._json("GET", "/api/v1/get_outputs_for_amount", {"addr": addr, "amount": amount})
+
+    def last_known_milestones(self) -> "KnownLatestMilestones":
+        """latest milestones of sequencers known to the node
+        """
+        return self._json("GET", "/api/v1/last_known_milestones", {})
+
+    def node_info(self) -> "NodeInfo":
+        """node info
+        """
+        return self._json("GET", "/api/v1/node_info", {})
+
+    def openapi(self) -> "Dict[str, Any]":
+        """this OpenAPI document
+        """
+        return self._json("GET", "/api/v1/openapi.json", {})
+
+    def peers_info(self) -> "PeersInfo":
+        """peers of the node
+        """
+        return self._json("GET", "/api/v1/peers_info", {})
+
+    def sequencer_stats(self) -> "SequencerStats":
+        """counters of the sequencer running on the node since the start of the node
+        """
+        return self._json("GET", "/api/v1/sequencer_stats", {})
+
+    def submit_tx(self, body: bytes, timeout: Optional[int] = None) -> "Error":
+        """submits raw transaction bytes. Feedback only on parsing error, otherwise async posting
+
+        :param timeout: timeout in seconds
+        """
+        return self._json("POST", "/api/v1/submit_tx", {"timeout": timeout}, body)
+
+    def sync_info(self) -> "SyncInfo":
+        """sync info of the node
+        """
+        return self._json("GET", "/api/v1/sync_info", {})
+
+    def tx_rejections(self, txid: Optional[str] = None, max: Optional[int] = None) -> "TxRejections":
+        """transactions rejected by the node, the latest first
+
+        :param txid: hex-encoded transaction ID. If omitted, the latest rejections are returned
+        :param max: maximum number of returned rejections, when txid is omitted
+        """
+        return self._json("GET", "/api/v1/tx_rejections", {"txid": txid, "max": max})
+
+    def compile_script(self, source: str) -> "Bytecode":
+        """compiles EasyFL script in the context of the ledger of the node
+
+        :param source: script source in EasyFL
+        """
+        return self._json("GET", "/txapi/v1/compile_script", {"source": source})
+
+    def decompile_bytecode(self, bytecode: str) -> "ScriptSource":
+        """decompiles bytecode in the context of the ledger of the node to EasyFL script
+
+        :param bytecode: hex-encoded bytecode
+        """
+        return self._json("GET", "/txapi/v1/decompile_bytecode", {"bytecode": bytecode})
+
+    def get_parsed_transaction(self, txid: str) -> "TransactionJSONAble":
+        """parsed transaction in JSON form, intended for display
+
+        :param txid: hex-encoded transaction ID
+        """
+        return self._json("GET", "/txapi/v1/get_parsed_transaction", {"txid": txid})
+
+    def get_txbytes(self, txid: str) -> "TxBytes":
+        """raw transaction bytes and metadata
+
+        :param txid: hex-encoded transaction ID
+        """
+        return self._json("GET", "/txapi/v1/get_txbytes", {"txid": txid})
+
+    def get_vertex_dep(self, txid: str) -> "VertexWithDependencies":
+        """compressed form of the DAG vertex, intended for DAG visualizers
+
+        :param txid: hex-encoded transaction ID
+        """
+        return self._json("GET", "/txapi/v1/get_vertex_dep", {"txid": txid})
+
+    def parse_output(self, output_id: str) -> "ParsedOutput":
+        """finds output in the latest reliable branch and parses it
+
+        :param output_id: hex-encoded output ID
+        """
+        return self._json("GET", "/txapi/v1/parse_output", {"output_id": output_id})
+
+    def parse_output_data(self, output_data: str) -> "ParsedOutput":
+        """parses raw output data
+
+        :param output_data: hex-encoded output binary
+        """
+        return self._json("GET", "/txapi/v1/parse_output_data", {"output_data": output_data})
@@ -0,0 +1,594 @@
+// Code generated from the OpenAPI document of the Proxima node. DO NOT EDIT.
+// Regenerate with 'go generate ./api/clients'
+// Client of the Proxima node API. Uses the standard 'fetch'
+
+/** Error returned by the node: HTTP status other than 200 or non-empty 'error' field of the response */
+export class ProximaAPIError extends Error {
+  constructor(message: string, public status: number = 200) {
+    super(message);
+    this.name = "ProximaAPIError";
+  }
+}
+
+// Schemas of responses
+
+export interface AccountHistory {
+  account: string;
+  error?: string;
+  lrbid: string;
+  since_slot: number;
+  transactions: AccountTx[];
+  truncated: boolean;
+}
+
+export interface AccountTx {
+  amount: number;
+  counterparties: string[];
+  direction: string;
+  fee: number;
+  slot: number;
+  status: string;
+  txid: string;
+}
+
+export interface Balance {
+  amount: number;
+  error?: string;
+  lrbid: string;
+}
+
+export interface BranchData {
+  data: BranchDataJSONAble;
+  id: string;
+}
+
+export interface BranchDataJSONAble {
+  branch_inflation: number;
+  on_chain_amount: number;
+  root: RootRecordJSONAble;
+  sequencer_output_index: number;
+  stem_output_index: number;
+}
+
+export interface Bytecode {
+  bytecode: string;
+}
+
+export interface ChainHistory {
+  chain_id: string;
+  error?: string;
+  lrbid: string;
+  transitions: ChainTransition[];
+  truncated: boolean;
+}
+
+export interface ChainOutput {
+  data: string;
+  error?: string;
+  id: string;
+  lrbid: string;
+}
+
+export interface ChainTransition {
+  amount: number;
+  inflation: number;
+  lock: string;
+  milestone_data?: MilestoneData;
+  output_id: string;
+  slot: number;
+  txid: string;
+}
+
+export interface ChainedOutputs {
+  error?: string;
+  lrbid: string;
+  outputs?: Record<string, string>;
+}
+
+export interface Chains {
+  chains: Record<string, OutputDataWithID>;
+  error?: string;
+  lrbid: string;
+}
+
+export interface CheckTxIDInLRB {
+  error?: string;
+  found_at_depth: number;
+  lrbid: string;
+  txid: string;
+}
+
+export interface DelegationData {
+  amount: number;
+  since_slot: number;
+  start_amount: number;
+}
+
+export interface DelegationHistory {
+  chain_id: string;
+  earned_per_slot: DelegationSlotEarnings[];
+  error?: string;
+  owner_lock?: string;
+  start_amount?: number;
+  start_slot?: number;
+  target_lock?: string;
+  total_earned: number;
+  total_margin: number;
+  transitions: DelegationTransition[];
+  truncated: boolean;
+}
+
+export interface DelegationSlotEarnings {
+  earned: number;
+  slot: number;
+}
+
+export interface DelegationTransition {
+  amount: number;
+  earned: number;
+  inflation: number;
+  margin: number;
+  output_id: string;
+  sequencer_id?: string;
+  slot: number;
+}
+
+export interface DelegationsBySequencer {
+  error?: string;
+  lrbid: string;
+  sequencers: Record<string, DelegationsOnSequencer>;
+}
+
+export interface DelegationsOnSequencer {
+  balance: number;
+  delegations: Record<string, DelegationData>;
+  seq_name: string;
+  seq_output_id: string;
+}
+
+export interface Error {
+  error?: string;
+}
+
+export interface ForkBranch {
+  coverage: number;
+  id: string;
+  source: string;
+}
+
+export interface ForkInfo {
+  competing?: ForkBranch[];
+  detected: boolean;
+  main_tip?: string;
+  main_tip_coverage: number;
+  num_slots: number;
+  on_minority: boolean;
+  since_slot: number;
+}
+
+export interface Input {
+  output_id: string;
+  unlock_data: string;
+}
+
+export interface KnownLatestMilestones {
+  error?: string;
+  sequencers: Record<string, LatestSequencerTipDataJSONAble>;
+}
+
+export interface LatestReliableBranch {
+  branch_id?: string;
+  error?: string;
+  root_record?: RootRecordJSONAble;
+}
+
+export interface LatestSequencerTipDataJSONAble {
+  last_activity_unix_nano: number;
+  last_branch_txid?: string;
+  latest_milestone_txid: string;
+  milestone_count: number;
+}
+
+export interface MainChain {
+  branches: BranchData[];
+  error?: string;
+}
+
+export interface MilestoneData {
+  branch_height: number;
+  chain_height: number;
+  minimum_fee: number;
+  name: string;
+}
+
+export interface NodeInfo {
+  clock_correction_ns: number;
+  clock_offset_ns: number;
+  clock_skew_too_big: boolean;
+  commit_hash: string;
+  commit_time: string;
+  id: string;
+  num_dynamic_alive: number;
+  num_static_peers: number;
+  sequencers?: string;
+  version: string;
+}
+
+export interface OutputData {
+  error?: string;
+  lrbid: string;
+  output_data?: string;
+}
+
+export interface OutputDataWithID {
+  data: string;
+  id: string;
+}
+
+export interface OutputList {
+  error?: string;
+  lrbid: string;
+  outputs?: Record<string, string>;
+}
+
+export interface ParsedOutput {
+  amount: number;
+  chain_id?: string;
+  constraints: string[];
+  data: string;
+  lock_name: string;
+}
+
+export interface ParsedOutputList {
+  error?: string;
+  lrbid: string;
+  outputs?: Record<string, ParsedOutput>;
+}
+
+export interface PeerInfo {
+  bytes_in: number;
+  bytes_out: number;
+  clock_differences_quartiles: number[];
+  hb_differences_quartiles: number[];
+  id: string;
+  is_alive: boolean;
+  is_static: boolean;
+  last_heartbeat_received: number;
+  multiAddresses?: string[];
+  num_incoming_hb: number;
+  num_incoming_pull: number;
+  num_incoming_tx: number;
+  num_throttled: number;
+  reputation?: PeerReputation;
+  responds_to_pull: boolean;
+  supports_announce: boolean;
+  supports_compression: boolean;
+  supports_pull_batch: boolean;
+  when_added: number;
+}
+
+export interface PeerReputation {
+  clock_penalty: number;
+  misbehaviour: number;
+  num_bans: number;
+  num_invalid_tx: number;
+  num_pull_missed: number;
+  num_pull_responded: number;
+  num_useful_tx: number;
+  num_violations: number;
+  reliability: number;
+  score: number;
+}
+
+export interface PeersInfo {
+  blacklist?: Record<string, string>;
+  error?: string;
+  host_id: string;
+  peers?: PeerInfo[];
+}
+
+export interface RootRecordJSONAble {
+  coverage_delta: number;
+  root: string;
+  sequencer_id: string;
+  slot_inflation: number;
+  supply: number;
+}
+
+export interface ScriptSource {
+  source: string;
+}
+
+export interface SequencerStats {
+  backlog_size: number;
+  best_proposals: Record<string, number>;
+  branches: number;
+  error?: string;
+  milestones: number;
+  own_milestones: number;
+  proposals: Record<string, number>;
+  sequencer_id?: string;
+  targets: number;
+}
+
+export interface SequencerSyncInfo {
+  latest_committed_slot: number;
+  latest_healthy_slot: number;
+  ledger_coverage: number;
+  synced: boolean;
+}
+
+export interface SequencerTxData {
+  milestone_data?: MilestoneData;
+  sequencer_id: string;
+  sequencer_output_index: number;
+  stem_output_index?: number;
+}
+
+export interface SyncInfo {
+  current_slot: number;
+  error?: string;
+  fork?: ForkInfo;
+  ledger_coverage: number;
+  lrb_slot: number;
+  per_sequencer?: Record<string, SequencerSyncInfo>;
+  synced: boolean;
+}
+
+export interface TransactionJSONAble {
+  endorsements?: string[];
+  id: string;
+  inputs: Input[];
+  is_branch: boolean;
+  outputs: ParsedOutput[];
+  sender: string;
+  sequencer_tx_data?: SequencerTxData;
+  signature: string;
+  total_amount: number;
+  total_inflation: number;
+  tx_metadata?: TransactionMetadataJSONAble;
+}
+
+export interface TransactionMetadataJSONAble {
+  coverage_delta?: number;
+  ledger_coverage?: number;
+  slot_inflation?: number;
+  state_root?: string;
+  supply?: number;
+}
+
+export interface TxBytes {
+  tx_bytes: string;
+  tx_metadata?: TransactionMetadataJSONAble;
+}
+
+export interface TxRejection {
+  reason: string;
+  slot: number;
+  source: string;
+  time: number;
+  txid: string;
+}
+
+export interface TxRejections {
+  error?: string;
+  rejections: TxRejection[];
+  total: number;
+}
+
+export interface VertexWithDependencies {
+  a: number;
+  endorse?: string[];
+  explicit_baseline?: string;
+  i?: number;
+  id: string;
+  in: string[];
+  seqid?: string;
+  seqidx?: number;
+  stemidx?: number;
+}
+
+type QueryParams = Record<string, string | number | undefined>;
+
+/** Client of the Proxima node API. API key, if provided, is sent in the 'X-API-Key' header */
+export class ProximaAPI {
+  constructor(public baseURL: string, public apiKey?: string) {
+    this.baseURL = baseURL.replace(/\/+$/, "");
+  }
+
+  private async request(method: string, path: string, params: QueryParams, body?: Uint8Array): Promise<Response> {
+    const query = new URLSearchParams();
+    for (const [k, v] of Object.entries(params)) {
+      if (v !== undefined) {
+        query.set(k, String(v));
+      }
+    }
+    const qs = query.toString();
+    const headers: Record<string, string> = {};
+    if (this.apiKey) {
+      headers["X-API-Key"] = this.apiKey;
+    }
+    if (body !== undefined) {
+      headers["Content-Type"] = "application/octet-stream";
+    }
+    const resp = await fetch(this.baseURL + path + (qs ? "?" + qs : ""), { method, headers, body });
+    if (!resp.ok) {
+      let message = resp.statusText;
+      try {
+        message = (await resp.json()).error || message;
+      } catch {
+        // not JSON
+      }
+      throw new ProximaAPIError(message, resp.status);
+    }
+    return resp;
+  }
+
+  private async json<T>(method: string, path: string, params: QueryParams, body?: Uint8Array): Promise<T> {
+    const ret = await (await this.request(method, path, params, body)).json();
+    if (ret && typeof ret === "object" && ret.error) {
+      throw new ProximaAPIError(ret.error);
+    }
+    return ret as T;
+  }
+
+  /** transactions touching the address, the latest first. Sequencer transactions are not included */
+  async accountHistory(params: { addr: string; since?: number; max?: number }): Promise<AccountHistory> {
+    return this.json<AccountHistory>("GET", "/api/v1/account_history", params);
+  }
+
+  /** transitions of the chain, the latest first. The chain is walked backwards from its output in the LRB through predecessor inputs */
+  async chainHistory(params: { chainid: string; max?: number }): Promise<ChainHistory> {
+    return this.json<ChainHistory>("GET", "/api/v1/chain_history", params);
+  }
+
+  /** checks if transaction is included in the latest reliable branch */
+  async checkTxidInLrb(params: { txid: string; max_depth?: number }): Promise<CheckTxIDInLRB> {
+    return this.json<CheckTxIDInLRB>("GET", "/api/v1/check_txid_in_lrb", params);
+  }
+
+  /** history of earnings of the delegation chain */
+  async delegationHistory(params: { chainid: string }): Promise<DelegationHistory> {
+    return this.json<DelegationHistory>("GET", "/api/v1/delegation_history", params);
+  }
+
+  /** outputs of the account in the latest reliable branch */
+  async getAccountOutputs(params: { accountable: string; max_outputs?: number; sort?: "asc" | "desc" }): Promise<OutputList> {
+    return this.json<OutputList>("GET", "/api/v1/get_account_outputs", params);
+  }
+
+  /** parsed outputs of the account in the latest reliable branch */
+  async getAccountParsedOutputs(params: { accountable: string; max_outputs?: number; sort?: "asc" | "desc" }): Promise<ParsedOutputList> {
+    return this.json<ParsedOutputList>("GET", "/api/v1/get_account_parsed_outputs", params);
+  }
+
+  /** outputs locked in the address with the simple signature lock */
+  async getAccountSimpleSiglocked(params: { addr: string; max_outputs?: number; sort?: "asc" | "desc" }): Promise<OutputList> {
+    return this.json<OutputList>("GET", "/api/v1/get_account_simple_siglocked", params);
+  }
+
+  /** all chains in the latest reliable branch */
+  async getAllChains(): Promise<Chains> {
+    return this.json<Chains>("GET", "/api/v1/get_all_chains", {});
+  }
+
+  /** output of the chain in the latest reliable branch */
+  async getChainOutput(params: { chainid: string }): Promise<ChainOutput> {
+    return this.json<ChainOutput>("GET", "/api/v1/get_chain_output", params);
+  }
+
+  /** chain outputs controlled by the account */
+  async getChainOutputs(params: { accountable: string }): Promise<ChainedOutputs> {
+    return this.json<ChainedOutputs>("GET", "/api/v1/get_chain_outputs", params);
+  }
+
+  /** delegations in the latest reliable branch summarized by sequencer */
+  async getDelegationsBySequencer(): Promise<DelegationsBySequencer> {
+    return this.json<DelegationsBySequencer>("GET", "/api/v1/get_delegations_by_sequencer", {});
+  }
+
+  /** latest reliable branch (LRB) */
+  async getLatestReliableBranch(): Promise<LatestReliableBranch> {
+    return this.json<LatestReliableBranch>("GET", "/api/v1/get_latest_reliable_branch", {});
+  }
+
+  /** ledger definitions in YAML format */
+  async getLedgerIdData(): Promise<string> {
+    return (await this.request("GET", "/api/v1/get_ledger_id_data", {})).text();
+  }
+
+  /** main chain of branches */
+  async getMainchain(params: { max?: number } = {}): Promise<MainChain> {
+    return this.json<MainChain>("GET", "/api/v1/get_mainchain", params);
+  }
+
+  /** balance of non-chain outputs of the address */
+  async getNonchainBalance(params: { addr: string }): Promise<Balance> {
+    return this.json<Balance>("GET", "/api/v1/get_nonchain_balance", params);
+  }
+
+  /** output by output ID */
+  async getOutput(params: { id: string }): Promise<OutputData> {
+    return this.json<OutputData>("GET", "/api/v1/get_output", params);
+  }
+
+  /** outputs of the address enough to cover the amount */
+  async getOutputsForAmount(params: { addr: string; amount: number }): Promise<OutputList> {
+    return this.json<OutputList>("GET", "/api/v1/get_outputs_for_amount", params);
+  }
+
+  /** latest milestones of sequencers known to the node */
+  async lastKnownMilestones(): Promise<KnownLatestMilestones> {
+    return this.json<KnownLatestMilestones>("GET", "/api/v1/last_known_milestones", {});
+  }
+
+  /** node info */
+  async nodeInfo(): Promise<NodeInfo> {
+    return this.json<NodeInfo>("GET", "/api/v1/node_info", {});
+  }
+
+  /** this OpenAPI document */
+  async openapi(): Promise<Record<string, unknown>> {
+    return this.json<Record<string, unknown>>("GET", "/api/v1/openapi.json", {});
+  }
+
+  /** peers of the node */
+  async peersInfo(): Promise<PeersInfo> {
+    return this.json<PeersInfo>("GET", "/api/v1/peers_info", {});
+  }
+
+  /** counters of the sequencer running on the node since the start of the node */
+  async sequencerStats(): Promise<SequencerStats> {
+    return this.json<SequencerStats>("GET", "/api/v1/sequencer_stats", {});
+  }
+
+  /** submits raw transaction bytes. Feedback only on parsing error, otherwise async posting */
+  async submitTx(body: Uint8Array, params: { timeout?: number } = {}): Promise<Error> {
+    return this.json<Error>("POST", "/api/v1/submit_tx", params, body);
+  }
+
+  /** sync info of the node */
+  async syncInfo(): Promise<SyncInfo> {
+    return this.json<SyncInfo>("GET", "/api/v1/sync_info", {});
+  }
+
+  /** transactions rejected by the node, the latest first */
+  async txRejections(params: { txid?: string; max?: number } = {}): Promise<TxRejections> {
+    return this.json<TxRejections>("GET", "/api/v1/tx_rejections", params);
+  }
+
+  /** compiles EasyFL script in the context of the ledger of the node */
+  async compileScript(params: { source: string }): Promise<Bytecode> {
+    return this.json<Bytecode>("GET", "/txapi/v1/compile_script", params);
+  }
+
+  /** decompiles bytecode in the context of the ledger of the node to EasyFL script */
+  async decompileBytecode(params: { bytecode: string }): Promise<ScriptSource> {
+    return this.json<ScriptSource>("GET", "/txapi/v1/decompile_bytecode", params);
+  }
+
+  /** parsed transaction in JSON form, intended for display */
+  async getParsedTransaction(params: { txid: string }): Promise<TransactionJSONAble> {
+    return this.json<TransactionJSONAble>("GET", "/txapi/v1/get_parsed_transaction", params);
+  }
+
+  /** raw transaction bytes and metadata */
+  async getTxbytes(params: { txid: string }): Promise<TxBytes> {
+    return this.json<TxBytes>("GET", "/txapi/v1/get_txbytes", params);
+  }
+
+  /** compressed form of the DAG vertex, intended for DAG visualizers */
+  async getVertexDep(params: { txid: string }): Promise<VertexWithDependencies> {
+    return this.json<VertexWithDependencies>("GET", "/txapi/v1/get_vertex_dep", params);
+  }
+
+  /** finds output in the latest reliable branch and parses it */
+  async parseOutput(params: { output_id: string }): Promise<ParsedOutput> {
+    return this.json<ParsedOutput>("GET", "/txapi/v1/parse_output", params);
+  }
+
+  /** parses raw output data */
+  async parseOutputData(params: { output_data: string }): Promise<ParsedOutput> {
+    return this.json<ParsedOutput>("GET", "/txapi/v1/parse_output_data", params);
+  }
+}
@@ -0,0 +1,386 @@
+package server
+
+import (
+	"bufio"
+	"crypto/subtle"
+	"fmt"
+	"net"
+	"net/http"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/lunfardo314/proxima/api"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// access control of the API server: API keys with scopes, token-bucket rate limits, CORS and per-endpoint metrics
+
+const (
+	// ScopeRead allows all read-only endpoints
+	ScopeRead = "read"
+	// ScopeSubmit allows submission of transactions
+	ScopeSubmit = "submit"
+)
+
+type (
+	// Config is configuration of the API server
+	Config struct {
+		// listen address, for example ':8000'
+		Addr string
+		// server certificate and key. If empty, plain HTTP is served
+		CertFile string
+		KeyFile  string
+		// scopes granted to requests without API key. Empty means API key is required for all endpoints
+		AnonymousScopes []string
+		// API keys with its scopes and optional own rate limits
+		Keys []KeyConfig
+		// rate limits. Nil means no rate limiting
+		RateLimit *RateLimitConfig
+		CORS      CORSConfig
+		// web socket stream handlers by path. Served with the same access control as other endpoints
+		StreamHandlers map[string]func(http.ResponseWriter, *http.Request)
+	}
+
+	KeyConfig struct {
+		// name is used in logs and as a rate limiting bucket
+		Name   string
+		Key    string
+		Scopes []string
+		// overrides per key limits of the RateLimitConfig for this key. Nil means no override
+		Limit *Limit
+	}
+
+	// Limit is a parameters of the token bucket. Zero RPS means unlimited
+	Limit struct {
+		RPS   float64
+		Burst int
+	}
+
+	EndpointLimits struct {
+		PerIP  *Limit
+		PerKey *Limit
+	}
+
+	RateLimitConfig struct {
+		// default limits for each endpoint. Requests with API key are limited per key, others per IP
+		PerIP  Limit
+		PerKey Limit
+		// overrides of default limits by endpoint name, for example 'submit_tx'
+		Endpoints map[string]EndpointLimits
+		// take client IP from the 'X-Forwarded-For' header. Only makes sense behind the trusted reverse proxy
+		TrustForwardedFor bool
+	}
+
+	CORSConfig struct {
+		// allowed origins. '*' means any. Empty means CORS headers are not sent
+		AllowedOrigins []string
+		// allowed request headers in addition to the API key headers
+		AllowedHeaders []string
+		MaxAge         time.Duration
+	}
+
+	accessControl struct {
+		anonymousScopes []string
+		keys            []KeyConfig
+		rateLimit       *RateLimitConfig
+		cors            CORSConfig
+		limiter         *rateLimiter
+	}
+
+	rateLimiter struct {
+		mutex       sync.Mutex
+		buckets     map[string]*tokenBucket
+		lastCleanup time.Time
+	}
+
+	tokenBucket struct {
+		limit  Limit
+		tokens float64
+		last   time.Time
+	}
+
+	// statusRecorder captures HTTP status of the response for metrics
+	statusRecorder struct {
+		http.ResponseWriter
+		status int
+	}
+)
+
+const (
+	apiKeyHeader          = "X-API-Key"
+	apiKeyQueryParam      = "api_key"
+	rateLimiterCleanupPer = time.Minute
+	// scopePublic means endpoint is served without API key. Only for static content
+	scopePublic = ""
+)
+
+// endpointScopes scopes required by endpoints other than ScopeRead
+var endpointScopes = map[string]string{
+	api.PathSubmitTransaction: ScopeSubmit,
+	// the dashboard page and its scripts are static, they contain no data of the node
+	api.PathGetDashboard:       scopePublic,
+	api.PathGetDashboardAssets: scopePublic,
+}
+
+// queryKeyPatterns are endpoints which accept the API key in the 'api_key' query parameter, because the browser
+// can't send headers when it opens the web socket
+var queryKeyPatterns = map[string]bool{
+	api.PathDAGVertexStream: true,
+	api.PathForkEventStream: true,
+}
+
+func newAccessControl(cfg *Config) *accessControl {
+	return &accessControl{
+		anonymousScopes: cfg.AnonymousScopes,
+		keys:            cfg.Keys,
+		rateLimit:       cfg.RateLimit,
+		cors:            cfg.CORS,
+		limiter:         newRateLimiter(),
+	}
+}
+
+func requiredScope(pattern string) string {
+	if scope, found := endpointScopes[pattern]; found {
+		return scope
+	}
+	return ScopeRead
+}
+
+// apiKeyFromRequest takes API key from the 'X-API-Key' header, from the bearer token or, if allowed,
+// from the 'api_key' query parameter
+func apiKeyFromRequest(r *http.Request, allowQuery bool) string {
+	if key := r.Header.Get(apiKeyHeader); key != "" {
+		return key
+	}
+	if key, found := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); found {
+		return key
+	}
+	if allowQuery {
+		return r.URL.Query().Get(apiKeyQueryParam)
+	}
+	return ""
+}
+
+// findKey returns config of the API key or nil if the key is unknown
+func (ac *accessControl) findKey(key string) *KeyConfig {
+	var ret *KeyConfig
+	for i := range ac.keys {
+		// do not stop on the first match to keep timing independent of the position of the key
+		if subtle.ConstantTimeCompare([]byte(key), []byte(ac.keys[i].Key)) == 1 {
+			ret = &ac.keys[i]
+		}
+	}
+	return ret
+}
+
+func (ac *accessControl) clientIP(r *http.Request) string {
+	if ac.rateLimit != nil && ac.rateLimit.TrustForwardedFor {
+		if fwd := r.Header.Get("X-Forwarded-For"); fwd != "" {
+			first, _, _ := strings.Cut(fwd, ",")
+			return strings.TrimSpace(first)
+		}
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// authorize checks API key and the scope required by the endpoint. Returns key config (nil for anonymous)
+// or HTTP status and error message
+func (ac *accessControl) authorize(r *http.Request, pattern string) (*KeyConfig, int, string) {
+	scope := requiredScope(pattern)
+	if scope == scopePublic {
+		return nil, http.StatusOK, ""
+	}
+	key := apiKeyFromRequest(r, queryKeyPatterns[pattern])
+	if key == "" {
+		if slices.Contains(ac.anonymousScopes, scope) {
+			return nil, http.StatusOK, ""
+		}
+		return nil, http.StatusUnauthorized, "API key is required"
+	}
+	kc := ac.findKey(key)
+	if kc == nil {
+		return nil, http.StatusUnauthorized, "invalid API key"
+	}
+	if !slices.Contains(kc.Scopes, scope) {
+		return nil, http.StatusForbidden, fmt.Sprintf("API key '%s' has no scope '%s'", kc.Name, scope)
+	}
+	return kc, http.StatusOK, ""
+}
+
+// allow checks rate limit of the endpoint for the key or, if key is nil, for the client IP
+func (ac *accessControl) allow(r *http.Request, pattern string, kc *KeyConfig) bool {
+	if ac.rateLimit == nil {
+		return true
+	}
+	endpoint := path.Base(pattern)
+	overrides := ac.rateLimit.Endpoints[endpoint]
+
+	var limit Limit
+	var bucket string
+	if kc != nil {
+		limit = ac.rateLimit.PerKey
+		if overrides.PerKey != nil {
+			limit = *overrides.PerKey
+		}
+		if kc.Limit != nil {
+			limit = *kc.Limit
+		}
+		bucket = endpoint + "|key:" + kc.Name
+	} else {
+		limit = ac.rateLimit.PerIP
+		if overrides.PerIP != nil {
+			limit = *overrides.PerIP
+		}
+		bucket = endpoint + "|ip:" + ac.clientIP(r)
+	}
+	return ac.limiter.allow(bucket, limit, time.Now())
+}
+
+// setCORSHeaders sets CORS headers if origin of the request is allowed. Otherwise, headers are not set and
+// the browser does not let the page to read the response
+func (ac *accessControl) setCORSHeaders(w http.ResponseWriter, r *http.Request) {
+	origin := r.Header.Get("Origin")
+	if origin == "" || len(ac.cors.AllowedOrigins) == 0 {
+		return
+	}
+	switch {
+	case slices.Contains(ac.cors.AllowedOrigins, "*"):
+		w.Header().Set("Access-Control-Allow-Origin", "*")
+	case slices.Contains(ac.cors.AllowedOrigins, origin):
+		w.Header().Set("Access-Control-Allow-Origin", origin)
+		w.Header().Add("Vary", "Origin")
+	default:
+		return
+	}
+	if r.Method == http.MethodOptions {
+		headers := append([]string{apiKeyHeader, "Authorization", "Content-Type"}, ac.cors.AllowedHeaders...)
+		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, OPTIONS")
+		w.Header().Set("Access-Control-Allow-Headers", strings.Join(headers, ", "))
+		if ac.cors.MaxAge > 0 {
+			w.Header().Set("Access-Control-Max-Age", strconv.Itoa(int(ac.cors.MaxAge.Seconds())))
+		}
+	}
+}
+
+func newRateLimiter() *rateLimiter {
+	return &rateLimiter{
+		buckets:     make(map[string]*tokenBucket),
+		lastCleanup: time.Now(),
+	}
+}
+
+func (rl *rateLimiter) allow(bucket string, limit Limit, now time.Time) bool {
+	if limit.RPS <= 0 {
+		return true
+	}
+	rl.mutex.Lock()
+	defer rl.mutex.Unlock()
+
+	if now.Sub(rl.lastCleanup) >= rateLimiterCleanupPer {
+		rl._cleanup(now)
+	}
+	b, found := rl.buckets[bucket]
+	if !found || b.limit != limit {
+		b = &tokenBucket{limit: limit, tokens: float64(max(limit.Burst, 1)), last: now}
+		rl.buckets[bucket] = b
+	}
+	return b.take(now)
+}
+
+// _cleanup removes buckets which are full, i.e. are equivalent to the new ones
+func (rl *rateLimiter) _cleanup(now time.Time) {
+	for key, b := range rl.buckets {
+		if b.refilled(now) >= float64(max(b.limit.Burst, 1)) {
+			delete(rl.buckets, key)
+		}
+	}
+	rl.lastCleanup = now
+}
+
+func (b *tokenBucket) refilled(now time.Time) float64 {
+	return min(b.tokens+now.Sub(b.last).Seconds()*b.limit.RPS, float64(max(b.limit.Burst, 1)))
+}
+
+func (b *tokenBucket) take(now time.Time) bool {
+	b.tokens = b.refilled(now)
+	b.last = now
+	if b.tokens < 1 {
+		return false
+	}
+	b.tokens--
+	return true
+}
+
+func (sr *statusRecorder) WriteHeader(status int) {
+	sr.status = status
+	sr.ResponseWriter.WriteHeader(status)
+}
+
+// Hijack makes upgrade of the stream endpoints to web socket connections possible
+func (sr *statusRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	h, ok := sr.ResponseWriter.(http.Hijacker)
+	if !ok {
+		return nil, nil, fmt.Errorf("response writer does not support hijacking")
+	}
+	sr.status = http.StatusSwitchingProtocols
+	return h.Hijack()
+}
+
+func (srv *server) registerMetrics() {
+	srv.metrics.requests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "proxima_api_requests",
+		Help: "API requests by endpoint and HTTP status",
+	}, []string{"endpoint", "status"})
+	srv.MetricsRegistry().MustRegister(srv.metrics.requests)
+}
+
+// wrapHandler wraps the endpoint handler with CORS, authorization, rate limiting and metrics
+func (srv *server) wrapHandler(pattern string, handler func(http.ResponseWriter, *http.Request)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		srv.Tracef(TraceTag, "API request: %s from %s", r.URL.Path, r.RemoteAddr)
+
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		srv.serveWithAccessControl(rec, r, pattern, handler)
+		if srv.metrics.requests != nil {
+			srv.metrics.requests.WithLabelValues(pattern, strconv.Itoa(rec.status)).Inc()
+		}
+	}
+}
+
+func (srv *server) serveWithAccessControl(w http.ResponseWriter, r *http.Request, pattern string, handler func(http.ResponseWriter, *http.Request)) {
+	srv.access.setCORSHeaders(w, r)
+	if r.Method == http.MethodOptions {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	kc, status, errStr := srv.access.authorize(r, pattern)
+	if status != http.StatusOK {
+		srv.Tracef(TraceTag, "API request %s from %s rejected: %s", r.URL.Path, r.RemoteAddr, errStr)
+		if status == http.StatusUnauthorized {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+		}
+		writeErrWithStatus(w, status, errStr)
+		return
+	}
+	if !srv.access.allow(r, pattern, kc) {
+		srv.Tracef(TraceTag, "API request %s from %s rejected: rate limit exceeded", r.URL.Path, r.RemoteAddr)
+		w.Header().Set("Retry-After", "1")
+		writeErrWithStatus(w, http.StatusTooManyRequests, "rate limit exceeded")
+		return
+	}
+	handler(w, r)
+}
+
+func writeErrWithStatus(w http.ResponseWriter, status int, errStr string) {
+	api.SetHeader(w)
+	w.WriteHeader(status)
+	api.WriteErr(w, errStr)
+}
@@ -0,0 +1,183 @@
+package server
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/lunfardo314/proxima/api"
+	"github.com/lunfardo314/proxima/global"
+	"github.com/prometheus/client_golang/prometheus"
+	promtestutil "github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// testEnvironment implements only logging, the rest of the environment panics
+type testEnvironment struct {
+	environment
+	log *zap.SugaredLogger
+}
+
+func (e testEnvironment) Log() *zap.SugaredLogger             { return e.log }
+func (e testEnvironment) Tracef(_ string, _ string, _ ...any) {}
+
+func newTestAccessServer(cfg *Config) *server {
+	return &server{
+		environment: testEnvironment{log: global.NewDefault().Log()},
+		access:      newAccessControl(cfg),
+		metrics: metrics{
+			requests: prometheus.NewCounterVec(prometheus.CounterOpts{Name: "test"}, []string{"endpoint", "status"}),
+		},
+	}
+}
+
+func doRequest(h http.HandlerFunc, method, path string, header map[string]string) int {
+	req := httptest.NewRequest(method, path, nil)
+	for k, v := range header {
+		req.Header.Set(k, v)
+	}
+	w := httptest.NewRecorder()
+	h(w, req)
+	return w.Result().StatusCode
+}
+
+func TestTokenBucket(t *testing.T) {
+	rl := newRateLimiter()
+	lim := Limit{RPS: 2, Burst: 3}
+	now := time.Now()
+	for i := 0; i < 3; i++ {
+		require.True(t, rl.allow("a", lim, now))
+	}
+	require.False(t, rl.allow("a", lim, now))
+	// other bucket is independent
+	require.True(t, rl.allow("b", lim, now))
+	// one token after 0.5 sec
+	require.True(t, rl.allow("a", lim, now.Add(500*time.Millisecond)))
+	require.False(t, rl.allow("a", lim, now.Add(500*time.Millisecond)))
+	// no more than burst after a long time
+	later := now.Add(time.Hour)
+	for i := 0; i < 3; i++ {
+		require.True(t, rl.allow("a", lim, later))
+	}
+	require.False(t, rl.allow("a", lim, later))
+	// full buckets are removed by the cleanup
+	require.Len(t, rl.buckets, 1)
+	// zero rate means unlimited
+	for i := 0; i < 100; i++ {
+		require.True(t, rl.allow("c", Limit{}, now))
+	}
+}
+
+func TestAccessControl(t *testing.T) {
+	ok := func(w http.ResponseWriter, _ *http.Request) { api.WriteOk(w) }
+
+	t.Run("scopes", func(t *testing.T) {
+		srv := newTestAccessServer(&Config{
+			AnonymousScopes: []string{ScopeRead},
+			Keys: []KeyConfig{
+				{Name: "reader", Key: "r-key", Scopes: []string{ScopeRead}},
+				{Name: "submitter", Key: "s-key", Scopes: []string{ScopeRead, ScopeSubmit}},
+			},
+		})
+		read := srv.wrapHandler(api.PathGetSyncInfo, ok)
+		submit := srv.wrapHandler(api.PathSubmitTransaction, ok)
+
+		require.Equal(t, http.StatusOK, doRequest(read, http.MethodGet, api.PathGetSyncInfo, nil))
+		require.Equal(t, http.StatusUnauthorized, doRequest(submit, http.MethodPost, api.PathSubmitTransaction, nil))
+		require.Equal(t, http.StatusUnauthorized, doRequest(read, http.MethodGet, api.PathGetSyncInfo, map[string]string{"X-API-Key": "wrong"}))
+		require.Equal(t, http.StatusForbidden, doRequest(submit, http.MethodPost, api.PathSubmitTransaction, map[string]string{"X-API-Key": "r-key"}))
+		require.Equal(t, http.StatusOK, doRequest(submit, http.MethodPost, api.PathSubmitTransaction, map[string]string{"X-API-Key": "s-key"}))
+		require.Equal(t, http.StatusOK, doRequest(submit, http.MethodPost, api.PathSubmitTransaction, map[string]string{"Authorization": "Bearer s-key"}))
+
+		require.EqualValues(t, 1, promtestutil.ToFloat64(srv.metrics.requests.WithLabelValues(api.PathGetSyncInfo, "401")))
+		require.EqualValues(t, 1, promtestutil.ToFloat64(srv.metrics.requests.WithLabelValues(api.PathSubmitTransaction, "403")))
+		require.EqualValues(t, 2, promtestutil.ToFloat64(srv.metrics.requests.WithLabelValues(api.PathSubmitTransaction, "200")))
+	})
+	t.Run("rate limit", func(t *testing.T) {
+		srv := newTestAccessServer(&Config{
+			AnonymousScopes: []string{ScopeRead, ScopeSubmit},
+			Keys:            []KeyConfig{{Name: "k", Key: "key", Scopes: []string{ScopeRead}}},
+			RateLimit: &RateLimitConfig{
+				PerIP:  Limit{RPS: 0.001, Burst: 2},
+				PerKey: Limit{RPS: 0.001, Burst: 3},
+				Endpoints: map[string]EndpointLimits{
+					"submit_tx": {PerIP: &Limit{RPS: 0.001, Burst: 1}},
+				},
+			},
+		})
+		read := srv.wrapHandler(api.PathGetSyncInfo, ok)
+		submit := srv.wrapHandler(api.PathSubmitTransaction, ok)
+
+		for i := 0; i < 2; i++ {
+			require.Equal(t, http.StatusOK, doRequest(read, http.MethodGet, api.PathGetSyncInfo, nil))
+		}
+		require.Equal(t, http.StatusTooManyRequests, doRequest(read, http.MethodGet, api.PathGetSyncInfo, nil))
+		// limits are per endpoint
+		require.Equal(t, http.StatusOK, doRequest(submit, http.MethodPost, api.PathSubmitTransaction, nil))
+		require.Equal(t, http.StatusTooManyRequests, doRequest(submit, http.MethodPost, api.PathSubmitTransaction, nil))
+		// key has its own bucket
+		for i := 0; i < 3; i++ {
+			require.Equal(t, http.StatusOK, doRequest(read, http.MethodGet, api.PathGetSyncInfo, map[string]string{"X-API-Key": "key"}))
+		}
+		require.Equal(t, http.StatusTooManyRequests, doRequest(read, http.MethodGet, api.PathGetSyncInfo, map[string]string{"X-API-Key": "key"}))
+		require.EqualValues(t, 2, promtestutil.ToFloat64(srv.metrics.requests.WithLabelValues(api.PathGetSyncInfo, "429")))
+	})
+	t.Run("streams", func(t *testing.T) {
+		srv := newTestAccessServer(&Config{
+			Keys: []KeyConfig{{Name: "reader", Key: "r-key", Scopes: []string{ScopeRead}}},
+		})
+		upgraded := 0
+		stream := srv.wrapHandler(api.PathForkEventStream, func(w http.ResponseWriter, _ *http.Request) {
+			// web socket upgrade requires hijacking of the connection
+			_, isHijacker := w.(http.Hijacker)
+			require.True(t, isHijacker)
+			upgraded++
+		})
+		require.Equal(t, http.StatusUnauthorized, doRequest(stream, http.MethodGet, api.PathForkEventStream, nil))
+		require.Equal(t, 0, upgraded)
+		require.Equal(t, http.StatusOK, doRequest(stream, http.MethodGet, api.PathForkEventStream, map[string]string{"X-API-Key": "r-key"}))
+		require.Equal(t, 1, upgraded)
+		// the browser can't send the header with the web socket, so the key is taken from the query
+		require.Equal(t, http.StatusOK, doRequest(stream, http.MethodGet, api.PathForkEventStream+"?api_key=r-key", nil))
+		require.Equal(t, 2, upgraded)
+		require.Equal(t, http.StatusUnauthorized, doRequest(stream, http.MethodGet, api.PathForkEventStream+"?api_key=wrong", nil))
+		require.Equal(t, 2, upgraded)
+	})
+	t.Run("api key in the query", func(t *testing.T) {
+		srv := newTestAccessServer(&Config{
+			Keys: []KeyConfig{{Name: "reader", Key: "r-key", Scopes: []string{ScopeRead}}},
+		})
+		// not accepted by other endpoints, to keep keys out of the logs of proxies
+		read := srv.wrapHandler(api.PathGetSyncInfo, ok)
+		require.Equal(t, http.StatusUnauthorized, doRequest(read, http.MethodGet, api.PathGetSyncInfo+"?api_key=r-key", nil))
+		// the dashboard page and its assets are static and served without key
+		dashboard := srv.wrapHandler(api.PathGetDashboard, ok)
+		require.Equal(t, http.StatusOK, doRequest(dashboard, http.MethodGet, api.PathGetDashboard, nil))
+		assets := srv.wrapHandler(api.PathGetDashboardAssets, ok)
+		require.Equal(t, http.StatusOK, doRequest(assets, http.MethodGet, api.PathGetDashboardAssets+"dashboard.js", nil))
+	})
+	t.Run("cors", func(t *testing.T) {
+		srv := newTestAccessServer(&Config{
+			AnonymousScopes: []string{ScopeRead},
+			CORS:            CORSConfig{AllowedOrigins: []string{"https://explorer.example"}},
+		})
+		h := srv.wrapHandler(api.PathGetSyncInfo, ok)
+
+		req := httptest.NewRequest(http.MethodOptions, api.PathGetSyncInfo, nil)
+		req.Header.Set("Origin", "https://explorer.example")
+		w := httptest.NewRecorder()
+		h(w, req)
+		require.Equal(t, http.StatusNoContent, w.Code)
+		require.Equal(t, "https://explorer.example", w.Header().Get("Access-Control-Allow-Origin"))
+		require.Contains(t, w.Header().Get("Access-Control-Allow-Headers"), "X-API-Key")
+
+		req = httptest.NewRequest(http.MethodGet, api.PathGetSyncInfo, nil)
+		req.Header.Set("Origin", "https://other.example")
+		w = httptest.NewRecorder()
+		h(w, req)
+		require.Equal(t, http.StatusOK, w.Code)
+		require.Empty(t, w.Header().Get("Access-Control-Allow-Origin"))
+	})
+}
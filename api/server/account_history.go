@@ -0,0 +1,239 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+
+	"github.com/lunfardo314/proxima/api"
+	"github.com/lunfardo314/proxima/ledger"
+	"github.com/lunfardo314/proxima/ledger/base"
+	"github.com/lunfardo314/proxima/ledger/multistate"
+	"github.com/lunfardo314/proxima/ledger/transaction"
+	"github.com/lunfardo314/proxima/txstore"
+	"github.com/lunfardo314/proxima/util"
+)
+
+// Account history is collected from the committed transaction partition of the latest reliable branch and,
+// for slots after the LRB, of the latest heaviest branch. Transactions themselves are loaded from the tx store.
+// Transaction IDs are purged from the state after some time, so the history does not go deeper than that.
+// Outputs of the account can only be unlocked by its owner, so the transaction is outgoing
+// if it is signed by the address. Sequencer transactions are not included.
+// To bound the cost of the request, sequencer transaction IDs are skipped before loading transactions,
+// not more than maxAccountHistorySlots are scanned and the scan, the latest slots first, stops after maxRecords
+
+const (
+	defaultMaxAccountHistory = 100
+	maxAccountHistory        = 1000
+	maxAccountHistorySlots   = 1000
+)
+
+func (srv *server) getAccountHistory(w http.ResponseWriter, r *http.Request) {
+	api.SetHeader(w)
+
+	lst, ok := r.URL.Query()["addr"]
+	if !ok || len(lst) != 1 {
+		api.WriteErr(w, "wrong parameter 'addr' in request 'account_history'")
+		return
+	}
+	addr, err := ledger.AddressED25519FromSource(lst[0])
+	if err != nil {
+		api.WriteErr(w, err.Error())
+		return
+	}
+	var since base.Slot
+	if lst, ok = r.URL.Query()["since"]; ok {
+		if len(lst) != 1 {
+			api.WriteErr(w, "wrong parameter 'since' in request 'account_history'")
+			return
+		}
+		s, err := strconv.ParseUint(lst[0], 10, 32)
+		if err != nil {
+			api.WriteErr(w, err.Error())
+			return
+		}
+		since = base.Slot(s)
+	}
+	maxRecords := defaultMaxAccountHistory
+	if lst, ok = r.URL.Query()["max"]; ok {
+		if len(lst) != 1 {
+			api.WriteErr(w, "wrong parameter 'max' in request 'account_history'")
+			return
+		}
+		if maxRecords, err = strconv.Atoi(lst[0]); err != nil {
+			api.WriteErr(w, err.Error())
+			return
+		}
+		if maxRecords <= 0 || maxRecords > maxAccountHistory {
+			maxRecords = maxAccountHistory
+		}
+	}
+
+	var resp *api.AccountHistory
+	err = util.CatchPanicOrError(func() error {
+		var err1 error
+		resp, err1 = srv.accountHistory(addr, since, maxRecords)
+		return err1
+	})
+	if err != nil {
+		api.WriteErr(w, err.Error())
+		return
+	}
+	respBin, err := json.MarshalIndent(resp, "", "  ")
+	if err != nil {
+		api.WriteErr(w, err.Error())
+		return
+	}
+	_, err = w.Write(respBin)
+	util.AssertNoError(err)
+}
+
+func (srv *server) accountHistory(addr ledger.AddressED25519, since base.Slot, maxRecords int) (*api.AccountHistory, error) {
+	lrb := srv.GetLatestReliableBranch()
+	if lrb == nil {
+		return nil, fmt.Errorf("latest reliable branch (LRB) has not been found")
+	}
+	lrbID := lrb.Stem.ID.TransactionID()
+	rdr, err := multistate.NewReadable(srv.StateStore(), lrb.Root)
+	if err != nil {
+		return nil, err
+	}
+	resp := &api.AccountHistory{
+		Account:      addr.Source(),
+		LRBID:        lrbID.StringHex(),
+		Transactions: make([]api.AccountTx, 0),
+	}
+
+	// the latest heaviest branch contains transactions which are not in the LRB yet
+	var rdrLatest *multistate.Readable
+	toSlot := lrbID.Slot()
+	if latest := multistate.FetchLatestBranches(srv.StateStore()); len(latest) > 0 && latest[0].Stem.ID.Slot() > lrbID.Slot() {
+		if rdrLatest, err = multistate.NewReadable(srv.StateStore(), latest[0].Root); err != nil {
+			return nil, err
+		}
+		toSlot = latest[0].Stem.ID.Slot()
+	}
+	if toSlot >= maxAccountHistorySlots {
+		since = max(since, toSlot-maxAccountHistorySlots+1)
+	}
+	resp.SinceSlot = uint32(since)
+
+	// transaction IDs are collected before chain outputs are resolved in the same state,
+	// because the iteration holds the lock of the state reader
+	txids := make([]base.TransactionID, 0)
+	if rdrLatest != nil {
+		txids = committedTxIDs(rdrLatest, max(lrbID.Slot()+1, since), toSlot)
+	}
+	numPending := len(txids)
+	txids = append(txids, committedTxIDs(rdr, since, lrbID.Slot())...)
+
+	lrbState := multistate.MakeSugared(rdr)
+	isSequencer := make(map[base.ChainID]bool)
+	for i, txid := range txids {
+		if len(resp.Transactions) == maxRecords {
+			resp.Truncated = true
+			break
+		}
+		tx, _, err1 := txstore.LoadAndParseTransaction(srv.TxBytesStore(), txid)
+		if err1 != nil {
+			continue
+		}
+		rec, ok := accountTxFromTransaction(tx, addr, func(chainID base.ChainID) bool {
+			ret, already := isSequencer[chainID]
+			if !already {
+				o, err2 := lrbState.GetChainOutput(chainID)
+				ret = err2 == nil && o.Output.IsSequencerOutput()
+				isSequencer[chainID] = ret
+			}
+			return ret
+		})
+		if !ok {
+			continue
+		}
+		rec.Status = api.AccountTxFinal
+		if i < numPending {
+			rec.Status = api.AccountTxPending
+		}
+		resp.Transactions = append(resp.Transactions, *rec)
+	}
+	return resp, nil
+}
+
+// committedTxIDs returns IDs of non-sequencer transactions committed in the state in slots from fromSlot to toSlot,
+// the latest first
+func committedTxIDs(rdr *multistate.Readable, fromSlot, toSlot base.Slot) []base.TransactionID {
+	ret := make([]base.TransactionID, 0)
+	for slot := toSlot; slot >= fromSlot; slot-- {
+		slotTxIDs := make([]base.TransactionID, 0)
+		rdr.IterateKnownCommittedTransactions(func(txid *base.TransactionID, _ base.Slot) bool {
+			// sequencer transactions are not in the history, so they are not loaded
+			if !txid.IsSequencerMilestone() {
+				slotTxIDs = append(slotTxIDs, *txid)
+			}
+			return true
+		}, slot)
+		sort.Slice(slotTxIDs, func(i, j int) bool {
+			return slotTxIDs[j].Timestamp().Before(slotTxIDs[i].Timestamp())
+		})
+		ret = append(ret, slotTxIDs...)
+		if slot == 0 {
+			break
+		}
+	}
+	return ret
+}
+
+// accountTxFromTransaction returns record of the transaction in the history of the account or false if transaction
+// does not touch it. Sequencer transactions are not included
+func accountTxFromTransaction(tx *transaction.Transaction, addr ledger.AddressED25519, isSequencer func(chainID base.ChainID) bool) (*api.AccountTx, bool) {
+	if tx.IsSequencerTransaction() {
+		return nil, false
+	}
+	var received uint64
+	others := make([]*ledger.Output, 0)
+	tx.ForEachProducedOutput(func(_ byte, o *ledger.Output, _ base.OutputID) bool {
+		if ledger.BelongsToAccount(o.Lock(), addr) {
+			received += o.Amount()
+		} else {
+			others = append(others, o)
+		}
+		return true
+	})
+
+	ret := &api.AccountTx{
+		TxID:           tx.IDStringHex(),
+		Slot:           uint32(tx.Slot()),
+		Counterparties: make([]string, 0),
+	}
+	sender := tx.SenderAddress()
+	if !ledger.EqualAccountables(sender, addr) {
+		if received == 0 {
+			return nil, false
+		}
+		ret.Direction = api.AccountTxIn
+		ret.Amount = received
+		ret.Counterparties = append(ret.Counterparties, sender.Source())
+		return ret, true
+	}
+
+	for _, o := range others {
+		// tag-along output is the plain chain lock of the sequencer
+		if cl, ok := o.Lock().(ledger.ChainLock); ok && isSequencer(cl.ChainID()) {
+			if _, idx := o.ChainConstraint(); idx == 0xff {
+				ret.Fee += o.Amount()
+				continue
+			}
+		}
+		ret.Amount += o.Amount()
+		ret.Counterparties = append(ret.Counterparties, o.Lock().String())
+	}
+	if len(ret.Counterparties) > 0 {
+		ret.Direction = api.AccountTxOut
+	} else {
+		ret.Direction = api.AccountTxSelf
+		ret.Amount = received
+	}
+	return ret, true
+}
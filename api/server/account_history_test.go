@@ -0,0 +1,123 @@
+package server
+
+import (
+	"crypto/ed25519"
+	"testing"
+
+	"github.com/lunfardo314/proxima/api"
+	"github.com/lunfardo314/proxima/ledger"
+	"github.com/lunfardo314/proxima/ledger/base"
+	"github.com/lunfardo314/proxima/ledger/transaction"
+	"github.com/lunfardo314/proxima/ledger/txbuilder"
+	"github.com/lunfardo314/proxima/util/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+// makeAccountTestTx makes transaction which consumes one output of the sender and produces outputs.
+// The transaction is parsed but not validated
+func makeAccountTestTx(t *testing.T, senderKey ed25519.PrivateKey, sequencerOutputIndex byte, outs ...*ledger.Output) *transaction.Transaction {
+	total := uint64(0)
+	for _, o := range outs {
+		total += o.Amount()
+	}
+	in := ledger.NewOutput(func(o *ledger.OutputBuilder) {
+		o.WithAmount(total).WithLock(ledger.AddressED25519FromPrivateKey(senderKey))
+	})
+	oid, err := base.NewOutputID(base.RandomTransactionID(false, 1), 0)
+	require.NoError(t, err)
+
+	txb := txbuilder.New()
+	_, err = txb.ConsumeOutput(in, oid)
+	require.NoError(t, err)
+	_, err = txb.ProduceOutputs(outs...)
+	require.NoError(t, err)
+	txb.PutSignatureUnlock(0)
+	txb.TransactionData.SequencerOutputIndex = sequencerOutputIndex
+	txb.TransactionData.Timestamp = base.NewLedgerTime(10, 1)
+	txb.SignED25519(senderKey)
+	tx, err := txb.Transaction()
+	require.NoError(t, err)
+	return tx
+}
+
+func TestAccountTxFromTransaction(t *testing.T) {
+	privKey := testutil.GetTestingPrivateKey(1)
+	addr := ledger.AddressED25519FromPrivateKey(privKey)
+	otherKey := testutil.GetTestingPrivateKey(2)
+	otherAddr := ledger.AddressED25519FromPrivateKey(otherKey)
+	seqID := base.RandomChainID()
+	isSequencer := func(chainID base.ChainID) bool {
+		return chainID == seqID
+	}
+	outputTo := func(lock ledger.Lock, amount uint64) *ledger.Output {
+		return ledger.NewOutput(func(o *ledger.OutputBuilder) {
+			o.WithAmount(amount).WithLock(lock)
+		})
+	}
+
+	t.Run("incoming", func(t *testing.T) {
+		tx := makeAccountTestTx(t, otherKey, 0xff, outputTo(addr, 1000), outputTo(otherAddr, 5000))
+		rec, ok := accountTxFromTransaction(tx, addr, isSequencer)
+		require.True(t, ok)
+		require.Equal(t, api.AccountTxIn, rec.Direction)
+		require.EqualValues(t, 1000, rec.Amount)
+		require.EqualValues(t, 0, rec.Fee)
+		require.Equal(t, []string{otherAddr.Source()}, rec.Counterparties)
+	})
+	t.Run("not touching the account", func(t *testing.T) {
+		tx := makeAccountTestTx(t, otherKey, 0xff, outputTo(otherAddr, 5000))
+		_, ok := accountTxFromTransaction(tx, addr, isSequencer)
+		require.False(t, ok)
+	})
+	t.Run("outgoing", func(t *testing.T) {
+		tx := makeAccountTestTx(t, privKey, 0xff, outputTo(otherAddr, 1000), outputTo(addr, 5000))
+		rec, ok := accountTxFromTransaction(tx, addr, isSequencer)
+		require.True(t, ok)
+		require.Equal(t, api.AccountTxOut, rec.Direction)
+		require.EqualValues(t, 1000, rec.Amount)
+		require.EqualValues(t, 0, rec.Fee)
+		require.Equal(t, []string{otherAddr.String()}, rec.Counterparties)
+	})
+	t.Run("tag-along is excluded", func(t *testing.T) {
+		tx := makeAccountTestTx(t, privKey, 0xff,
+			outputTo(otherAddr, 1000),
+			outputTo(ledger.ChainLockFromChainID(seqID), 500),
+			outputTo(addr, 5000),
+		)
+		rec, ok := accountTxFromTransaction(tx, addr, isSequencer)
+		require.True(t, ok)
+		require.Equal(t, api.AccountTxOut, rec.Direction)
+		require.EqualValues(t, 1000, rec.Amount)
+		require.EqualValues(t, 500, rec.Fee)
+		require.Equal(t, []string{otherAddr.String()}, rec.Counterparties)
+	})
+	t.Run("chain lock of not a sequencer is a counterparty", func(t *testing.T) {
+		chainLock := ledger.ChainLockFromChainID(base.RandomChainID())
+		tx := makeAccountTestTx(t, privKey, 0xff, outputTo(chainLock, 500), outputTo(addr, 5000))
+		rec, ok := accountTxFromTransaction(tx, addr, isSequencer)
+		require.True(t, ok)
+		require.Equal(t, api.AccountTxOut, rec.Direction)
+		require.EqualValues(t, 500, rec.Amount)
+		require.EqualValues(t, 0, rec.Fee)
+		require.Equal(t, []string{chainLock.String()}, rec.Counterparties)
+	})
+	t.Run("self", func(t *testing.T) {
+		tx := makeAccountTestTx(t, privKey, 0xff, outputTo(addr, 1000), outputTo(addr, 5000))
+		rec, ok := accountTxFromTransaction(tx, addr, isSequencer)
+		require.True(t, ok)
+		require.Equal(t, api.AccountTxSelf, rec.Direction)
+		require.EqualValues(t, 6000, rec.Amount)
+		require.EqualValues(t, 0, len(rec.Counterparties))
+	})
+	t.Run("sequencer transaction", func(t *testing.T) {
+		seqOut := ledger.NewOutput(func(o *ledger.OutputBuilder) {
+			o.WithAmount(5000).WithLock(addr)
+			chainIdx := o.MustPushConstraint(ledger.NewChainConstraint(seqID, 0, 0, 0).Bytes())
+			o.MustPushConstraint(ledger.NewSequencerConstraint(chainIdx).Bytes())
+		})
+		tx := makeAccountTestTx(t, privKey, 0, seqOut, outputTo(otherAddr, 1000))
+		require.True(t, tx.IsSequencerTransaction())
+		_, ok := accountTxFromTransaction(tx, addr, isSequencer)
+		require.False(t, ok)
+	})
+}
@@ -0,0 +1,123 @@
+package server
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/lunfardo314/proxima/api"
+	"github.com/lunfardo314/proxima/ledger"
+	"github.com/lunfardo314/proxima/ledger/base"
+	"github.com/lunfardo314/proxima/ledger/multistate"
+	"github.com/lunfardo314/proxima/ledger/transaction"
+	"github.com/lunfardo314/proxima/txstore"
+	"github.com/lunfardo314/proxima/util"
+)
+
+// Chain history starts from the chain output in the latest reliable branch and follows predecessor inputs
+// of the chain constraint. Transactions are loaded from the tx store, so the history ends either at the origin
+// of the chain or at the first transaction which is not in the store (for example, before the snapshot)
+
+const (
+	defaultMaxChainHistory = 100
+	maxChainHistory        = 1000
+)
+
+func (srv *server) getChainHistory(w http.ResponseWriter, r *http.Request) {
+	api.SetHeader(w)
+
+	lst, ok := r.URL.Query()["chainid"]
+	if !ok || len(lst) != 1 {
+		api.WriteErr(w, "wrong parameter 'chainid' in request 'chain_history'")
+		return
+	}
+	chainID, err := base.ChainIDFromHexString(lst[0])
+	if err != nil {
+		api.WriteErr(w, err.Error())
+		return
+	}
+	maxRecords := defaultMaxChainHistory
+	if lst, ok = r.URL.Query()["max"]; ok {
+		if len(lst) != 1 {
+			api.WriteErr(w, "wrong parameter 'max' in request 'chain_history'")
+			return
+		}
+		if maxRecords, err = strconv.Atoi(lst[0]); err != nil {
+			api.WriteErr(w, err.Error())
+			return
+		}
+		if maxRecords <= 0 || maxRecords > maxChainHistory {
+			maxRecords = maxChainHistory
+		}
+	}
+
+	resp := &api.ChainHistory{
+		ChainID:     chainID.StringHex(),
+		Transitions: make([]api.ChainTransition, 0),
+	}
+	var tip base.OutputID
+	err = srv.withLRB(func(rdr multistate.SugaredStateReader) error {
+		o, err1 := rdr.GetChainOutput(chainID)
+		if err1 != nil {
+			return err1
+		}
+		tip = o.ID
+		lrbid := rdr.GetStemOutput().ID.TransactionID()
+		resp.LRBID = lrbid.StringHex()
+		return nil
+	})
+	if err != nil {
+		api.WriteErr(w, err.Error())
+		return
+	}
+
+	resp.Truncated = true
+	err = txstore.IterateChainBackwards(srv.TxBytesStore(), tip, func(tx *transaction.Transaction, o *ledger.OutputWithID, cc *ledger.ChainConstraint) bool {
+		resp.Transitions = append(resp.Transitions, chainTransition(tx, o))
+		if cc.IsOrigin() {
+			resp.Truncated = false
+		}
+		return len(resp.Transitions) < maxRecords
+	})
+	// the chain can't be followed beyond transactions in the store. The history is truncated then
+	if err != nil && !errors.Is(err, txstore.ErrTransactionNotFound) {
+		api.WriteErr(w, err.Error())
+		return
+	}
+	if len(resp.Transitions) == 0 {
+		api.WriteErr(w, "transaction of the chain output "+tip.StringShort()+" is not in the tx store")
+		return
+	}
+
+	respBin, err := json.MarshalIndent(resp, "", "  ")
+	if err != nil {
+		api.WriteErr(w, err.Error())
+		return
+	}
+	_, err = w.Write(respBin)
+	util.AssertNoError(err)
+}
+
+func chainTransition(tx *transaction.Transaction, o *ledger.OutputWithID) api.ChainTransition {
+	ret := api.ChainTransition{
+		TxID:      tx.IDStringHex(),
+		OutputID:  o.ID.StringHex(),
+		Slot:      uint32(o.ID.Slot()),
+		Amount:    o.Output.Amount(),
+		Inflation: o.Output.Inflation(),
+		Lock:      o.Output.Lock().String(),
+	}
+	if !o.Output.IsSequencerOutput() {
+		return ret
+	}
+	if md := ledger.ParseMilestoneData(o.Output); md != nil {
+		ret.MilestoneData = &api.MilestoneData{
+			Name:         md.Name,
+			MinimumFee:   md.MinimumFee,
+			ChainHeight:  md.ChainHeight,
+			BranchHeight: md.BranchHeight,
+		}
+	}
+	return ret
+}
@@ -0,0 +1,597 @@
+// Dashboard of the Proxima node. Polls the node API and draws the DAG from the DAG vertex stream.
+// Everything is served by the node itself, nothing is loaded from outside
+"use strict";
+
+const pollingPeriod = 5000;     // ms
+const maxMainChain = 30;        // branches in the main chain table and coverage chart
+const maxRecentTx = 30;         // rows in the recent transactions table
+const maxRejections = 15;       // rows in the recent rejections table
+const dagKeepSlots = 6;         // slots kept in the DAG view
+const healthyFraction = 0.5;    // global.FractionHealthyBranch
+const maxLagSlots = 3;          // LRB further behind the current slot is a warning
+
+const ticksPerSlot = 128;
+
+const apiKeyInput = document.getElementById("apikey");
+apiKeyInput.value = localStorage.getItem("proxima_api_key") || "";
+// the key can be given in the link to the dashboard: /dashboard?api_key=...
+const apiKeyFromURL = new URLSearchParams(location.search).get("api_key");
+if (apiKeyFromURL) {
+    apiKeyInput.value = apiKeyFromURL;
+    localStorage.setItem("proxima_api_key", apiKeyFromURL);
+    history.replaceState(null, "", location.pathname);
+}
+apiKeyInput.addEventListener("change", () => {
+    localStorage.setItem("proxima_api_key", apiKeyInput.value);
+    pollAll();
+});
+
+// ------------------------------------------------------------------ helpers
+
+async function getJSON(path) {
+    const headers = {};
+    if (apiKeyInput.value !== "") {
+        headers["X-API-Key"] = apiKeyInput.value;
+    }
+    const resp = await fetch(path, {headers});
+    const data = await resp.json();
+    if (data.error) {
+        throw new Error(path + ": " + data.error);
+    }
+    return data;
+}
+
+function el(tag, attrs, ...children) {
+    const e = document.createElement(tag);
+    for (const [k, v] of Object.entries(attrs || {})) {
+        e.setAttribute(k, v);
+    }
+    for (const c of children) {
+        e.append(c === undefined || c === null ? "" : c);
+    }
+    return e;
+}
+
+function row(label, ...value) {
+    return el("div", {class: "row"}, el("span", {class: "label"}, label), el("span", {}, ...value));
+}
+
+function fillTable(id, rows) {
+    const tbody = document.querySelector("#" + id + " tbody");
+    tbody.replaceChildren(...rows);
+}
+
+function td(v, cls) {
+    return el("td", cls ? {class: cls} : {}, v);
+}
+
+function showError(id, err) {
+    document.getElementById(id).replaceChildren(el("span", {class: "bad"}, String(err)));
+}
+
+// thousands separators, same as util.Th
+function th(n) {
+    if (n === undefined || n === null) {
+        return "";
+    }
+    return BigInt(n).toLocaleString("en-US").replaceAll(",", "_");
+}
+
+function since(unixNano) {
+    if (!unixNano) {
+        return "never";
+    }
+    const sec = (Date.now() - unixNano / 1e6) / 1000;
+    if (sec < 60) {
+        return sec.toFixed(1) + "s ago";
+    }
+    if (sec < 3600) {
+        return (sec / 60).toFixed(1) + "m ago";
+    }
+    return (sec / 3600).toFixed(1) + "h ago";
+}
+
+function duration(ns) {
+    const ms = ns / 1e6;
+    if (Math.abs(ms) < 1000) {
+        return ms.toFixed(1) + "ms";
+    }
+    return (ms / 1000).toFixed(2) + "s";
+}
+
+function bytes(n) {
+    const units = ["B", "KB", "MB", "GB", "TB"];
+    let i = 0;
+    while (n >= 1024 && i < units.length - 1) {
+        n /= 1024;
+        i++;
+    }
+    return n.toFixed(i === 0 ? 0 : 1) + units[i];
+}
+
+function short(hex) {
+    return hex ? hex.substring(0, 12) + ".." : "";
+}
+
+// txIDInfo parses hex-encoded transaction ID: 4 bytes of slot, then tick << 1 | sequencer flag
+function txIDInfo(hex) {
+    const slot = parseInt(hex.substring(0, 8), 16);
+    const tickByte = parseInt(hex.substring(8, 10), 16);
+    const tick = tickByte >> 1;
+    const seq = (tickByte & 1) !== 0;
+    return {slot, tick, seq, branch: seq && tick === 0};
+}
+
+// txIDShort renders transaction ID in the same form as base.TransactionID.StringShort
+function txIDShort(hex) {
+    const t = txIDInfo(hex);
+    const flag = t.branch ? "br" : (t.seq ? "sq" : "");
+    return "[" + t.slot + "|" + t.tick + flag + "]" + hex.substring(10, 22) + "..";
+}
+
+function txLink(hex) {
+    return el("a", {class: "mono", href: "/txapi/v1/get_parsed_transaction?txid=" + hex, target: "_blank", title: hex}, txIDShort(hex));
+}
+
+// ------------------------------------------------------------------ node, sync, LRB, sequencer
+
+const state = {
+    currentSlot: 0,
+    synced: undefined,
+    lagSlots: undefined,
+    lrbHealthy: undefined,
+    fork: undefined,
+    perSequencer: {},
+    ownSequencer: "",
+};
+
+async function pollNodeInfo() {
+    try {
+        const d = await getJSON("/api/v1/node_info");
+        state.ownSequencer = d.sequencers || "";
+        const clock = el("span", {class: d.clock_skew_too_big ? "bad" : ""},
+            "offset " + duration(d.clock_offset_ns) + ", correction " + duration(d.clock_correction_ns) +
+            (d.clock_skew_too_big ? ", skew too big" : ""));
+        document.getElementById("node-info").replaceChildren(
+            row("Node ID:", el("span", {class: "mono"}, d.id)),
+            row("Version:", d.version + " " + short(d.commit_hash) + " " + (d.commit_time || "")),
+            row("Alive peers:", d.num_static_peers + " static, " + d.num_dynamic_alive + " dynamic"),
+            row("Sequencer:", el("span", {class: "mono"}, d.sequencers || "none")),
+            row("Clock:", clock),
+        );
+    } catch (err) {
+        showError("node-info", err);
+    }
+}
+
+async function pollSyncInfo() {
+    try {
+        const d = await getJSON("/api/v1/sync_info");
+        state.synced = d.synced;
+        state.currentSlot = d.current_slot;
+        state.lagSlots = d.current_slot - d.lrb_slot;
+        state.perSequencer = d.per_sequencer || {};
+        state.fork = d.fork;
+        const rows = [
+            row("Synced:", el("span", {class: d.synced ? "ok" : "bad"}, String(d.synced))),
+            row("Slots (LRB / current):", d.lrb_slot + " / " + d.current_slot + " (" + state.lagSlots + " behind)"),
+            row("Ledger coverage:", th(d.ledger_coverage)),
+        ];
+        if (d.fork) {
+            const f = d.fork;
+            const bad = f.detected || f.on_minority;
+            let text = bad ? "detected: " + f.detected + ", on minority: " + f.on_minority +
+                ", since slot " + f.since_slot + " (" + f.num_slots + " slots)" : "none";
+            if (f.competing && f.competing.length > 0) {
+                text += ", " + f.competing.length + " competing branch(es)";
+            }
+            rows.push(row("Fork:", el("span", {class: bad ? "bad" : "ok"}, text)));
+        }
+        document.getElementById("sync-info").replaceChildren(...rows);
+    } catch (err) {
+        state.synced = undefined;
+        showError("sync-info", err);
+    }
+}
+
+async function pollLRB() {
+    try {
+        const d = await getJSON("/api/v1/get_latest_reliable_branch");
+        const r = d.root_record;
+        state.lrbHealthy = r.coverage_delta > healthyFraction * r.supply;
+        document.getElementById("lrb-info").replaceChildren(
+            row("Branch:", txLink(d.branch_id)),
+            row("Sequencer:", el("span", {class: "mono"}, short(r.sequencer_id))),
+            row("Coverage delta:", th(r.coverage_delta) + " (" + (100 * r.coverage_delta / r.supply).toFixed(1) + "% of supply)"),
+            row("Healthy:", el("span", {class: state.lrbHealthy ? "ok" : "bad"}, String(state.lrbHealthy))),
+            row("Supply:", th(r.supply)),
+            row("Slot inflation:", th(r.slot_inflation)),
+        );
+    } catch (err) {
+        state.lrbHealthy = undefined;
+        showError("lrb-info", err);
+    }
+}
+
+async function pollSequencerStats() {
+    try {
+        const d = await getJSON("/api/v1/sequencer_stats");
+        if (!d.sequencer_id) {
+            document.getElementById("seq-stats").replaceChildren(el("span", {class: "muted"}, "sequencer is not running on the node"));
+            return;
+        }
+        const proposals = Object.keys(d.proposals || {}).sort().map(s =>
+            s + ": " + ((d.best_proposals || {})[s] || 0) + "/" + d.proposals[s]).join(", ");
+        document.getElementById("seq-stats").replaceChildren(
+            row("Sequencer ID:", el("span", {class: "mono"}, short(d.sequencer_id))),
+            row("Milestones:", th(d.milestones) + ", branches: " + th(d.branches)),
+            row("Own milestones:", th(d.own_milestones)),
+            row("Backlog:", th(d.backlog_size) + " outputs"),
+            row("Proposals best/all:", proposals || "none"),
+        );
+    } catch (err) {
+        showError("seq-stats", err);
+    }
+}
+
+function updateHealth() {
+    const badge = document.getElementById("health");
+    let cls, text;
+    if (state.synced === undefined || state.lrbHealthy === undefined) {
+        cls = "unknown";
+        text = "node not reachable";
+    } else if (!state.synced || !state.lrbHealthy || (state.fork && state.fork.on_minority)) {
+        cls = "unhealthy";
+        text = !state.synced ? "not synced" : (!state.lrbHealthy ? "LRB not healthy" : "on minority fork");
+    } else if (state.lagSlots > maxLagSlots || (state.fork && state.fork.detected)) {
+        cls = "warning";
+        text = state.lagSlots > maxLagSlots ? "LRB " + state.lagSlots + " slots behind" : "fork detected";
+    } else {
+        cls = "healthy";
+        text = "healthy";
+    }
+    badge.className = "badge " + cls;
+    badge.textContent = text;
+    document.getElementById("updated").textContent = "updated " + new Date().toLocaleTimeString();
+}
+
+// ------------------------------------------------------------------ sequencer tips
+
+async function pollTips() {
+    try {
+        const d = await getJSON("/api/v1/last_known_milestones");
+        const seqs = d.sequencers || {};
+        const ids = Object.keys(seqs).sort((a, b) => seqs[b].last_activity_unix_nano - seqs[a].last_activity_unix_nano);
+        let maxCoverage = 1;
+        for (const id of ids) {
+            const ps = state.perSequencer[id];
+            if (ps && ps.ledger_coverage > maxCoverage) {
+                maxCoverage = ps.ledger_coverage;
+            }
+        }
+        fillTable("tips", ids.map(id => {
+            const tip = seqs[id];
+            const ps = state.perSequencer[id];
+            const coverage = ps ? ps.ledger_coverage : 0;
+            const width = Math.round(120 * coverage / maxCoverage);
+            return el("tr", {},
+                td(el("span", {class: "mono", title: id}, short(id) + (id === state.ownSequencer ? " (own)" : ""))),
+                td(th(tip.milestone_count), "num"),
+                td(txLink(tip.latest_milestone_txid)),
+                td(since(tip.last_activity_unix_nano)),
+                td(ps ? el("span", {}, el("span", {class: "bar", style: "width:" + width + "px"}), " " + th(coverage)) : ""),
+                td(ps ? el("span", {class: ps.synced ? "ok" : "bad"}, String(ps.synced)) : ""),
+            );
+        }));
+    } catch (err) {
+        fillTable("tips", [el("tr", {}, el("td", {colspan: 6, class: "bad"}, String(err)))]);
+    }
+}
+
+// ------------------------------------------------------------------ main chain and coverage chart
+
+async function pollMainChain() {
+    try {
+        const d = await getJSON("/api/v1/get_mainchain?max=" + maxMainChain);
+        const branches = d.branches || [];
+        fillTable("mainchain", branches.map(b => {
+            const r = b.data.root;
+            return el("tr", {},
+                td(txLink(b.id)),
+                td(el("span", {class: "mono", title: r.sequencer_id}, short(r.sequencer_id))),
+                td(th(r.coverage_delta), "num"),
+                td(th(b.data.branch_inflation), "num"),
+                td(th(r.supply), "num"),
+            );
+        }));
+        drawCoverageChart(branches);
+    } catch (err) {
+        fillTable("mainchain", [el("tr", {}, el("td", {colspan: 5, class: "bad"}, String(err)))]);
+    }
+}
+
+function resizeCanvas(canvas) {
+    const ratio = window.devicePixelRatio || 1;
+    const width = canvas.clientWidth;
+    const height = canvas.clientHeight;
+    if (canvas.width !== Math.round(width * ratio) || canvas.height !== Math.round(height * ratio)) {
+        canvas.width = Math.round(width * ratio);
+        canvas.height = Math.round(height * ratio);
+    }
+    const ctx = canvas.getContext("2d");
+    ctx.setTransform(ratio, 0, 0, ratio, 0, 0);
+    ctx.clearRect(0, 0, width, height);
+    return {ctx, width, height};
+}
+
+function drawCoverageChart(branches) {
+    const {ctx, width, height} = resizeCanvas(document.getElementById("coverage-chart"));
+    const points = branches.map(b => ({
+        slot: txIDInfo(b.id).slot,
+        value: b.data.root.supply > 0 ? b.data.root.coverage_delta / b.data.root.supply : 0,
+    })).sort((a, b) => a.slot - b.slot);
+    if (points.length === 0) {
+        return;
+    }
+    const pad = {left: 50, right: 20, top: 10, bottom: 25};
+    const minSlot = points[0].slot;
+    const maxSlot = Math.max(points[points.length - 1].slot, minSlot + 1);
+    const maxValue = Math.max(1, ...points.map(p => p.value));
+    const x = slot => pad.left + (width - pad.left - pad.right) * (slot - minSlot) / (maxSlot - minSlot);
+    const y = v => height - pad.bottom - (height - pad.top - pad.bottom) * v / maxValue;
+
+    // axes and grid
+    ctx.strokeStyle = "#ddd";
+    ctx.fillStyle = "#888";
+    ctx.font = "11px sans-serif";
+    for (let v = 0; v <= maxValue + 1e-9; v += 0.25) {
+        ctx.beginPath();
+        ctx.moveTo(pad.left, y(v));
+        ctx.lineTo(width - pad.right, y(v));
+        ctx.stroke();
+        ctx.fillText((100 * v).toFixed(0) + "%", 8, y(v) + 4);
+    }
+    const slotStep = Math.max(1, Math.ceil((maxSlot - minSlot) / 10));
+    for (let s = minSlot; s <= maxSlot; s += slotStep) {
+        ctx.fillText(String(s), x(s) - 15, height - 8);
+    }
+
+    // threshold of the healthy branch
+    ctx.strokeStyle = "#c33";
+    ctx.setLineDash([5, 5]);
+    ctx.beginPath();
+    ctx.moveTo(pad.left, y(healthyFraction));
+    ctx.lineTo(width - pad.right, y(healthyFraction));
+    ctx.stroke();
+    ctx.setLineDash([]);
+
+    // coverage
+    ctx.strokeStyle = "#2f7ec1";
+    ctx.lineWidth = 2;
+    ctx.beginPath();
+    points.forEach((p, i) => i === 0 ? ctx.moveTo(x(p.slot), y(p.value)) : ctx.lineTo(x(p.slot), y(p.value)));
+    ctx.stroke();
+    ctx.lineWidth = 1;
+    ctx.fillStyle = "#2f7ec1";
+    for (const p of points) {
+        ctx.beginPath();
+        ctx.arc(x(p.slot), y(p.value), 3, 0, 2 * Math.PI);
+        ctx.fill();
+    }
+}
+
+// ------------------------------------------------------------------ peers
+
+async function pollPeers() {
+    try {
+        const d = await getJSON("/api/v1/peers_info");
+        const peers = (d.peers || []).sort((a, b) => a.id.localeCompare(b.id));
+        const alive = peers.filter(p => p.is_alive).length;
+        document.getElementById("peers-summary").textContent =
+            peers.length + " peers, " + alive + " alive, " + Object.keys(d.blacklist || {}).length + " blacklisted";
+        fillTable("peers", peers.map(p => el("tr", {},
+            td(el("span", {class: "mono", title: (p.multiAddresses || []).join(", ")}, p.id)),
+            td(el("span", {class: p.is_alive ? "ok" : "bad"}, String(p.is_alive))),
+            td(String(p.is_static)),
+            td(since(p.when_added)),
+            td(since(p.last_heartbeat_received)),
+            td(duration(p.hb_differences_quartiles[1]), "num"),
+            td(duration(p.clock_differences_quartiles[1]), "num"),
+            td(p.num_incoming_hb + " / " + p.num_incoming_tx + " / " + p.num_incoming_pull, "num"),
+            td(bytes(p.bytes_in || 0) + " / " + bytes(p.bytes_out || 0), "num"),
+        )));
+    } catch (err) {
+        fillTable("peers", [el("tr", {}, el("td", {colspan: 9, class: "bad"}, String(err)))]);
+    }
+}
+
+// ------------------------------------------------------------------ rejections
+
+async function pollRejections() {
+    try {
+        const d = await getJSON("/api/v1/tx_rejections?max=" + maxRejections);
+        fillTable("rejections", (d.rejections || []).map(r => el("tr", {},
+            td(el("span", {class: "mono", title: r.txid}, txIDShort(r.txid))),
+            td(r.source),
+            td(el("span", {title: r.reason}, r.reason.length > 80 ? r.reason.substring(0, 80) + ".." : r.reason)),
+        )));
+    } catch (err) {
+        fillTable("rejections", [el("tr", {}, el("td", {colspan: 3, class: "bad"}, String(err)))]);
+    }
+}
+
+// ------------------------------------------------------------------ live DAG and recent transactions
+
+const dag = {
+    vertices: new Map(),    // txid -> vertex
+    recent: [],             // the latest first
+    lanes: new Map(),       // sequencer ID -> lane index
+    received: 0,
+    connected: false,
+};
+
+function laneOf(v) {
+    if (!v.seqid) {
+        return -1;
+    }
+    if (!dag.lanes.has(v.seqid)) {
+        dag.lanes.set(v.seqid, dag.lanes.size);
+    }
+    return dag.lanes.get(v.seqid);
+}
+
+function onVertex(v) {
+    if (!v.in) {
+        // deletion message has only ID
+        dag.vertices.delete(v.id);
+        return;
+    }
+    const info = txIDInfo(v.id);
+    dag.received++;
+    dag.vertices.set(v.id, {id: v.id, info, lane: laneOf(v), v});
+    if (!dag.recent.some(r => r.id === v.id)) {
+        dag.recent.unshift(v);
+        dag.recent.sort((a, b) => b.id.localeCompare(a.id));
+        dag.recent.length = Math.min(dag.recent.length, maxRecentTx);
+    }
+    // keep only the latest slots
+    let maxSlot = 0;
+    for (const x of dag.vertices.values()) {
+        maxSlot = Math.max(maxSlot, x.info.slot);
+    }
+    for (const [id, x] of dag.vertices) {
+        if (x.info.slot + dagKeepSlots <= maxSlot) {
+            dag.vertices.delete(id);
+        }
+    }
+}
+
+function connectDAGStream() {
+    const proto = location.protocol === "https:" ? "wss://" : "ws://";
+    // the browser can't send the API key header with the web socket, so the key is sent as the query parameter
+    let url = proto + location.host + "/wsapi/v1/dag_vertex_stream";
+    if (apiKeyInput.value !== "") {
+        url += "?api_key=" + encodeURIComponent(apiKeyInput.value);
+    }
+    const ws = new WebSocket(url);
+    ws.onopen = () => {
+        dag.connected = true;
+    };
+    ws.onmessage = e => {
+        try {
+            onVertex(JSON.parse(e.data));
+        } catch (err) {
+            console.error("wrong message from the DAG vertex stream:", err);
+        }
+    };
+    ws.onclose = () => {
+        dag.connected = false;
+        setTimeout(connectDAGStream, pollingPeriod);
+    };
+}
+
+function updateRecentTx() {
+    fillTable("recent-tx", dag.recent.map(v => {
+        const info = txIDInfo(v.id);
+        return el("tr", {},
+            td(txLink(v.id)),
+            td(info.branch ? "branch" : (info.seq ? "sequencer" : "transfer")),
+            td(th(v.a), "num"),
+            td(th(v.i || 0), "num"),
+            td(String(v.in.length), "num"),
+        );
+    }));
+}
+
+function drawDAG() {
+    const {ctx, width, height} = resizeCanvas(document.getElementById("dag"));
+    document.getElementById("dag-summary").textContent = dag.connected ?
+        dag.vertices.size + " vertices in view, " + dag.received + " received, " + dag.lanes.size + " sequencers" :
+        "not connected to the DAG vertex stream";
+    if (dag.vertices.size === 0) {
+        return;
+    }
+    let maxTime = 0;
+    for (const x of dag.vertices.values()) {
+        maxTime = Math.max(maxTime, x.info.slot * ticksPerSlot + x.info.tick);
+    }
+    const minTime = maxTime - dagKeepSlots * ticksPerSlot;
+    const numLanes = Math.max(1, dag.lanes.size);
+    const seqHeight = height * 0.7;
+    const pos = new Map();
+    for (const x of dag.vertices.values()) {
+        const t = x.info.slot * ticksPerSlot + x.info.tick;
+        const px = 20 + (width - 40) * (t - minTime) / (maxTime - minTime);
+        let py;
+        if (x.lane >= 0) {
+            py = 15 + (seqHeight - 30) * (x.lane % numLanes + 0.5) / numLanes;
+        } else {
+            // non-sequencer transactions are spread below the sequencer lanes by the hash
+            py = seqHeight + (height - seqHeight - 15) * (parseInt(x.id.substring(10, 14), 16) / 0xffff);
+        }
+        pos.set(x.id, [px, py]);
+    }
+
+    // slot boundaries
+    ctx.strokeStyle = "#eee";
+    ctx.fillStyle = "#aaa";
+    ctx.font = "11px sans-serif";
+    const firstSlot = Math.ceil(minTime / ticksPerSlot);
+    for (let s = firstSlot; s * ticksPerSlot <= maxTime; s++) {
+        const px = 20 + (width - 40) * (s * ticksPerSlot - minTime) / (maxTime - minTime);
+        ctx.beginPath();
+        ctx.moveTo(px, 0);
+        ctx.lineTo(px, height);
+        ctx.stroke();
+        ctx.fillText(String(s), px + 3, height - 4);
+    }
+
+    // edges
+    const drawEdges = (list, dashed) => {
+        ctx.setLineDash(dashed ? [3, 3] : []);
+        for (const x of dag.vertices.values()) {
+            const from = pos.get(x.id);
+            for (const dep of list(x.v) || []) {
+                const to = pos.get(dep);
+                if (!to) {
+                    continue;
+                }
+                ctx.beginPath();
+                ctx.moveTo(from[0], from[1]);
+                ctx.lineTo(to[0], to[1]);
+                ctx.stroke();
+            }
+        }
+    };
+    ctx.strokeStyle = "rgba(100, 100, 100, 0.35)";
+    drawEdges(v => v.in, false);
+    ctx.strokeStyle = "rgba(47, 126, 193, 0.5)";
+    drawEdges(v => v.endorse, true);
+    ctx.setLineDash([]);
+
+    // vertices
+    for (const x of dag.vertices.values()) {
+        const [px, py] = pos.get(x.id);
+        ctx.fillStyle = x.info.branch ? "#c33" : (x.info.seq ? "#2f7ec1" : "#999");
+        ctx.beginPath();
+        ctx.arc(px, py, x.info.branch ? 6 : (x.info.seq ? 4 : 3), 0, 2 * Math.PI);
+        ctx.fill();
+    }
+}
+
+// ------------------------------------------------------------------ main loop
+
+async function pollAll() {
+    await Promise.all([pollNodeInfo(), pollSyncInfo(), pollLRB(), pollSequencerStats()]);
+    updateHealth();
+    await Promise.all([pollTips(), pollMainChain(), pollPeers(), pollRejections()]);
+}
+
+pollAll();
+setInterval(pollAll, pollingPeriod);
+
+connectDAGStream();
+setInterval(() => {
+    updateRecentTx();
+    drawDAG();
+}, 1000);
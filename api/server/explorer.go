@@ -0,0 +1,141 @@
+package server
+
+// explorerHTML is a self-contained API explorer page. It reads the OpenAPI document of the node
+// and lets call each endpoint with parameters. It does not load anything from outside the node
+const explorerHTML = `<!DOCTYPE html>
+<html lang="en">
+<head>
+    <meta charset="UTF-8">
+    <title>Proxima node API explorer</title>
+    <style>
+        body { font-family: sans-serif; margin: 20px; background: #f7f7f7; color: #222; }
+        h1 { font-size: 1.4em; }
+        .toolbar { margin-bottom: 16px; }
+        .toolbar input { width: 360px; }
+        .tag { margin-top: 20px; font-size: 1.1em; font-weight: bold; text-transform: uppercase; color: #555; }
+        .endpoint { background: #fff; border: 1px solid #ddd; border-radius: 4px; margin: 6px 0; }
+        .endpoint summary { cursor: pointer; padding: 8px; }
+        .method { display: inline-block; width: 50px; font-weight: bold; color: #fff; text-align: center; border-radius: 3px; margin-right: 8px; }
+        .get { background: #2f7ec1; }
+        .post { background: #49a35b; }
+        .path { font-family: monospace; font-weight: bold; }
+        .descr { color: #666; margin-left: 8px; }
+        .body { padding: 8px 16px 16px 16px; }
+        .param { margin: 4px 0; }
+        .param label { display: inline-block; width: 140px; font-family: monospace; }
+        .param input { width: 480px; }
+        .required { color: #c33; }
+        pre { background: #272822; color: #f8f8f2; padding: 10px; overflow: auto; max-height: 480px; }
+    </style>
+</head>
+<body>
+<h1 id="title">Proxima node API explorer</h1>
+<div class="toolbar">
+    API key (optional): <input id="apikey" type="password" placeholder="sent in the X-API-Key header">
+    <a href="openapi.json">openapi.json</a>
+</div>
+<div id="endpoints">loading OpenAPI document...</div>
+
+<script>
+const specURL = "openapi.json";
+const apiKeyInput = document.getElementById("apikey");
+apiKeyInput.value = localStorage.getItem("proxima_api_key") || "";
+apiKeyInput.addEventListener("change", () => localStorage.setItem("proxima_api_key", apiKeyInput.value));
+
+function el(tag, attrs, ...children) {
+    const e = document.createElement(tag);
+    for (const [k, v] of Object.entries(attrs || {})) {
+        e.setAttribute(k, v);
+    }
+    for (const c of children) {
+        e.append(c);
+    }
+    return e;
+}
+
+async function call(path, method, inputs, fileInput, out) {
+    const params = new URLSearchParams();
+    for (const [name, input] of inputs) {
+        if (input.value !== "") {
+            params.set(name, input.value);
+        }
+    }
+    const url = path + (params.toString() ? "?" + params.toString() : "");
+    const headers = {};
+    if (apiKeyInput.value) {
+        headers["X-API-Key"] = apiKeyInput.value;
+    }
+    const opts = { method: method.toUpperCase(), headers: headers };
+    if (fileInput && fileInput.files.length > 0) {
+        opts.body = await fileInput.files[0].arrayBuffer();
+    }
+    out.textContent = method.toUpperCase() + " " + url + "\n...";
+    try {
+        const resp = await fetch(url, opts);
+        const text = await resp.text();
+        let body = text;
+        try { body = JSON.stringify(JSON.parse(text), null, 2); } catch (e) {}
+        out.textContent = method.toUpperCase() + " " + url + "\n" + resp.status + " " + resp.statusText + "\n\n" + body;
+    } catch (e) {
+        out.textContent = "request failed: " + e;
+    }
+}
+
+function renderEndpoint(path, method, op) {
+    const inputs = [];
+    const body = el("div", { class: "body" });
+    for (const p of op.parameters || []) {
+        const input = el("input", { placeholder: p.description || "" });
+        if (p.schema && p.schema.enum) {
+            input.setAttribute("placeholder", p.schema.enum.join(" | "));
+        }
+        inputs.push([p.name, input]);
+        const label = el("label", {}, p.name);
+        if (p.required) {
+            label.append(el("span", { class: "required" }, " *"));
+        }
+        body.append(el("div", { class: "param" }, label, input));
+    }
+    let fileInput = null;
+    if (op.requestBody) {
+        fileInput = el("input", { type: "file" });
+        body.append(el("div", { class: "param" }, el("label", {}, "body"), fileInput));
+    }
+    const out = el("pre", {});
+    const button = el("button", {}, "Send");
+    button.addEventListener("click", () => call(path, method, inputs, fileInput, out));
+    body.append(button, out);
+
+    const summary = el("summary", {},
+        el("span", { class: "method " + method }, method.toUpperCase()),
+        el("span", { class: "path" }, path),
+        el("span", { class: "descr" }, op.summary || ""));
+    return el("details", { class: "endpoint" }, summary, body);
+}
+
+async function load() {
+    const container = document.getElementById("endpoints");
+    try {
+        const spec = await (await fetch(specURL)).json();
+        document.getElementById("title").textContent = spec.info.title + " " + spec.info.version;
+        container.textContent = "";
+        for (const tag of spec.tags) {
+            container.append(el("div", { class: "tag" }, tag.name + " - " + tag.description));
+            for (const path of Object.keys(spec.paths).sort()) {
+                for (const [method, op] of Object.entries(spec.paths[path])) {
+                    if ((op.tags || []).includes(tag.name)) {
+                        container.append(renderEndpoint(path, method, op));
+                    }
+                }
+            }
+        }
+    } catch (e) {
+        container.textContent = "failed to load OpenAPI document: " + e;
+    }
+}
+
+load();
+</script>
+</body>
+</html>
+`
@@ -0,0 +1,549 @@
+package server
+
+import (
+	"encoding"
+	"encoding/json"
+	"net/http"
+	"path"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/lunfardo314/proxima/api"
+	"github.com/lunfardo314/proxima/global"
+	"github.com/lunfardo314/proxima/util"
+)
+
+// OpenAPI 3.0 document of the node API. Paths and parameters are described in the apiEndpoints table,
+// schemas of responses are generated from the Go types of the 'api' package by reflection,
+// so JSON structs and the document can't drift apart. Tests check that each handler registered
+// by registerHandlers is described in the table and vice versa
+
+type (
+	apiEndpoint struct {
+		path   string
+		method string
+		// operation ID is used as a name of the method in generated clients. Default is the last element of the path
+		operationID string
+		tag         string
+		summary     string
+		params      []apiParam
+		// content type of the request body. Empty if request has no body
+		requestBody string
+		// value of the response type. Nil for responses which are not described by schema
+		response any
+		// content type of the response. Default is 'application/json'
+		contentType string
+	}
+
+	apiParam struct {
+		name        string
+		description string
+		// 'string' or 'integer'
+		typ      string
+		required bool
+		enum     []string
+	}
+
+	openAPISchemas struct {
+		components map[string]any
+		names      map[reflect.Type]string
+	}
+)
+
+const (
+	openAPITagGeneral = "general"
+	openAPITagTx      = "tx"
+	openAPITagNode    = "node"
+)
+
+var (
+	paramMaxOutputs  = apiParam{name: "max_outputs", typ: "integer", description: "maximum number of returned outputs"}
+	paramSort        = apiParam{name: "sort", typ: "string", description: "sort outputs by output ID", enum: []string{"asc", "desc"}}
+	paramAccountable = apiParam{name: "accountable", typ: "string", required: true,
+		description: "EasyFL source form of the accountable lock constraint, for example 'a(0x....)'"}
+	paramAddr  = apiParam{name: "addr", typ: "string", required: true, description: "ED25519 address in EasyFL source form 'a(0x....)'"}
+	paramTxID  = apiParam{name: "txid", typ: "string", required: true, description: "hex-encoded transaction ID"}
+	paramChain = apiParam{name: "chainid", typ: "string", required: true, description: "hex-encoded chain ID"}
+)
+
+var apiEndpoints = []apiEndpoint{
+	{
+		path: api.PathGetLedgerIDData, method: http.MethodGet, tag: openAPITagGeneral,
+		summary:     "ledger definitions in YAML format",
+		contentType: "application/yaml",
+	},
+	{
+		path: api.PathGetLibraryUpgrades, method: http.MethodGet, tag: openAPITagGeneral,
+		summary:     "schedule of ledger library upgrades committed in the genesis state, in YAML format",
+		contentType: "application/yaml",
+	},
+	{
+		path: api.PathGetAccountOutputs, method: http.MethodGet, tag: openAPITagGeneral,
+		summary:  "outputs of the account in the latest reliable branch",
+		params:   []apiParam{paramAccountable, paramMaxOutputs, paramSort},
+		response: api.OutputList{},
+	},
+	{
+		path: api.PathGetAccountParsedOutputs, method: http.MethodGet, tag: openAPITagGeneral,
+		summary:  "parsed outputs of the account in the latest reliable branch",
+		params:   []apiParam{paramAccountable, paramMaxOutputs, paramSort},
+		response: api.ParsedOutputList{},
+	},
+	{
+		path: api.PathGetAccountSimpleSiglockedOutputs, method: http.MethodGet, tag: openAPITagGeneral,
+		summary:  "outputs locked in the address with the simple signature lock",
+		params:   []apiParam{paramAddr, paramMaxOutputs, paramSort},
+		response: api.OutputList{},
+	},
+	{
+		path: api.PathGetOutputsForAmount, method: http.MethodGet, tag: openAPITagGeneral,
+		summary: "outputs of the address enough to cover the amount",
+		params: []apiParam{paramAddr,
+			{name: "amount", typ: "integer", required: true, description: "amount to cover"},
+		},
+		response: api.OutputList{},
+	},
+	{
+		path: api.PathGetNonChainBalance, method: http.MethodGet, tag: openAPITagGeneral,
+		summary:  "balance of non-chain outputs of the address",
+		params:   []apiParam{paramAddr},
+		response: api.Balance{},
+	},
+	{
+		path: api.PathGetChainedOutputs, method: http.MethodGet, tag: openAPITagGeneral,
+		summary:  "chain outputs controlled by the account",
+		params:   []apiParam{paramAccountable},
+		response: api.ChainedOutputs{},
+	},
+	{
+		path: api.PathGetChainOutput, method: http.MethodGet, tag: openAPITagGeneral,
+		summary:  "output of the chain in the latest reliable branch",
+		params:   []apiParam{paramChain},
+		response: api.ChainOutput{},
+	},
+	{
+		path: api.PathGetOutput, method: http.MethodGet, tag: openAPITagGeneral,
+		summary:  "output by output ID",
+		params:   []apiParam{{name: "id", typ: "string", required: true, description: "hex-encoded output ID"}},
+		response: api.OutputData{},
+	},
+	{
+		path: api.PathSubmitTransaction, method: http.MethodPost, tag: openAPITagGeneral,
+		summary:     "submits raw transaction bytes. Feedback only on parsing error, otherwise async posting",
+		params:      []apiParam{{name: "timeout", typ: "integer", description: "timeout in seconds"}},
+		requestBody: "application/octet-stream",
+		response:    api.Error{},
+	},
+	{
+		path: api.PathGetSyncInfo, method: http.MethodGet, tag: openAPITagNode,
+		summary:  "sync info of the node",
+		response: api.SyncInfo{},
+	},
+	{
+		path: api.PathGetNodeInfo, method: http.MethodGet, tag: openAPITagNode,
+		summary:  "node info",
+		response: global.NodeInfo{},
+	},
+	{
+		path: api.PathGetPeersInfo, method: http.MethodGet, tag: openAPITagNode,
+		summary:  "peers of the node",
+		response: api.PeersInfo{},
+	},
+	{
+		path: api.PathGetLatestReliableBranch, method: http.MethodGet, tag: openAPITagGeneral,
+		summary:  "latest reliable branch (LRB)",
+		response: api.LatestReliableBranch{},
+	},
+	{
+		path: api.PathCheckTxIDInLRB, method: http.MethodGet, tag: openAPITagGeneral,
+		summary: "checks if transaction is included in the latest reliable branch",
+		params: []apiParam{paramTxID,
+			{name: "max_depth", typ: "integer", description: "maximum depth of the search in the LRB"},
+		},
+		response: api.CheckTxIDInLRB{},
+	},
+	{
+		path: api.PathGetLastKnownSequencerMilestones, method: http.MethodGet, tag: openAPITagGeneral,
+		summary:  "latest milestones of sequencers known to the node",
+		response: api.KnownLatestMilestones{},
+	},
+	{
+		path: api.PathGetMainChain, method: http.MethodGet, tag: openAPITagGeneral,
+		summary:  "main chain of branches",
+		params:   []apiParam{{name: "max", typ: "integer", description: "maximum number of branches"}},
+		response: api.MainChain{},
+	},
+	{
+		path: api.PathGetAllChains, method: http.MethodGet, tag: openAPITagGeneral,
+		summary:  "all chains in the latest reliable branch",
+		response: api.Chains{},
+	},
+	{
+		path: api.PathGetDelegationsBySequencer, method: http.MethodGet, tag: openAPITagGeneral,
+		summary:  "delegations in the latest reliable branch summarized by sequencer",
+		response: api.DelegationsBySequencer{},
+	},
+	{
+		path: api.PathGetDelegationHistory, method: http.MethodGet, tag: openAPITagGeneral,
+		summary:  "history of earnings of the delegation chain",
+		params:   []apiParam{paramChain},
+		response: api.DelegationHistory{},
+	},
+	{
+		path: api.PathGetTxRejections, method: http.MethodGet, tag: openAPITagGeneral,
+		summary: "transactions rejected by the node, the latest first",
+		params: []apiParam{
+			{name: "txid", typ: "string", description: "hex-encoded transaction ID. If omitted, the latest rejections are returned"},
+			{name: "max", typ: "integer", description: "maximum number of returned rejections, when txid is omitted"},
+		},
+		response: api.TxRejections{},
+	},
+	{
+		path: api.PathGetAccountHistory, method: http.MethodGet, tag: openAPITagGeneral,
+		summary: "transactions touching the address, the latest first. Sequencer transactions are not included",
+		params: []apiParam{
+			paramAddr,
+			{name: "since", typ: "integer", description: "the earliest slot of returned transactions"},
+			{name: "max", typ: "integer", description: "maximum number of returned transactions"},
+		},
+		response: api.AccountHistory{},
+	},
+	{
+		path: api.PathGetSequencerStats, method: http.MethodGet, tag: openAPITagNode,
+		summary:  "counters of the sequencer running on the node since the start of the node",
+		response: api.SequencerStats{},
+	},
+	{
+		path: api.PathGetChainHistory, method: http.MethodGet, tag: openAPITagGeneral,
+		summary: "transitions of the chain, the latest first. The chain is walked backwards from its output in the LRB through predecessor inputs",
+		params: []apiParam{
+			paramChain,
+			{name: "max", typ: "integer", description: "maximum number of returned transitions"},
+		},
+		response: api.ChainHistory{},
+	},
+	{
+		path: api.PathGetDashboard, method: http.MethodGet, tag: openAPITagNode,
+		summary:     "dashboard of the node: sync status, peers, sequencer tips, coverage, main chain, recent transactions and live DAG",
+		contentType: "text/html",
+	},
+	{
+		path: api.PathGetDashboardAssets, method: http.MethodGet, tag: openAPITagNode, operationID: "dashboard_assets",
+		summary:     "static files of the dashboard (scripts and styles) embedded into the node. The root is the dashboard page",
+		contentType: "text/html",
+	},
+	{
+		path: api.PathGetOpenAPI, method: http.MethodGet, tag: openAPITagNode, operationID: "openapi",
+		summary: "this OpenAPI document",
+	},
+	{
+		path: api.PathGetAPIExplorer, method: http.MethodGet, tag: openAPITagNode,
+		summary:     "API explorer page",
+		contentType: "text/html",
+	},
+	{
+		path: api.PathCompileScript, method: http.MethodGet, tag: openAPITagTx,
+		summary:  "compiles EasyFL script in the context of the ledger of the node",
+		params:   []apiParam{{name: "source", typ: "string", required: true, description: "script source in EasyFL"}},
+		response: api.Bytecode{},
+	},
+	{
+		path: api.PathDecompileBytecode, method: http.MethodGet, tag: openAPITagTx,
+		summary:  "decompiles bytecode in the context of the ledger of the node to EasyFL script",
+		params:   []apiParam{{name: "bytecode", typ: "string", required: true, description: "hex-encoded bytecode"}},
+		response: api.ScriptSource{},
+	},
+	{
+		path: api.PathParseOutput, method: http.MethodGet, tag: openAPITagTx,
+		summary:  "finds output in the latest reliable branch and parses it",
+		params:   []apiParam{{name: "output_id", typ: "string", required: true, description: "hex-encoded output ID"}},
+		response: api.ParsedOutput{},
+	},
+	{
+		path: api.PathParseOutputData, method: http.MethodGet, tag: openAPITagTx,
+		summary:  "parses raw output data",
+		params:   []apiParam{{name: "output_data", typ: "string", required: true, description: "hex-encoded output binary"}},
+		response: api.ParsedOutput{},
+	},
+	{
+		path: api.PathGetTxBytes, method: http.MethodGet, tag: openAPITagTx,
+		summary:  "raw transaction bytes and metadata",
+		params:   []apiParam{paramTxID},
+		response: api.TxBytes{},
+	},
+	{
+		path: api.PathGetParsedTransaction, method: http.MethodGet, tag: openAPITagTx,
+		summary:  "parsed transaction in JSON form, intended for display",
+		params:   []apiParam{paramTxID},
+		response: api.TransactionJSONAble{},
+	},
+	{
+		path: api.PathGetVertexWithDependencies, method: http.MethodGet, tag: openAPITagTx,
+		summary:  "compressed form of the DAG vertex, intended for DAG visualizers",
+		params:   []apiParam{paramTxID},
+		response: api.VertexWithDependencies{},
+	},
+}
+
+var (
+	openAPIDocumentOnce  sync.Once
+	openAPIDocumentBytes []byte
+)
+
+// openAPIDocument returns the OpenAPI document. It is built once
+func openAPIDocument() []byte {
+	openAPIDocumentOnce.Do(func() {
+		var err error
+		openAPIDocumentBytes, err = json.MarshalIndent(makeOpenAPIDocument(), "", "  ")
+		util.AssertNoError(err)
+	})
+	return openAPIDocumentBytes
+}
+
+func makeOpenAPIDocument() map[string]any {
+	schemas := &openAPISchemas{
+		components: make(map[string]any),
+		names:      make(map[reflect.Type]string),
+	}
+	errorRef := schemas.schema(reflect.TypeOf(api.Error{}))
+
+	paths := make(map[string]any)
+	for i := range apiEndpoints {
+		ep := &apiEndpoints[i]
+		op := map[string]any{
+			"operationId": ep.opID(),
+			"summary":     ep.summary,
+			"tags":        []string{ep.tag},
+			"responses": map[string]any{
+				"200": map[string]any{
+					"description": "result. Errors are returned in the 'error' field",
+					"content":     map[string]any{ep.responseContentType(): map[string]any{"schema": schemas.responseSchema(ep, errorRef)}},
+				},
+				"401": errorResponse("API key is required or invalid", errorRef),
+				"403": errorResponse("API key has no scope required by the endpoint", errorRef),
+				"429": errorResponse("rate limit exceeded", errorRef),
+			},
+		}
+		if len(ep.params) > 0 {
+			params := make([]any, 0, len(ep.params))
+			for _, p := range ep.params {
+				schema := map[string]any{"type": p.typ}
+				if len(p.enum) > 0 {
+					schema["enum"] = p.enum
+				}
+				params = append(params, map[string]any{
+					"name":        p.name,
+					"in":          "query",
+					"description": p.description,
+					"required":    p.required,
+					"schema":      schema,
+				})
+			}
+			op["parameters"] = params
+		}
+		if ep.requestBody != "" {
+			op["requestBody"] = map[string]any{
+				"required": true,
+				"content": map[string]any{
+					ep.requestBody: map[string]any{"schema": map[string]any{"type": "string", "format": "binary"}},
+				},
+			}
+		}
+		paths[ep.path] = map[string]any{strings.ToLower(ep.method): op}
+	}
+
+	return map[string]any{
+		"openapi": "3.0.3",
+		"info": map[string]any{
+			"title":       "Proxima node API",
+			"version":     "v1",
+			"description": "API of the Proxima node. See also docs/api.md",
+		},
+		"tags": []any{
+			map[string]any{"name": openAPITagGeneral, "description": "ledger state and transactions"},
+			map[string]any{"name": openAPITagTx, "description": "transaction API"},
+			map[string]any{"name": openAPITagNode, "description": "node status and utilities"},
+		},
+		"paths": paths,
+		"components": map[string]any{
+			"schemas": schemas.components,
+			"securitySchemes": map[string]any{
+				"apiKey": map[string]any{"type": "apiKey", "in": "header", "name": apiKeyHeader},
+				"bearer": map[string]any{"type": "http", "scheme": "bearer"},
+			},
+		},
+		// API key is optional unless the node requires it
+		"security": []any{
+			map[string]any{},
+			map[string]any{"apiKey": []string{}},
+			map[string]any{"bearer": []string{}},
+		},
+	}
+}
+
+func (ep *apiEndpoint) opID() string {
+	if ep.operationID != "" {
+		return ep.operationID
+	}
+	return path.Base(ep.path)
+}
+
+func (ep *apiEndpoint) responseContentType() string {
+	if ep.contentType != "" {
+		return ep.contentType
+	}
+	return "application/json"
+}
+
+func errorResponse(description string, errorRef map[string]any) map[string]any {
+	return map[string]any{
+		"description": description,
+		"content":     map[string]any{"application/json": map[string]any{"schema": errorRef}},
+	}
+}
+
+// responseSchema returns schema of the response type. Errors of types which do not embed api.Error
+// are returned as api.Error, so the schema is one of the two
+func (s *openAPISchemas) responseSchema(ep *apiEndpoint, errorRef map[string]any) map[string]any {
+	if ep.response == nil {
+		if ep.responseContentType() == "application/json" {
+			return map[string]any{"type": "object"}
+		}
+		return map[string]any{"type": "string"}
+	}
+	t := reflect.TypeOf(ep.response)
+	ret := s.schema(t)
+	if _, hasError := t.FieldByName("Error"); hasError {
+		return ret
+	}
+	return map[string]any{"oneOf": []any{ret, errorRef}}
+}
+
+var (
+	jsonMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	textMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+// schema returns JSON schema of the type the way encoding/json marshals it. Structs become components
+func (s *openAPISchemas) schema(t reflect.Type) map[string]any {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.Implements(jsonMarshalerType) || reflect.PointerTo(t).Implements(jsonMarshalerType) ||
+		t.Implements(textMarshalerType) || reflect.PointerTo(t).Implements(textMarshalerType) {
+		// all custom marshalers in the API marshal to strings
+		return map[string]any{"type": "string"}
+	}
+	switch t.Kind() {
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int, reflect.Int64:
+		return map[string]any{"type": "integer", "format": "int64"}
+	case reflect.Int8, reflect.Int16, reflect.Int32:
+		return map[string]any{"type": "integer", "format": "int32"}
+	case reflect.Uint, reflect.Uint64:
+		return map[string]any{"type": "integer", "format": "int64", "minimum": 0}
+	case reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return map[string]any{"type": "integer", "format": "int32", "minimum": 0}
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": "number"}
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Slice:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return map[string]any{"type": "string", "format": "byte"}
+		}
+		return map[string]any{"type": "array", "items": s.schema(t.Elem())}
+	case reflect.Array:
+		return map[string]any{"type": "array", "items": s.schema(t.Elem()), "minItems": t.Len(), "maxItems": t.Len()}
+	case reflect.Map:
+		return map[string]any{"type": "object", "additionalProperties": s.schema(t.Elem())}
+	case reflect.Struct:
+		return s.structSchemaRef(t)
+	default:
+		return map[string]any{}
+	}
+}
+
+func (s *openAPISchemas) structSchemaRef(t reflect.Type) map[string]any {
+	name, found := s.names[t]
+	if !found {
+		name = t.Name()
+		for _, existing := range s.names {
+			if existing == name {
+				// same name in different packages
+				name = path.Base(t.PkgPath()) + "." + name
+				break
+			}
+		}
+		s.names[t] = name
+		properties := make(map[string]any)
+		required := make([]string, 0)
+		s.collectProperties(t, properties, &required)
+		schema := map[string]any{"type": "object", "properties": properties}
+		if len(required) > 0 {
+			schema["required"] = required
+		}
+		s.components[name] = schema
+	}
+	return map[string]any{"$ref": "#/components/schemas/" + name}
+}
+
+// collectProperties collects properties of the struct. Embedded structs without JSON name are inlined, as encoding/json does
+func (s *openAPISchemas) collectProperties(t reflect.Type, properties map[string]any, required *[]string) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, opts, _ := strings.Cut(tag, ",")
+		if f.Anonymous && name == "" {
+			ft := f.Type
+			if ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				s.collectProperties(ft, properties, required)
+				continue
+			}
+		}
+		if !f.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		properties[name] = s.schema(f.Type)
+		if !strings.Contains(opts, "omitempty") && f.Type.Kind() != reflect.Pointer {
+			*required = append(*required, name)
+		}
+	}
+}
+
+func (srv *server) getOpenAPI(w http.ResponseWriter, _ *http.Request) {
+	api.SetHeader(w)
+
+	_, err := w.Write(openAPIDocument())
+	util.AssertNoError(err)
+}
+
+func (srv *server) getAPIExplorer(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	_, err := w.Write([]byte(explorerHTML))
+	util.AssertNoError(err)
+}
+
+// checkOpenAPIDocumented warns about registered handlers which are not described in the OpenAPI document
+func (srv *server) checkOpenAPIDocumented() {
+	documented := make(map[string]bool)
+	for _, ep := range apiEndpoints {
+		documented[ep.path] = true
+	}
+	for _, pattern := range srv.handlerPatterns {
+		if !documented[pattern] && !strings.HasPrefix(pattern, api.PrefixWebSocketV1) {
+			srv.Log().Warnf("[apiServer] endpoint %s is not described in the OpenAPI document", pattern)
+		}
+	}
+}
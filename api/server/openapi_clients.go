@@ -0,0 +1,508 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/lunfardo314/proxima/util"
+)
+
+// Generation of API clients from the OpenAPI document. Clients are generated from the document itself,
+// not from the Go types, so they are exactly what the node publishes at /api/v1/openapi.json.
+// Generated clients are committed in the api/clients directory and kept in sync by tests
+
+const (
+	APIClientPython     = "python"
+	APIClientTypeScript = "typescript"
+)
+
+type (
+	openAPIDoc struct {
+		Paths      map[string]map[string]*openAPIOperation `json:"paths"`
+		Components struct {
+			Schemas map[string]*jsonSchema `json:"schemas"`
+		} `json:"components"`
+	}
+
+	openAPIOperation struct {
+		OperationID string `json:"operationId"`
+		Summary     string `json:"summary"`
+		Parameters  []struct {
+			Name        string      `json:"name"`
+			Description string      `json:"description"`
+			Required    bool        `json:"required"`
+			Schema      *jsonSchema `json:"schema"`
+		} `json:"parameters"`
+		RequestBody *struct {
+			Content map[string]any `json:"content"`
+		} `json:"requestBody"`
+		Responses map[string]struct {
+			Content map[string]struct {
+				Schema *jsonSchema `json:"schema"`
+			} `json:"content"`
+		} `json:"responses"`
+	}
+
+	jsonSchema struct {
+		Type                 string                 `json:"type"`
+		Ref                  string                 `json:"$ref"`
+		Items                *jsonSchema            `json:"items"`
+		AdditionalProperties *jsonSchema            `json:"additionalProperties"`
+		Properties           map[string]*jsonSchema `json:"properties"`
+		Required             []string               `json:"required"`
+		OneOf                []*jsonSchema          `json:"oneOf"`
+		Enum                 []string               `json:"enum"`
+	}
+
+	// clientOperation is the operation as it is seen by client generators
+	clientOperation struct {
+		name        string
+		path        string
+		method      string
+		summary     string
+		params      []clientParam
+		requestBody bool
+		// 'json' or 'text'
+		responseKind string
+		// response schema. Nil if response is untyped
+		response *jsonSchema
+	}
+
+	clientParam struct {
+		name        string
+		description string
+		required    bool
+		schema      *jsonSchema
+	}
+)
+
+const generatedClientHeader = "Code generated from the OpenAPI document of the Proxima node. DO NOT EDIT.\nRegenerate with 'go generate ./api/clients'"
+
+// GenerateAPIClient generates source of the API client in the language 'python' or 'typescript'
+func GenerateAPIClient(lang string) ([]byte, error) {
+	var doc openAPIDoc
+	if err := json.Unmarshal(openAPIDocument(), &doc); err != nil {
+		return nil, err
+	}
+	ops := doc.clientOperations()
+	switch lang {
+	case APIClientPython:
+		return generatePythonClient(&doc, ops), nil
+	case APIClientTypeScript:
+		return generateTypeScriptClient(&doc, ops), nil
+	}
+	return nil, fmt.Errorf("unsupported client language '%s'. Must be '%s' or '%s'", lang, APIClientPython, APIClientTypeScript)
+}
+
+// clientOperations returns operations in the order of paths. HTML pages are skipped
+func (doc *openAPIDoc) clientOperations() []*clientOperation {
+	ret := make([]*clientOperation, 0)
+	for _, p := range util.KeysSorted(doc.Paths, func(p1, p2 string) bool { return p1 < p2 }) {
+		for method, op := range doc.Paths[p] {
+			cop := &clientOperation{
+				name:        op.OperationID,
+				path:        p,
+				method:      strings.ToUpper(method),
+				summary:     op.Summary,
+				requestBody: op.RequestBody != nil,
+			}
+			for ct, c := range op.Responses["200"].Content {
+				switch ct {
+				case "application/json":
+					cop.responseKind = "json"
+					cop.response = c.Schema.withoutErrorAlternative()
+				case "text/html":
+				default:
+					cop.responseKind = "text"
+				}
+			}
+			if cop.responseKind == "" {
+				continue
+			}
+			for _, par := range op.Parameters {
+				cop.params = append(cop.params, clientParam{
+					name:        par.Name,
+					description: par.Description,
+					required:    par.Required,
+					schema:      par.Schema,
+				})
+			}
+			// required parameters first
+			slices.SortStableFunc(cop.params, func(p1, p2 clientParam) int {
+				switch {
+				case p1.required == p2.required:
+					return 0
+				case p1.required:
+					return -1
+				}
+				return 1
+			})
+			ret = append(ret, cop)
+		}
+	}
+	return ret
+}
+
+// withoutErrorAlternative returns the response schema without api.Error alternative: clients return errors as exceptions
+func (s *jsonSchema) withoutErrorAlternative() *jsonSchema {
+	if s == nil || len(s.OneOf) == 0 {
+		return s
+	}
+	for _, alt := range s.OneOf {
+		if schemaName(alt.Ref) != "Error" {
+			return alt
+		}
+	}
+	return s.OneOf[0]
+}
+
+var nonIdentifierChars = regexp.MustCompile(`[^A-Za-z0-9_]`)
+
+func schemaName(ref string) string {
+	return nonIdentifierChars.ReplaceAllString(strings.TrimPrefix(ref, "#/components/schemas/"), "_")
+}
+
+func camelCase(s string) string {
+	parts := strings.Split(nonIdentifierChars.ReplaceAllString(s, "_"), "_")
+	for i := 1; i < len(parts); i++ {
+		if parts[i] != "" {
+			parts[i] = strings.ToUpper(parts[i][:1]) + parts[i][1:]
+		}
+	}
+	return strings.Join(parts, "")
+}
+
+func isIdentifier(s string) bool {
+	return s != "" && !nonIdentifierChars.MatchString(s) && (s[0] < '0' || s[0] > '9')
+}
+
+// ---------------------------------------------------- Python
+
+var pythonKeywords = []string{"and", "as", "assert", "async", "await", "break", "class", "continue", "def", "del", "elif",
+	"else", "except", "finally", "for", "from", "global", "if", "import", "in", "is", "lambda", "nonlocal", "not", "or",
+	"pass", "raise", "return", "try", "while", "with", "yield", "None", "True", "False"}
+
+func pythonName(s string) string {
+	s = nonIdentifierChars.ReplaceAllString(s, "_")
+	if slices.Contains(pythonKeywords, s) {
+		return s + "_"
+	}
+	return s
+}
+
+func pythonType(s *jsonSchema) string {
+	switch {
+	case s == nil:
+		return "Any"
+	case s.Ref != "":
+		return schemaName(s.Ref)
+	case len(s.OneOf) > 0:
+		alts := make([]string, len(s.OneOf))
+		for i, alt := range s.OneOf {
+			alts[i] = pythonType(alt)
+		}
+		return "Union[" + strings.Join(alts, ", ") + "]"
+	}
+	switch s.Type {
+	case "string":
+		return "str"
+	case "integer":
+		return "int"
+	case "number":
+		return "float"
+	case "boolean":
+		return "bool"
+	case "array":
+		return "List[" + pythonType(s.Items) + "]"
+	case "object":
+		if s.AdditionalProperties != nil {
+			return "Dict[str, " + pythonType(s.AdditionalProperties) + "]"
+		}
+		return "Dict[str, Any]"
+	}
+	return "Any"
+}
+
+func generatePythonClient(doc *openAPIDoc, ops []*clientOperation) []byte {
+	var buf bytes.Buffer
+	w := func(format string, args ...any) {
+		_, _ = fmt.Fprintf(&buf, format+"\n", args...)
+	}
+	for _, line := range strings.Split(generatedClientHeader, "\n") {
+		w("# %s", line)
+	}
+	w(`"""Client of the Proxima node API. Requires only the Python standard library"""`)
+	w("")
+	w("import json")
+	w("import urllib.error")
+	w("import urllib.parse")
+	w("import urllib.request")
+	w("from typing import Any, Dict, List, Optional, TypedDict, Union")
+	w("")
+	w("")
+	w("class ProximaAPIError(Exception):")
+	w(`    """Error returned by the node: HTTP status other than 200 or non-empty 'error' field of the response"""`)
+	w("")
+	w("    def __init__(self, message: str, status: int = 200):")
+	w("        super().__init__(message)")
+	w("        self.status = status")
+	w("")
+	w("")
+	w("# Schemas of responses. All fields are optional because the node omits empty ones")
+	for _, name := range util.KeysSorted(doc.Components.Schemas, func(n1, n2 string) bool { return n1 < n2 }) {
+		s := doc.Components.Schemas[name]
+		w("")
+		w("%s = TypedDict(%q, {", schemaName(name), schemaName(name))
+		for _, prop := range util.KeysSorted(s.Properties, func(p1, p2 string) bool { return p1 < p2 }) {
+			w("    %q: %q,", prop, pythonType(s.Properties[prop]))
+		}
+		w("}, total=False)")
+	}
+	w("")
+	w("")
+	w("class ProximaAPI:")
+	w(`    """Client of the Proxima node API. API key, if provided, is sent in the 'X-API-Key' header"""`)
+	w("")
+	w("    def __init__(self, base_url: str, api_key: Optional[str] = None, timeout: float = 10.0):")
+	w("        self.base_url = base_url.rstrip(\"/\")")
+	w("        self.api_key = api_key")
+	w("        self.timeout = timeout")
+	w("")
+	w("    def _request(self, method: str, path: str, params: Dict[str, Any], body: Optional[bytes] = None) -> bytes:")
+	w("        query = urllib.parse.urlencode({k: v for k, v in params.items() if v is not None})")
+	w("        req = urllib.request.Request(self.base_url + path + (\"?\" + query if query else \"\"), data=body, method=method)")
+	w("        if self.api_key:")
+	w("            req.add_header(\"X-API-Key\", self.api_key)")
+	w("        if body is not None:")
+	w("            req.add_header(\"Content-Type\", \"application/octet-stream\")")
+	w("        try:")
+	w("            with urllib.request.urlopen(req, timeout=self.timeout) as resp:")
+	w("                return resp.read()")
+	w("        except urllib.error.HTTPError as e:")
+	w("            message = str(e)")
+	w("            try:")
+	w("                message = json.loads(e.read()).get(\"error\") or message")
+	w("            except ValueError:")
+	w("                pass")
+	w("            raise ProximaAPIError(message, e.code) from None")
+	w("")
+	w("    def _json(self, method: str, path: str, params: Dict[str, Any], body: Optional[bytes] = None) -> Any:")
+	w("        ret = json.loads(self._request(method, path, params, body))")
+	w("        if isinstance(ret, dict) and ret.get(\"error\"):")
+	w("            raise ProximaAPIError(ret[\"error\"])")
+	w("        return ret")
+
+	for _, op := range ops {
+		args := []string{"self"}
+		if op.requestBody {
+			args = append(args, "body: bytes")
+		}
+		paramsDict := make([]string, 0, len(op.params))
+		for _, p := range op.params {
+			if p.required {
+				args = append(args, fmt.Sprintf("%s: %s", pythonName(p.name), pythonType(p.schema)))
+			} else {
+				args = append(args, fmt.Sprintf("%s: Optional[%s] = None", pythonName(p.name), pythonType(p.schema)))
+			}
+			paramsDict = append(paramsDict, fmt.Sprintf("%q: %s", p.name, pythonName(p.name)))
+		}
+		retType := "str"
+		if op.responseKind == "json" {
+			retType = pythonType(op.response)
+		}
+		w("")
+		w("    def %s(%s) -> %q:", pythonName(op.name), strings.Join(args, ", "), retType)
+		w(`        """%s`, op.summary)
+		if len(op.params) > 0 {
+			w("")
+			for _, p := range op.params {
+				w("        :param %s: %s", pythonName(p.name), p.description)
+			}
+		}
+		w(`        """`)
+		bodyArg := ""
+		if op.requestBody {
+			bodyArg = ", body"
+		}
+		call := fmt.Sprintf("self._json(%q, %q, {%s}%s)", op.method, op.path, strings.Join(paramsDict, ", "), bodyArg)
+		if op.responseKind != "json" {
+			call = fmt.Sprintf("self._request(%q, %q, {%s}%s).decode()", op.method, op.path, strings.Join(paramsDict, ", "), bodyArg)
+		}
+		w("        return %s", call)
+	}
+	return buf.Bytes()
+}
+
+// ---------------------------------------------------- TypeScript
+
+func typeScriptType(s *jsonSchema) string {
+	switch {
+	case s == nil:
+		return "unknown"
+	case s.Ref != "":
+		return schemaName(s.Ref)
+	case len(s.OneOf) > 0:
+		alts := make([]string, len(s.OneOf))
+		for i, alt := range s.OneOf {
+			alts[i] = typeScriptType(alt)
+		}
+		return strings.Join(alts, " | ")
+	case len(s.Enum) > 0:
+		alts := make([]string, len(s.Enum))
+		for i, e := range s.Enum {
+			alts[i] = fmt.Sprintf("%q", e)
+		}
+		return strings.Join(alts, " | ")
+	}
+	switch s.Type {
+	case "string":
+		return "string"
+	case "integer", "number":
+		return "number"
+	case "boolean":
+		return "boolean"
+	case "array":
+		if s.Items != nil && len(s.Items.OneOf) > 0 {
+			return "(" + typeScriptType(s.Items) + ")[]"
+		}
+		return typeScriptType(s.Items) + "[]"
+	case "object":
+		if s.AdditionalProperties != nil {
+			return "Record<string, " + typeScriptType(s.AdditionalProperties) + ">"
+		}
+		return "Record<string, unknown>"
+	}
+	return "unknown"
+}
+
+func typeScriptPropName(s string) string {
+	if isIdentifier(s) {
+		return s
+	}
+	return fmt.Sprintf("%q", s)
+}
+
+func generateTypeScriptClient(doc *openAPIDoc, ops []*clientOperation) []byte {
+	var buf bytes.Buffer
+	w := func(format string, args ...any) {
+		_, _ = fmt.Fprintf(&buf, format+"\n", args...)
+	}
+	for _, line := range strings.Split(generatedClientHeader, "\n") {
+		w("// %s", line)
+	}
+	w("// Client of the Proxima node API. Uses the standard 'fetch'")
+	w("")
+	w("/** Error returned by the node: HTTP status other than 200 or non-empty 'error' field of the response */")
+	w("export class ProximaAPIError extends Error {")
+	w("  constructor(message: string, public status: number = 200) {")
+	w("    super(message);")
+	w("    this.name = \"ProximaAPIError\";")
+	w("  }")
+	w("}")
+	w("")
+	w("// Schemas of responses")
+	for _, name := range util.KeysSorted(doc.Components.Schemas, func(n1, n2 string) bool { return n1 < n2 }) {
+		s := doc.Components.Schemas[name]
+		w("")
+		w("export interface %s {", schemaName(name))
+		for _, prop := range util.KeysSorted(s.Properties, func(p1, p2 string) bool { return p1 < p2 }) {
+			opt := "?"
+			if slices.Contains(s.Required, prop) {
+				opt = ""
+			}
+			w("  %s%s: %s;", typeScriptPropName(prop), opt, typeScriptType(s.Properties[prop]))
+		}
+		w("}")
+	}
+	w("")
+	w("type QueryParams = Record<string, string | number | undefined>;")
+	w("")
+	w("/** Client of the Proxima node API. API key, if provided, is sent in the 'X-API-Key' header */")
+	w("export class ProximaAPI {")
+	w("  constructor(public baseURL: string, public apiKey?: string) {")
+	w("    this.baseURL = baseURL.replace(/\\/+$/, \"\");")
+	w("  }")
+	w("")
+	w("  private async request(method: string, path: string, params: QueryParams, body?: Uint8Array): Promise<Response> {")
+	w("    const query = new URLSearchParams();")
+	w("    for (const [k, v] of Object.entries(params)) {")
+	w("      if (v !== undefined) {")
+	w("        query.set(k, String(v));")
+	w("      }")
+	w("    }")
+	w("    const qs = query.toString();")
+	w("    const headers: Record<string, string> = {};")
+	w("    if (this.apiKey) {")
+	w("      headers[\"X-API-Key\"] = this.apiKey;")
+	w("    }")
+	w("    if (body !== undefined) {")
+	w("      headers[\"Content-Type\"] = \"application/octet-stream\";")
+	w("    }")
+	w("    const resp = await fetch(this.baseURL + path + (qs ? \"?\" + qs : \"\"), { method, headers, body });")
+	w("    if (!resp.ok) {")
+	w("      let message = resp.statusText;")
+	w("      try {")
+	w("        message = (await resp.json()).error || message;")
+	w("      } catch {")
+	w("        // not JSON")
+	w("      }")
+	w("      throw new ProximaAPIError(message, resp.status);")
+	w("    }")
+	w("    return resp;")
+	w("  }")
+	w("")
+	w("  private async json<T>(method: string, path: string, params: QueryParams, body?: Uint8Array): Promise<T> {")
+	w("    const ret = await (await this.request(method, path, params, body)).json();")
+	w("    if (ret && typeof ret === \"object\" && ret.error) {")
+	w("      throw new ProximaAPIError(ret.error);")
+	w("    }")
+	w("    return ret as T;")
+	w("  }")
+
+	for _, op := range ops {
+		args := make([]string, 0)
+		if op.requestBody {
+			args = append(args, "body: Uint8Array")
+		}
+		paramsArg := "{}"
+		if len(op.params) > 0 {
+			fields := make([]string, len(op.params))
+			allOptional := true
+			for i, p := range op.params {
+				opt := "?"
+				if p.required {
+					opt = ""
+					allOptional = false
+				}
+				fields[i] = fmt.Sprintf("%s%s: %s", typeScriptPropName(p.name), opt, typeScriptType(p.schema))
+			}
+			arg := "params: { " + strings.Join(fields, "; ") + " }"
+			if allOptional {
+				arg += " = {}"
+			}
+			args = append(args, arg)
+			paramsArg = "params"
+		}
+		bodyArg := ""
+		if op.requestBody {
+			bodyArg = ", body"
+		}
+		w("")
+		w("  /** %s */", op.summary)
+		if op.responseKind == "json" {
+			retType := typeScriptType(op.response)
+			w("  async %s(%s): Promise<%s> {", camelCase(op.name), strings.Join(args, ", "), retType)
+			w("    return this.json<%s>(%q, %q, %s%s);", retType, op.method, op.path, paramsArg, bodyArg)
+		} else {
+			w("  async %s(%s): Promise<string> {", camelCase(op.name), strings.Join(args, ", "))
+			w("    return (await this.request(%q, %q, %s%s)).text();", op.method, op.path, paramsArg, bodyArg)
+		}
+		w("  }")
+	}
+	w("}")
+	return buf.Bytes()
+}
@@ -0,0 +1,292 @@
+package server
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/libp2p/go-libp2p/core/crypto"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/lunfardo314/proxima/api"
+	"github.com/lunfardo314/proxima/core/core_modules/delegation_history"
+	"github.com/lunfardo314/proxima/core/core_modules/tippool"
+	"github.com/lunfardo314/proxima/core/txmetadata"
+	"github.com/lunfardo314/proxima/global"
+	"github.com/lunfardo314/proxima/ledger"
+	"github.com/lunfardo314/proxima/ledger/base"
+	"github.com/lunfardo314/proxima/ledger/multistate"
+	"github.com/lunfardo314/proxima/tests"
+	"github.com/lunfardo314/proxima/txstore"
+	"github.com/lunfardo314/proxima/util"
+	"github.com/lunfardo314/proxima/util/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+// openAPITestEnvironment is the test environment with the ledger state after the genesis distribution.
+// It returns non-empty data for all endpoints
+type openAPITestEnvironment struct {
+	environment
+	lrb *multistate.BranchData
+}
+
+func (e *openAPITestEnvironment) GetNodeInfo() *global.NodeInfo {
+	_, pub, err := crypto.GenerateEd25519Key(rand.Reader)
+	util.AssertNoError(err)
+	id, err := peer.IDFromPublicKey(pub)
+	util.AssertNoError(err)
+	return &global.NodeInfo{ID: id, Version: "test", NumStaticAlive: 1}
+}
+
+func (e *openAPITestEnvironment) GetSyncInfo() *api.SyncInfo {
+	return &api.SyncInfo{
+		Synced:       true,
+		PerSequencer: map[string]api.SequencerSyncInfo{"seq": {Synced: true}},
+	}
+}
+
+func (e *openAPITestEnvironment) GetPeersInfo() *api.PeersInfo {
+	return &api.PeersInfo{
+		HostID:    "host",
+		Peers:     []api.PeerInfo{{ID: "peer", MultiAddresses: []string{"/ip4/127.0.0.1/tcp/4000"}}},
+		Blacklist: map[string]string{"bad": "test"},
+	}
+}
+
+func (e *openAPITestEnvironment) LatestReliableState() (multistate.SugaredStateReader, error) {
+	rdr, err := multistate.NewReadable(e.StateStore(), e.lrb.Root)
+	if err != nil {
+		return multistate.SugaredStateReader{}, err
+	}
+	return multistate.MakeSugared(rdr), nil
+}
+
+func (e *openAPITestEnvironment) GetLatestReliableBranch() *multistate.BranchData {
+	return e.lrb
+}
+
+func (e *openAPITestEnvironment) CheckTransactionInLRB(_ base.TransactionID, _ int) (base.TransactionID, int) {
+	return e.lrb.TxID(), 0
+}
+
+func (e *openAPITestEnvironment) GetKnownLatestMilestonesJSONAble() map[string]tippool.LatestSequencerTipDataJSONAble {
+	return map[string]tippool.LatestSequencerTipDataJSONAble{"seq": {LatestMilestoneTxID: "txid", MilestoneCount: 1}}
+}
+
+func (e *openAPITestEnvironment) GetTxRejections(_ *base.TransactionID, _ int) (uint64, []*txstore.TxRejection) {
+	return 1, []*txstore.TxRejection{{Reason: "test", Source: txmetadata.SourceTypeAPI, Time: time.Now()}}
+}
+
+func (e *openAPITestEnvironment) GetDelegationHistory(chainID base.ChainID) (*delegation_history.History, error) {
+	rdr, err := e.LatestReliableState()
+	if err != nil {
+		return nil, err
+	}
+	o, err := rdr.GetChainOutput(chainID)
+	if err != nil {
+		return nil, err
+	}
+	return &delegation_history.History{
+		ChainID:     chainID,
+		Transitions: []delegation_history.Transition{{OutputID: o.ID, Amount: o.Output.Amount()}},
+	}, nil
+}
+
+// openAPITestFixture is the test environment and the request parameters which refer to its ledger state
+type openAPITestFixture struct {
+	env     *openAPITestEnvironment
+	txBytes []byte
+	params  map[string]url.Values
+}
+
+func newOpenAPITestFixture(t *testing.T) *openAPITestFixture {
+	wrkEnv, txid, err := tests.StartTestEnv()
+	require.NoError(t, err)
+	lrb, found := multistate.FetchBranchData(wrkEnv.StateStore(), *txid)
+	require.True(t, found)
+	env := &openAPITestEnvironment{environment: wrkEnv, lrb: &lrb}
+
+	_, txBytes, err := txmetadata.SplitTxBytesWithMetadata(wrkEnv.TxBytesStore().GetTxBytesWithMetadata(txid))
+	require.NoError(t, err)
+	require.True(t, len(txBytes) > 0)
+
+	rdr, err := env.LatestReliableState()
+	require.NoError(t, err)
+	chains, err := rdr.GetAllChainsOld()
+	require.NoError(t, err)
+	require.EqualValues(t, 1, len(chains))
+	var chainID base.ChainID
+	for id := range chains {
+		chainID = id
+	}
+	chainOut, err := rdr.GetChainOutput(chainID)
+	require.NoError(t, err)
+	addr := ledger.AddressED25519FromPrivateKey(testutil.GetTestingPrivateKey(1))
+	var addrOut *ledger.OutputWithID
+	err = rdr.IterateOutputsForAccount(addr, func(oid base.OutputID, o *ledger.Output) bool {
+		addrOut = &ledger.OutputWithID{ID: oid, Output: o}
+		return false
+	})
+	require.NoError(t, err)
+	require.NotNil(t, addrOut)
+
+	txidParams := url.Values{"txid": {txid.StringHex()}}
+	chainParams := url.Values{"chainid": {chainID.StringHex()}}
+	addrParams := url.Values{"addr": {addr.Source()}}
+	return &openAPITestFixture{
+		env:     env,
+		txBytes: txBytes,
+		params: map[string]url.Values{
+			api.PathGetAccountOutputs:                {"accountable": {addr.Source()}},
+			api.PathGetAccountParsedOutputs:          {"accountable": {addr.Source()}},
+			api.PathGetAccountSimpleSiglockedOutputs: addrParams,
+			api.PathGetOutputsForAmount:              {"addr": {addr.Source()}, "amount": {"1000"}},
+			api.PathGetNonChainBalance:               addrParams,
+			api.PathGetChainedOutputs:                {"accountable": {chainOut.Output.Lock().Source()}},
+			api.PathGetChainOutput:                   chainParams,
+			api.PathGetOutput:                        {"id": {addrOut.ID.StringHex()}},
+			api.PathCheckTxIDInLRB:                   txidParams,
+			api.PathGetDelegationHistory:             chainParams,
+			api.PathGetTxRejections:                  txidParams,
+			api.PathGetAccountHistory:                addrParams,
+			api.PathGetChainHistory:                  chainParams,
+			api.PathCompileScript:                    {"source": {"slice(0x0102,0,0)"}},
+			api.PathDecompileBytecode:                {"bytecode": {"1182010281008100"}},
+			api.PathParseOutput:                      {"output_id": {addrOut.ID.StringHex()}},
+			api.PathParseOutputData:                  {"output_data": {hex.EncodeToString(addrOut.Output.Bytes())}},
+			api.PathGetTxBytes:                       txidParams,
+			api.PathGetParsedTransaction:             txidParams,
+			api.PathGetVertexWithDependencies:        txidParams,
+		},
+	}
+}
+
+// request returns request to the endpoint with all required parameters
+func (f *openAPITestFixture) request(t *testing.T, ep *apiEndpoint) *http.Request {
+	for _, p := range ep.params {
+		if p.required {
+			require.Contains(t, f.params[ep.path], p.name, "no test value of the parameter '%s' of %s", p.name, ep.path)
+		}
+	}
+	target := ep.path
+	if ep.path == api.PathGetDashboardAssets {
+		target += "dashboard.js"
+	}
+	if q, ok := f.params[ep.path]; ok {
+		target += "?" + q.Encode()
+	}
+	var body []byte
+	if ep.method == http.MethodPost {
+		body = f.txBytes
+	}
+	return httptest.NewRequest(ep.method, target, bytes.NewReader(body))
+}
+
+func TestOpenAPIDocument(t *testing.T) {
+	var doc map[string]any
+	require.NoError(t, json.Unmarshal(openAPIDocument(), &doc))
+
+	paths := doc["paths"].(map[string]any)
+	require.Equal(t, len(apiEndpoints), len(paths), "duplicate paths in the OpenAPI document")
+
+	schemas := doc["components"].(map[string]any)["schemas"].(map[string]any)
+	var checkRefs func(v any)
+	checkRefs = func(v any) {
+		switch v := v.(type) {
+		case map[string]any:
+			if ref, ok := v["$ref"].(string); ok {
+				name, found := strings.CutPrefix(ref, "#/components/schemas/")
+				require.True(t, found, "wrong reference %s", ref)
+				require.Contains(t, schemas, name)
+			}
+			for _, el := range v {
+				checkRefs(el)
+			}
+		case []any:
+			for _, el := range v {
+				checkRefs(el)
+			}
+		}
+	}
+	checkRefs(doc)
+
+	// embedded structs are inlined, as in JSON
+	chainOutput := schemas["ChainOutput"].(map[string]any)["properties"].(map[string]any)
+	for _, prop := range []string{"error", "id", "data", "lrbid"} {
+		require.Contains(t, chainOutput, prop)
+	}
+	// custom JSON marshalers are strings
+	lrb := schemas["LatestReliableBranch"].(map[string]any)["properties"].(map[string]any)
+	require.Equal(t, "string", lrb["branch_id"].(map[string]any)["type"])
+}
+
+// TestOpenAPIHandlers hits every registered handler and checks its response against the OpenAPI document
+func TestOpenAPIHandlers(t *testing.T) {
+	f := newOpenAPITestFixture(t)
+	srv := &server{
+		environment: f.env,
+		access:      newAccessControl(&Config{AnonymousScopes: []string{ScopeRead, ScopeSubmit}}),
+	}
+	srv.registerHandlers()
+
+	documented := make(map[string]*apiEndpoint)
+	for i := range apiEndpoints {
+		documented[apiEndpoints[i].path] = &apiEndpoints[i]
+	}
+	registered := make(map[string]bool)
+	for _, pattern := range srv.handlerPatterns {
+		require.Contains(t, documented, pattern, "handler %s is not described in the OpenAPI document", pattern)
+		registered[pattern] = true
+	}
+	for p := range documented {
+		require.True(t, registered[p], "endpoint %s is described in the OpenAPI document but not registered", p)
+	}
+
+	for i := range apiEndpoints {
+		ep := &apiEndpoints[i]
+		t.Run(ep.path, func(t *testing.T) {
+			req := f.request(t, ep)
+			_, pattern := http.DefaultServeMux.Handler(req)
+			require.Equal(t, ep.path, pattern)
+
+			w := httptest.NewRecorder()
+			require.NotPanics(t, func() {
+				http.DefaultServeMux.ServeHTTP(w, req)
+			}, "handler of %s panicked", ep.path)
+			require.Equal(t, http.StatusOK, w.Code)
+			if ep.responseContentType() != "application/json" {
+				require.NotEmpty(t, w.Body.Bytes())
+				return
+			}
+			var generic map[string]any
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &generic))
+			errStr, _ := generic["error"].(string)
+			require.Empty(t, errStr)
+			if ep.response == nil {
+				return
+			}
+			dec := json.NewDecoder(bytes.NewReader(w.Body.Bytes()))
+			dec.DisallowUnknownFields()
+			require.NoError(t, dec.Decode(reflect.New(reflect.TypeOf(ep.response)).Interface()))
+		})
+	}
+}
+
+func TestOpenAPIOperationIDs(t *testing.T) {
+	ids := make(map[string]string)
+	for i := range apiEndpoints {
+		ep := &apiEndpoints[i]
+		id := ep.opID()
+		require.True(t, isIdentifier(id), "operationId '%s' of %s is not an identifier", id, ep.path)
+		prev, dup := ids[id]
+		require.False(t, dup, "operationId '%s' of %s is the same as of %s", id, ep.path, prev)
+		ids[id] = ep.path
+	}
+}
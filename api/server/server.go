@@ -14,11 +14,13 @@ import (
 	"time"
 
 	"github.com/lunfardo314/proxima/api"
+	"github.com/lunfardo314/proxima/core/core_modules/delegation_history"
 	"github.com/lunfardo314/proxima/core/core_modules/tippool"
 	"github.com/lunfardo314/proxima/global"
 	"github.com/lunfardo314/proxima/ledger"
 	"github.com/lunfardo314/proxima/ledger/base"
 	"github.com/lunfardo314/proxima/ledger/multistate"
+	"github.com/lunfardo314/proxima/txstore"
 	"github.com/lunfardo314/proxima/util"
 	"github.com/prometheus/client_golang/prometheus"
 	"golang.org/x/exp/slices"
@@ -38,16 +40,21 @@ type (
 		StateStore() multistate.StateStore
 		TxBytesStore() global.TxBytesStore
 		GetKnownLatestMilestonesJSONAble() map[string]tippool.LatestSequencerTipDataJSONAble
+		GetDelegationHistory(chainID base.ChainID) (*delegation_history.History, error)
+		GetTxRejections(txid *base.TransactionID, maxRecords int) (uint64, []*txstore.TxRejection)
 	}
 
 	server struct {
 		*http.Server
 		environment
 		metrics
+		access *accessControl
+		// patterns of all registered handlers
+		handlerPatterns []string
 	}
 
 	metrics struct {
-		totalRequests prometheus.Counter
+		requests *prometheus.CounterVec
 	}
 )
 
@@ -56,6 +63,8 @@ const TraceTag = "apiServer"
 func (srv *server) registerHandlers() {
 	// GET request format: '/api/v1/get_ledger_id'
 	srv.addHandler(api.PathGetLedgerIDData, srv.getLedgerIDData)
+	// GET request format: '/api/v1/get_library_upgrades'
+	srv.addHandler(api.PathGetLibraryUpgrades, srv.getLibraryUpgrades)
 	// GET request format: '/api/v1/get_account_outputs?accountable=<EasyFL source form of the accountable lock constraint>'
 	srv.addHandler(api.PathGetAccountOutputs, srv.getAccountOutputs)
 	// GET request format: '/api/v1/get_account_parsed_outputs?accountable=<EasyFL source form of the accountable lock constraint>'
@@ -92,8 +101,24 @@ func (srv *server) registerHandlers() {
 	srv.addHandler(api.PathGetAllChains, srv.getAllChains)
 	// GET all chains in the LRB /get_delegations_by_sequencer
 	srv.addHandler(api.PathGetDelegationsBySequencer, srv.getDelegationsBySequencer)
+	// GET history of earnings of the delegation chain /delegation_history?chainid=<hex-encoded chain id>
+	srv.addHandler(api.PathGetDelegationHistory, srv.getDelegationHistory)
+	// GET rejected transactions /tx_rejections?[txid=<hex-encoded transaction id>][&max=]
+	srv.addHandler(api.PathGetTxRejections, srv.getTxRejections)
+	// GET transactions touching the account /account_history?addr=<a(0x....)>[&since=<slot>][&max=]
+	srv.addHandler(api.PathGetAccountHistory, srv.getAccountHistory)
+	// GET counters of the sequencer running on the node /sequencer_stats
+	srv.addHandler(api.PathGetSequencerStats, srv.getSequencerStats)
+	// GET transitions of the chain /chain_history?chainid=<hex-encoded chain id>[&max=]
+	srv.addHandler(api.PathGetChainHistory, srv.getChainHistory)
 	// GET dashboard for node
 	srv.addHandler(api.PathGetDashboard, srv.getDashboard)
+	// GET scripts and styles of the dashboard '/dashboard/<file name>'
+	srv.addHandler(api.PathGetDashboardAssets, srv.getDashboardAssets)
+	// GET OpenAPI document of the API '/api/v1/openapi.json'
+	srv.addHandler(api.PathGetOpenAPI, srv.getOpenAPI)
+	// GET API explorer page '/api/v1/explorer'
+	srv.addHandler(api.PathGetAPIExplorer, srv.getAPIExplorer)
 
 	// register handlers of tx API
 	srv.registerTxAPIHandlers()
@@ -107,6 +132,20 @@ func (srv *server) getLedgerIDData(w http.ResponseWriter, _ *http.Request) {
 	util.AssertNoError(err)
 }
 
+// getLibraryUpgrades returns YAML of the schedule of ledger library upgrades committed in the state.
+// The list of upgrades is empty if there are no upgrades
+func (srv *server) getLibraryUpgrades(w http.ResponseWriter, _ *http.Request) {
+	api.SetHeader(w)
+
+	srv.Tracef(TraceTag, "getLibraryUpgrades invoked")
+	upgradesYAML := multistate.LibraryUpgradesBytesFromStore(srv.StateStore())
+	if len(upgradesYAML) == 0 {
+		upgradesYAML = (&ledger.LibraryUpgrades{}).YAML()
+	}
+	_, err := w.Write(upgradesYAML)
+	util.AssertNoError(err)
+}
+
 func (srv *server) _getAccountOutputsWithFilter(r *http.Request, addr ledger.Accountable, filter func(oid base.OutputID, o *ledger.Output) bool) (
 	outs []*ledger.OutputWithID, lrbid base.TransactionID, err error) {
 	if filter == nil {
@@ -773,6 +812,182 @@ func (srv *server) getDelegationsBySequencer(w http.ResponseWriter, _ *http.Requ
 	srv.AssertNoError(err)
 }
 
+func (srv *server) getDelegationHistory(w http.ResponseWriter, r *http.Request) {
+	api.SetHeader(w)
+
+	lst, ok := r.URL.Query()["chainid"]
+	if !ok || len(lst) != 1 {
+		api.WriteErr(w, "wrong parameters in request 'delegation_history'")
+		return
+	}
+	chainID, err := base.ChainIDFromHexString(lst[0])
+	if err != nil {
+		api.WriteErr(w, err.Error())
+		return
+	}
+	h, err := srv.GetDelegationHistory(chainID)
+	if err != nil {
+		api.WriteErr(w, err.Error())
+		return
+	}
+
+	resp := api.DelegationHistory{
+		ChainID:     chainID.StringHex(),
+		Truncated:   h.Truncated,
+		Transitions: make([]api.DelegationTransition, len(h.Transitions)),
+	}
+	resp.TotalEarned, resp.TotalMargin = h.TotalEarned()
+	if h.Lock != nil {
+		resp.OwnerLock = h.Lock.OwnerLock.String()
+		resp.TargetLock = h.Lock.TargetLock.String()
+		resp.StartSlot = uint32(h.Lock.StartTime.Slot)
+		resp.StartAmount = h.Lock.StartAmount
+	}
+	for i, t := range h.Transitions {
+		resp.Transitions[i] = api.DelegationTransition{
+			OutputID:  t.OutputID.StringHex(),
+			Slot:      uint32(t.OutputID.Slot()),
+			Amount:    t.Amount,
+			Inflation: t.Inflation,
+			Earned:    t.Earned,
+			Margin:    t.Margin,
+		}
+		if t.SequencerID != nil {
+			resp.Transitions[i].SequencerID = t.SequencerID.StringHex()
+		}
+	}
+	perSlot := h.EarnedPerSlot()
+	resp.EarnedPerSlot = make([]api.DelegationSlotEarnings, len(perSlot))
+	for i, e := range perSlot {
+		resp.EarnedPerSlot[i] = api.DelegationSlotEarnings{Slot: uint32(e.Slot), Earned: e.Earned}
+	}
+	respBin, err := json.MarshalIndent(resp, "", "  ")
+	if err != nil {
+		api.WriteErr(w, err.Error())
+		return
+	}
+	_, err = w.Write(respBin)
+	util.AssertNoError(err)
+}
+
+const (
+	defaultMaxTxRejections = 100
+	maxTxRejections        = 1000
+)
+
+func (srv *server) getTxRejections(w http.ResponseWriter, r *http.Request) {
+	api.SetHeader(w)
+
+	var txid *base.TransactionID
+	if lst, ok := r.URL.Query()["txid"]; ok {
+		if len(lst) != 1 {
+			api.WriteErr(w, "wrong parameter 'txid' in request 'tx_rejections'")
+			return
+		}
+		id, err := base.TransactionIDFromHexString(lst[0])
+		if err != nil {
+			api.WriteErr(w, err.Error())
+			return
+		}
+		txid = &id
+	}
+	maxRecords := defaultMaxTxRejections
+	if lst, ok := r.URL.Query()["max"]; ok {
+		var err error
+		if len(lst) != 1 {
+			api.WriteErr(w, "wrong parameter 'max' in request 'tx_rejections'")
+			return
+		}
+		if maxRecords, err = strconv.Atoi(lst[0]); err != nil {
+			api.WriteErr(w, err.Error())
+			return
+		}
+		if maxRecords <= 0 || maxRecords > maxTxRejections {
+			maxRecords = maxTxRejections
+		}
+	}
+
+	total, recs := srv.GetTxRejections(txid, maxRecords)
+	resp := api.TxRejections{
+		Total:      total,
+		Rejections: make([]api.TxRejection, len(recs)),
+	}
+	for i, rec := range recs {
+		resp.Rejections[i] = api.TxRejection{
+			TxID:   rec.TxID.StringHex(),
+			Reason: rec.Reason,
+			Slot:   uint32(rec.Slot),
+			Source: rec.Source.String(),
+			Time:   rec.Time.Unix(),
+		}
+	}
+	respBin, err := json.MarshalIndent(resp, "", "  ")
+	if err != nil {
+		api.WriteErr(w, err.Error())
+		return
+	}
+	_, err = w.Write(respBin)
+	util.AssertNoError(err)
+}
+
+// names of the sequencer metrics. Sequencer statistics is taken from the metrics registry of the node
+const (
+	metricSeqMilestones          = "proxima_seq_milestones"
+	metricSeqBranches            = "proxima_seq_branches"
+	metricSeqTargets             = "proxima_seq_targets"
+	metricSeqProposalsPrefix     = "proxima_seq_proposals_"
+	metricSeqBestProposalsPrefix = "proxima_seq_best_proposals_"
+	metricSeqBacklogSize         = "proxima_seq_backlog_size"
+	metricSeqOwnMilestones       = "proxima_seq_own_milestones"
+)
+
+func (srv *server) getSequencerStats(w http.ResponseWriter, _ *http.Request) {
+	api.SetHeader(w)
+
+	resp := api.SequencerStats{
+		Proposals:     make(map[string]uint64),
+		BestProposals: make(map[string]uint64),
+	}
+	if seqID := srv.GetNodeInfo().Sequencer; seqID != nil {
+		resp.SequencerID = seqID.StringHex()
+	}
+	families, err := srv.MetricsRegistry().Gather()
+	if err != nil {
+		api.WriteErr(w, err.Error())
+		return
+	}
+	for _, mf := range families {
+		if len(mf.GetMetric()) == 0 {
+			continue
+		}
+		m := mf.GetMetric()[0]
+		name := mf.GetName()
+		switch {
+		case name == metricSeqMilestones:
+			resp.Milestones = uint64(m.GetCounter().GetValue())
+		case name == metricSeqBranches:
+			resp.Branches = uint64(m.GetCounter().GetValue())
+		case name == metricSeqTargets:
+			resp.Targets = uint64(m.GetCounter().GetValue())
+		case name == metricSeqBacklogSize:
+			resp.BacklogSize = int(m.GetGauge().GetValue())
+		case name == metricSeqOwnMilestones:
+			resp.OwnMilestones = int(m.GetGauge().GetValue())
+		case strings.HasPrefix(name, metricSeqProposalsPrefix):
+			resp.Proposals[strings.TrimPrefix(name, metricSeqProposalsPrefix)] = uint64(m.GetCounter().GetValue())
+		case strings.HasPrefix(name, metricSeqBestProposalsPrefix):
+			resp.BestProposals[strings.TrimPrefix(name, metricSeqBestProposalsPrefix)] = uint64(m.GetCounter().GetValue())
+		}
+	}
+	respBin, err := json.MarshalIndent(resp, "", "  ")
+	if err != nil {
+		api.WriteErr(w, err.Error())
+		return
+	}
+	_, err = w.Write(respBin)
+	util.AssertNoError(err)
+}
+
 func (srv *server) getLatestReliableBranch(w http.ResponseWriter, _ *http.Request) {
 	api.SetHeader(w)
 
@@ -854,35 +1069,34 @@ func (srv *server) withLRB(fun func(rdr multistate.SugaredStateReader) error) er
 	})
 }
 
-func Run(addr string, env environment) {
+func Run(cfg *Config, env environment) {
 	srv := &server{
 		Server: &http.Server{
-			Addr:         addr,
+			Addr:         cfg.Addr,
 			ReadTimeout:  10 * time.Second,
 			WriteTimeout: 10 * time.Second,
 			IdleTimeout:  10 * time.Second,
 		},
 		environment: env,
+		access:      newAccessControl(cfg),
 	}
 	srv.registerHandlers()
+	for pattern, handler := range cfg.StreamHandlers {
+		srv.addHandler(pattern, handler)
+	}
+	srv.checkOpenAPIDocumented()
 	srv.registerMetrics()
 
-	err := srv.ListenAndServe()
+	var err error
+	if cfg.CertFile != "" {
+		err = srv.ListenAndServeTLS(cfg.CertFile, cfg.KeyFile)
+	} else {
+		err = srv.ListenAndServe()
+	}
 	util.AssertNoError(err)
 }
 
-func (srv *server) registerMetrics() {
-	srv.metrics.totalRequests = prometheus.NewCounter(prometheus.CounterOpts{
-		Name: "proxima_api_totalRequests",
-		Help: "total API requests",
-	})
-	srv.MetricsRegistry().MustRegister(srv.metrics.totalRequests)
-}
-
 func (srv *server) addHandler(pattern string, handler func(http.ResponseWriter, *http.Request)) {
-	http.HandleFunc(pattern, func(w http.ResponseWriter, r *http.Request) {
-		srv.Tracef(TraceTag, "API request: %s from %s", r.URL.String(), r.RemoteAddr)
-		handler(w, r)
-		srv.metrics.totalRequests.Inc()
-	})
+	srv.handlerPatterns = append(srv.handlerPatterns, pattern)
+	http.HandleFunc(pattern, srv.wrapHandler(pattern, handler))
 }
@@ -77,10 +77,10 @@ func TestParseOutputData(t *testing.T) {
 	addr := ledger.AddressED25519FromPrivateKey(testutil.GetTestingPrivateKey(100))
 	chanID := base.RandomChainID()
 	cc := ledger.NewChainConstraint(chanID, 1, 2, 0)
-	o := ledger.NewOutput(func(o *ledger.Output) {
+	o := ledger.NewOutput(func(o *ledger.OutputBuilder) {
 		o.WithAmount(amount).
 			WithLock(addr)
-		o.PushConstraint(cc.Bytes())
+		o.MustPushConstraint(cc.Bytes())
 	})
 	oDataStr := hex.EncodeToString(o.Bytes())
 	reqStr := fmt.Sprintf("/txapi/v1/parse_output_data?output_data=%s", oDataStr)
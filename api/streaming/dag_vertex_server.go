@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"net/http"
 	"sync"
+	"sync/atomic"
 
 	"github.com/gorilla/websocket"
 	"github.com/lunfardo314/proxima/api"
@@ -21,20 +22,63 @@ type (
 		OnTransaction(fun func(tx *transaction.Transaction) bool)
 		OnTxDeleted(fun func(txid base.TransactionID) bool) // called whenever tx is GCed. Could be useful for the visualizer
 		TxBytesStore() global.TxBytesStore
+		ForkInfo() *api.ForkInfo
+		OnForkEvent(fun func(info *api.ForkInfo) bool) (remove func())
 	}
 	wsServer struct {
 		environment
+		maxConnections int32
+		connections    atomic.Int32
 	}
 )
 
-const TraceTag = "streaming"
+const (
+	TraceTag = "streaming"
 
-func Run(env environment) {
+	DefaultMaxConnections = 100
+)
+
+// Handlers returns web socket stream handlers by path. They are served by the API server, with the same API key check
+// and rate limits as other endpoints. Number of simultaneously connected stream clients is limited by maxConnections
+func Handlers(env environment, maxConnections int) map[string]func(http.ResponseWriter, *http.Request) {
+	if maxConnections <= 0 {
+		maxConnections = DefaultMaxConnections
+	}
 	srv := &wsServer{
-		environment: env,
+		environment:    env,
+		maxConnections: int32(maxConnections),
+	}
+	srv.Log().Infof("[%s] web socket streaming is enabled, max connections: %d", TraceTag, maxConnections)
+	return map[string]func(http.ResponseWriter, *http.Request){
+		api.PathDAGVertexStream: srv.dagVertexStreamHandler,
+		api.PathForkEventStream: srv.forkEventStreamHandler,
+	}
+}
+
+// upgrade takes connection slot and upgrades the request to the web socket connection.
+// The slot must be released with releaseConnection when the connection is closed
+func (srv *wsServer) upgrade(w http.ResponseWriter, r *http.Request) (*websocket.Conn, bool) {
+	if srv.connections.Add(1) > srv.maxConnections {
+		srv.connections.Add(-1)
+		srv.Log().Warnf("[%s] too many stream connections, rejected remote: %s", TraceTag, r.RemoteAddr)
+		api.SetHeader(w)
+		w.WriteHeader(http.StatusServiceUnavailable)
+		api.WriteErr(w, "too many stream connections")
+		return nil, false
+	}
+	u := websocket.Upgrader{CheckOrigin: func(r *http.Request) bool { return true }}
+	conn, err := u.Upgrade(w, r, nil)
+	if err != nil {
+		srv.connections.Add(-1)
+		srv.Log().Warnf("[%s] WebSocket upgrade failed, remote: %s", TraceTag, r.RemoteAddr)
+		api.WriteErr(w, "failed to upgrade to websocket connection")
+		return nil, false
 	}
-	srv.Log().Infof("[%s] web socket steraming is running", TraceTag)
-	http.HandleFunc(api.PathDAGVertexStream, srv.dagVertexStreamHandler)
+	return conn, true
+}
+
+func (srv *wsServer) releaseConnection() {
+	srv.connections.Add(-1)
 }
 
 func vertexDepsForTx(srv *wsServer, txidstr string) []byte {
@@ -70,11 +114,8 @@ func vertexDepsForTx(srv *wsServer, txidstr string) []byte {
 const keepMaxSlots = 10 // Keep only last 10 slots
 
 func (srv *wsServer) dagVertexStreamHandler(w http.ResponseWriter, r *http.Request) {
-	u := websocket.Upgrader{CheckOrigin: func(r *http.Request) bool { return true }}
-	conn, err := u.Upgrade(w, r, nil)
-	if err != nil {
-		srv.Log().Warnf("[%s] WebSocket upgrade failed, remote: %s", TraceTag, r.RemoteAddr)
-		api.WriteErr(w, "failed to upgrade to websocket connection")
+	conn, ok := srv.upgrade(w, r)
+	if !ok {
 		return
 	}
 
@@ -93,6 +134,7 @@ func (srv *wsServer) dagVertexStreamHandler(w http.ResponseWriter, r *http.Reque
 			if err != nil {
 				srv.Log().Infof("[%s] WebSocket client disconnected, remote: %s, err: %v", TraceTag, r.RemoteAddr, err)
 				_ = conn.Close() // explicitly close the connection
+				srv.releaseConnection()
 				return
 			}
 
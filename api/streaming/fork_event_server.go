@@ -0,0 +1,58 @@
+package streaming
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/gorilla/websocket"
+	"github.com/lunfardo314/proxima/api"
+	"github.com/lunfardo314/proxima/util"
+)
+
+// forkEventStreamHandler sends current fork status when the client connects, then api.ForkInfo
+// each time fork is detected or resolved
+func (srv *wsServer) forkEventStreamHandler(w http.ResponseWriter, r *http.Request) {
+	conn, ok := srv.upgrade(w, r)
+	if !ok {
+		return
+	}
+	srv.Log().Infof("[%s] fork event stream client connected, remote: %s", TraceTag, r.RemoteAddr)
+
+	send := func(info *api.ForkInfo) bool {
+		respBin, err := json.MarshalIndent(info, "", "  ")
+		util.AssertNoError(err)
+
+		if err = conn.WriteMessage(websocket.TextMessage, respBin); err != nil {
+			srv.Log().Infof("[%s] fork event stream client disconnected, remote: %s, err = %v", TraceTag, r.RemoteAddr, err)
+			return false
+		}
+		return true
+	}
+
+	info := srv.ForkInfo()
+	if info == nil {
+		_ = conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, "fork monitor is disabled"))
+		_ = conn.Close()
+		srv.releaseConnection()
+		return
+	}
+	if !send(info) {
+		_ = conn.Close()
+		srv.releaseConnection()
+		return
+	}
+	removeHandler := srv.OnForkEvent(send)
+
+	go func() {
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				srv.Log().Infof("[%s] fork event stream client disconnected, remote: %s, err: %v", TraceTag, r.RemoteAddr, err)
+				// the handler is removed right away, not when the next event fails to be sent
+				removeHandler()
+				_ = conn.Close()
+				srv.releaseConnection()
+				return
+			}
+		}
+	}()
+}
@@ -29,5 +29,4 @@ func WriteOk(w http.ResponseWriter) {
 
 func SetHeader(w http.ResponseWriter) {
 	w.Header().Set("Content-Type", "application/json")
-	w.Header().Set("Access-Control-Allow-Origin", "*")
 }
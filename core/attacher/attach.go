@@ -84,6 +84,7 @@ func AttachTxID(txid base.TransactionID, env Environment, opts ...AttachTxOption
 			err := fmt.Errorf("baseline branch state %s is before snapshot slot %d and is not available -> can't solidify baseline",
 				txid.String(), snapID.Slot())
 			vid.SetTxStatusBadNoLock(err)
+			env.PostEventTxRejected(txid, err, nil)
 		}
 	})
 	return
@@ -170,12 +171,18 @@ func AttachTransactionFromBytes(txBytes []byte, env Environment, opts ...AttachT
 	return AttachTransaction(tx, env, opts...), nil
 }
 
-// InvalidateTxID marks existing vertex as BAD or creates new BAD
-func InvalidateTxID(txid base.TransactionID, env Environment, reason error) {
+// InvalidateTxID marks existing vertex as BAD or creates new BAD. Metadata, if provided with options,
+// is used to report the source of the rejected transaction
+func InvalidateTxID(txid base.TransactionID, env Environment, reason error, opts ...AttachTxOption) {
+	options := &_attacherOptions{}
+	for _, opt := range opts {
+		opt(options)
+	}
 	env.Tracef(TraceTagAttach, "InvalidateTxID: %s", txid.StringShort())
 
 	vid := AttachTxID(txid, env, WithInvokedBy("InvalidateTxID"))
 	vid.SetTxStatusBad(reason)
+	env.PostEventTxRejected(txid, reason, options.metadata)
 }
 
 func AttachOutputID(oid base.OutputID, env Environment, opts ...AttachTxOption) vertex.WrappedOutput {
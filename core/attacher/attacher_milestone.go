@@ -50,6 +50,7 @@ func runMilestoneAttacher(
 
 	if err = a.run(); err != nil {
 		vid.SetTxStatusBad(err)
+		env.PostEventTxRejected(vid.ID(), err, metadata)
 		if !errors.Is(err, ErrSolidificationDeadline) {
 			// solidification errors with big attachment depth are too verbose
 			env.Log().Warnf(a.logErrorStatusString(err))
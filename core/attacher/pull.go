@@ -10,6 +10,9 @@ import (
 
 const TraceTagPull = "pull"
 
+// pullPastConeSlots number of slots before the slot of the branch for which past cone of the branch is pulled together with the branch
+const pullPastConeSlots = 1
+
 func (a *attacher) pullIfNeeded(deptVID *vertex.WrappedTx, tag string) bool {
 	a.Tracef(TraceTagPull, "pullIfNeeded IN (%s): %s", tag, deptVID.IDShortString)
 	ok := true
@@ -80,7 +83,13 @@ func (a *attacher) pull(virtualTx *vertex.VirtualTransaction, deptVID *vertex.Wr
 	a.AddWantedTransaction(deptVID.ID())
 	// do not pull is node is not connected to any peer longer than 2 pull repeat periods
 	if a.DurationSinceLastMessageFromPeer() <= 2*repeatPullAfter {
-		a.PullFromNPeers(nPeers, deptVID.ID())
+		if deptVID.IsBranchTransaction() && virtualTx.TimesPulled() == 0 {
+			// missing branch usually means the node is behind, so the past cone of the branch in the last slots
+			// is most likely missing too. Pull it in one request. Repeated pulls request the branch only
+			a.PullWithPastConeFromNPeers(nPeers, deptVID.ID(), deptVID.Slot()-min(deptVID.Slot(), pullPastConeSlots))
+		} else {
+			a.PullFromNPeers(nPeers, deptVID.ID())
+		}
 		virtualTx.SetPullHappened(repeatPullAfter)
 
 		a.Tracef(TraceTagPull, "pull: %s", deptVID.IDShortString)
@@ -33,10 +33,12 @@ type (
 		PokeMe(me, with *vertex.WrappedTx)
 		PokeAllWith(wanted *vertex.WrappedTx)
 		PullFromNPeers(nPeers int, txid base.TransactionID) int
+		PullWithPastConeFromNPeers(nPeers int, txid base.TransactionID, downToSlot base.Slot) int
 	}
 
 	postEventEnvironment interface {
 		PostEventNewTransaction(vid *vertex.WrappedTx)
+		PostEventTxRejected(txid base.TransactionID, reason error, metadata *txmetadata.TransactionMetadata)
 	}
 
 	Environment interface {
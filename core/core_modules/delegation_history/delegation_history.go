@@ -0,0 +1,318 @@
+// Package delegation_history implements accounting of delegation rewards.
+// It follows transitions of delegation chains along the latest reliable branches and keeps, for each
+// delegation, amounts of inflation earned by the delegator and the margin kept by the target sequencer
+package delegation_history
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/lunfardo314/proxima/global"
+	"github.com/lunfardo314/proxima/ledger"
+	"github.com/lunfardo314/proxima/ledger/base"
+	"github.com/lunfardo314/proxima/ledger/multistate"
+	"github.com/lunfardo314/proxima/ledger/transaction"
+	"github.com/lunfardo314/proxima/txstore"
+	"github.com/lunfardo314/proxima/util"
+	"github.com/spf13/viper"
+)
+
+type (
+	environment interface {
+		global.NodeGlobal
+		TxBytesStore() global.TxBytesStore
+		LatestReliableState() (multistate.SugaredStateReader, error)
+	}
+
+	// Transition is one step of the delegation chain
+	Transition struct {
+		// ID of the produced chain output
+		OutputID base.OutputID
+		// amount on the produced chain output
+		Amount uint64
+		// inflation generated on the chain by the transition
+		Inflation uint64
+		// part of the inflation which went to the delegation
+		Earned uint64
+		// part of the inflation kept by the sequencer
+		Margin uint64
+		// sequencer which made the transition. nil if transition was made by the owner
+		SequencerID *base.ChainID
+	}
+
+	// History is the accounting data of one delegation chain
+	History struct {
+		ChainID base.ChainID
+		// latest known delegation lock. nil if the chain is not delegation-locked anymore
+		Lock *ledger.DelegationLock
+		// Truncated is true when the history does not reach the origin of the chain
+		Truncated bool
+		// transitions in ascending order of time
+		Transitions []Transition
+		lastOutput  *ledger.Output
+		lastUpdated time.Time
+	}
+
+	DelegationHistory struct {
+		environment
+		mutex          sync.RWMutex
+		updateMutex    sync.Mutex
+		chains         map[base.ChainID]*History
+		maxTransitions int
+		keepSlots      int
+	}
+)
+
+const (
+	Name     = "delegation_history"
+	TraceTag = Name
+
+	defaultMaxTransitions = 2000
+	defaultKeepSlots      = 10_000
+)
+
+var ErrNotFound = errors.New("delegation not found")
+
+func New(env environment) *DelegationHistory {
+	ret := &DelegationHistory{
+		environment:    env,
+		chains:         make(map[base.ChainID]*History),
+		maxTransitions: viper.GetInt("delegation_history.max_transitions"),
+		keepSlots:      viper.GetInt("delegation_history.keep_slots"),
+	}
+	if ret.maxTransitions <= 0 {
+		ret.maxTransitions = defaultMaxTransitions
+	}
+	if ret.keepSlots <= 0 {
+		ret.keepSlots = defaultKeepSlots
+	}
+	if viper.GetBool("delegation_history.disable") {
+		env.Log().Infof("[%s] background tracking of delegations is disabled. Delegation history will be collected on request", Name)
+		return ret
+	}
+	env.RepeatInBackground(Name+"_loop", ledger.L().ID.SlotDuration(), func() bool {
+		ret.updateAll()
+		return true
+	}, true)
+	env.Log().Infof("[%s] STARTED. Max transitions per delegation: %d", Name, ret.maxTransitions)
+	return ret
+}
+
+// updateAll follows all delegation chains in the latest reliable branch
+func (d *DelegationHistory) updateAll() {
+	rdr, err := d.LatestReliableState()
+	if err != nil {
+		d.Tracef(TraceTag, "updateAll: %v", err)
+		return
+	}
+	tips := make(map[base.ChainID]base.OutputID)
+	err = rdr.IterateChainedOutputs(func(out ledger.OutputWithChainID) bool {
+		if out.Output.DelegationLock() != nil {
+			tips[out.ChainID] = out.ID
+		}
+		return true
+	})
+	if err != nil {
+		d.Log().Errorf("[%s] %v", Name, err)
+		return
+	}
+	for chainID, oid := range tips {
+		if err = d.update(chainID, oid); err != nil {
+			d.Tracef(TraceTag, "update %s: %v", chainID.StringShort(), err)
+		}
+	}
+	d.purge()
+}
+
+// purge removes history of delegation chains which were not seen in the LRB for too long
+func (d *DelegationHistory) purge() {
+	d.purgeOlderThan(time.Duration(d.keepSlots) * ledger.L().ID.SlotDuration())
+}
+
+func (d *DelegationHistory) purgeOlderThan(ttl time.Duration) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	for chainID, h := range d.chains {
+		if time.Since(h.lastUpdated) > ttl {
+			delete(d.chains, chainID)
+		}
+	}
+}
+
+// update walks the chain back from the tip until the latest known transition and appends new transitions to the history
+// Updates are serialized, readers are blocked only while new transitions are being appended
+func (d *DelegationHistory) update(chainID base.ChainID, tip base.OutputID) error {
+	d.updateMutex.Lock()
+	defer d.updateMutex.Unlock()
+
+	d.mutex.RLock()
+	h := d.chains[chainID]
+	var last *Transition
+	var lastOutput *ledger.Output
+	if h != nil && len(h.Transitions) > 0 {
+		last = util.Ref(h.Transitions[len(h.Transitions)-1])
+		lastOutput = h.lastOutput
+	}
+	d.mutex.RUnlock()
+
+	if last != nil && last.OutputID == tip {
+		d.mutex.Lock()
+		h.lastUpdated = time.Now()
+		d.mutex.Unlock()
+		return nil
+	}
+
+	type step struct {
+		tx  *transaction.Transaction
+		out *ledger.OutputWithID
+	}
+	steps := make([]step, 0)
+	reachedLast, reachedOrigin := false, false
+	err := txstore.IterateChainBackwards(d.TxBytesStore(), tip, func(tx *transaction.Transaction, o *ledger.OutputWithID, cc *ledger.ChainConstraint) bool {
+		if last != nil && o.ID == last.OutputID {
+			reachedLast = true
+			return false
+		}
+		steps = append(steps, step{tx: tx, out: o})
+		reachedOrigin = cc.IsOrigin()
+		return len(steps) < d.maxTransitions
+	})
+	if err != nil && !errors.Is(err, txstore.ErrTransactionNotFound) {
+		return err
+	}
+	if len(steps) == 0 {
+		return fmt.Errorf("can't follow chain %s from %s: %v", chainID.StringShort(), tip.StringShort(), err)
+	}
+	slices.Reverse(steps)
+
+	newTransitions := make([]Transition, len(steps))
+	for i, s := range steps {
+		var pred *ledger.Output
+		switch {
+		case i > 0:
+			pred = steps[i-1].out.Output
+		case reachedLast:
+			pred = lastOutput
+		}
+		newTransitions[i] = makeTransition(s.tx, s.out, pred)
+	}
+
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	if !reachedLast || h == nil {
+		h = &History{
+			ChainID:   chainID,
+			Truncated: !reachedOrigin,
+		}
+		d.chains[chainID] = h
+	}
+	h.appendTransitions(newTransitions, steps[len(steps)-1].out.Output, d.maxTransitions)
+	return nil
+}
+
+// appendTransitions appends new transitions and keeps at most maxTransitions latest of them
+func (h *History) appendTransitions(transitions []Transition, lastOutput *ledger.Output, maxTransitions int) {
+	h.Transitions = append(h.Transitions, transitions...)
+	if len(h.Transitions) > maxTransitions {
+		h.Transitions = slices.Clone(h.Transitions[len(h.Transitions)-maxTransitions:])
+		h.Truncated = true
+	}
+	h.lastOutput = lastOutput
+	h.Lock = nil
+	if lastOutput != nil {
+		h.Lock = lastOutput.DelegationLock()
+	}
+	h.lastUpdated = time.Now()
+}
+
+func makeTransition(tx *transaction.Transaction, o *ledger.OutputWithID, pred *ledger.Output) Transition {
+	ret := Transition{
+		OutputID:  o.ID,
+		Amount:    o.Output.Amount(),
+		Inflation: o.Output.Inflation(),
+	}
+	if seqData := tx.SequencerTransactionData(); seqData != nil {
+		ret.SequencerID = util.Ref(seqData.SequencerID)
+	}
+	if pred == nil || pred.DelegationLock() == nil || ret.SequencerID == nil {
+		// only transitions of delegated chain made by the sequencer are counted as earnings
+		return ret
+	}
+	if ret.Amount > pred.Amount() {
+		ret.Earned = ret.Amount - pred.Amount()
+	}
+	if ret.Inflation > ret.Earned {
+		ret.Margin = ret.Inflation - ret.Earned
+	}
+	return ret
+}
+
+// GetHistory returns a copy of the delegation history. If the delegation chain is in the latest reliable branch,
+// the history is updated up to the current tip before returning
+func (d *DelegationHistory) GetHistory(chainID base.ChainID) (*History, error) {
+	rdr, err := d.LatestReliableState()
+	if err != nil {
+		return nil, err
+	}
+	d.mutex.RLock()
+	_, known := d.chains[chainID]
+	d.mutex.RUnlock()
+
+	if o, err1 := rdr.GetChainOutput(chainID); err1 == nil && (known || o.Output.DelegationLock() != nil) {
+		if err = d.update(chainID, o.ID); err != nil {
+			return nil, err
+		}
+	}
+
+	d.mutex.RLock()
+	defer d.mutex.RUnlock()
+
+	h, ok := d.chains[chainID]
+	if !ok {
+		return nil, ErrNotFound
+	}
+	return &History{
+		ChainID:     h.ChainID,
+		Lock:        h.Lock,
+		Truncated:   h.Truncated,
+		Transitions: slices.Clone(h.Transitions),
+	}, nil
+}
+
+// TotalEarned returns totals of earned inflation and margin kept by sequencers over all transitions in the history
+func (h *History) TotalEarned() (earned, margin uint64) {
+	for i := range h.Transitions {
+		earned += h.Transitions[i].Earned
+		margin += h.Transitions[i].Margin
+	}
+	return
+}
+
+// SlotEarnings is the amount earned by the delegation in the slot
+type SlotEarnings struct {
+	Slot   base.Slot
+	Earned uint64
+}
+
+// EarnedPerSlot returns earned amounts summed up per slot, in ascending order of slots.
+// Slots without earnings are skipped
+func (h *History) EarnedPerSlot() []SlotEarnings {
+	ret := make([]SlotEarnings, 0)
+	for i := range h.Transitions {
+		if h.Transitions[i].Earned == 0 {
+			continue
+		}
+		slot := h.Transitions[i].OutputID.Slot()
+		if len(ret) > 0 && ret[len(ret)-1].Slot == slot {
+			ret[len(ret)-1].Earned += h.Transitions[i].Earned
+		} else {
+			ret = append(ret, SlotEarnings{Slot: slot, Earned: h.Transitions[i].Earned})
+		}
+	}
+	return ret
+}
@@ -0,0 +1,66 @@
+package delegation_history
+
+import (
+	"testing"
+	"time"
+
+	"github.com/lunfardo314/proxima/ledger/base"
+	"github.com/stretchr/testify/require"
+)
+
+func transitionAt(t *testing.T, slot base.Slot, earned, margin uint64) Transition {
+	txid := base.RandomTransactionID(true, 1, base.NewLedgerTime(slot, 1))
+	oid, err := base.NewOutputID(txid, 0)
+	require.NoError(t, err)
+	return Transition{OutputID: oid, Earned: earned, Margin: margin, Inflation: earned + margin}
+}
+
+func TestHistory(t *testing.T) {
+	t.Run("accumulate", func(t *testing.T) {
+		h := &History{}
+		h.appendTransitions([]Transition{transitionAt(t, 10, 0, 0), transitionAt(t, 11, 90, 10)}, nil, 100)
+		h.appendTransitions([]Transition{transitionAt(t, 11, 45, 5), transitionAt(t, 13, 9, 1)}, nil, 100)
+		require.Equal(t, 4, len(h.Transitions))
+		require.False(t, h.Truncated)
+		require.Nil(t, h.Lock)
+
+		earned, margin := h.TotalEarned()
+		require.EqualValues(t, 144, earned)
+		require.EqualValues(t, 16, margin)
+
+		require.EqualValues(t, []SlotEarnings{{Slot: 11, Earned: 135}, {Slot: 13, Earned: 9}}, h.EarnedPerSlot())
+	})
+	t.Run("trim", func(t *testing.T) {
+		const maxTransitions = 5
+		h := &History{}
+		for i := 0; i < 3; i++ {
+			batch := []Transition{transitionAt(t, base.Slot(10+2*i), 1, 0), transitionAt(t, base.Slot(11+2*i), 1, 0)}
+			h.appendTransitions(batch, nil, maxTransitions)
+		}
+		require.Equal(t, maxTransitions, len(h.Transitions))
+		require.True(t, h.Truncated)
+		// the oldest transition is dropped
+		require.EqualValues(t, 11, h.Transitions[0].OutputID.Slot())
+		require.EqualValues(t, 15, h.Transitions[maxTransitions-1].OutputID.Slot())
+		earned, _ := h.TotalEarned()
+		require.EqualValues(t, maxTransitions, earned)
+	})
+	t.Run("no earnings", func(t *testing.T) {
+		h := &History{}
+		require.EqualValues(t, 0, len(h.EarnedPerSlot()))
+		h.appendTransitions([]Transition{transitionAt(t, 10, 0, 0)}, nil, 100)
+		require.EqualValues(t, 0, len(h.EarnedPerSlot()))
+	})
+}
+
+func TestPurge(t *testing.T) {
+	d := &DelegationHistory{chains: make(map[base.ChainID]*History)}
+	fresh, stale := base.RandomChainID(), base.RandomChainID()
+	d.chains[fresh] = &History{ChainID: fresh, lastUpdated: time.Now()}
+	d.chains[stale] = &History{ChainID: stale, lastUpdated: time.Now().Add(-time.Hour)}
+
+	d.purgeOlderThan(time.Minute)
+	require.Equal(t, 1, len(d.chains))
+	_, ok := d.chains[fresh]
+	require.True(t, ok)
+}
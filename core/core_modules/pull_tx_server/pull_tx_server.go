@@ -1,12 +1,15 @@
 package pull_tx_server
 
 import (
+	"sort"
+
 	"github.com/libp2p/go-libp2p/core/peer"
 	"github.com/lunfardo314/proxima/core/core_modules"
 	"github.com/lunfardo314/proxima/core/txmetadata"
 	"github.com/lunfardo314/proxima/global"
 	"github.com/lunfardo314/proxima/ledger/base"
 	"github.com/lunfardo314/proxima/ledger/multistate"
+	"github.com/lunfardo314/proxima/ledger/transaction"
 	"github.com/lunfardo314/proxima/peering"
 	"github.com/lunfardo314/proxima/util"
 	"github.com/prometheus/client_golang/prometheus"
@@ -18,11 +21,21 @@ type (
 		TxBytesStore() global.TxBytesStore
 		StateStore() multistate.StateStore
 		SendTxBytesWithMetadataToPeer(id peer.ID, txBytes []byte, metadata *txmetadata.TransactionMetadata, txid base.TransactionID) bool
+		ChargePullResponse(id peer.ID, nBytes int) bool
 	}
 
 	Input struct {
-		TxID   base.TransactionID
+		TxIDs  []base.TransactionID
 		PeerID peer.ID
+		// if true, past cones of transactions down to the PastConeDownToSlot (inclusive) are sent too
+		WithPastCone       bool
+		PastConeDownToSlot base.Slot
+	}
+
+	txToSend struct {
+		txid     base.TransactionID
+		txBytes  []byte
+		metadata *txmetadata.TransactionMetadata
 	}
 
 	PullTxServer struct {
@@ -37,6 +50,14 @@ const (
 	TraceTag = Name
 )
 
+const (
+	// maxPastConeResponseSize limits number of transactions sent in response to one past cone request
+	maxPastConeResponseSize = 2000
+	// maxPastConeDepthSlots limits how deep below the requested transactions the past cone is collected.
+	// A peer which is far behind pulls deeper parts of the past cone with next requests
+	maxPastConeDepthSlots = 100
+)
+
 func New(env environment) *PullTxServer {
 	ret := &PullTxServer{
 		environment: env,
@@ -48,20 +69,120 @@ func New(env environment) *PullTxServer {
 }
 
 func (d *PullTxServer) consume(inp *Input) {
-	txBytesWithMetadata := d.TxBytesStore().GetTxBytesWithMetadata(&inp.TxID)
-	if len(txBytesWithMetadata) == 0 {
-		d.Tracef(TraceTag, "NOT FOUND %s, request from %s", inp.TxID.StringShort, peering.ShortPeerIDString(inp.PeerID))
+	var txs []*txToSend
+	if inp.WithPastCone {
+		txs = d.collectPastCones(inp.TxIDs, clampPastConeDownToSlot(inp.PastConeDownToSlot, inp.TxIDs))
+	} else {
+		txs = make([]*txToSend, 0, len(inp.TxIDs))
+		for i := range inp.TxIDs {
+			if tx := d.fetchTx(inp.TxIDs[i]); tx != nil {
+				txs = append(txs, tx)
+			}
+		}
+	}
+	if len(txs) == 0 {
+		d.Tracef(TraceTag, "NOT FOUND %d transaction(s), request from %s", len(inp.TxIDs), peering.ShortPeerIDString(inp.PeerID))
 		return
 	}
+	// inputs and endorsements of the transaction are always older than the transaction itself,
+	// so sorting by timestamp puts dependencies before dependent transactions
+	sort.Slice(txs, func(i, j int) bool {
+		return txs[i].txid.Timestamp().Before(txs[j].txid.Timestamp())
+	})
+	// the peer pays by the size of the response. Transactions over its limit are not sent, the peer will pull them again
+	for i, tx := range txs {
+		if !d.ChargePullResponse(inp.PeerID, len(tx.txBytes)) {
+			d.Tracef(TraceTag, "response to %s cut to %d of %d transaction(s)", peering.ShortPeerIDString(inp.PeerID), i, len(txs))
+			txs = txs[:i]
+			break
+		}
+	}
+	if len(txs) == 0 {
+		return
+	}
+	// send sequentially to keep the order
+	go func() {
+		for _, tx := range txs {
+			d.SendTxBytesWithMetadataToPeer(inp.PeerID, tx.txBytes, tx.metadata, tx.txid)
+		}
+	}()
+	d.responseToPullCounter.Add(float64(len(txs)))
+
+	d.Tracef(TraceTag, "FOUND %d transaction(s) -> %s", len(txs), peering.ShortPeerIDString(inp.PeerID))
+}
+
+func (d *PullTxServer) fetchTx(txid base.TransactionID) *txToSend {
+	txBytesWithMetadata := d.TxBytesStore().GetTxBytesWithMetadata(&txid)
+	if len(txBytesWithMetadata) == 0 {
+		return nil
+	}
 	metadataBytes, txBytes, err := txmetadata.SplitTxBytesWithMetadata(txBytesWithMetadata)
 	util.AssertNoError(err)
 	metadata, err := txmetadata.TransactionMetadataFromBytes(metadataBytes)
 	util.AssertNoError(err)
+	return &txToSend{txid: txid, txBytes: txBytes, metadata: metadata}
+}
 
-	go d.SendTxBytesWithMetadataToPeer(inp.PeerID, txBytes, metadata, inp.TxID)
-	d.responseToPullCounter.Inc()
+// clampPastConeDownToSlot limits the past cone requested by the peer to maxPastConeDepthSlots below the latest
+// of the requested transactions. The size of the response is limited by maxPastConeResponseSize and by the charge
+func clampPastConeDownToSlot(downToSlot base.Slot, txids []base.TransactionID) base.Slot {
+	latestSlot := base.Slot(0)
+	for i := range txids {
+		latestSlot = max(latestSlot, txids[i].Slot())
+	}
+	if latestSlot < maxPastConeDepthSlots {
+		return downToSlot
+	}
+	return max(downToSlot, latestSlot-maxPastConeDepthSlots)
+}
 
-	d.Tracef(TraceTag, "FOUND %s -> %s", inp.TxID.StringShort, peering.ShortPeerIDString(inp.PeerID))
+// collectPastCones collects transactions and their past cones from the tx store down to the slot.
+// Transactions not in the tx store are skipped together with their past cones
+func (d *PullTxServer) collectPastCones(txids []base.TransactionID, downToSlot base.Slot) []*txToSend {
+	ret := make([]*txToSend, 0)
+	visited := make(map[base.TransactionID]struct{})
+	queue := make([]base.TransactionID, 0, len(txids))
+	for _, txid := range txids {
+		if _, already := visited[txid]; !already {
+			visited[txid] = struct{}{}
+			queue = append(queue, txid)
+		}
+	}
+	push := func(txid base.TransactionID) {
+		if txid.Slot() < downToSlot {
+			return
+		}
+		if _, already := visited[txid]; already {
+			return
+		}
+		visited[txid] = struct{}{}
+		queue = append(queue, txid)
+	}
+	for len(queue) > 0 && len(ret) < maxPastConeResponseSize {
+		txid := queue[0]
+		queue = queue[1:]
+
+		tx := d.fetchTx(txid)
+		if tx == nil {
+			continue
+		}
+		ret = append(ret, tx)
+
+		parsed, err := transaction.FromBytes(tx.txBytes)
+		if err != nil {
+			d.Log().Errorf("[pullTxServer] can't parse transaction %s from the tx store: %v", txid.StringShort(), err)
+			continue
+		}
+		parsed.ForEachInput(func(_ byte, oid base.OutputID) bool {
+			push(oid.TransactionID())
+			return true
+		})
+		parsed.ForEachEndorsement(func(_ byte, endorsed base.TransactionID) bool {
+			push(endorsed)
+			return true
+		})
+	}
+	return ret
 }
 
 func (d *PullTxServer) registerMetrics() {
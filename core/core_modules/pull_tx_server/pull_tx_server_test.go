@@ -0,0 +1,23 @@
+package pull_tx_server
+
+import (
+	"testing"
+
+	"github.com/lunfardo314/proxima/ledger/base"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClampPastConeDownToSlot(t *testing.T) {
+	txid := func(slot base.Slot) base.TransactionID {
+		return base.RandomTransactionID(true, 1, base.NewLedgerTime(slot, 0))
+	}
+	// the depth is counted from the latest requested transaction, not from the current slot
+	require.EqualValues(t, 1000-maxPastConeDepthSlots, clampPastConeDownToSlot(0, []base.TransactionID{txid(1000)}))
+	require.EqualValues(t, 1000-maxPastConeDepthSlots, clampPastConeDownToSlot(0, []base.TransactionID{txid(990), txid(1000)}))
+	require.EqualValues(t, 999, clampPastConeDownToSlot(999, []base.TransactionID{txid(1000)}))
+	// catching up peer: requested transactions are far in the past, the cone is not cut by the current time
+	require.EqualValues(t, 50, clampPastConeDownToSlot(50, []base.TransactionID{txid(60)}))
+	// near the genesis the depth is not clamped
+	require.EqualValues(t, 0, clampPastConeDownToSlot(0, []base.TransactionID{txid(maxPastConeDepthSlots - 1)}))
+	require.EqualValues(t, 0, clampPastConeDownToSlot(0, nil))
+}
@@ -1,8 +1,11 @@
 package snapshot
 
 import (
+	"errors"
+	"fmt"
 	"io"
 	"os"
+	"sync"
 	"time"
 
 	"github.com/lunfardo314/proxima/global"
@@ -27,6 +30,8 @@ type (
 		directory     string
 		keepLatest    int
 		safeSlotsBack int
+		// serializes periodic and on-demand snapshots
+		mutex sync.Mutex
 	}
 )
 
@@ -39,26 +44,34 @@ const (
 	defaultSafetySlots           = 20
 )
 
-func Start(env environment) {
+// Start starts periodic snapshots if enabled. The returned Snapshot can be used to take snapshots on demand
+// even if periodic snapshots are disabled
+func Start(env environment) *Snapshot {
 	ret := &Snapshot{
 		environment: env,
 	}
+	ret.directory = viper.GetString("snapshot.directory")
+	if ret.directory == "" {
+		ret.directory = defaultSnapshotDirectory
+	}
+	ret.keepLatest = viper.GetInt("snapshot.keep_latest")
+	if ret.keepLatest <= 0 {
+		ret.keepLatest = defaultKeepLatest
+	}
+	ret.safeSlotsBack = viper.GetInt("snapshot.safety_slots")
+	if ret.safeSlotsBack == 0 {
+		ret.safeSlotsBack = defaultSafetySlots
+	}
+
 	if !viper.GetBool("snapshot.enable") {
 		// will not have any effect
 		env.Log().Infof("[snapshot] is disabled")
-		return
+		return ret
 	}
 	env.Log().Infof("[snapshot] is enabled")
 
-	ret.directory = viper.GetString("snapshot.directory")
-	if ret.directory == "" {
-		ret.directory = defaultSnapshotDirectory
-	}
 	env.Log().Infof("%s directory is '%s'", Name, ret.directory)
-	if !directoryExists(ret.directory) {
-		err := os.MkdirAll(ret.directory, 0777)
-		util.AssertNoError(err, "can't create snapshot directory ", ret.directory)
-	}
+	ret.ensureDirectory()
 
 	periodInSlots := viper.GetInt("snapshot.period_in_slots")
 	if periodInSlots <= 0 {
@@ -66,16 +79,6 @@ func Start(env environment) {
 	}
 	period := time.Duration(periodInSlots) * ledger.L().ID.SlotDuration()
 
-	ret.keepLatest = viper.GetInt("snapshot.keep_latest")
-	if ret.keepLatest <= 0 {
-		ret.keepLatest = defaultKeepLatest
-	}
-
-	ret.safeSlotsBack = viper.GetInt("snapshot.safety_slots")
-	if ret.safeSlotsBack == 0 {
-		ret.safeSlotsBack = defaultSafetySlots
-	}
-
 	ret.registerMetrics()
 
 	env.RepeatInBackground(Name, period, func() bool {
@@ -90,35 +93,66 @@ func Start(env environment) {
 		Add("keep latest: %d", ret.keepLatest).
 		Add("safety slot back: %d", ret.safeSlotsBack)
 	ret.Log().Infof("[snapshot] work process STARTED\n%s", ln.String())
-	return
+	return ret
+}
+
+func (s *Snapshot) ensureDirectory() {
+	if !directoryExists(s.directory) {
+		err := os.MkdirAll(s.directory, 0777)
+		util.AssertNoError(err, "can't create snapshot directory ", s.directory)
+	}
+}
+
+// TakeSnapshotNow saves snapshot immediately. Returns name of the snapshot file
+func (s *Snapshot) TakeSnapshotNow() (string, error) {
+	s.ensureDirectory()
+	fname, err := s.saveSnapshot()
+	if err != nil {
+		return "", err
+	}
+	s.purgeOldSnapshots()
+	return fname, nil
 }
 
 func (s *Snapshot) registerMetrics() {
 	// TODO implement snapshot metrics
 }
 
+var ErrNotSynced = errors.New("node is not synced")
+
 func directoryExists(dir string) bool {
 	fileInfo, err := os.Stat(dir)
 	return err == nil && fileInfo.IsDir()
 }
 
 func (s *Snapshot) doSnapshot() {
-	if !s.IsSynced() {
+	_, err := s.saveSnapshot()
+	switch {
+	case errors.Is(err, ErrNotSynced):
 		s.Log().Infof("[snapshot] not synced, skipping snapshot")
-		return
+	case err != nil:
+		s.Log().Errorf("[snapshot] %v", err)
+	}
+}
+
+func (s *Snapshot) saveSnapshot() (string, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if !s.IsSynced() {
+		return "", ErrNotSynced
 	}
 	snapshotBranch := multistate.FindLatestReliableBranchAndNSlotsBack(s.StateStore(), s.safeSlotsBack, global.FractionHealthyBranch)
 	if snapshotBranch == nil {
-		s.Log().Errorf("[snapshot] can't find latest reliable branch")
-		return
+		return "", fmt.Errorf("can't find latest reliable branch")
 	}
 	fname, stats, err := multistate.SaveSnapshot(s.StateStore(), snapshotBranch, s.Ctx(), s.directory, io.Discard)
 	if err != nil {
-		s.Log().Errorf("[snapshot] failed to save snapshot: %v", err)
-	} else {
-		s.Log().Infof("[snapshot] snapshot has been saved to %s.\n%s\nBranch data:\n%s",
-			fname, stats.Lines("             ").String(), snapshotBranch.Lines("             ").String())
+		return "", fmt.Errorf("failed to save snapshot: %w", err)
 	}
+	s.Log().Infof("[snapshot] snapshot has been saved to %s.\n%s\nBranch data:\n%s",
+		fname, stats.Lines("             ").String(), snapshotBranch.Lines("             ").String())
+	return fname, nil
 }
 
 func (s *Snapshot) purgeOldSnapshots() {
@@ -11,6 +11,7 @@ import (
 	"github.com/lunfardo314/proxima/ledger"
 	"github.com/lunfardo314/proxima/ledger/base"
 	"github.com/lunfardo314/proxima/util"
+	"golang.org/x/exp/maps"
 	"golang.org/x/exp/rand"
 )
 
@@ -281,3 +282,24 @@ func (t *SequencerTips) GetKnownLatestSequencerDataJSONAble() map[string]LatestS
 	}
 	return ret
 }
+
+// LatestSequencerData returns copy of the data about the latest milestones of all known sequencers
+func (t *SequencerTips) LatestSequencerData() map[base.ChainID]LatestSequencerTipData {
+	t.mutex.RLock()
+	defer t.mutex.RUnlock()
+
+	return maps.Clone(t.latestSequencerData)
+}
+
+// RestoreLatestSequencerData restores data saved before the restart of the node. Data of sequencers
+// which are already known is not overwritten
+func (t *SequencerTips) RestoreLatestSequencerData(data map[base.ChainID]LatestSequencerTipData) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	for seqID, sd := range data {
+		if _, already := t.latestSequencerData[seqID]; !already {
+			t.latestSequencerData[seqID] = sd
+		}
+	}
+}
@@ -42,6 +42,15 @@ func (g *inGate[T]) checkPass(key T) (pass, wanted bool) {
 	return !found || entry.isWanted, entry.isWanted
 }
 
+// seen checks if the key passed the gate or is wanted, without changing the gate
+func (g *inGate[T]) seen(key T) bool {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	_, found := g.m[key]
+	return found
+}
+
 func (g *inGate[T]) addWanted(key T) {
 	g.mutex.Lock()
 	defer g.mutex.Unlock()
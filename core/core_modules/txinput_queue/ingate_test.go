@@ -32,4 +32,18 @@ func TestInputGate(t *testing.T) {
 		require.True(t, pass)
 		require.True(t, wanted)
 	})
+	t.Run("seen", func(t *testing.T) {
+		g := newInGate[int](10*time.Second, 10000)
+		require.False(t, g.seen(1))
+		require.False(t, g.seen(1))
+		pass, _ := g.checkPass(1)
+		require.True(t, pass)
+		require.True(t, g.seen(1))
+
+		g.addWanted(2)
+		require.True(t, g.seen(2))
+		pass, wanted := g.checkPass(2)
+		require.True(t, pass)
+		require.True(t, wanted)
+	})
 }
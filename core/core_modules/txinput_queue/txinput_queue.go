@@ -22,6 +22,8 @@ type (
 		TxInFromPeer(tx *transaction.Transaction, metaData *txmetadata.TransactionMetadata, from peer.ID) error
 		TxInFromAPI(tx *transaction.Transaction) error
 		GossipTxBytesToPeers(txBytes []byte, metadata *txmetadata.TransactionMetadata, txid base.TransactionID, except ...peer.ID)
+		// feedback to the reputation of the peer which sent malformed transaction
+		EvidenceInvalidTxFromPeer(from peer.ID, reason string)
 	}
 
 	Input struct {
@@ -126,12 +128,14 @@ func (q *TxInputQueue) fromPeer(inp *Input) {
 	if err != nil {
 		q.badTxCounter.Inc()
 		q.Log().Warn("TxInputQueue: %v", err)
+		q.EvidenceInvalidTxFromPeer(inp.FromPeer, err.Error())
 		return
 	}
 	// check if message prefix is equal to txid
 	if tx.ID() != inp.TxIDPrefix {
 		q.badTxCounter.Inc()
 		q.Log().Warn("TxInputQueue: tx message prefix != real txid", err)
+		q.EvidenceInvalidTxFromPeer(inp.FromPeer, "tx message prefix != real txid")
 		return
 	}
 
@@ -235,6 +239,11 @@ func (q *TxInputQueue) AddWantedTransaction(txid base.TransactionID) {
 	q.inGate.addWanted(txid)
 }
 
+// IsKnownTransaction returns true if the transaction was already received or is being pulled
+func (q *TxInputQueue) IsKnownTransaction(txid base.TransactionID) bool {
+	return q.inGate.seen(txid)
+}
+
 func (q *TxInputQueue) EvidenceNonSequencerTx() {
 	q.nonSequencerTxCounter.Inc()
 }
@@ -146,6 +146,11 @@ func (v *VirtualTransaction) SetPullHappened(repeatAfter time.Duration) {
 	v.nextPull = time.Now().Add(repeatAfter)
 }
 
+// TimesPulled number of pulls since pull rules were defined
+func (v *VirtualTransaction) TimesPulled() int {
+	return v.timesPulled
+}
+
 func (v *VirtualTransaction) PullPatienceExpired(maxPullAttempts int) bool {
 	return v.PullNeeded() && v.timesPulled >= maxPullAttempts
 }
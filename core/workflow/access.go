@@ -6,6 +6,7 @@ import (
 
 	"github.com/libp2p/go-libp2p/core/peer"
 	"github.com/lunfardo314/proxima/core/core_modules/branches"
+	"github.com/lunfardo314/proxima/core/core_modules/delegation_history"
 	"github.com/lunfardo314/proxima/core/core_modules/tippool"
 	"github.com/lunfardo314/proxima/core/memdag"
 	"github.com/lunfardo314/proxima/core/txmetadata"
@@ -34,6 +35,10 @@ func (w *Workflow) SendTxBytesWithMetadataToPeer(id peer.ID, txBytes []byte, met
 	return w.peers.SendTxBytesWithMetadataToPeer(id, txBytes, metadata, txid)
 }
 
+func (w *Workflow) ChargePullResponse(id peer.ID, nBytes int) bool {
+	return w.peers.ChargePullResponse(id, nBytes)
+}
+
 func (w *Workflow) GossipAttachedTransaction(tx *transaction.Transaction, metadata *txmetadata.TransactionMetadata) {
 	if metadata != nil {
 		if metadata.SourceTypeNonPersistent == txmetadata.SourceTypeTxStore || metadata.SourceTypeNonPersistent == txmetadata.SourceTypePulled {
@@ -83,6 +88,10 @@ func (w *Workflow) PeerName(id peer.ID) string {
 	return w.peers.PeerName(id)
 }
 
+func (w *Workflow) EvidenceInvalidTxFromPeer(from peer.ID, reason string) {
+	w.peers.EvidenceInvalidTx(from, reason)
+}
+
 func (w *Workflow) QueryTxIDStatus(txid base.TransactionID) (ret vertex.TxIDStatus) {
 	ret = w.MemDAG.QueryTxIDStatus(txid)
 	ret.InStorage = w.TxBytesStore().HasTxBytes(&txid)
@@ -128,3 +137,11 @@ func (w *Workflow) DisableMemDAGGC() bool {
 func (w *Workflow) Branches() *branches.Branches {
 	return w.branches
 }
+
+func (w *Workflow) GetDelegationHistory(chainID base.ChainID) (*delegation_history.History, error) {
+	return w.delegationHistory.GetHistory(chainID)
+}
+
+func (w *Workflow) TakeSnapshotNow() (string, error) {
+	return w.snapshot.TakeSnapshotNow()
+}
@@ -1,14 +1,23 @@
 package workflow
 
 import (
+	"github.com/lunfardo314/proxima/core/txmetadata"
 	"github.com/lunfardo314/proxima/core/vertex"
 	"github.com/lunfardo314/proxima/ledger/base"
 	"github.com/lunfardo314/proxima/util/eventtype"
 )
 
+// TxRejected is the payload of EventTxRejected
+type TxRejected struct {
+	TxID   base.TransactionID
+	Reason error
+	Source txmetadata.SourceType
+}
+
 var (
-	EventNewTx     = eventtype.RegisterNew[*vertex.WrappedTx]("new tx") // event may be posted more than once for the transaction
-	EventTxDeleted = eventtype.RegisterNew[base.TransactionID]("del tx")
+	EventNewTx      = eventtype.RegisterNew[*vertex.WrappedTx]("new tx") // event may be posted more than once for the transaction
+	EventTxDeleted  = eventtype.RegisterNew[base.TransactionID]("del tx")
+	EventTxRejected = eventtype.RegisterNew[*TxRejected]("rejected tx")
 )
 
 func (w *Workflow) PostEventNewTransaction(vid *vertex.WrappedTx) {
@@ -18,3 +27,15 @@ func (w *Workflow) PostEventNewTransaction(vid *vertex.WrappedTx) {
 func (w *Workflow) PostEventTxDeleted(txid base.TransactionID) {
 	w.events.PostEvent(EventTxDeleted, txid)
 }
+
+// PostEventTxRejected is posted when the transaction is marked BAD. Metadata may be nil
+func (w *Workflow) PostEventTxRejected(txid base.TransactionID, reason error, metadata *txmetadata.TransactionMetadata) {
+	ev := &TxRejected{
+		TxID:   txid,
+		Reason: reason,
+	}
+	if metadata != nil {
+		ev.Source = metadata.SourceTypeNonPersistent
+	}
+	w.events.PostEvent(EventTxRejected, ev)
+}
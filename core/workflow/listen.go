@@ -37,12 +37,15 @@ type txListener struct {
 	handlers             map[int]func(tx *transaction.Transaction) bool
 	deleteHandlerCounter int
 	deleteHandlers       map[int]func(txid base.TransactionID) bool
+	rejectHandlerCounter int
+	rejectHandlers       map[int]func(rej *TxRejected) bool
 }
 
 func (w *Workflow) startListeningTransactions() {
 	w.txListener = &txListener{
 		handlers:       make(map[int]func(tx *transaction.Transaction) bool),
 		deleteHandlers: make(map[int]func(txid base.TransactionID) bool),
+		rejectHandlers: make(map[int]func(rej *TxRejected) bool),
 	}
 	w.events.OnEvent(EventNewTx, func(vid *vertex.WrappedTx) {
 		var tx *transaction.Transaction
@@ -58,6 +61,9 @@ func (w *Workflow) startListeningTransactions() {
 	w.events.OnEvent(EventTxDeleted, func(txid base.TransactionID) {
 		w.txListener.runForDelete(txid)
 	})
+	w.events.OnEvent(EventTxRejected, func(rej *TxRejected) {
+		w.txListener.runForReject(rej)
+	})
 }
 
 func (tl *txListener) runFor(tx *transaction.Transaction) {
@@ -82,6 +88,17 @@ func (tl *txListener) runForDelete(txid base.TransactionID) {
 	}
 }
 
+func (tl *txListener) runForReject(rej *TxRejected) {
+	tl.mutex.Lock()
+	defer tl.mutex.Unlock()
+
+	for id, fun := range tl.rejectHandlers {
+		if !fun(rej) {
+			delete(tl.rejectHandlers, id)
+		}
+	}
+}
+
 func (w *Workflow) OnTransaction(fun func(tx *transaction.Transaction) bool) {
 	w.txListener.mutex.Lock()
 	defer w.txListener.mutex.Unlock()
@@ -97,3 +114,12 @@ func (w *Workflow) OnTxDeleted(fun func(txid base.TransactionID) bool) {
 	w.txListener.deleteHandlers[w.txListener.deleteHandlerCounter] = fun
 	w.txListener.deleteHandlerCounter++
 }
+
+// OnTxRejected registers handler called each time a transaction is marked BAD. Handler returns false to be removed
+func (w *Workflow) OnTxRejected(fun func(rej *TxRejected) bool) {
+	w.txListener.mutex.Lock()
+	defer w.txListener.mutex.Unlock()
+
+	w.txListener.rejectHandlers[w.txListener.rejectHandlerCounter] = fun
+	w.txListener.rejectHandlerCounter++
+}
@@ -1,6 +1,7 @@
 package workflow
 
 import (
+	"errors"
 	"fmt"
 	"time"
 
@@ -27,6 +28,9 @@ const (
 	TraceTagTxInput = "txinput"
 )
 
+// errTooFarInTheFuture usually is a consequence of clock differences, not of misbehaviour of the peer
+var errTooFarInTheFuture = errors.New("transaction is too far in the future")
+
 func (w *Workflow) TxFromStoreIn(txid base.TransactionID) (err error) {
 	_, err = w.TxBytesFromStoreIn(w.TxBytesStore().GetTxBytesWithMetadata(&txid))
 	return
@@ -88,7 +92,15 @@ func (w *Workflow) TxBytesInFromPeerQueued(txBytesReceived []byte, metaData *txm
 }
 
 func (w *Workflow) TxInFromPeer(tx *transaction.Transaction, metaData *txmetadata.TransactionMetadata, from peer.ID) error {
-	return w.TxIn(tx, WithPeerMetadata(from, metaData))
+	err := w.TxIn(tx, WithPeerMetadata(from, metaData))
+	// feedback to the reputation of the peer. Clock differences are accounted by peering separately
+	switch {
+	case err == nil:
+		w.peers.EvidenceUsefulTx(from)
+	case !errors.Is(err, errTooFarInTheFuture):
+		w.peers.EvidenceInvalidTx(from, err.Error())
+	}
+	return err
 }
 
 func (w *Workflow) TxIn(tx *transaction.Transaction, opts ...TxInOption) error {
@@ -118,8 +130,8 @@ func (w *Workflow) TxIn(tx *transaction.Transaction, opts ...TxInOption) error {
 	if err != nil {
 		if enforceTimeBounds {
 			w.Tracef(TraceTagTxInput, "invalidate %s: time bounds validation failed", txid.StringShort)
-			err = fmt.Errorf("%w (MaxDurationInTheFuture = %v)", err, w.MaxDurationInTheFuture())
-			attacher.InvalidateTxID(txid, w, err)
+			err = fmt.Errorf("%w: %w (MaxDurationInTheFuture = %v)", errTooFarInTheFuture, err, w.MaxDurationInTheFuture())
+			attacher.InvalidateTxID(txid, w, err, attacher.WithTransactionMetadata(&options.txMetadata))
 
 			return err
 		}
@@ -130,7 +142,7 @@ func (w *Workflow) TxIn(tx *transaction.Transaction, opts ...TxInOption) error {
 	if err = tx.Validate(transaction.MainTxValidationOptions...); err != nil {
 		err = fmt.Errorf("error while pre-validating transaction %s: '%w'", txid.StringShort(), err)
 		w.Tracef(TraceTagTxInput, "%v", err)
-		attacher.InvalidateTxID(txid, w, err)
+		attacher.InvalidateTxID(txid, w, err, attacher.WithTransactionMetadata(&options.txMetadata))
 		return err
 	}
 
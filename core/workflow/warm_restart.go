@@ -0,0 +1,145 @@
+package workflow
+
+import (
+	"encoding/json"
+	"sort"
+
+	"github.com/lunfardo314/proxima/core/core_modules/tippool"
+	"github.com/lunfardo314/proxima/core/vertex"
+	"github.com/lunfardo314/proxima/global"
+	"github.com/lunfardo314/proxima/ledger"
+	"github.com/lunfardo314/proxima/ledger/base"
+	"github.com/lunfardo314/proxima/ledger/multistate"
+	"github.com/lunfardo314/unitrie/common"
+)
+
+// Warm restart. At shutdown, the frontier of the memDAG is saved into the store: latest milestones of sequencers
+// from the tippool and full vertices which are not yet included in the latest reliable branch.
+// At startup, transactions of the frontier are loaded from the transaction store as if they were received
+// from peers, so the node does not need to wait for sequencer tips to re-learn them
+
+type (
+	frontierRecord struct {
+		// slot of the ledger time when frontier was saved
+		Slot       base.Slot               `json:"slot"`
+		Sequencers []frontierSequencerData `json:"sequencers"`
+		// ascending by timestamp
+		TxIDs []base.TransactionID `json:"txids"`
+	}
+
+	frontierSequencerData struct {
+		SequencerID base.ChainID `json:"sequencer_id"`
+		tippool.LatestSequencerTipData
+	}
+)
+
+const (
+	// frontier older than that is useless because vertices would be already deleted from the memDAG
+	frontierMaxAgeSlots = 24
+	frontierMaxSize     = 5000
+)
+
+// frontierKey is shorter than transaction ID, so it does not collide with keys of the transaction store
+var frontierKey = []byte("memdag_frontier")
+
+// frontierTimeNow is the ledger time used to determine age of the frontier. Tests replace it
+var frontierTimeNow = ledger.TimeNow
+
+// SaveFrontier saves the frontier of the memDAG into the store. Returns number of transactions in the frontier
+func (w *Workflow) SaveFrontier(store common.KVStore) (int, error) {
+	rec := frontierRecord{
+		Slot:       frontierTimeNow().Slot,
+		Sequencers: make([]frontierSequencerData, 0),
+		TxIDs:      make([]base.TransactionID, 0),
+	}
+	for seqID, sd := range w.tippool.LatestSequencerData() {
+		rec.Sequencers = append(rec.Sequencers, frontierSequencerData{
+			SequencerID:            seqID,
+			LatestSequencerTipData: sd,
+		})
+	}
+
+	var lrbSlot base.Slot
+	if lrb := multistate.FindLatestReliableBranch(w.StateStore(), global.FractionHealthyBranch); lrb != nil {
+		lrbSlot = lrb.Stem.ID.Slot()
+	}
+	frontier := make(map[*vertex.WrappedTx]struct{})
+	for _, vid := range w.Vertices() {
+		if vid.Slot() > lrbSlot {
+			frontier[vid] = struct{}{}
+		}
+	}
+	for _, vid := range w.tippool.LatestActiveMilestonesDescending() {
+		frontier[vid] = struct{}{}
+	}
+	vids := make([]*vertex.WrappedTx, 0, len(frontier))
+	for vid := range frontier {
+		if !vid.IsVirtualTx() && !vid.IsBad() {
+			vids = append(vids, vid)
+		}
+	}
+	sort.Slice(vids, func(i, j int) bool {
+		return vids[i].Timestamp().Before(vids[j].Timestamp())
+	})
+	if len(vids) > frontierMaxSize {
+		vids = vids[len(vids)-frontierMaxSize:]
+	}
+	for _, vid := range vids {
+		rec.TxIDs = append(rec.TxIDs, vid.ID())
+	}
+
+	data, err := json.Marshal(&rec)
+	if err != nil {
+		return 0, err
+	}
+	batch := store.BatchedWriter()
+	batch.Set(frontierKey, data)
+	if err = batch.Commit(); err != nil {
+		return 0, err
+	}
+	return len(rec.TxIDs), nil
+}
+
+// LoadFrontier loads frontier saved at the shutdown and sends its transactions from the transaction store
+// to the workflow. The frontier is loaded once, then it is deleted from the store.
+// Returns number of transactions sent to the workflow
+func (w *Workflow) LoadFrontier(store common.KVStore) (int, error) {
+	data := store.Get(frontierKey)
+	if len(data) == 0 {
+		return 0, nil
+	}
+	batch := store.BatchedWriter()
+	batch.Set(frontierKey, nil)
+	if err := batch.Commit(); err != nil {
+		return 0, err
+	}
+
+	var rec frontierRecord
+	if err := json.Unmarshal(data, &rec); err != nil {
+		return 0, err
+	}
+	if slotNow := frontierTimeNow().Slot; slotNow > rec.Slot+frontierMaxAgeSlots {
+		w.Log().Infof("[warm restart] frontier saved at slot %d is too old (current slot is %d). Ignore", rec.Slot, slotNow)
+		return 0, nil
+	}
+
+	seqData := make(map[base.ChainID]tippool.LatestSequencerTipData)
+	for _, sd := range rec.Sequencers {
+		seqData[sd.SequencerID] = sd.LatestSequencerTipData
+	}
+	w.tippool.RestoreLatestSequencerData(seqData)
+
+	count := 0
+	for _, txid := range rec.TxIDs {
+		txBytesWithMetadata := w.TxBytesStore().GetTxBytesWithMetadata(&txid)
+		if len(txBytesWithMetadata) == 0 {
+			continue
+		}
+		if _, err := w.TxBytesFromStoreIn(txBytesWithMetadata); err != nil {
+			w.Log().Warnf("[warm restart] failed to load %s: %v", txid.StringShort(), err)
+			continue
+		}
+		count++
+	}
+	return count, nil
+}
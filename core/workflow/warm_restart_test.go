@@ -0,0 +1,134 @@
+package workflow
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/lunfardo314/proxima/core/core_modules/tippool"
+	"github.com/lunfardo314/proxima/ledger"
+	"github.com/lunfardo314/proxima/ledger/base"
+	"github.com/lunfardo314/proxima/peering"
+	"github.com/lunfardo314/unitrie/common"
+	"github.com/stretchr/testify/require"
+)
+
+func startTestWorkflow(t *testing.T) *Workflow {
+	env := newWorkflowDummyEnvironment()
+	w := Start(env, peering.NewPeersDummy(), OptionDisableMemDAGGC)
+	t.Cleanup(func() {
+		env.Stop()
+		env.WaitAllWorkProcessesStop()
+	})
+	return w
+}
+
+func testSequencerData() map[base.ChainID]tippool.LatestSequencerTipData {
+	branchID := base.RandomTransactionID(true, 1)
+	return map[base.ChainID]tippool.LatestSequencerTipData{
+		base.RandomChainID(): {
+			LatestMilestoneTxID: base.RandomTransactionID(true, 1),
+			LastBranchTxID:      &branchID,
+			MilestoneCount:      17,
+			LastActivity:        time.Unix(0, time.Now().UnixNano()),
+		},
+		base.RandomChainID(): {
+			LatestMilestoneTxID: base.RandomTransactionID(true, 2),
+			MilestoneCount:      3,
+			LastActivity:        time.Unix(0, time.Now().UnixNano()),
+		},
+	}
+}
+
+func requireSameSequencerData(t *testing.T, exp, got map[base.ChainID]tippool.LatestSequencerTipData) {
+	require.EqualValues(t, len(exp), len(got))
+	for seqID, sd := range exp {
+		sdGot, found := got[seqID]
+		require.True(t, found)
+		require.EqualValues(t, sd.LatestMilestoneTxID, sdGot.LatestMilestoneTxID)
+		require.EqualValues(t, sd.LastBranchTxID, sdGot.LastBranchTxID)
+		require.EqualValues(t, sd.MilestoneCount, sdGot.MilestoneCount)
+		require.True(t, sd.LastActivity.Equal(sdGot.LastActivity))
+	}
+}
+
+func TestWarmRestart(t *testing.T) {
+	t.Run("round trip", func(t *testing.T) {
+		store := common.NewInMemoryKVStore()
+		seqData := testSequencerData()
+
+		w := startTestWorkflow(t)
+		w.tippool.RestoreLatestSequencerData(seqData)
+		n, err := w.SaveFrontier(store)
+		require.NoError(t, err)
+		require.EqualValues(t, 0, n)
+		require.True(t, len(store.Get(frontierKey)) > 0)
+
+		wRestarted := startTestWorkflow(t)
+		n, err = wRestarted.LoadFrontier(store)
+		require.NoError(t, err)
+		require.EqualValues(t, 0, n)
+		requireSameSequencerData(t, seqData, wRestarted.tippool.LatestSequencerData())
+	})
+	t.Run("transactions not in the store are skipped", func(t *testing.T) {
+		store := common.NewInMemoryKVStore()
+		rec := frontierRecord{
+			Slot:       ledger.TimeNow().Slot,
+			Sequencers: make([]frontierSequencerData, 0),
+			TxIDs:      []base.TransactionID{base.RandomTransactionID(true, 1), base.RandomTransactionID(false, 2)},
+		}
+		data, err := json.Marshal(&rec)
+		require.NoError(t, err)
+		store.Set(frontierKey, data)
+
+		w := startTestWorkflow(t)
+		n, err := w.LoadFrontier(store)
+		require.NoError(t, err)
+		require.EqualValues(t, 0, n)
+	})
+	t.Run("frontier is deleted after load", func(t *testing.T) {
+		store := common.NewInMemoryKVStore()
+		seqData := testSequencerData()
+
+		w := startTestWorkflow(t)
+		w.tippool.RestoreLatestSequencerData(seqData)
+		_, err := w.SaveFrontier(store)
+		require.NoError(t, err)
+
+		wRestarted := startTestWorkflow(t)
+		_, err = wRestarted.LoadFrontier(store)
+		require.NoError(t, err)
+		require.EqualValues(t, 0, len(store.Get(frontierKey)))
+
+		// second load finds nothing
+		wRestartedAgain := startTestWorkflow(t)
+		n, err := wRestartedAgain.LoadFrontier(store)
+		require.NoError(t, err)
+		require.EqualValues(t, 0, n)
+		require.EqualValues(t, 0, len(wRestartedAgain.tippool.LatestSequencerData()))
+	})
+	t.Run("too old frontier is ignored", func(t *testing.T) {
+		store := common.NewInMemoryKVStore()
+
+		w := startTestWorkflow(t)
+		w.tippool.RestoreLatestSequencerData(testSequencerData())
+		_, err := w.SaveFrontier(store)
+		require.NoError(t, err)
+
+		// move ledger time of the restarted node past the age cutoff
+		frontierTimeNow = func() base.LedgerTime {
+			return base.NewLedgerTime(ledger.TimeNow().Slot+frontierMaxAgeSlots+2, 0)
+		}
+		t.Cleanup(func() {
+			frontierTimeNow = ledger.TimeNow
+		})
+
+		wRestarted := startTestWorkflow(t)
+		n, err := wRestarted.LoadFrontier(store)
+		require.NoError(t, err)
+		require.EqualValues(t, 0, n)
+		require.EqualValues(t, 0, len(wRestarted.tippool.LatestSequencerData()))
+		// old frontier is deleted too
+		require.EqualValues(t, 0, len(store.Get(frontierKey)))
+	})
+}
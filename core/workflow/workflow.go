@@ -7,6 +7,7 @@ import (
 
 	"github.com/libp2p/go-libp2p/core/peer"
 	"github.com/lunfardo314/proxima/core/core_modules/branches"
+	"github.com/lunfardo314/proxima/core/core_modules/delegation_history"
 	"github.com/lunfardo314/proxima/core/core_modules/events"
 	"github.com/lunfardo314/proxima/core/core_modules/poker"
 	"github.com/lunfardo314/proxima/core/core_modules/pull_tx_server"
@@ -29,6 +30,7 @@ type (
 		StateStore() multistate.StateStore
 		TxBytesStore() global.TxBytesStore
 		PullFromNPeers(nPeers int, txid base.TransactionID) int
+		PullWithPastConeFromNPeers(nPeers int, txid base.TransactionID, downToSlot base.Slot) int
 		GetOwnSequencerID() *base.ChainID
 		EvidencePastConeSize(sz int)
 		EvidenceNumberOfTxDependencies(n int)
@@ -38,13 +40,29 @@ type (
 		EvidenceTxValidationStats(took time.Duration, numIn, numOut int)
 		LatestReliableState() (multistate.SugaredStateReader, error)
 		EvidenceBranchInflationBonus(ib uint64)
+		ClockSkew() (skew time.Duration, tooBig bool)
+	}
+
+	// Peers is the network layer used by the workflow. It is implemented by peering.Peers
+	// and by the in-memory network of the simulator
+	Peers interface {
+		OnReceiveTxBytes(fun func(from peer.ID, txBytes []byte, metadata *txmetadata.TransactionMetadata, txIDPrefix base.TransactionID))
+		OnReceivePullTxRequest(fun func(from peer.ID, txid base.TransactionID))
+		OnReceivePullTxBatchRequest(fun func(from peer.ID, batch *peering.PullBatch))
+		SetKnownTransactionFilter(fun func(txid base.TransactionID) bool)
+		SendTxBytesWithMetadataToPeer(id peer.ID, txBytes []byte, metadata *txmetadata.TransactionMetadata, txid base.TransactionID) bool
+		ChargePullResponse(id peer.ID, nBytes int) bool
+		GossipTxBytesToPeers(txBytes []byte, metadata *txmetadata.TransactionMetadata, txid base.TransactionID, except ...peer.ID)
+		PeerName(id peer.ID) string
+		EvidenceUsefulTx(id peer.ID)
+		EvidenceInvalidTx(id peer.ID, reason string)
 	}
 
 	Workflow struct {
 		environment
 		*memdag.MemDAG
 		cfg          *ConfigParams
-		peers        *peering.Peers
+		peers        Peers
 		earliestSlot base.Slot // cached, immutable
 		// queues and daemons
 		pullTxServer *pull_tx_server.PullTxServer
@@ -53,6 +71,9 @@ type (
 		txInputQueue *txinput_queue.TxInputQueue
 		tippool      *tippool.SequencerTips
 		branches     *branches.Branches
+		// accounting of delegation rewards
+		delegationHistory *delegation_history.DelegationHistory
+		snapshot          *snapshot.Snapshot
 		// particular event handlers
 		txListener *txListener
 		//
@@ -64,7 +85,7 @@ type (
 
 const recreateMapPeriod = time.Minute
 
-func Start(env environment, peers *peering.Peers, opts ...ConfigOption) *Workflow {
+func Start(env environment, peers Peers, opts ...ConfigOption) *Workflow {
 	cfg := defaultConfigParams()
 	for _, opt := range opts {
 		opt(&cfg)
@@ -84,8 +105,9 @@ func Start(env environment, peers *peering.Peers, opts ...ConfigOption) *Workflo
 	ret.pullTxServer = pull_tx_server.New(ret)
 	ret.tippool = tippool.New(ret)
 	ret.branches = branches.New(ret)
+	ret.delegationHistory = delegation_history.New(ret)
 	ret.txInputQueue = txinput_queue.New(ret)
-	snapshot.Start(ret)
+	ret.snapshot = snapshot.Start(ret)
 	ret.startListeningTransactions()
 
 	ret.peers.OnReceiveTxBytes(func(from peer.ID, txBytes []byte, metadata *txmetadata.TransactionMetadata, txIDPrefix base.TransactionID) {
@@ -94,11 +116,23 @@ func Start(env environment, peers *peering.Peers, opts ...ConfigOption) *Workflo
 
 	ret.peers.OnReceivePullTxRequest(func(from peer.ID, txid base.TransactionID) {
 		ret.pullTxServer.Push(&pull_tx_server.Input{
-			TxID:   txid,
+			TxIDs:  []base.TransactionID{txid},
 			PeerID: from,
 		})
 	})
 
+	ret.peers.OnReceivePullTxBatchRequest(func(from peer.ID, batch *peering.PullBatch) {
+		ret.pullTxServer.Push(&pull_tx_server.Input{
+			TxIDs:              batch.TxIDs,
+			PeerID:             from,
+			WithPastCone:       batch.WithPastCone,
+			PastConeDownToSlot: batch.PastConeDownToSlot,
+		})
+	})
+
+	// announced transactions which passed the input queue are not requested again
+	ret.peers.SetKnownTransactionFilter(ret.txInputQueue.IsKnownTransaction)
+
 	// hopefully protects against memory leak
 	ret.RepeatInBackground("workflow_recreate_map_loop", recreateMapPeriod, func() bool {
 		ret.RecreateVertexMap()
@@ -110,7 +144,7 @@ func Start(env environment, peers *peering.Peers, opts ...ConfigOption) *Workflo
 	return ret
 }
 
-func StartFromConfig(env environment, peers *peering.Peers) *Workflow {
+func StartFromConfig(env environment, peers Peers) *Workflow {
 	opts := make([]ConfigOption, 0)
 	if viper.GetBool("workflow.do_not_start_pruner") {
 		opts = append(opts, OptionDisableMemDAGGC)
@@ -41,6 +41,10 @@ func (d *workflowDummyEnvironment) PullFromNPeers(_ int, _ base.TransactionID) i
 	panic("not implemented")
 }
 
+func (d *workflowDummyEnvironment) PullWithPastConeFromNPeers(_ int, _ base.TransactionID, _ base.Slot) int {
+	panic("not implemented")
+}
+
 func (d *workflowDummyEnvironment) GetOwnSequencerID() *base.ChainID {
 	panic("not implemented")
 }
@@ -57,10 +61,22 @@ func (d *workflowDummyEnvironment) DurationSinceLastMessageFromPeer() time.Durat
 	return 0
 }
 
+func (d *workflowDummyEnvironment) ClockSkew() (time.Duration, bool) {
+	return 0, false
+}
+
 func (d *workflowDummyEnvironment) SelfPeerID() peer.ID {
 	return "self"
 }
 
+func (d *workflowDummyEnvironment) EvidenceTxValidationStats(_ time.Duration, _, _ int) {}
+
+func (d *workflowDummyEnvironment) EvidenceBranchInflationBonus(_ uint64) {}
+
+func (d *workflowDummyEnvironment) LatestReliableState() (multistate.SugaredStateReader, error) {
+	panic("not implemented")
+}
+
 func newWorkflowDummyEnvironment() *workflowDummyEnvironment {
 	stateStore := common.NewInMemoryKVStore()
 	multistate.InitStateStoreWithGlobalLedgerIdentity(stateStore)
@@ -294,6 +294,16 @@ func (l *Global) StopTracingTag(tag string) {
 	}
 }
 
+// TraceTags returns sorted list of currently enabled trace tags
+func (l *Global) TraceTags() []string {
+	l.traceTagsMutex.RLock()
+	defer l.traceTagsMutex.RUnlock()
+
+	return l.traceTags.Ordered(func(el1, el2 string) bool {
+		return el1 < el2
+	})
+}
+
 func (l *Global) Tracef(tag string, format string, args ...any) {
 	if !l.enabledTrace.Load() {
 		return
@@ -2,6 +2,7 @@ package global
 
 import (
 	"encoding/json"
+	"time"
 
 	"github.com/libp2p/go-libp2p/core/peer"
 	"github.com/lunfardo314/proxima/ledger/base"
@@ -17,6 +18,12 @@ type NodeInfo struct {
 	NumStaticAlive  uint16        `json:"num_static_peers"`
 	NumDynamicAlive uint16        `json:"num_dynamic_alive"`
 	Sequencer       *base.ChainID `json:"sequencers,omitempty"`
+	// estimated offset of the local clock with respect to peers. Positive means ahead
+	ClockOffsetNs int64 `json:"clock_offset_ns"`
+	// correction added to the local clock when computing ledger time
+	ClockCorrectionNs int64 `json:"clock_correction_ns"`
+	// true if the remaining skew is too big for the sequencer to produce milestones
+	ClockSkewTooBig bool `json:"clock_skew_too_big"`
 }
 
 func (ni *NodeInfo) Bytes() []byte {
@@ -44,6 +51,8 @@ func (ni *NodeInfo) Lines(prefix ...string) *lines.Lines {
 		Add("static peers alive: %d", ni.NumStaticAlive).
 		Add("dynamic peers alive: %d", ni.NumDynamicAlive).
 		Add("sequencer: %s", seqStr).
+		Add("clock offset: %v, correction: %v, skew too big: %v",
+			time.Duration(ni.ClockOffsetNs), time.Duration(ni.ClockCorrectionNs), ni.ClockSkewTooBig).
 		Add("commit hash: %s", ni.CommitHash).
 		Add("commit time: %s", ni.CommitTime)
 	return ret
@@ -0,0 +1,178 @@
+package kvdb
+
+import (
+	"bytes"
+	"path/filepath"
+	"sync/atomic"
+	"time"
+
+	"github.com/lunfardo314/proxima/util"
+	"github.com/lunfardo314/unitrie/common"
+	bolt "go.etcd.io/bbolt"
+)
+
+// boltDB is common.KVStore on top of bbolt. All key/value pairs are in one bucket.
+// Iteration reads pairs in chunks, each in separate read transaction, so the callback
+// of the iterator may update the database
+
+type (
+	boltDB struct {
+		db     *bolt.DB
+		closed atomic.Bool
+	}
+
+	boltBatch struct {
+		db   *bolt.DB
+		keys [][]byte
+		vals [][]byte
+	}
+
+	boltIterator struct {
+		db     *bolt.DB
+		prefix []byte
+	}
+)
+
+const (
+	boltFileName          = "bolt.db"
+	boltIterateChunkSize  = 1000
+	boltOpenTimeout       = 5 * time.Second
+	boltInitialMmapSizeMB = 64
+)
+
+var boltBucket = []byte("kv")
+
+func openBolt(dir string) (DB, error) {
+	db, err := bolt.Open(filepath.Join(dir, boltFileName), 0644, &bolt.Options{
+		Timeout:         boltOpenTimeout,
+		NoFreelistSync:  true,
+		FreelistType:    bolt.FreelistMapType,
+		InitialMmapSize: boltInitialMmapSizeMB << 20,
+	})
+	if err != nil {
+		return nil, err
+	}
+	err = db.Update(func(tx *bolt.Tx) error {
+		_, err1 := tx.CreateBucketIfNotExists(boltBucket)
+		return err1
+	})
+	if err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+	return &boltDB{db: db}, nil
+}
+
+func (b *boltDB) Get(key []byte) (ret []byte) {
+	_ = b.db.View(func(tx *bolt.Tx) error {
+		// value is valid only during transaction
+		if v := tx.Bucket(boltBucket).Get(key); v != nil {
+			ret = bytes.Clone(v)
+		}
+		return nil
+	})
+	return
+}
+
+func (b *boltDB) Has(key []byte) (ret bool) {
+	_ = b.db.View(func(tx *bolt.Tx) error {
+		ret = tx.Bucket(boltBucket).Get(key) != nil
+		return nil
+	})
+	return
+}
+
+// Set with empty value deletes the key
+func (b *boltDB) Set(key, value []byte) {
+	err := b.db.Update(func(tx *bolt.Tx) error {
+		return boltSet(tx.Bucket(boltBucket), key, value)
+	})
+	util.AssertNoError(err)
+}
+
+func boltSet(bucket *bolt.Bucket, key, value []byte) error {
+	if len(value) == 0 {
+		return bucket.Delete(key)
+	}
+	return bucket.Put(key, value)
+}
+
+func (b *boltDB) BatchedWriter() common.KVBatchedWriter {
+	return &boltBatch{db: b.db}
+}
+
+func (b *boltDB) Iterator(prefix []byte) common.KVIterator {
+	return &boltIterator{db: b.db, prefix: prefix}
+}
+
+func (b *boltDB) IsClosed() bool {
+	return b.closed.Load()
+}
+
+func (b *boltDB) Close() error {
+	b.closed.Store(true)
+	return b.db.Close()
+}
+
+func (bb *boltBatch) Set(key, value []byte) {
+	bb.keys = append(bb.keys, key)
+	bb.vals = append(bb.vals, value)
+}
+
+func (bb *boltBatch) Commit() error {
+	return bb.db.Update(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(boltBucket)
+		for i := range bb.keys {
+			if err := boltSet(bucket, bb.keys[i], bb.vals[i]); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (it *boltIterator) Iterate(fun func(k, v []byte) bool) {
+	it.iterate(true, fun)
+}
+
+func (it *boltIterator) IterateKeys(fun func(k []byte) bool) {
+	it.iterate(false, func(k, _ []byte) bool {
+		return fun(k)
+	})
+}
+
+func (it *boltIterator) iterate(withValues bool, fun func(k, v []byte) bool) {
+	seek := it.prefix
+	skipFirst := false
+	for {
+		keys := make([][]byte, 0, boltIterateChunkSize)
+		vals := make([][]byte, 0, boltIterateChunkSize)
+		_ = it.db.View(func(tx *bolt.Tx) error {
+			c := tx.Bucket(boltBucket).Cursor()
+			k, v := c.Seek(seek)
+			if skipFirst && k != nil && bytes.Equal(k, seek) {
+				k, v = c.Next()
+			}
+			for ; k != nil && bytes.HasPrefix(k, it.prefix) && len(keys) < boltIterateChunkSize; k, v = c.Next() {
+				keys = append(keys, bytes.Clone(k))
+				if withValues {
+					vals = append(vals, bytes.Clone(v))
+				} else {
+					vals = append(vals, nil)
+				}
+			}
+			return nil
+		})
+		for i := range keys {
+			if !fun(keys[i], vals[i]) {
+				return
+			}
+		}
+		if len(keys) < boltIterateChunkSize {
+			return
+		}
+		// continue after the last key of the chunk
+		seek = keys[len(keys)-1]
+		skipFirst = true
+	}
+}
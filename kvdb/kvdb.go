@@ -0,0 +1,148 @@
+// Package kvdb implements selection of the embedded key/value database engine (backend) for
+// the multi-state database and the transaction store. All backends implement common.KVStore.
+// The backend of the existing database is detected from the files in its directory, so the
+// database opened with the wrong backend is never silently re-created empty
+package kvdb
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/lunfardo314/unitrie/adaptors/badger_adaptor"
+	"github.com/lunfardo314/unitrie/common"
+)
+
+type (
+	// DB is the database of any backend
+	DB interface {
+		common.KVStore
+		IsClosed() bool
+		Close() error
+	}
+
+	backend struct {
+		// file in the database directory which identifies the backend
+		markerFile string
+		open       func(dir string) (DB, error)
+	}
+)
+
+const (
+	// BackendBadger is Badger DB, the LSM tree based engine. Default
+	BackendBadger = "badger"
+	// BackendBolt is bbolt, the B+tree based engine with memory mapped file. It has smaller memory footprint than Badger
+	BackendBolt = "bolt"
+	// BackendMemWAL keeps all data in memory and persists it in the write-ahead log. Intended for tests and small databases
+	BackendMemWAL = "memwal"
+
+	DefaultBackend = BackendBadger
+)
+
+var backends = map[string]backend{
+	BackendBadger: {
+		markerFile: "MANIFEST",
+		open: func(dir string) (DB, error) {
+			bdb, err := badger_adaptor.OpenBadgerDB(dir)
+			if err != nil {
+				return nil, err
+			}
+			return badger_adaptor.New(bdb), nil
+		},
+	},
+	BackendBolt: {
+		markerFile: boltFileName,
+		open:       openBolt,
+	},
+	BackendMemWAL: {
+		markerFile: memWALFileName,
+		open:       openMemWAL,
+	},
+}
+
+var ErrWrongBackend = errors.New("database exists with another backend")
+
+// Backends returns names of all supported backends
+func Backends() []string {
+	return []string{BackendBadger, BackendBolt, BackendMemWAL}
+}
+
+// Detect returns backend of the existing database in the directory or empty string if there is no database
+func Detect(dir string) string {
+	for _, name := range Backends() {
+		if _, err := os.Stat(filepath.Join(dir, backends[name].markerFile)); err == nil {
+			return name
+		}
+	}
+	return ""
+}
+
+// Open opens the database in the directory or creates new one with the backend.
+// If the backend is empty, backend of the existing database is used or the default one for the new database.
+// Opening the existing database with another backend is an error
+func Open(dir string, backendName string) (DB, error) {
+	detected := Detect(dir)
+	switch {
+	case backendName == "" && detected == "":
+		backendName = DefaultBackend
+	case backendName == "":
+		backendName = detected
+	case detected != "" && detected != backendName:
+		return nil, fmt.Errorf("%w: '%s' is '%s' database, backend '%s' is configured. Use 'proxi db migrate' to convert it",
+			ErrWrongBackend, dir, detected, backendName)
+	}
+	b, ok := backends[backendName]
+	if !ok {
+		return nil, fmt.Errorf("unknown database backend '%s'. Supported backends: %s", backendName, strings.Join(Backends(), ", "))
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	return b.open(dir)
+}
+
+func MustOpen(dir string, backendName string) DB {
+	ret, err := Open(dir, backendName)
+	if err != nil {
+		panic(err)
+	}
+	return ret
+}
+
+const defaultCopyBatchSize = 10_000
+
+// Copy copies all key/value pairs from one store to another in batches. Returns number of copied pairs
+func Copy(dst common.BatchedUpdatable, src common.Traversable, batchSize ...int) (int, error) {
+	bs := defaultCopyBatchSize
+	if len(batchSize) > 0 && batchSize[0] > 0 {
+		bs = batchSize[0]
+	}
+	count := 0
+	var err error
+	batch := dst.BatchedWriter()
+	inBatch := 0
+	src.Iterator(nil).Iterate(func(k, v []byte) bool {
+		// iterator may reuse buffers
+		batch.Set(bytes.Clone(k), bytes.Clone(v))
+		count++
+		inBatch++
+		if inBatch >= bs {
+			if err = batch.Commit(); err != nil {
+				return false
+			}
+			batch = dst.BatchedWriter()
+			inBatch = 0
+		}
+		return true
+	})
+	if err != nil {
+		return count, err
+	}
+	if inBatch > 0 {
+		err = batch.Commit()
+	}
+	return count, err
+}
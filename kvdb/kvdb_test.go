@@ -0,0 +1,134 @@
+package kvdb
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func testBackends() []string {
+	// Badger is tested by its adaptor
+	return []string{BackendBolt, BackendMemWAL}
+}
+
+func TestBasic(t *testing.T) {
+	for _, backend := range testBackends() {
+		t.Run(backend, func(t *testing.T) {
+			dir := filepath.Join(t.TempDir(), "db")
+			db, err := Open(dir, backend)
+			require.NoError(t, err)
+			require.Equal(t, backend, Detect(dir))
+
+			require.Nil(t, db.Get([]byte("a")))
+			require.False(t, db.Has([]byte("a")))
+			db.Set([]byte("a"), []byte("1"))
+			require.Equal(t, []byte("1"), db.Get([]byte("a")))
+			require.True(t, db.Has([]byte("a")))
+
+			batch := db.BatchedWriter()
+			for i := 0; i < 2500; i++ {
+				batch.Set([]byte(fmt.Sprintf("k%05d", i)), []byte(fmt.Sprintf("v%d", i)))
+			}
+			batch.Set([]byte("a"), nil)
+			require.NoError(t, batch.Commit())
+			require.False(t, db.Has([]byte("a")))
+
+			// iteration is ordered and crosses chunk boundaries
+			i := 0
+			db.Iterator([]byte("k")).Iterate(func(k, v []byte) bool {
+				require.Equal(t, fmt.Sprintf("k%05d", i), string(k))
+				require.Equal(t, fmt.Sprintf("v%d", i), string(v))
+				i++
+				return true
+			})
+			require.Equal(t, 2500, i)
+
+			// callback may update the database
+			n := 0
+			db.Iterator([]byte("k0000")).IterateKeys(func(k []byte) bool {
+				db.Set(k, nil)
+				n++
+				return true
+			})
+			require.Equal(t, 10, n)
+			require.False(t, db.Has([]byte("k00005")))
+			require.True(t, db.Has([]byte("k00010")))
+
+			require.NoError(t, db.Close())
+
+			// reopen with detected backend
+			db, err = Open(dir, "")
+			require.NoError(t, err)
+			require.Equal(t, []byte("v10"), db.Get([]byte("k00010")))
+			require.False(t, db.Has([]byte("k00005")))
+			require.NoError(t, db.Close())
+		})
+	}
+}
+
+func TestWrongBackend(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "db")
+	db, err := Open(dir, BackendMemWAL)
+	require.NoError(t, err)
+	require.NoError(t, db.Close())
+
+	_, err = Open(dir, BackendBolt)
+	require.ErrorIs(t, err, ErrWrongBackend)
+
+	_, err = Open(filepath.Join(t.TempDir(), "db1"), "unknown")
+	require.Error(t, err)
+}
+
+func TestMemWALTornTail(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "db")
+	db, err := Open(dir, BackendMemWAL)
+	require.NoError(t, err)
+	db.Set([]byte("a"), []byte("1"))
+	db.Set([]byte("b"), []byte("2"))
+	require.NoError(t, db.Close())
+
+	// simulate crash in the middle of writing the last record
+	fname := filepath.Join(dir, memWALFileName)
+	fi, err := os.Stat(fname)
+	require.NoError(t, err)
+	require.NoError(t, os.Truncate(fname, fi.Size()-1))
+
+	db, err = Open(dir, "")
+	require.NoError(t, err)
+	require.Equal(t, []byte("1"), db.Get([]byte("a")))
+	require.False(t, db.Has([]byte("b")))
+
+	// log is usable after the torn tail is cut
+	db.Set([]byte("c"), []byte("3"))
+	require.NoError(t, db.Close())
+
+	db, err = Open(dir, "")
+	require.NoError(t, err)
+	require.Equal(t, []byte("3"), db.Get([]byte("c")))
+	require.NoError(t, db.Close())
+}
+
+func TestCopy(t *testing.T) {
+	src, err := Open(filepath.Join(t.TempDir(), "src"), BackendMemWAL)
+	require.NoError(t, err)
+	batch := src.BatchedWriter()
+	for i := 0; i < 1000; i++ {
+		batch.Set([]byte(fmt.Sprintf("%04d", i)), []byte(fmt.Sprintf("%d", i)))
+	}
+	require.NoError(t, batch.Commit())
+
+	dst, err := Open(filepath.Join(t.TempDir(), "dst"), BackendBolt)
+	require.NoError(t, err)
+
+	n, err := Copy(dst, src, 300)
+	require.NoError(t, err)
+	require.Equal(t, 1000, n)
+	for i := 0; i < 1000; i++ {
+		require.Equal(t, src.Get([]byte(fmt.Sprintf("%04d", i))), dst.Get([]byte(fmt.Sprintf("%04d", i))))
+	}
+	require.NoError(t, src.Close())
+	require.NoError(t, dst.Close())
+}
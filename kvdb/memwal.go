@@ -0,0 +1,351 @@
+package kvdb
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/lunfardo314/proxima/util"
+	"github.com/lunfardo314/unitrie/common"
+)
+
+// memWAL keeps all key/value pairs in memory and appends each update to the write-ahead log.
+// At open the log is replayed. If the log is much bigger than the data, it is compacted by
+// rewriting it with the current data.
+// Each record of the log is: 4 bytes length of the payload, 4 bytes CRC32 of the payload, payload.
+// Payload is a sequence of updates: uvarint key length, key, uvarint value length, value.
+// Empty value means deletion. A torn record at the end of the log (e.g. after crash) is discarded
+
+type (
+	memWAL struct {
+		mutex sync.RWMutex
+		data  map[string][]byte
+		// sorted keys, nil if must be re-sorted
+		sorted  []string
+		dir     string
+		file    *os.File
+		logSize int64
+		// size of keys and values in memory
+		dataSize int64
+	}
+
+	memWALBatch struct {
+		db   *memWAL
+		keys [][]byte
+		vals [][]byte
+	}
+
+	memWALIterator struct {
+		db     *memWAL
+		prefix []byte
+	}
+)
+
+const (
+	memWALFileName    = "memwal.log"
+	memWALTmpFileName = "memwal.tmp"
+	// log is compacted at open when it is more than compactFactor times bigger than the data
+	memWALCompactFactor = 2
+	memWALMinCompactLog = 1 << 20
+)
+
+var errMemWALClosed = errors.New("memwal database is closed")
+
+func openMemWAL(dir string) (DB, error) {
+	ret := &memWAL{
+		data: make(map[string][]byte),
+		dir:  dir,
+	}
+	fname := filepath.Join(dir, memWALFileName)
+	validSize, err := ret.replay(fname)
+	if err != nil {
+		return nil, err
+	}
+	if validSize > memWALMinCompactLog && validSize > memWALCompactFactor*ret.dataSize {
+		if err = ret.compact(); err != nil {
+			return nil, err
+		}
+	} else {
+		// open for appending and cut the torn tail, if any
+		if ret.file, err = os.OpenFile(fname, os.O_RDWR|os.O_CREATE, 0644); err != nil {
+			return nil, err
+		}
+		if err = ret.file.Truncate(validSize); err != nil {
+			return nil, err
+		}
+		if _, err = ret.file.Seek(validSize, io.SeekStart); err != nil {
+			return nil, err
+		}
+		ret.logSize = validSize
+	}
+	return ret, nil
+}
+
+// replay reads log and returns size of the valid part of it
+func (m *memWAL) replay(fname string) (int64, error) {
+	f, err := os.Open(fname)
+	if errors.Is(err, os.ErrNotExist) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	defer func() { _ = f.Close() }()
+
+	r := bufio.NewReader(f)
+	var validSize int64
+	var header [8]byte
+	for {
+		if _, err = io.ReadFull(r, header[:]); err != nil {
+			return validSize, nil
+		}
+		payload := make([]byte, binary.BigEndian.Uint32(header[:4]))
+		if _, err = io.ReadFull(r, payload); err != nil {
+			return validSize, nil
+		}
+		if crc32.ChecksumIEEE(payload) != binary.BigEndian.Uint32(header[4:]) {
+			return validSize, nil
+		}
+		if err = m.applyPayload(payload); err != nil {
+			return validSize, nil
+		}
+		validSize += int64(len(header) + len(payload))
+	}
+}
+
+func (m *memWAL) applyPayload(payload []byte) error {
+	for len(payload) > 0 {
+		key, rest, err := readChunk(payload)
+		if err != nil {
+			return err
+		}
+		value, rest, err := readChunk(rest)
+		if err != nil {
+			return err
+		}
+		m.setNoLock(key, value)
+		payload = rest
+	}
+	return nil
+}
+
+func readChunk(data []byte) ([]byte, []byte, error) {
+	l, n := binary.Uvarint(data)
+	if n <= 0 || uint64(len(data)-n) < l {
+		return nil, nil, io.ErrUnexpectedEOF
+	}
+	return data[n : n+int(l)], data[n+int(l):], nil
+}
+
+func appendChunk(buf, data []byte) []byte {
+	buf = binary.AppendUvarint(buf, uint64(len(data)))
+	return append(buf, data...)
+}
+
+// compact writes current data into the temporary log and replaces the log with it
+func (m *memWAL) compact() error {
+	tmpName := filepath.Join(m.dir, memWALTmpFileName)
+	f, err := os.Create(tmpName)
+	if err != nil {
+		return err
+	}
+	w := bufio.NewWriter(f)
+	var size int64
+	for k, v := range m.data {
+		n, err1 := writeRecord(w, appendChunk(appendChunk(nil, []byte(k)), v))
+		if err1 != nil {
+			_ = f.Close()
+			return err1
+		}
+		size += n
+	}
+	if err = w.Flush(); err != nil {
+		_ = f.Close()
+		return err
+	}
+	if err = f.Sync(); err != nil {
+		_ = f.Close()
+		return err
+	}
+	if err = os.Rename(tmpName, filepath.Join(m.dir, memWALFileName)); err != nil {
+		_ = f.Close()
+		return err
+	}
+	m.file = f
+	m.logSize = size
+	return nil
+}
+
+func writeRecord(w io.Writer, payload []byte) (int64, error) {
+	var header [8]byte
+	binary.BigEndian.PutUint32(header[:4], uint32(len(payload)))
+	binary.BigEndian.PutUint32(header[4:], crc32.ChecksumIEEE(payload))
+	if _, err := w.Write(header[:]); err != nil {
+		return 0, err
+	}
+	if _, err := w.Write(payload); err != nil {
+		return 0, err
+	}
+	return int64(len(header) + len(payload)), nil
+}
+
+func (m *memWAL) setNoLock(key, value []byte) {
+	k := string(key)
+	old, exists := m.data[k]
+	if exists {
+		m.dataSize -= int64(len(k) + len(old))
+	}
+	if len(value) == 0 {
+		if exists {
+			delete(m.data, k)
+			m.sorted = nil
+		}
+		return
+	}
+	m.data[k] = bytes.Clone(value)
+	m.dataSize += int64(len(k) + len(value))
+	if !exists {
+		m.sorted = nil
+	}
+}
+
+// update writes the batch of updates to the log and applies it to the data
+func (m *memWAL) update(keys, vals [][]byte, sync bool) error {
+	var payload []byte
+	for i := range keys {
+		payload = appendChunk(appendChunk(payload, keys[i]), vals[i])
+	}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if m.file == nil {
+		return errMemWALClosed
+	}
+	n, err := writeRecord(m.file, payload)
+	if err != nil {
+		return err
+	}
+	m.logSize += n
+	if sync {
+		if err = m.file.Sync(); err != nil {
+			return err
+		}
+	}
+	for i := range keys {
+		m.setNoLock(keys[i], vals[i])
+	}
+	return nil
+}
+
+func (m *memWAL) Get(key []byte) []byte {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	if v, ok := m.data[string(key)]; ok {
+		return bytes.Clone(v)
+	}
+	return nil
+}
+
+func (m *memWAL) Has(key []byte) bool {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	_, ok := m.data[string(key)]
+	return ok
+}
+
+// Set with empty value deletes the key. The log is not synced to disk, as opposed to the batch commit
+func (m *memWAL) Set(key, value []byte) {
+	util.AssertNoError(m.update([][]byte{key}, [][]byte{value}, false))
+}
+
+func (m *memWAL) BatchedWriter() common.KVBatchedWriter {
+	return &memWALBatch{db: m}
+}
+
+func (m *memWAL) Iterator(prefix []byte) common.KVIterator {
+	return &memWALIterator{db: m, prefix: prefix}
+}
+
+func (m *memWAL) IsClosed() bool {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	return m.file == nil
+}
+
+func (m *memWAL) Close() error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if m.file == nil {
+		return nil
+	}
+	err := m.file.Sync()
+	if err1 := m.file.Close(); err == nil {
+		err = err1
+	}
+	m.file = nil
+	return err
+}
+
+// keysWithPrefix returns sorted keys with the prefix at the moment of the call
+func (m *memWAL) keysWithPrefix(prefix []byte) []string {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if m.sorted == nil {
+		m.sorted = make([]string, 0, len(m.data))
+		for k := range m.data {
+			m.sorted = append(m.sorted, k)
+		}
+		sort.Strings(m.sorted)
+	}
+	p := string(prefix)
+	from := sort.SearchStrings(m.sorted, p)
+	to := from
+	for to < len(m.sorted) && strings.HasPrefix(m.sorted[to], p) {
+		to++
+	}
+	return m.sorted[from:to:to]
+}
+
+func (b *memWALBatch) Set(key, value []byte) {
+	b.keys = append(b.keys, key)
+	b.vals = append(b.vals, value)
+}
+
+func (b *memWALBatch) Commit() error {
+	return b.db.update(b.keys, b.vals, true)
+}
+
+func (it *memWALIterator) Iterate(fun func(k, v []byte) bool) {
+	for _, k := range it.db.keysWithPrefix(it.prefix) {
+		// the pair may be deleted by the callback
+		if v := it.db.Get([]byte(k)); v != nil {
+			if !fun([]byte(k), v) {
+				return
+			}
+		}
+	}
+}
+
+func (it *memWALIterator) IterateKeys(fun func(k []byte) bool) {
+	for _, k := range it.db.keysWithPrefix(it.prefix) {
+		if it.db.Has([]byte(k)) {
+			if !fun([]byte(k)) {
+				return
+			}
+		}
+	}
+}
@@ -1,6 +1,7 @@
 package ledger
 
 import (
+	"sync/atomic"
 	"time"
 
 	"github.com/lunfardo314/proxima/ledger/base"
@@ -14,8 +15,21 @@ func SlotDuration() time.Duration {
 	return L().ID.SlotDuration()
 }
 
+// clockCorrection is added to the local clock when it is converted to the ledger time and back.
+// It is 0 unless the node corrects its clock according to clocks of peers
+var clockCorrection atomic.Int64
+
+// SetClockCorrection sets correction of the local clock
+func SetClockCorrection(d time.Duration) {
+	clockCorrection.Store(int64(d))
+}
+
+func ClockCorrection() time.Duration {
+	return time.Duration(clockCorrection.Load())
+}
+
 func TimeFromClockTime(nowis time.Time) base.LedgerTime {
-	return L().ID.LedgerTimeFromClockTime(nowis)
+	return L().ID.LedgerTimeFromClockTime(nowis.Add(ClockCorrection()))
 }
 
 func UnixNanoFromLedgerTime(t base.LedgerTime) int64 {
@@ -36,8 +50,9 @@ func ValidSequencerPace(t1, t2 base.LedgerTime) bool {
 	return base.DiffTicks(t2, t1) >= int64(TransactionPaceSequencer())
 }
 
+// ClockTime is the local clock time of the ledger time
 func ClockTime(t base.LedgerTime) time.Time {
-	return time.Unix(0, UnixNanoFromLedgerTime(t))
+	return time.Unix(0, UnixNanoFromLedgerTime(t)).Add(-ClockCorrection())
 }
 
 func TooCloseOnTimeAxis(txid1, txid2 base.TransactionID) bool {
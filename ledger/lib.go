@@ -19,6 +19,9 @@ type (
 		constraintByPrefix map[string]*constraintRecord
 		constraintNames    map[string]struct{}
 		inlineTests        []func()
+		// all versions of the library starting from version 0. Empty if there are no upgrades
+		versions []libraryVersion
+		upgrades *LibraryUpgrades
 	}
 
 	LibraryConst struct {
@@ -22,15 +22,23 @@ func L() *Library {
 	return libraryGlobal
 }
 
-func MustInitSingleton(identityData []byte) {
+// MustInitSingleton initializes ledger library from the ledger identity data and optional schedule of library upgrades.
+// With upgrades, the ledger library is the latest version. Transactions are validated with the version active in their slot
+func MustInitSingleton(identityData []byte, upgrades ...*LibraryUpgrades) {
+	var ret *Library
+	if len(upgrades) > 0 && upgrades[0] != nil && len(upgrades[0].Upgrades) > 0 {
+		ret = mustNewLibraryWithUpgrades(identityData, upgrades[0])
+	} else {
+		lib, idParams, err := ParseLedgerIdYAML(identityData, base.GetEmbeddedFunctionResolver)
+		util.AssertNoError(err)
+		ret = newLibrary(lib, idParams, identityData)
+	}
+
 	libraryGlobalMutex.Lock()
 
 	util.Assertf(libraryGlobal == nil, "ledger is already initialized")
 
-	lib, idParams, err := ParseLedgerIdYAML(identityData, base.GetEmbeddedFunctionResolver)
-	util.AssertNoError(err)
-
-	libraryGlobal = newLibrary(lib, idParams, identityData)
+	libraryGlobal = ret
 	libraryGlobal.registerConstraints()
 
 	libraryGlobalMutex.Unlock()
@@ -38,6 +46,30 @@ func MustInitSingleton(identityData []byte) {
 	libraryGlobal.runInlineTests()
 }
 
+func mustNewLibraryWithUpgrades(identityData []byte, upgrades *LibraryUpgrades) *Library {
+	err := upgrades.CheckCompiled()
+	util.AssertNoError(err)
+	libs, err := CompileLibraryUpgrades(identityData, upgrades)
+	util.AssertNoError(err)
+	_, idParams, err := ParseLedgerIdYAML(identityData)
+	util.AssertNoError(err)
+
+	ret := newLibrary(libs[len(libs)-1], idParams, identityData)
+	ret.versions = make([]libraryVersion, len(libs))
+	for v, lib := range libs {
+		ret.versions[v] = libraryVersion{
+			version: v,
+			lib:     lib,
+			hash:    lib.LibraryHash(),
+		}
+		if v > 0 {
+			ret.versions[v].activationSlot = base.Slot(upgrades.Upgrades[v-1].ActivationSlot)
+		}
+	}
+	ret.upgrades = upgrades
+	return ret
+}
+
 // InitWithTestingLedgerIDData for testing
 func InitWithTestingLedgerIDData(opts ...func(data *IdentityParameters)) ed25519.PrivateKey {
 	id, pk := GetTestingIdentityData(31415926535)
@@ -49,6 +81,19 @@ func InitWithTestingLedgerIDData(opts ...func(data *IdentityParameters)) ed25519
 	return pk
 }
 
+// InitWithTestingLedgerIDDataAndUpgrades for testing. Compiles and schedules library upgrades on top of the testing ledger identity
+func InitWithTestingLedgerIDDataAndUpgrades(upgradesYAML []byte, opts ...func(data *IdentityParameters)) ed25519.PrivateKey {
+	id, pk := GetTestingIdentityData(31415926535)
+	for _, opt := range opts {
+		opt(id)
+	}
+	idData := LibraryFromIdentityParameters(id).ToYAML(true)
+	upgrades, err := CompileLibraryUpgradesYAML(idData, upgradesYAML)
+	util.AssertNoError(err)
+	MustInitSingleton(idData, upgrades)
+	return pk
+}
+
 func WithTickDuration(d time.Duration) func(id *IdentityParameters) {
 	return func(id *IdentityParameters) {
 		id.SetTickDuration(d)
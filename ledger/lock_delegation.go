@@ -5,6 +5,7 @@ import (
 	"encoding/binary"
 	"encoding/hex"
 	"fmt"
+	"sync"
 	"sync/atomic"
 
 	"github.com/lunfardo314/easyfl"
@@ -228,8 +229,9 @@ func IsOpenDelegationSlot(chainID base.ChainID, slot base.Slot) bool {
 	return len(easyfl.EvalExpression(nil, __precompiledIsOpenDelegationSlot(), chainID[:4], slot.Bytes())) > 0
 }
 
-func MinimumDelegationAmount() uint64 {
-	res, err := L().EvalFromSource(nil, "minimumDelegatedAmount")
+// MinimumDelegationAmount is the minimum amount on the delegation output with the library version active in the slot
+func MinimumDelegationAmount(slot base.Slot) uint64 {
+	res, err := L().LibraryAt(slot).EvalFromSource(nil, "minimumDelegatedAmount")
 	util.AssertNoError(err)
 	return binary.BigEndian.Uint64(res)
 }
@@ -242,8 +244,8 @@ func NextOpenDelegationSlot(chainID base.ChainID, slot base.Slot) base.Slot {
 
 func NextOpenDelegationTimestamp(chainID base.ChainID, ts base.LedgerTime) (ret base.LedgerTime) {
 	ret = ts
-	if base.DiffTicks(ret, ts) < int64(DelegationLockPaceTicks()) {
-		ret = ts.AddTicks(int(DelegationLockPaceTicks()))
+	if pace := DelegationLockPaceTicks(ts.Slot); base.DiffTicks(ret, ts) < int64(pace) {
+		ret = ts.AddTicks(int(pace))
 	}
 	return base.NewLedgerTime(NextOpenDelegationSlot(chainID, ret.Slot), ret.Tick)
 }
@@ -256,27 +258,29 @@ func NextClosedDelegationSlot(chainID base.ChainID, slot base.Slot) base.Slot {
 
 func NextClosedDelegationTimestamp(chainID base.ChainID, ts base.LedgerTime) (ret base.LedgerTime) {
 	ret = ts
-	if base.DiffTicks(ret, ts) < int64(DelegationLockPaceTicks()) {
-		ret = ts.AddTicks(int(DelegationLockPaceTicks()))
+	if pace := DelegationLockPaceTicks(ts.Slot); base.DiffTicks(ret, ts) < int64(pace) {
+		ret = ts.AddTicks(int(pace))
 	}
 	return base.NewLedgerTime(NextClosedDelegationSlot(chainID, ret.Slot), ret.Tick)
 }
 
-var _delegationLockPace atomic.Uint64
+// delegation pace by library version
+var _delegationLockPace sync.Map
 
-func DelegationLockPaceTicks() uint64 {
-	v := _delegationLockPace.Load()
-	if v > 0 {
-		return v
+// DelegationLockPaceTicks is the delegation pace with the library version active in the slot
+func DelegationLockPaceTicks(slot base.Slot) uint64 {
+	lib := L().LibraryAt(slot)
+	if v, ok := _delegationLockPace.Load(lib); ok {
+		return v.(uint64)
 	}
-	constBin, err := L().EvalFromSource(nil, "delegationPaceTicks")
+	constBin, err := lib.EvalFromSource(nil, "delegationPaceTicks")
 	util.AssertNoError(err)
 
-	v = binary.BigEndian.Uint64(constBin)
-	_delegationLockPace.Store(v)
+	v := binary.BigEndian.Uint64(constBin)
+	_delegationLockPace.Store(lib, v)
 	return v
 }
 
 func ValidDelegationPace(predTs, succTs base.LedgerTime) bool {
-	return base.DiffTicks(succTs, predTs) >= int64(DelegationLockPaceTicks())
+	return base.DiffTicks(succTs, predTs) >= int64(DelegationLockPaceTicks(succTs.Slot))
 }
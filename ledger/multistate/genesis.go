@@ -22,7 +22,7 @@ func CommitEmptyRootWithLedgerIdentity(id []byte, store StateStore) (common.VCom
 }
 
 // InitStateStoreWithGlobalLedgerIdentity initializes origin ledger state in the empty store
-// Writes initial supply and origin stem outputs. Plus writes root record into the DB
+// Writes initial supply and origin stem outputs and the schedule of library upgrades, if any. Plus writes root record into the DB
 // Returns root commitment to the genesis ledger state and genesis chainID
 // The function is dependent on the global singleton of ledger definitions, because
 // origin outputs can be created only with the library
@@ -53,6 +53,10 @@ func genesisUpdateMutations(genesisOut, genesisStemOut *ledger.OutputWithID) *Mu
 	ret.InsertAddOutputMutation(genesisOut.ID, genesisOut.Output)
 	ret.InsertAddOutputMutation(genesisStemOut.ID, genesisStemOut.Output)
 	ret.InsertAddTxMutation(base.GenesisTransactionID(), genesisOut.ID.Slot(), 1)
+	if upgrades := ledger.L().Upgrades(); upgrades != nil {
+		// the schedule is committed in the genesis state, so all nodes of the network run with the same library versions
+		ret.InsertSetLibraryUpgradesMutation(upgrades.YAML())
+	}
 	return ret
 }
 
@@ -92,6 +96,9 @@ func ScanGenesisState(stateStore StateStore) (*ledger.IdentityParameters, common
 	return stateID, branchData.Root, nil
 }
 
+// InitLedgerFromStore initializes ledger library with the ledger identity and library upgrades committed in the state
 func InitLedgerFromStore(stateStore StateStore) {
-	ledger.MustInitSingleton(LedgerIdentityBytesFromStore(stateStore))
+	upgrades, err := FetchLibraryUpgrades(stateStore)
+	util.AssertNoError(err)
+	ledger.MustInitSingleton(LedgerIdentityBytesFromStore(stateStore), upgrades)
 }
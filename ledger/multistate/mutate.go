@@ -45,6 +45,10 @@ type (
 		ChainID base.ChainID
 	}
 
+	mutationSetLibraryUpgrades struct {
+		YAML []byte
+	}
+
 	Mutations struct {
 		mut []mutationCmd
 	}
@@ -114,6 +118,23 @@ func (m *mutationDelChain) timestamp() base.LedgerTime {
 	return base.NewLedgerTime(0xffffffff, 0xff)
 }
 
+func (m *mutationSetLibraryUpgrades) mutate(trie *immutable.TrieUpdatable) (delta supplyDelta, err error) {
+	trie.Update([]byte{TriePartitionLibraryUpgrades}, m.YAML)
+	return
+}
+
+func (m *mutationSetLibraryUpgrades) text() string {
+	return fmt.Sprintf("UPGR  %d bytes", len(m.YAML))
+}
+
+func (m *mutationSetLibraryUpgrades) sortOrder() byte {
+	return 4
+}
+
+func (m *mutationSetLibraryUpgrades) timestamp() base.LedgerTime {
+	return base.NewLedgerTime(0, 0)
+}
+
 func NewMutations() *Mutations {
 	return &Mutations{
 		mut: make([]mutationCmd, 0),
@@ -154,6 +175,10 @@ func (mut *Mutations) InsertDelChainMutation(id base.ChainID) {
 	mut.mut = append(mut.mut, &mutationDelChain{ChainID: id})
 }
 
+func (mut *Mutations) InsertSetLibraryUpgradesMutation(upgradesYAML []byte) {
+	mut.mut = append(mut.mut, &mutationSetLibraryUpgrades{YAML: upgradesYAML})
+}
+
 func (mut *Mutations) Lines(prefix ...string) *lines.Lines {
 	ret := lines.New(prefix...)
 	mutClone := slices.Clone(mut.mut)
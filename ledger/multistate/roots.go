@@ -15,7 +15,7 @@ import (
 	"github.com/lunfardo314/unitrie/immutable"
 )
 
-// two additional partitions of the k/v store
+// additional partitions of the k/v store
 const (
 	// rootRecordDBPartition
 	rootRecordDBPartition   = immutable.PartitionOther
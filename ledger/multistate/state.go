@@ -59,6 +59,8 @@ const (
 	TriePartitionAccounts
 	TriePartitionChainID
 	TriePartitionCommittedTransactionID
+	// TriePartitionLibraryUpgrades contains single record with the schedule of ledger library upgrades, committed in genesis
+	TriePartitionLibraryUpgrades
 )
 
 func PartitionToString(p byte) string {
@@ -71,6 +73,8 @@ func PartitionToString(p byte) string {
 		return "CHID"
 	case TriePartitionCommittedTransactionID:
 		return "TXID"
+	case TriePartitionLibraryUpgrades:
+		return "UPGR"
 	default:
 		return "????"
 	}
@@ -87,6 +91,27 @@ func LedgerIdentityBytesFromRoot(store StateStoreReader, root common.VCommitment
 	return trie.Get(nil)
 }
 
+// LibraryUpgradesBytesFromStore returns YAML of the ledger library upgrades committed in the state or nil if there are no upgrades
+func LibraryUpgradesBytesFromStore(store StateStore) []byte {
+	rr := FetchAnyLatestRootRecord(store)
+	return LibraryUpgradesBytesFromRoot(store, rr.Root)
+}
+
+func LibraryUpgradesBytesFromRoot(store StateStoreReader, root common.VCommitment) []byte {
+	trie, err := immutable.NewTrieReader(ledger.CommitmentModel, store, root, 0)
+	util.AssertNoError(err)
+	return trie.Get([]byte{TriePartitionLibraryUpgrades})
+}
+
+// FetchLibraryUpgrades returns parsed schedule of ledger library upgrades committed in the state or nil if there are no upgrades
+func FetchLibraryUpgrades(store StateStore) (*ledger.LibraryUpgrades, error) {
+	data := LibraryUpgradesBytesFromStore(store)
+	if len(data) == 0 {
+		return nil, nil
+	}
+	return ledger.ParseLibraryUpgradesYAML(data)
+}
+
 // NewReadable creates read-only ledger state with the given root
 func NewReadable(store common.KVReader, root common.VCommitment, clearCacheAtSize ...int) (*Readable, error) {
 	trie, err := immutable.NewTrieReader(ledger.CommitmentModel, store, root, clearCacheAtSize...)
@@ -618,19 +618,21 @@ func (o *Output) MustValidOutput() {
 	util.AssertNoError(err)
 }
 
-func (o *Output) EnoughAmountForStorageDeposit() error {
-	if o.Amount() >= o.MinimumStorageDeposit(0) {
+// EnoughAmountForStorageDeposit checks storage deposit of the output produced by the transaction in the slot
+func (o *Output) EnoughAmountForStorageDeposit(slot base.Slot) error {
+	minDeposit := o.MinimumStorageDeposit(0, slot)
+	if o.Amount() >= minDeposit {
 		return nil
 	}
 	return fmt.Errorf("not enough tokens (%s) for the minimum storage deposit (%s)",
-		util.Th(o.Amount()), util.Th(o.MinimumStorageDeposit(0)))
+		util.Th(o.Amount()), util.Th(minDeposit))
 }
 
-func (o *Output) MinimumStorageDeposit(extraWeight uint32) uint64 {
+func (o *Output) MinimumStorageDeposit(extraWeight uint32, slot base.Slot) uint64 {
 	if _, isStem := o.StemLock(); isStem {
 		return 0
 	}
-	return StorageDeposit(len(o.Bytes()))
+	return StorageDeposit(len(o.Bytes()), slot)
 }
 
 // HashOutputs calculates input commitment from outputs: the hash of lazyarray composed of output data
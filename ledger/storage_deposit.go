@@ -4,14 +4,16 @@ import (
 	"encoding/binary"
 	"fmt"
 
+	"github.com/lunfardo314/proxima/ledger/base"
 	"github.com/lunfardo314/proxima/util"
 )
 
 // TODO proper calculation of the storage deposit
 
-func StorageDeposit(nBytes int) uint64 {
+// StorageDeposit evaluates storage deposit with the library version active in the slot of the transaction
+func StorageDeposit(nBytes int, slot base.Slot) uint64 {
 	src := fmt.Sprintf("storageDeposit(u32/%d)", nBytes)
-	res, err := L().EvalFromSource(nil, src)
+	res, err := L().LibraryAt(slot).EvalFromSource(nil, src)
 	util.AssertNoError(err)
 	return binary.BigEndian.Uint64(res)
 }
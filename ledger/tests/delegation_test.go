@@ -542,3 +542,134 @@ func TestDelegationChainLock(t *testing.T) {
 		require.True(t, err != nil && strings.Contains(err.Error(), "amount should not decrease"))
 	})
 }
+
+func TestDelegationTransitionTransaction(t *testing.T) {
+	const (
+		tokensFromFaucet = 200_000_000_000
+		delegatedTokens  = 1_000_000_000
+		withdrawAmount   = 100_000_000
+	)
+	var u *utxodb.UTXODB
+	var ownerPrivateKey ed25519.PrivateKey
+	var ownerAddr, targetAddr, newTargetAddr ledger.AddressED25519
+	var delegatedOutput *ledger.OutputWithChainID
+
+	initTest := func() base.ChainID {
+		u = utxodb.NewUTXODB(genesisPrivateKey, true)
+		privKey, _, addr := u.GenerateAddresses(0, 3)
+		ownerPrivateKey = privKey[0]
+		ownerAddr, targetAddr, newTargetAddr = addr[0], addr[1], addr[2]
+
+		err := u.TokensFromFaucet(ownerAddr, tokensFromFaucet)
+		require.NoError(t, err)
+		par, err := u.MakeTransferInputData(ownerPrivateKey, nil, base.NilLedgerTime)
+		require.NoError(t, err)
+		txBytes, err := txbuilder.MakeSimpleTransferTransaction(par.
+			WithAmount(delegatedTokens).
+			WithTargetLock(ledger.NewDelegationLock(ownerAddr, targetAddr, 2, ledger.TimeNow(), delegatedTokens)).
+			WithConstraint(ledger.NewChainOrigin()),
+		)
+		require.NoError(t, err)
+		require.NoError(t, u.AddTransaction(txBytes))
+
+		outs, err := multistate.MakeSugared(u.StateReader()).GetOutputsDelegatedToAccount(targetAddr)
+		require.NoError(t, err)
+		require.EqualValues(t, 1, len(outs))
+		delegatedOutput = outs[0]
+		chainID, _, _ := delegatedOutput.ExtractChainID()
+		return chainID
+	}
+	// the transaction is made on the closed slot, when only the owner can transit the delegation
+	nextTimestamp := func(chainID base.ChainID) base.LedgerTime {
+		ts := ledger.NextClosedDelegationTimestamp(chainID, delegatedOutput.Timestamp().AddSlots(2))
+		if ts.IsSlotBoundary() {
+			ts = ts.AddTicks(1)
+		}
+		return ts
+	}
+	delegatedOutputAfter := func(delegatedTo ledger.Accountable) *ledger.OutputWithChainID {
+		outs, err := multistate.MakeSugared(u.StateReader()).GetOutputsDelegatedToAccount(delegatedTo)
+		require.NoError(t, err)
+		require.EqualValues(t, 1, len(outs))
+		return outs[0]
+	}
+
+	t.Run("withdraw", func(t *testing.T) {
+		chainID := initTest()
+		lockBefore := delegatedOutput.Output.DelegationLock()
+		balanceBefore := u.Balance(ownerAddr)
+		ts := nextTimestamp(chainID)
+		inflation := ledger.L().CalcChainInflationAmount(delegatedOutput.Timestamp(), ts, delegatedOutput.Output.Amount())
+
+		tx, err := txbuilder.MakeDelegationTransitionTransaction(txbuilder.DelegationTransitionParams{
+			Timestamp:      ts,
+			ChainIn:        delegatedOutput,
+			PrivateKey:     ownerPrivateKey,
+			WithdrawAmount: withdrawAmount,
+		})
+		require.NoError(t, err)
+		err = u.AddTransaction(tx.Bytes())
+		if err != nil {
+			t.Logf("============ failing transaction ==============\n%s", u.TxToString(tx.Bytes()))
+		}
+		require.NoError(t, err)
+
+		out := delegatedOutputAfter(targetAddr)
+		require.True(t, out.ChainID == chainID)
+		require.EqualValues(t, delegatedTokens+inflation-withdrawAmount, out.Output.Amount())
+		// delegation lock is preserved
+		require.EqualValues(t, lockBefore.Bytes(), out.Output.DelegationLock().Bytes())
+		require.EqualValues(t, balanceBefore+withdrawAmount, u.Balance(ownerAddr))
+	})
+	t.Run("move", func(t *testing.T) {
+		chainID := initTest()
+		ts := nextTimestamp(chainID)
+
+		tx, err := txbuilder.MakeDelegationTransitionTransaction(txbuilder.DelegationTransitionParams{
+			Timestamp:  ts,
+			ChainIn:    delegatedOutput,
+			PrivateKey: ownerPrivateKey,
+			NewTarget:  newTargetAddr,
+		})
+		require.NoError(t, err)
+		require.NoError(t, u.AddTransaction(tx.Bytes()))
+
+		out := delegatedOutputAfter(newTargetAddr)
+		require.True(t, out.ChainID == chainID)
+		dl := out.Output.DelegationLock()
+		require.True(t, ledger.EqualAccountables(ownerAddr, dl.OwnerLock))
+		require.True(t, ledger.EqualAccountables(newTargetAddr, dl.TargetLock))
+		require.EqualValues(t, ts, dl.StartTime)
+		require.EqualValues(t, out.Output.Amount(), dl.StartAmount)
+	})
+	t.Run("not owner", func(t *testing.T) {
+		chainID := initTest()
+		_, err := txbuilder.MakeDelegationTransitionTransaction(txbuilder.DelegationTransitionParams{
+			Timestamp:  nextTimestamp(chainID),
+			ChainIn:    delegatedOutput,
+			PrivateKey: genesisPrivateKey,
+		})
+		require.Error(t, err)
+	})
+	t.Run("too much withdrawn", func(t *testing.T) {
+		chainID := initTest()
+		_, err := txbuilder.MakeDelegationTransitionTransaction(txbuilder.DelegationTransitionParams{
+			Timestamp:      nextTimestamp(chainID),
+			ChainIn:        delegatedOutput,
+			PrivateKey:     ownerPrivateKey,
+			WithdrawAmount: delegatedTokens,
+		})
+		require.Error(t, err)
+	})
+	t.Run("withdraw below storage deposit", func(t *testing.T) {
+		chainID := initTest()
+		_, err := txbuilder.MakeDelegationTransitionTransaction(txbuilder.DelegationTransitionParams{
+			Timestamp:      nextTimestamp(chainID),
+			ChainIn:        delegatedOutput,
+			PrivateKey:     ownerPrivateKey,
+			WithdrawAmount: 1,
+		})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "storage deposit")
+	})
+}
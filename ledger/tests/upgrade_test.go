@@ -0,0 +1,170 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/lunfardo314/proxima/ledger"
+	"github.com/stretchr/testify/require"
+)
+
+const testUpgradesYAML = `
+upgrades:
+  - version: 1
+    activation_slot: 100
+    description: "test upgrade 1"
+    functions:
+      - sym: "upgradeTestConcat"
+        numArgs: 2
+        source: "concat($0,$1)"
+  - version: 2
+    activation_slot: 200
+    functions:
+      - sym: "upgradeTestConcat3"
+        numArgs: 3
+        source: "concat(upgradeTestConcat($0,$1),$2)"
+`
+
+func TestLibraryUpgrades(t *testing.T) {
+	idData := ledger.L().IdentityData()
+
+	t.Run("compile", func(t *testing.T) {
+		upgrades, err := ledger.CompileLibraryUpgradesYAML(idData, []byte(testUpgradesYAML))
+		require.NoError(t, err)
+		require.NoError(t, upgrades.CheckCompiled())
+		require.Equal(t, 2, len(upgrades.Upgrades))
+		t.Logf("\n%s", string(upgrades.YAML()))
+
+		// compiled YAML parses back and compiles to the same hashes
+		upgradesBack, err := ledger.ParseLibraryUpgradesYAML(upgrades.YAML())
+		require.NoError(t, err)
+		libs, err := ledger.CompileLibraryUpgrades(idData, upgradesBack)
+		require.NoError(t, err)
+		require.Equal(t, 3, len(libs))
+		require.EqualValues(t, ledger.L().LibraryHash(), libs[0].LibraryHash())
+
+		// new functions are only in the new versions
+		_, err = libs[0].EvalFromSource(nil, "upgradeTestConcat(1,2)")
+		require.Error(t, err)
+		res, err := libs[1].EvalFromSource(nil, "upgradeTestConcat(1,2)")
+		require.NoError(t, err)
+		require.EqualValues(t, []byte{1, 2}, res)
+		_, err = libs[1].EvalFromSource(nil, "upgradeTestConcat3(1,2,3)")
+		require.Error(t, err)
+		res, err = libs[2].EvalFromSource(nil, "upgradeTestConcat3(1,2,3)")
+		require.NoError(t, err)
+		require.EqualValues(t, []byte{1, 2, 3}, res)
+
+		// bytecodes of the previous version are the same in the new version
+		for _, src := range []string{"concat(1,2)", "timestampBytes(u32/255, 21)", "addressED25519(0x01)"} {
+			_, _, bc0, err := libs[0].CompileExpression(src)
+			require.NoError(t, err)
+			_, _, bc2, err := libs[2].CompileExpression(src)
+			require.NoError(t, err)
+			require.EqualValues(t, bc0, bc2)
+		}
+
+		// wrong hash
+		upgradesBack.Upgrades[1].Hash = upgradesBack.Upgrades[0].Hash
+		_, err = ledger.CompileLibraryUpgrades(idData, upgradesBack)
+		require.Error(t, err)
+	})
+	t.Run("validate", func(t *testing.T) {
+		upgrades, err := ledger.ParseLibraryUpgradesYAML([]byte(testUpgradesYAML))
+		require.NoError(t, err)
+		require.Error(t, upgrades.CheckCompiled())
+
+		upgrades.Upgrades[1].ActivationSlot = 100
+		require.Error(t, upgrades.Validate())
+		upgrades.Upgrades[1].ActivationSlot = 200
+		upgrades.Upgrades[1].Version = 3
+		require.Error(t, upgrades.Validate())
+		upgrades.Upgrades[1].Version = 2
+		upgrades.Upgrades[1].Functions = nil
+		require.Error(t, upgrades.Validate())
+
+		_, err = ledger.ParseLibraryUpgradesYAML([]byte("upgrades:\n  - version: 1\n    activation_slot: 0\n"))
+		require.Error(t, err)
+	})
+	t.Run("extends", func(t *testing.T) {
+		upgrades, err := ledger.CompileLibraryUpgradesYAML(idData, []byte(testUpgradesYAML))
+		require.NoError(t, err)
+		prev := &ledger.LibraryUpgrades{Upgrades: upgrades.Upgrades[:1]}
+		require.NoError(t, upgrades.CheckExtends(nil))
+		require.NoError(t, upgrades.CheckExtends(prev))
+		require.Error(t, prev.CheckExtends(upgrades))
+
+		conflicting := &ledger.LibraryUpgrades{Upgrades: []ledger.LibraryUpgrade{upgrades.Upgrades[0]}}
+		conflicting.Upgrades[0].ActivationSlot = 150
+		require.Error(t, upgrades.CheckExtends(conflicting))
+	})
+	t.Run("redefine", func(t *testing.T) {
+		const redefineYAML = testUpgradesYAML + `
+  - version: 3
+    activation_slot: 300
+    functions:
+      - sym: "upgradeTestConcat"
+        numArgs: 2
+        source: "concat($1,$0)"
+`
+		upgrades, err := ledger.CompileLibraryUpgradesYAML(idData, []byte(redefineYAML))
+		require.NoError(t, err)
+		libs, err := ledger.CompileLibraryUpgrades(idData, upgrades)
+		require.NoError(t, err)
+		require.Equal(t, 4, len(libs))
+
+		// redefined function keeps function code
+		_, _, bc2, err := libs[2].CompileExpression("upgradeTestConcat(1,2)")
+		require.NoError(t, err)
+		_, _, bc3, err := libs[3].CompileExpression("upgradeTestConcat(1,2)")
+		require.NoError(t, err)
+		require.EqualValues(t, bc2, bc3)
+
+		res, err := libs[2].EvalFromBytecode(nil, bc2)
+		require.NoError(t, err)
+		require.EqualValues(t, []byte{1, 2}, res)
+		res, err = libs[3].EvalFromBytecode(nil, bc2)
+		require.NoError(t, err)
+		require.EqualValues(t, []byte{2, 1}, res)
+		// function which calls redefined function uses the new definition
+		res, err = libs[3].EvalFromSource(nil, "upgradeTestConcat3(1,2,3)")
+		require.NoError(t, err)
+		require.EqualValues(t, []byte{2, 1, 3}, res)
+
+		// number of arguments can't be changed
+		_, err = ledger.CompileLibraryUpgradesYAML(idData, []byte(testUpgradesYAML+`
+  - version: 3
+    activation_slot: 300
+    functions:
+      - sym: "upgradeTestConcat"
+        numArgs: 3
+        source: "concat($1,$0,$2)"
+`))
+		require.Error(t, err)
+		_, err = ledger.CompileLibraryUpgradesYAML(idData, []byte(testUpgradesYAML+`
+  - version: 3
+    activation_slot: 300
+    functions:
+      - sym: "upgradeTestConcat"
+        numArgs: 2
+        source: "concat($2,$0)"
+`))
+		require.Error(t, err)
+		// embedded function can't be redefined
+		_, err = ledger.CompileLibraryUpgradesYAML(idData, []byte(testUpgradesYAML+`
+  - version: 3
+    activation_slot: 300
+    functions:
+      - sym: "concat"
+        numArgs: -1
+        source: "0x"
+`))
+		require.Error(t, err)
+	})
+	t.Run("no upgrades", func(t *testing.T) {
+		require.EqualValues(t, [32]byte{}, ledger.L().UpgradesHashAt(1000))
+		require.Nil(t, ledger.L().Upgrades())
+		require.EqualValues(t, ledger.L().LibraryHash(), ledger.L().LedgerIDHash())
+		require.True(t, ledger.L().LibraryAt(1000) == ledger.L().Library)
+		require.EqualValues(t, 0, ledger.L().VersionAt(1000))
+	})
+}
@@ -0,0 +1,44 @@
+package upgrades
+
+import (
+	"crypto/ed25519"
+
+	"github.com/lunfardo314/proxima/ledger"
+)
+
+// initializes ledger.Library singleton with scheduled library upgrades for all tests and creates testing genesis private key
+
+const (
+	activationSlotV1 = 10
+	activationSlotV2 = 20
+	activationSlotV3 = 30
+)
+
+// version 1 adds function upgradeTestConcat, version 2 redefines it, version 3 doubles the storage deposit
+const testUpgradesYAML = `
+upgrades:
+  - version: 1
+    activation_slot: 10
+    functions:
+      - sym: "upgradeTestConcat"
+        numArgs: 2
+        source: "concat($0,$1)"
+  - version: 2
+    activation_slot: 20
+    functions:
+      - sym: "upgradeTestConcat"
+        numArgs: 2
+        source: "concat($1,$0)"
+  - version: 3
+    activation_slot: 30
+    functions:
+      - sym: "storageDeposit"
+        numArgs: 1
+        source: "mul(mul(constVBCost16,$0),u64/2)"
+`
+
+var genesisPrivateKey ed25519.PrivateKey
+
+func init() {
+	genesisPrivateKey = ledger.InitWithTestingLedgerIDDataAndUpgrades([]byte(testUpgradesYAML))
+}
@@ -0,0 +1,121 @@
+package upgrades
+
+import (
+	"testing"
+
+	"github.com/lunfardo314/proxima/ledger"
+	"github.com/lunfardo314/proxima/ledger/base"
+	"github.com/lunfardo314/proxima/ledger/multistate"
+	"github.com/lunfardo314/proxima/ledger/txbuilder"
+	"github.com/lunfardo314/proxima/ledger/utxodb"
+	"github.com/lunfardo314/unitrie/common"
+	"github.com/stretchr/testify/require"
+)
+
+// addTxWithConstraint adds transfer transaction in the slot with the output constraint compiled from the source
+func addTxWithConstraint(t *testing.T, slot base.Slot, constraintSource string) error {
+	u := utxodb.NewUTXODB(genesisPrivateKey, true)
+	privKey0, _, addr0 := u.GenerateAddress(0)
+	err := u.TokensFromFaucet(addr0, 10000)
+	require.NoError(t, err)
+	_, _, addr1 := u.GenerateAddress(1)
+
+	// compiled with the latest library version
+	_, _, constr, err := ledger.L().CompileExpression(constraintSource)
+	require.NoError(t, err)
+
+	par, err := u.MakeTransferInputData(privKey0, nil, base.NewLedgerTime(slot, 0))
+	require.NoError(t, err)
+	par.WithAmount(2000).
+		WithTargetLock(addr1).
+		WithConstraintBinary(constr)
+	txBytes, err := txbuilder.MakeTransferTransaction(par)
+	require.NoError(t, err)
+
+	return u.AddTransaction(txBytes)
+}
+
+// addTransferTx adds transfer transaction of the amount in the slot
+func addTransferTx(t *testing.T, slot base.Slot, amount uint64) error {
+	u := utxodb.NewUTXODB(genesisPrivateKey, true)
+	privKey0, _, addr0 := u.GenerateAddress(0)
+	err := u.TokensFromFaucet(addr0, 10000)
+	require.NoError(t, err)
+	_, _, addr1 := u.GenerateAddress(1)
+
+	par, err := u.MakeTransferInputData(privKey0, nil, base.NewLedgerTime(slot, 0))
+	require.NoError(t, err)
+	par.WithAmount(amount).
+		WithTargetLock(addr1)
+	txBytes, err := txbuilder.MakeTransferTransaction(par)
+	if err != nil {
+		return err
+	}
+	return u.AddTransaction(txBytes)
+}
+
+func TestActivation(t *testing.T) {
+	require.EqualValues(t, 0, ledger.L().VersionAt(activationSlotV1-1))
+	require.EqualValues(t, 1, ledger.L().VersionAt(activationSlotV1))
+	require.EqualValues(t, 2, ledger.L().VersionAt(activationSlotV2))
+	require.EqualValues(t, 3, ledger.L().VersionAt(activationSlotV3))
+	// only active versions are hashed
+	require.EqualValues(t, [32]byte{}, ledger.L().UpgradesHashAt(activationSlotV1-1))
+	require.NotEqualValues(t, [32]byte{}, ledger.L().UpgradesHashAt(activationSlotV1))
+	require.EqualValues(t, ledger.L().UpgradesHashAt(activationSlotV1), ledger.L().UpgradesHashAt(activationSlotV2-1))
+	require.NotEqualValues(t, ledger.L().UpgradesHashAt(activationSlotV1), ledger.L().UpgradesHashAt(activationSlotV2))
+
+	t.Run("new function", func(t *testing.T) {
+		const src = "upgradeTestConcat(1,2)"
+		err := addTxWithConstraint(t, activationSlotV1-1, src)
+		require.Error(t, err)
+		t.Logf("expected error: %v", err)
+
+		require.NoError(t, addTxWithConstraint(t, activationSlotV1, src))
+		require.NoError(t, addTxWithConstraint(t, activationSlotV1+1, src))
+	})
+	t.Run("redefined function", func(t *testing.T) {
+		// version 1 definition
+		const srcV1 = "equal(upgradeTestConcat(1,2), 0x0102)"
+		require.NoError(t, addTxWithConstraint(t, activationSlotV2-1, srcV1))
+		err := addTxWithConstraint(t, activationSlotV2, srcV1)
+		require.Error(t, err)
+		t.Logf("expected error: %v", err)
+
+		// version 2 definition
+		const srcV2 = "equal(upgradeTestConcat(1,2), 0x0201)"
+		err = addTxWithConstraint(t, activationSlotV2-1, srcV2)
+		require.Error(t, err)
+		t.Logf("expected error: %v", err)
+		require.NoError(t, addTxWithConstraint(t, activationSlotV2, srcV2))
+	})
+	t.Run("redefined storage deposit", func(t *testing.T) {
+		addr := ledger.AddressED25519Random()
+		mkOut := func(amount uint64) *ledger.Output {
+			return ledger.NewOutput(func(o *ledger.OutputBuilder) {
+				o.WithAmount(amount).WithLock(addr)
+			})
+		}
+		// amount between the storage deposits of version 2 and version 3
+		amount := mkOut(1000).MinimumStorageDeposit(0, activationSlotV3-1) * 3 / 2
+		out := mkOut(amount)
+		require.True(t, out.EnoughAmountForStorageDeposit(activationSlotV3-1) == nil)
+		require.True(t, out.EnoughAmountForStorageDeposit(activationSlotV3) != nil)
+
+		require.NoError(t, addTransferTx(t, activationSlotV3-1, amount))
+		err := addTransferTx(t, activationSlotV3, amount)
+		require.Error(t, err)
+		t.Logf("expected error: %v", err)
+		require.NoError(t, addTransferTx(t, activationSlotV3, 2*amount))
+	})
+}
+
+func TestCommittedInGenesis(t *testing.T) {
+	store := common.NewInMemoryKVStore()
+	multistate.InitStateStoreWithGlobalLedgerIdentity(store)
+
+	upgrades, err := multistate.FetchLibraryUpgrades(store)
+	require.NoError(t, err)
+	require.NotNil(t, upgrades)
+	require.EqualValues(t, ledger.L().Upgrades().YAML(), upgrades.YAML())
+}
@@ -182,7 +182,7 @@ func (ctx *TxContext) _validateOutputs(consumedBranch bool, failFast bool, spool
 			lastErr = fmt.Errorf("%w :\n%s", err, o.ToString("   "))
 			return !failFast
 		}
-		minDeposit := o.MinimumStorageDeposit(extraDepositWeight)
+		minDeposit := o.MinimumStorageDeposit(extraDepositWeight, ctx.txid.Slot())
 		amount := o.Amount()
 		if amount < minDeposit {
 			lastErr = fmt.Errorf("not enough storage deposit in output %s. Minimum %d, got %d",
@@ -369,7 +369,8 @@ func (ctx *TxContext) evalConstraint(constr []byte, path tuples.TreePath, spool
 	if constr[0] == 0 {
 		return nil, "", fmt.Errorf("binary code cannot begin with 0-byte")
 	}
-	ret, err = ledger.L().EvalFromBytecodeWithSlicePool(evalCtx, spool, constr)
+	// constraint is evaluated with the library version active at the slot of the transaction
+	ret, err = ledger.L().LibraryAt(ctx.txid.Slot()).EvalFromBytecodeWithSlicePool(evalCtx, spool, constr)
 
 	if evalCtx.Trace() {
 		if err != nil {
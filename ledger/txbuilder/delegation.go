@@ -0,0 +1,127 @@
+package txbuilder
+
+import (
+	"crypto/ed25519"
+
+	"github.com/lunfardo314/proxima/ledger"
+	"github.com/lunfardo314/proxima/ledger/base"
+	"github.com/lunfardo314/proxima/ledger/transaction"
+	"github.com/lunfardo314/proxima/util"
+)
+
+type DelegationTransitionParams struct {
+	// transaction timestamp. Not adjusted. Must be consistent with the delegation pace
+	Timestamp base.LedgerTime
+	// delegation chain output
+	ChainIn *ledger.OutputWithChainID
+	// private key of the delegation owner
+	PrivateKey ed25519.PrivateKey
+	// new delegation target. If nil, the target remains the same
+	NewTarget ledger.Accountable
+	// amount withdrawn from the delegation to the owner's address. 0 means nothing is withdrawn
+	WithdrawAmount uint64
+	// tag-along sequencer and fee amount. The fee is paid from the delegated amount
+	TagAlongSeqID base.ChainID
+	TagAlongFee   uint64 // 0 means no fee output will be produced
+}
+
+// MakeDelegationTransitionTransaction makes owner's transition of the delegation chain.
+// It continues the chain with the inflation, optionally withdraws part of the funds to the owner and/or
+// changes the delegation target. In the latter case the delegation lock is re-created with the new start time and amount
+func MakeDelegationTransitionTransaction(par DelegationTransitionParams) (*transaction.Transaction, error) {
+	errP := util.MakeErrFuncForPrefix("MakeDelegationTransitionTransaction")
+
+	dLock := par.ChainIn.Output.DelegationLock()
+	if dLock == nil {
+		return nil, errP("not a delegation output: %s", par.ChainIn.ID.StringShort())
+	}
+	owner := ledger.AddressED25519FromPrivateKey(par.PrivateKey)
+	if !ledger.EqualAccountables(owner, dLock.OwnerLock) {
+		return nil, errP("private key does not correspond to the owner of the delegation %s", par.ChainIn.ChainID.StringShort())
+	}
+	if par.Timestamp.IsSlotBoundary() {
+		return nil, errP("timestamp is on slot boundary")
+	}
+	if !ledger.ValidDelegationPace(par.ChainIn.Timestamp(), par.Timestamp) {
+		return nil, errP("timestamp %s is inconsistent with delegation pace after %s",
+			par.Timestamp.String(), par.ChainIn.Timestamp().String())
+	}
+	chainInConstraint, chainInConstraintIdx := par.ChainIn.Output.ChainConstraint()
+	if chainInConstraintIdx == 0xff {
+		return nil, errP("not a chain output: %s", par.ChainIn.ID.StringShort())
+	}
+
+	inflationAmount := ledger.L().CalcChainInflationAmount(par.ChainIn.Timestamp(), par.Timestamp, par.ChainIn.Output.Amount())
+	available := par.ChainIn.Output.Amount() + inflationAmount
+	if available < par.WithdrawAmount+par.TagAlongFee+ledger.MinimumDelegationAmount(par.Timestamp.Slot) {
+		return nil, errP("not enough tokens on the delegation: after withdrawal of %s and fee %s less than minimum %s would remain",
+			util.Th(par.WithdrawAmount), util.Th(par.TagAlongFee), util.Th(ledger.MinimumDelegationAmount(par.Timestamp.Slot)))
+	}
+	chainOutAmount := available - par.WithdrawAmount - par.TagAlongFee
+
+	chainID := chainInConstraint.ID
+	if chainInConstraint.IsOrigin() {
+		chainID = base.MakeOriginChainID(par.ChainIn.ID)
+	}
+
+	lockOut := dLock
+	if par.NewTarget != nil {
+		lockOut = ledger.NewDelegationLock(dLock.OwnerLock, par.NewTarget, ledger.ConstraintIndexFirstOptionalConstraint, par.Timestamp, chainOutAmount)
+	}
+
+	txb := New()
+	txb.TransactionData.Timestamp = par.Timestamp
+	chainPredIdx, err := txb.ConsumeOutput(par.ChainIn.Output, par.ChainIn.ID)
+	if err != nil {
+		return nil, errP(err)
+	}
+	txb.PutSignatureUnlock(chainPredIdx)
+
+	var chainOutConstraintIdx byte
+	chainOut := ledger.NewOutput(func(o *ledger.OutputBuilder) {
+		o.PutAmount(chainOutAmount)
+		o.PutLock(lockOut)
+		chainOutConstraintIdx = o.MustPushConstraint(ledger.NewChainConstraint(chainID, chainPredIdx, chainInConstraintIdx, 0).Bytes())
+		if inflationAmount > 0 {
+			o.MustPushConstraint((&ledger.InflationConstraint{
+				InflationAmount:      inflationAmount,
+				ChainConstraintIndex: chainOutConstraintIdx,
+			}).Bytes())
+		}
+	})
+	if chainOutConstraintIdx != lockOut.ChainConstraintIndex {
+		return nil, errP("chain constraint index %d is inconsistent with the delegation lock", chainOutConstraintIdx)
+	}
+	chainOutIndex, err := txb.ProduceOutput(chainOut)
+	if err != nil {
+		return nil, errP(err)
+	}
+	txb.PutUnlockParams(chainPredIdx, chainInConstraintIdx, ledger.NewChainUnlockParams(chainOutIndex, chainOutConstraintIdx, 0))
+
+	if par.WithdrawAmount > 0 {
+		withdrawOut := ledger.NewOutput(func(o *ledger.OutputBuilder) {
+			o.WithAmount(par.WithdrawAmount).
+				WithLock(owner)
+		})
+		if err = withdrawOut.EnoughAmountForStorageDeposit(par.Timestamp.Slot); err != nil {
+			return nil, errP("withdraw amount %s: %v", util.Th(par.WithdrawAmount), err)
+		}
+		if _, err = txb.ProduceOutput(withdrawOut); err != nil {
+			return nil, errP(err)
+		}
+	}
+	if par.TagAlongFee > 0 {
+		tagAlongFeeOut := ledger.NewOutput(func(o *ledger.OutputBuilder) {
+			o.WithAmount(par.TagAlongFee).
+				WithLock(ledger.ChainLockFromChainID(par.TagAlongSeqID))
+		})
+		if _, err = txb.ProduceOutput(tagAlongFeeOut); err != nil {
+			return nil, errP(err)
+		}
+	}
+
+	txb.TransactionData.InputCommitment = ledger.HashOutputs(txb.ConsumedOutputs...)
+	txb.SignED25519(par.PrivateKey)
+
+	return txb.Transaction()
+}
@@ -40,16 +40,7 @@ func MakeDistributionTransaction(stateStore multistate.StateStore, originPrivate
 			return nil, err
 		}
 	}
-	genesisDistributionOutputs := make([]*ledger.Output, len(genesisDistribution))
-	for i := range genesisDistribution {
-		genesisDistributionOutputs[i] = ledger.NewOutput(func(o *ledger.OutputBuilder) {
-			o.WithAmount(genesisDistribution[i].Balance).
-				WithLock(genesisDistribution[i].Lock)
-			if genesisDistribution[i].ChainOrigin {
-				o.MustPushConstraint(ledger.NewChainOrigin().Bytes())
-			}
-		})
-	}
+	genesisDistributionOutputs := MakeDistributionOutputs(genesisDistribution)
 
 	rdr, err := multistate.NewSugaredReadableState(stateStore, genesisRoot)
 	if err != nil {
@@ -84,6 +75,22 @@ func MakeDistributionTransaction(stateStore multistate.StateStore, originPrivate
 	return txBytes, nil
 }
 
+// MakeDistributionOutputs creates outputs of the initial distribution transaction, one for each item of the distribution list.
+// In the distribution transaction they are produced in the same order after the chain and stem outputs
+func MakeDistributionOutputs(genesisDistribution []ledger.LockBalance) []*ledger.Output {
+	ret := make([]*ledger.Output, len(genesisDistribution))
+	for i := range genesisDistribution {
+		ret[i] = ledger.NewOutput(func(o *ledger.OutputBuilder) {
+			o.WithAmount(genesisDistribution[i].Balance).
+				WithLock(genesisDistribution[i].Lock)
+			if genesisDistribution[i].ChainOrigin {
+				o.MustPushConstraint(ledger.NewChainOrigin().Bytes())
+			}
+		})
+	}
+	return ret
+}
+
 // DistributeInitialSupply updates genesis state and branch records according to initial supply distribution parameters by
 // adding initial distribution transaction.
 // Distribution transaction is a branch transaction in the slot next after the genesis.
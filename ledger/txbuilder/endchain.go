@@ -24,6 +24,7 @@ type EndChainParams struct {
 func MakeEndChainTransaction(par EndChainParams) (*transaction.Transaction, error) {
 	_, predecessorConstraintIndex := par.ChainIn.Output.ChainConstraint()
 	txb := New()
+	txb.TransactionData.Timestamp = par.Timestamp
 
 	consumedIndex, err := txb.ConsumeOutput(par.ChainIn.Output, par.ChainIn.ID)
 	util.AssertNoError(err)
@@ -51,7 +52,6 @@ func MakeEndChainTransaction(par EndChainParams) (*transaction.Transaction, erro
 	txb.PutSignatureUnlock(consumedIndex)
 
 	// finalize the transaction
-	txb.TransactionData.Timestamp = par.Timestamp
 	txb.TransactionData.InputCommitment = ledger.HashOutputs(txb.ConsumedOutputs...)
 	txb.SignED25519(par.PrivateKey)
 
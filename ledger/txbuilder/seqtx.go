@@ -76,6 +76,7 @@ func MakeSequencerTransactionWithInputLoader(par MakeSequencerTransactionParams)
 	}
 
 	txb := New()
+	txb.TransactionData.Timestamp = par.Timestamp
 
 	// calculate delegation outputs. Offset = 1 because inputs are consumed starting from index 1
 	delegationTransitions, delegationTotalIn, delegationTotalOut, delegationMargin, err :=
@@ -283,7 +284,6 @@ func MakeSequencerTransactionWithInputLoader(par MakeSequencerTransactionParams)
 	}
 	txb.PushEndorsements(par.Endorsements...)
 	txb.PutExplicitBaseline(par.ExplicitBaseline)
-	txb.TransactionData.Timestamp = par.Timestamp
 	txb.TransactionData.SequencerOutputIndex = chainOutIndex
 	txb.TransactionData.StemOutputIndex = stemOutputIndex
 	txb.TransactionData.InputCommitment = ledger.HashOutputs(txb.ConsumedOutputs...)
@@ -144,7 +144,7 @@ func (txb *TransactionBuilder) PutExplicitBaseline(txid *base.TransactionID) {
 }
 
 func (txb *TransactionBuilder) ProduceOutput(o *ledger.Output) (byte, error) {
-	if err := o.EnoughAmountForStorageDeposit(); err != nil {
+	if err := o.EnoughAmountForStorageDeposit(txb.slot()); err != nil {
 		return 0, err
 	}
 	o.MustValidOutput()
@@ -155,6 +155,15 @@ func (txb *TransactionBuilder) ProduceOutput(o *ledger.Output) (byte, error) {
 	return byte(len(txb.TransactionData.Outputs) - 1), nil
 }
 
+// slot of the transaction, which defines the library version for the storage deposit.
+// Current slot if the timestamp is not set yet
+func (txb *TransactionBuilder) slot() base.Slot {
+	if txb.TransactionData.Timestamp == base.NilLedgerTime {
+		return ledger.TimeNow().Slot
+	}
+	return txb.TransactionData.Timestamp.Slot
+}
+
 func (txb *TransactionBuilder) ProduceOutputs(outs ...*ledger.Output) (uint64, error) {
 	total := uint64(0)
 	for _, o := range outs {
@@ -419,7 +428,11 @@ func (t *TransferData) TotalAdjustedAmount() uint64 {
 		}
 	})
 
-	minimumDeposit := outTentative.MinimumStorageDeposit(0)
+	ts := t.Timestamp
+	if ts == base.NilLedgerTime {
+		ts = ledger.TimeNow()
+	}
+	minimumDeposit := outTentative.MinimumStorageDeposit(0, ts.Slot)
 	if t.Amount < minimumDeposit {
 		return minimumDeposit
 	}
@@ -504,6 +517,7 @@ func MakeSimpleTransferTransactionWithRemainder(par *TransferData, disableEndors
 
 	adjustedTs := base.MaximumTime(inputTs, par.Timestamp).
 		AddTicks(ledger.TransactionPace())
+	txb.TransactionData.Timestamp = adjustedTs
 
 	util.Assertf(base.ValidTime(adjustedTs), "ledger.ValidTime(adjustedTs): ts bytes 0x%s", adjustedTs.Hex)
 
@@ -582,7 +596,6 @@ func MakeSimpleTransferTransactionWithRemainder(par *TransferData, disableEndors
 	for _, un := range par.UnlockData {
 		txb.PutUnlockParams(un.OutputIndex, un.ConstraintIndex, un.Data)
 	}
-	txb.TransactionData.Timestamp = adjustedTs
 	txb.TransactionData.Endorsements = par.Endorsements
 	txb.TransactionData.InputCommitment = ledger.HashOutputs(txb.ConsumedOutputs...)
 	txb.SignED25519(par.SenderPrivateKey)
@@ -652,6 +665,7 @@ func MakeChainSuccessorTransaction(par *MakeChainSuccTransactionParams) ([]byte,
 	}
 
 	txb := New()
+	txb.TransactionData.Timestamp = par.Timestamp
 
 	// consume predecessor
 	chainPredIdx, err := txb.ConsumeOutput(par.ChainInput.Output, par.ChainInput.ID)
@@ -700,7 +714,6 @@ func MakeChainSuccessorTransaction(par *MakeChainSuccTransactionParams) ([]byte,
 		}
 	}
 
-	txb.TransactionData.Timestamp = par.Timestamp
 	txb.TransactionData.InputCommitment = ledger.HashOutputs(txb.ConsumedOutputs...)
 	txb.SignED25519(par.PrivateKey)
 
@@ -750,6 +763,7 @@ func MakeChainTransferTransaction(par *TransferData, disableEndorsementChecking
 	util.Assertf(availableTokens == checkAmount+par.ChainOutput.Output.Amount(), "availableTokens == checkAmount")
 	adjustedTs := base.MaximumTime(inputTs, par.ChainOutput.Timestamp()).
 		AddTicks(ledger.TransactionPace())
+	txb.TransactionData.Timestamp = adjustedTs
 
 	for i := range par.Endorsements {
 		if len(disableEndorsementChecking) == 0 || !disableEndorsementChecking[0] {
@@ -805,7 +819,6 @@ func MakeChainTransferTransaction(par *TransferData, disableEndorsementChecking
 		util.AssertNoError(err)
 	}
 
-	txb.TransactionData.Timestamp = adjustedTs
 	txb.TransactionData.InputCommitment = ledger.HashOutputs(txb.ConsumedOutputs...)
 	txb.SignED25519(par.SenderPrivateKey)
 
@@ -0,0 +1,321 @@
+package ledger
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/lunfardo314/easyfl"
+	"github.com/lunfardo314/proxima/ledger/base"
+	"github.com/lunfardo314/proxima/util/lines"
+	"golang.org/x/crypto/blake2b"
+	"gopkg.in/yaml.v2"
+)
+
+// Scheduled upgrades of the ledger library (protocol versions).
+// The library defined by the ledger identity data is version 0. Each next version adds new functions
+// to the library of the previous version or redefines existing extended functions, and becomes active at the given slot.
+// New functions get new function codes. A redefined function keeps its function code and number of arguments,
+// so constraint bytecodes which call it are interpreted with the new definition from the activation slot
+// (e.g. a fix of the storage deposit). Embedded functions can't be redefined (see also comments in def.go).
+// Transaction is validated with the library version active at the slot of the transaction.
+// The schedule is committed in the genesis ledger state, so all nodes of the network run with the same library versions.
+// In addition, nodes exchange hash of the active versions when connecting (see UpgradesHashAt)
+
+type (
+	// LibraryUpgrades is the schedule of library versions
+	LibraryUpgrades struct {
+		Upgrades []LibraryUpgrade `yaml:"upgrades"`
+	}
+
+	// LibraryUpgrade is the upgrade to the library of the previous version
+	LibraryUpgrade struct {
+		Version        int    `yaml:"version"`
+		ActivationSlot uint32 `yaml:"activation_slot"`
+		Description    string `yaml:"description,omitempty"`
+		// hex-encoded hash of the library after the upgrade. Empty if upgrade is not compiled
+		Hash      string                          `yaml:"hash,omitempty"`
+		Functions []easyfl.FuncDescriptorYAMLAble `yaml:"functions"`
+	}
+
+	// libraryVersion is the compiled library of the particular version
+	libraryVersion struct {
+		version        int
+		activationSlot base.Slot
+		lib            *easyfl.Library
+		hash           [32]byte
+	}
+)
+
+const LibraryUpgradesYAMLHeader = "# Proxima ledger library upgrades"
+
+func ParseLibraryUpgradesYAML(data []byte) (*LibraryUpgrades, error) {
+	ret := &LibraryUpgrades{}
+	if err := yaml.UnmarshalStrict(data, ret); err != nil {
+		return nil, fmt.Errorf("ParseLibraryUpgradesYAML: %w", err)
+	}
+	if err := ret.Validate(); err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
+
+func (u *LibraryUpgrades) YAML() []byte {
+	data, err := yaml.Marshal(u)
+	if err != nil {
+		panic(err)
+	}
+	return append([]byte(LibraryUpgradesYAMLHeader+"\n"), data...)
+}
+
+// Validate checks consistency of the schedule: versions are 1, 2, 3.. and activation slots are increasing
+func (u *LibraryUpgrades) Validate() error {
+	prevSlot := uint32(0)
+	for i := range u.Upgrades {
+		up := &u.Upgrades[i]
+		if up.Version != i+1 {
+			return fmt.Errorf("library upgrade #%d: expected version %d, got %d", i, i+1, up.Version)
+		}
+		if up.ActivationSlot <= prevSlot {
+			return fmt.Errorf("library upgrade version %d: activation slot %d must be after activation slot of the previous version %d",
+				up.Version, up.ActivationSlot, prevSlot)
+		}
+		prevSlot = up.ActivationSlot
+		if len(up.Functions) == 0 {
+			return fmt.Errorf("library upgrade version %d: no functions", up.Version)
+		}
+		if up.Hash != "" {
+			if h, err := hex.DecodeString(up.Hash); err != nil || len(h) != 32 {
+				return fmt.Errorf("library upgrade version %d: wrong hash", up.Version)
+			}
+		}
+	}
+	return nil
+}
+
+// CheckCompiled returns error if some of the upgrades has no hash
+func (u *LibraryUpgrades) CheckCompiled() error {
+	for i := range u.Upgrades {
+		if u.Upgrades[i].Hash == "" {
+			return fmt.Errorf("library upgrade version %d is not compiled", u.Upgrades[i].Version)
+		}
+	}
+	return nil
+}
+
+// CheckExtends checks if the schedule contains all versions of the previous schedule
+func (u *LibraryUpgrades) CheckExtends(prev *LibraryUpgrades) error {
+	if prev == nil {
+		return nil
+	}
+	for i := range prev.Upgrades {
+		p := &prev.Upgrades[i]
+		if i >= len(u.Upgrades) {
+			return fmt.Errorf("library version %d with activation slot %d is not known", p.Version, p.ActivationSlot)
+		}
+		if u.Upgrades[i].ActivationSlot != p.ActivationSlot || u.Upgrades[i].Hash != p.Hash {
+			return fmt.Errorf("library version %d: conflicting definitions (activation slot %d, hash %s) and (activation slot %d, hash %s)",
+				p.Version, u.Upgrades[i].ActivationSlot, u.Upgrades[i].Hash, p.ActivationSlot, p.Hash)
+		}
+	}
+	return nil
+}
+
+func (u *LibraryUpgrades) Lines(prefix ...string) *lines.Lines {
+	ret := lines.New(prefix...)
+	for i := range u.Upgrades {
+		up := &u.Upgrades[i]
+		ret.Add("version %d: activation slot %d, %d functions, hash: %s, description: '%s'",
+			up.Version, up.ActivationSlot, len(up.Functions), up.Hash, up.Description)
+	}
+	return ret
+}
+
+// CompileLibraryUpgrades creates library of each version on top of the library defined by the ledger identity data.
+// Returns libraries of all versions, starting from version 0. Checks hashes, if provided in the upgrades
+func CompileLibraryUpgrades(identityData []byte, upgrades *LibraryUpgrades) ([]*easyfl.Library, error) {
+	var nUpgrades int
+	if upgrades != nil {
+		if err := upgrades.Validate(); err != nil {
+			return nil, err
+		}
+		nUpgrades = len(upgrades.Upgrades)
+	}
+	ret := make([]*easyfl.Library, nUpgrades+1)
+	lib, _, err := ParseLedgerIdYAML(identityData, base.GetEmbeddedFunctionResolver)
+	if err != nil {
+		return nil, err
+	}
+	ret[0] = lib
+	if nUpgrades == 0 {
+		return ret, nil
+	}
+	// function definitions of the current version in the order of function codes
+	current, err := easyfl.ReadLibraryFromYAML(identityData)
+	if err != nil {
+		return nil, err
+	}
+	for v := 1; v <= nUpgrades; v++ {
+		up := &upgrades.Upgrades[v-1]
+		// each version is created from scratch, so that libraries of different versions do not share function definitions
+		if current, err = applyLibraryUpgrade(current, up); err != nil {
+			return nil, fmt.Errorf("library upgrade version %d: %w", up.Version, err)
+		}
+		lib = easyfl.NewLibrary()
+		if err = lib.Upgrade(current, base.GetEmbeddedFunctionResolver(lib)); err != nil {
+			return nil, fmt.Errorf("library upgrade version %d: %w", up.Version, err)
+		}
+		if err = checkRedefinitions(ret[v-1], lib, up); err != nil {
+			return nil, fmt.Errorf("library upgrade version %d: %w", up.Version, err)
+		}
+		h := lib.LibraryHash()
+		if up.Hash != "" && up.Hash != hex.EncodeToString(h[:]) {
+			return nil, fmt.Errorf("library upgrade version %d: provided hash %s is not equal to the hash of compiled library %s",
+				up.Version, up.Hash, hex.EncodeToString(h[:]))
+		}
+		ret[v] = lib
+	}
+	return ret, nil
+}
+
+// applyLibraryUpgrade returns function definitions of the next version. A function with a new name is added at the end.
+// A function with the name which already exists in the library replaces the source of the existing extended function
+// in place, so it keeps its function code and constraints which call it are interpreted with the new definition.
+// Embedded functions can't be redefined
+func applyLibraryUpgrade(prev *easyfl.LibraryFromYAML, up *LibraryUpgrade) (*easyfl.LibraryFromYAML, error) {
+	ret := &easyfl.LibraryFromYAML{
+		Functions: make([]easyfl.FuncDescriptorYAMLAble, 0, len(prev.Functions)+len(up.Functions)),
+	}
+	idx := make(map[string]int)
+	for _, f := range prev.Functions {
+		// compiled part is ignored and re-created
+		f.FunCode = 0
+		f.Bytecode = ""
+		idx[f.Sym] = len(ret.Functions)
+		ret.Functions = append(ret.Functions, f)
+	}
+	for _, f := range up.Functions {
+		i, redefined := idx[f.Sym]
+		if !redefined {
+			idx[f.Sym] = len(ret.Functions)
+			ret.Functions = append(ret.Functions, f)
+			continue
+		}
+		existing := &ret.Functions[i]
+		if existing.Embedded || f.Embedded {
+			return nil, fmt.Errorf("embedded function '%s' can't be redefined", f.Sym)
+		}
+		if f.NumArgs != existing.NumArgs {
+			return nil, fmt.Errorf("redefinition of '%s' changes number of arguments from %d to %d", f.Sym, existing.NumArgs, f.NumArgs)
+		}
+		existing.Source = f.Source
+		if f.Description != "" {
+			existing.Description = f.Description
+		}
+	}
+	return ret, nil
+}
+
+// checkRedefinitions checks if functions redefined by the upgrade keep function codes and number of arguments,
+// i.e. if call bytecodes of the previous version call the new definition
+func checkRedefinitions(prevLib, lib *easyfl.Library, up *LibraryUpgrade) error {
+	for _, f := range up.Functions {
+		prefixPrev, err := prevLib.FunctionCallPrefixByName(f.Sym, byte(f.NumArgs))
+		if err != nil {
+			// not a redefinition
+			continue
+		}
+		prefix, err := lib.FunctionCallPrefixByName(f.Sym, byte(f.NumArgs))
+		if err != nil {
+			return fmt.Errorf("redefinition of '%s': %w", f.Sym, err)
+		}
+		if !bytes.Equal(prefixPrev, prefix) {
+			return fmt.Errorf("redefinition of '%s' changes its call bytecode", f.Sym)
+		}
+	}
+	return nil
+}
+
+// CompileLibraryUpgradesYAML compiles upgrades and returns the schedule with hashes of all versions
+func CompileLibraryUpgradesYAML(identityData []byte, upgradesYAML []byte) (*LibraryUpgrades, error) {
+	upgrades, err := ParseLibraryUpgradesYAML(upgradesYAML)
+	if err != nil {
+		return nil, err
+	}
+	for i := range upgrades.Upgrades {
+		upgrades.Upgrades[i].Hash = ""
+	}
+	libs, err := CompileLibraryUpgrades(identityData, upgrades)
+	if err != nil {
+		return nil, err
+	}
+	for i := range upgrades.Upgrades {
+		h := libs[i+1].LibraryHash()
+		upgrades.Upgrades[i].Hash = hex.EncodeToString(h[:])
+	}
+	return upgrades, nil
+}
+
+// LibraryAt returns the library of the version active at the slot
+func (lib *Library) LibraryAt(slot base.Slot) *easyfl.Library {
+	for i := len(lib.versions) - 1; i >= 0; i-- {
+		if slot >= lib.versions[i].activationSlot {
+			return lib.versions[i].lib
+		}
+	}
+	return lib.Library
+}
+
+// VersionAt returns the version of the library active at the slot
+func (lib *Library) VersionAt(slot base.Slot) int {
+	for i := len(lib.versions) - 1; i >= 0; i-- {
+		if slot >= lib.versions[i].activationSlot {
+			return lib.versions[i].version
+		}
+	}
+	return 0
+}
+
+// LedgerIDHash is the hash of the library defined by the ledger identity data, i.e. of the version 0.
+// It is the same on all nodes of the network, independently of the known upgrades
+func (lib *Library) LedgerIDHash() [32]byte {
+	if len(lib.versions) > 0 {
+		return lib.versions[0].hash
+	}
+	return lib.LibraryHash()
+}
+
+// UpgradesHashAt is the hash of library upgrades active at the slot: version, activation slot and library hash of each version.
+// It is all-0 if there are no active upgrades. Nodes with different hashes of active upgrades would fork, so they can't be peers
+func (lib *Library) UpgradesHashAt(slot base.Slot) (ret [32]byte) {
+	if lib.VersionAt(slot) == 0 {
+		return
+	}
+	var buf bytes.Buffer
+	var u32 [4]byte
+	for _, v := range lib.versions[1 : lib.VersionAt(slot)+1] {
+		binary.BigEndian.PutUint32(u32[:], uint32(v.version))
+		buf.Write(u32[:])
+		binary.BigEndian.PutUint32(u32[:], uint32(v.activationSlot))
+		buf.Write(u32[:])
+		buf.Write(v.hash[:])
+	}
+	return blake2b.Sum256(buf.Bytes())
+}
+
+// Upgrades returns the schedule of library upgrades or nil if there are no upgrades
+func (lib *Library) Upgrades() *LibraryUpgrades {
+	return lib.upgrades
+}
+
+// LibraryVersionsLines lists all library versions with activation slots and hashes
+func (lib *Library) LibraryVersionsLines(prefix ...string) *lines.Lines {
+	ret := lines.New(prefix...)
+	h := lib.LedgerIDHash()
+	ret.Add("version 0 (ledger ID): hash: %s", hex.EncodeToString(h[:]))
+	for _, v := range lib.versions[min(1, len(lib.versions)):] {
+		ret.Add("version %d: activation slot %d, hash: %s", v.version, v.activationSlot, hex.EncodeToString(v.hash[:]))
+	}
+	return ret
+}
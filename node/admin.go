@@ -0,0 +1,116 @@
+package node
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/lunfardo314/proxima/api/admin"
+	"github.com/lunfardo314/proxima/ledger"
+	"github.com/lunfardo314/proxima/ledger/base"
+	"github.com/lunfardo314/proxima/util"
+	"github.com/spf13/viper"
+)
+
+const defaultAdminAPIAddr = "127.0.0.1:8002"
+
+func (p *ProximaNode) startAdminAPIServer() {
+	if !viper.GetBool("admin_api.enable") {
+		// default is disabled admin API
+		p.Log().Infof("admin API server is disabled")
+		return
+	}
+	cfg := &admin.Config{
+		Addr:         viper.GetString("admin_api.listen"),
+		Token:        viper.GetString("admin_api.token"),
+		CertFile:     viper.GetString("admin_api.tls.cert_file"),
+		KeyFile:      viper.GetString("admin_api.tls.key_file"),
+		ClientCAFile: viper.GetString("admin_api.tls.client_ca_file"),
+	}
+	if cfg.Addr == "" {
+		cfg.Addr = defaultAdminAPIAddr
+	}
+	if envToken := os.Getenv("PROXIMA_ADMIN_TOKEN"); envToken != "" {
+		cfg.Token = envToken
+	}
+	p.Log().Infof("starting admin API server on %s. Bearer token: %v, mTLS: %v", cfg.Addr, cfg.Token != "", cfg.ClientCAFile != "")
+
+	go func() {
+		if err := admin.Run(p.Ctx(), cfg, p); err != nil {
+			p.Log().Errorf("admin API server: %v", err)
+			return
+		}
+		p.Log().Infof("admin API server has been stopped")
+	}()
+}
+
+func (p *ProximaNode) StaticPeers() map[string]string {
+	return p.peers.StaticPeers()
+}
+
+func (p *ProximaNode) AddStaticPeer(name, addrString string) error {
+	return p.peers.AddStaticPeer(name, addrString)
+}
+
+func (p *ProximaNode) RemoveStaticPeer(id peer.ID) error {
+	return p.peers.RemoveStaticPeer(id)
+}
+
+func (p *ProximaNode) AddToBlacklist(id peer.ID, reason string) error {
+	return p.peers.AddToBlacklist(id, reason)
+}
+
+func (p *ProximaNode) RemoveFromBlacklist(id peer.ID) error {
+	return p.peers.RemoveFromBlacklist(id)
+}
+
+func (p *ProximaNode) TakeSnapshotNow() (string, error) {
+	return p.workflow.TakeSnapshotNow()
+}
+
+func (p *ProximaNode) SequencerStatus() (*base.ChainID, bool) {
+	if p.sequencer == nil {
+		return nil, false
+	}
+	return util.Ref(p.sequencer.SequencerID()), !p.sequencer.IsPaused()
+}
+
+// StartSequencer resumes the sequencer paused by StopSequencer. Sequencer must be configured
+func (p *ProximaNode) StartSequencer() error {
+	if p.sequencer == nil {
+		return fmt.Errorf("sequencer is not configured or disabled")
+	}
+	p.sequencer.Resume()
+	return nil
+}
+
+// StopSequencer pauses the sequencer. It stops producing milestones until started again
+func (p *ProximaNode) StopSequencer() error {
+	if p.sequencer == nil {
+		return fmt.Errorf("sequencer is not configured or disabled")
+	}
+	p.sequencer.Pause()
+	return nil
+}
+
+// SaveMemDAGGraph saves graph of the memDAG in the DOT format into the 'admin_api.graph_dir' directory
+func (p *ProximaNode) SaveMemDAGGraph() (string, error) {
+	dir := viper.GetString("admin_api.graph_dir")
+	if dir == "" {
+		dir = "."
+	}
+	if err := os.MkdirAll(dir, 0777); err != nil {
+		return "", err
+	}
+	fname := filepath.Join(dir, fmt.Sprintf("memdag_%d_%d", ledger.TimeNow().Slot, time.Now().Unix()))
+	err := util.CatchPanicOrError(func() error {
+		p.workflow.SaveGraph(fname)
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	return fname + ".gv", nil
+}
@@ -3,11 +3,13 @@ package node
 import (
 	"errors"
 	"fmt"
+	"time"
 
 	"github.com/libp2p/go-libp2p/core/peer"
 	"github.com/lunfardo314/proxima/api"
 	"github.com/lunfardo314/proxima/api/server"
 	"github.com/lunfardo314/proxima/api/streaming"
+	"github.com/lunfardo314/proxima/core/core_modules/delegation_history"
 	"github.com/lunfardo314/proxima/core/core_modules/tippool"
 	"github.com/lunfardo314/proxima/global"
 	"github.com/lunfardo314/proxima/ledger"
@@ -25,11 +27,17 @@ func (p *ProximaNode) startAPIServer() {
 		p.Log().Infof("API server is disabled")
 		return
 	}
-	port := viper.GetInt("api.port")
-	addr := fmt.Sprintf(":%d", port)
-	p.Log().Infof("starting API server on %s", addr)
+	cfg, err := readAPIServerConfig()
+	if err != nil {
+		p.Log().Fatalf("wrong API server configuration: %v", err)
+	}
+	if viper.GetBool("streaming.enable") || viper.GetBool("api.streaming_enable") {
+		cfg.StreamHandlers = streaming.Handlers(p, viper.GetInt("api.streaming_max_connections"))
+	}
+	p.Log().Infof("starting API server on %s. TLS: %v, API keys: %d, anonymous scopes: %v, rate limits: %v",
+		cfg.Addr, cfg.CertFile != "", len(cfg.Keys), cfg.AnonymousScopes, cfg.RateLimit != nil)
 
-	go server.Run(addr, p)
+	go server.Run(cfg, p)
 	go func() {
 		<-p.Ctx().Done()
 		p.stopAPIServer()
@@ -37,15 +45,94 @@ func (p *ProximaNode) startAPIServer() {
 
 }
 
-func (p *ProximaNode) stopAPIServer() {
-	// do we need to do something else here?
-	p.Log().Debugf("API server has been stopped")
+// readAPIServerConfig reads configuration of the API server from the 'api' section.
+// Without API keys, rate limits and CORS settings the API is open for everybody, as before
+func readAPIServerConfig() (*server.Config, error) {
+	ret := &server.Config{
+		Addr:     fmt.Sprintf(":%d", viper.GetInt("api.port")),
+		CertFile: viper.GetString("api.tls.cert_file"),
+		KeyFile:  viper.GetString("api.tls.key_file"),
+		CORS: server.CORSConfig{
+			AllowedOrigins: []string{"*"},
+			AllowedHeaders: viper.GetStringSlice("api.cors.allowed_headers"),
+			MaxAge:         time.Duration(viper.GetInt("api.cors.max_age_sec")) * time.Second,
+		},
+		AnonymousScopes: []string{server.ScopeRead, server.ScopeSubmit},
+	}
+	if (ret.CertFile == "") != (ret.KeyFile == "") {
+		return nil, fmt.Errorf("both 'api.tls.cert_file' and 'api.tls.key_file' must be specified")
+	}
+	if viper.IsSet("api.cors.allowed_origins") {
+		ret.CORS.AllowedOrigins = viper.GetStringSlice("api.cors.allowed_origins")
+	}
+	if viper.IsSet("api.anonymous_scopes") {
+		ret.AnonymousScopes = viper.GetStringSlice("api.anonymous_scopes")
+	}
+	if err := checkAPIScopes(ret.AnonymousScopes); err != nil {
+		return nil, fmt.Errorf("api.anonymous_scopes: %w", err)
+	}
+	for _, name := range util.KeysSorted(viper.GetStringMap("api.keys"), func(k1, k2 string) bool { return k1 < k2 }) {
+		prefix := "api.keys." + name
+		kc := server.KeyConfig{
+			Name:   name,
+			Key:    viper.GetString(prefix + ".key"),
+			Scopes: viper.GetStringSlice(prefix + ".scopes"),
+			Limit:  readAPILimit(prefix + ".rate_limit"),
+		}
+		if kc.Key == "" {
+			return nil, fmt.Errorf("%s: key is empty", prefix)
+		}
+		if err := checkAPIScopes(kc.Scopes); err != nil {
+			return nil, fmt.Errorf("%s: %w", prefix, err)
+		}
+		ret.Keys = append(ret.Keys, kc)
+	}
+	if !viper.GetBool("api.rate_limit.enable") {
+		return ret, nil
+	}
+	ret.RateLimit = &server.RateLimitConfig{
+		Endpoints:         make(map[string]server.EndpointLimits),
+		TrustForwardedFor: viper.GetBool("api.rate_limit.trust_forwarded_for"),
+	}
+	if lim := readAPILimit("api.rate_limit.per_ip"); lim != nil {
+		ret.RateLimit.PerIP = *lim
+	}
+	if lim := readAPILimit("api.rate_limit.per_key"); lim != nil {
+		ret.RateLimit.PerKey = *lim
+	}
+	for endpoint := range viper.GetStringMap("api.rate_limit.endpoints") {
+		prefix := "api.rate_limit.endpoints." + endpoint
+		ret.RateLimit.Endpoints[endpoint] = server.EndpointLimits{
+			PerIP:  readAPILimit(prefix + ".per_ip"),
+			PerKey: readAPILimit(prefix + ".per_key"),
+		}
+	}
+	return ret, nil
 }
 
-func (p *ProximaNode) startStreaming() {
-	if viper.GetBool("streaming.enable") || viper.GetBool("api.streaming_enable") {
-		streaming.Run(p)
+// readAPILimit returns nil if limit is not configured
+func readAPILimit(key string) *server.Limit {
+	if !viper.IsSet(key + ".rps") {
+		return nil
+	}
+	return &server.Limit{
+		RPS:   viper.GetFloat64(key + ".rps"),
+		Burst: viper.GetInt(key + ".burst"),
+	}
+}
+
+func checkAPIScopes(scopes []string) error {
+	for _, scope := range scopes {
+		if scope != server.ScopeRead && scope != server.ScopeSubmit {
+			return fmt.Errorf("wrong scope '%s'. Must be '%s' or '%s'", scope, server.ScopeRead, server.ScopeSubmit)
+		}
 	}
+	return nil
+}
+
+func (p *ProximaNode) stopAPIServer() {
+	// do we need to do something else here?
+	p.Log().Debugf("API server has been stopped")
 }
 
 // GetNodeInfo TODO not finished
@@ -61,6 +148,11 @@ func (p *ProximaNode) GetNodeInfo() *global.NodeInfo {
 		CommitHash:      global.CommitHash,
 		CommitTime:      global.CommitTime,
 	}
+	if p.clock != nil {
+		ret.ClockOffsetNs = p.clock.Offset().Nanoseconds()
+	}
+	ret.ClockCorrectionNs = ledger.ClockCorrection().Nanoseconds()
+	_, ret.ClockSkewTooBig = p.ClockSkew()
 	return ret
 }
 
@@ -84,6 +176,7 @@ func (p *ProximaNode) GetSyncInfo() *api.SyncInfo {
 		LrbSlot:        lrbSlot,
 		LedgerCoverage: cov,
 		PerSequencer:   make(map[string]api.SequencerSyncInfo),
+		Fork:           p.ForkInfo(),
 	}
 	if p.sequencer != nil {
 		seqInfo := p.sequencer.Info()
@@ -143,6 +236,10 @@ func (p *ProximaNode) GetKnownLatestMilestonesJSONAble() map[string]tippool.Late
 	return p.workflow.GetKnownLatestSequencerDataJSONAble()
 }
 
+func (p *ProximaNode) GetDelegationHistory(chainID base.ChainID) (*delegation_history.History, error) {
+	return p.workflow.GetDelegationHistory(chainID)
+}
+
 func (p *ProximaNode) OnTransaction(fun func(tx *transaction.Transaction) bool) {
 	p.workflow.OnTransaction(fun)
 }
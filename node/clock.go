@@ -0,0 +1,91 @@
+package node
+
+import (
+	"time"
+
+	"github.com/lunfardo314/proxima/ledger"
+	"github.com/lunfardo314/proxima/peering"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/spf13/viper"
+)
+
+// clock service estimates offset of the local clock from heartbeats of peers. Optionally, it corrects
+// the ledger time by the bounded amount. When the remaining skew exceeds the threshold, the sequencer
+// does not produce milestones
+
+type clockService struct {
+	*peering.ClockTracker
+	// metrics
+	offsetGauge     prometheus.Gauge
+	correctionGauge prometheus.Gauge
+}
+
+const (
+	clockSyncPeriod         = 2 * time.Second
+	defaultMaxClockSkew     = 3 * time.Second
+	defaultMaxCorrection    = time.Second
+	defaultMinPeersForClock = 2
+)
+
+func readClockConfig() peering.ClockConfig {
+	ret := peering.ClockConfig{
+		MaxSkew:           time.Duration(viper.GetInt("clock.max_skew_millis")) * time.Millisecond,
+		CorrectionEnabled: viper.GetBool("clock.correction.enable"),
+		MaxCorrection:     time.Duration(viper.GetInt("clock.correction.max_millis")) * time.Millisecond,
+		MinPeers:          viper.GetInt("clock.min_peers"),
+	}
+	if ret.MaxSkew <= 0 {
+		ret.MaxSkew = defaultMaxClockSkew
+	}
+	if ret.MaxCorrection <= 0 {
+		ret.MaxCorrection = defaultMaxCorrection
+	}
+	if ret.MinPeers <= 0 {
+		ret.MinPeers = defaultMinPeersForClock
+	}
+	return ret
+}
+
+func (p *ProximaNode) startClockService() {
+	cfg := readClockConfig()
+	p.clock = &clockService{ClockTracker: peering.NewClockTracker(cfg)}
+	p.clock.registerMetrics(p.MetricsRegistry())
+
+	p.Log().Infof("[clock] max skew: %v, correction enabled: %v, max correction: %v, min static peers: %d",
+		cfg.MaxSkew, cfg.CorrectionEnabled, cfg.MaxCorrection, cfg.MinPeers)
+
+	p.RepeatInBackground("clock_sync_loop", clockSyncPeriod, func() bool {
+		p.updateClockOffset()
+		return true
+	})
+}
+
+func (p *ProximaNode) updateClockOffset() {
+	all, static := p.peers.ClockOffset()
+	if correction, changed := p.clock.Update(all, static, ledger.ClockCorrection()); changed {
+		ledger.SetClockCorrection(correction)
+		p.clock.correctionGauge.Set(float64(correction.Nanoseconds()))
+	}
+	p.clock.offsetGauge.Set(float64(all.Offset.Nanoseconds()))
+}
+
+// ClockSkew returns estimated skew of the ledger time with respect to static peers, i.e. the offset of the local
+// clock minus correction. tooBig is true if it exceeds the configured maximum
+func (p *ProximaNode) ClockSkew() (skew time.Duration, tooBig bool) {
+	if p.clock == nil {
+		return 0, false
+	}
+	return p.clock.Skew(ledger.ClockCorrection())
+}
+
+func (c *clockService) registerMetrics(reg *prometheus.Registry) {
+	c.offsetGauge = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "proxima_clock_offset_ns",
+		Help: "estimated offset of the local clock with respect to clocks of peers, nanoseconds. Positive means ahead",
+	})
+	c.correctionGauge = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "proxima_clock_correction_ns",
+		Help: "correction added to the local clock when computing ledger time, nanoseconds",
+	})
+	reg.MustRegister(c.offsetGauge, c.correctionGauge)
+}
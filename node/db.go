@@ -2,9 +2,11 @@ package node
 
 import (
 	"encoding/hex"
+	"fmt"
 	"time"
 
 	"github.com/lunfardo314/proxima/global"
+	"github.com/lunfardo314/proxima/kvdb"
 	"github.com/lunfardo314/proxima/ledger"
 	"github.com/lunfardo314/proxima/ledger/multistate"
 	"github.com/lunfardo314/proxima/txstore"
@@ -16,19 +18,24 @@ import (
 func (p *ProximaNode) initMultiStateLedger() {
 	var err error
 	dbname := global.MultiStateDBName
-	bdb, err := badger_adaptor.OpenBadgerDB(dbname)
+	p.multiStateDB, err = kvdb.Open(dbname, viper.GetString("db.backend"))
 	if err != nil {
 		p.Log().Fatalf("can't open '%s': %v", dbname, err)
 	}
 	p.dbClosedWG.Add(1)
-	p.multiStateDB = badger_adaptor.New(bdb)
-	p.Log().Infof("opened multi-state DB '%s'", dbname)
+	p.Log().Infof("opened multi-state DB '%s', backend: '%s'", dbname, kvdb.Detect(dbname))
 
-	// initialize the ledger library singleton with the ledger ID data from DB
+	// initialize the ledger library singleton with the ledger ID data and library upgrades committed in the state
+	p.checkLibraryUpgrades()
 	multistate.InitLedgerFromStore(p.multiStateDB)
 	p.Log().Infof("ledger ID params:\n%s", ledger.L().ID.Lines("       ").String())
-	h := ledger.L().LibraryHash()
-	p.Log().Infof("ledger constraint library hash: %s", hex.EncodeToString(h[:]))
+	h := ledger.L().LedgerIDHash()
+	p.Log().Infof("ledger ID hash: %s", hex.EncodeToString(h[:]))
+	if ledger.L().Upgrades() != nil {
+		h = ledger.L().LibraryHash()
+		p.Log().Infof("ledger library versions:\n%s", ledger.L().LibraryVersionsLines("       ").String())
+		p.Log().Infof("latest ledger library hash: %s, active version: %d", hex.EncodeToString(h[:]), ledger.L().VersionAt(ledger.TimeNow().Slot))
+	}
 
 	p.snapshotBranchID = multistate.FetchSnapshotBranchID(p.multiStateDB)
 	p.Log().Infof("current slot: %d", ledger.TimeNow().Slot)
@@ -53,6 +60,33 @@ func (p *ProximaNode) initMultiStateLedger() {
 	}()
 }
 
+// checkLibraryUpgrades checks the schedule of ledger library upgrades committed in the genesis state.
+// The node refuses to run if it can't compile any of the scheduled library versions
+func (p *ProximaNode) checkLibraryUpgrades() {
+	upgrades, err := multistate.FetchLibraryUpgrades(p.multiStateDB)
+	if err != nil {
+		p.Log().Fatalf("can't parse ledger library upgrades committed in the state: %v", err)
+	}
+	if err = checkLibraryUpgrades(multistate.LedgerIdentityBytesFromStore(p.multiStateDB), upgrades); err != nil {
+		p.Log().Fatalf("ledger library upgrades: %v", err)
+	}
+}
+
+// checkLibraryUpgrades returns error if the node software can't compile any of the scheduled library versions
+// or if hash of the compiled version is different from the committed one
+func checkLibraryUpgrades(idData []byte, upgrades *ledger.LibraryUpgrades) error {
+	if upgrades == nil {
+		return nil
+	}
+	if err := upgrades.CheckCompiled(); err != nil {
+		return err
+	}
+	if _, err := ledger.CompileLibraryUpgrades(idData, upgrades); err != nil {
+		return fmt.Errorf("the node does not support ledger library upgrades committed in the state: %v\nUpgrade the node software", err)
+	}
+	return nil
+}
+
 func (p *ProximaNode) initTxStore() {
 	switch viper.GetString(global.ConfigKeyTxStoreType) {
 	case "dummy":
@@ -66,10 +100,14 @@ func (p *ProximaNode) initTxStore() {
 		// default option is predefined database name
 		dbname := global.TxStoreDBName
 		p.Log().Infof("transaction store database dbname is '%s'", dbname)
-		p.txStoreDB = badger_adaptor.New(badger_adaptor.MustCreateOrOpenBadgerDB(dbname))
+		txStoreDB, err := kvdb.Open(dbname, viper.GetString("db.backend"))
+		if err != nil {
+			p.Log().Fatalf("can't open '%s': %v", dbname, err)
+		}
+		p.txStoreDB = txStoreDB
 		p.dbClosedWG.Add(1)
 		p.txBytesStore = txstore.NewSimpleTxBytesStore(p.txStoreDB, p)
-		p.Log().Infof("opened DB '%s' as transaction store", dbname)
+		p.Log().Infof("opened DB '%s' as transaction store, backend: '%s'", dbname, kvdb.Detect(dbname))
 
 		go func() {
 			<-p.workProcessesStopStepChan
@@ -86,7 +124,12 @@ func (p *ProximaNode) initTxStore() {
 }
 
 func (p *ProximaNode) databaseGC() {
+	bdb, isBadger := p.multiStateDB.(*badger_adaptor.DB)
+	if !isBadger {
+		// only Badger needs garbage collection of the value log
+		return
+	}
 	start := time.Now()
-	err := p.multiStateDB.RunValueLogGC(0.5)
+	err := bdb.RunValueLogGC(0.5)
 	p.Log().Infof("----- Badger DB GC (%v): %v", time.Since(start), err)
 }
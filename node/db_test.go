@@ -0,0 +1,63 @@
+package node
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/lunfardo314/proxima/ledger"
+	"github.com/stretchr/testify/require"
+)
+
+const testLibraryUpgradesYAML = `
+upgrades:
+  - version: 1
+    activation_slot: 100
+    functions:
+      - sym: "nodeTestConcat"
+        numArgs: 2
+        source: "concat($0,$1)"
+`
+
+func TestCheckLibraryUpgrades(t *testing.T) {
+	id, _ := ledger.GetTestingIdentityData(31415926535)
+	idData := ledger.LibraryFromIdentityParameters(id).ToYAML(true)
+
+	compiled, err := ledger.CompileLibraryUpgradesYAML(idData, []byte(testLibraryUpgradesYAML))
+	require.NoError(t, err)
+
+	t.Run("no upgrades", func(t *testing.T) {
+		require.NoError(t, checkLibraryUpgrades(idData, nil))
+	})
+	t.Run("compiled", func(t *testing.T) {
+		require.NoError(t, checkLibraryUpgrades(idData, compiled))
+	})
+	t.Run("not compiled", func(t *testing.T) {
+		notCompiled, err := ledger.ParseLibraryUpgradesYAML([]byte(testLibraryUpgradesYAML))
+		require.NoError(t, err)
+		require.Error(t, checkLibraryUpgrades(idData, notCompiled))
+	})
+	t.Run("unknown version", func(t *testing.T) {
+		// version scheduled by the newer node software, which embeds function unknown to this node
+		unknown, err := ledger.ParseLibraryUpgradesYAML(compiled.YAML())
+		require.NoError(t, err)
+		unknown.Upgrades = append(unknown.Upgrades, ledger.LibraryUpgrade{
+			Version:        2,
+			ActivationSlot: 200,
+			Hash:           strings.Repeat("00", 32),
+		})
+		unknown.Upgrades[1].Functions = append(unknown.Upgrades[1].Functions, unknown.Upgrades[0].Functions[0])
+		unknown.Upgrades[1].Functions[0].Sym = "nodeTestUnknownEmbedded"
+		unknown.Upgrades[1].Functions[0].Embedded = true
+		unknown.Upgrades[1].Functions[0].Source = ""
+
+		err = checkLibraryUpgrades(idData, unknown)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "Upgrade the node software")
+	})
+	t.Run("wrong hash", func(t *testing.T) {
+		wrongHash, err := ledger.ParseLibraryUpgradesYAML(compiled.YAML())
+		require.NoError(t, err)
+		wrongHash.Upgrades[0].Hash = strings.Repeat("00", 32)
+		require.Error(t, checkLibraryUpgrades(idData, wrongHash))
+	})
+}
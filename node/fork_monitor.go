@@ -0,0 +1,295 @@
+package node
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/lunfardo314/proxima/api"
+	"github.com/lunfardo314/proxima/global"
+	"github.com/lunfardo314/proxima/ledger"
+	"github.com/lunfardo314/proxima/ledger/base"
+	"github.com/lunfardo314/proxima/ledger/multistate"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/spf13/viper"
+)
+
+// fork monitor compares branch tips known to the node with each other. Tips are the branches of the latest
+// committed slot in the state store (received from peers or produced by own sequencer) and the baseline branches
+// of the latest milestones of active sequencers in the tippool.
+// Tips with significant coverage, which are not in the same lineage with the heaviest tip, are competing.
+// Branches of different sequencers in the same slot are competing too, until one of them is continued.
+// So the fork is detected only when the same competing lineage, i.e. descendants of the competing tip,
+// persists for the configured number of slots.
+// The node is on the minority fork if the heaviest tip is not in the same lineage with its LRB
+
+type (
+	forkMonitorConfig struct {
+		// number of slots competing branches must persist for the fork to be detected
+		slots int
+		// minimum coverage delta of the branch to be taken into account, as fraction of the supply
+		minCoverage global.Fraction
+	}
+
+	forkMonitor struct {
+		mutex sync.RWMutex
+		cfg   forkMonitorConfig
+		// competing tips of the previous check with slots when their lineages were seen first
+		lineages map[base.TransactionID]base.Slot
+		info     api.ForkInfo
+		// listeners of fork events
+		handlerCounter int
+		handlers       map[int]func(info *api.ForkInfo) bool
+		// metrics
+		detectedGauge  prometheus.Gauge
+		competingGauge prometheus.Gauge
+		slotsGauge     prometheus.Gauge
+	}
+
+	forkTip struct {
+		txid     base.TransactionID
+		coverage uint64
+		source   string
+	}
+)
+
+const (
+	defaultForkMonitorSlots          = 3
+	defaultForkMinCoveragePercentage = 20
+
+	forkTipSourceStore   = "store"
+	forkTipSourceTippool = "tippool"
+)
+
+func readForkMonitorConfig() forkMonitorConfig {
+	ret := forkMonitorConfig{
+		slots: viper.GetInt("fork_monitor.slots"),
+		minCoverage: global.Fraction{
+			Numerator:   viper.GetInt("fork_monitor.min_coverage_percentage"),
+			Denominator: 100,
+		},
+	}
+	if ret.slots <= 0 {
+		ret.slots = defaultForkMonitorSlots
+	}
+	if ret.minCoverage.Numerator <= 0 || ret.minCoverage.Numerator > 100 {
+		ret.minCoverage.Numerator = defaultForkMinCoveragePercentage
+	}
+	return ret
+}
+
+func (p *ProximaNode) startForkMonitor() {
+	if viper.GetBool("fork_monitor.disable") {
+		p.Log().Infof("[fork] fork monitor is disabled")
+		return
+	}
+	p.forks = newForkMonitor(readForkMonitorConfig())
+	p.forks.registerMetrics(p.MetricsRegistry())
+
+	p.Log().Infof("[fork] fork monitor started. Slots: %d, min coverage: %s",
+		p.forks.cfg.slots, p.forks.cfg.minCoverage.String())
+
+	p.RepeatInBackground("fork_monitor_loop", ledger.L().ID.SlotDuration()/2, func() bool {
+		p.checkForks()
+		return true
+	})
+}
+
+func newForkMonitor(cfg forkMonitorConfig) *forkMonitor {
+	return &forkMonitor{
+		cfg:      cfg,
+		lineages: make(map[base.TransactionID]base.Slot),
+		handlers: make(map[int]func(info *api.ForkInfo) bool),
+	}
+}
+
+// forkTips collects significant branch tips from the state store and the tippool, sorted descending by coverage
+func (p *ProximaNode) forkTips() []forkTip {
+	ret := make([]forkTip, 0)
+	seen := make(map[base.TransactionID]struct{})
+	snapshotSlot := p.workflow.Branches().SnapshotSlot()
+	add := func(bd *multistate.BranchData, source string) {
+		txid := bd.Stem.ID.TransactionID()
+		if _, already := seen[txid]; already {
+			return
+		}
+		seen[txid] = struct{}{}
+		if txid.Slot() > snapshotSlot && global.IsHealthyCoverageDelta(bd.CoverageDelta, bd.Supply, p.forks.cfg.minCoverage) {
+			ret = append(ret, forkTip{
+				txid:     txid,
+				coverage: p.workflow.Branches().LedgerCoverage(txid),
+				source:   source,
+			})
+		}
+	}
+	for _, bd := range multistate.FetchLatestBranches(p.StateStore()) {
+		add(bd, forkTipSourceStore)
+	}
+	for _, vid := range p.workflow.LatestMilestonesDescending() {
+		if baseline, ok := vid.BaselineBranch(); ok {
+			if bd := p.workflow.Branches().Get(baseline); bd != nil {
+				add(bd, forkTipSourceTippool)
+			}
+		}
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		return ret[i].coverage > ret[j].coverage
+	})
+	return ret
+}
+
+func (p *ProximaNode) sameLineage(branch1, branch2 base.TransactionID) bool {
+	if branch1.Slot() < branch2.Slot() {
+		branch1, branch2 = branch2, branch1
+	}
+	ret, _ := p.workflow.Branches().IsDescendantBranch(branch1, branch2)
+	return ret
+}
+
+func (p *ProximaNode) checkForks() {
+	info := api.ForkInfo{}
+	// tips of competing lineages. On the minority fork, the LRB is one of them
+	competing := make([]base.TransactionID, 0)
+	var mainTip base.TransactionID
+	tips := p.forkTips()
+	if len(tips) > 0 {
+		mainTip = tips[0].txid
+		info.MainTip = mainTip.StringHex()
+		info.MainTipCoverage = tips[0].coverage
+		for _, tip := range tips[1:] {
+			if !p.sameLineage(mainTip, tip.txid) {
+				info.Competing = append(info.Competing, api.ForkBranch{
+					ID:       tip.txid.StringHex(),
+					Coverage: tip.coverage,
+					Source:   tip.source,
+				})
+				competing = append(competing, tip.txid)
+			}
+		}
+		if lrb := p.GetLatestReliableBranch(); lrb != nil {
+			lrbid := lrb.Stem.ID.TransactionID()
+			if info.OnMinority = !p.sameLineage(mainTip, lrbid); info.OnMinority {
+				competing = append(competing, lrbid)
+			}
+		}
+	}
+	p.forks.update(&info, mainTip, competing, ledger.TimeNow().Slot, p.sameLineage, p)
+}
+
+// update tracks lineages of competing tips. Competing tip continues the lineage of the tip of the previous check if
+// it is in the same lineage with it, i.e. it is the same branch or its descendant, unless the main tip
+// continues that lineage too. The lineage which does not continue is dropped, so competing branches, which are
+// replaced by other ones each slot, are not reported as fork.
+// Fork event handlers are called outside the lock
+func (f *forkMonitor) update(info *api.ForkInfo, mainTip base.TransactionID, competing []base.TransactionID, curSlot base.Slot, sameLineage func(b1, b2 base.TransactionID) bool, log global.Logging) {
+	f.mutex.Lock()
+
+	lineages := make(map[base.TransactionID]base.Slot, len(competing))
+	var sinceSlot base.Slot
+	for _, tip := range competing {
+		since := curSlot
+		for prevTip, prevSince := range f.lineages {
+			if prevSince < since && sameLineage(tip, prevTip) && !sameLineage(mainTip, prevTip) {
+				since = prevSince
+			}
+		}
+		lineages[tip] = since
+		if sinceSlot == 0 || since < sinceSlot {
+			sinceSlot = since
+		}
+	}
+	f.lineages = lineages
+
+	if sinceSlot != 0 {
+		info.SinceSlot = uint32(sinceSlot)
+		info.NumSlots = uint32(curSlot - sinceSlot)
+	}
+	info.Detected = sinceSlot != 0 && curSlot-sinceSlot >= base.Slot(f.cfg.slots)
+
+	wasDetected := f.info.Detected
+	f.info = *info
+
+	if f.detectedGauge != nil {
+		f.competingGauge.Set(float64(len(info.Competing)))
+		f.slotsGauge.Set(float64(info.NumSlots))
+		if info.Detected {
+			f.detectedGauge.Set(1)
+		} else {
+			f.detectedGauge.Set(0)
+		}
+	}
+
+	switch {
+	case info.Detected && !wasDetected:
+		log.Log().Warnf("[fork] FORK DETECTED: %d competing branch(es) for %d slots since slot %d, on minority: %v. Main tip: %s",
+			len(info.Competing), info.NumSlots, info.SinceSlot, info.OnMinority, info.MainTip)
+	case !info.Detected && wasDetected:
+		log.Log().Infof("[fork] fork resolved. Main tip: %s", info.MainTip)
+	default:
+		f.mutex.Unlock()
+		return
+	}
+	handlers := make(map[int]func(info *api.ForkInfo) bool, len(f.handlers))
+	for id, fun := range f.handlers {
+		handlers[id] = fun
+	}
+	event := f.info
+	f.mutex.Unlock()
+
+	for id, fun := range handlers {
+		if !fun(&event) {
+			f.removeHandler(id)
+		}
+	}
+}
+
+func (f *forkMonitor) removeHandler(id int) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	delete(f.handlers, id)
+}
+
+// ForkInfo returns status of the fork monitor or nil if it is disabled
+func (p *ProximaNode) ForkInfo() *api.ForkInfo {
+	if p.forks == nil {
+		return nil
+	}
+	p.forks.mutex.RLock()
+	defer p.forks.mutex.RUnlock()
+
+	ret := p.forks.info
+	return &ret
+}
+
+// OnForkEvent registers handler called when fork is detected or resolved. Handler returns false to be removed.
+// Returned function removes the handler, for example when the client disconnects
+func (p *ProximaNode) OnForkEvent(fun func(info *api.ForkInfo) bool) (remove func()) {
+	if p.forks == nil {
+		return func() {}
+	}
+	p.forks.mutex.Lock()
+	defer p.forks.mutex.Unlock()
+
+	id := p.forks.handlerCounter
+	p.forks.handlers[id] = fun
+	p.forks.handlerCounter++
+	return func() {
+		p.forks.removeHandler(id)
+	}
+}
+
+func (f *forkMonitor) registerMetrics(reg *prometheus.Registry) {
+	f.detectedGauge = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "proxima_fork_detected",
+		Help: "1 if competing branches persist longer than the configured number of slots, 0 otherwise",
+	})
+	f.competingGauge = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "proxima_fork_competing_branches",
+		Help: "number of branch tips with significant coverage, not in the same lineage with the heaviest tip",
+	})
+	f.slotsGauge = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "proxima_fork_slots",
+		Help: "number of slots the competing branches persist",
+	})
+	reg.MustRegister(f.detectedGauge, f.competingGauge, f.slotsGauge)
+}
@@ -0,0 +1,157 @@
+package node
+
+import (
+	"testing"
+
+	"github.com/lunfardo314/proxima/api"
+	"github.com/lunfardo314/proxima/global"
+	"github.com/lunfardo314/proxima/ledger/base"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/require"
+)
+
+// branchTree is the test lineage of branches
+type branchTree map[base.TransactionID]base.TransactionID
+
+func (bt branchTree) branch(slot base.Slot, parent *base.TransactionID) base.TransactionID {
+	ret := base.RandomTransactionID(true, 1, base.NewLedgerTime(slot, 0))
+	if parent != nil {
+		bt[ret] = *parent
+	}
+	return ret
+}
+
+func (bt branchTree) sameLineage(b1, b2 base.TransactionID) bool {
+	if b1.Slot() < b2.Slot() {
+		b1, b2 = b2, b1
+	}
+	for {
+		if b1 == b2 {
+			return true
+		}
+		parent, ok := bt[b1]
+		if !ok {
+			return false
+		}
+		b1 = parent
+	}
+}
+
+func newTestForkMonitor() *forkMonitor {
+	ret := newForkMonitor(forkMonitorConfig{slots: 3, minCoverage: global.Fraction{Numerator: 20, Denominator: 100}})
+	ret.registerMetrics(prometheus.NewRegistry())
+	return ret
+}
+
+func (f *forkMonitor) updateTest(bt branchTree, slot base.Slot, mainTip base.TransactionID, competing ...base.TransactionID) *api.ForkInfo {
+	info := &api.ForkInfo{MainTip: mainTip.StringHex()}
+	for _, c := range competing {
+		info.Competing = append(info.Competing, api.ForkBranch{ID: c.StringHex()})
+	}
+	f.update(info, mainTip, competing, slot, bt.sameLineage, global.NewDefault())
+	return info
+}
+
+func TestForkMonitor(t *testing.T) {
+	t.Run("same slot siblings", func(t *testing.T) {
+		f := newTestForkMonitor()
+		bt := make(branchTree)
+		winner := bt.branch(1, nil)
+		for slot := base.Slot(2); slot < 20; slot++ {
+			b1, b2 := bt.branch(slot, &winner), bt.branch(slot, &winner)
+			// the winner is taken from both sequencers in turns
+			main, competing := b1, b2
+			if slot%2 == 0 {
+				main, competing = b2, b1
+			}
+			info := f.updateTest(bt, slot, main, competing)
+			require.False(t, info.Detected, "slot %d", slot)
+			require.EqualValues(t, 0, info.NumSlots)
+			winner = main
+		}
+	})
+	t.Run("sibling continued by the main tip", func(t *testing.T) {
+		f := newTestForkMonitor()
+		bt := make(branchTree)
+		winner := bt.branch(1, nil)
+		for slot := base.Slot(2); slot < 20; slot++ {
+			b1, b2 := bt.branch(slot, &winner), bt.branch(slot, &winner)
+			info := f.updateTest(bt, slot, b1, b2)
+			require.False(t, info.Detected, "slot %d", slot)
+			// next branches descend from the competing one, i.e. the competing lineage wins
+			winner = b2
+		}
+	})
+	t.Run("persistent split", func(t *testing.T) {
+		f := newTestForkMonitor()
+		bt := make(branchTree)
+		numEvents := 0
+		f.handlers[0] = func(_ *api.ForkInfo) bool {
+			numEvents++
+			return true
+		}
+		root := bt.branch(1, nil)
+		main, minor := root, root
+		for slot := base.Slot(2); slot < 10; slot++ {
+			main, minor = bt.branch(slot, &main), bt.branch(slot, &minor)
+			info := f.updateTest(bt, slot, main, minor)
+			require.EqualValues(t, 2, info.SinceSlot)
+			require.EqualValues(t, slot-2, info.NumSlots)
+			require.Equal(t, slot-2 >= 3, info.Detected, "slot %d", slot)
+		}
+		require.Equal(t, 1, numEvents)
+
+		main = bt.branch(10, &main)
+		info := f.updateTest(bt, 10, main)
+		require.False(t, info.Detected)
+		require.Equal(t, 2, numEvents)
+	})
+	t.Run("minority", func(t *testing.T) {
+		f := newTestForkMonitor()
+		bt := make(branchTree)
+		lrb := bt.branch(1, nil)
+		var info *api.ForkInfo
+		for slot := base.Slot(2); slot < 6; slot++ {
+			info = f.updateTest(bt, slot, bt.branch(slot, nil), lrb)
+		}
+		require.True(t, info.Detected)
+		require.EqualValues(t, 2, info.SinceSlot)
+	})
+	t.Run("handlers outside the lock", func(t *testing.T) {
+		f := newTestForkMonitor()
+		bt := make(branchTree)
+		calls := 0
+		f.handlers[0] = func(info *api.ForkInfo) bool {
+			// would deadlock if called while holding the lock
+			f.mutex.Lock()
+			defer f.mutex.Unlock()
+			calls++
+			return false
+		}
+		root := bt.branch(1, nil)
+		main, minor := root, root
+		for slot := base.Slot(2); slot < 10; slot++ {
+			main, minor = bt.branch(slot, &main), bt.branch(slot, &minor)
+			f.updateTest(bt, slot, main, minor)
+		}
+		require.Equal(t, 1, calls)
+		// handler returned false, so it is removed
+		require.Equal(t, 0, len(f.handlers))
+	})
+	t.Run("remove handler", func(t *testing.T) {
+		p := &ProximaNode{forks: newTestForkMonitor()}
+		remove1 := p.OnForkEvent(func(_ *api.ForkInfo) bool { return true })
+		remove2 := p.OnForkEvent(func(_ *api.ForkInfo) bool { return true })
+		require.Equal(t, 2, len(p.forks.handlers))
+		remove1()
+		require.Equal(t, 1, len(p.forks.handlers))
+		remove1()
+		require.Equal(t, 1, len(p.forks.handlers))
+		remove2()
+		require.Equal(t, 0, len(p.forks.handlers))
+
+		// fork monitor disabled
+		p = &ProximaNode{}
+		p.OnForkEvent(func(_ *api.ForkInfo) bool { return true })()
+	})
+}
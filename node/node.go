@@ -10,14 +10,15 @@ import (
 	"github.com/lunfardo314/easyfl/slicepool"
 	"github.com/lunfardo314/proxima/core/workflow"
 	"github.com/lunfardo314/proxima/global"
+	"github.com/lunfardo314/proxima/kvdb"
 	"github.com/lunfardo314/proxima/ledger"
 	"github.com/lunfardo314/proxima/ledger/base"
 	"github.com/lunfardo314/proxima/ledger/multistate"
 	"github.com/lunfardo314/proxima/peering"
 	"github.com/lunfardo314/proxima/sequencer"
+	"github.com/lunfardo314/proxima/txstore"
 	"github.com/lunfardo314/proxima/util"
 	"github.com/lunfardo314/proxima/util/diskusage"
-	"github.com/lunfardo314/unitrie/adaptors/badger_adaptor"
 	"github.com/prometheus/client_golang/prometheus"
 	"github.com/prometheus/client_golang/prometheus/collectors"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
@@ -27,9 +28,9 @@ import (
 type (
 	ProximaNode struct {
 		*global.Global
-		multiStateDB              *badger_adaptor.DB
+		multiStateDB              kvdb.DB
 		snapshotBranchID          base.TransactionID
-		txStoreDB                 *badger_adaptor.DB
+		txStoreDB                 kvdb.DB
 		txBytesStore              global.TxBytesStore
 		peers                     *peering.Peers
 		sequencer                 *sequencer.Sequencer
@@ -38,6 +39,9 @@ type (
 		workProcessesStopStepChan chan struct{}
 		dbClosedWG                sync.WaitGroup
 		started                   time.Time
+		clock                     *clockService
+		forks                     *forkMonitor
+		txRejections              *txstore.TxRejections
 		metrics
 	}
 
@@ -56,7 +60,7 @@ type (
 	}
 )
 
-func init() {
+func readConfig() {
 	viper.SetConfigName("proxima")
 	viper.SetConfigType("yaml")
 	viper.AddConfigPath(".")
@@ -65,6 +69,7 @@ func init() {
 }
 
 func New() *ProximaNode {
+	readConfig()
 	ret := &ProximaNode{
 		Global:                    global.NewFromConfig(),
 		workProcessesStopStepChan: make(chan struct{}),
@@ -80,6 +85,9 @@ const waitAllProcessesStopTimeout = 20 * time.Second
 // WaitAllWorkProcessesToStop wait everything to stop before closing databases
 func (p *ProximaNode) WaitAllWorkProcessesToStop() {
 	<-p.Ctx().Done()
+	// databases are still open
+	p.saveFrontier()
+	p.flushTxRejections()
 	p.workProcessesStopStepChan <- struct{}{} // first step release DB close goroutines
 	p.Log().Infof("waiting all processes to stop for up to %v", waitAllProcessesStopTimeout)
 	p.Global.WaitAllWorkProcessesStop(waitAllProcessesStopTimeout)
@@ -103,6 +111,10 @@ func (p *ProximaNode) PullFromNPeers(nPeers int, txid base.TransactionID) int {
 	return p.peers.PullTransactionsFromNPeers(nPeers, txid)
 }
 
+func (p *ProximaNode) PullWithPastConeFromNPeers(nPeers int, txid base.TransactionID, downToSlot base.Slot) int {
+	return p.peers.PullTransactionsBatchFromNPeers(nPeers, []base.TransactionID{txid}, downToSlot)
+}
+
 func (p *ProximaNode) GetOwnSequencerID() *base.ChainID {
 	if p.sequencer == nil {
 		return nil
@@ -137,14 +149,23 @@ func (p *ProximaNode) Start() {
 		p.initTxStore()
 		initStep = "initPeering"
 		p.initPeering()
+		initStep = "startClockService"
+		p.startClockService()
 
 		initStep = "startWorkflow"
 		p.startWorkflow()
+		initStep = "startTxRejections"
+		p.startTxRejections()
+		initStep = "loadFrontier"
+		p.loadFrontier()
+		initStep = "startForkMonitor"
+		p.startForkMonitor()
 		initStep = "startSequencer"
 		p.startSequencer()
 		initStep = "startAPIServer"
 		p.startAPIServer()
-		p.startStreaming()
+		initStep = "startAdminAPIServer"
+		p.startAdminAPIServer()
 		initStep = "startPProfIfEnabled"
 		p.startPProfIfEnabled()
 		return nil
@@ -0,0 +1,74 @@
+package node
+
+import (
+	"time"
+
+	"github.com/lunfardo314/proxima/core/workflow"
+	"github.com/lunfardo314/proxima/ledger"
+	"github.com/lunfardo314/proxima/ledger/base"
+	"github.com/lunfardo314/proxima/txstore"
+	"github.com/lunfardo314/unitrie/common"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/spf13/viper"
+)
+
+// rejections of transactions are persisted in the bounded ring in the transaction store database,
+// so that the reason is known after the vertex is deleted from the memDAG.
+// Records are buffered and written to the database periodically and at shutdown
+
+const txRejectionsFlushPeriod = 2 * time.Second
+
+func (p *ProximaNode) startTxRejections() {
+	var store common.KVStore
+	if p.txStoreDB != nil {
+		store = p.txStoreDB
+	} else {
+		// 'dummy' transaction store: rejections are kept in memory only
+		store = common.NewInMemoryKVStore()
+	}
+	p.txRejections = txstore.NewTxRejections(store, viper.GetInt("tx_rejections.ring_size"))
+
+	counter := prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "proxima_tx_rejections",
+		Help: "number of transactions rejected by the node by source type",
+	}, []string{"source"})
+	p.MetricsRegistry().MustRegister(counter)
+
+	p.workflow.OnTxRejected(func(rej *workflow.TxRejected) bool {
+		counter.WithLabelValues(rej.Source.String()).Inc()
+		err := p.txRejections.Put(&txstore.TxRejection{
+			TxID:   rej.TxID,
+			Reason: rej.Reason.Error(),
+			Slot:   ledger.TimeNow().Slot,
+			Source: rej.Source,
+			Time:   time.Now(),
+		})
+		if err != nil {
+			p.Log().Warnf("failed to persist tx rejections: %v", err)
+		}
+		return true
+	})
+	p.RepeatInBackground("tx_rejections_flush_loop", txRejectionsFlushPeriod, func() bool {
+		p.flushTxRejections()
+		return true
+	}, true)
+	p.Log().Infof("tx rejections ring started. Records in the past: %d", p.txRejections.Count())
+}
+
+func (p *ProximaNode) flushTxRejections() {
+	if p.txRejections == nil {
+		return
+	}
+	if err := p.txRejections.Flush(); err != nil {
+		p.Log().Warnf("failed to persist tx rejections: %v", err)
+	}
+}
+
+// GetTxRejections returns total number of rejections and rejection records. If txid is nil,
+// returns at most maxRecords latest records, otherwise all records of the transaction
+func (p *ProximaNode) GetTxRejections(txid *base.TransactionID, maxRecords int) (uint64, []*txstore.TxRejection) {
+	if txid != nil {
+		return p.txRejections.Count(), p.txRejections.Get(*txid)
+	}
+	return p.txRejections.Count(), p.txRejections.Latest(maxRecords)
+}
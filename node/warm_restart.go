@@ -0,0 +1,40 @@
+package node
+
+import (
+	"time"
+
+	"github.com/spf13/viper"
+)
+
+// warm restart: frontier of the memDAG is saved into the transaction store database at shutdown
+// and reloaded at startup. Not available with the 'dummy' transaction store
+
+func (p *ProximaNode) warmRestartEnabled() bool {
+	return p.txStoreDB != nil && !viper.GetBool("warm_restart.disable")
+}
+
+func (p *ProximaNode) loadFrontier() {
+	if !p.warmRestartEnabled() {
+		p.Log().Infof("[warm restart] disabled")
+		return
+	}
+	start := time.Now()
+	n, err := p.workflow.LoadFrontier(p.txStoreDB)
+	if err != nil {
+		p.Log().Errorf("[warm restart] failed to load frontier of the memDAG: %v", err)
+		return
+	}
+	p.Log().Infof("[warm restart] %d transactions of the memDAG frontier loaded from the transaction store in %v", n, time.Since(start))
+}
+
+func (p *ProximaNode) saveFrontier() {
+	if !p.warmRestartEnabled() || p.workflow == nil {
+		return
+	}
+	n, err := p.workflow.SaveFrontier(p.txStoreDB)
+	if err != nil {
+		p.Log().Errorf("[warm restart] failed to save frontier of the memDAG: %v", err)
+		return
+	}
+	p.Log().Infof("[warm restart] frontier of the memDAG saved: %d transactions", n)
+}
@@ -0,0 +1,112 @@
+package peering
+
+import (
+	"fmt"
+
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/multiformats/go-multiaddr"
+)
+
+// runtime management of static peers and the blacklist, used by the admin API
+
+// AddStaticPeer adds new static peer at runtime. The peer is not persisted in the configuration
+func (ps *Peers) AddStaticPeer(name, addrString string) error {
+	maddr, err := multiaddr.NewMultiaddr(addrString)
+	if err != nil {
+		return fmt.Errorf("can't parse multiaddress: %w", err)
+	}
+	info, err := peer.AddrInfoFromP2pAddr(maddr)
+	if err != nil {
+		return fmt.Errorf("can't get multiaddress info: %v", err)
+	}
+	if info.ID == ps.host.ID() {
+		return fmt.Errorf("can't add host itself as a static peer")
+	}
+	ps.mutex.Lock()
+	for id, sp := range ps.staticPeers {
+		if sp.name == name && id != info.ID {
+			ps.mutex.Unlock()
+			return fmt.Errorf("static peer with name '%s' already exists", name)
+		}
+	}
+	// static peer replaces dynamic peer with the same ID
+	if p := ps._getPeer(info.ID); p != nil && !p.isStatic {
+		ps._dropPeer(p, "replaced by static peer", false)
+	}
+	delete(ps.blacklist, info.ID)
+	ps._removeFromCoolOffList(info.ID)
+	ps.mutex.Unlock()
+
+	return ps.addStaticPeer(maddr, name, addrString)
+}
+
+// RemoveStaticPeer removes static peer and disconnects from it
+func (ps *Peers) RemoveStaticPeer(id peer.ID) error {
+	ps.mutex.Lock()
+	defer ps.mutex.Unlock()
+
+	sp, found := ps.staticPeers[id]
+	if !found {
+		return fmt.Errorf("static peer %s not found", ShortPeerIDString(id))
+	}
+	delete(ps.staticPeers, id)
+	if p := ps._getPeer(id); p != nil {
+		ps._dropPeer(p, "static peer removed", false)
+	}
+	ps.Log().Infof("[peering] removed static peer %s - %s", ShortPeerIDString(id), sp.name)
+	return nil
+}
+
+// StaticPeers returns map name -> multiaddress of all static peers
+func (ps *Peers) StaticPeers() map[string]string {
+	ps.mutex.RLock()
+	defer ps.mutex.RUnlock()
+
+	ret := make(map[string]string, len(ps.staticPeers))
+	for _, sp := range ps.staticPeers {
+		ret[sp.name] = sp.addrString
+	}
+	return ret
+}
+
+func (ps *Peers) isStaticPeer(id peer.ID) bool {
+	ps.mutex.RLock()
+	defer ps.mutex.RUnlock()
+
+	_, yes := ps.staticPeers[id]
+	return yes
+}
+
+// AddToBlacklist drops the peer, if connected, and puts it into the blacklist for the configured blacklist TTL
+func (ps *Peers) AddToBlacklist(id peer.ID, reason string) error {
+	if id == ps.host.ID() {
+		return fmt.Errorf("can't blacklist host itself")
+	}
+	ps.mutex.Lock()
+	defer ps.mutex.Unlock()
+
+	if p := ps._getPeer(id); p != nil {
+		ps._dropPeer(p, reason, true)
+	}
+	ps._addToBlacklist(id, reason)
+	ps.Log().Infof("[peering] blacklisted peer %s. Reason: '%s'", ShortPeerIDString(id), reason)
+	return nil
+}
+
+// RemoveFromBlacklist removes peer from the blacklist. Static peer is re-connected
+func (ps *Peers) RemoveFromBlacklist(id peer.ID) error {
+	ps.mutex.Lock()
+	if _, found := ps.blacklist[id]; !found {
+		ps.mutex.Unlock()
+		return fmt.Errorf("peer %s is not in the blacklist", ShortPeerIDString(id))
+	}
+	delete(ps.blacklist, id)
+	sp, static := ps.staticPeers[id]
+	ps.mutex.Unlock()
+
+	ps.Log().Infof("[peering] removed peer %s from the blacklist", ShortPeerIDString(id))
+	if static {
+		return ps.addStaticPeer(sp.maddr, sp.name, sp.addrString)
+	}
+	return nil
+}
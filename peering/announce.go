@@ -0,0 +1,331 @@
+package peering
+
+import (
+	"bytes"
+	"compress/flate"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/libp2p/go-libp2p/core/network"
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/lunfardo314/proxima/ledger/base"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Announce protocol is optional. It is used between peers which both set flagSupportsAnnounce
+// and/or flagSupportsCompression in heartbeat messages. Older nodes do not open the stream.
+// 1st byte of the message is the type of the message. The rest is message body
+//
+// In the announce mode (inv/getdata) the node gossips only IDs of new transactions. The receiving peer
+// requests bodies of transactions it has not seen yet with the batched pull request to the announcer.
+// It saves bandwidth taken by duplicate transactions at the cost of one additional round trip
+
+const (
+	// AnnounceTransactions body is 2 bytes of number of transaction IDs, followed by transaction IDs
+	AnnounceTransactions = byte(iota)
+	// CompressedTransaction body is deflate-compressed gossip message
+	CompressedTransaction
+)
+
+const (
+	// maxAnnounceBatchSize maximum number of transaction IDs in one announcement
+	maxAnnounceBatchSize = 256
+	// announceBatchDelay is how long announcements to the same peer are collected before sending them in one message
+	announceBatchDelay = 20 * time.Millisecond
+	// announceRequestTTL is how long transaction requested after announcement is not requested again,
+	// when announced by other peers
+	announceRequestTTL = 5 * time.Second
+	// maxAnnounceRequested bounds the number of remembered requests
+	maxAnnounceRequested = 100_000
+)
+
+const TraceTagAnnounce = "peering_announce"
+
+type (
+	// announceRequests remembers transactions requested after announcement, to request each only from one peer
+	announceRequests struct {
+		mutex     sync.Mutex
+		requested map[base.TransactionID]time.Time
+	}
+
+	announceMetrics struct {
+		announcedIn             prometheus.Counter
+		announcedOut            prometheus.Counter
+		announceRequestsCounter prometheus.Counter
+		compressedSaved         prometheus.Counter
+	}
+)
+
+func (ps *Peers) announceStreamHandler(stream network.Stream) {
+	defer func() {
+		_ = stream.Close()
+		ps.Log().Infof("[peering] announce: streamHandler exit")
+	}()
+
+	id := stream.Conn().RemotePeer()
+
+	known, blacklisted, _ := ps.knownPeer(id, func(p *Peer) {
+	})
+	if blacklisted {
+		// ignore
+		return
+	}
+	if !known {
+		if !ps.isAutopeeringEnabled() {
+			// node does not take any incoming dynamic peers
+			ps.Log().Warnf("[peering] node does not take any incoming dynamic peers")
+			return
+		}
+		ps.Log().Infof("[peering] incoming peer request. Add new dynamic peer %s", id.String())
+	}
+
+	// receive start
+	_, err := readFrame(stream)
+	if err != nil {
+		ps.Log().Errorf("[peering] announce: error while reading start message from peer %s: err='%v'", ShortPeerIDString(id), err)
+		return
+	}
+	var msgData []byte
+
+	for {
+		msgData, err = readFrame(stream)
+		ps.inMsgCounter.Inc()
+		_, blacklisted, _ = ps.knownPeer(id, func(p *Peer) {})
+		if blacklisted {
+			// ignore
+			return
+		}
+		switch {
+		case err != nil:
+			ps.Log().Errorf("announce: error while reading message from peer %s: %v", id.String(), err)
+			return
+		case len(msgData) == 0:
+			ps.Log().Errorf("announce: error while reading message from peer %s: empty data", id.String())
+			return
+		}
+		if !ps.throttleInbound(id, protocolNameAnnounce, len(msgData)) {
+			continue
+		}
+
+		switch msgData[0] {
+		case AnnounceTransactions:
+			var txids []base.TransactionID
+			if txids, err = decodeAnnounceMsg(msgData); err == nil {
+				ps.evidenceMessage()
+				go ps.onReceiveAnnouncement(id, txids)
+			}
+		case CompressedTransaction:
+			var txMsg []byte
+			if txMsg, err = decompressTxMsg(msgData[1:]); err == nil {
+				ps.knownPeer(id, func(p *Peer) {
+					p.numIncomingTx++
+				})
+				err = ps.processTxMessage(id, txMsg)
+			}
+		default:
+			err = fmt.Errorf("wrong msg type '%d'", msgData[0])
+		}
+		if err != nil {
+			// protocol violation
+			err = fmt.Errorf("announce: error while parsing message from peer %s: %v", id.String(), err)
+			ps.Log().Error(err)
+			ps.evidenceProtocolViolation(id, err.Error())
+			return
+		}
+	}
+}
+
+// onReceiveAnnouncement requests from the announcer all announced transactions which are not known yet
+// and were not requested recently from other peers. Requested transactions are not marked as wanted,
+// so they are gossiped further when received
+func (ps *Peers) onReceiveAnnouncement(from peer.ID, txids []base.TransactionID) {
+	ps.mutex.RLock()
+	isKnown := ps.isKnownTx
+	ps.mutex.RUnlock()
+
+	toRequest := make([]base.TransactionID, 0, len(txids))
+	nowis := time.Now()
+
+	ps.announceRequests.mutex.Lock()
+	for _, txid := range txids {
+		if isKnown(txid) {
+			continue
+		}
+		if deadline, already := ps.announceRequests.requested[txid]; already && deadline.After(nowis) {
+			continue
+		}
+		if len(ps.announceRequests.requested) < maxAnnounceRequested {
+			ps.announceRequests.requested[txid] = nowis.Add(announceRequestTTL)
+		}
+		toRequest = append(toRequest, txid)
+	}
+	ps.announceRequests.mutex.Unlock()
+
+	if ps.announcedIn != nil {
+		ps.announcedIn.Add(float64(len(txids)))
+		ps.announceRequestsCounter.Add(float64(len(toRequest)))
+	}
+	ps.Tracef(TraceTagAnnounce, "received %d txids from %s, requested %d", len(txids), ShortPeerIDString(from), len(toRequest))
+
+	if len(toRequest) > 0 {
+		ps.sendPullBatchToPeer(from, &PullBatch{TxIDs: toRequest})
+	}
+}
+
+// cleanAnnounceRequests removes expired requests
+func (ps *Peers) cleanAnnounceRequests() {
+	ps.announceRequests.mutex.Lock()
+	defer ps.announceRequests.mutex.Unlock()
+
+	nowis := time.Now()
+	for txid, deadline := range ps.announceRequests.requested {
+		if deadline.Before(nowis) {
+			delete(ps.announceRequests.requested, txid)
+		}
+	}
+}
+
+// SetKnownTransactionFilter sets function which tells if the transaction was already received.
+// Known transactions are not requested after announcement
+func (ps *Peers) SetKnownTransactionFilter(fun func(txid base.TransactionID) bool) {
+	ps.mutex.Lock()
+	defer ps.mutex.Unlock()
+
+	ps.isKnownTx = fun
+}
+
+// sendAnnouncement sends transaction IDs to the peer in as few messages as possible
+func (ps *Peers) sendAnnouncement(id peer.ID, txids []base.TransactionID) {
+	for _, msgData := range encodeAnnounceMsgs(txids) {
+		ps.sendMsgBytesOut(id, ps.lppProtocolAnnounce, msgData)
+	}
+	if ps.announcedOut != nil {
+		ps.announcedOut.Add(float64(len(txids)))
+	}
+}
+
+// _respondsToPullFrom is what the node tells the peer in the heartbeat messages
+func (ps *Peers) _respondsToPullFrom(p *Peer) bool {
+	return !ps.cfg.IgnoreAllPullRequests && (p.isStatic || !ps.cfg.AcceptPullRequestsFromStaticPeersOnly)
+}
+
+// _canAnnounceTo is true if the node is in the announce mode and the peer can request announced transactions
+func (ps *Peers) _canAnnounceTo(p *Peer) bool {
+	if !ps.cfg.Announce || !p.supportsAnnounce || !ps._respondsToPullFrom(p) {
+		return false
+	}
+	_, hasStream := p.streams[ps.lppProtocolAnnounce]
+	return hasStream
+}
+
+// _canCompressTo is true if compression is enabled on both sides
+func (ps *Peers) _canCompressTo(p *Peer) bool {
+	if !ps.cfg.Compression || !p.supportsCompression {
+		return false
+	}
+	_, hasStream := p.streams[ps.lppProtocolAnnounce]
+	return hasStream
+}
+
+func (ps *Peers) canCompressTo(id peer.ID) bool {
+	ps.mutex.RLock()
+	defer ps.mutex.RUnlock()
+
+	p := ps._getPeer(id)
+	return p != nil && ps._canCompressTo(p)
+}
+
+// encodeAnnounceMsgs splits transaction IDs into messages of at most maxAnnounceBatchSize IDs
+func encodeAnnounceMsgs(txids []base.TransactionID) [][]byte {
+	ret := make([][]byte, 0, len(txids)/maxAnnounceBatchSize+1)
+	for len(txids) > 0 {
+		n := min(len(txids), maxAnnounceBatchSize)
+
+		var buf bytes.Buffer
+		buf.WriteByte(AnnounceTransactions)
+		_ = binary.Write(&buf, binary.BigEndian, uint16(n))
+		for i := range txids[:n] {
+			buf.Write(txids[i][:])
+		}
+		ret = append(ret, buf.Bytes())
+		txids = txids[n:]
+	}
+	return ret
+}
+
+func decodeAnnounceMsg(data []byte) ([]base.TransactionID, error) {
+	if len(data) < 3 || data[0] != AnnounceTransactions {
+		return nil, fmt.Errorf("not an announce message")
+	}
+	n := int(binary.BigEndian.Uint16(data[1:3]))
+	data = data[3:]
+	if n == 0 || n > maxAnnounceBatchSize || len(data) != n*base.TransactionIDLength {
+		return nil, fmt.Errorf("wrong announce message: wrong number of transaction IDs or data length")
+	}
+	ret := make([]base.TransactionID, n)
+	var err error
+	for i := range ret {
+		if ret[i], err = base.TransactionIDFromBytes(data[i*base.TransactionIDLength : (i+1)*base.TransactionIDLength]); err != nil {
+			return nil, err
+		}
+	}
+	return ret, nil
+}
+
+// encodeCompressedTxMsg returns CompressedTransaction message, or nil if compression does not make the message shorter
+func encodeCompressedTxMsg(txMsg []byte) []byte {
+	var buf bytes.Buffer
+	buf.WriteByte(CompressedTransaction)
+	w, err := flate.NewWriter(&buf, flate.BestSpeed)
+	if err != nil {
+		return nil
+	}
+	if _, err = w.Write(txMsg); err != nil {
+		return nil
+	}
+	if err = w.Close(); err != nil {
+		return nil
+	}
+	if buf.Len() >= len(txMsg) {
+		return nil
+	}
+	return buf.Bytes()
+}
+
+// decompressTxMsg decompresses message body. Gossip message never exceeds MaxPayloadSize
+func decompressTxMsg(data []byte) ([]byte, error) {
+	r := flate.NewReader(bytes.NewReader(data))
+	defer func() { _ = r.Close() }()
+
+	ret, err := io.ReadAll(io.LimitReader(r, MaxPayloadSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("decompressTxMsg: %w", err)
+	}
+	if len(ret) > MaxPayloadSize {
+		return nil, fmt.Errorf("decompressTxMsg: decompressed message exceeds maximum %d bytes", MaxPayloadSize)
+	}
+	return ret, nil
+}
+
+func (ps *Peers) registerAnnounceMetrics() {
+	ps.announcedIn = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "proxima_peering_announcedIn",
+		Help: "number of transaction IDs received in announcements",
+	})
+	ps.announcedOut = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "proxima_peering_announcedOut",
+		Help: "number of transaction IDs sent in announcements",
+	})
+	ps.announceRequestsCounter = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "proxima_peering_announceRequests",
+		Help: "number of announced transactions requested from announcers",
+	})
+	ps.compressedSaved = prometheus.NewCounter(prometheus.CounterOpts{
+		Name: "proxima_peering_compressedSavedBytes",
+		Help: "number of bytes saved by compression of sent transaction messages",
+	})
+	ps.MetricsRegistry().MustRegister(ps.announcedIn, ps.announcedOut, ps.announceRequestsCounter, ps.compressedSaved)
+}
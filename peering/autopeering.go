@@ -1,6 +1,7 @@
 package peering
 
 import (
+	"fmt"
 	"math/rand"
 	"sort"
 	"time"
@@ -72,6 +73,13 @@ func (ps *Peers) dropExcessPeersIfNeeded() {
 	defer ps.mutex.Unlock()
 
 	sortedDynamicPeers := ps._sortedDynamicPeersByRankAsc()
+	// dynamic peers with low reputation are dropped even if not excess. Autopeering will replace them
+	for _, p := range sortedDynamicPeers {
+		if score := p.score(); score < dropScoreThreshold && time.Since(p.whenAdded) > gracePeriodAfterAdded {
+			ps._dropPeer(p, fmt.Sprintf("low reputation score %.1f", score), false)
+		}
+	}
+	sortedDynamicPeers = ps._sortedDynamicPeersByRankAsc()
 	if len(sortedDynamicPeers) <= ps.cfg.MaxDynamicPeers {
 		return
 	}
@@ -0,0 +1,139 @@
+package peering
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	// staticPeerClockWeight static peers are chosen by the node operator, so their clocks are trusted more
+	staticPeerClockWeight = 3
+	// minHeartbeatsForClockOffset the peer is taken into account only when the buffer of clock differences is full
+	minHeartbeatsForClockOffset = 10
+)
+
+type (
+	clockSample struct {
+		diff   time.Duration
+		weight int
+	}
+
+	// ClockEstimate is the offset of the local clock estimated from clocks of a group of peers
+	ClockEstimate struct {
+		Offset time.Duration
+		// number of peers taken into account
+		NumPeers int
+	}
+
+	ClockConfig struct {
+		// maximum remaining skew with which the sequencer produces milestones
+		MaxSkew time.Duration
+		// if true, the ledger time is corrected by the estimated offset
+		CorrectionEnabled bool
+		// maximum absolute value of the correction
+		MaxCorrection time.Duration
+		// minimum number of static peers needed for the estimate
+		MinPeers int
+	}
+
+	// ClockTracker keeps the latest clock estimates and decides on correction of the ledger time and
+	// on the skew. Decisions are based only on static peers: sybil dynamic peers could shift
+	// the median and stop the sequencer. Estimate from all peers is only reported
+	ClockTracker struct {
+		mutex  sync.RWMutex
+		cfg    ClockConfig
+		all    ClockEstimate
+		static ClockEstimate
+	}
+)
+
+// ClockOffset estimates offset of the local clock with respect to clocks of peers. Positive means local
+// clock is ahead. Heartbeat latency is included in clock differences, so estimates are biased
+// towards positive values by the typical latency.
+// 'all' is weighted median of medians of clock differences of all alive peers, static peers having bigger weight.
+// Dynamic peers may be sybils, so 'static' is the median of static peers only. It is the one to make
+// decisions upon, like correction of the ledger time
+func (ps *Peers) ClockOffset() (all, static ClockEstimate) {
+	samples := make([]clockSample, 0)
+	staticSamples := make([]clockSample, 0)
+	ps.forEachPeerRLock(func(p *Peer) bool {
+		if p._isAlive() && p.numIncomingHB >= minHeartbeatsForClockOffset {
+			weight := 1
+			if p.isStatic {
+				weight = staticPeerClockWeight
+				staticSamples = append(staticSamples, clockSample{diff: p.clockDifferenceQuartiles[1], weight: 1})
+			}
+			samples = append(samples, clockSample{diff: p.clockDifferenceQuartiles[1], weight: weight})
+		}
+		return true
+	})
+	all = ClockEstimate{Offset: weightedMedian(samples), NumPeers: len(samples)}
+	static = ClockEstimate{Offset: weightedMedian(staticSamples), NumPeers: len(staticSamples)}
+	return
+}
+
+// weightedMedian returns value for which at least half of the total weight is on each side. 0 for empty samples
+func weightedMedian(samples []clockSample) time.Duration {
+	if len(samples) == 0 {
+		return 0
+	}
+	sort.Slice(samples, func(i, j int) bool {
+		return samples[i].diff < samples[j].diff
+	})
+	total := 0
+	for _, s := range samples {
+		total += s.weight
+	}
+	cumulative := 0
+	for i, s := range samples {
+		cumulative += s.weight
+		if 2*cumulative == total && i+1 < len(samples) {
+			// exactly in between
+			return (s.diff + samples[i+1].diff) / 2
+		}
+		if 2*cumulative > total {
+			return s.diff
+		}
+	}
+	return samples[len(samples)-1].diff
+}
+
+func NewClockTracker(cfg ClockConfig) *ClockTracker {
+	return &ClockTracker{cfg: cfg}
+}
+
+// Update stores new estimates and returns new correction of the ledger time. changed is false
+// if the correction must stay as it is
+func (c *ClockTracker) Update(all, static ClockEstimate, currentCorrection time.Duration) (correction time.Duration, changed bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.all, c.static = all, static
+	if !c.cfg.CorrectionEnabled || static.NumPeers < c.cfg.MinPeers {
+		return currentCorrection, false
+	}
+	correction = min(max(-static.Offset, -c.cfg.MaxCorrection), c.cfg.MaxCorrection)
+	return correction, correction != currentCorrection
+}
+
+// Skew returns skew of the ledger time with the correction with respect to static peers.
+// With fewer static peers than required, the skew is unknown and reported as 0
+func (c *ClockTracker) Skew(correction time.Duration) (skew time.Duration, tooBig bool) {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+
+	if c.static.NumPeers < c.cfg.MinPeers {
+		return 0, false
+	}
+	skew = c.static.Offset + correction
+	return skew, skew > c.cfg.MaxSkew || skew < -c.cfg.MaxSkew
+}
+
+// Offset returns offset of the local clock estimated from all peers
+func (c *ClockTracker) Offset() time.Duration {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+
+	return c.all.Offset
+}
@@ -3,6 +3,7 @@ package peering
 import (
 	"bytes"
 	"encoding/binary"
+	"encoding/hex"
 	"fmt"
 	"time"
 
@@ -18,12 +19,21 @@ type heartbeatInfo struct {
 	clock                  time.Time
 	counter                uint32
 	respondsToPullRequests bool
+	supportsPullBatch      bool
+	supportsAnnounce       bool
+	supportsCompression    bool
 }
 
 // flags of the heartbeat message. Information for the peer about the node
 const (
 	// flagRespondsToPullRequests if false, node ignores all pull requests from the message target
 	flagRespondsToPullRequests = byte(0b00000001)
+	// flagSupportsPullBatch node understands PullTransactionsBatch messages. Older nodes ignore unknown flags
+	flagSupportsPullBatch = byte(0b00000010)
+	// flagSupportsAnnounce node accepts announce protocol stream and requests announced transactions
+	flagSupportsAnnounce = byte(0b00000100)
+	// flagSupportsCompression node enabled compression and wants compressed transaction messages
+	flagSupportsCompression = byte(0b00001000)
 )
 
 const (
@@ -99,11 +109,25 @@ func (ps *Peers) heartbeatStreamHandler(stream network.Stream) {
 	ps.Tracef(TraceTagHeartBeatRecv, "[peering] hb: ******** streamHandler started for %s", ShortPeerIDString(id))
 
 	// receive start
-	_, err := readFrame(stream)
+	startMsg, err := readFrame(stream)
 	if err != nil {
 		ps.Tracef(TraceTagHeartBeatRecv, "[peering] hb: error while reading start message from peer %s: err='%v'", ShortPeerIDString(id), err)
 		return
 	}
+	upgradesHash, err := upgradesHashFromStartMessage(startMsg)
+	if err != nil {
+		err = fmt.Errorf("[peering] hb: error while parsing start message from peer %s: %v", ShortPeerIDString(id), err)
+		ps.Log().Error(err)
+		ps.evidenceProtocolViolation(id, err.Error())
+		return
+	}
+	if expected := ps.upgradesHash(); upgradesHash != expected {
+		// the peer would validate transactions with different ledger library versions. The peer is not blacklisted:
+		// near the activation slot the difference may be caused by the difference of clocks
+		ps.Log().Warnf("[peering] hb: refused heartbeat from peer %s: different active ledger library upgrades: hash %s, expected %s",
+			ShortPeerIDString(id), hex.EncodeToString(upgradesHash[:]), hex.EncodeToString(expected[:]))
+		return
+	}
 
 	for {
 		var hbInfo heartbeatInfo
@@ -120,11 +144,14 @@ func (ps *Peers) heartbeatStreamHandler(stream network.Stream) {
 			ps.dropPeer(id, err.Error(), false) // peer probably just restart
 			return
 		}
+		if !ps.throttleInbound(id, protocolNameHeartbeat, len(msgData)) {
+			continue
+		}
 		if hbInfo, err = heartbeatInfoFromBytes(msgData); err != nil {
 			// protocol violation
-			err = fmt.Errorf("[peering] hb: error while serializing message from peer %s: %v. Reset connection", ShortPeerIDString(id), err)
+			err = fmt.Errorf("[peering] hb: error while serializing message from peer %s: %v", ShortPeerIDString(id), err)
 			ps.Log().Error(err)
-			ps.dropPeer(id, err.Error(), true)
+			ps.evidenceProtocolViolation(id, err.Error())
 			return
 		}
 
@@ -175,6 +202,9 @@ func (ps *Peers) _evidenceHeartBeat(p *Peer, hbInfo heartbeatInfo) {
 	p.lastHeartbeatReceived = nowis
 
 	p.respondsToPullRequests = hbInfo.respondsToPullRequests
+	p.supportsPullBatch = hbInfo.supportsPullBatch
+	p.supportsAnnounce = hbInfo.supportsAnnounce
+	p.supportsCompression = hbInfo.supportsCompression
 
 	ps.Tracef(TraceTagHeartBeatRecv, ">>>>> received #%d from %s: clock diff: %v, median: %v, responds to pull: %v, alive: %v",
 		hbInfo.counter, ShortPeerIDString(p.id), diff, q[1], p.respondsToPullRequests, p._isAlive())
@@ -185,7 +215,7 @@ func (ps *Peers) sendHeartbeatToPeer(id peer.ID, hbCounter uint32) {
 	if ps.cfg.IgnoreAllPullRequests {
 		respondsToPull = false
 	} else if ps.cfg.AcceptPullRequestsFromStaticPeersOnly {
-		_, respondsToPull = ps.staticPeers[id]
+		respondsToPull = ps.isStaticPeer(id)
 	}
 	p := ps.getPeer(id)
 	if p == nil {
@@ -204,6 +234,9 @@ func (ps *Peers) sendHeartbeatToPeer(id peer.ID, hbCounter uint32) {
 	msg := &heartbeatInfo{
 		// time now will be set in the queue consumer
 		respondsToPullRequests: respondsToPull,
+		supportsPullBatch:      true,
+		supportsAnnounce:       true,
+		supportsCompression:    ps.cfg.Compression,
 		counter:                hbCounter,
 		clock:                  time.Now(),
 	}
@@ -261,11 +294,23 @@ func (hi *heartbeatInfo) flags() (ret byte) {
 	if hi.respondsToPullRequests {
 		ret |= flagRespondsToPullRequests
 	}
+	if hi.supportsPullBatch {
+		ret |= flagSupportsPullBatch
+	}
+	if hi.supportsAnnounce {
+		ret |= flagSupportsAnnounce
+	}
+	if hi.supportsCompression {
+		ret |= flagSupportsCompression
+	}
 	return
 }
 
 func (hi *heartbeatInfo) setFromFlags(fl byte) {
 	hi.respondsToPullRequests = (fl & flagRespondsToPullRequests) != 0
+	hi.supportsPullBatch = (fl & flagSupportsPullBatch) != 0
+	hi.supportsAnnounce = (fl & flagSupportsAnnounce) != 0
+	hi.supportsCompression = (fl & flagSupportsCompression) != 0
 }
 
 func (hi *heartbeatInfo) Bytes() []byte {
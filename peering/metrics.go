@@ -19,6 +19,11 @@ type metrics struct {
 	// txMsg metrics
 	transactionsReceivedCounter prometheus.Counter
 	txBytesReceivedCounter      prometheus.Counter
+
+	// per peer traffic metrics
+	trafficMetrics
+	// announce protocol metrics
+	announceMetrics
 }
 
 func (ps *Peers) registerMetrics() {
@@ -74,6 +79,9 @@ func (ps *Peers) registerMetrics() {
 		Help: "counts number of received transaction bytes",
 	})
 	ps.MetricsRegistry().MustRegister(ps.transactionsReceivedCounter, ps.txBytesReceivedCounter)
+
+	ps.registerTrafficMetrics()
+	ps.registerAnnounceMetrics()
 }
 
 func (ps *Peers) peerStats() (ret peersStats) {
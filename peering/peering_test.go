@@ -2,6 +2,7 @@ package peering
 
 import (
 	"bytes"
+	"crypto/rand"
 	"sync"
 	"sync/atomic"
 	"testing"
@@ -124,6 +125,49 @@ func TestHeartbeat(t *testing.T) {
 	}
 }
 
+func TestUpgradesHashMismatch(t *testing.T) {
+	const numHosts = 3
+	hosts := makeHosts(t, numHosts, false)
+	// host 0 has different active ledger library upgrades
+	var upgradesHash [32]byte
+	_, _ = rand.Read(upgradesHash[:])
+	hosts[0].upgradesHash = func() [32]byte { return upgradesHash }
+	for _, h := range hosts {
+		h.Run()
+	}
+	time.Sleep(10 * time.Second)
+	require.True(t, hosts[1].IsAlive(hosts[2].host.ID()))
+	require.True(t, hosts[2].IsAlive(hosts[1].host.ID()))
+	for _, ps := range hosts[1:] {
+		require.False(t, ps.IsAlive(hosts[0].host.ID()))
+		require.False(t, hosts[0].IsAlive(ps.host.ID()))
+		// different versions are refused, but not blacklisted
+		require.False(t, ps.IsBlacklisted(hosts[0].host.ID()))
+		require.False(t, hosts[0].IsBlacklisted(ps.host.ID()))
+	}
+	for _, ps := range hosts {
+		ps.Stop()
+	}
+}
+
+func TestStartMessage(t *testing.T) {
+	h, err := upgradesHashFromStartMessage(startMessage([32]byte{}))
+	require.NoError(t, err)
+	require.EqualValues(t, [32]byte{}, h)
+	require.EqualValues(t, "Start", string(startMessage([32]byte{})))
+
+	var upgradesHash [32]byte
+	_, _ = rand.Read(upgradesHash[:])
+	h, err = upgradesHashFromStartMessage(startMessage(upgradesHash))
+	require.NoError(t, err)
+	require.EqualValues(t, upgradesHash, h)
+
+	_, err = upgradesHashFromStartMessage([]byte("Stop"))
+	require.Error(t, err)
+	_, err = upgradesHashFromStartMessage(startMessage(upgradesHash)[:20])
+	require.Error(t, err)
+}
+
 func TestSendMsg(t *testing.T) {
 	t.Run("1", func(t *testing.T) {
 		const (
@@ -335,3 +379,286 @@ func TestSendMsg(t *testing.T) {
 		require.EqualValues(t, 0, len(txSet))
 	})
 }
+
+func TestPullBatchMsg(t *testing.T) {
+	t.Run("batch", func(t *testing.T) {
+		batch := &PullBatch{TxIDs: make([]base.TransactionID, maxPullBatchSize+10)}
+		for i := range batch.TxIDs {
+			batch.TxIDs[i] = base.RandomTransactionID(false, 2)
+		}
+		msgs := encodePullTransactionsBatchMsgs(batch)
+		require.EqualValues(t, 2, len(msgs))
+
+		decoded := make([]base.TransactionID, 0)
+		for _, msgData := range msgs {
+			b, err := decodePullTransactionsBatchMsg(msgData)
+			require.NoError(t, err)
+			require.False(t, b.WithPastCone)
+			decoded = append(decoded, b.TxIDs...)
+		}
+		require.EqualValues(t, batch.TxIDs, decoded)
+	})
+	t.Run("past cone", func(t *testing.T) {
+		batch := &PullBatch{
+			TxIDs:              []base.TransactionID{base.RandomTransactionID(true, 1)},
+			WithPastCone:       true,
+			PastConeDownToSlot: 1337,
+		}
+		msgs := encodePullTransactionsBatchMsgs(batch)
+		require.EqualValues(t, 1, len(msgs))
+		b, err := decodePullTransactionsBatchMsg(msgs[0])
+		require.NoError(t, err)
+		require.EqualValues(t, batch, b)
+
+		// single txid message is not a batch
+		_, err = decodePullTransactionsBatchMsg(encodePullTransactionMsg(batch.TxIDs[0]))
+		require.Error(t, err)
+		// unknown version
+		msgs[0][1] = pullBatchVersion + 1
+		_, err = decodePullTransactionsBatchMsg(msgs[0])
+		require.Error(t, err)
+	})
+	t.Run("heartbeat flags", func(t *testing.T) {
+		hb := heartbeatInfo{clock: time.Unix(0, 1000), counter: 5, supportsPullBatch: true}
+		hbBack, err := heartbeatInfoFromBytes(hb.Bytes())
+		require.NoError(t, err)
+		require.EqualValues(t, hb, hbBack)
+
+		// heartbeat of the older node
+		data := hb.Bytes()
+		data[0] = flagRespondsToPullRequests
+		hbBack, err = heartbeatInfoFromBytes(data)
+		require.NoError(t, err)
+		require.True(t, hbBack.respondsToPullRequests)
+		require.False(t, hbBack.supportsPullBatch)
+	})
+}
+
+func TestReputation(t *testing.T) {
+	t.Run("flaky is not banned", func(t *testing.T) {
+		p := &Peer{}
+		for i := 0; i < 1000; i++ {
+			p.reputation._addReliability(-penaltyPullMissed)
+		}
+		require.EqualValues(t, minReliability, p.reputation.reliability)
+		require.True(t, p.reputation.misbehaviour > banThreshold)
+		// even with the biggest clock difference
+		p.clockDifferenceQuartiles[1] = -time.Hour
+		require.InDelta(t, minReliability-maxClockPenalty, p.score(), 0.01)
+		require.True(t, p.score() > banThreshold)
+	})
+	t.Run("decay", func(t *testing.T) {
+		r := reputation{misbehaviour: -40, reliability: 10, lastUpdated: time.Now().Add(-reputationHalfLife)}
+		reliability, misbehaviour := r.decayed(time.Now())
+		require.InDelta(t, 5, reliability, 0.01)
+		require.InDelta(t, -20, misbehaviour, 0.01)
+		// next penalty after decay does not reach the ban threshold
+		r._addMisbehaviour(penaltyInvalidTx)
+		require.InDelta(t, -30, r.misbehaviour, 0.01)
+	})
+	t.Run("clock penalty", func(t *testing.T) {
+		p := &Peer{}
+		require.EqualValues(t, 0, p.clockPenalty())
+		p.clockDifferenceQuartiles[1] = clockTolerance
+		require.InDelta(t, maxClockPenalty/2, p.clockPenalty(), 0.01)
+	})
+}
+
+func TestInboundLimiter(t *testing.T) {
+	nowis := time.Now()
+	t.Run("drop", func(t *testing.T) {
+		l := newInboundLimiter(InboundLimit{MsgPerSec: 10, BytesPerSec: 1000}, nowis)
+		for i := 0; i < 10; i++ {
+			require.True(t, l.allow(10, nowis))
+		}
+		require.False(t, l.allow(10, nowis))
+		require.True(t, l.allow(10, nowis.Add(100*time.Millisecond)))
+
+		l = newInboundLimiter(InboundLimit{BytesPerSec: 1000}, nowis)
+		require.True(t, l.allow(600, nowis))
+		require.False(t, l.allow(600, nowis))
+		require.True(t, l.allow(600, nowis.Add(200*time.Millisecond)))
+		// message bigger than the capacity passes when the bucket is full
+		require.False(t, l.allow(5000, nowis.Add(time.Second)))
+		require.True(t, l.allow(5000, nowis.Add(2*time.Second)))
+	})
+	t.Run("backpressure", func(t *testing.T) {
+		l := newInboundLimiter(InboundLimit{BytesPerSec: 1000}, nowis)
+		require.EqualValues(t, 0, l.reserve(1000, nowis))
+		require.InDelta(t, float64(500*time.Millisecond), float64(l.reserve(500, nowis)), float64(time.Millisecond))
+		for i := 0; i < 10; i++ {
+			l.reserve(1000, nowis)
+		}
+		require.EqualValues(t, maxBackpressureDelay, l.reserve(1000, nowis))
+	})
+	t.Run("unlimited", func(t *testing.T) {
+		l := newInboundLimiter(InboundLimit{}, nowis)
+		for i := 0; i < 1000; i++ {
+			require.True(t, l.allow(1<<20, nowis))
+			require.EqualValues(t, 0, l.reserve(1<<20, nowis))
+		}
+	})
+}
+
+func TestChargePullResponse(t *testing.T) {
+	ps := NewPeersDummy()
+	require.True(t, ps.ChargePullResponse("peer", 1<<30))
+
+	ps.environment = newEnvironment()
+	ps.cfg = &Config{InboundLimits: map[string]InboundLimit{protocolNamePullResponse: {BytesPerSec: 1000}}}
+	id := peer.ID("peer")
+	ps.peers[id] = &Peer{id: id}
+	require.True(t, ps.ChargePullResponse(id, 600))
+	require.False(t, ps.ChargePullResponse(id, 600))
+	require.EqualValues(t, 1, ps.peers[id].numThrottled)
+	// unknown peer is not limited
+	require.True(t, ps.ChargePullResponse("other", 600))
+}
+
+func newTestClockTracker(correctionEnabled bool) *ClockTracker {
+	return NewClockTracker(ClockConfig{
+		MaxSkew:           3 * time.Second,
+		CorrectionEnabled: correctionEnabled,
+		MaxCorrection:     time.Second,
+		MinPeers:          2,
+	})
+}
+
+func TestClockTracker(t *testing.T) {
+	t.Run("halt", func(t *testing.T) {
+		c := newTestClockTracker(false)
+		c.Update(ClockEstimate{}, ClockEstimate{Offset: 5 * time.Second, NumPeers: 2}, 0)
+		skew, tooBig := c.Skew(0)
+		require.EqualValues(t, 5*time.Second, skew)
+		require.True(t, tooBig)
+
+		c.Update(ClockEstimate{}, ClockEstimate{Offset: -5 * time.Second, NumPeers: 2}, 0)
+		_, tooBig = c.Skew(0)
+		require.True(t, tooBig)
+
+		c.Update(ClockEstimate{}, ClockEstimate{Offset: 2 * time.Second, NumPeers: 2}, 0)
+		_, tooBig = c.Skew(0)
+		require.False(t, tooBig)
+	})
+	t.Run("dynamic peers do not halt", func(t *testing.T) {
+		c := newTestClockTracker(true)
+		// many dynamic peers agree on the big offset, but they are not trusted
+		correction, changed := c.Update(ClockEstimate{Offset: time.Hour, NumPeers: 100}, ClockEstimate{Offset: time.Hour, NumPeers: 1}, 0)
+		require.False(t, changed)
+		require.EqualValues(t, 0, correction)
+		skew, tooBig := c.Skew(0)
+		require.EqualValues(t, 0, skew)
+		require.False(t, tooBig)
+		require.EqualValues(t, time.Hour, c.Offset())
+
+		c.Update(ClockEstimate{Offset: time.Hour, NumPeers: 100}, ClockEstimate{Offset: 10 * time.Millisecond, NumPeers: 3}, 0)
+		_, tooBig = c.Skew(0)
+		require.False(t, tooBig)
+	})
+	t.Run("correction disabled", func(t *testing.T) {
+		c := newTestClockTracker(false)
+		_, changed := c.Update(ClockEstimate{}, ClockEstimate{Offset: 500 * time.Millisecond, NumPeers: 2}, 0)
+		require.False(t, changed)
+	})
+	t.Run("correction", func(t *testing.T) {
+		c := newTestClockTracker(true)
+		static := ClockEstimate{Offset: 500 * time.Millisecond, NumPeers: 2}
+		correction, changed := c.Update(ClockEstimate{}, static, 0)
+		require.True(t, changed)
+		require.EqualValues(t, -500*time.Millisecond, correction)
+		skew, tooBig := c.Skew(correction)
+		require.EqualValues(t, 0, skew)
+		require.False(t, tooBig)
+		// same estimate, nothing to change
+		_, changed = c.Update(ClockEstimate{}, static, correction)
+		require.False(t, changed)
+	})
+	t.Run("bounded correction", func(t *testing.T) {
+		c := newTestClockTracker(true)
+		correction, changed := c.Update(ClockEstimate{}, ClockEstimate{Offset: -5 * time.Second, NumPeers: 2}, 0)
+		require.True(t, changed)
+		require.EqualValues(t, time.Second, correction)
+		// the rest of the skew stops the sequencer
+		skew, tooBig := c.Skew(correction)
+		require.EqualValues(t, -4*time.Second, skew)
+		require.True(t, tooBig)
+	})
+}
+
+func TestAnnounceMsg(t *testing.T) {
+	t.Run("announce", func(t *testing.T) {
+		txids := make([]base.TransactionID, maxAnnounceBatchSize+1)
+		for i := range txids {
+			txids[i] = base.RandomTransactionID(i%2 == 0, 2)
+		}
+		msgs := encodeAnnounceMsgs(txids)
+		require.EqualValues(t, 2, len(msgs))
+
+		decoded := make([]base.TransactionID, 0)
+		for _, msgData := range msgs {
+			d, err := decodeAnnounceMsg(msgData)
+			require.NoError(t, err)
+			decoded = append(decoded, d...)
+		}
+		require.EqualValues(t, txids, decoded)
+
+		_, err := decodeAnnounceMsg(msgs[1][:len(msgs[1])-1])
+		require.Error(t, err)
+	})
+	t.Run("compression", func(t *testing.T) {
+		txid := base.RandomTransactionID(true, 1)
+		msgBytes := append(txid[:], bytes.Repeat([]byte("transaction bytes "), 100)...)
+		compressed := encodeCompressedTxMsg(msgBytes)
+		require.True(t, len(compressed) > 0 && len(compressed) < len(msgBytes))
+		require.EqualValues(t, CompressedTransaction, compressed[0])
+
+		back, err := decompressTxMsg(compressed[1:])
+		require.NoError(t, err)
+		require.EqualValues(t, msgBytes, back)
+
+		// incompressible message is sent as is
+		random := make([]byte, 500)
+		_, _ = rand.Read(random)
+		require.Nil(t, encodeCompressedTxMsg(random))
+
+		// decompressed message cannot exceed maximum payload size
+		tooLong := encodeCompressedTxMsg(make([]byte, MaxPayloadSize+1))
+		require.NotNil(t, tooLong)
+		_, err = decompressTxMsg(tooLong[1:])
+		require.Error(t, err)
+	})
+	t.Run("heartbeat flags", func(t *testing.T) {
+		hb := heartbeatInfo{clock: time.Unix(0, 1000), counter: 5, supportsAnnounce: true, supportsCompression: true}
+		hbBack, err := heartbeatInfoFromBytes(hb.Bytes())
+		require.NoError(t, err)
+		require.EqualValues(t, hb, hbBack)
+
+		hb.supportsCompression = false
+		hbBack, err = heartbeatInfoFromBytes(hb.Bytes())
+		require.NoError(t, err)
+		require.True(t, hbBack.supportsAnnounce)
+		require.False(t, hbBack.supportsCompression)
+	})
+}
+
+func TestClockOffset(t *testing.T) {
+	require.EqualValues(t, 0, weightedMedian(nil))
+	samples := []clockSample{
+		{diff: 5 * time.Second, weight: 1},
+		{diff: 10 * time.Millisecond, weight: staticPeerClockWeight},
+		{diff: -time.Second, weight: 1},
+	}
+	require.EqualValues(t, 10*time.Millisecond, weightedMedian(samples))
+	// static peer outweighs dynamic peers
+	samples = []clockSample{
+		{diff: 2 * time.Second, weight: 1},
+		{diff: 3 * time.Second, weight: 1},
+		{diff: 0, weight: staticPeerClockWeight},
+	}
+	require.EqualValues(t, 0, weightedMedian(samples))
+	samples = []clockSample{
+		{diff: time.Second, weight: 1},
+		{diff: 3 * time.Second, weight: 1},
+	}
+	require.EqualValues(t, 2*time.Second, weightedMedian(samples))
+}
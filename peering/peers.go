@@ -1,8 +1,10 @@
 package peering
 
 import (
+	"bytes"
 	"context"
 	"encoding/binary"
+	"encoding/hex"
 	"fmt"
 	"time"
 
@@ -36,10 +38,13 @@ func NewPeersDummy() *Peers {
 	ret := &Peers{
 		peers:           make(map[peer.ID]*Peer),
 		blacklist:       make(map[peer.ID]_deadlineWithReason),
+		bans:            make(map[peer.ID]banRecord),
 		cooloffList:     make(map[peer.ID]time.Time),
 		onReceiveTx:     func(_ peer.ID, _ []byte, _ *txmetadata.TransactionMetadata, _ base.TransactionID) {},
 		onReceivePullTx: func(_ peer.ID, _ base.TransactionID) {},
+		isKnownTx:       func(_ base.TransactionID) bool { return false },
 	}
+	ret.initBatchers()
 	//ret.registerMetrics()
 	return ret
 }
@@ -79,7 +84,7 @@ func New(env environment, cfg *Config) (*Peers, error) {
 		return nil, fmt.Errorf("unable create libp2p host: %w", err)
 	}
 
-	ledgerLibraryHash := ledger.L().Library.LibraryHash()
+	ledgerLibraryHash := ledger.L().LedgerIDHash()
 	rendezvousNumber := binary.BigEndian.Uint64(ledgerLibraryHash[:8])
 
 	ret := &Peers{
@@ -89,17 +94,25 @@ func New(env environment, cfg *Config) (*Peers, error) {
 		peers:                make(map[peer.ID]*Peer),
 		staticPeers:          make(map[peer.ID]*staticPeerInfo),
 		blacklist:            make(map[peer.ID]_deadlineWithReason),
+		bans:                 make(map[peer.ID]banRecord),
 		cooloffList:          make(map[peer.ID]time.Time),
 		connectList:          set.New[peer.ID](),
 		onReceiveTx:          func(_ peer.ID, _ []byte, _ *txmetadata.TransactionMetadata, _ base.TransactionID) {},
 		onReceivePullTx:      func(_ peer.ID, _ base.TransactionID) {},
+		isKnownTx:            func(_ base.TransactionID) bool { return false },
 		lppProtocolGossip:    protocol.ID(fmt.Sprintf(lppProtocolGossip, rendezvousNumber)),
 		lppProtocolPull:      protocol.ID(fmt.Sprintf(lppProtocolPull, rendezvousNumber)),
 		lppProtocolHeartbeat: protocol.ID(fmt.Sprintf(lppProtocolHeartbeat, rendezvousNumber)),
+		lppProtocolAnnounce:  protocol.ID(fmt.Sprintf(lppProtocolAnnounce, rendezvousNumber)),
 		rendezvousString:     fmt.Sprintf("%d", rendezvousNumber),
+		upgradesHash:         activeUpgradesHash,
 	}
+	ret.initBatchers()
 
 	env.Log().Infof("[peering] rendezvous number is %d", rendezvousNumber)
+	if h := ret.upgradesHash(); h != [32]byte{} {
+		env.Log().Infof("[peering] hash of active ledger library upgrades is %s", hex.EncodeToString(h[:]))
+	}
 	for name, maddr := range cfg.PreConfiguredPeers {
 		if err = ret.addStaticPeer(maddr.Multiaddr, name, maddr.addrString); err != nil {
 			return nil, err
@@ -133,6 +146,7 @@ func New(env environment, cfg *Config) (*Peers, error) {
 	}
 	env.Log().Infof("[peering] ignore all pull requests: %v", cfg.IgnoreAllPullRequests)
 	env.Log().Infof("[peering] only pull requests from static peers are accepted: %v", cfg.AcceptPullRequestsFromStaticPeersOnly)
+	env.Log().Infof("[peering] gossip announce mode: %v, compression: %v", cfg.Announce, cfg.Compression)
 
 	ret.registerMetrics()
 
@@ -173,6 +187,7 @@ func (ps *Peers) Run() {
 	ps.host.SetStreamHandler(ps.lppProtocolGossip, ps.gossipStreamHandler)
 	ps.host.SetStreamHandler(ps.lppProtocolPull, ps.pullStreamHandler)
 	ps.host.SetStreamHandler(ps.lppProtocolHeartbeat, ps.heartbeatStreamHandler)
+	ps.host.SetStreamHandler(ps.lppProtocolAnnounce, ps.announceStreamHandler)
 
 	//ps.startHeartbeat()
 	var logNumPeersDeadline time.Time
@@ -227,6 +242,16 @@ func (ps *Peers) Run() {
 		return true
 	})
 
+	ps.RepeatInBackground(Name+"_check_reputations", checkReputationEvery, func() bool {
+		ps.checkReputations()
+		return true
+	})
+
+	ps.RepeatInBackground(Name+"_announce_cleanup", announceRequestTTL, func() bool {
+		ps.cleanAnnounceRequests()
+		return true
+	})
+
 	ps.RepeatInBackground(Name+"_adjust_ranks", 500*time.Millisecond, func() bool {
 		ps.adjustRanks()
 		return true
@@ -281,6 +306,10 @@ func (ps *Peers) addStaticPeer(maddr multiaddr.Multiaddr, name, addrString strin
 	}
 	ps.Log().Infof("[peering] added pre-configured peer %s as '%s'", addrString, name)
 	ps.addPeer(info, name, true)
+
+	ps.mutex.Lock()
+	defer ps.mutex.Unlock()
+
 	_, found := ps.staticPeers[info.ID]
 	if !found {
 		ps.staticPeers[info.ID] = &staticPeerInfo{
@@ -311,7 +340,7 @@ func (ps *Peers) NewStream(peerID peer.ID, pID protocol.ID, timeout time.Duratio
 	stream, err := ps.host.NewStream(ctx, peerID, pID)
 	if err == nil {
 		// force the start of the streamHandler on the peer to avoid the stream reset error
-		err = writeFrame(stream, []byte("Start"))
+		err = writeFrame(stream, startMessage(ps.upgradesHash()))
 		if err != nil {
 			_ = stream.Close()
 			return nil, err
@@ -321,6 +350,44 @@ func (ps *Peers) NewStream(peerID peer.ID, pID protocol.ID, timeout time.Duratio
 	return stream, err
 }
 
+const (
+	startMessagePrefix = "Start"
+	// library versions which activate within the horizon are included into the hash in the start message,
+	// so that peers with different clocks agree on the versions near the activation slot
+	upgradesHashHorizonSlots = 3
+)
+
+// activeUpgradesHash is the hash of ledger library versions active at the current slot plus horizon.
+// Versions scheduled later are not compared
+func activeUpgradesHash() [32]byte {
+	return ledger.L().UpgradesHashAt(ledger.TimeNow().Slot + upgradesHashHorizonSlots)
+}
+
+// startMessage is the first frame of each stream. It contains hash of the active ledger library upgrades,
+// if there are any, so that nodes which would validate transactions with different library versions do not become peers
+func startMessage(upgradesHash [32]byte) []byte {
+	if upgradesHash == [32]byte{} {
+		return []byte(startMessagePrefix)
+	}
+	return append([]byte(startMessagePrefix), upgradesHash[:]...)
+}
+
+// upgradesHashFromStartMessage returns hash of the active ledger library upgrades of the peer.
+// Start message without the hash means there are no upgrades
+func upgradesHashFromStartMessage(data []byte) (ret [32]byte, err error) {
+	if !bytes.HasPrefix(data, []byte(startMessagePrefix)) {
+		return ret, fmt.Errorf("wrong start message")
+	}
+	switch h := data[len(startMessagePrefix):]; len(h) {
+	case 0:
+	case 32:
+		copy(ret[:], h)
+	default:
+		return ret, fmt.Errorf("wrong start message length %d", len(data))
+	}
+	return ret, nil
+}
+
 func (ps *Peers) dialPeer(peerID peer.ID, peer *Peer) error {
 	timeout := 15 * time.Second
 
@@ -358,8 +425,24 @@ func (ps *Peers) dialPeer(peerID peer.ID, peer *Peer) error {
 	peer.streams[ps.lppProtocolGossip] = &peerStream{
 		stream: stream,
 	}
+	// announce protocol is optional. Peers with older versions do not support it
+	stream, err = ps.NewStream(peerID, ps.lppProtocolAnnounce, timeout)
+	if err != nil {
+		ps.Log().Infof("[peering] peer %s does not support announce protocol: %v", ShortPeerIDString(peerID), err)
+		return nil
+	}
+	peer.streams[ps.lppProtocolAnnounce] = &peerStream{
+		stream: stream,
+	}
+	return nil
+}
 
-	return err
+func (ps *Peers) initBatchers() {
+	ps.pullBatch = newTxIDBatcher(pullBatchDelay, maxPullBatchSize, func(id peer.ID, txids []base.TransactionID) {
+		ps.sendPullBatchToPeer(id, &PullBatch{TxIDs: txids})
+	})
+	ps.announceBatch = newTxIDBatcher(announceBatchDelay, maxAnnounceBatchSize, ps.sendAnnouncement)
+	ps.announceRequests.requested = make(map[base.TransactionID]time.Time)
 }
 
 func (ps *Peers) _addPeer(addrInfo *peer.AddrInfo, name string, static bool) *Peer {
@@ -429,6 +512,7 @@ func (ps *Peers) _dropPeer(p *Peer, reason string, blacklist bool) {
 	}
 	_ = ps.host.Network().ClosePeer(p.id)
 	delete(ps.peers, p.id)
+	ps.deleteTrafficMetrics(p.id)
 
 	if blacklist {
 		ps._addToBlacklist(p.id, "")
@@ -440,10 +524,14 @@ func (ps *Peers) _dropPeer(p *Peer, reason string, blacklist bool) {
 }
 
 func (ps *Peers) _addToBlacklist(id peer.ID, reason string) {
-	ps.Tracef(TraceTagPeeringPeers, "[peering] add to blacklist peer %s", ShortPeerIDString(id))
+	ps._addToBlacklistFor(id, reason, time.Duration(ps.cfg.BlacklistTTL))
+}
+
+func (ps *Peers) _addToBlacklistFor(id peer.ID, reason string, ttl time.Duration) {
+	ps.Tracef(TraceTagPeeringPeers, "[peering] add to blacklist peer %s for %v", ShortPeerIDString(id), ttl)
 	ps._removeFromCoolOffList(id)
 	ps.blacklist[id] = _deadlineWithReason{
-		Time:   time.Now().Add(time.Duration(ps.cfg.BlacklistTTL)),
+		Time:   time.Now().Add(ttl),
 		reason: reason,
 	}
 }
@@ -511,6 +599,15 @@ func (ps *Peers) OnReceivePullTxRequest(fun func(from peer.ID, txid base.Transac
 	ps.onReceivePullTx = fun
 }
 
+// OnReceivePullTxBatchRequest sets handler of batched pull requests. If not set, transactions of
+// the batch are passed to the handler set by OnReceivePullTxRequest one by one and past cone is ignored
+func (ps *Peers) OnReceivePullTxBatchRequest(fun func(from peer.ID, batch *PullBatch)) {
+	ps.mutex.Lock()
+	defer ps.mutex.Unlock()
+
+	ps.onReceivePullTxBatch = fun
+}
+
 func (ps *Peers) _getPeer(id peer.ID) *Peer {
 	if ret, ok := ps.peers[id]; ok {
 		return ret
@@ -596,15 +693,16 @@ func (ps *Peers) cleanCoolofflist() {
 			toDelete = append(toDelete, id)
 		}
 	}
+	toReconnect := make([]*staticPeerInfo, 0)
 	for _, id := range toDelete {
 		delete(ps.cooloffList, id)
+		if p, static := ps.staticPeers[id]; static {
+			toReconnect = append(toReconnect, p)
+		}
 	}
 	ps.mutex.Unlock()
-	for _, id := range toDelete {
-		p, static := ps.staticPeers[id]
-		if static {
-			_ = ps.addStaticPeer(p.maddr, p.name, p.addrString)
-		}
+	for _, p := range toReconnect {
+		_ = ps.addStaticPeer(p.maddr, p.name, p.addrString)
 	}
 }
 
@@ -686,6 +784,7 @@ func (ps *Peers) sendMsgBytesOut(peerID peer.ID, protocolID protocol.ID, data []
 	}
 
 	ps.outMsgCounter.Inc()
+	ps.evidenceOutbound(peerID, ps.protocolName(protocolID), len(data))
 	return true
 }
 
@@ -722,6 +821,9 @@ func (ps *Peers) GetPeersInfo() *api.PeersInfo {
 			ID:                        p.id.String(),
 			IsStatic:                  p.isStatic,
 			RespondsToPull:            p.respondsToPullRequests,
+			SupportsPullBatch:         p.supportsPullBatch,
+			SupportsAnnounce:          p.supportsAnnounce,
+			SupportsCompression:       p.supportsCompression,
 			IsAlive:                   p._isAlive(),
 			WhenAdded:                 p.whenAdded.UnixNano(),
 			LastHeartbeatReceived:     p.lastHeartbeatReceived.UnixNano(),
@@ -730,6 +832,10 @@ func (ps *Peers) GetPeersInfo() *api.PeersInfo {
 			NumIncomingHB:             p.numIncomingHB,
 			NumIncomingPull:           p.numIncomingPull,
 			NumIncomingTx:             p.numIncomingTx,
+			Reputation:                ps._reputationInfo(p),
+			BytesIn:                   p.bytesIn,
+			BytesOut:                  p.bytesOut,
+			NumThrottled:              p.numThrottled,
 		}
 		pi.MultiAddresses = make([]string, 0)
 		for _, ma := range ps.host.Peerstore().Addrs(p.id) {
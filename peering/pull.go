@@ -2,7 +2,10 @@ package peering
 
 import (
 	"bytes"
+	"encoding/binary"
 	"fmt"
+	"sync"
+	"time"
 
 	"github.com/libp2p/go-libp2p/core/network"
 	"github.com/libp2p/go-libp2p/core/peer"
@@ -12,7 +15,91 @@ import (
 
 // pull request message 1st byte is the type of the message. The rest is message body
 
-const PullTransactions = byte(iota)
+const (
+	// PullTransactions requests one transaction
+	PullTransactions = byte(iota)
+	// PullTransactionsBatch requests many transactions at once, optionally with past cones.
+	// Sent only to peers which announce flagSupportsPullBatch in heartbeat messages
+	PullTransactionsBatch
+)
+
+// pullBatchVersion is the version of the PullTransactionsBatch message body. Body of the version 1:
+// - version byte
+// - flags byte
+// - 4 bytes of slot, only if pullBatchFlagPastCone is set
+// - 2 bytes of number of transaction IDs, followed by transaction IDs
+const pullBatchVersion = byte(1)
+
+const (
+	// pullBatchFlagPastCone requests past cones of transactions down to the slot
+	pullBatchFlagPastCone = byte(0b00000001)
+)
+
+const (
+	// maxPullBatchSize maximum number of transaction IDs in one batch message
+	maxPullBatchSize = 256
+	// pullBatchDelay is how long pull requests to the same peer are collected before sending them in one message
+	pullBatchDelay = 10 * time.Millisecond
+)
+
+type (
+	// PullBatch is a request to pull many transactions at once
+	PullBatch struct {
+		TxIDs []base.TransactionID
+		// if true, past cones of transactions down to the PastConeDownToSlot (inclusive) are requested too
+		WithPastCone       bool
+		PastConeDownToSlot base.Slot
+	}
+
+	// txidBatcher collects transaction IDs to be sent to peers. IDs for the same peer are sent
+	// in one message after delay or as soon as there are maxSize of them
+	txidBatcher struct {
+		mutex   sync.Mutex
+		pending map[peer.ID][]base.TransactionID
+		delay   time.Duration
+		maxSize int
+		send    func(id peer.ID, txids []base.TransactionID)
+	}
+)
+
+func newTxIDBatcher(delay time.Duration, maxSize int, send func(id peer.ID, txids []base.TransactionID)) *txidBatcher {
+	return &txidBatcher{
+		pending: make(map[peer.ID][]base.TransactionID),
+		delay:   delay,
+		maxSize: maxSize,
+		send:    send,
+	}
+}
+
+func (b *txidBatcher) add(id peer.ID, txid base.TransactionID) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	pending := b.pending[id]
+	if len(pending) == 0 {
+		time.AfterFunc(b.delay, func() {
+			b.flush(id)
+		})
+	}
+	pending = append(pending, txid)
+	if len(pending) < b.maxSize {
+		b.pending[id] = pending
+		return
+	}
+	delete(b.pending, id)
+	go b.send(id, pending)
+}
+
+func (b *txidBatcher) flush(id peer.ID) {
+	b.mutex.Lock()
+	pending := b.pending[id]
+	delete(b.pending, id)
+	b.mutex.Unlock()
+
+	if len(pending) > 0 {
+		b.send(id, pending)
+	}
+}
 
 func (ps *Peers) pullStreamHandler(stream network.Stream) {
 	defer func() {
@@ -73,21 +160,37 @@ func (ps *Peers) pullStreamHandler(stream network.Stream) {
 		case len(msgData) == 0:
 			ps.Log().Errorf("pull: error while reading message from peer %s: empty data", id.String())
 			return
-		case msgData[0] != PullTransactions:
-			ps.Log().Errorf("pull: wrong msg type '%d'", msgData[0])
-			return
 		}
+		if !ps.throttleInbound(id, protocolNamePull, len(msgData)) {
+			continue
+		}
+
+		switch msgData[0] {
+		case PullTransactions:
+			var txid base.TransactionID
+			txid, err = decodePullTransactionMsg(msgData)
+			if err != nil {
+				ps.Log().Errorf("pull: error while decoding message: %v", err)
+				return
+			}
+			go ps.onReceivePullTx(id, txid)
 
-		var txid base.TransactionID
-		txid, err = decodePullTransactionMsg(msgData)
-		if err != nil {
-			ps.Log().Errorf("pull: error while decoding message: %v", err)
+		case PullTransactionsBatch:
+			var batch *PullBatch
+			batch, err = decodePullTransactionsBatchMsg(msgData)
+			if err != nil {
+				// may be a newer version of the message. Ignore it, but keep the stream
+				ps.Log().Warnf("pull: error while decoding batch message from peer %s: %v", ShortPeerIDString(id), err)
+				continue
+			}
+			go ps.onReceivePullBatch(id, batch)
+
+		default:
+			ps.Log().Errorf("pull: wrong msg type '%d'", msgData[0])
 			return
 		}
 
 		ps.evidenceMessage()
-
-		go ps.onReceivePullTx(id, txid)
 		ps.pullRequestsIn.Inc()
 
 		// return buffer for reuse
@@ -95,23 +198,93 @@ func (ps *Peers) pullStreamHandler(stream network.Stream) {
 	}
 }
 
+// onReceivePullBatch calls batch handler if set, otherwise handles the batch as separate pull requests
+func (ps *Peers) onReceivePullBatch(from peer.ID, batch *PullBatch) {
+	ps.mutex.RLock()
+	onBatch, onSingle := ps.onReceivePullTxBatch, ps.onReceivePullTx
+	ps.mutex.RUnlock()
+
+	if onBatch != nil {
+		onBatch(from, batch)
+		return
+	}
+	for _, txid := range batch.TxIDs {
+		onSingle(from, txid)
+	}
+}
+
 func (ps *Peers) sendPullTransactionToPeers(ids []peer.ID, txid base.TransactionID) {
 	msg := _pullTransaction{
 		txid: txid,
 	}
 	ps.sendMsgBytesOutMulti(ids, ps.lppProtocolPull, msg.Bytes())
+	ps.pullRequestsOut.Add(float64(len(ids)))
+	for _, id := range ids {
+		ps.evidencePullSent(id, txid)
+	}
+}
+
+// sendPullBatchToPeer sends batch to the peer. If the peer does not support batches, sends separate
+// pull requests for each transaction and past cone is not requested
+func (ps *Peers) sendPullBatchToPeer(id peer.ID, batch *PullBatch) {
+	if !ps.supportsPullBatch(id) {
+		for _, txid := range batch.TxIDs {
+			ps.sendPullTransactionToPeers([]peer.ID{id}, txid)
+		}
+		return
+	}
+	for _, msgData := range encodePullTransactionsBatchMsgs(batch) {
+		go ps.sendMsgBytesOut(id, ps.lppProtocolPull, msgData)
+		ps.pullRequestsOut.Inc()
+	}
+	ps.evidencePullSent(id, batch.TxIDs...)
 }
 
 // PullTransactionsFromNPeers sends pull request to the random peers which has txStore
-// Return number of peer pull request was sent to
+// Return number of peer pull request was sent to.
+// Requests to peers which support batched pull are delayed for pullBatchDelay and sent together
+// with other requests to the same peer in one message
 func (ps *Peers) PullTransactionsFromNPeers(nPeers int, txid base.TransactionID) int {
 	util.Assertf(nPeers >= 1, "nPeers")
 
 	targets := ps.chooseNPullTargets(nPeers)
-	ps.sendPullTransactionToPeers(targets, txid)
+	for _, id := range targets {
+		if ps.supportsPullBatch(id) {
+			ps.pullBatch.add(id, txid)
+		} else {
+			ps.sendPullTransactionToPeers([]peer.ID{id}, txid)
+		}
+	}
+	return len(targets)
+}
+
+// PullTransactionsBatchFromNPeers sends batch pull request for many transactions to the random peers which has txStore.
+// If pastConeDownToSlot is provided, past cones of the transactions down to the slot are requested too.
+// Return number of peer pull request was sent to
+func (ps *Peers) PullTransactionsBatchFromNPeers(nPeers int, txids []base.TransactionID, pastConeDownToSlot ...base.Slot) int {
+	util.Assertf(nPeers >= 1, "nPeers")
+	if len(txids) == 0 {
+		return 0
+	}
+	batch := &PullBatch{TxIDs: txids}
+	if len(pastConeDownToSlot) > 0 {
+		batch.WithPastCone = true
+		batch.PastConeDownToSlot = pastConeDownToSlot[0]
+	}
+	targets := ps.chooseNPullTargets(nPeers)
+	for _, id := range targets {
+		go ps.sendPullBatchToPeer(id, batch)
+	}
 	return len(targets)
 }
 
+func (ps *Peers) supportsPullBatch(id peer.ID) (ret bool) {
+	ps.withPeer(id, func(p *Peer) {
+		ret = p != nil && p.supportsPullBatch
+	})
+	return
+}
+
 func encodePullTransactionMsg(txid base.TransactionID) []byte {
 	var buf bytes.Buffer
 	// write request type byte
@@ -127,6 +300,65 @@ func decodePullTransactionMsg(data []byte) (base.TransactionID, error) {
 	return base.TransactionIDFromBytes(data[1:])
 }
 
+// encodePullTransactionsBatchMsgs encodes batch into one or more messages of at most maxPullBatchSize transaction IDs each
+func encodePullTransactionsBatchMsgs(batch *PullBatch) [][]byte {
+	ret := make([][]byte, 0, len(batch.TxIDs)/maxPullBatchSize+1)
+	for start := 0; start < len(batch.TxIDs); start += maxPullBatchSize {
+		chunk := batch.TxIDs[start:min(start+maxPullBatchSize, len(batch.TxIDs))]
+		var buf bytes.Buffer
+		buf.WriteByte(PullTransactionsBatch)
+		buf.WriteByte(pullBatchVersion)
+		if batch.WithPastCone {
+			buf.WriteByte(pullBatchFlagPastCone)
+			buf.Write(batch.PastConeDownToSlot.Bytes())
+		} else {
+			buf.WriteByte(0)
+		}
+		_ = binary.Write(&buf, binary.BigEndian, uint16(len(chunk)))
+		for i := range chunk {
+			buf.Write(chunk[i][:])
+		}
+		ret = append(ret, buf.Bytes())
+	}
+	return ret
+}
+
+func decodePullTransactionsBatchMsg(data []byte) (*PullBatch, error) {
+	if len(data) < 3 || data[0] != PullTransactionsBatch {
+		return nil, fmt.Errorf("not a pull transactions batch message")
+	}
+	if data[1] != pullBatchVersion {
+		return nil, fmt.Errorf("unsupported version %d of the pull transactions batch message", data[1])
+	}
+	ret := &PullBatch{}
+	flags := data[2]
+	data = data[3:]
+	if flags&pullBatchFlagPastCone != 0 {
+		if len(data) < 4 {
+			return nil, fmt.Errorf("wrong pull transactions batch message: can't read slot")
+		}
+		ret.WithPastCone = true
+		ret.PastConeDownToSlot = base.Slot(binary.BigEndian.Uint32(data[:4]))
+		data = data[4:]
+	}
+	if len(data) < 2 {
+		return nil, fmt.Errorf("wrong pull transactions batch message: can't read number of transaction IDs")
+	}
+	n := int(binary.BigEndian.Uint16(data[:2]))
+	data = data[2:]
+	if n == 0 || n > maxPullBatchSize || len(data) != n*base.TransactionIDLength {
+		return nil, fmt.Errorf("wrong pull transactions batch message: wrong number of transaction IDs")
+	}
+	var err error
+	ret.TxIDs = make([]base.TransactionID, n)
+	for i := range ret.TxIDs {
+		if ret.TxIDs[i], err = base.TransactionIDFromBytes(data[i*base.TransactionIDLength : (i+1)*base.TransactionIDLength]); err != nil {
+			return nil, err
+		}
+	}
+	return ret, nil
+}
+
 func (ps *Peers) _isPullTarget(p *Peer) bool {
 	return p.respondsToPullRequests || ps.cfg.ForcePullFromAllPeers
 }
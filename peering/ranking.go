@@ -30,10 +30,17 @@ func (ps *Peers) adjustRanks() {
 	for i, p := range sorted {
 		p.rankByClockDifference = i
 	}
+	// by reputation score. Score is counted twice, because it includes clock differences too
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].score() < sorted[j].score()
+	})
+	for i, p := range sorted {
+		p.rankByScore = 2 * i
+	}
 }
 
 func (p *Peer) rank() int {
-	return p.rankByLastHBReceived + p.rankByClockDifference
+	return p.rankByLastHBReceived + p.rankByClockDifference + p.rankByScore
 }
 
 func (ps *Peers) _pullTargets() []*Peer {
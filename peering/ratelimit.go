@@ -0,0 +1,290 @@
+package peering
+
+import (
+	"time"
+
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/libp2p/go-libp2p/core/protocol"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/spf13/viper"
+)
+
+// per peer limits of incoming messages and accounting of the traffic
+
+type (
+	// InboundLimit limits incoming messages from one peer on one protocol. Zero means unlimited
+	InboundLimit struct {
+		MsgPerSec   float64
+		BytesPerSec float64
+	}
+
+	// inboundLimiter token buckets of one peer and one protocol. Nil bucket means unlimited
+	inboundLimiter struct {
+		msgs  *rateBucket
+		bytes *rateBucket
+	}
+
+	// rateBucket is a token bucket with capacity of one second of the rate
+	rateBucket struct {
+		rate   float64
+		tokens float64
+		last   time.Time
+	}
+
+	trafficMetrics struct {
+		bytesIn   *prometheus.CounterVec
+		bytesOut  *prometheus.CounterVec
+		throttled *prometheus.CounterVec
+	}
+)
+
+// protocol names used in configuration and as metrics labels
+const (
+	protocolNameGossip    = "gossip"
+	protocolNamePull      = "pull"
+	protocolNameHeartbeat = "heartbeat"
+	protocolNameAnnounce  = "announce"
+	// protocolNamePullResponse limits bytes of transactions sent to the peer in response to its pull requests
+	protocolNamePullResponse = "pull_response"
+)
+
+const (
+	// maxBackpressureDelay limits delay of reading from the stream of one message
+	maxBackpressureDelay = 5 * time.Second
+	TraceTagRateLimit    = "peering_rate_limit"
+)
+
+// default limits are much above of what honest peers send in normal conditions, including sync
+var defaultInboundLimits = map[string]InboundLimit{
+	protocolNameGossip:    {MsgPerSec: 2000, BytesPerSec: 20 << 20},
+	protocolNamePull:      {MsgPerSec: 500, BytesPerSec: 1 << 20},
+	protocolNameHeartbeat: {MsgPerSec: 5, BytesPerSec: 1 << 10},
+	protocolNameAnnounce:  {MsgPerSec: 2000, BytesPerSec: 20 << 20},
+	// one pull request may be answered with the whole past cone of the transaction
+	protocolNamePullResponse: {BytesPerSec: 4 << 20},
+}
+
+func readInboundLimitsConfig(cfg *Config) {
+	cfg.InboundLimits = make(map[string]InboundLimit)
+	if viper.GetBool("peering.rate_limit.disable") {
+		return
+	}
+	for name, def := range defaultInboundLimits {
+		lim := def
+		key := "peering.rate_limit." + name
+		if viper.IsSet(key + ".msg_per_sec") {
+			lim.MsgPerSec = viper.GetFloat64(key + ".msg_per_sec")
+		}
+		if viper.IsSet(key + ".bytes_per_sec") {
+			lim.BytesPerSec = viper.GetFloat64(key + ".bytes_per_sec")
+		}
+		cfg.InboundLimits[name] = lim
+	}
+	cfg.DropOverLimit = viper.GetBool("peering.rate_limit.drop")
+}
+
+func newRateBucket(rate float64, now time.Time) *rateBucket {
+	if rate <= 0 {
+		return nil
+	}
+	return &rateBucket{rate: rate, tokens: rate, last: now}
+}
+
+func (b *rateBucket) refill(now time.Time) {
+	b.tokens = min(b.rate, b.tokens+now.Sub(b.last).Seconds()*b.rate)
+	b.last = now
+}
+
+// available checks if n tokens can be taken. Message bigger than capacity passes when the bucket is full
+func (b *rateBucket) available(n float64, now time.Time) bool {
+	if b == nil {
+		return true
+	}
+	b.refill(now)
+	return b.tokens >= min(n, b.rate)
+}
+
+func (b *rateBucket) take(n float64) {
+	if b != nil {
+		b.tokens -= min(n, b.rate)
+	}
+}
+
+// waitTime returns time until the bucket becomes non-negative
+func (b *rateBucket) waitTime() time.Duration {
+	if b == nil || b.tokens >= 0 {
+		return 0
+	}
+	return time.Duration(-b.tokens / b.rate * float64(time.Second))
+}
+
+func newInboundLimiter(lim InboundLimit, now time.Time) *inboundLimiter {
+	return &inboundLimiter{
+		msgs:  newRateBucket(lim.MsgPerSec, now),
+		bytes: newRateBucket(lim.BytesPerSec, now),
+	}
+}
+
+// allow takes tokens for the message if it is within limits. Used in the dropping mode
+func (l *inboundLimiter) allow(msgLen int, now time.Time) bool {
+	if !l.msgs.available(1, now) || !l.bytes.available(float64(msgLen), now) {
+		return false
+	}
+	l.msgs.take(1)
+	l.bytes.take(float64(msgLen))
+	return true
+}
+
+// reserve takes tokens for the message unconditionally and returns how long to wait
+// before reading the next message. Used in the backpressure mode
+func (l *inboundLimiter) reserve(msgLen int, now time.Time) time.Duration {
+	l.msgs.available(1, now)
+	l.bytes.available(float64(msgLen), now)
+	l.msgs.take(1)
+	l.bytes.take(float64(msgLen))
+	return min(max(l.msgs.waitTime(), l.bytes.waitTime()), maxBackpressureDelay)
+}
+
+// throttleInbound accounts incoming message from the peer and enforces limits of the protocol.
+// In the backpressure mode it delays the stream handler, so the peer cannot send faster than allowed.
+// In the dropping mode returns false if the message must be dropped
+func (ps *Peers) throttleInbound(id peer.ID, protocolName string, msgLen int) bool {
+	lim, limited := ps.cfg.InboundLimits[protocolName]
+	var wait time.Duration
+	pass := true
+	ps.withPeer(id, func(p *Peer) {
+		if p == nil {
+			return
+		}
+		p.bytesIn += uint64(msgLen)
+		if !limited {
+			return
+		}
+		nowis := time.Now()
+		if p.inboundLimiters == nil {
+			p.inboundLimiters = make(map[string]*inboundLimiter)
+		}
+		limiter, found := p.inboundLimiters[protocolName]
+		if !found {
+			limiter = newInboundLimiter(lim, nowis)
+			p.inboundLimiters[protocolName] = limiter
+		}
+		if ps.cfg.DropOverLimit {
+			pass = limiter.allow(msgLen, nowis)
+		} else {
+			wait = limiter.reserve(msgLen, nowis)
+		}
+		if !pass || wait > 0 {
+			p.numThrottled++
+		}
+	})
+	if ps.bytesIn != nil {
+		ps.bytesIn.WithLabelValues(id.String(), protocolName).Add(float64(msgLen))
+		if !pass || wait > 0 {
+			ps.throttled.WithLabelValues(id.String(), protocolName).Inc()
+		}
+	}
+	if !pass {
+		ps.Tracef(TraceTagRateLimit, "%s message from %s dropped: over the limit", protocolName, ShortPeerIDString(id))
+		return false
+	}
+	if wait > 0 {
+		ps.Tracef(TraceTagRateLimit, "%s message from %s: over the limit, wait %v", protocolName, ShortPeerIDString(id), wait)
+		select {
+		case <-ps.Ctx().Done():
+		case <-time.After(wait):
+		}
+	}
+	return true
+}
+
+// ChargePullResponse takes bytes of the response to the pull request of the peer from its pull response limit.
+// Returns false if the peer is over the limit and the rest of the response must not be sent.
+// The limit is always enforced by dropping, so a peer cannot make the node send more than allowed by requesting big past cones
+func (ps *Peers) ChargePullResponse(id peer.ID, nBytes int) bool {
+	if ps.cfg == nil {
+		// dummy peers
+		return true
+	}
+	lim, limited := ps.cfg.InboundLimits[protocolNamePullResponse]
+	if !limited {
+		return true
+	}
+	pass := true
+	ps.withPeer(id, func(p *Peer) {
+		if p == nil {
+			return
+		}
+		nowis := time.Now()
+		if p.inboundLimiters == nil {
+			p.inboundLimiters = make(map[string]*inboundLimiter)
+		}
+		limiter, found := p.inboundLimiters[protocolNamePullResponse]
+		if !found {
+			limiter = newInboundLimiter(lim, nowis)
+			p.inboundLimiters[protocolNamePullResponse] = limiter
+		}
+		if pass = limiter.allow(nBytes, nowis); !pass {
+			p.numThrottled++
+		}
+	})
+	if !pass {
+		if ps.throttled != nil {
+			ps.throttled.WithLabelValues(id.String(), protocolNamePullResponse).Inc()
+		}
+		ps.Tracef(TraceTagRateLimit, "response to pull from %s cut: over the limit", ShortPeerIDString(id))
+	}
+	return pass
+}
+
+func (ps *Peers) evidenceOutbound(id peer.ID, protocolName string, msgLen int) {
+	ps.withPeer(id, func(p *Peer) {
+		if p != nil {
+			p.bytesOut += uint64(msgLen)
+		}
+	})
+	if ps.bytesOut != nil {
+		ps.bytesOut.WithLabelValues(id.String(), protocolName).Add(float64(msgLen))
+	}
+}
+
+func (ps *Peers) protocolName(protocolID protocol.ID) string {
+	switch protocolID {
+	case ps.lppProtocolGossip:
+		return protocolNameGossip
+	case ps.lppProtocolPull:
+		return protocolNamePull
+	case ps.lppProtocolHeartbeat:
+		return protocolNameHeartbeat
+	case ps.lppProtocolAnnounce:
+		return protocolNameAnnounce
+	}
+	return string(protocolID)
+}
+
+func (ps *Peers) registerTrafficMetrics() {
+	ps.bytesIn = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "proxima_peering_peer_bytes_in",
+		Help: "bytes received from the peer by protocol",
+	}, []string{"peer", "protocol"})
+	ps.bytesOut = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "proxima_peering_peer_bytes_out",
+		Help: "bytes sent to the peer by protocol",
+	}, []string{"peer", "protocol"})
+	ps.throttled = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Name: "proxima_peering_peer_throttled",
+		Help: "messages from the peer delayed or dropped because of rate limits, by protocol",
+	}, []string{"peer", "protocol"})
+	ps.MetricsRegistry().MustRegister(ps.bytesIn, ps.bytesOut, ps.throttled)
+}
+
+// deleteTrafficMetrics removes metrics of the dropped peer to keep number of time series bounded
+func (ps *Peers) deleteTrafficMetrics(id peer.ID) {
+	if ps.bytesIn == nil {
+		return
+	}
+	labels := prometheus.Labels{"peer": id.String()}
+	ps.bytesIn.DeletePartialMatch(labels)
+	ps.bytesOut.DeletePartialMatch(labels)
+	ps.throttled.DeletePartialMatch(labels)
+}
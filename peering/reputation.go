@@ -0,0 +1,234 @@
+package peering
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/lunfardo314/proxima/api"
+	"github.com/lunfardo314/proxima/ledger/base"
+)
+
+// Reputation of the peer consists of 3 components:
+//   - reliability: rewards for useful transactions and responses to pull requests, penalties for pull requests
+//     without response. It is bounded from below by minReliability, so an unreliable (flaky) peer is only less
+//     preferred as a pull target and, if dynamic, eventually dropped and replaced, but never banned
+//   - misbehaviour: penalties for invalid transactions and protocol violations. When it falls down to banThreshold,
+//     peer is banned for a period which doubles with each next ban
+//   - clock penalty: computed from the median of clock differences
+//
+// Reliability and misbehaviour decay towards 0 with reputationHalfLife, so the peer has a chance to improve
+
+type (
+	reputation struct {
+		reliability  float64
+		misbehaviour float64
+		lastUpdated  time.Time
+		// counters, for information
+		numUsefulTx      int
+		numInvalidTx     int
+		numViolations    int
+		numPullResponded int
+		numPullMissed    int
+		// pull requests waiting for response: txid -> deadline
+		pendingPulls map[base.TransactionID]time.Time
+	}
+
+	// banRecord survives the peer, so that bans of the returning peer grow longer
+	banRecord struct {
+		numBans int
+		lastBan time.Time
+	}
+)
+
+const (
+	reputationHalfLife = 10 * time.Minute
+
+	rewardUsefulTx       = 0.1
+	rewardPullResponse   = 0.5
+	penaltyPullMissed    = 1.0
+	penaltyInvalidTx     = 10.0
+	penaltyViolation     = 50.0
+	maxReliability       = 30.0
+	minReliability       = -30.0
+	maxClockPenalty      = 20.0
+	banThreshold         = -50.0
+	dropScoreThreshold   = -40.0
+	pullResponseTimeout  = 10 * time.Second
+	maxPendingPulls      = 10_000
+	maxBanTTL            = 24 * time.Hour
+	forgetBansAfter      = 24 * time.Hour
+	checkReputationEvery = time.Second
+)
+
+const TraceTagReputation = "peering_reputation"
+
+// decayed returns reputation components brought towards 0 according to the time passed since last update
+func (r *reputation) decayed(nowis time.Time) (reliability, misbehaviour float64) {
+	passed := nowis.Sub(r.lastUpdated)
+	if r.lastUpdated.IsZero() || passed <= 0 {
+		return r.reliability, r.misbehaviour
+	}
+	coeff := math.Pow(0.5, float64(passed)/float64(reputationHalfLife))
+	return r.reliability * coeff, r.misbehaviour * coeff
+}
+
+func (r *reputation) _decay(nowis time.Time) {
+	r.reliability, r.misbehaviour = r.decayed(nowis)
+	r.lastUpdated = nowis
+}
+
+func (r *reputation) _addReliability(delta float64) {
+	r._decay(time.Now())
+	r.reliability = min(max(r.reliability+delta, minReliability), maxReliability)
+}
+
+func (r *reputation) _addMisbehaviour(penalty float64) {
+	r._decay(time.Now())
+	r.misbehaviour -= penalty
+}
+
+// clockPenalty grows linearly with the median clock difference, reaching maxClockPenalty at 2 * clockTolerance
+func (p *Peer) clockPenalty() float64 {
+	median := p.clockDifferenceQuartiles[1]
+	if median < 0 {
+		median = -median
+	}
+	return min(maxClockPenalty, maxClockPenalty*float64(median)/float64(2*clockTolerance))
+}
+
+// score is the total reputation of the peer. 0 is neutral
+func (p *Peer) score() float64 {
+	reliability, misbehaviour := p.reputation.decayed(time.Now())
+	return reliability + misbehaviour - p.clockPenalty()
+}
+
+// EvidenceUsefulTx is called when new valid transaction is received from the peer
+func (ps *Peers) EvidenceUsefulTx(id peer.ID) {
+	ps.withPeer(id, func(p *Peer) {
+		if p != nil {
+			p.reputation.numUsefulTx++
+			p.reputation._addReliability(rewardUsefulTx)
+		}
+	})
+}
+
+// EvidenceInvalidTx is called when transaction received from the peer is invalid.
+// Honest peers only gossip pre-validated transactions
+func (ps *Peers) EvidenceInvalidTx(id peer.ID, reason string) {
+	ps.penalize(id, penaltyInvalidTx, fmt.Sprintf("invalid tx: %s", reason), func(r *reputation) {
+		r.numInvalidTx++
+	})
+}
+
+// evidenceProtocolViolation is called when message received from the peer cannot be parsed
+func (ps *Peers) evidenceProtocolViolation(id peer.ID, reason string) {
+	ps.penalize(id, penaltyViolation, reason, func(r *reputation) {
+		r.numViolations++
+	})
+}
+
+func (ps *Peers) penalize(id peer.ID, penalty float64, reason string, count func(r *reputation)) {
+	ps.mutex.Lock()
+	defer ps.mutex.Unlock()
+
+	p := ps._getPeer(id)
+	if p == nil {
+		return
+	}
+	count(&p.reputation)
+	p.reputation._addMisbehaviour(penalty)
+	ps.Tracef(TraceTagReputation, "peer %s penalized by %.1f: '%s'. Misbehaviour: %.1f", ShortPeerIDString(id), penalty, reason, p.reputation.misbehaviour)
+
+	if p.reputation.misbehaviour <= banThreshold {
+		ps._ban(p, reason)
+	}
+}
+
+// _ban drops the peer and blacklists it. Each next ban within forgetBansAfter is twice as long as the previous one
+func (ps *Peers) _ban(p *Peer, reason string) {
+	rec := ps.bans[p.id]
+	if time.Since(rec.lastBan) > forgetBansAfter {
+		rec = banRecord{}
+	}
+	ttl := min(time.Duration(ps.cfg.BlacklistTTL)<<rec.numBans, maxBanTTL)
+	rec.numBans++
+	rec.lastBan = time.Now()
+	ps.bans[p.id] = rec
+
+	ps.Log().Warnf("[peering] ban #%d of peer %s for %v. Reason: '%s'", rec.numBans, ShortPeerIDString(p.id), ttl, reason)
+	ps._dropPeer(p, reason, false)
+	ps._addToBlacklistFor(p.id, fmt.Sprintf("ban #%d for %v: %s", rec.numBans, ttl, reason), ttl)
+}
+
+// evidencePullSent registers pull request to the peer, so that response or its absence can be accounted
+func (ps *Peers) evidencePullSent(id peer.ID, txids ...base.TransactionID) {
+	deadline := time.Now().Add(pullResponseTimeout)
+	ps.withPeer(id, func(p *Peer) {
+		if p == nil {
+			return
+		}
+		if p.reputation.pendingPulls == nil {
+			p.reputation.pendingPulls = make(map[base.TransactionID]time.Time)
+		}
+		for _, txid := range txids {
+			if len(p.reputation.pendingPulls) >= maxPendingPulls {
+				return
+			}
+			p.reputation.pendingPulls[txid] = deadline
+		}
+	})
+}
+
+// evidenceTxReceived rewards the peer if the transaction was pulled from it
+func (ps *Peers) evidenceTxReceived(id peer.ID, txid base.TransactionID) {
+	ps.withPeer(id, func(p *Peer) {
+		if p == nil {
+			return
+		}
+		if _, pulled := p.reputation.pendingPulls[txid]; pulled {
+			delete(p.reputation.pendingPulls, txid)
+			p.reputation.numPullResponded++
+			p.reputation._addReliability(rewardPullResponse)
+		}
+	})
+}
+
+// checkReputations penalizes peers for expired pull requests and cleans old ban records
+func (ps *Peers) checkReputations() {
+	ps.mutex.Lock()
+	defer ps.mutex.Unlock()
+
+	nowis := time.Now()
+	for _, p := range ps.peers {
+		for txid, deadline := range p.reputation.pendingPulls {
+			if deadline.Before(nowis) {
+				delete(p.reputation.pendingPulls, txid)
+				p.reputation.numPullMissed++
+				p.reputation._addReliability(-penaltyPullMissed)
+			}
+		}
+	}
+	for id, rec := range ps.bans {
+		if nowis.Sub(rec.lastBan) > forgetBansAfter {
+			delete(ps.bans, id)
+		}
+	}
+}
+
+func (ps *Peers) _reputationInfo(p *Peer) *api.PeerReputation {
+	reliability, misbehaviour := p.reputation.decayed(time.Now())
+	return &api.PeerReputation{
+		Score:            p.score(),
+		Reliability:      reliability,
+		Misbehaviour:     misbehaviour,
+		ClockPenalty:     p.clockPenalty(),
+		NumUsefulTx:      p.reputation.numUsefulTx,
+		NumInvalidTx:     p.reputation.numInvalidTx,
+		NumViolations:    p.reputation.numViolations,
+		NumPullResponded: p.reputation.numPullResponded,
+		NumPullMissed:    p.reputation.numPullMissed,
+		NumBans:          ps.bans[p.id].numBans,
+	}
+}
@@ -40,12 +40,10 @@ func (ps *Peers) gossipStreamHandler(stream network.Stream) {
 		return
 	}
 
-	var txBytesWithMetadata, metadataBytes, txBytes []byte
-	var metadata *txmetadata.TransactionMetadata
-	var txIDPrefix base.TransactionID
+	var txMsg []byte
 
 	for {
-		txBytesWithMetadata, err = readFrame(stream)
+		txMsg, err = readFrame(stream)
 		ps.inMsgCounter.Inc()
 		_, blacklisted, _ = ps.knownPeer(id, func(p *Peer) {
 			p.numIncomingTx++
@@ -58,50 +56,70 @@ func (ps *Peers) gossipStreamHandler(stream network.Stream) {
 			ps.Log().Errorf("gossip: error while reading message from peer %s: %v", id.String(), err)
 			return
 		}
-		if len(txBytesWithMetadata) < base.TransactionIDLength {
-			// protocol violation
-			err = fmt.Errorf("gossip: wrong tx message from peer %s (txid prefix): at least 32 bytes expected", id.String())
-			ps.Log().Error(err)
-			ps.dropPeer(id, err.Error(), true)
-			return
-		}
-		txIDPrefix, err = base.TransactionIDFromBytes(txBytesWithMetadata[:base.TransactionIDLength])
-		if err != nil {
-			// protocol violation
-			err = fmt.Errorf("gossip: wrong tx message from peer (txid prefix) %s: %v", id.String(), err)
-			ps.Log().Error(err)
-			ps.dropPeer(id, err.Error(), true)
-			return
+		if !ps.throttleInbound(id, protocolNameGossip, len(txMsg)) {
+			continue
 		}
-		txBytesWithMetadata = txBytesWithMetadata[base.TransactionIDLength:]
-		metadataBytes, txBytes, err = txmetadata.SplitTxBytesWithMetadata(txBytesWithMetadata)
-		if err != nil {
+		if err = ps.processTxMessage(id, txMsg); err != nil {
 			// protocol violation
-			err = fmt.Errorf("gossip: error while parsing tx message from peer %s: %v", id.String(), err)
+			err = fmt.Errorf("gossip: %w", err)
 			ps.Log().Error(err)
-			ps.dropPeer(id, err.Error(), true)
-			return
-		}
-		metadata, err = txmetadata.TransactionMetadataFromBytes(metadataBytes)
-		if err != nil {
-			// protocol violation
-			err = fmt.Errorf("gossip: error while parsing tx message metadata from peer %s: %v", id.String(), err)
-			ps.Log().Error(err)
-			ps.dropPeer(id, err.Error(), true)
+			ps.evidenceProtocolViolation(id, err.Error())
 			return
 		}
+	}
+}
+
+// processTxMessage parses gossip message received from the peer and passes transaction to the handler
+func (ps *Peers) processTxMessage(id peer.ID, txMsg []byte) error {
+	if len(txMsg) < base.TransactionIDLength {
+		return fmt.Errorf("wrong tx message from peer %s (txid prefix): at least 32 bytes expected", id.String())
+	}
+	txIDPrefix, err := base.TransactionIDFromBytes(txMsg[:base.TransactionIDLength])
+	if err != nil {
+		return fmt.Errorf("wrong tx message from peer (txid prefix) %s: %v", id.String(), err)
+	}
+	txBytesWithMetadata := txMsg[base.TransactionIDLength:]
+	metadataBytes, txBytes, err := txmetadata.SplitTxBytesWithMetadata(txBytesWithMetadata)
+	if err != nil {
+		return fmt.Errorf("error while parsing tx message from peer %s: %v", id.String(), err)
+	}
+	metadata, err := txmetadata.TransactionMetadataFromBytes(metadataBytes)
+	if err != nil {
+		return fmt.Errorf("error while parsing tx message metadata from peer %s: %v", id.String(), err)
+	}
 
-		ps.evidenceMessage()
+	ps.evidenceMessage()
+	ps.evidenceTxReceived(id, txIDPrefix)
 
-		ps.transactionsReceivedCounter.Inc()
-		ps.txBytesReceivedCounter.Add(float64(len(txBytesWithMetadata)))
+	ps.transactionsReceivedCounter.Inc()
+	ps.txBytesReceivedCounter.Add(float64(len(txBytesWithMetadata)))
 
-		go ps.onReceiveTx(id, txBytes, metadata, txIDPrefix)
-	}
+	go ps.onReceiveTx(id, txBytes, metadata, txIDPrefix)
+	return nil
 }
 
+// GossipTxBytesToPeers sends transaction to all alive peers. In the announce mode, peers which support
+// it receive only transaction ID and request the transaction if they need it
 func (ps *Peers) GossipTxBytesToPeers(txBytes []byte, metadata *txmetadata.TransactionMetadata, txid base.TransactionID, except ...peer.ID) {
-	targets := ps.peerIDsAlive(except...)
+	targets := make([]peer.ID, 0)
+	announceTo := make([]peer.ID, 0)
+	ps.forEachPeerRLock(func(p *Peer) bool {
+		if len(except) > 0 && p.id == except[0] {
+			return true
+		}
+		if !p._isAlive() {
+			return true
+		}
+		if ps._canAnnounceTo(p) {
+			announceTo = append(announceTo, p.id)
+		} else {
+			targets = append(targets, p.id)
+		}
+		return true
+	})
+	for _, id := range announceTo {
+		ps.announceBatch.add(id, txid)
+	}
 	ps.sendTxBytesWithMetadataToPeers(targets, txBytes, metadata, txid)
 }
 
@@ -111,7 +129,21 @@ func (ps *Peers) sendTxBytesWithMetadataToPeers(ids []peer.ID, txBytes []byte, m
 		metadata: metadata,
 		txBytes:  txBytes,
 	}
-	ps.sendMsgBytesOutMulti(ids, ps.lppProtocolGossip, msg.Bytes())
+	msgBytes := msg.Bytes()
+	var compressed []byte
+	compressedDone := false
+	for _, id := range ids {
+		if ps.canCompressTo(id) {
+			if !compressedDone {
+				compressed, compressedDone = encodeCompressedTxMsg(msgBytes), true
+			}
+			if compressed != nil {
+				go ps.sendCompressedTxMsg(id, compressed, len(msgBytes))
+				continue
+			}
+		}
+		go ps.sendMsgBytesOut(id, ps.lppProtocolGossip, msgBytes)
+	}
 }
 
 func (ps *Peers) SendTxBytesWithMetadataToPeer(id peer.ID, txBytes []byte, metadata *txmetadata.TransactionMetadata, txid base.TransactionID) bool {
@@ -120,7 +152,23 @@ func (ps *Peers) SendTxBytesWithMetadataToPeer(id peer.ID, txBytes []byte, metad
 		metadata: metadata,
 		txBytes:  txBytes,
 	}
-	return ps.sendMsgBytesOut(id, ps.lppProtocolGossip, msg.Bytes())
+	msgBytes := msg.Bytes()
+	if ps.canCompressTo(id) {
+		if compressed := encodeCompressedTxMsg(msgBytes); compressed != nil {
+			return ps.sendCompressedTxMsg(id, compressed, len(msgBytes))
+		}
+	}
+	return ps.sendMsgBytesOut(id, ps.lppProtocolGossip, msgBytes)
+}
+
+func (ps *Peers) sendCompressedTxMsg(id peer.ID, compressed []byte, originalLen int) bool {
+	if !ps.sendMsgBytesOut(id, ps.lppProtocolAnnounce, compressed) {
+		return false
+	}
+	if ps.compressedSaved != nil {
+		ps.compressedSaved.Add(float64(originalLen - len(compressed)))
+	}
+	return true
 }
 
 // message wrapper
@@ -54,6 +54,16 @@ type (
 
 		// disable Quicreuse
 		DisableQuicreuse bool
+
+		// limits of incoming messages per peer by protocol name: 'gossip', 'pull', 'heartbeat', 'announce'. Missing means unlimited
+		InboundLimits map[string]InboundLimit
+		// if true, messages over the limit are dropped. Otherwise, reading from the peer is delayed (backpressure)
+		DropOverLimit bool
+
+		// if true, only IDs of new transactions are gossiped to peers which support it. Peers request transactions they did not see yet
+		Announce bool
+		// if true, transaction messages to peers which also enable compression are compressed
+		Compression bool
 	}
 
 	_multiaddr struct {
@@ -80,17 +90,30 @@ type (
 		staticPeers      map[peer.ID]*staticPeerInfo
 		lastMsgReceived  atomic.Int64
 		blacklist        map[peer.ID]_deadlineWithReason
+		bans             map[peer.ID]banRecord
 		cooloffList      map[peer.ID]time.Time
 		connectList      set.Set[peer.ID]
 
 		// on receive handlers
 		onReceiveTx     func(from peer.ID, txBytes []byte, mdata *txmetadata.TransactionMetadata, txIDPrefix base.TransactionID)
 		onReceivePullTx func(from peer.ID, txid base.TransactionID)
+		// if nil, batch pull requests are handled by onReceivePullTx one by one
+		onReceivePullTxBatch func(from peer.ID, batch *PullBatch)
+		// tells if the announced transaction is already known
+		isKnownTx func(txid base.TransactionID) bool
+		// pull requests waiting to be sent in batches
+		pullBatch *txidBatcher
+		// announcements waiting to be sent in batches
+		announceBatch    *txidBatcher
+		announceRequests announceRequests
 		// lpp protocol names
 		lppProtocolGossip    protocol.ID
 		lppProtocolPull      protocol.ID
 		lppProtocolHeartbeat protocol.ID
+		lppProtocolAnnounce  protocol.ID
 		rendezvousString     string
+		// hash of the ledger library versions active at the current slot plus horizon. Peers must have the same versions
+		upgradesHash func() [32]byte
 		metrics
 	}
 
@@ -117,6 +140,9 @@ type (
 		streams                map[protocol.ID]*peerStream
 		isStatic               bool // statically pre-configured (manual peering)
 		respondsToPullRequests bool // from hb info
+		supportsPullBatch      bool // from hb info
+		supportsAnnounce       bool // from hb info
+		supportsCompression    bool // from hb info
 		whenAdded              time.Time
 		lastHeartbeatReceived  time.Time
 		lastLoggedConnected    bool // toggle
@@ -131,12 +157,19 @@ type (
 		// ranks
 		rankByLastHBReceived  int
 		rankByClockDifference int
+		rankByScore           int
+		reputation            reputation
 		// msg counters
 		numIncomingHB   int
 		numIncomingPull int
 		numIncomingTx   int
 
 		numHBSendErr int
+		// traffic
+		bytesIn         uint64
+		bytesOut        uint64
+		numThrottled    int
+		inboundLimiters map[string]*inboundLimiter
 	}
 )
 
@@ -152,6 +185,7 @@ const (
 	lppProtocolGossip    = "/proxima/gossip/%d"
 	lppProtocolPull      = "/proxima/pull/%d"
 	lppProtocolHeartbeat = "/proxima/heartbeat/%d"
+	lppProtocolAnnounce  = "/proxima/announce/%d"
 
 	// clockTolerance is how big the difference between local and remote clocks is tolerated.
 	// The difference includes difference between local clocks (positive or negative) plus
@@ -264,5 +298,8 @@ func readPeeringConfig() (*Config, error) {
 		cfg.CooloffListTTL = int(cooloffTTL)
 	}
 	cfg.DisableQuicreuse = viper.GetBool("peering.disable_quicreuse")
+	readInboundLimitsConfig(cfg)
+	cfg.Announce = viper.GetBool("peering.gossip.announce")
+	cfg.Compression = viper.GetBool("peering.gossip.compression")
 	return cfg, nil
 }
@@ -0,0 +1,212 @@
+package admin_cmd
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/lunfardo314/proxima/api"
+	"github.com/lunfardo314/proxima/proxi/glb"
+	"github.com/lunfardo314/proxima/util"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+func Init() *cobra.Command {
+	adminCmd := &cobra.Command{
+		Use:   "admin [<subcommand>]",
+		Short: "specifies node admin API subcommand",
+		Args:  cobra.NoArgs,
+		PersistentPreRun: func(_ *cobra.Command, _ []string) {
+			glb.ReadInConfig()
+		},
+	}
+
+	adminCmd.PersistentFlags().StringP("config", "c", "", "proxi config profile name")
+	err := viper.BindPFlag("config", adminCmd.PersistentFlags().Lookup("config"))
+	glb.AssertNoError(err)
+
+	adminCmd.PersistentFlags().String("admin.endpoint", "", "<DNS name>:port of the admin API")
+	err = viper.BindPFlag("admin.endpoint", adminCmd.PersistentFlags().Lookup("admin.endpoint"))
+	glb.AssertNoError(err)
+
+	adminCmd.InitDefaultHelpCmd()
+	adminCmd.AddCommand(
+		initTraceCmd(),
+		initPeersCmd(),
+		initSnapshotCmd(),
+		initSequencerCmd(),
+		initSaveGraphCmd(),
+	)
+	return adminCmd
+}
+
+func initTraceCmd() *cobra.Command {
+	var enable, disable []string
+	traceCmd := &cobra.Command{
+		Use:   "trace [--enable <tag1,tag2,...>] [--disable <tag1,tag2,...>]",
+		Short: `lists enabled trace tags. Enables and disables trace tags on the node`,
+		Args:  cobra.NoArgs,
+		Run: func(_ *cobra.Command, _ []string) {
+			var tags []string
+			var err error
+			if len(enable) == 0 && len(disable) == 0 {
+				tags, err = glb.GetAdminClient().GetTraceTags()
+			} else {
+				tags, err = glb.GetAdminClient().SetTraceTags(enable, disable)
+			}
+			glb.AssertNoError(err)
+			if len(tags) == 0 {
+				glb.Infof("no trace tags are enabled")
+				return
+			}
+			glb.Infof("enabled trace tags: %s", strings.Join(tags, ", "))
+		},
+	}
+	traceCmd.Flags().StringSliceVar(&enable, "enable", nil, "trace tags to enable")
+	traceCmd.Flags().StringSliceVar(&disable, "disable", nil, "trace tags to disable")
+	traceCmd.InitDefaultHelpCmd()
+	return traceCmd
+}
+
+func initPeersCmd() *cobra.Command {
+	peersCmd := &cobra.Command{
+		Use:   "peers",
+		Short: `lists static peers and the blacklist`,
+		Args:  cobra.NoArgs,
+		Run: func(_ *cobra.Command, _ []string) {
+			res, err := glb.GetAdminClient().GetPeers()
+			glb.AssertNoError(err)
+			displayPeers(res)
+		},
+	}
+	peersCmd.AddCommand(
+		&cobra.Command{
+			Use:   "add <name> <multiaddress>",
+			Short: `adds static peer`,
+			Args:  cobra.ExactArgs(2),
+			Run: func(_ *cobra.Command, args []string) {
+				res, err := glb.GetAdminClient().AddStaticPeer(args[0], args[1])
+				glb.AssertNoError(err)
+				displayPeers(res)
+			},
+		},
+		&cobra.Command{
+			Use:   "remove <peer id>",
+			Short: `removes static peer`,
+			Args:  cobra.ExactArgs(1),
+			Run: func(_ *cobra.Command, args []string) {
+				res, err := glb.GetAdminClient().RemoveStaticPeer(args[0])
+				glb.AssertNoError(err)
+				displayPeers(res)
+			},
+		},
+		&cobra.Command{
+			Use:   "blacklist <peer id> [<reason>]",
+			Short: `drops the peer and puts it into the blacklist`,
+			Args:  cobra.RangeArgs(1, 2),
+			Run: func(_ *cobra.Command, args []string) {
+				reason := ""
+				if len(args) > 1 {
+					reason = args[1]
+				}
+				res, err := glb.GetAdminClient().BlacklistPeer(args[0], reason)
+				glb.AssertNoError(err)
+				displayPeers(res)
+			},
+		},
+		&cobra.Command{
+			Use:   "unblacklist <peer id>",
+			Short: `removes the peer from the blacklist`,
+			Args:  cobra.ExactArgs(1),
+			Run: func(_ *cobra.Command, args []string) {
+				res, err := glb.GetAdminClient().UnblacklistPeer(args[0])
+				glb.AssertNoError(err)
+				displayPeers(res)
+			},
+		},
+	)
+	peersCmd.InitDefaultHelpCmd()
+	return peersCmd
+}
+
+func displayPeers(res *api.AdminPeers) {
+	glb.Infof("static peers (%d):", len(res.StaticPeers))
+	for _, name := range util.KeysSorted(res.StaticPeers, func(k1, k2 string) bool { return k1 < k2 }) {
+		glb.Infof("    %s: %s", name, res.StaticPeers[name])
+	}
+	glb.Infof("blacklist (%d):", len(res.Blacklist))
+	ids := make([]string, 0, len(res.Blacklist))
+	for id := range res.Blacklist {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	for _, id := range ids {
+		glb.Infof("    %s: '%s'", id, res.Blacklist[id])
+	}
+}
+
+func initSnapshotCmd() *cobra.Command {
+	snapshotCmd := &cobra.Command{
+		Use:   "snapshot",
+		Short: `takes snapshot of the node's multi-state now`,
+		Args:  cobra.NoArgs,
+		Run: func(_ *cobra.Command, _ []string) {
+			glb.Infof("taking snapshot. It may take a while...")
+			fname, err := glb.GetAdminClient().TakeSnapshot()
+			glb.AssertNoError(err)
+			glb.Infof("snapshot has been saved to %s on the node", fname)
+		},
+	}
+	snapshotCmd.InitDefaultHelpCmd()
+	return snapshotCmd
+}
+
+func initSequencerCmd() *cobra.Command {
+	seqCmd := &cobra.Command{
+		Use:   "sequencer [start|stop]",
+		Short: `displays status of the sequencer on the node. Starts (resumes) or stops (pauses) it`,
+		Args:  cobra.MaximumNArgs(1),
+		Run: func(_ *cobra.Command, args []string) {
+			clnt := glb.GetAdminClient()
+			var res *api.AdminSequencer
+			var err error
+			switch {
+			case len(args) == 0:
+				res, err = clnt.GetSequencerStatus()
+			case args[0] == "start":
+				res, err = clnt.StartSequencer()
+			case args[0] == "stop":
+				res, err = clnt.StopSequencer()
+			default:
+				glb.Assertf(false, "wrong argument '%s'. Must be 'start' or 'stop'", args[0])
+			}
+			glb.AssertNoError(err)
+			if res.SequencerID == "" {
+				glb.Infof("sequencer is not configured on the node")
+				return
+			}
+			status := "STOPPED"
+			if res.Running {
+				status = "RUNNING"
+			}
+			glb.Infof("sequencer %s is %s", res.SequencerID, status)
+		},
+	}
+	seqCmd.InitDefaultHelpCmd()
+	return seqCmd
+}
+
+func initSaveGraphCmd() *cobra.Command {
+	graphCmd := &cobra.Command{
+		Use:   "save_graph",
+		Short: `saves graph of the memDAG on the node in the DOT format`,
+		Args:  cobra.NoArgs,
+		Run: func(_ *cobra.Command, _ []string) {
+			fname, err := glb.GetAdminClient().SaveGraph()
+			glb.AssertNoError(err)
+			glb.Infof("memDAG graph has been saved to %s on the node", fname)
+		},
+	}
+	graphCmd.InitDefaultHelpCmd()
+	return graphCmd
+}
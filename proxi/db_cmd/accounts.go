@@ -7,6 +7,20 @@ import (
 	"github.com/spf13/cobra"
 )
 
+type (
+	accountsResult struct {
+		BranchID string                   `json:"branch_id"`
+		Accounts map[string]accountResult `json:"accounts"`
+		// chain id hex -> balance
+		Chains map[string]uint64 `json:"chains"`
+	}
+
+	accountResult struct {
+		Balance    uint64 `json:"balance"`
+		NumOutputs int    `json:"num_outputs"`
+	}
+)
+
 func initAccountsCmd() *cobra.Command {
 	accountsCmd := &cobra.Command{
 		Use:   "accounts",
@@ -25,10 +39,7 @@ func runAccountsCmd(_ *cobra.Command, _ []string) {
 	glb.Infof("---------------- account totals at the heaviest branch ------------------")
 
 	branchData := multistate.FetchLatestBranches(glb.StateStore())
-	if len(branchData) == 0 {
-		glb.Infof("no branches found")
-		return
-	}
+	glb.Assertf(len(branchData) > 0, "no branches found")
 
 	brHeaviest := util.Maximum(branchData, func(br1, br2 *multistate.BranchData) bool {
 		return br1.CoverageDelta < br2.CoverageDelta
@@ -36,4 +47,18 @@ func runAccountsCmd(_ *cobra.Command, _ []string) {
 
 	accountInfo := multistate.MustCollectAccountInfo(glb.StateStore(), brHeaviest.Root)
 	glb.Infof("%s\n", accountInfo.Lines("   ").String())
+
+	brID := brHeaviest.Stem.ID.TransactionID()
+	res := &accountsResult{
+		BranchID: brID.StringHex(),
+		Accounts: make(map[string]accountResult, len(accountInfo.LockedAccounts)),
+		Chains:   make(map[string]uint64, len(accountInfo.ChainRecords)),
+	}
+	for acc, ai := range accountInfo.LockedAccounts {
+		res.Accounts[acc] = accountResult{Balance: ai.Balance, NumOutputs: ai.NumOutputs}
+	}
+	for chainID, ci := range accountInfo.ChainRecords {
+		res.Chains[chainID.StringHex()] = ci.Balance
+	}
+	glb.PrintResult(res)
 }
@@ -14,6 +14,22 @@ var (
 	missingOnly              bool
 )
 
+type (
+	analyzeBranchesResult struct {
+		LatestSlot       uint32               `json:"latest_slot"`
+		Slots            []analyzedSlotResult `json:"slots"`
+		TotalSlots       int                  `json:"total_slots"`
+		SlotsWithMissing int                  `json:"slots_with_missing"`
+	}
+
+	analyzedSlotResult struct {
+		Slot          uint32   `json:"slot"`
+		Main          string   `json:"main"`
+		CoverageDelta uint64   `json:"coverage_delta"`
+		Missing       []string `json:"missing"`
+	}
+)
+
 func initAnalyzeBranchesCmd() *cobra.Command {
 	dbMainChainCmd := &cobra.Command{
 		Use:   "analyze_branches",
@@ -48,6 +64,11 @@ func runAnalyzeBranchesCmd(_ *cobra.Command, _ []string) {
 		return slotsBackAnalyzeBranches <= 0 || len(mainChain) <= slotsBackAnalyzeBranches
 	})
 
+	res := &analyzeBranchesResult{
+		LatestSlot: uint32(latest),
+		Slots:      make([]analyzedSlotResult, 0),
+		TotalSlots: len(mainChain),
+	}
 	countWithMissing := 0
 	for _, br := range mainChain {
 		rootsInTheSlot := multistate.FetchRootRecords(glb.StateStore(), br.Slot())
@@ -65,7 +86,20 @@ func runAnalyzeBranchesCmd(_ *cobra.Command, _ []string) {
 			return seqID.StringShort()
 		})
 		glb.Infof("%6d: main: %s (%s), missing: [%s]", br.Slot(), br.SequencerID.StringShort(), util.Th(br.CoverageDelta), ln.Join(", "))
+
+		missing := make([]string, 0, len(missingSeqIDs))
+		for seqID := range missingSeqIDs {
+			missing = append(missing, seqID.StringHex())
+		}
+		res.Slots = append(res.Slots, analyzedSlotResult{
+			Slot:          uint32(br.Slot()),
+			Main:          br.SequencerID.StringHex(),
+			CoverageDelta: br.CoverageDelta,
+			Missing:       missing,
+		})
 	}
 	glb.Infof("total slots analyzed: %d", len(mainChain))
 	glb.Infof("total slots with missing branches: %d", countWithMissing)
+	res.SlotsWithMissing = countWithMissing
+	glb.PrintResult(res)
 }
@@ -4,6 +4,7 @@ import (
 	"sort"
 	"strconv"
 
+	"github.com/lunfardo314/proxima/api"
 	"github.com/lunfardo314/proxima/ledger/multistate"
 	"github.com/lunfardo314/proxima/proxi/glb"
 	"github.com/spf13/cobra"
@@ -44,6 +45,7 @@ func runBranchesCmd(_ *cobra.Command, args []string) {
 		return branchData[i].Stem.Timestamp().After(branchData[j].Stem.Timestamp())
 	})
 
+	res := make([]api.BranchData, 0, len(branchData))
 	for i, bd := range branchData {
 		txid := bd.Stem.ID.TransactionID()
 		glb.Infof("%3d: %18s   numTx: %d, seqID: %s, hex: %s, root: %s",
@@ -54,5 +56,7 @@ func runBranchesCmd(_ *cobra.Command, args []string) {
 			txid.StringHex(),
 			bd.Root.String(),
 		)
+		res = append(res, branchResult(bd))
 	}
+	glb.PrintResult(res)
 }
@@ -12,6 +12,14 @@ import (
 	"github.com/spf13/cobra"
 )
 
+type dbChainsResult struct {
+	BranchID string            `json:"branch_id"`
+	Supply   uint64            `json:"supply"`
+	Chains   []*glb.UTXOResult `json:"chains"`
+	Stem     *glb.UTXOResult   `json:"stem"`
+	Total    uint64            `json:"total"`
+}
+
 func initChainsCmd() *cobra.Command {
 	dbChainsCmd := &cobra.Command{
 		Use:   "chains",
@@ -30,10 +38,7 @@ func runChainsCmd(_ *cobra.Command, _ []string) {
 	defer glb.CloseDatabases()
 
 	branchData := multistate.FindLatestReliableBranch(glb.StateStore(), global.FractionHealthyBranch)
-	if branchData == nil {
-		glb.Infof("no branches found")
-		return
-	}
+	glb.Assertf(branchData != nil, "no branches found")
 
 	accountInfo := multistate.MustCollectAccountInfo(glb.StateStore(), branchData.Root)
 
@@ -47,9 +52,18 @@ func runChainsCmd(_ *cobra.Command, _ []string) {
 		return bytes.Compare(k1[:], k2[:]) < 0
 	})
 
+	brID := branchData.Stem.ID.TransactionID()
+	res := &dbChainsResult{
+		BranchID: brID.StringHex(),
+		Supply:   branchData.Supply,
+		Chains:   make([]*glb.UTXOResult, 0, len(chainIDSSorted)),
+	}
 	sum := uint64(0)
 	for _, chainID := range chainIDSSorted {
 		ci := accountInfo.ChainRecords[chainID]
+		if o, err := ci.Output.Parse(); err == nil {
+			res.Chains = append(res.Chains, glb.NewUTXOResult(o))
+		}
 		glb.Infof("   %s :: %s :: %s   seq=%v branch=%v",
 			chainID.String(), ci.Output.ID.String(), util.Th(ci.Balance), ci.Output.ID.IsSequencerTransaction(), ci.Output.ID.IsBranchTransaction())
 		if glb.IsVerbose() {
@@ -68,4 +82,7 @@ func runChainsCmd(_ *cobra.Command, _ []string) {
 	lines := stem.Lines("  ")
 	glb.Infof(lines.String())
 
+	res.Stem = glb.NewUTXOResult(stem)
+	res.Total = sum
+	glb.PrintResult(res)
 }
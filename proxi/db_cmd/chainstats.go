@@ -52,6 +52,27 @@ type (
 		score int
 		outOf int
 	}
+
+	chainStatsResult struct {
+		NumBranches int    `json:"num_branches"`
+		MinBIB      uint64 `json:"min_bib"`
+		MaxBIB      uint64 `json:"max_bib"`
+		AvgBIB      uint64 `json:"avg_bib"`
+		MedianBIB   uint64 `json:"median_bib"`
+		// distribution of branch inflation bonus
+		Buckets    []int                     `json:"buckets"`
+		Sequencers map[string]seqStatsResult `json:"sequencers"`
+		// distribution of the winning branch's place by BIB in the slot
+		WinningBuckets []int `json:"winning_buckets"`
+	}
+
+	seqStatsResult struct {
+		NumBranches int    `json:"num_branches"`
+		NumSlots    int    `json:"num_slots"`
+		AvgBIB      uint64 `json:"avg_bib"`
+		MinBalance  uint64 `json:"min_balance"`
+		MaxBalance  uint64 `json:"max_balance"`
+	}
 )
 
 func runChainStats() {
@@ -133,6 +154,15 @@ func runChainStats() {
 	for i, n := range buckets {
 		glb.Infof("%d: %d (%.1f%%)", i, n, (float64(n)*100)/float64(numBranches))
 	}
+	res := &chainStatsResult{
+		NumBranches: numBranches,
+		MinBIB:      minBib,
+		MaxBIB:      maxBib,
+		AvgBIB:      bibSum / uint64(numBranches),
+		MedianBIB:   util.Median(allBibs),
+		Buckets:     buckets,
+		Sequencers:  make(map[string]seqStatsResult, len(sequencers)),
+	}
 	glb.Infof("\nstats by sequencer:")
 	seqIDs := util.KeysSorted(sequencers, func(id1, id2 base.ChainID) bool {
 		return sequencers[id1].numBranches > sequencers[id2].numBranches
@@ -150,6 +180,13 @@ func runChainStats() {
 			util.Th(seqStatsRec.minBalance),
 			util.Th(seqStatsRec.maxBalance),
 		)
+		res.Sequencers[seqID.StringHex()] = seqStatsResult{
+			NumBranches: seqStatsRec.numBranches,
+			NumSlots:    seqStatsRec.maxSlot - seqStatsRec.minSlot + 1,
+			AvgBIB:      seqStatsRec.sumInflation / uint64(seqStatsRec.numBranches),
+			MinBalance:  seqStatsRec.minBalance,
+			MaxBalance:  seqStatsRec.maxBalance,
+		}
 	}
 
 	glb.Infof("\nwinning branch by BIB score in the slot:")
@@ -202,5 +239,6 @@ func runChainStats() {
 	for i, no := range buckets1 {
 		glb.Infof("  bucket #%d: %d (%.1f%%)", i, no, (float64(no)*100)/float64(len(branchIDs)))
 	}
-
+	res.WinningBuckets = buckets1
+	glb.PrintResult(res)
 }
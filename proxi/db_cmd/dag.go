@@ -25,7 +25,7 @@ func initDBDAGCmd() *cobra.Command {
 		Args:  cobra.MaximumNArgs(1),
 		Run:   runDbDAGCmd,
 	}
-	dbTreeCmd.PersistentFlags().StringVarP(&outputFileDAG, "output", "o", "", "output file")
+	dbTreeCmd.PersistentFlags().StringVarP(&outputFileDAG, "out_file", "o", "", "output file")
 	dbTreeCmd.InitDefaultHelpCmd()
 	return dbTreeCmd
 }
@@ -63,4 +63,5 @@ func runDbDAGCmd(_ *cobra.Command, args []string) {
 		tmpDag.SaveGraph(outputFileDAG)
 	}
 	glb.Infof("MemDAG has been store in .DOT format in the file '%s', %d slots back", outFile, numSlotsBack)
+	glb.PrintResult(&glb.FileResult{File: outputFileDAG})
 }
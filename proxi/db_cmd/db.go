@@ -1,6 +1,8 @@
 package db_cmd
 
 import (
+	"github.com/lunfardo314/proxima/api"
+	"github.com/lunfardo314/proxima/ledger/multistate"
 	"github.com/lunfardo314/proxima/proxi/db_cmd/txstore"
 	"github.com/spf13/cobra"
 )
@@ -31,6 +33,13 @@ func Init() *cobra.Command {
 		//initDbStatsCmd(),
 		initDbChainStatsCmd(),
 		initAnalyzeBranchesCmd(),
+		initMigrateCmd(),
 	)
 	return dbCmd
 }
+
+// branchResult is the branch record in the results of commands
+func branchResult(br *multistate.BranchData) api.BranchData {
+	txid := br.Stem.ID.TransactionID()
+	return api.BranchData{ID: txid.StringHex(), Data: *br.JSONAble()}
+}
@@ -76,10 +76,12 @@ func runFindTxCmd(_ *cobra.Command, _ []string) {
 	rdr := multistate.MustNewReadable(glb.StateStore(), root)
 	nTx := 0
 	nFound := 0
+	found := make([]string, 0)
 	rdr.IterateKnownCommittedTransactions(func(txid *base.TransactionID, _ base.Slot) bool {
 		if findWithHexFragment == "" || strings.Contains(txid.String(), findWithHexFragment) {
 			glb.Infof("%6d   %s    %s", nFound, txid.StringHex(), txid.String())
 			nFound++
+			found = append(found, txid.StringHex())
 		}
 		nTx++
 		return !findFirst || nFound == 0
@@ -87,4 +89,10 @@ func runFindTxCmd(_ *cobra.Command, _ []string) {
 
 	glb.Infof("---------\ntotal: %d transaction IDs found", nFound)
 	glb.Infof("---------\ntotal: %d transaction scanned", nTx)
+	glb.PrintResult(&findTxResult{Found: found, Scanned: nTx})
+}
+
+type findTxResult struct {
+	Found   []string `json:"found"`
+	Scanned int      `json:"scanned"`
 }
@@ -7,7 +7,6 @@ import (
 	"github.com/lunfardo314/proxima/global"
 	"github.com/lunfardo314/proxima/ledger/multistate"
 	"github.com/lunfardo314/proxima/proxi/glb"
-	"github.com/lunfardo314/unitrie/adaptors/badger_adaptor"
 	"github.com/spf13/cobra"
 )
 
@@ -26,19 +25,18 @@ func dbGetLedgerIDCmd(_ *cobra.Command, _ []string) {
 	dbName := global.MultiStateDBName
 	glb.FileMustExist(dbName)
 	glb.Infof("Multi-state database: %s", dbName)
-	stateDB := badger_adaptor.MustCreateOrOpenBadgerDB(dbName)
-	stateStore := badger_adaptor.New(stateDB)
+	stateStore := glb.MustOpenDB(dbName)
 	yamlData := multistate.LedgerIdentityBytesFromStore(stateStore)
 	defer glb.CloseDatabases()
 
 	if glb.FileExists(glb.LedgerIDFileName) {
 		if !glb.YesNoPrompt(fmt.Sprintf("file '%s' already exists. Owerwrite", glb.LedgerIDFileName), false) {
-			glb.Infof("exit")
-			return
+			glb.Abort()
 		}
 	}
 
 	err := os.WriteFile(glb.LedgerIDFileName, yamlData, 0644)
 	glb.AssertNoError(err)
 	glb.Infof("ledger definitions has been saved to '%s'", glb.LedgerIDFileName)
+	glb.PrintResult(&glb.FileResult{File: glb.LedgerIDFileName})
 }
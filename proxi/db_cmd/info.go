@@ -5,6 +5,7 @@ import (
 	"encoding/hex"
 	"sort"
 
+	"github.com/lunfardo314/proxima/api"
 	"github.com/lunfardo314/proxima/ledger"
 	"github.com/lunfardo314/proxima/ledger/multistate"
 	"github.com/lunfardo314/proxima/proxi/glb"
@@ -13,6 +14,25 @@ import (
 
 var slotsBackDBInfo int
 
+type (
+	dbInfoResult struct {
+		LatestSlot   uint32              `json:"latest_slot"`
+		EarliestSlot uint32              `json:"earliest_slot"`
+		LibraryHash  string              `json:"library_hash"`
+		Branches     []api.BranchData    `json:"branches"`
+		Supply       supplySummaryResult `json:"supply"`
+	}
+
+	supplySummaryResult struct {
+		NumberOfBranches int    `json:"number_of_branches"`
+		OldestSlot       uint32 `json:"oldest_slot"`
+		LatestSlot       uint32 `json:"latest_slot"`
+		BeginSupply      uint64 `json:"begin_supply"`
+		EndSupply        uint64 `json:"end_supply"`
+		TotalInflation   uint64 `json:"total_inflation"`
+	}
+)
+
 func initDBInfoCmd() *cobra.Command {
 	dbInfoCmd := &cobra.Command{
 		Use:   "info",
@@ -31,10 +51,7 @@ func runDbInfoCmd(_ *cobra.Command, _ []string) {
 	defer glb.CloseDatabases()
 
 	branchData := multistate.FetchLatestBranches(glb.StateStore())
-	if len(branchData) == 0 {
-		glb.Infof("no branches found")
-		return
-	}
+	glb.Assertf(len(branchData) > 0, "no branches found")
 	glb.Infof("Total %d branches in the latest slot %d", len(branchData), branchData[0].Stem.Timestamp().Slot)
 
 	sort.Slice(branchData, func(i, j int) bool {
@@ -56,11 +73,28 @@ func runDbInfoCmd(_ *cobra.Command, _ []string) {
 	glb.Infof("ledger library hash: %s", hex.EncodeToString(h[:]))
 	glb.Verbosef("\n----------------- Ledger state identity ----------------")
 	glb.Verbosef("%s", idParams.String())
+	res := &dbInfoResult{
+		LatestSlot:   uint32(branchData[0].Stem.Timestamp().Slot),
+		EarliestSlot: uint32(earliestSlot),
+		LibraryHash:  hex.EncodeToString(h[:]),
+		Branches:     make([]api.BranchData, 0, len(branchData)),
+	}
 	glb.Infof("----------------- branch data ----------------------")
 	for i, br := range branchData {
 		glb.Infof("%3d %s", i, br.LinesShort().Join(", "))
+		res.Branches = append(res.Branches, branchResult(br))
 	}
 	glb.Infof("\n------------- Supply and inflation summary -------------")
 	summary := multistate.FetchSummarySupply(glb.StateStore(), slotsBackDBInfo)
 	glb.Infof("%s", summary.Lines("   ").String())
+
+	res.Supply = supplySummaryResult{
+		NumberOfBranches: summary.NumberOfBranches,
+		OldestSlot:       uint32(summary.OldestSlot),
+		LatestSlot:       uint32(summary.LatestSlot),
+		BeginSupply:      summary.BeginSupply,
+		EndSupply:        summary.EndSupply,
+		TotalInflation:   summary.TotalInflation,
+	}
+	glb.PrintResult(res)
 }
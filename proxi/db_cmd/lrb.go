@@ -1,9 +1,9 @@
 package db_cmd
 
 import (
-	"os"
 	"time"
 
+	"github.com/lunfardo314/proxima/api"
 	"github.com/lunfardo314/proxima/global"
 	"github.com/lunfardo314/proxima/ledger"
 	"github.com/lunfardo314/proxima/ledger/base"
@@ -30,10 +30,7 @@ func runReliableBranchCmd(_ *cobra.Command, _ []string) {
 	glb.InitLedgerFromDB()
 
 	lrb := multistate.FindLatestReliableBranch(glb.StateStore(), global.FractionHealthyBranch)
-	if lrb == nil {
-		glb.Infof("reliable branch has not been found")
-		os.Exit(1)
-	}
+	glb.Assertf(lrb != nil, "reliable branch has not been found")
 	nowSlot := ledger.TimeNow().Slot
 	glb.Infof("current slot is %d", nowSlot)
 	lrbID := lrb.Stem.ID.TransactionID()
@@ -43,6 +40,12 @@ func runReliableBranchCmd(_ *cobra.Command, _ []string) {
 
 	byChainID := set.New[base.ChainID]()
 	counter := 0
+	res := &dbLRBResult{
+		Branch:    branchResult(lrb),
+		SlotsBack: int(nowSlot - lrbID.Slot()),
+		// latest branch of each sequencer before the LRB
+		BySequencer: make([]api.BranchData, 0),
+	}
 
 	start := time.Now()
 	multistate.IterateBranchChainBack(glb.StateStore(), lrb, func(branchID *base.TransactionID, branch *multistate.BranchData) bool {
@@ -52,6 +55,7 @@ func runReliableBranchCmd(_ *cobra.Command, _ []string) {
 		}
 		byChainID.Insert(branch.SequencerID)
 
+		res.BySequencer = append(res.BySequencer, branchResult(branch))
 		slotsBefore := int(branchID.Slot()) - int(lrbID.Slot())
 		if glb.IsVerbose() {
 			glb.Infof("     (%d branches, %d slots) %s %s", 1-counter, slotsBefore, branchID.String(), branch.LinesVerbose().Join("  "))
@@ -61,4 +65,11 @@ func runReliableBranchCmd(_ *cobra.Command, _ []string) {
 		return true
 	})
 	glb.Infof("--------------------\nTotal %d branches before the LRBID have been scanned in %v", counter-1, time.Since(start))
+	glb.PrintResult(res)
+}
+
+type dbLRBResult struct {
+	Branch      api.BranchData   `json:"branch"`
+	SlotsBack   int              `json:"slots_back"`
+	BySequencer []api.BranchData `json:"by_sequencer"`
 }
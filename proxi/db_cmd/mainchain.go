@@ -24,7 +24,7 @@ func initMainChainCmd() *cobra.Command {
 		Args:  cobra.NoArgs,
 		Run:   runMainChainCmd,
 	}
-	dbMainChainCmd.PersistentFlags().StringVarP(&outputFile, "output", "o", "", "output file")
+	dbMainChainCmd.PersistentFlags().StringVarP(&outputFile, "out_file", "o", "", "output file")
 	dbMainChainCmd.PersistentFlags().Uint16VarP(&slotsBack, "slots_back", "s", 1000, "limit maximum how many slots back")
 
 	dbMainChainCmd.InitDefaultHelpCmd()
@@ -77,9 +77,32 @@ func runMainChainCmd(_ *cobra.Command, _ []string) {
 		return bySeqID[k1].onChainBalance > bySeqID[k2].onChainBalance
 	})
 	glb.Infof("stats by sequencer id:")
+	res := &mainChainResult{
+		NumBranches: len(mainBranches),
+		Sequencers:  make(map[string]mainChainSeqResult, len(sorted)),
+	}
 	for _, k := range sorted {
 		sd := bySeqID[k]
 		glb.Infof("%10s %s  %8d (%2d%%)       %s", sd.name, k.StringShort(),
 			sd.numOccurrences, (100*sd.numOccurrences)/len(mainBranches), util.Th(sd.onChainBalance))
+		res.Sequencers[k.StringHex()] = mainChainSeqResult{
+			Name:           sd.name,
+			NumBranches:    sd.numOccurrences,
+			OnChainBalance: sd.onChainBalance,
+		}
 	}
+	glb.PrintResult(res)
 }
+
+type (
+	mainChainResult struct {
+		NumBranches int                           `json:"num_branches"`
+		Sequencers  map[string]mainChainSeqResult `json:"sequencers"`
+	}
+
+	mainChainSeqResult struct {
+		Name           string `json:"name"`
+		NumBranches    int    `json:"num_branches"`
+		OnChainBalance uint64 `json:"on_chain_balance"`
+	}
+)
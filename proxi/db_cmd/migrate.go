@@ -0,0 +1,67 @@
+package db_cmd
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/lunfardo314/proxima/kvdb"
+	"github.com/lunfardo314/proxima/proxi/glb"
+	"github.com/spf13/cobra"
+)
+
+var (
+	migrateToBackend string
+	migrateBatchSize int
+)
+
+func initMigrateCmd() *cobra.Command {
+	migrateCmd := &cobra.Command{
+		Use: "migrate <source database directory> <target database directory>",
+		Short: fmt.Sprintf("copies database to the new database with another backend. Backend of the source is detected. Supported backends: %s",
+			strings.Join(kvdb.Backends(), ", ")),
+		Args: cobra.ExactArgs(2),
+		Run:  runMigrateCmd,
+	}
+	migrateCmd.Flags().StringVar(&migrateToBackend, "backend", kvdb.BackendBolt, "backend of the target database")
+	migrateCmd.Flags().IntVar(&migrateBatchSize, "batch", 10_000, "number of key/value pairs in one batch")
+	migrateCmd.InitDefaultHelpCmd()
+	return migrateCmd
+}
+
+func runMigrateCmd(_ *cobra.Command, args []string) {
+	from, to := args[0], args[1]
+	glb.FileMustExist(from)
+	glb.FileMustNotExist(to)
+
+	fromBackend := kvdb.Detect(from)
+	glb.Assertf(fromBackend != "", "can't detect backend of the database '%s'", from)
+	glb.Assertf(fromBackend != migrateToBackend, "database '%s' already is '%s' database", from, fromBackend)
+
+	glb.Infof("database '%s' (%s) will be copied to the new database '%s' (%s)", from, fromBackend, to, migrateToBackend)
+	if !glb.YesNoPrompt("Proceed?", true) {
+		glb.Abort()
+	}
+
+	src, err := kvdb.Open(from, fromBackend)
+	glb.AssertNoError(err)
+	defer func() { _ = src.Close() }()
+
+	dst, err := kvdb.Open(to, migrateToBackend)
+	glb.AssertNoError(err)
+	defer func() { _ = dst.Close() }()
+
+	start := time.Now()
+	n, err := kvdb.Copy(dst, src, migrateBatchSize)
+	glb.AssertNoError(err)
+	glb.Infof("%d key/value pairs copied in %v", n, time.Since(start))
+	glb.Infof("replace '%s' with '%s' and set 'db.backend: %s' in the node config to use the new database", from, to, migrateToBackend)
+	glb.PrintResult(&migrateResult{From: from, To: to, Backend: migrateToBackend, Pairs: n})
+}
+
+type migrateResult struct {
+	From    string `json:"from"`
+	To      string `json:"to"`
+	Backend string `json:"backend"`
+	Pairs   int    `json:"pairs"`
+}
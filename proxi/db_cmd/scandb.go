@@ -25,11 +25,14 @@ func runScanDBCmd(_ *cobra.Command, _ []string) {
 	glb.InitLedgerFromDB()
 	defer glb.CloseDatabases()
 
+	res := &scanDBResult{}
 	multistate.IterateSlotsBack(glb.StateStore(), func(slot base.Slot, roots []multistate.RootRecord) bool {
 		branches := multistate.FetchBranchDataMulti(glb.StateStore(), roots...)
 		if len(branches) == 0 {
 			return true
 		}
+		res.Slots++
+		res.Branches += len(branches)
 		glb.Infof("----------- slot %d: %d branches", slot, len(branches))
 
 		for i, br := range branches {
@@ -47,5 +50,11 @@ func runScanDBCmd(_ *cobra.Command, _ []string) {
 		}
 		return true
 	})
+	// inconsistencies end the command with error
+	glb.PrintResult(res)
+}
 
+type scanDBResult struct {
+	Slots    int `json:"slots"`
+	Branches int `json:"branches"`
 }
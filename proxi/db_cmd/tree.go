@@ -23,7 +23,7 @@ func initDBTreeCmd() *cobra.Command {
 		Args:  cobra.MaximumNArgs(1),
 		Run:   runDbTreeCmd,
 	}
-	dbTreeCmd.PersistentFlags().StringVarP(&outputFile, "output", "o", "", "output file")
+	dbTreeCmd.PersistentFlags().StringVarP(&outputFile, "out_file", "o", "", "output file")
 
 	dbTreeCmd.InitDefaultHelpCmd()
 	return dbTreeCmd
@@ -52,4 +52,5 @@ func runDbTreeCmd(_ *cobra.Command, args []string) {
 		multistate.SaveBranchTree(glb.StateStore(), outFile, numSlotsBack)
 	}
 	glb.Infof("branch tree has been store in .DOT format in the file '%s', %d slots back", outFile, numSlotsBack)
+	glb.PrintResult(&glb.FileResult{File: outFile})
 }
@@ -23,7 +23,7 @@ func initCrossCheckCmd() *cobra.Command {
 		Args:  cobra.MaximumNArgs(1),
 		Run:   runReconcileCmd,
 	}
-	crossCheckCmd.PersistentFlags().StringVarP(&outputFileReconcile, "output", "o", "", "output file")
+	crossCheckCmd.PersistentFlags().StringVarP(&outputFileReconcile, "out_file", "o", "", "output file")
 	crossCheckCmd.InitDefaultHelpCmd()
 	return crossCheckCmd
 }
@@ -54,11 +54,13 @@ func runReconcileCmd(_ *cobra.Command, args []string) {
 
 	nTx := 0
 	nSlots := 0
+	missing := make([]string, 0)
 	start := time.Now()
 	for ; slot >= downToSlot; slot-- {
 		rdr.IterateKnownCommittedTransactions(func(txid *base.TransactionID, slot base.Slot) bool {
 			if !glb.TxBytesStore().HasTxBytes(txid) {
 				glb.Infof("transaction %s not in the txStore: hex id = %s", txid.String(), txid.StringHex())
+				missing = append(missing, txid.StringHex())
 			}
 			nTx++
 			return true
@@ -66,4 +68,12 @@ func runReconcileCmd(_ *cobra.Command, args []string) {
 		nSlots++
 	}
 	glb.Infof("it took %v to check %d transaction IDs and %d slots", time.Since(start), nTx, nSlots)
+	glb.PrintResult(&crossCheckResult{Missing: missing, Checked: nTx, Slots: nSlots})
+}
+
+type crossCheckResult struct {
+	// transactions without bytes in the txStore
+	Missing []string `json:"missing"`
+	Checked int      `json:"checked"`
+	Slots   int      `json:"slots"`
 }
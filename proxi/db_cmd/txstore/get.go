@@ -1,6 +1,7 @@
 package txstore
 
 import (
+	"encoding/hex"
 	"fmt"
 	"os"
 
@@ -42,19 +43,29 @@ func runGetCmd(_ *cobra.Command, args []string) {
 	glb.AssertNoError(err)
 
 	txBytesWithMetadata := glb.TxBytesStore().GetTxBytesWithMetadata(&txid)
-	if len(txBytesWithMetadata) == 0 {
-		glb.Infof("NOT FOUND transaction %s in the txStore", txid.String())
-		os.Exit(1)
-	}
+	glb.Assertf(len(txBytesWithMetadata) > 0, "NOT FOUND transaction %s in the txStore", txid.String())
 
 	glb.Infof("FOUND transaction %s in the txStore\n%d bytes including metadata", txid.String(), len(txBytesWithMetadata))
 	if txStoreParse {
 		glb.ParseAndDisplayTxFromSore(txid)
 	}
 
+	res := &getTxResult{
+		TxID: txid.StringHex(),
+		Data: hex.EncodeToString(txBytesWithMetadata),
+	}
 	if txStoreSave {
 		saveTx(&txid, txBytesWithMetadata)
+		res.File = txid.AsFileName()
 	}
+	glb.PrintResult(res)
+}
+
+type getTxResult struct {
+	TxID string `json:"txid"`
+	// transaction bytes with metadata
+	Data string `json:"data"`
+	File string `json:"file,omitempty"`
 }
 
 func saveTx(txid *base.TransactionID, txBytesWithMetadata []byte) {
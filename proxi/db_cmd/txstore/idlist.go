@@ -45,13 +45,16 @@ func runIdListCmd(_ *cobra.Command, args []string) {
 		glb.Infof("slot = %d, hex: %s", slot, hex.EncodeToString(prefix))
 	}
 
+	res := make([]txResult, 0)
 	db.Iterator(prefix).IterateKeys(func(k []byte) bool {
 		txid, err = base.TransactionIDFromBytes(k)
 		glb.AssertNoError(err)
+		res = append(res, txResult{TxID: txid.StringHex()})
 		glb.Infof("%s    hex = %s", txid.String(), txid.StringHex())
 		count++
 		return true
 	})
 
 	glb.Infof("total: %d transactions", count)
+	glb.PrintResult(res)
 }
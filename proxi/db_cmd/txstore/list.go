@@ -42,12 +42,15 @@ func runListCmd(_ *cobra.Command, args []string) {
 	rdr := multistate.MustNewReadable(glb.StateStore(), branches[0].Root)
 
 	nTx := 0
+	res := make([]txResult, 0)
 	rdr.IterateKnownCommittedTransactions(func(txid *base.TransactionID, slot base.Slot) bool {
 		hasBytes := glb.TxBytesStore().HasTxBytes(txid)
+		res = append(res, txResult{TxID: txid.StringHex(), HasTxBytes: &hasBytes})
 		glb.Infof("%s, hex id = %s, has txBytes = %v ", txid.StringShort(), txid.StringHex(), hasBytes)
 		nTx++
 		return true
 	}, slot)
 
 	glb.Infof("total: %d transactions", nTx)
+	glb.PrintResult(res)
 }
@@ -36,4 +36,5 @@ func runPastConeCmd(_ *cobra.Command, args []string) {
 	fname := txid.AsFileNameShort()
 	glb.Infof("writing past cone of %s to '%s'", txid.StringShort(), fname)
 	memdag.SavePastConeFromTxStore(txid, glb.TxBytesStore(), oldestSlot, fname)
+	glb.PrintResult(&glb.FileResult{File: fname})
 }
@@ -43,4 +43,5 @@ func runPutCmd(_ *cobra.Command, args []string) {
 
 	_, err = glb.TxBytesStore().PersistTxBytesWithMetadata(txBytes, meta, tx.ID())
 	glb.AssertNoError(err)
+	glb.PrintResult(&txResult{TxID: txid.StringHex()})
 }
@@ -2,6 +2,12 @@ package txstore
 
 import "github.com/spf13/cobra"
 
+// txResult is the transaction in the results of txstore commands
+type txResult struct {
+	TxID       string `json:"txid"`
+	HasTxBytes *bool  `json:"has_tx_bytes,omitempty"`
+}
+
 func Init() *cobra.Command {
 	dbCmd := &cobra.Command{
 		Use:   "txstore [<subcommand>]",
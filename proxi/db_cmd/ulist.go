@@ -2,7 +2,6 @@ package db_cmd
 
 import (
 	"math"
-	"os"
 	"strconv"
 
 	"github.com/lunfardo314/proxima/global"
@@ -43,18 +42,12 @@ func runUlist(_ *cobra.Command, args []string) {
 		branchID, err = base.TransactionIDFromHexString(branchIDStr)
 		glb.AssertNoError(err)
 		bd, ok := multistate.FetchBranchData(glb.StateStore(), branchID)
-		if !ok {
-			glb.Infof("can't find branch %s", branchIDStr)
-			os.Exit(1)
-		}
+		glb.Assertf(ok, "can't find branch %s", branchIDStr)
 		branchData = &bd
 		branchID = branchData.Stem.ID.TransactionID()
 	} else {
 		branchData = multistate.FindLatestReliableBranch(glb.StateStore(), global.FractionHealthyBranch)
-		if branchData == nil {
-			glb.Infof("latest reliable branch has not been found")
-			os.Exit(1)
-		}
+		glb.Assertf(branchData != nil, "latest reliable branch has not been found")
 		branchID = branchData.Stem.ID.TransactionID()
 		glb.Infof("latest reliable branch (LRB) is %s", branchID.String())
 	}
@@ -66,9 +59,15 @@ func runUlist(_ *cobra.Command, args []string) {
 	var o *ledger.Output
 	var err1 error
 	count := 0
+	res := &ulistResult{
+		BranchID: branchID.StringHex(),
+		Slot:     uint32(slot),
+		Outputs:  make([]*glb.UTXOResult, 0),
+	}
 	err = rdr.IterateUTXOsInSlot(slot, func(oid base.OutputID, oData []byte) bool {
 		o, err1 = ledger.OutputFromBytesReadOnly(oData)
 		glb.AssertNoError(err1)
+		res.Outputs = append(res.Outputs, glb.NewUTXOResult(&ledger.OutputWithID{ID: oid, Output: o}))
 		glb.Infof("%s", oid.String())
 		if glb.IsVerbose() {
 			glb.Infof("%s", o.LinesVerbose("     "))
@@ -80,5 +79,11 @@ func runUlist(_ *cobra.Command, args []string) {
 	})
 	glb.AssertNoError(err)
 	glb.Infof("-------------------\nTOTAL %d UTXOs", count)
+	glb.PrintResult(res)
+}
 
+type ulistResult struct {
+	BranchID string            `json:"branch_id"`
+	Slot     uint32            `json:"slot"`
+	Outputs  []*glb.UTXOResult `json:"outputs"`
 }
@@ -12,7 +12,7 @@ import (
 )
 
 func Infof(format string, args ...any) {
-	fmt.Printf(format+"\n", args...)
+	_, _ = fmt.Fprintf(Console(), format+"\n", args...)
 }
 
 func IsVerbose() bool {
@@ -31,13 +31,12 @@ func VerbosityLevel() int {
 
 func Verbosef(format string, args ...any) {
 	if IsVerbose() {
-		fmt.Printf(format+"\n", args...)
+		_, _ = fmt.Fprintf(Console(), format+"\n", args...)
 	}
 }
 
 func Fatalf(format string, args ...any) {
-	fmt.Printf("Error: "+format+"\n", args...)
-	os.Exit(1)
+	FatalfWithCode(ExitError, format, args...)
 }
 
 func AssertNoError(err error) {
@@ -52,10 +51,18 @@ func Assertf(cond bool, format string, args ...any) {
 	}
 }
 
+// YesNoPrompt asks the user. In JSON mode there is no dialogue: the prompt is bypassed
+// with '--force' or the command is aborted
 func YesNoPrompt(label string, def bool, force ...bool) bool {
 	if len(force) > 0 && force[0] {
 		return def
 	}
+	if IsJSONOutput() {
+		if BypassYesNoPrompt() {
+			return def
+		}
+		FatalfWithCode(ExitAborted, "confirmation required: '%s'. Use --force to proceed", strings.TrimSpace(label))
+	}
 	choices := "Y/n"
 	if !def {
 		choices = "y/N"
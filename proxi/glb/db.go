@@ -3,18 +3,17 @@ package glb
 import (
 	"os"
 
-	"github.com/dgraph-io/badger/v4"
 	"github.com/lunfardo314/proxima/global"
+	"github.com/lunfardo314/proxima/kvdb"
 	"github.com/lunfardo314/proxima/ledger"
 	"github.com/lunfardo314/proxima/ledger/multistate"
 	"github.com/lunfardo314/proxima/txstore"
-	"github.com/lunfardo314/unitrie/adaptors/badger_adaptor"
 )
 
 var (
-	stateDB      *badger.DB
+	stateDB      kvdb.DB
 	stateStore   multistate.StateStore
-	txBytesDB    *badger.DB
+	txBytesDB    kvdb.DB
 	txBytesStore global.TxBytesStore
 )
 
@@ -22,8 +21,8 @@ func InitLedgerFromDB() {
 	dbName := global.MultiStateDBName
 	Infof("Multi-state database: %s", dbName)
 	FileMustExist(dbName)
-	stateDB = badger_adaptor.MustCreateOrOpenBadgerDB(dbName)
-	stateStore = badger_adaptor.New(stateDB)
+	stateDB = MustOpenDB(dbName)
+	stateStore = stateDB
 	multistate.InitLedgerFromStore(stateStore)
 	Infof("ledger was initialized from definitions provided in database '%s'", global.MultiStateDBName)
 }
@@ -53,17 +52,24 @@ func InitTxStoreDB() {
 	txDBName := global.TxStoreDBName
 	Infof("Transaction store database: %s", txDBName)
 
-	txBytesDB = badger_adaptor.MustCreateOrOpenBadgerDB(txDBName)
-	txBytesStore = txstore.NewSimpleTxBytesStore(badger_adaptor.New(txBytesDB))
+	txBytesDB = MustOpenDB(txDBName)
+	txBytesStore = txstore.NewSimpleTxBytesStore(txBytesDB)
 }
 
 func TxBytesStore() global.TxBytesStore {
 	return txBytesStore
 }
 
-func InitDBRaw(dbName string) *badger_adaptor.DB {
+func InitDBRaw(dbName string) kvdb.DB {
 	Infof("Opening raw database: %s", dbName)
 	FileMustExist(dbName)
-	stateDB = badger_adaptor.MustCreateOrOpenBadgerDB(dbName)
-	return badger_adaptor.New(stateDB)
+	stateDB = MustOpenDB(dbName)
+	return stateDB
+}
+
+// MustOpenDB opens existing database with its backend or creates new one with the default backend
+func MustOpenDB(dbName string) kvdb.DB {
+	ret, err := kvdb.Open(dbName, "")
+	AssertNoError(err)
+	return ret
 }
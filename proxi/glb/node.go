@@ -1,7 +1,10 @@
 package glb
 
 import (
+	"crypto/tls"
+	"crypto/x509"
 	"encoding/hex"
+	"os"
 	"sync"
 	"time"
 
@@ -37,7 +40,15 @@ func GetClient(endpoint ...string) *client.APIClient {
 			Infof("using API endpoint: %s, timeout: %v", endpoint, timeout[0])
 		}
 	})
-	return client.NewWithGoogleDNS(endp, timeout...)
+	return client.NewWithGoogleDNS(endp, timeout...).WithAPIKey(GetAPIKey())
+}
+
+// GetAPIKey returns API key of the node API from the profile or from the PROXIMA_API_KEY environment variable
+func GetAPIKey() string {
+	if key := os.Getenv("PROXIMA_API_KEY"); key != "" {
+		return key
+	}
+	return viper.GetString("api.key")
 }
 
 func InitLedgerFromNode() {
@@ -61,3 +72,32 @@ func InitLedgerFromNode() {
 	h := ledger.L().LibraryHash()
 	Infof("ledger library hash: %s", hex.EncodeToString(h[:]))
 }
+
+// GetAdminClient creates client of the node's admin API from the 'admin' section of the profile
+func GetAdminClient() *client.AdminClient {
+	endp := viper.GetString("admin.endpoint")
+	Assertf(endp != "", "GetAdminClient: node admin API endpoint not specified")
+
+	token := viper.GetString("admin.token")
+	if envToken := os.Getenv("PROXIMA_ADMIN_TOKEN"); envToken != "" {
+		token = envToken
+	}
+	var tlsConfig *tls.Config
+	if certFile := viper.GetString("admin.tls.cert_file"); certFile != "" {
+		cert, err := tls.LoadX509KeyPair(certFile, viper.GetString("admin.tls.key_file"))
+		AssertNoError(err)
+		tlsConfig = &tls.Config{Certificates: []tls.Certificate{cert}}
+	}
+	if caFile := viper.GetString("admin.tls.ca_file"); caFile != "" {
+		caPEM, err := os.ReadFile(caFile)
+		AssertNoError(err)
+		pool := x509.NewCertPool()
+		Assertf(pool.AppendCertsFromPEM(caPEM), "can't parse CA certificate from %s", caFile)
+		if tlsConfig == nil {
+			tlsConfig = &tls.Config{}
+		}
+		tlsConfig.RootCAs = pool
+	}
+	Verbosef("using admin API endpoint: %s", endp)
+	return client.NewAdminClient(endp, token, tlsConfig)
+}
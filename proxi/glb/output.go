@@ -0,0 +1,140 @@
+package glb
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/lunfardo314/proxima/ledger"
+	"github.com/lunfardo314/proxima/ledger/base"
+	"github.com/spf13/viper"
+)
+
+// With '--output json' the stdout of proxi contains only JSON documents: results of the command or the error object.
+// Human-readable messages and prompts go to stderr
+
+const (
+	OutputText = "text"
+	OutputJSON = "json"
+)
+
+// exit codes of proxi
+const (
+	ExitOK      = 0
+	ExitError   = 1 // command failed
+	ExitUsage   = 2 // wrong command line
+	ExitAborted = 3 // declined by the user or confirmation is required
+)
+
+type (
+	// ErrorResult is printed to stdout in JSON mode when command fails
+	ErrorResult struct {
+		Error ErrorData `json:"error"`
+	}
+
+	ErrorData struct {
+		Message string `json:"message"`
+		Code    int    `json:"code"`
+	}
+
+	// UTXOResult is the output in the results of commands
+	UTXOResult struct {
+		ID      string `json:"id"`
+		Amount  uint64 `json:"amount"`
+		Lock    string `json:"lock"`
+		ChainID string `json:"chain_id,omitempty"`
+		Data    string `json:"data"`
+	}
+
+	// FileResult is the file written by the command
+	FileResult struct {
+		File string `json:"file"`
+	}
+
+	// TxResult is the transaction submitted by the command
+	TxResult struct {
+		TxID     string `json:"txid"`
+		Included bool   `json:"included"`
+	}
+)
+
+func NewUTXOResult(o *ledger.OutputWithID) *UTXOResult {
+	ret := &UTXOResult{
+		ID:     o.ID.StringHex(),
+		Amount: o.Output.Amount(),
+		Lock:   o.Output.Lock().String(),
+		Data:   o.Output.Hex(),
+	}
+	if chainID, _, ok := o.ExtractChainID(); ok {
+		ret.ChainID = chainID.StringHex()
+	}
+	return ret
+}
+
+func NewTxResult(txid base.TransactionID, included bool) *TxResult {
+	return &TxResult{TxID: txid.StringHex(), Included: included}
+}
+
+func OutputFormat() string {
+	if ret := viper.GetString("output"); ret != "" {
+		return ret
+	}
+	return OutputText
+}
+
+func IsJSONOutput() bool {
+	return OutputFormat() == OutputJSON
+}
+
+func CheckOutputFormat() {
+	switch f := OutputFormat(); f {
+	case OutputText, OutputJSON:
+	default:
+		// error must be readable by automation in any case
+		viper.Set("output", OutputJSON)
+		FatalfWithCode(ExitUsage, "wrong output format '%s'. Must be '%s' or '%s'", f, OutputText, OutputJSON)
+	}
+}
+
+// Console is the writer for human-readable output of the command
+func Console() io.Writer {
+	if IsJSONOutput() {
+		return os.Stderr
+	}
+	return os.Stdout
+}
+
+// PrintResult writes result of the command as one line of JSON to stdout. Does nothing in text mode.
+// Commands which produce results continuously write one line per result
+func PrintResult(v any) {
+	if !IsJSONOutput() {
+		return
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		FatalfWithCode(ExitError, "can't marshal result: %v", err)
+	}
+	_, _ = os.Stdout.Write(append(data, '\n'))
+}
+
+// FatalfWithCode terminates proxi with the error message and exit code
+func FatalfWithCode(code int, format string, args ...any) {
+	msg := fmt.Sprintf(format, args...)
+	if IsJSONOutput() {
+		data, _ := json.Marshal(&ErrorResult{Error: ErrorData{Message: msg, Code: code}})
+		_, _ = os.Stdout.Write(append(data, '\n'))
+	} else {
+		fmt.Printf("Error: %s\n", msg)
+	}
+	os.Exit(code)
+}
+
+// Abort terminates proxi when the user declines the prompt. In text mode it is not an error
+func Abort() {
+	if IsJSONOutput() {
+		FatalfWithCode(ExitAborted, "aborted")
+	}
+	Infof("exit")
+	os.Exit(ExitOK)
+}
@@ -13,7 +13,11 @@ import (
 	"github.com/spf13/viper"
 )
 
-const LedgerIDFileName = "proxima.genesis.id.yaml"
+const (
+	LedgerIDFileName = "proxima.genesis.id.yaml"
+	// LibraryUpgradesFileName is the default file with the schedule of ledger library upgrades
+	LibraryUpgradesFileName = "proxima.upgrades.yaml"
+)
 
 type WalletData struct {
 	PrivateKey ed25519.PrivateKey
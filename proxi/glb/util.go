@@ -78,7 +78,9 @@ func ParseAndDisplayTxBytes(txBytesWithMetadata []byte) {
 
 	Infof("--- transaction ---\n%s", tx.String())
 	Infof("--- metadata ---\n%s", meta.String())
-
+	PrintResult(&struct {
+		TxID string `json:"txid"`
+	}{tx.IDStringHex()})
 }
 
 func ParseAndDisplayTxFromSore(txid base.TransactionID) {
@@ -0,0 +1,288 @@
+package init_cmd
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/lunfardo314/proxima/ledger"
+	"golang.org/x/crypto/blake2b"
+	"gopkg.in/yaml.v2"
+)
+
+// Genesis ceremony of the multi-party ledger launch:
+//   - the distribution manifest contains hash of the ledger identity data and the initial distribution list.
+//     It is distributed among all parties for review
+//   - the manifest lists public keys of the parties which must approve it and the threshold: minimum number of approvals
+//   - each party checks the manifest against the ledger ID file and adds its approval: the signature of the manifest hash
+//   - the holder of the genesis controller key deterministically creates genesis state and distribution transaction from the manifest
+//   - each party verifies the resulting genesis database against the manifest
+
+const defaultGenesisManifestFileName = "genesis_manifest.yaml"
+
+type (
+	genesisManifest struct {
+		// hex-encoded library hash of the ledger identity data
+		LedgerIDHash string                    `yaml:"ledger_id_hash"`
+		Distribution []genesisManifestItem     `yaml:"distribution"`
+		Approvers    []genesisManifestApprover `yaml:"approvers"`
+		// minimum number of approvals by the listed approvers. 0 means all approvers
+		Threshold int                       `yaml:"threshold"`
+		Approvals []genesisManifestApproval `yaml:"approvals,omitempty"`
+	}
+
+	genesisManifestItem struct {
+		// EasyFL source of the lock, for example 'a(0x...)'
+		Lock   string `yaml:"lock"`
+		Amount uint64 `yaml:"amount"`
+		// if true, the output is chain origin
+		Chain bool `yaml:"chain"`
+		// informational, not included in the manifest hash
+		Comment string `yaml:"comment,omitempty"`
+	}
+
+	genesisManifestApprover struct {
+		Name      string `yaml:"name"`
+		PublicKey string `yaml:"public_key"`
+	}
+
+	genesisManifestApproval struct {
+		Name      string `yaml:"name"`
+		PublicKey string `yaml:"public_key"`
+		Signature string `yaml:"signature"`
+	}
+)
+
+const genesisManifestHeader = `# Proxima genesis distribution manifest.
+# The manifest hash covers 'ledger_id_hash', the 'distribution' list, except comments, public keys of 'approvers' and 'threshold'.
+# Approvals are signatures of the manifest hash by the listed approvers. 'threshold' is the minimum number
+# of approvals required by the genesis ceremony, 0 means all approvers
+`
+
+func readGenesisManifest(fname string) (*genesisManifest, error) {
+	data, err := os.ReadFile(fname)
+	if err != nil {
+		return nil, err
+	}
+	return parseGenesisManifest(data)
+}
+
+func parseGenesisManifest(data []byte) (*genesisManifest, error) {
+	ret := &genesisManifest{}
+	if err := yaml.UnmarshalStrict(data, ret); err != nil {
+		return nil, fmt.Errorf("wrong genesis manifest: %w", err)
+	}
+	if _, err := ret.ledgerIDHash(); err != nil {
+		return nil, err
+	}
+	if len(ret.Distribution) == 0 {
+		return nil, fmt.Errorf("wrong genesis manifest: distribution list is empty")
+	}
+	if _, err := ret.approverKeys(); err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
+
+func (m *genesisManifest) bytes() []byte {
+	data, err := yaml.Marshal(m)
+	if err != nil {
+		panic(err)
+	}
+	return append([]byte(genesisManifestHeader), data...)
+}
+
+func (m *genesisManifest) save(fname string) error {
+	return os.WriteFile(fname, m.bytes(), 0644)
+}
+
+func (m *genesisManifest) ledgerIDHash() ([32]byte, error) {
+	var ret [32]byte
+	h, err := hex.DecodeString(m.LedgerIDHash)
+	if err != nil || len(h) != 32 {
+		return ret, fmt.Errorf("wrong genesis manifest: 'ledger_id_hash' must be 32 bytes hex-encoded")
+	}
+	copy(ret[:], h)
+	return ret, nil
+}
+
+// distribution parses the distribution list. Ledger must be initialized
+func (m *genesisManifest) distribution() ([]ledger.LockBalance, error) {
+	ret := make([]ledger.LockBalance, len(m.Distribution))
+	for i, item := range m.Distribution {
+		accountable, err := ledger.AccountableFromSource(item.Lock)
+		if err != nil {
+			return nil, fmt.Errorf("distribution item #%d: wrong lock '%s': %w", i, item.Lock, err)
+		}
+		if item.Amount == 0 {
+			return nil, fmt.Errorf("distribution item #%d: amount must be positive", i)
+		}
+		ret[i] = ledger.LockBalance{
+			Lock:        accountable.AsLock(),
+			Balance:     item.Amount,
+			ChainOrigin: item.Chain,
+		}
+	}
+	return ret, nil
+}
+
+// approverKeys parses public keys of approvers and checks the threshold
+func (m *genesisManifest) approverKeys() ([]ed25519.PublicKey, error) {
+	ret := make([]ed25519.PublicKey, len(m.Approvers))
+	seen := make(map[string]struct{})
+	for i, a := range m.Approvers {
+		pubKey, err := hex.DecodeString(a.PublicKey)
+		if err != nil || len(pubKey) != ed25519.PublicKeySize {
+			return nil, fmt.Errorf("wrong genesis manifest: wrong public key of approver '%s'", a.Name)
+		}
+		if _, already := seen[string(pubKey)]; already {
+			return nil, fmt.Errorf("wrong genesis manifest: approver '%s' is listed twice", a.Name)
+		}
+		seen[string(pubKey)] = struct{}{}
+		ret[i] = pubKey
+	}
+	if m.Threshold < 0 || m.Threshold > len(m.Approvers) {
+		return nil, fmt.Errorf("wrong genesis manifest: threshold must be between 0 and number of approvers %d", len(m.Approvers))
+	}
+	return ret, nil
+}
+
+// requiredApprovals is the number of approvals required by the threshold
+func (m *genesisManifest) requiredApprovals() int {
+	if m.Threshold == 0 {
+		return len(m.Approvers)
+	}
+	return m.Threshold
+}
+
+func (m *genesisManifest) totalAmount() (ret uint64) {
+	for _, item := range m.Distribution {
+		ret += item.Amount
+	}
+	return
+}
+
+// hash is blake2b-256 of the ledger ID hash followed by 2 bytes number of distribution items, the binary encoded
+// distribution items: 2 bytes length of the lock, lock bytes, 8 bytes amount and 1 byte chain flag,
+// then 2 bytes threshold, 2 bytes number of approvers and public keys of approvers. Ledger must be initialized
+func (m *genesisManifest) hash() ([32]byte, error) {
+	idHash, err := m.ledgerIDHash()
+	if err != nil {
+		return [32]byte{}, err
+	}
+	distribution, err := m.distribution()
+	if err != nil {
+		return [32]byte{}, err
+	}
+	approverKeys, err := m.approverKeys()
+	if err != nil {
+		return [32]byte{}, err
+	}
+	var buf bytes.Buffer
+	buf.Write(idHash[:])
+	_ = binary.Write(&buf, binary.BigEndian, uint16(len(distribution)))
+	for _, lb := range distribution {
+		lockBytes := lb.Lock.Bytes()
+		_ = binary.Write(&buf, binary.BigEndian, uint16(len(lockBytes)))
+		buf.Write(lockBytes)
+		_ = binary.Write(&buf, binary.BigEndian, lb.Balance)
+		if lb.ChainOrigin {
+			buf.WriteByte(1)
+		} else {
+			buf.WriteByte(0)
+		}
+	}
+	_ = binary.Write(&buf, binary.BigEndian, uint16(m.Threshold))
+	_ = binary.Write(&buf, binary.BigEndian, uint16(len(approverKeys)))
+	for _, pubKey := range approverKeys {
+		buf.Write(pubKey)
+	}
+	return blake2b.Sum256(buf.Bytes()), nil
+}
+
+// approve adds or replaces approval by the private key. The key must be in the list of approvers
+func (m *genesisManifest) approve(name string, privateKey ed25519.PrivateKey) error {
+	h, err := m.hash()
+	if err != nil {
+		return err
+	}
+	approval := genesisManifestApproval{
+		Name:      name,
+		PublicKey: hex.EncodeToString(privateKey.Public().(ed25519.PublicKey)),
+		Signature: hex.EncodeToString(ed25519.Sign(privateKey, h[:])),
+	}
+	if m.approverIndex(approval.PublicKey) < 0 {
+		return fmt.Errorf("public key %s is not in the list of approvers of the manifest", approval.PublicKey)
+	}
+	for i := range m.Approvals {
+		if m.Approvals[i].PublicKey == approval.PublicKey {
+			m.Approvals[i] = approval
+			return nil
+		}
+	}
+	m.Approvals = append(m.Approvals, approval)
+	return nil
+}
+
+// approverIndex returns index of the approver with the hex-encoded public key or -1
+func (m *genesisManifest) approverIndex(pubKeyHex string) int {
+	pubKey, err := hex.DecodeString(pubKeyHex)
+	if err != nil {
+		return -1
+	}
+	for i, a := range m.Approvers {
+		if k, err := hex.DecodeString(a.PublicKey); err == nil && bytes.Equal(k, pubKey) {
+			return i
+		}
+	}
+	return -1
+}
+
+// verifyApprovals checks signatures of all approvals and the threshold. Returns error if any of approvals is invalid
+// or is not by the listed approver, or if there are fewer approvals than required
+func (m *genesisManifest) verifyApprovals() error {
+	h, err := m.hash()
+	if err != nil {
+		return err
+	}
+	if len(m.Approvers) == 0 {
+		return fmt.Errorf("genesis manifest does not list approvers")
+	}
+	approved := make([]bool, len(m.Approvers))
+	for _, a := range m.Approvals {
+		pubKey, err := hex.DecodeString(a.PublicKey)
+		if err != nil || len(pubKey) != ed25519.PublicKeySize {
+			return fmt.Errorf("approval of '%s': wrong public key", a.Name)
+		}
+		idx := m.approverIndex(a.PublicKey)
+		if idx < 0 {
+			return fmt.Errorf("approval of '%s': public key %s is not in the list of approvers", a.Name, a.PublicKey)
+		}
+		if approved[idx] {
+			return fmt.Errorf("approval of '%s': duplicate approval by approver '%s'", a.Name, m.Approvers[idx].Name)
+		}
+		sig, err := hex.DecodeString(a.Signature)
+		if err != nil {
+			return fmt.Errorf("approval of '%s': wrong signature: %w", a.Name, err)
+		}
+		if !ed25519.Verify(pubKey, h[:], sig) {
+			return fmt.Errorf("approval of '%s': signature is not valid for the manifest hash %s", a.Name, hex.EncodeToString(h[:]))
+		}
+		approved[idx] = true
+	}
+	missing := make([]string, 0)
+	for i, a := range m.Approvers {
+		if !approved[i] {
+			missing = append(missing, a.Name)
+		}
+	}
+	if required := m.requiredApprovals(); len(m.Approvers)-len(missing) < required {
+		return fmt.Errorf("%d approvals are required, the manifest has %d. Missing approvals of: %s",
+			required, len(m.Approvers)-len(missing), strings.Join(missing, ", "))
+	}
+	return nil
+}
@@ -0,0 +1,188 @@
+package init_cmd
+
+import (
+	"crypto/ed25519"
+	"encoding/hex"
+	"testing"
+
+	"github.com/lunfardo314/proxima/ledger"
+	"github.com/lunfardo314/proxima/ledger/multistate"
+	"github.com/lunfardo314/proxima/ledger/txbuilder"
+	"github.com/lunfardo314/proxima/util/testutil"
+	"github.com/lunfardo314/unitrie/common"
+	"github.com/stretchr/testify/require"
+)
+
+var genesisPrivateKey = ledger.InitWithTestingLedgerIDData()
+
+func testingApprover(name string, idx int) genesisManifestApprover {
+	return genesisManifestApprover{
+		Name:      name,
+		PublicKey: hex.EncodeToString(testutil.GetTestingPrivateKey(idx).Public().(ed25519.PublicKey)),
+	}
+}
+
+func testingManifest() *genesisManifest {
+	h := ledger.L().LibraryHash()
+	return &genesisManifest{
+		LedgerIDHash: hex.EncodeToString(h[:]),
+		Distribution: []genesisManifestItem{
+			{Lock: ledger.AddressED25519FromPrivateKey(testutil.GetTestingPrivateKey(1)).Source(), Amount: 1_000_000, Comment: "org 1"},
+			{Lock: ledger.AddressED25519FromPrivateKey(testutil.GetTestingPrivateKey(2)).Source(), Amount: 2_000_000, Chain: true},
+		},
+		Approvers: []genesisManifestApprover{testingApprover("org 1", 1), testingApprover("org 2", 2)},
+	}
+}
+
+func TestGenesisManifest(t *testing.T) {
+	m := testingManifest()
+	h, err := m.hash()
+	require.NoError(t, err)
+
+	// save and parse back, comments are not hashed
+	mBack, err := parseGenesisManifest(m.bytes())
+	require.NoError(t, err)
+	mBack.Distribution[0].Comment = "another comment"
+	hBack, err := mBack.hash()
+	require.NoError(t, err)
+	require.EqualValues(t, h, hBack)
+
+	// order of items matters
+	mBack.Distribution[0], mBack.Distribution[1] = mBack.Distribution[1], mBack.Distribution[0]
+	hBack, err = mBack.hash()
+	require.NoError(t, err)
+	require.NotEqualValues(t, h, hBack)
+
+	_, err = parseGenesisManifest([]byte("ledger_id_hash: 0102\ndistribution: []\n"))
+	require.Error(t, err)
+
+	// approvers and threshold are hashed
+	mBack, err = parseGenesisManifest(m.bytes())
+	require.NoError(t, err)
+	mBack.Threshold = 1
+	hBack, err = mBack.hash()
+	require.NoError(t, err)
+	require.NotEqualValues(t, h, hBack)
+	mBack.Threshold = 0
+	mBack.Approvers = mBack.Approvers[:1]
+	hBack, err = mBack.hash()
+	require.NoError(t, err)
+	require.NotEqualValues(t, h, hBack)
+
+	// wrong approvers or threshold
+	mBack.Threshold = 2
+	_, err = parseGenesisManifest(mBack.bytes())
+	require.Error(t, err)
+	mBack.Threshold = 0
+	mBack.Approvers = append(mBack.Approvers, testingApprover("org 1 again", 1))
+	_, err = parseGenesisManifest(mBack.bytes())
+	require.Error(t, err)
+
+	m.Distribution[0].Lock = "a(0x01)"
+	_, err = m.hash()
+	require.Error(t, err)
+}
+
+func TestGenesisManifestApprovals(t *testing.T) {
+	m := testingManifest()
+	require.NoError(t, m.approve("org 1", testutil.GetTestingPrivateKey(1)))
+	require.NoError(t, m.approve("org 2", testutil.GetTestingPrivateKey(2)))
+	require.NoError(t, m.verifyApprovals())
+	// approval by the same key is replaced
+	require.NoError(t, m.approve("org 2 again", testutil.GetTestingPrivateKey(2)))
+	require.Equal(t, 2, len(m.Approvals))
+	require.NoError(t, m.verifyApprovals())
+
+	// approvals are invalidated by the change of the distribution
+	m.Distribution[1].Amount++
+	require.Error(t, m.verifyApprovals())
+
+	t.Run("no approvals", func(t *testing.T) {
+		m := testingManifest()
+		require.Error(t, m.verifyApprovals())
+		m.Approvers = nil
+		require.Error(t, m.verifyApprovals())
+	})
+	t.Run("missing approval", func(t *testing.T) {
+		m := testingManifest()
+		require.NoError(t, m.approve("org 1", testutil.GetTestingPrivateKey(1)))
+		err := m.verifyApprovals()
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "org 2")
+	})
+	t.Run("threshold", func(t *testing.T) {
+		m := testingManifest()
+		m.Threshold = 1
+		require.Error(t, m.verifyApprovals())
+		require.NoError(t, m.approve("org 2", testutil.GetTestingPrivateKey(2)))
+		require.NoError(t, m.verifyApprovals())
+	})
+	t.Run("not listed approver", func(t *testing.T) {
+		m := testingManifest()
+		require.Error(t, m.approve("org 3", testutil.GetTestingPrivateKey(3)))
+		require.NoError(t, m.approve("org 1", testutil.GetTestingPrivateKey(1)))
+		require.NoError(t, m.approve("org 2", testutil.GetTestingPrivateKey(2)))
+		require.NoError(t, m.verifyApprovals())
+
+		// approval added by editing the file
+		h, err := m.hash()
+		require.NoError(t, err)
+		privKey := testutil.GetTestingPrivateKey(3)
+		m.Approvals = append(m.Approvals, genesisManifestApproval{
+			Name:      "org 3",
+			PublicKey: hex.EncodeToString(privKey.Public().(ed25519.PublicKey)),
+			Signature: hex.EncodeToString(ed25519.Sign(privKey, h[:])),
+		})
+		require.Error(t, m.verifyApprovals())
+	})
+	t.Run("duplicate approval", func(t *testing.T) {
+		m := testingManifest()
+		m.Threshold = 1
+		require.NoError(t, m.approve("org 1", testutil.GetTestingPrivateKey(1)))
+		m.Approvals = append(m.Approvals, m.Approvals[0])
+		require.Error(t, m.verifyApprovals())
+	})
+}
+
+func TestGenesisCeremony(t *testing.T) {
+	m := testingManifest()
+	require.NoError(t, m.approve("org 1", testutil.GetTestingPrivateKey(1)))
+	require.NoError(t, m.approve("org 2", testutil.GetTestingPrivateKey(2)))
+	distribution, err := m.distribution()
+	require.NoError(t, err)
+
+	makeGenesis := func() (multistate.StateStore, [2]string) {
+		store := common.NewInMemoryKVStore()
+		multistate.InitStateStoreWithGlobalLedgerIdentity(store)
+		_, txid, err := txbuilder.DistributeInitialSupplyExt(store, genesisPrivateKey, distribution)
+		require.NoError(t, err)
+		rr, found := multistate.FetchRootRecord(store, txid)
+		require.True(t, found)
+		return store, [2]string{txid.StringHex(), rr.Root.String()}
+	}
+	// deterministic
+	store, res1 := makeGenesis()
+	_, res2 := makeGenesis()
+	require.EqualValues(t, res1, res2)
+
+	res, err := verifyGenesisDB(store, nil, m)
+	require.NoError(t, err)
+	require.Equal(t, res1[0], res.TxID)
+	require.Equal(t, res1[1], res.StateRoot)
+	require.EqualValues(t, ledger.L().ID.InitialSupply-3_000_000, res.Remainder)
+	require.Equal(t, []string{"org 1", "org 2"}, res.Approvals)
+
+	// approvals are required
+	mNoApprovals := testingManifest()
+	_, err = verifyGenesisDB(store, nil, mNoApprovals)
+	require.Error(t, err)
+
+	m.Distribution[1].Chain = false
+	_, err = verifyGenesisDB(store, nil, m)
+	require.Error(t, err)
+
+	m = testingManifest()
+	m.Distribution = m.Distribution[:1]
+	_, err = verifyGenesisDB(store, nil, m)
+	require.Error(t, err)
+}
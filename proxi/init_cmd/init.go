@@ -17,6 +17,10 @@ func CmdInit() *cobra.Command {
 		initGenesisDBCmd(),
 		initBootstrapAccountCmd(),
 		initNodeConfigCmd(),
+		initGenesisManifestCmd(),
+		initApproveManifestCmd(),
+		initGenesisCeremonyCmd(),
+		initVerifyGenesisCmd(),
 	)
 	initCmd.InitDefaultHelpCmd()
 	return initCmd
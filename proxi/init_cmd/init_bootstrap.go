@@ -4,7 +4,6 @@ import (
 	"os"
 	"strconv"
 
-	"github.com/dgraph-io/badger/v4"
 	"github.com/lunfardo314/proxima/core/txmetadata"
 	"github.com/lunfardo314/proxima/global"
 	"github.com/lunfardo314/proxima/ledger"
@@ -13,7 +12,6 @@ import (
 	"github.com/lunfardo314/proxima/proxi/glb"
 	"github.com/lunfardo314/proxima/txstore"
 	"github.com/lunfardo314/proxima/util"
-	"github.com/lunfardo314/unitrie/adaptors/badger_adaptor"
 	"github.com/spf13/cobra"
 	"gopkg.in/yaml.v2"
 )
@@ -39,9 +37,8 @@ const (
 func runBootstrapAccount(_ *cobra.Command, args []string) {
 	// initialize ledger
 	glb.FileMustExist(global.MultiStateDBName)
-	stateDb := badger_adaptor.MustCreateOrOpenBadgerDB(global.MultiStateDBName, badger.DefaultOptions(global.MultiStateDBName))
-	stateStore := badger_adaptor.New(stateDb)
-	defer func() { _ = stateDb.Close() }()
+	stateStore := glb.MustOpenDB(global.MultiStateDBName)
+	defer func() { _ = stateStore.Close() }()
 
 	multistate.InitLedgerFromStore(stateStore)
 	privKey := glb.MustGetPrivateKey()
@@ -65,7 +62,7 @@ func runBootstrapAccount(_ *cobra.Command, args []string) {
 		glb.Fatalf("exit: bootstrap account wasn't created")
 	}
 
-	txStoreDB := badger_adaptor.New(badger_adaptor.MustCreateOrOpenBadgerDB(global.TxStoreDBName, badger.DefaultOptions(global.TxStoreDBName)))
+	txStoreDB := glb.MustOpenDB(global.TxStoreDBName)
 	txStore := txstore.NewSimpleTxBytesStore(txStoreDB)
 	defer func() { _ = txStoreDB.Close() }()
 
@@ -0,0 +1,97 @@
+package init_cmd
+
+import (
+	"encoding/hex"
+	"fmt"
+
+	"github.com/lunfardo314/proxima/core/txmetadata"
+	"github.com/lunfardo314/proxima/global"
+	"github.com/lunfardo314/proxima/ledger/multistate"
+	"github.com/lunfardo314/proxima/ledger/txbuilder"
+	"github.com/lunfardo314/proxima/proxi/glb"
+	"github.com/lunfardo314/proxima/txstore"
+	"github.com/lunfardo314/proxima/util"
+	"github.com/spf13/cobra"
+)
+
+func initGenesisCeremonyCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use: "genesis_ceremony",
+		Short: fmt.Sprintf("creates genesis state and transaction store DBs with the initial distribution transaction "+
+			"according to the genesis manifest and ledger identity in '%s'. Requires genesis controller private key in the wallet", glb.LedgerIDFileName),
+		Long: `creates multi-state DB with genesis ledger state and the initial distribution transaction according to the genesis manifest.
+The distribution transaction is stored in the transaction store DB, so that other nodes could sync from the genesis.
+Approvals of the listed approvers are required according to the threshold in the manifest.
+The result is deterministic: same manifest, ledger identity and genesis controller key always produce the same transaction and state root.
+The resulting databases can be checked against the manifest by each party with 'proxi init verify_genesis'`,
+		PersistentPreRun: func(_ *cobra.Command, _ []string) {
+			glb.ReadInConfig()
+		},
+		Args: cobra.NoArgs,
+		Run:  runGenesisCeremonyCmd,
+	}
+	addManifestFlag(cmd)
+	return cmd
+}
+
+type genesisCeremonyResult struct {
+	ManifestHash string `json:"manifest_hash"`
+	LedgerIDHash string `json:"ledger_id_hash"`
+	TxID         string `json:"txid"`
+	StateRoot    string `json:"state_root"`
+	Approvals    int    `json:"approvals"`
+}
+
+func runGenesisCeremonyCmd(_ *cobra.Command, _ []string) {
+	glb.DirMustNotExistOrBeEmpty(global.MultiStateDBName)
+	glb.FileMustNotExist(global.TxStoreDBName)
+
+	manifest, h := mustLoadManifestForLedgerIDFile()
+	glb.AssertNoError(manifest.verifyApprovals())
+	distribution, err := manifest.distribution()
+	glb.AssertNoError(err)
+
+	privKey := glb.MustGetPrivateKey()
+
+	glb.Infof(manifestLines(manifest).String())
+	glb.Infof("manifest hash: %s", hex.EncodeToString(h[:]))
+	glb.Infof("Multi-state database name: '%s'", global.MultiStateDBName)
+	glb.Infof("Transaction store database name: '%s'", global.TxStoreDBName)
+	if !glb.YesNoPrompt("Proceed?", true) {
+		glb.Fatalf("exit: genesis databases weren't created")
+	}
+
+	stateStore := glb.MustOpenDB(global.MultiStateDBName)
+	defer func() { _ = stateStore.Close() }()
+
+	bootstrapChainID, _ := multistate.InitStateStoreWithGlobalLedgerIdentity(stateStore)
+	glb.Infof("genesis state has been created. Bootstrap sequencer chainID: %s", bootstrapChainID.String())
+
+	txStoreDB := glb.MustOpenDB(global.TxStoreDBName)
+	txStore := txstore.NewSimpleTxBytesStore(txStoreDB)
+	defer func() { _ = txStoreDB.Close() }()
+
+	txBytes, txid, err := txbuilder.DistributeInitialSupplyExt(stateStore, privKey, distribution)
+	glb.AssertNoError(err)
+
+	rr, found := multistate.FetchRootRecord(stateStore, txid)
+	glb.Assertf(found, "inconsistency: can't find root record")
+
+	_, err = txStore.PersistTxBytesWithMetadata(txBytes, &txmetadata.TransactionMetadata{
+		StateRoot:     rr.Root,
+		CoverageDelta: util.Ref(rr.CoverageDelta),
+		SlotInflation: util.Ref(rr.SlotInflation),
+		Supply:        util.Ref(rr.Supply),
+	})
+	glb.AssertNoError(err)
+	util.Assertf(len(txStore.GetTxBytesWithMetadata(&txid)) > 0, "inconsistency: just stored transaction has not been found")
+
+	glb.Infof("Success. Initial distribution transaction: %s\nstate root: %s", txid.String(), rr.Root.String())
+	glb.PrintResult(&genesisCeremonyResult{
+		ManifestHash: hex.EncodeToString(h[:]),
+		LedgerIDHash: manifest.LedgerIDHash,
+		TxID:         txid.StringHex(),
+		StateRoot:    rr.Root.String(),
+		Approvals:    len(manifest.Approvals),
+	})
+}
@@ -2,16 +2,15 @@ package init_cmd
 
 import (
 	"encoding/hex"
+	"errors"
 	"fmt"
 	"os"
 
-	"github.com/dgraph-io/badger/v4"
 	"github.com/lunfardo314/proxima/global"
 	"github.com/lunfardo314/proxima/ledger"
 	"github.com/lunfardo314/proxima/ledger/base"
 	"github.com/lunfardo314/proxima/ledger/multistate"
 	"github.com/lunfardo314/proxima/proxi/glb"
-	"github.com/lunfardo314/unitrie/adaptors/badger_adaptor"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
@@ -22,7 +21,8 @@ func initGenesisDBCmd() *cobra.Command {
 	genesisCmd := &cobra.Command{
 		Use: "genesis_db",
 		Short: fmt.Sprintf("creates multi-state DB and initializes genesis ledger state init according "+
-			"ledger id data taken either from file '%s' (default) or from another API endpoint specified with flag -r", glb.LedgerIDFileName),
+			"ledger id data and schedule of library upgrades taken either from files '%s' and '%s' (default) "+
+			"or from another API endpoint specified with flag -r", glb.LedgerIDFileName, glb.LibraryUpgradesFileName),
 		Args: cobra.NoArgs,
 		Run:  runGenesis,
 	}
@@ -38,17 +38,24 @@ func runGenesis(_ *cobra.Command, _ []string) {
 
 	var err error
 	var idDataYAML []byte
+	var upgrades *ledger.LibraryUpgrades
 
 	if remoteEndpoint != "" {
 		glb.ReadInConfig()
-		glb.Infof("retrieving ledger identity data from '%s'", remoteEndpoint)
+		glb.Infof("retrieving ledger identity data and library upgrades from '%s'", remoteEndpoint)
 		idDataYAML, err = glb.GetClient(remoteEndpoint).GetLedgerIdentityData()
 		glb.AssertNoError(err)
+		upgrades, err = glb.GetClient(remoteEndpoint).GetLibraryUpgrades()
+		glb.AssertNoError(err)
 	} else {
 		glb.Infof("reading ledger identity data from file '%s'", glb.LedgerIDFileName)
 		// take ledger id data from the 'proxi.genesis.id.yaml'
 		idDataYAML, err = os.ReadFile(glb.LedgerIDFileName)
 		glb.AssertNoError(err)
+		upgrades = mustReadLibraryUpgradesFile()
+	}
+	if upgrades != nil {
+		glb.AssertNoError(checkLibraryUpgrades(idDataYAML, upgrades))
 	}
 
 	// parse and validate
@@ -59,6 +66,9 @@ func runGenesis(_ *cobra.Command, _ []string) {
 	glb.Infof(idParams.Lines("      ").String())
 	h := lib.LibraryHash()
 	glb.Infof("library hash: %s", hex.EncodeToString(h[:]))
+	if upgrades != nil {
+		glb.Infof("ledger library upgrades will be committed in the genesis state:\n%s", upgrades.Lines("      ").String())
+	}
 	glb.Infof("Multi-state database name: '%s'", global.MultiStateDBName)
 
 	if !glb.YesNoPrompt("Proceed?", true) {
@@ -66,12 +76,33 @@ func runGenesis(_ *cobra.Command, _ []string) {
 	}
 
 	// create state store and initialize genesis state
-	stateDb := badger_adaptor.MustCreateOrOpenBadgerDB(global.MultiStateDBName, badger.DefaultOptions(global.MultiStateDBName))
-	stateStore := badger_adaptor.New(stateDb)
+	stateStore := glb.MustOpenDB(global.MultiStateDBName)
 	defer func() { _ = stateStore.Close() }()
 
-	ledger.MustInitSingleton(idDataYAML)
+	ledger.MustInitSingleton(idDataYAML, upgrades)
 
 	bootstrapChainID, _ := multistate.InitStateStoreWithGlobalLedgerIdentity(stateStore)
 	glb.Infof("Genesis state DB '%s' has been created successfully.\nBootstrap sequencer chainID: %s", global.MultiStateDBName, bootstrapChainID.String())
 }
+
+// mustReadLibraryUpgradesFile reads schedule of ledger library upgrades from the file or returns nil if the file does not exist
+func mustReadLibraryUpgradesFile() *ledger.LibraryUpgrades {
+	data, err := os.ReadFile(glb.LibraryUpgradesFileName)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	glb.AssertNoError(err)
+	glb.Infof("reading ledger library upgrades from file '%s'", glb.LibraryUpgradesFileName)
+	upgrades, err := ledger.ParseLibraryUpgradesYAML(data)
+	glb.AssertNoError(err)
+	return upgrades
+}
+
+// checkLibraryUpgrades checks if the schedule is compiled and consistent with the ledger identity
+func checkLibraryUpgrades(idDataYAML []byte, upgrades *ledger.LibraryUpgrades) error {
+	if err := upgrades.CheckCompiled(); err != nil {
+		return fmt.Errorf("%w. Compile it with 'proxi util compile_upgrades'", err)
+	}
+	_, err := ledger.CompileLibraryUpgrades(idDataYAML, upgrades)
+	return err
+}
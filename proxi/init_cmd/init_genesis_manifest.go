@@ -0,0 +1,154 @@
+package init_cmd
+
+import (
+	"encoding/hex"
+	"fmt"
+	"os"
+
+	"github.com/lunfardo314/proxima/ledger"
+	"github.com/lunfardo314/proxima/ledger/base"
+	"github.com/lunfardo314/proxima/proxi/glb"
+	"github.com/lunfardo314/proxima/util"
+	"github.com/lunfardo314/proxima/util/lines"
+	"github.com/spf13/cobra"
+)
+
+var manifestFile string
+
+func addManifestFlag(cmd *cobra.Command) {
+	cmd.PersistentFlags().StringVarP(&manifestFile, "manifest", "m", defaultGenesisManifestFileName, "file name of the genesis distribution manifest")
+}
+
+func initGenesisManifestCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use: "genesis_manifest",
+		Short: fmt.Sprintf("creates genesis distribution manifest for the ledger identity in '%s'. "+
+			"Distribution list contains bootstrap account of the wallet and accounts from 'distribution.yaml', if present", glb.LedgerIDFileName),
+		PersistentPreRun: func(_ *cobra.Command, _ []string) {
+			glb.ReadInConfig()
+		},
+		Args: cobra.NoArgs,
+		Run:  runGenesisManifestCmd,
+	}
+	addManifestFlag(cmd)
+	return cmd
+}
+
+func runGenesisManifestCmd(_ *cobra.Command, _ []string) {
+	if glb.FileExists(manifestFile) {
+		if !glb.YesNoPrompt(fmt.Sprintf("file '%s' already exists. Overwrite", manifestFile), false) {
+			glb.Abort()
+		}
+	}
+	idHash := mustInitLedgerFromIDFile()
+
+	distribution := getAdditionalDistribution([]ledger.LockBalance{
+		{Lock: glb.GetWalletData().Account, Balance: defaultBootstrapBalance},
+	})
+	manifest := &genesisManifest{
+		LedgerIDHash: hex.EncodeToString(idHash[:]),
+		Distribution: make([]genesisManifestItem, len(distribution)),
+		Approvers:    make([]genesisManifestApprover, 0),
+	}
+	for i, lb := range distribution {
+		manifest.Distribution[i] = genesisManifestItem{
+			Lock:   lb.Lock.Source(),
+			Amount: lb.Balance,
+			Chain:  lb.ChainOrigin,
+		}
+	}
+	manifest.Distribution[0].Comment = "bootstrap account"
+
+	h, err := manifest.hash()
+	glb.AssertNoError(err)
+	glb.AssertNoError(manifest.save(manifestFile))
+
+	glb.Infof("genesis manifest has been saved to '%s'", manifestFile)
+	glb.Infof("manifest hash: %s", hex.EncodeToString(h[:]))
+	glb.Infof("add names and public keys of the parties to 'approvers' before sending the manifest for approval")
+	glb.PrintResult(&glb.FileResult{File: manifestFile})
+}
+
+func initApproveManifestCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "approve_manifest <name>",
+		Short: "checks genesis manifest against the ledger identity and adds approval signed by the wallet private key. The wallet key must be in the list of approvers",
+		PersistentPreRun: func(_ *cobra.Command, _ []string) {
+			glb.ReadInConfig()
+		},
+		Args: cobra.ExactArgs(1),
+		Run:  runApproveManifestCmd,
+	}
+	addManifestFlag(cmd)
+	return cmd
+}
+
+func runApproveManifestCmd(_ *cobra.Command, args []string) {
+	manifest, h := mustLoadManifestForLedgerIDFile()
+	glb.Infof(manifestLines(manifest).String())
+	glb.Infof("manifest hash: %s", hex.EncodeToString(h[:]))
+
+	if !glb.YesNoPrompt(fmt.Sprintf("Approve the manifest as '%s'?", args[0]), false) {
+		glb.Abort()
+	}
+	glb.AssertNoError(manifest.approve(args[0], glb.MustGetPrivateKey()))
+	glb.AssertNoError(manifest.save(manifestFile))
+	glb.Infof("approval has been added to '%s'", manifestFile)
+	glb.PrintResult(&glb.FileResult{File: manifestFile})
+}
+
+// mustInitLedgerFromIDFile initializes ledger from the ledger ID file and the schedule of library upgrades, if any.
+// Returns hash of the ledger ID
+func mustInitLedgerFromIDFile() [32]byte {
+	idDataYAML, err := os.ReadFile(glb.LedgerIDFileName)
+	glb.AssertNoError(err)
+	lib, _, err := ledger.ParseLedgerIdYAML(idDataYAML, base.GetEmbeddedFunctionResolver)
+	glb.AssertNoError(err)
+	upgrades := mustReadLibraryUpgradesFile()
+	if upgrades != nil {
+		glb.AssertNoError(checkLibraryUpgrades(idDataYAML, upgrades))
+	}
+	ledger.MustInitSingleton(idDataYAML, upgrades)
+	return lib.LibraryHash()
+}
+
+// mustLoadManifestForLedgerIDFile initializes ledger from the ledger ID file, reads the manifest and checks
+// if both match each other. Returns the manifest and its hash
+func mustLoadManifestForLedgerIDFile() (*genesisManifest, [32]byte) {
+	idHash := mustInitLedgerFromIDFile()
+	manifest, err := readGenesisManifest(manifestFile)
+	glb.AssertNoError(err)
+	manifestIDHash, err := manifest.ledgerIDHash()
+	glb.AssertNoError(err)
+	glb.Assertf(manifestIDHash == idHash, "ledger ID hash in the manifest %s is not equal to the hash of '%s' %s",
+		manifest.LedgerIDHash, glb.LedgerIDFileName, hex.EncodeToString(idHash[:]))
+	h, err := manifest.hash()
+	glb.AssertNoError(err)
+	return manifest, h
+}
+
+func manifestLines(manifest *genesisManifest) *lines.Lines {
+	ret := lines.New()
+	ret.Add("ledger ID hash: %s", manifest.LedgerIDHash)
+	ret.Add("distribution (%d items, total %s):", len(manifest.Distribution), util.Th(manifest.totalAmount()))
+	for i, item := range manifest.Distribution {
+		chain := ""
+		if item.Chain {
+			chain = ", chain origin"
+		}
+		comment := ""
+		if item.Comment != "" {
+			comment = "  # " + item.Comment
+		}
+		ret.Add("   #%d: %s -> %s%s%s", i, util.Th(item.Amount), item.Lock, chain, comment)
+	}
+	ret.Add("approvers (%d, required approvals %d):", len(manifest.Approvers), manifest.requiredApprovals())
+	for _, a := range manifest.Approvers {
+		ret.Add("   %s (public key %s)", a.Name, a.PublicKey)
+	}
+	ret.Add("approvals: %d", len(manifest.Approvals))
+	for _, a := range manifest.Approvals {
+		ret.Add("   %s (public key %s)", a.Name, a.PublicKey)
+	}
+	return ret
+}
@@ -121,10 +121,94 @@ peering:
   # defines if local IPs are allowed to be used for autopeering.
   allow_local_ips: false
 
+  # limits of incoming messages from one peer, per protocol. Enabled by default with the values below
+  rate_limit:
+    disable: false
+    # if true, messages over the limit are dropped. Otherwise, reading from the peer is delayed (backpressure)
+    drop: false
+    gossip:
+      msg_per_sec: 2000
+      bytes_per_sec: 20971520
+    pull:
+      msg_per_sec: 500
+      bytes_per_sec: 1048576
+    heartbeat:
+      msg_per_sec: 5
+      bytes_per_sec: 1024
+    announce:
+      msg_per_sec: 2000
+      bytes_per_sec: 20971520
+    # bytes of transactions sent to the peer in response to its pull requests, including past cones.
+    # Over the limit, the response is cut and the peer has to pull the rest later
+    pull_response:
+      bytes_per_sec: 4194304
+
+  gossip:
+    # if true, only IDs of new transactions are sent to peers which support it. Peers request only
+    # transactions they did not see yet. Saves bandwidth taken by duplicates at the cost of one round trip
+    announce: false
+    # if true, transaction messages are compressed for peers which enable compression too
+    compression: false
+
 # Node's API config
 api:
     # server port
   port: {{.APIPort}}
+    # scopes granted to requests without API key: 'read' and/or 'submit' (submit_tx).
+    # Empty list means API key is required for all requests. Default is both scopes
+  anonymous_scopes: [read, submit]
+    # API keys are sent in the 'X-API-Key' header or as a bearer token
+#  keys:
+#    frontend:
+#      key: <secret key>
+#      scopes: [read, submit]
+#        # overrides 'rate_limit.per_key' for this key
+#      rate_limit:
+#        rps: 100
+#        burst: 200
+    # token bucket rate limits for each endpoint. Requests with API key are limited per key, others per IP
+  rate_limit:
+    enable: false
+    per_ip:
+      rps: 10
+      burst: 20
+    per_key:
+      rps: 50
+      burst: 100
+      # use client IP from the 'X-Forwarded-For' header. Enable only behind the trusted reverse proxy
+    trust_forwarded_for: false
+      # overrides of limits by endpoint name
+    endpoints:
+      submit_tx:
+        per_ip:
+          rps: 1
+          burst: 5
+#  tls:
+#    cert_file: api.crt
+#    key_file: api.key
+  cors:
+    allowed_origins: ["*"]
+    max_age_sec: 600
+    # web socket streams are served on the API port with the same API keys and rate limits
+  streaming_enable: false
+    # maximum number of simultaneously connected stream clients
+  streaming_max_connections: 100
+
+# runtime admin API: trace tags, static peers, blacklist, snapshots, sequencer, memDAG graphs
+# served on the separate listener. Requires bearer token, mTLS or both
+admin_api:
+  enable: false
+    # listen address. Keep it local unless TLS is configured
+  listen: 127.0.0.1:8002
+    # bearer token. Can also be provided in the PROXIMA_ADMIN_TOKEN environment variable
+  token: ""
+#  tls:
+#    cert_file: admin.crt
+#    key_file: admin.key
+#      # when specified, client certificate signed by this CA is required (mTLS)
+#    client_ca_file: admin_ca.crt
+    # directory where memDAG graphs are saved
+  graph_dir: graphs
 
 snapshot:
   enable: false
@@ -135,6 +219,51 @@ snapshot:
     # keep latest up to 3 snapshots, older ones will be purged
   keep_latest: 2
 
+# accounting of delegation rewards, served by the /api/v1/delegation_history endpoint
+delegation_history:
+    # when disabled, delegation history is collected only on request
+  disable: false
+    # maximum number of transitions kept for each delegation
+  max_transitions: 2000
+    # history of the delegation is dropped when the chain is not seen in the latest reliable branch for that many slots
+  keep_slots: 10000
+
+# embedded database engine of the multi-state database and the transaction store: 'badger' (default), 'bolt' or 'memwal'.
+# 'bolt' has smaller memory footprint. 'memwal' keeps all data in memory and is intended for tests.
+# Existing database is converted to another backend with 'proxi db migrate'
+db:
+  backend: badger
+
+# rejected transactions are kept in the transaction store, served by the /api/v1/tx_rejections endpoint
+tx_rejections:
+  # maximum number of kept rejections. The oldest are overwritten. Kept rejections are deleted when the size changes
+  ring_size: 10000
+
+# frontier of the memDAG (latest sequencer milestones and transactions not yet in the latest reliable branch) is saved
+# at shutdown and reloaded from the transaction store at startup, so the restarted node is productive within seconds
+warm_restart:
+  disable: false
+
+# offset of the local clock is estimated from heartbeats of peers. The skew and the correction of the ledger time
+# are estimated from static peers only, so dynamic peers cannot stop the sequencer
+clock:
+  # sequencer does not produce milestones while the skew of the ledger time exceeds the maximum
+  max_skew_millis: 3000
+  # minimum number of static peers needed for the estimate. With fewer static peers, the skew is not checked
+  min_peers: 2
+  correction:
+    # if enabled, the ledger time is corrected by the estimated offset, up to the maximum
+    enable: false
+    max_millis: 1000
+
+# fork monitor compares the latest branches and baselines of sequencer tips. Lineages of competing branches with
+# significant coverage, persisting for the number of slots, are reported as fork in sync_info and metrics
+fork_monitor:
+  disable: false
+  slots: 3
+  # minimum coverage delta of the competing branch, percentage of the supply
+  min_coverage_percentage: 20
+
 # logger config
 # logger.previous can be 'erase' or 'save'
 logger:
@@ -0,0 +1,216 @@
+package init_cmd
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/lunfardo314/proxima/core/txmetadata"
+	"github.com/lunfardo314/proxima/global"
+	"github.com/lunfardo314/proxima/ledger"
+	"github.com/lunfardo314/proxima/ledger/base"
+	"github.com/lunfardo314/proxima/ledger/multistate"
+	"github.com/lunfardo314/proxima/ledger/transaction"
+	"github.com/lunfardo314/proxima/ledger/txbuilder"
+	"github.com/lunfardo314/proxima/proxi/glb"
+	"github.com/lunfardo314/proxima/util"
+	"github.com/spf13/cobra"
+)
+
+func initVerifyGenesisCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "verify_genesis",
+		Short: "verifies that the genesis multi-state DB and the initial distribution transaction match the genesis manifest",
+		Long: `verifies that the multi-state DB contains only the genesis state and the initial distribution branch,
+that ledger identity in the DB has the ledger ID hash of the genesis manifest and that the initial distribution
+transaction produced exactly the outputs of the manifest, with the remainder on the bootstrap chain.
+If transaction store DB is present, also checks the stored distribution transaction and its metadata.
+Signatures of all approvals in the manifest are verified too`,
+		Args: cobra.NoArgs,
+		Run:  runVerifyGenesisCmd,
+	}
+	addManifestFlag(cmd)
+	return cmd
+}
+
+type genesisVerificationResult struct {
+	ManifestHash   string   `json:"manifest_hash"`
+	LedgerIDHash   string   `json:"ledger_id_hash"`
+	TxID           string   `json:"txid"`
+	StateRoot      string   `json:"state_root"`
+	BootstrapChain string   `json:"bootstrap_chain"`
+	Remainder      uint64   `json:"remainder"`
+	TxStoreChecked bool     `json:"tx_store_checked"`
+	Approvals      []string `json:"approvals"`
+}
+
+func runVerifyGenesisCmd(_ *cobra.Command, _ []string) {
+	glb.InitLedgerFromDB()
+	defer glb.CloseDatabases()
+
+	var txStore global.TxBytesGet
+	if glb.FileExists(global.TxStoreDBName) {
+		glb.InitTxStoreDB()
+		txStore = glb.TxBytesStore()
+	} else {
+		glb.Infof("transaction store DB '%s' not found, the distribution transaction will be checked in the state only", global.TxStoreDBName)
+	}
+
+	manifest, err := readGenesisManifest(manifestFile)
+	glb.AssertNoError(err)
+	glb.Infof(manifestLines(manifest).String())
+
+	res, err := verifyGenesisDB(glb.StateStore(), txStore, manifest)
+	if err != nil {
+		glb.Fatalf("genesis DB does NOT match the manifest '%s': %v", manifestFile, err)
+	}
+	glb.Infof("genesis DB MATCHES the manifest '%s'", manifestFile)
+	glb.Infof("manifest hash:       %s", res.ManifestHash)
+	glb.Infof("ledger ID hash:      %s", res.LedgerIDHash)
+	glb.Infof("distribution tx:     %s", res.TxID)
+	glb.Infof("state root:          %s", res.StateRoot)
+	glb.Infof("bootstrap chain:     %s, remainder %s", res.BootstrapChain, util.Th(res.Remainder))
+	glb.Infof("valid approvals:     %d", len(res.Approvals))
+	glb.PrintResult(res)
+}
+
+// verifyGenesisDB checks the genesis DB against the manifest. Ledger must be initialized from the same DB.
+// Transaction store is optional
+func verifyGenesisDB(stateStore multistate.StateStore, txStore global.TxBytesGet, manifest *genesisManifest) (*genesisVerificationResult, error) {
+	// ledger identity
+	manifestIDHash, err := manifest.ledgerIDHash()
+	if err != nil {
+		return nil, err
+	}
+	lib, stateID, err := ledger.ParseLedgerIdYAML(multistate.LedgerIdentityBytesFromStore(stateStore), base.GetEmbeddedFunctionResolver)
+	if err != nil {
+		return nil, err
+	}
+	if idHash := lib.LibraryHash(); idHash != manifestIDHash {
+		return nil, fmt.Errorf("ledger ID hash in the DB %s is not equal to ledger ID hash in the manifest %s",
+			hex.EncodeToString(idHash[:]), manifest.LedgerIDHash)
+	}
+	h, err := manifest.hash()
+	if err != nil {
+		return nil, err
+	}
+	if err = manifest.verifyApprovals(); err != nil {
+		return nil, err
+	}
+	distribution, err := manifest.distribution()
+	if err != nil {
+		return nil, err
+	}
+
+	// the DB must contain exactly genesis branch and the distribution branch
+	genesisTxID := base.GenesisTransactionID()
+	var txid base.TransactionID
+	var rr multistate.RootRecord
+	genesisFound, numBranches := false, 0
+	multistate.IterateRootRecords(stateStore, func(branchTxID base.TransactionID, rootData multistate.RootRecord) bool {
+		numBranches++
+		if branchTxID == genesisTxID {
+			genesisFound = true
+		} else {
+			txid, rr = branchTxID, rootData
+		}
+		return true
+	})
+	if !genesisFound {
+		return nil, fmt.Errorf("genesis branch not found")
+	}
+	if numBranches != 2 {
+		return nil, fmt.Errorf("expected exactly 2 branches in the DB: genesis and initial distribution, found %d", numBranches)
+	}
+	if txid.Slot() != 1 {
+		return nil, fmt.Errorf("initial distribution branch %s is expected in slot 1", txid.String())
+	}
+	if rr.Supply != stateID.InitialSupply {
+		return nil, fmt.Errorf("supply in the initial distribution branch %s is not equal to initial supply %s",
+			util.Th(rr.Supply), util.Th(stateID.InitialSupply))
+	}
+
+	rdr, err := multistate.NewReadable(stateStore, rr.Root)
+	if err != nil {
+		return nil, err
+	}
+	sugared := multistate.MakeSugared(rdr)
+
+	// remainder stays on the bootstrap chain
+	bootstrapChainID := stateID.OriginChainID()
+	chainOut, err := sugared.GetChainOutput(bootstrapChainID)
+	if err != nil {
+		return nil, fmt.Errorf("bootstrap chain output not found: %w", err)
+	}
+	if chainOut.ID.TransactionID() != txid {
+		return nil, fmt.Errorf("bootstrap chain output %s is not produced by the distribution transaction", chainOut.ID.String())
+	}
+	total := manifest.totalAmount()
+	if total > stateID.InitialSupply || chainOut.Output.Amount() != stateID.InitialSupply-total {
+		return nil, fmt.Errorf("amount on the bootstrap chain %s is not equal to initial supply minus distributed %s",
+			util.Th(chainOut.Output.Amount()), util.Th(total))
+	}
+	stemOut := sugared.GetStemOutput()
+	if stemOut.ID.TransactionID() != txid {
+		return nil, fmt.Errorf("stem output %s is not produced by the distribution transaction", stemOut.ID.String())
+	}
+
+	// distribution outputs in the same order as in the manifest
+	produced := make(map[byte][]byte)
+	err = rdr.IterateUTXOsInSlot(txid.Slot(), func(oid base.OutputID, oData []byte) bool {
+		if oid.TransactionID() == txid && oid != chainOut.ID && oid != stemOut.ID {
+			produced[oid.Index()] = oData
+		}
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(produced) != len(distribution) {
+		return nil, fmt.Errorf("the distribution transaction produced %d outputs, the manifest contains %d items", len(produced), len(distribution))
+	}
+	indices := util.KeysSorted(produced, func(i1, i2 byte) bool { return i1 < i2 })
+	for i, o := range txbuilder.MakeDistributionOutputs(distribution) {
+		if !bytes.Equal(produced[indices[i]], o.Bytes()) {
+			return nil, fmt.Errorf("output #%d of the distribution transaction does not match item #%d of the manifest", indices[i], i)
+		}
+	}
+
+	ret := &genesisVerificationResult{
+		ManifestHash:   hex.EncodeToString(h[:]),
+		LedgerIDHash:   manifest.LedgerIDHash,
+		TxID:           txid.StringHex(),
+		StateRoot:      rr.Root.String(),
+		BootstrapChain: bootstrapChainID.StringHex(),
+		Remainder:      chainOut.Output.Amount(),
+		Approvals:      make([]string, 0, len(manifest.Approvals)),
+	}
+	for _, a := range manifest.Approvals {
+		ret.Approvals = append(ret.Approvals, a.Name)
+	}
+	if txStore == nil {
+		return ret, nil
+	}
+
+	// stored transaction must be the distribution transaction with metadata consistent with the state
+	txBytesWithMetadata := txStore.GetTxBytesWithMetadata(&txid)
+	if len(txBytesWithMetadata) == 0 {
+		return nil, fmt.Errorf("distribution transaction %s not found in the transaction store", txid.String())
+	}
+	txBytes, metadata, err := txmetadata.ParseTxMetadata(txBytesWithMetadata)
+	if err != nil {
+		return nil, err
+	}
+	tx, err := transaction.FromBytesMainChecksWithOpt(txBytes)
+	if err != nil {
+		return nil, err
+	}
+	if tx.ID() != txid {
+		return nil, fmt.Errorf("wrong transaction in the transaction store: expected %s, got %s", txid.String(), tx.IDString())
+	}
+	if metadata == nil || util.IsNil(metadata.StateRoot) || !ledger.CommitmentModel.EqualCommitments(metadata.StateRoot, rr.Root) {
+		return nil, fmt.Errorf("state root in the metadata of the stored distribution transaction is not equal to the root in the DB")
+	}
+	ret.TxStoreChecked = true
+	return ret, nil
+}
@@ -67,6 +67,21 @@ wallet:
 api:
     # API endpoint of the node 
     endpoint: http://127.0.0.1:8000
+    # API key, if required by the node. Can also be provided in the PROXIMA_API_KEY environment variable
+    key: ""
+
+# provides parameters for 'proxi admin' commands
+admin:
+    # admin API endpoint of the node
+    endpoint: http://127.0.0.1:8002
+    # bearer token. Can also be provided in the PROXIMA_ADMIN_TOKEN environment variable
+    token: ""
+#    tls:
+#      # client certificate and key for mTLS
+#      cert_file: client.crt
+#      key_file: client.key
+#      # CA to verify the node's certificate
+#      ca_file: admin_ca.crt
 
 tag_along:
     # id of the tag-along sequencer. Currently only one tag-along sequencer is supported
@@ -79,17 +94,24 @@ tag_along:
 faucet:
     port:  9500
     host:  113.30.191.219
+    # access token, if required by the faucet
+    token: ""
 
 # provides parameters for 'proxi node spam' command
-# The spammer in a loop sends bundles of transactions to the target address by using specified tag-along sequencer
-# Before sending next bundle, the spammer waits for the finality of the previous according to the provided criterion
+# With the scenario file, the load test is run according to the scenario (see 'proxi node spam -h' and docs/proxi.md).
+# Without it, the wallet sends transfers to the target address at the constant pace.
+# Each transaction pays tag-along fee and is tracked until it is included into the latest reliable branch
 spammer:
-    bundle_size: 5
+    # YAML file with the load test scenario
+#    scenario: loadtest.yaml
     output_amount: 1000
+    # pace in ticks between transactions
     pace: 25
+    # transaction not included into the LRB in finality_slots is counted as timed out
+    finality_slots: 5
     tag_along:
         fee: 50
-        # <sequencer ID hex encoded> is tag-along sequencer id for the tip transaction in the bundle
+        # <sequencer ID hex encoded> is tag-along sequencer id
         # If not specified, the default sequencer id will be used
         # sequencer_id: <sequencer id hex encoded>
     # target address
@@ -4,11 +4,13 @@ import (
 	"os"
 	"strings"
 
+	"github.com/lunfardo314/proxima/proxi/admin_cmd"
 	"github.com/lunfardo314/proxima/proxi/db_cmd"
 	"github.com/lunfardo314/proxima/proxi/glb"
 	"github.com/lunfardo314/proxima/proxi/init_cmd"
 	"github.com/lunfardo314/proxima/proxi/node_cmd"
 	"github.com/lunfardo314/proxima/proxi/snapshot_cmd"
+	"github.com/lunfardo314/proxima/proxi/top_cmd"
 	"github.com/lunfardo314/proxima/proxi/util_cmd"
 	"github.com/lunfardo314/proxima/proxi/version"
 	"github.com/spf13/cobra"
@@ -16,7 +18,15 @@ import (
 )
 
 func main() {
-	glb.Infof("Command line: '%s'", strings.Join(os.Args, " "))
+	defer func() {
+		// in JSON mode, panic is reported as the error object
+		if r := recover(); r != nil {
+			if !glb.IsJSONOutput() {
+				panic(r)
+			}
+			glb.Fatalf("%v", r)
+		}
+	}()
 
 	rootCmd := &cobra.Command{
 		Use:   "proxi",
@@ -44,16 +54,32 @@ and withdraw funds from the sequencer chain
 	err = viper.BindPFlag("force", rootCmd.PersistentFlags().Lookup("force"))
 	glb.AssertNoError(err)
 
+	rootCmd.PersistentFlags().String("output", glb.OutputText, "output format: 'text' or 'json'. With 'json', stdout contains only results and errors as JSON")
+	err = viper.BindPFlag("output", rootCmd.PersistentFlags().Lookup("output"))
+	glb.AssertNoError(err)
+
+	// called when flags are parsed
+	cobra.OnInitialize(func() {
+		glb.CheckOutputFormat()
+		glb.Infof("Command line: '%s'", strings.Join(os.Args, " "))
+	})
+
 	rootCmd.AddCommand(
 		init_cmd.CmdInit(),
 		db_cmd.Init(),
 		node_cmd.Init(),
+		admin_cmd.Init(),
 		util_cmd.Init(),
 		snapshot_cmd.Init(),
+		top_cmd.Init(),
 		version.CmdVersion(),
 	)
 	rootCmd.InitDefaultHelpCmd()
 	if err = rootCmd.Execute(); err != nil {
-		os.Exit(1)
+		// cobra has already reported the error to stderr
+		if glb.IsJSONOutput() {
+			glb.FatalfWithCode(glb.ExitUsage, "%v", err)
+		}
+		os.Exit(glb.ExitUsage)
 	}
 }
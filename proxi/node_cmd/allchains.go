@@ -3,6 +3,8 @@ package node_cmd
 import (
 	"fmt"
 
+	"github.com/lunfardo314/proxima/api"
+
 	"github.com/lunfardo314/proxima/ledger"
 	"github.com/lunfardo314/proxima/ledger/base"
 	"github.com/lunfardo314/proxima/proxi/glb"
@@ -52,18 +54,21 @@ func runAllChainsCmd(_ *cobra.Command, _ []string) {
 
 	if showDelegationsOnly && showSequencersOnly {
 		listSequencerDelegationInfo(rr.Supply)
+		return
+	}
+	chains, _, err := clnt.GetAllChains()
+	glb.AssertNoError(err)
+	res := &chainListResult{LRBID: lrbid.StringHex()}
+	if groupByDelegationTarget {
+		res.Chains = listGrouped(chains)
 	} else {
-		chains, _, err := clnt.GetAllChains()
-		glb.AssertNoError(err)
-		if groupByDelegationTarget {
-			listGrouped(chains)
-		} else {
-			listChains(chains)
-		}
+		res.Chains = listChains(chains)
 	}
+	glb.PrintResult(res)
 }
 
-func listChains(chains []*ledger.OutputWithChainID) {
+func listChains(chains []*ledger.OutputWithChainID) []chainResult {
+	ret := make([]chainResult, 0, len(chains))
 	glb.Infof("\nshow sequencers only = %v", showSequencersOnly)
 	glb.Infof("show delegations only = %v", showDelegationsOnly)
 	glb.Infof("------------------------------")
@@ -94,11 +99,15 @@ func listChains(chains []*ledger.OutputWithChainID) {
 		glb.Infof("      controller lock : %s", lock.String())
 		glb.Infof("      output          : %s", o.ID.String())
 		count++
+		ret = append(ret, newChainResult(o))
 	}
 	glb.Infof("\ntotal %d chains", count)
+	return ret
 }
 
-func listGrouped(chains []*ledger.OutputWithChainID) {
+// listGrouped returns delegation chains in the order of listing
+func listGrouped(chains []*ledger.OutputWithChainID) []chainResult {
+	ret := make([]chainResult, 0)
 	glb.Infof("\ndelegations grouped by target lock")
 	m := make(map[string][]*ledger.OutputWithChainID)
 
@@ -129,11 +138,13 @@ func listGrouped(chains []*ledger.OutputWithChainID) {
 			glb.Infof("      output          : %s", o.ID.String())
 			totalForTarget += o.Output.Amount()
 			count++
+			ret = append(ret, newChainResult(o))
 		}
 		glb.Infof("\n------ Amount delegated to the target lock: %s", util.Th(totalForTarget))
 		total += totalForTarget
 	}
 	glb.Infof("\nTOTAL delegations: %d, delegated amount: %s", count, util.Th(total))
+	return ret
 }
 
 func listSequencerDelegationInfo(supply uint64) {
@@ -191,4 +202,18 @@ func listSequencerDelegationInfo(supply uint64) {
 	glb.Infof("TOTAL SUPPLY          :  %s", util.Th(supply))
 	glb.Infof("TOTAL DELEGATIONS     :  %d", totalDelegations)
 	glb.Infof("TOTAL DELEGATED AMOUNT:  %s (%.2f%% of supply)", util.Th(totalDelegated), 100*float64(totalDelegated)/float64(supply))
+
+	glb.PrintResult(&sequencerDelegationsResult{
+		Supply:           supply,
+		TotalDelegations: totalDelegations,
+		TotalDelegated:   totalDelegated,
+		Sequencers:       bySeq,
+	})
+}
+
+type sequencerDelegationsResult struct {
+	Supply           uint64                                `json:"supply"`
+	TotalDelegations int                                   `json:"total_delegations"`
+	TotalDelegated   uint64                                `json:"total_delegated"`
+	Sequencers       map[string]api.DelegationsOnSequencer `json:"sequencers"`
 }
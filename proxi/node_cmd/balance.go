@@ -28,17 +28,43 @@ func runBalanceCmd(_ *cobra.Command, _ []string) {
 	outs, lrbid, err := glb.GetClient().GetAccountOutputs(accountable)
 	glb.AssertNoError(err)
 	glb.PrintLRB(lrbid)
-	displayBalanceTotals(outs, accountable)
+	res := displayBalanceTotals(outs, accountable)
+	res.LRBID = lrbid.StringHex()
+	glb.PrintResult(res)
 }
 
-type _delegation struct {
-	amount     uint64
-	inflation  uint64
-	sinceSlot  base.Slot
-	lastActive base.Slot
-}
+type (
+	_delegation struct {
+		amount     uint64
+		inflation  uint64
+		sinceSlot  base.Slot
+		lastActive base.Slot
+	}
+
+	balanceResult struct {
+		Account           string             `json:"account"`
+		LRBID             string             `json:"lrbid"`
+		NumNonChain       int                `json:"num_non_chain"`
+		AmountNonChain    uint64             `json:"amount_non_chain"`
+		NumChains         int                `json:"num_chains"`
+		AmountOnChains    uint64             `json:"amount_on_chains"`
+		NumDelegations    int                `json:"num_delegations"`
+		AmountDelegations uint64             `json:"amount_delegations"`
+		Total             uint64             `json:"total"`
+		Delegations       []delegationResult `json:"delegations,omitempty"`
+	}
 
-func displayBalanceTotals(outs []*ledger.OutputWithID, target ledger.Accountable) {
+	delegationResult struct {
+		ID         string  `json:"id"`
+		Amount     uint64  `json:"amount"`
+		Inflation  uint64  `json:"inflation"`
+		AnnualRate float64 `json:"annual_rate"`
+		SinceSlot  uint32  `json:"since_slot"`
+		LastActive uint32  `json:"last_active"`
+	}
+)
+
+func displayBalanceTotals(outs []*ledger.OutputWithID, target ledger.Accountable) *balanceResult {
 	var sumOnChains, sumOutsideChains, sumDelegation uint64
 	var numChains, numNonChains, numDelegation int
 
@@ -78,9 +104,19 @@ func displayBalanceTotals(outs []*ledger.OutputWithID, target ledger.Accountable
 	glb.Infof("    %d chain outputs (including delegation): %s", numChains, util.Th(sumOnChains))
 	glb.Infof("    %d delegation outputs:                   %s", numDelegation, util.Th(sumDelegation))
 	glb.Infof("-----------------\nTOTAL controlled on %d outputs: %s", numChains+numNonChains, util.Th(sumOnChains+sumOutsideChains))
+	ret := &balanceResult{
+		Account:           target.String(),
+		NumNonChain:       numNonChains,
+		AmountNonChain:    sumOutsideChains,
+		NumChains:         numChains,
+		AmountOnChains:    sumOnChains,
+		NumDelegations:    numDelegation,
+		AmountDelegations: sumDelegation,
+		Total:             sumOnChains + sumOutsideChains,
+	}
 	if len(delegations) == 0 {
 		glb.Infof("\nNO DELEGATIONS")
-		return
+		return ret
 	}
 	glb.Infof("\nDELEGATIONS:")
 	ids := util.KeysSorted(delegations, func(k1, k2 base.ChainID) bool {
@@ -99,6 +135,15 @@ func displayBalanceTotals(outs []*ledger.OutputWithID, target ledger.Accountable
 		glb.Infof("     %s   %20s (+%s, %.1f%% annual) since/last active slot: %d/%d",
 			id.String(), util.Th(d.amount), util.Th(d.inflation), annualRate, d.sinceSlot, d.lastActive)
 		totalDelegated += d.amount
+		ret.Delegations = append(ret.Delegations, delegationResult{
+			ID:         id.StringHex(),
+			Amount:     d.amount,
+			Inflation:  d.inflation,
+			AnnualRate: annualRate,
+			SinceSlot:  uint32(d.sinceSlot),
+			LastActive: uint32(d.lastActive),
+		})
 	}
 	glb.Infof("----------------\nTOTAL DELEGATED AMOUNT: %s", util.Th(totalDelegated))
+	return ret
 }
@@ -0,0 +1,547 @@
+package node_cmd
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lunfardo314/proxima/ledger"
+	"github.com/lunfardo314/proxima/ledger/base"
+	"github.com/lunfardo314/proxima/ledger/transaction"
+	"github.com/lunfardo314/proxima/ledger/txbuilder"
+	"github.com/lunfardo314/proxima/proxi/glb"
+	"github.com/lunfardo314/proxima/util"
+	"github.com/lunfardo314/proxima/util/lines"
+	"github.com/spf13/cobra"
+)
+
+type (
+	batchRecipient struct {
+		Target string `json:"target"`
+		Amount uint64 `json:"amount"`
+
+		// line in CSV file or index in JSON array, for error messages
+		line int
+		lock ledger.Lock
+	}
+
+	// batchRange is the range of recipients [from, to) paid by one transaction
+	batchRange struct {
+		from, to int
+	}
+)
+
+const (
+	// 256 outputs minus tag-along and remainder outputs
+	maxBatchOutputsPerTx     = 254
+	defaultBatchOutputsPerTx = 250
+	// total size of recipient outputs in one transaction. Leaves enough space for inputs and unlock data
+	maxBatchOutputBytes = 48_000
+	// batch transaction not included into LRB in that many slots is considered failed
+	batchInclusionTimeoutSlots = 10
+	// number of attempts to rebuild failed batches in one run
+	maxBatchRebuildAttempts = 3
+)
+
+var (
+	batchOutputsPerTx int
+	batchJournalFile  string
+	batchDryRun       bool
+)
+
+func initBatchTransferCmd() *cobra.Command {
+	batchTransferCmd := &cobra.Command{
+		Use:   "batch_transfer <CSV or JSON file>",
+		Short: `sends tokens from the wallet's account to many recipients listed in the file, packed into as few transactions as possible`,
+		Long: `sends tokens from the wallet's account to many recipients listed in the file.
+CSV file contains lines '<target lock in EasyFL format>,<amount>', optional header and '#' comments.
+JSON file contains array of objects {"target": "<target lock in EasyFL format>", "amount": <amount>}.
+Recipients are packed into transactions in the order of the file. Each next transaction consumes remainder of the previous.
+Submitted transactions are recorded in the journal file. If the command fails or is interrupted, run it again with
+the same file: batches already included into the LRB are skipped, the rest are re-submitted or rebuilt`,
+		Args: cobra.ExactArgs(1),
+		Run:  runBatchTransferCmd,
+	}
+	batchTransferCmd.PersistentFlags().IntVar(&batchOutputsPerTx, "per_tx", defaultBatchOutputsPerTx,
+		fmt.Sprintf("maximum number of recipients in one transaction, up to %d", maxBatchOutputsPerTx))
+	batchTransferCmd.PersistentFlags().StringVar(&batchJournalFile, "journal", "", "journal file. Default is <file>.journal")
+	batchTransferCmd.PersistentFlags().BoolVar(&batchDryRun, "dry_run", false, "check the file and display the batches without submitting")
+
+	batchTransferCmd.InitDefaultHelpCmd()
+	return batchTransferCmd
+}
+
+func runBatchTransferCmd(_ *cobra.Command, args []string) {
+	glb.InitLedgerFromNode()
+	glb.Assertf(0 < batchOutputsPerTx && batchOutputsPerTx <= maxBatchOutputsPerTx, "--per_tx must be > 0 and <= %d", maxBatchOutputsPerTx)
+
+	fname := args[0]
+	data, err := os.ReadFile(fname)
+	glb.AssertNoError(err)
+	recipients, err := parseBatchRecipients(data, strings.HasSuffix(strings.ToLower(fname), ".json"))
+	glb.AssertNoError(err)
+	glb.Assertf(len(recipients) > 0, "no recipients in %s", fname)
+
+	sizes, err := resolveBatchRecipients(recipients)
+	glb.AssertNoError(err)
+	batches := packBatches(sizes, batchOutputsPerTx, maxBatchOutputBytes)
+
+	walletData := glb.GetWalletData()
+	feeAmount := glb.GetTagAlongFee()
+	glb.Assertf(feeAmount > 0, "tag-along fee is configured 0. Fee-less option not supported yet")
+	tagAlongSeqID := glb.GetTagAlongSequencerID()
+	glb.Assertf(tagAlongSeqID != nil, "tag-along sequencer not specified")
+
+	md, err := glb.GetClient().GetMilestoneData(*tagAlongSeqID)
+	glb.AssertNoError(err)
+	if md != nil && md.MinimumFee > feeAmount {
+		feeAmount = md.MinimumFee
+	}
+
+	total := uint64(0)
+	for _, r := range recipients {
+		total += r.Amount
+	}
+	glb.Infof("source is the wallet account: %s", walletData.Account.String())
+	glb.Infof("%d recipients, total amount %s, %d transactions, total fees %s paid to the tag-along sequencer %s",
+		len(recipients), util.Th(total), len(batches), util.Th(feeAmount*uint64(len(batches))), tagAlongSeqID.StringShort())
+
+	if batchDryRun {
+		glb.Infof(batchesLines(recipients, batches, "   ").String())
+		glb.PrintResult(&batchTransferResult{Batches: batchResults(batches, nil)})
+		return
+	}
+
+	if batchJournalFile == "" {
+		batchJournalFile = fname + ".journal"
+	}
+	journal, resumed, err := openBatchJournal(batchJournalFile, recipients, batches)
+	glb.AssertNoError(err)
+	if resumed {
+		glb.Infof("resuming from the journal %s", batchJournalFile)
+	} else {
+		glb.Infof("submitted transactions will be recorded in the journal %s", batchJournalFile)
+	}
+	if !glb.YesNoPrompt("Proceed?", true, glb.BypassYesNoPrompt()) {
+		glb.Abort()
+	}
+
+	bt := &batchTransfer{
+		recipients:    recipients,
+		batches:       batches,
+		journal:       journal,
+		walletData:    walletData,
+		tagAlongSeqID: *tagAlongSeqID,
+		fee:           feeAmount,
+	}
+	bt.run()
+}
+
+// parseBatchRecipients parses CSV or JSON list of recipients
+func parseBatchRecipients(data []byte, isJSON bool) ([]*batchRecipient, error) {
+	if isJSON || bytes.HasPrefix(bytes.TrimSpace(data), []byte("[")) {
+		var ret []*batchRecipient
+		if err := json.Unmarshal(data, &ret); err != nil {
+			return nil, fmt.Errorf("failed to parse JSON: %w", err)
+		}
+		for i, r := range ret {
+			r.line = i
+			r.Target = strings.TrimSpace(r.Target)
+			if r.Target == "" {
+				return nil, fmt.Errorf("item %d: target is empty", i)
+			}
+			if r.Amount == 0 {
+				return nil, fmt.Errorf("item %d: amount is 0", i)
+			}
+		}
+		return ret, nil
+	}
+
+	rdr := csv.NewReader(bytes.NewReader(data))
+	rdr.Comment = '#'
+	rdr.TrimLeadingSpace = true
+	rdr.FieldsPerRecord = 2
+
+	ret := make([]*batchRecipient, 0)
+	for {
+		rec, err := rdr.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		line, _ := rdr.FieldPos(0)
+		amount, err := strconv.ParseUint(strings.ReplaceAll(strings.TrimSpace(rec[1]), "_", ""), 10, 64)
+		if err != nil {
+			if len(ret) == 0 {
+				// header
+				continue
+			}
+			return nil, fmt.Errorf("line %d: wrong amount '%s'", line, rec[1])
+		}
+		target := strings.TrimSpace(rec[0])
+		if target == "" {
+			return nil, fmt.Errorf("line %d: target is empty", line)
+		}
+		if amount == 0 {
+			return nil, fmt.Errorf("line %d: amount is 0", line)
+		}
+		ret = append(ret, &batchRecipient{Target: target, Amount: amount, line: line})
+	}
+	return ret, nil
+}
+
+// resolveBatchRecipients parses target locks and checks storage deposit. Returns sizes of the outputs
+func resolveBatchRecipients(recipients []*batchRecipient) ([]int, error) {
+	ret := make([]int, len(recipients))
+	errs := make([]error, 0)
+	for i, r := range recipients {
+		accountable, err := ledger.AccountableFromSource(r.Target)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("line %d: wrong target '%s': %v", r.line, r.Target, err))
+			continue
+		}
+		r.lock = accountable.AsLock()
+		o := ledger.NewOutput(func(o *ledger.OutputBuilder) {
+			o.WithAmount(r.Amount).WithLock(r.lock)
+		})
+		if err = o.EnoughAmountForStorageDeposit(ledger.TimeNow().Slot); err != nil {
+			errs = append(errs, fmt.Errorf("line %d: %v", r.line, err))
+			continue
+		}
+		ret[i] = len(o.Bytes())
+	}
+	return ret, errors.Join(errs...)
+}
+
+// packBatches splits outputs into consecutive batches limited by number of outputs and their total size
+func packBatches(sizes []int, maxOutputs, maxBytes int) []batchRange {
+	ret := make([]batchRange, 0)
+	cur := batchRange{}
+	curBytes := 0
+	for i, sz := range sizes {
+		if cur.to > cur.from && (cur.to-cur.from >= maxOutputs || curBytes+sz > maxBytes) {
+			ret = append(ret, cur)
+			cur = batchRange{from: i, to: i}
+			curBytes = 0
+		}
+		cur.to++
+		curBytes += sz
+	}
+	if cur.to > cur.from {
+		ret = append(ret, cur)
+	}
+	return ret
+}
+
+func batchesLines(recipients []*batchRecipient, batches []batchRange, prefix ...string) *lines.Lines {
+	ret := lines.New(prefix...)
+	for i, b := range batches {
+		sum := uint64(0)
+		for _, r := range recipients[b.from:b.to] {
+			sum += r.Amount
+		}
+		ret.Add("batch #%d: recipients %d..%d (%d), amount %s", i, b.from, b.to-1, b.to-b.from, util.Th(sum))
+	}
+	return ret
+}
+
+type (
+	batchTransferResult struct {
+		Journal string        `json:"journal,omitempty"`
+		Batches []batchResult `json:"batches"`
+		// true if all batches are included into the LRB
+		Included bool `json:"included"`
+	}
+
+	batchResult struct {
+		Batch int `json:"batch"`
+		// recipients [from, to) in the order of the file
+		From int    `json:"from"`
+		To   int    `json:"to"`
+		TxID string `json:"txid,omitempty"`
+	}
+)
+
+// batchResults lists batches with recorded transactions, if any
+func batchResults(batches []batchRange, journal *batchJournal) []batchResult {
+	ret := make([]batchResult, len(batches))
+	for i, b := range batches {
+		ret[i] = batchResult{Batch: i, From: b.from, To: b.to}
+		if journal != nil {
+			if txid, _, ok := journal.get(i); ok {
+				ret[i].TxID = txid.StringHex()
+			}
+		}
+	}
+	return ret
+}
+
+type batchTransfer struct {
+	recipients    []*batchRecipient
+	batches       []batchRange
+	journal       *batchJournal
+	walletData    glb.WalletData
+	tagAlongSeqID base.ChainID
+	fee           uint64
+}
+
+func (bt *batchTransfer) run() {
+	for attempt := 0; ; attempt++ {
+		start := bt.firstNotIncluded()
+		if start == len(bt.batches) {
+			glb.Infof("all %d batches are included into the LRB. The journal %s can be removed", len(bt.batches), bt.journal.fname)
+			glb.PrintResult(&batchTransferResult{
+				Journal:  bt.journal.fname,
+				Batches:  batchResults(bt.batches, bt.journal),
+				Included: true,
+			})
+			return
+		}
+		glb.Assertf(attempt < maxBatchRebuildAttempts, "failed to complete batch transfer after %d attempts. Run the command again to resume", attempt)
+
+		if _, _, recorded := bt.journal.get(start); recorded {
+			if bt.resubmitRecorded(start) {
+				continue
+			}
+			if !bt.inputsSpent(start) {
+				txid, _, _ := bt.journal.get(start)
+				glb.Fatalf("transaction %s of batch #%d is not included but its inputs are not spent. %s\nRun the command again to resume",
+					txid.String(), start, rejectionReasonString(txid))
+			}
+			if bt.isIncluded(start) {
+				continue
+			}
+			glb.Infof("transaction of batch #%d can't be included anymore. Batches #%d..#%d will be rebuilt", start, start, len(bt.batches)-1)
+		}
+		txids := bt.buildAndSubmit(start)
+		if glb.NoWait() {
+			glb.Infof("run the command again to check the inclusion and to resume if necessary")
+			glb.PrintResult(&batchTransferResult{
+				Journal: bt.journal.fname,
+				Batches: batchResults(bt.batches, bt.journal),
+			})
+			return
+		}
+		bt.waitIncluded(txids)
+	}
+}
+
+func (bt *batchTransfer) isIncluded(batch int) bool {
+	txid, _, ok := bt.journal.get(batch)
+	if !ok {
+		return false
+	}
+	_, depth, err := glb.GetClient().CheckTransactionIDInLRB(txid, 0)
+	return err == nil && depth >= 0
+}
+
+func (bt *batchTransfer) firstNotIncluded() int {
+	for i := range bt.batches {
+		if !bt.isIncluded(i) {
+			return i
+		}
+	}
+	return len(bt.batches)
+}
+
+// resubmitRecorded submits again recorded transactions of consecutive batches and waits for their inclusion.
+// Returns true if all of them were included
+func (bt *batchTransfer) resubmitRecorded(start int) bool {
+	txids := make([]base.TransactionID, 0)
+	for i := start; i < len(bt.batches); i++ {
+		txid, txBytes, ok := bt.journal.get(i)
+		if !ok {
+			break
+		}
+		if err := glb.GetClient().SubmitTransaction(txBytes); err != nil {
+			glb.Verbosef("re-submit of batch #%d transaction %s failed: %v", i, txid.StringShort(), err)
+		}
+		txids = append(txids, txid)
+	}
+	glb.Infof("re-submitted %d recorded transactions starting from batch #%d", len(txids), start)
+	return bt.waitIncluded(txids)
+}
+
+// inputsSpent returns true if any input of the recorded transaction of the batch is not in the LRB
+func (bt *batchTransfer) inputsSpent(batch int) bool {
+	_, txBytes, ok := bt.journal.get(batch)
+	glb.Assertf(ok, "inconsistency: batch #%d is not recorded", batch)
+	tx, err := transaction.FromBytes(txBytes)
+	glb.AssertNoError(err)
+
+	spent := false
+	tx.ForEachInput(func(_ byte, oid base.OutputID) bool {
+		oData, err1 := glb.GetClient().GetOutputData(&oid)
+		glb.AssertNoError(err1)
+		spent = oData == nil
+		return !spent
+	})
+	return spent
+}
+
+func rejectionReasonString(txid base.TransactionID) string {
+	rej, err := glb.GetClient().GetTxRejections(txid)
+	if err != nil || len(rej.Rejections) == 0 {
+		return "The node has no record about its rejection"
+	}
+	return fmt.Sprintf("It was rejected by the node: %s", rej.Rejections[0].Reason)
+}
+
+// buildAndSubmit builds chain of transactions for batches starting from start, records and submits them
+func (bt *batchTransfer) buildAndSubmit(start int) []base.TransactionID {
+	required := uint64(0)
+	for _, b := range bt.batches[start:] {
+		for _, r := range bt.recipients[b.from:b.to] {
+			required += r.Amount
+		}
+		required += bt.fee
+	}
+	walletOuts, lrbid, _, err := glb.GetClient().GetTransferableOutputs(bt.walletData.Account, 256)
+	glb.AssertNoError(err)
+	glb.PrintLRB(lrbid)
+
+	sum := uint64(0)
+	walletOuts = util.PurgeSlice(walletOuts, func(o *ledger.OutputWithID) bool {
+		if sum >= required {
+			return false
+		}
+		sum += o.Output.Amount()
+		return true
+	})
+	glb.Assertf(sum >= required, "not enough tokens: required %s, available on %d outputs %s. Use 'proxi node compact' to compact outputs",
+		util.Th(required), len(walletOuts), util.Th(sum))
+
+	txs, err := bt.makeTransactions(start, walletOuts)
+	glb.AssertNoError(err)
+
+	txids := make([]base.TransactionID, len(txs))
+	for i, txBytes := range txs {
+		batch := start + i
+		txids[i], err = transaction.IDFromParsedTransactionBytes(txBytes)
+		glb.AssertNoError(err)
+
+		glb.AssertNoError(bt.journal.put(batch, txBytes))
+		err = glb.GetClient().SubmitTransaction(txBytes)
+		glb.Assertf(err == nil, "failed to submit transaction of batch #%d: %v\nRun the command again to resume", batch, err)
+		b := bt.batches[batch]
+		glb.Infof("batch #%d (%d recipients) submitted: %s", batch, b.to-b.from, txids[i].String())
+	}
+	return txids
+}
+
+// makeTransactions makes chain of transactions for batches starting from start.
+// Each next transaction consumes the remainder of the previous one
+func (bt *batchTransfer) makeTransactions(start int, inputs []*ledger.OutputWithID) ([][]byte, error) {
+	ret := make([][]byte, 0, len(bt.batches)-start)
+	ts := ledger.TimeNow()
+	for i := start; i < len(bt.batches); i++ {
+		txb := txbuilder.New()
+		inTotal, inTs, err := txb.ConsumeOutputs(inputs...)
+		if err != nil {
+			return nil, err
+		}
+		if err = txb.PutStandardInputUnlocks(len(inputs)); err != nil {
+			return nil, err
+		}
+		ts = base.MaximumTime(ts, inTs.AddTicks(ledger.TransactionPace()))
+		if ts.IsSlotBoundary() {
+			ts = ts.AddTicks(1)
+		}
+		txb.TransactionData.Timestamp = ts
+
+		sum := uint64(0)
+		b := bt.batches[i]
+		for _, r := range bt.recipients[b.from:b.to] {
+			if _, err = txb.ProduceOutput(ledger.NewOutput(func(o *ledger.OutputBuilder) {
+				o.WithAmount(r.Amount).WithLock(r.lock)
+			})); err != nil {
+				return nil, err
+			}
+			sum += r.Amount
+		}
+		if inTotal < sum+bt.fee {
+			return nil, fmt.Errorf("not enough tokens for batch #%d", i)
+		}
+		fee := bt.fee
+		remainder := inTotal - sum - fee
+		remainderOut := ledger.NewOutput(func(o *ledger.OutputBuilder) {
+			o.WithAmount(remainder).WithLock(bt.walletData.Account)
+		})
+		if remainder > 0 && remainderOut.EnoughAmountForStorageDeposit(ts.Slot) != nil {
+			if i != len(bt.batches)-1 {
+				return nil, fmt.Errorf("inconsistency: remainder of batch #%d is too small", i)
+			}
+			// dust goes to the tag-along sequencer
+			fee += remainder
+			remainder = 0
+		}
+		if _, err = txb.ProduceOutput(ledger.NewOutput(func(o *ledger.OutputBuilder) {
+			o.WithAmount(fee).WithLock(ledger.ChainLockFromChainID(bt.tagAlongSeqID))
+		})); err != nil {
+			return nil, err
+		}
+		remainderIdx := byte(0xff)
+		if remainder > 0 {
+			if remainderIdx, err = txb.ProduceOutput(remainderOut); err != nil {
+				return nil, err
+			}
+		}
+		txb.TransactionData.InputCommitment = ledger.HashOutputs(txb.ConsumedOutputs...)
+		txb.SignED25519(bt.walletData.PrivateKey)
+
+		txBytes, _, failedTx, err := txb.BytesWithValidation()
+		if err != nil {
+			return nil, fmt.Errorf("transaction of batch #%d is invalid: %v\n%s", i, err, failedTx)
+		}
+		ret = append(ret, txBytes)
+
+		if i == len(bt.batches)-1 {
+			break
+		}
+		if remainderIdx == 0xff {
+			return nil, fmt.Errorf("inconsistency: no remainder in batch #%d", i)
+		}
+		remainderWithID, err := transaction.OutputWithIDFromTransactionBytes(txBytes, remainderIdx)
+		if err != nil {
+			return nil, err
+		}
+		inputs = []*ledger.OutputWithID{remainderWithID}
+	}
+	return ret, nil
+}
+
+// waitIncluded waits until all transactions are included into the LRB or timeout. Returns true if all were included
+func (bt *batchTransfer) waitIncluded(txids []base.TransactionID) bool {
+	deadline := time.Now().Add(batchInclusionTimeoutSlots * ledger.SlotDuration())
+	included := make([]bool, len(txids))
+	numIncluded := 0
+	for {
+		for i, txid := range txids {
+			if included[i] {
+				continue
+			}
+			if _, depth, err := glb.GetClient().CheckTransactionIDInLRB(txid, 0); err == nil && depth >= 0 {
+				included[i] = true
+				numIncluded++
+			}
+		}
+		glb.Infof("%d of %d transactions are included into the LRB", numIncluded, len(txids))
+		if numIncluded == len(txids) {
+			return true
+		}
+		if time.Now().After(deadline) {
+			glb.Infof("not all transactions were included in %d slots", batchInclusionTimeoutSlots)
+			return false
+		}
+		time.Sleep(2 * time.Second)
+	}
+}
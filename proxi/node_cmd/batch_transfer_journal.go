@@ -0,0 +1,165 @@
+package node_cmd
+
+import (
+	"bufio"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/lunfardo314/proxima/ledger/base"
+	"github.com/lunfardo314/proxima/ledger/transaction"
+	"golang.org/x/crypto/blake2b"
+)
+
+// batch transfer journal is a local file of JSON lines. The first line is the header, which identifies the payment list
+// and its split into batches. Each next line is written before the transaction of the batch is submitted.
+// The later record of the same batch overrides the earlier one. The journal allows to resume the batch transfer
+// after partial failure without paying anybody twice
+
+type (
+	batchJournalHeader struct {
+		// hash of the recipient list and batch boundaries
+		Hash       string `json:"hash"`
+		Recipients int    `json:"recipients"`
+		Batches    int    `json:"batches"`
+	}
+
+	batchJournalRecord struct {
+		Batch   int    `json:"batch"`
+		TxID    string `json:"txid"`
+		TxBytes string `json:"tx_bytes"`
+	}
+
+	batchJournal struct {
+		fname   string
+		header  batchJournalHeader
+		records map[int]*batchJournalRecord
+	}
+)
+
+func batchJournalHash(recipients []*batchRecipient, batches []batchRange) string {
+	h, _ := blake2b.New256(nil)
+	for _, r := range recipients {
+		_, _ = fmt.Fprintf(h, "%s %d\n", r.Target, r.Amount)
+	}
+	for _, b := range batches {
+		_, _ = fmt.Fprintf(h, "[%d,%d)\n", b.from, b.to)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// openBatchJournal reads existing journal or creates new one. Existing journal must match the recipients and batches
+func openBatchJournal(fname string, recipients []*batchRecipient, batches []batchRange) (*batchJournal, bool, error) {
+	ret := &batchJournal{
+		fname: fname,
+		header: batchJournalHeader{
+			Hash:       batchJournalHash(recipients, batches),
+			Recipients: len(recipients),
+			Batches:    len(batches),
+		},
+		records: make(map[int]*batchJournalRecord),
+	}
+	f, err := os.Open(fname)
+	if errors.Is(err, os.ErrNotExist) {
+		return ret, false, ret.appendLine(&ret.header)
+	}
+	if err != nil {
+		return nil, false, err
+	}
+	defer func() { _ = f.Close() }()
+
+	scanner := bufio.NewScanner(f)
+	// transaction bytes may be long
+	scanner.Buffer(make([]byte, 0, 1<<16), 1<<20)
+	first := true
+	for scanner.Scan() {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		if first {
+			var header batchJournalHeader
+			if err = json.Unmarshal(scanner.Bytes(), &header); err != nil {
+				return nil, false, fmt.Errorf("journal %s: wrong header: %w", fname, err)
+			}
+			if header != ret.header {
+				return nil, false, fmt.Errorf("journal %s was created for another list of payments or with another number of outputs per transaction", fname)
+			}
+			first = false
+			continue
+		}
+		rec := &batchJournalRecord{}
+		if err = json.Unmarshal(scanner.Bytes(), rec); err != nil {
+			// the last line can be torn by crash
+			break
+		}
+		if rec.Batch < 0 || rec.Batch >= len(batches) {
+			return nil, false, fmt.Errorf("journal %s: wrong batch index %d", fname, rec.Batch)
+		}
+		ret.records[rec.Batch] = rec
+	}
+	if err = scanner.Err(); err != nil {
+		return nil, false, err
+	}
+	if first {
+		// empty file
+		return ret, false, ret.appendLine(&ret.header)
+	}
+	return ret, true, nil
+}
+
+func (j *batchJournal) appendLine(v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	f, err := os.OpenFile(j.fname, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err = f.Write(append(data, '\n')); err != nil {
+		_ = f.Close()
+		return err
+	}
+	if err = f.Sync(); err != nil {
+		_ = f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// put records transaction of the batch. Must be called before the transaction is submitted
+func (j *batchJournal) put(batch int, txBytes []byte) error {
+	txid, err := transaction.IDFromParsedTransactionBytes(txBytes)
+	if err != nil {
+		return err
+	}
+	rec := &batchJournalRecord{
+		Batch:   batch,
+		TxID:    txid.StringHex(),
+		TxBytes: hex.EncodeToString(txBytes),
+	}
+	if err = j.appendLine(rec); err != nil {
+		return err
+	}
+	j.records[batch] = rec
+	return nil
+}
+
+// get returns recorded transaction of the batch, if any
+func (j *batchJournal) get(batch int) (base.TransactionID, []byte, bool) {
+	rec, ok := j.records[batch]
+	if !ok {
+		return base.TransactionID{}, nil, false
+	}
+	txid, err := base.TransactionIDFromHexString(rec.TxID)
+	if err != nil {
+		return base.TransactionID{}, nil, false
+	}
+	txBytes, err := hex.DecodeString(rec.TxBytes)
+	if err != nil {
+		return base.TransactionID{}, nil, false
+	}
+	return txid, txBytes, true
+}
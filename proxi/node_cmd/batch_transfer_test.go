@@ -0,0 +1,98 @@
+package node_cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseBatchRecipients(t *testing.T) {
+	t.Run("csv", func(t *testing.T) {
+		const data = `target,amount
+# comment
+a(0x01), 1000
+"deadline(100, a(0x02), a(0x03))",1_000_000
+
+a(0x04),5
+`
+		ret, err := parseBatchRecipients([]byte(data), false)
+		require.NoError(t, err)
+		require.Equal(t, 3, len(ret))
+		require.Equal(t, "a(0x01)", ret[0].Target)
+		require.EqualValues(t, 1000, ret[0].Amount)
+		require.Equal(t, 3, ret[0].line)
+		require.Equal(t, "deadline(100, a(0x02), a(0x03))", ret[1].Target)
+		require.EqualValues(t, 1_000_000, ret[1].Amount)
+		require.EqualValues(t, 5, ret[2].Amount)
+	})
+	t.Run("csv errors", func(t *testing.T) {
+		_, err := parseBatchRecipients([]byte("a(0x01),1000\na(0x02),abc\n"), false)
+		require.Error(t, err)
+		_, err = parseBatchRecipients([]byte("a(0x01),0\n"), false)
+		require.Error(t, err)
+		_, err = parseBatchRecipients([]byte("a(0x01),1000,3\n"), false)
+		require.Error(t, err)
+		_, err = parseBatchRecipients([]byte(",1000\n"), false)
+		require.Error(t, err)
+	})
+	t.Run("json", func(t *testing.T) {
+		const data = ` [{"target": "a(0x01)", "amount": 1000}, {"target": "a(0x02)", "amount": 2000}]`
+		ret, err := parseBatchRecipients([]byte(data), false)
+		require.NoError(t, err)
+		require.Equal(t, 2, len(ret))
+		require.Equal(t, "a(0x02)", ret[1].Target)
+		require.EqualValues(t, 2000, ret[1].Amount)
+
+		_, err = parseBatchRecipients([]byte(`[{"target": "a(0x01)"}]`), true)
+		require.Error(t, err)
+		_, err = parseBatchRecipients([]byte(`{}`), true)
+		require.Error(t, err)
+	})
+}
+
+func TestPackBatches(t *testing.T) {
+	require.Equal(t, 0, len(packBatches(nil, 10, 1000)))
+
+	sizes := make([]int, 25)
+	for i := range sizes {
+		sizes[i] = 10
+	}
+	require.Equal(t, []batchRange{{0, 10}, {10, 20}, {20, 25}}, packBatches(sizes, 10, 1000))
+	// limited by size
+	require.Equal(t, []batchRange{{0, 7}, {7, 14}, {14, 21}, {21, 25}}, packBatches(sizes, 10, 75))
+	// output bigger than the limit goes alone
+	require.Equal(t, []batchRange{{0, 1}, {1, 2}}, packBatches([]int{100, 100}, 10, 50))
+}
+
+func TestBatchJournal(t *testing.T) {
+	recipients := []*batchRecipient{{Target: "a(0x01)", Amount: 1000}, {Target: "a(0x02)", Amount: 2000}}
+	batches := []batchRange{{0, 1}, {1, 2}}
+	fname := filepath.Join(t.TempDir(), "payments.journal")
+
+	j, resumed, err := openBatchJournal(fname, recipients, batches)
+	require.NoError(t, err)
+	require.False(t, resumed)
+	_, _, ok := j.get(0)
+	require.False(t, ok)
+
+	// record of the batch and the torn line
+	f, err := os.OpenFile(fname, os.O_APPEND|os.O_WRONLY, 0644)
+	require.NoError(t, err)
+	_, err = f.WriteString(`{"batch":1,"txid":"00","tx_bytes":"0102"}` + "\n" + `{"batch":0,"tx`)
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	j, resumed, err = openBatchJournal(fname, recipients, batches)
+	require.NoError(t, err)
+	require.True(t, resumed)
+	require.Equal(t, 1, len(j.records))
+	require.Equal(t, "0102", j.records[1].TxBytes)
+
+	// another list of payments
+	_, _, err = openBatchJournal(fname, recipients[:1], batches[:1])
+	require.Error(t, err)
+	_, _, err = openBatchJournal(fname, recipients, []batchRange{{0, 2}})
+	require.Error(t, err)
+}
@@ -0,0 +1,70 @@
+package node_cmd
+
+import (
+	"github.com/lunfardo314/proxima/api"
+	"github.com/lunfardo314/proxima/ledger/base"
+	"github.com/lunfardo314/proxima/proxi/glb"
+	"github.com/lunfardo314/proxima/util"
+	"github.com/spf13/cobra"
+)
+
+const defaultChainHistoryRecords = 50
+
+var chainHistoryMax int
+
+func initChainHistoryCmd() *cobra.Command {
+	chainHistoryCmd := &cobra.Command{
+		Use:   "chain_history <chain id hex-encoded>",
+		Short: `lists transitions of the chain, the latest first`,
+		Long: `lists transitions of the chain, the latest first. The node walks the chain backwards from its output
+in the latest reliable branch through predecessor inputs. Transactions are taken from the tx store of the node,
+so the history stops at the origin of the chain or at the first transaction which is not in the store.
+For sequencer outputs milestone data is displayed too. Use -v to display locks`,
+		Args: cobra.ExactArgs(1),
+		Run:  runChainHistoryCmd,
+	}
+	chainHistoryCmd.PersistentFlags().IntVar(&chainHistoryMax, "max", defaultChainHistoryRecords, "maximum number of listed transitions")
+
+	chainHistoryCmd.InitDefaultHelpCmd()
+	return chainHistoryCmd
+}
+
+func runChainHistoryCmd(_ *cobra.Command, args []string) {
+	glb.InitLedgerFromNode()
+
+	chainID, err := base.ChainIDFromHexString(args[0])
+	glb.AssertNoError(err)
+
+	res, err := glb.GetClient().GetChainHistory(chainID, chainHistoryMax)
+	glb.AssertNoError(err)
+	glb.PrintResult(res)
+
+	lrbid, err := base.TransactionIDFromHexString(res.LRBID)
+	glb.AssertNoError(err)
+	glb.PrintLRB(&lrbid)
+
+	glb.Infof("chain %s", chainID.String())
+	if res.Truncated {
+		glb.Infof("latest %d transitions (the origin is not reached):", len(res.Transitions))
+	} else {
+		glb.Infof("%d transitions from the origin:", len(res.Transitions))
+	}
+	for i := range res.Transitions {
+		displayChainTransition(&res.Transitions[i])
+	}
+}
+
+func displayChainTransition(t *api.ChainTransition) {
+	oid, err := base.OutputIDFromHexString(t.OutputID)
+	glb.AssertNoError(err)
+
+	if md := t.MilestoneData; md != nil {
+		glb.Infof("  %s amount: %s, inflation: %s, milestone '%s': chain height %d, branch height %d, min fee %s",
+			oid.String(), util.Th(t.Amount), util.Th(t.Inflation), md.Name, md.ChainHeight, md.BranchHeight, util.Th(md.MinimumFee))
+	} else {
+		glb.Infof("  %s amount: %s, inflation: %s", oid.String(), util.Th(t.Amount), util.Th(t.Inflation))
+	}
+	if glb.IsVerbose() {
+		glb.Infof("         lock: %s", t.Lock)
+	}
+}
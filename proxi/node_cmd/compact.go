@@ -2,7 +2,6 @@ package node_cmd
 
 import (
 	"fmt"
-	"os"
 	"strconv"
 	"time"
 
@@ -16,6 +15,13 @@ const (
 	defaultMaxNumberOfInputs = 100
 )
 
+// compactResult has no transaction when there is nothing to compact
+type compactResult struct {
+	*glb.TxResult
+	NumInputs int    `json:"num_inputs"`
+	Fee       uint64 `json:"fee,omitempty"`
+}
+
 func initCompactOutputsCmd() *cobra.Command {
 	compactCmd := &cobra.Command{
 		Use:   "compact [<max number of args. Default 100, maximum allowed 256>]",
@@ -60,7 +66,8 @@ func runCompactCmd(_ *cobra.Command, args []string) {
 	glb.PrintLRB(lrbid)
 	if len(walletOutputs) <= 1 {
 		glb.Infof("only one output -> no need for compacting")
-		os.Exit(0)
+		glb.PrintResult(&compactResult{NumInputs: len(walletOutputs)})
+		return
 	}
 	glb.Infof("%d ED25519 output(s) from account %s will be compacted into one", len(walletOutputs), walletData.Account.String())
 
@@ -68,9 +75,8 @@ func runCompactCmd(_ *cobra.Command, args []string) {
 	glb.Assertf(feeAmount > 0, "tag-along fee is configured 0. Fee-less option not supported yet")
 
 	prompt = fmt.Sprintf("compacting will cost %d of fees paid to the tag-along sequencer %s. Proceed?", feeAmount, tagAlongSeqID.StringShort())
-	if !glb.YesNoPrompt(prompt, true) {
-		glb.Infof("exit")
-		os.Exit(0)
+	if !glb.YesNoPrompt(prompt, true, glb.BypassYesNoPrompt()) {
+		glb.Abort()
 	}
 
 	txCtx, err := glb.GetClient().MakeCompactTransaction(walletData.PrivateKey, tagAlongSeqID, feeAmount, maxNumberOfInputs)
@@ -87,4 +93,9 @@ func runCompactCmd(_ *cobra.Command, args []string) {
 	if !glb.NoWait() {
 		glb.TrackTxInclusion(txCtx.TransactionID(), time.Second)
 	}
+	glb.PrintResult(&compactResult{
+		TxResult:  glb.NewTxResult(txCtx.TransactionID(), !glb.NoWait()),
+		NumInputs: txCtx.NumInputs(),
+		Fee:       feeAmount,
+	})
 }
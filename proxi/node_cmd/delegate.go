@@ -2,7 +2,6 @@ package node_cmd
 
 import (
 	"fmt"
-	"os"
 	"strconv"
 	"time"
 
@@ -32,6 +31,13 @@ func initDelegateCmd() *cobra.Command {
 	err := viper.BindPFlag("seq", delegateCmd.PersistentFlags().Lookup("seq"))
 	glb.AssertNoError(err)
 
+	delegateCmd.AddCommand(
+		initDelegateReportCmd(),
+		initDelegateListCmd(),
+		initDelegateWithdrawCmd(),
+		initDelegateMoveCmd(),
+	)
+
 	delegateCmd.InitDefaultHelpCmd()
 	return delegateCmd
 }
@@ -70,7 +76,7 @@ func runDelegateCmd(_ *cobra.Command, args []string) {
 	amountInt, err := strconv.Atoi(args[0])
 	glb.AssertNoError(err)
 	amount := uint64(amountInt)
-	glb.Assertf(amount >= ledger.MinimumDelegationAmount(), "amount must be >= %d", ledger.MinimumDelegationAmount())
+	glb.Assertf(amount >= ledger.MinimumDelegationAmount(ledger.TimeNow().Slot), "amount must be >= %d", ledger.MinimumDelegationAmount(ledger.TimeNow().Slot))
 
 	client := glb.GetClient()
 	walletOutputs, lrbid, _, err := client.GetOutputsForAmount(walletData.Account, amount+feeAmount)
@@ -139,9 +145,8 @@ func runDelegateCmd(_ *cobra.Command, args []string) {
 	prompt := fmt.Sprintf("delegate amount %s to sequencer %s (plus tag-along fee %s)?",
 		util.Th(amount), targetSeqID.String(), util.Th(feeAmount))
 
-	if !glb.YesNoPrompt(prompt, true) {
-		glb.Infof("exit")
-		os.Exit(0)
+	if !glb.YesNoPrompt(prompt, true, glb.BypassYesNoPrompt()) {
+		glb.Abort()
 	}
 
 	delegationOid, err := base.NewOutputID(txid, delegationOutputIdx)
@@ -154,4 +159,8 @@ func runDelegateCmd(_ *cobra.Command, args []string) {
 	glb.AssertNoError(err)
 
 	glb.TrackTxInclusion(txid, 2*time.Second)
+	glb.PrintResult(&chainTxResult{
+		TxResult: glb.NewTxResult(txid, true),
+		ChainID:  delegationID.StringHex(),
+	})
 }
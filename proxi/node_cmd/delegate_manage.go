@@ -0,0 +1,270 @@
+package node_cmd
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/lunfardo314/proxima/ledger"
+	"github.com/lunfardo314/proxima/ledger/base"
+	"github.com/lunfardo314/proxima/ledger/multistate"
+	"github.com/lunfardo314/proxima/ledger/txbuilder"
+	"github.com/lunfardo314/proxima/proxi/glb"
+	"github.com/lunfardo314/proxima/util"
+	"github.com/spf13/cobra"
+)
+
+// owner's transactions are scheduled in the closed delegation slots, where the target sequencer cannot
+// consume the delegation output. Transactions up to maxScheduleAheadSlots in the future are submitted
+// right away and the node holds them until the clock catches up. Otherwise proxi waits.
+const maxScheduleAheadSlots = 5
+
+var noWaitDelegationTx bool
+
+type (
+	delegationListResult struct {
+		LRBID       string               `json:"lrbid"`
+		Delegations []delegationListItem `json:"delegations"`
+		Total       uint64               `json:"total"`
+	}
+
+	delegationListItem struct {
+		ID             string `json:"id"`
+		OutputID       string `json:"output_id"`
+		Amount         uint64 `json:"amount"`
+		StartAmount    uint64 `json:"start_amount"`
+		Target         string `json:"target"`
+		OpenSlot       bool   `json:"open_slot"`
+		NextOpenSlot   uint32 `json:"next_open_slot"`
+		NextClosedSlot uint32 `json:"next_closed_slot"`
+	}
+)
+
+func initDelegateListCmd() *cobra.Command {
+	listCmd := &cobra.Command{
+		Use:   "list",
+		Short: `lists delegations of the wallet account with the schedule of open and closed delegation slots`,
+		Args:  cobra.NoArgs,
+		Run:   runDelegateListCmd,
+	}
+	listCmd.InitDefaultHelpCmd()
+	return listCmd
+}
+
+func initDelegateWithdrawCmd() *cobra.Command {
+	withdrawCmd := &cobra.Command{
+		Use:   "withdraw <delegation id hex encoded> [<amount>]",
+		Short: `withdraws amount from the delegation to the wallet account. Without amount, ends the delegation and withdraws everything`,
+		Args:  cobra.RangeArgs(1, 2),
+		Run:   runDelegateWithdrawCmd,
+	}
+	withdrawCmd.PersistentFlags().BoolVarP(&noWaitDelegationTx, "nowait", "n", false, "do not wait for the closed delegation slot, submit immediately")
+	withdrawCmd.InitDefaultHelpCmd()
+	return withdrawCmd
+}
+
+func initDelegateMoveCmd() *cobra.Command {
+	moveCmd := &cobra.Command{
+		Use:   "move <delegation id hex encoded> <target sequencer id hex encoded>",
+		Short: `re-delegates the delegation to another sequencer. The delegation chain remains the same`,
+		Args:  cobra.ExactArgs(2),
+		Run:   runDelegateMoveCmd,
+	}
+	moveCmd.PersistentFlags().BoolVarP(&noWaitDelegationTx, "nowait", "n", false, "do not wait for the closed delegation slot, submit immediately")
+	moveCmd.InitDefaultHelpCmd()
+	return moveCmd
+}
+
+func runDelegateListCmd(_ *cobra.Command, _ []string) {
+	glb.InitLedgerFromNode()
+	walletData := glb.GetWalletData()
+
+	outs, lrbid, err := glb.GetClient().GetChainedOutputs(walletData.Account)
+	glb.AssertNoError(err)
+	glb.PrintLRB(lrbid)
+
+	outs = util.PurgeSlice(outs, func(o *ledger.OutputWithChainID) bool {
+		dl := o.Output.DelegationLock()
+		return dl != nil && ledger.EqualAccountables(walletData.Account, dl.OwnerLock)
+	})
+	if len(outs) == 0 {
+		glb.Infof("no delegations found in the account %s", walletData.Account.String())
+		glb.PrintResult(&delegationListResult{LRBID: lrbid.StringHex(), Delegations: []delegationListItem{}})
+		return
+	}
+	sort.Slice(outs, func(i, j int) bool {
+		return bytes.Compare(outs[i].ChainID[:], outs[j].ChainID[:]) < 0
+	})
+
+	nowis := ledger.TimeNow()
+	total := uint64(0)
+	res := &delegationListResult{LRBID: lrbid.StringHex(), Delegations: make([]delegationListItem, 0, len(outs))}
+	glb.Infof("\n%d delegation(s) in the account %s. Current slot is %d\n", len(outs), walletData.Account.String(), nowis.Slot)
+	for _, o := range outs {
+		dl := o.Output.DelegationLock()
+		slotStatus := "closed"
+		if ledger.IsOpenDelegationSlot(o.ChainID, nowis.Slot) {
+			slotStatus = "open"
+		}
+		glb.Infof("%s   %s  \t\t-> %s", o.ChainID.String(), util.Th(o.Output.Amount()), dl.TargetLock.String())
+		glb.Infof("        current slot is %s, next open slot: %d, next closed slot: %d, last transition %d slots back",
+			slotStatus, ledger.NextOpenDelegationSlot(o.ChainID, nowis.Slot), ledger.NextClosedDelegationSlot(o.ChainID, nowis.Slot),
+			nowis.Slot-o.ID.Slot())
+		glb.Verbosef("        start amount %s at %s\n        output id: %s", util.Th(dl.StartAmount), dl.StartTime.String(), o.ID.String())
+		total += o.Output.Amount()
+		res.Delegations = append(res.Delegations, delegationListItem{
+			ID:             o.ChainID.StringHex(),
+			OutputID:       o.ID.StringHex(),
+			Amount:         o.Output.Amount(),
+			StartAmount:    dl.StartAmount,
+			Target:         dl.TargetLock.String(),
+			OpenSlot:       ledger.IsOpenDelegationSlot(o.ChainID, nowis.Slot),
+			NextOpenSlot:   uint32(ledger.NextOpenDelegationSlot(o.ChainID, nowis.Slot)),
+			NextClosedSlot: uint32(ledger.NextClosedDelegationSlot(o.ChainID, nowis.Slot)),
+		})
+	}
+	glb.Infof("\nTotal delegated: %s", util.Th(total))
+	res.Total = total
+	glb.PrintResult(res)
+}
+
+func runDelegateWithdrawCmd(_ *cobra.Command, args []string) {
+	glb.InitLedgerFromNode()
+
+	delegationID, err := base.ChainIDFromHexString(args[0])
+	glb.AssertNoError(err)
+
+	if len(args) == 1 {
+		endChain(delegationID)
+		return
+	}
+	amountInt, err := strconv.Atoi(args[1])
+	glb.AssertNoError(err)
+	glb.Assertf(amountInt > 0, "amount must be positive")
+
+	prompt := fmt.Sprintf("withdraw %s from delegation %s?", util.Th(amountInt), delegationID.String())
+	if !glb.YesNoPrompt(prompt, true, glb.BypassYesNoPrompt()) {
+		glb.Abort()
+	}
+	submitDelegationTransition(delegationID, nil, uint64(amountInt))
+}
+
+func runDelegateMoveCmd(_ *cobra.Command, args []string) {
+	glb.InitLedgerFromNode()
+
+	delegationID, err := base.ChainIDFromHexString(args[0])
+	glb.AssertNoError(err)
+	targetSeqID, err := base.ChainIDFromHexString(args[1])
+	glb.Assertf(err == nil, "failed parsing target chainID: %v", err)
+
+	seqOut, _, _, err := glb.GetClient().GetChainOutput(targetSeqID)
+	glb.Assertf(err == nil, "can't find sequencer id %s: %v", targetSeqID.StringShort(), err)
+	glb.Assertf(seqOut.ID.IsSequencerTransaction(), "chainID %s does not represent a sequencer", targetSeqID.StringShort())
+
+	prompt := fmt.Sprintf("move delegation %s to sequencer %s?", delegationID.String(), targetSeqID.String())
+	if !glb.YesNoPrompt(prompt, true, glb.BypassYesNoPrompt()) {
+		glb.Abort()
+	}
+	submitDelegationTransition(delegationID, ledger.ChainLockFromChainID(targetSeqID), 0)
+}
+
+// submitDelegationTransition makes owner's transition of the delegation chain in the next closed delegation slot.
+// If the chain output is consumed by the sequencer before the transaction is included, the transaction
+// is orphaned as a double-spend and a new one is built on the new chain output
+func submitDelegationTransition(delegationID base.ChainID, newTarget ledger.Accountable, withdrawAmount uint64) {
+	walletData := glb.GetWalletData()
+	clnt := glb.GetClient()
+
+	feeAmount := glb.GetTagAlongFee()
+	glb.Assertf(feeAmount > 0, "tag-along fee is configured 0. Fee-less option not supported yet")
+	tagAlongSeqID := glb.GetTagAlongSequencerID()
+	glb.Assertf(tagAlongSeqID != nil, "tag-along sequencer not specified")
+
+	md, err := clnt.GetMilestoneData(*tagAlongSeqID)
+	glb.AssertNoError(err)
+	if md != nil && md.MinimumFee > feeAmount {
+		feeAmount = md.MinimumFee
+	}
+
+	for attempt := 1; ; attempt++ {
+		o, _, _, err := clnt.GetChainOutput(delegationID)
+		glb.AssertNoError(err)
+		dl := o.Output.DelegationLock()
+		glb.Assertf(dl != nil, "chain %s is not a delegation", delegationID.StringShort())
+		glb.Assertf(ledger.EqualAccountables(walletData.Account, dl.OwnerLock), "delegation %s is not owned by the wallet account", delegationID.StringShort())
+
+		ts := ledger.NextClosedDelegationTimestamp(delegationID, base.MaximumTime(o.Timestamp(), ledger.TimeNow()))
+		if ts.IsSlotBoundary() {
+			ts = ts.AddTicks(1)
+		}
+		tx, err := txbuilder.MakeDelegationTransitionTransaction(txbuilder.DelegationTransitionParams{
+			Timestamp:      ts,
+			ChainIn:        o,
+			PrivateKey:     walletData.PrivateKey,
+			NewTarget:      newTarget,
+			WithdrawAmount: withdrawAmount,
+			TagAlongSeqID:  *tagAlongSeqID,
+			TagAlongFee:    feeAmount,
+		})
+		glb.AssertNoError(err)
+		glb.Verbosef("-------------- transaction --------------\n%s", tx.String())
+
+		if sleepFor := time.Until(ledger.ClockTime(ts)); !noWaitDelegationTx && ts.Slot > ledger.TimeNow().Slot+maxScheduleAheadSlots {
+			glb.Infof("closed delegation slot %d is far ahead. Waiting for approx. %v to submit the transaction... (ctrl-C to interrupt)", ts.Slot, sleepFor)
+			time.Sleep(sleepFor - ledger.SlotDuration())
+
+			oNow, _, _, err := clnt.GetChainOutput(delegationID)
+			glb.AssertNoError(err)
+			if oNow.ID != o.ID {
+				glb.Infof("delegation output changed while waiting. Re-building the transaction")
+				continue
+			}
+		}
+		err = clnt.SubmitTransaction(tx.Bytes())
+		glb.AssertNoError(err)
+		glb.Infof("attempt #%d. Submitted transaction %s scheduled at closed delegation slot %d (in %v)",
+			attempt, tx.IDString(), ts.Slot, time.Until(ledger.ClockTime(ts)).Truncate(time.Second))
+
+		if waitDelegationTransition(delegationID, o.ID, tx.ID(), ts) {
+			glb.TrackTxInclusion(tx.ID(), 2*time.Second)
+			glb.PrintResult(&chainTxResult{
+				TxResult: glb.NewTxResult(tx.ID(), true),
+				ChainID:  delegationID.StringHex(),
+			})
+			return
+		}
+		glb.Infof("re-building the transaction")
+	}
+}
+
+// waitDelegationTransition polls the delegation chain until it is transited. Returns true if it was transited by txid.
+// Returns false if the chain output was consumed by another transaction or the transaction was not included in time
+func waitDelegationTransition(delegationID base.ChainID, consumed base.OutputID, txid base.TransactionID, ts base.LedgerTime) bool {
+	const giveUpAfterSlots = 10
+
+	clnt := glb.GetClient()
+	for {
+		time.Sleep(2 * time.Second)
+
+		o, _, _, err := clnt.GetChainOutput(delegationID)
+		if errors.Is(err, multistate.ErrNotFound) {
+			glb.Infof("delegation %s does not exist anymore", delegationID.StringShort())
+			return false
+		}
+		glb.AssertNoError(err)
+		if o.ID.TransactionID() == txid {
+			return true
+		}
+		if o.ID != consumed {
+			glb.Infof("delegation output %s has been consumed by another transaction", consumed.StringShort())
+			return false
+		}
+		if nowSlot := ledger.TimeNow().Slot; nowSlot > ts.Slot+giveUpAfterSlots {
+			glb.Infof("transaction %s is not in the latest reliable branch %d slots after the scheduled time", txid.StringShort(), nowSlot-ts.Slot)
+			return false
+		}
+	}
+}
@@ -0,0 +1,111 @@
+package node_cmd
+
+import (
+	"github.com/lunfardo314/proxima/api"
+	"github.com/lunfardo314/proxima/ledger"
+	"github.com/lunfardo314/proxima/ledger/base"
+	"github.com/lunfardo314/proxima/proxi/glb"
+	"github.com/lunfardo314/proxima/util"
+	"github.com/spf13/cobra"
+)
+
+type delegationReportResult struct {
+	LRBID       string                   `json:"lrbid,omitempty"`
+	Delegations []*api.DelegationHistory `json:"delegations"`
+	TotalEarned uint64                   `json:"total_earned"`
+	TotalMargin uint64                   `json:"total_margin"`
+}
+
+func initDelegateReportCmd() *cobra.Command {
+	reportCmd := &cobra.Command{
+		Use:   "report [<delegation id hex encoded>]",
+		Short: `reports inflation earned by delegations of the wallet account or by the specified delegation. Use -v to list each transition`,
+		Args:  cobra.MaximumNArgs(1),
+		Run:   runDelegateReportCmd,
+	}
+	reportCmd.InitDefaultHelpCmd()
+	return reportCmd
+}
+
+func runDelegateReportCmd(_ *cobra.Command, args []string) {
+	glb.InitLedgerFromNode()
+
+	if len(args) == 1 {
+		delegationID, err := base.ChainIDFromHexString(args[0])
+		glb.AssertNoError(err)
+		h := reportDelegation(delegationID)
+		glb.PrintResult(&delegationReportResult{
+			Delegations: []*api.DelegationHistory{h},
+			TotalEarned: h.TotalEarned,
+			TotalMargin: h.TotalMargin,
+		})
+		return
+	}
+
+	walletData := glb.GetWalletData()
+	outs, lrbid, err := glb.GetClient().GetChainedOutputs(walletData.Account)
+	glb.AssertNoError(err)
+	glb.PrintLRB(lrbid)
+
+	res := &delegationReportResult{LRBID: lrbid.StringHex(), Delegations: make([]*api.DelegationHistory, 0)}
+	for _, o := range outs {
+		dl := o.Output.DelegationLock()
+		if dl == nil || !ledger.EqualAccountables(walletData.Account, dl.OwnerLock) {
+			continue
+		}
+		h := reportDelegation(o.ChainID)
+		res.TotalEarned += h.TotalEarned
+		res.TotalMargin += h.TotalMargin
+		res.Delegations = append(res.Delegations, h)
+	}
+	if len(res.Delegations) == 0 {
+		glb.Infof("no delegations found in the account %s", walletData.Account.String())
+		glb.PrintResult(res)
+		return
+	}
+	glb.Infof("\nTOTAL earned by %d delegation(s): %s, kept by sequencers: %s",
+		len(res.Delegations), util.Th(res.TotalEarned), util.Th(res.TotalMargin))
+	glb.PrintResult(res)
+}
+
+func reportDelegation(delegationID base.ChainID) *api.DelegationHistory {
+	h, err := glb.GetClient().GetDelegationHistory(delegationID)
+	glb.AssertNoError(err)
+
+	glb.Infof("\ndelegation %s", delegationID.String())
+	if h.TargetLock != "" {
+		glb.Infof("   owner       : %s", h.OwnerLock)
+		glb.Infof("   delegated to: %s", h.TargetLock)
+		glb.Infof("   since slot %d, start amount %s", h.StartSlot, util.Th(h.StartAmount))
+	} else {
+		glb.Infof("   the chain is not delegation-locked anymore")
+	}
+	if h.Truncated {
+		glb.Infof("   WARNING: history is incomplete, it does not reach the origin of the chain")
+	}
+	if len(h.Transitions) > 0 {
+		last := h.Transitions[len(h.Transitions)-1]
+		glb.Infof("   current amount %s, %d transitions since slot %d", util.Th(last.Amount), len(h.Transitions), h.Transitions[0].Slot)
+	}
+	for _, t := range h.Transitions {
+		by := "owner"
+		if t.SequencerID != "" {
+			by = "sequencer " + t.SequencerID
+		}
+		glb.Verbosef("      slot %d: amount %s, earned %s, margin %s, by %s",
+			t.Slot, util.Th(t.Amount), util.Th(t.Earned), util.Th(t.Margin), by)
+	}
+	if len(h.EarnedPerSlot) > 0 {
+		glb.Verbosef("   earned per slot:")
+		for _, e := range h.EarnedPerSlot {
+			glb.Verbosef("      slot %d: %s", e.Slot, util.Th(e.Earned))
+		}
+	}
+	marginPercent := 0.0
+	if h.TotalEarned+h.TotalMargin > 0 {
+		marginPercent = 100 * float64(h.TotalMargin) / float64(h.TotalEarned+h.TotalMargin)
+	}
+	glb.Infof("   earned %s, kept by sequencers %s (%.02f%% of generated inflation)",
+		util.Th(h.TotalEarned), util.Th(h.TotalMargin), marginPercent)
+	return h
+}
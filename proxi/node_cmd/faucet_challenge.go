@@ -0,0 +1,177 @@
+package node_cmd
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"math/bits"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/blake2b"
+)
+
+// Faucet requests may be protected by a challenge:
+//   - 'pow': the client takes a random challenge from the faucet and finds a nonce such that
+//     blake2b-256(challenge || target address || nonce as 8 bytes big-endian) has at least the required number of leading zero bits.
+//     Each challenge can be used once and expires after some time. Number of outstanding challenges
+//     is limited per remote host, so one host can't exhaust the pool
+//   - 'token': the client sends one of the access tokens configured in the faucet
+
+const (
+	faucetChallengeNone  = "none"
+	faucetChallengePoW   = "pow"
+	faucetChallengeToken = "token"
+
+	defaultPoWDifficulty   = 20
+	maxPoWDifficulty       = 32
+	powChallengeTTL        = 5 * time.Minute
+	maxOutstandingPoW      = 10_000
+	maxOutstandingPoWHost  = 10
+	powChallengeByteLength = 16
+)
+
+type (
+	// faucetChallenge is returned by the faucet on the challenge path
+	faucetChallenge struct {
+		Type string `json:"type"`
+		// hex-encoded challenge and required number of leading zero bits. Only for 'pow'
+		Challenge  string `json:"challenge,omitempty"`
+		Difficulty int    `json:"difficulty,omitempty"`
+		// unix seconds
+		Expires int64 `json:"expires,omitempty"`
+	}
+
+	powChallenges struct {
+		mutex      sync.Mutex
+		difficulty int
+		issued     map[string]issuedChallenge
+		// number of outstanding challenges per remote host
+		perHost map[string]int
+	}
+
+	issuedChallenge struct {
+		expires time.Time
+		host    string
+	}
+)
+
+func newPoWChallenges(difficulty int) *powChallenges {
+	return &powChallenges{
+		difficulty: difficulty,
+		issued:     make(map[string]issuedChallenge),
+		perHost:    make(map[string]int),
+	}
+}
+
+func (p *powChallenges) issue(host string, now time.Time) (*faucetChallenge, error) {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	p.purge(now)
+	if len(p.issued) >= maxOutstandingPoW {
+		return nil, fmt.Errorf("too many outstanding challenges, try later")
+	}
+	if p.perHost[host] >= maxOutstandingPoWHost {
+		return nil, fmt.Errorf("too many outstanding challenges from %s, try later", host)
+	}
+	var buf [powChallengeByteLength]byte
+	if _, err := rand.Read(buf[:]); err != nil {
+		return nil, err
+	}
+	challenge := hex.EncodeToString(buf[:])
+	expires := now.Add(powChallengeTTL)
+	p.issued[challenge] = issuedChallenge{expires: expires, host: host}
+	p.perHost[host]++
+	return &faucetChallenge{
+		Type:       faucetChallengePoW,
+		Challenge:  challenge,
+		Difficulty: p.difficulty,
+		Expires:    expires.Unix(),
+	}, nil
+}
+
+// verify checks the solution and consumes the challenge, so it can't be used again
+func (p *powChallenges) verify(challenge, addr string, nonce uint64, now time.Time) error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	ic, found := p.issued[challenge]
+	if !found || now.After(ic.expires) {
+		return fmt.Errorf("unknown or expired challenge")
+	}
+	challengeBin, err := hex.DecodeString(challenge)
+	if err != nil {
+		return err
+	}
+	if powLeadingZeroBits(challengeBin, addr, nonce) < p.difficulty {
+		return fmt.Errorf("wrong proof of work")
+	}
+	p.remove(challenge)
+	return nil
+}
+
+func (p *powChallenges) purge(now time.Time) {
+	for c, ic := range p.issued {
+		if now.After(ic.expires) {
+			p.remove(c)
+		}
+	}
+}
+
+func (p *powChallenges) remove(challenge string) {
+	ic, found := p.issued[challenge]
+	if !found {
+		return
+	}
+	delete(p.issued, challenge)
+	if p.perHost[ic.host]--; p.perHost[ic.host] <= 0 {
+		delete(p.perHost, ic.host)
+	}
+}
+
+func powLeadingZeroBits(challenge []byte, addr string, nonce uint64) int {
+	var nonceBin [8]byte
+	binary.BigEndian.PutUint64(nonceBin[:], nonce)
+	h, _ := blake2b.New256(nil)
+	h.Write(challenge)
+	h.Write([]byte(addr))
+	h.Write(nonceBin[:])
+	ret := 0
+	for _, b := range h.Sum(nil) {
+		if b != 0 {
+			return ret + bits.LeadingZeros8(b)
+		}
+		ret += 8
+	}
+	return ret
+}
+
+// solvePoW finds the nonce for the challenge. Expected number of attempts is 2^difficulty
+func solvePoW(challenge string, addr string, difficulty int) (uint64, error) {
+	if difficulty > maxPoWDifficulty {
+		return 0, fmt.Errorf("difficulty %d is too big", difficulty)
+	}
+	challengeBin, err := hex.DecodeString(challenge)
+	if err != nil {
+		return 0, err
+	}
+	for nonce := uint64(0); ; nonce++ {
+		if powLeadingZeroBits(challengeBin, addr, nonce) >= difficulty {
+			return nonce, nil
+		}
+	}
+}
+
+// checkFaucetToken compares the token with each configured token in constant time
+func checkFaucetToken(token string, tokens []string) bool {
+	ret := false
+	for _, t := range tokens {
+		if subtle.ConstantTimeCompare([]byte(token), []byte(t)) == 1 {
+			ret = true
+		}
+	}
+	return ret && token != ""
+}
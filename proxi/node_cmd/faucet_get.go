@@ -1,10 +1,15 @@
 package node_cmd
 
 import (
+	"encoding/json"
 	"fmt"
+	"net/url"
+	"time"
 
 	"github.com/lunfardo314/proxima/api/client"
+	"github.com/lunfardo314/proxima/ledger/base"
 	"github.com/lunfardo314/proxima/proxi/glb"
+	"github.com/lunfardo314/proxima/util"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
@@ -15,8 +20,11 @@ func initGetFundsCmd() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "getfunds",
 		Short: `requests funds from a faucet`,
-		Args:  cobra.NoArgs,
-		Run:   getFundsCmd,
+		Long: `requests funds from a faucet. If the faucet requires proof of work, the challenge is taken from the faucet and solved.
+If the faucet requires access token, it is taken from 'faucet.token' in the profile.
+Then inclusion of the faucet transaction is tracked, unless --nowait is specified`,
+		Args: cobra.NoArgs,
+		Run:  getFundsCmd,
 	}
 
 	cmd.PersistentFlags().Uint("faucet.port", defaultFaucetPort, "faucet port")
@@ -27,6 +35,10 @@ func initGetFundsCmd() *cobra.Command {
 	err = viper.BindPFlag("faucet.host", cmd.PersistentFlags().Lookup("faucet.host"))
 	glb.AssertNoError(err)
 
+	cmd.PersistentFlags().String("faucet.token", "", "access token, if required by the faucet")
+	err = viper.BindPFlag("faucet.token", cmd.PersistentFlags().Lookup("faucet.token"))
+	glb.AssertNoError(err)
+
 	return cmd
 }
 
@@ -37,18 +49,95 @@ func getFundsCmd(_ *cobra.Command, _ []string) {
 
 	glb.Infof("requesting funds from faucet at %s", faucetURL)
 
-	path := fmt.Sprintf(getFundsPath+"?addr=%s", walletData.Account.String())
-
 	c := client.NewWithGoogleDNS(faucetURL)
-	answer, err := c.Get(path)
+	addr := walletData.Account.String()
+	params := url.Values{}
+	params.Set("addr", addr)
 
-	if err != nil || len(answer) > 2 {
+	ch, err := getFaucetChallenge(c)
+	if err != nil {
+		failGetFunds(err)
+		return
+	}
+	switch ch.Type {
+	case faucetChallengePoW:
+		glb.Infof("solving proof of work challenge, difficulty %d bits..", ch.Difficulty)
+		start := time.Now()
+		nonce, err := solvePoW(ch.Challenge, addr, ch.Difficulty)
 		if err != nil {
-			glb.Infof("error requesting funds from: %s", err.Error())
-		} else {
-			glb.Infof("error requesting funds from: %s", string(answer))
+			failGetFunds(err)
+			return
+		}
+		glb.Infof("solved in %v", time.Since(start).Truncate(time.Millisecond))
+		params.Set("challenge", ch.Challenge)
+		params.Set("nonce", fmt.Sprintf("%d", nonce))
+	case faucetChallengeToken:
+		token := viper.GetString("faucet.token")
+		if token == "" {
+			failGetFunds(fmt.Errorf("faucet requires access token. Specify 'faucet.token' in the profile or with the flag"))
+			return
 		}
-	} else {
-		glb.Infof("Funds requested successfully!")
+		params.Set("token", token)
+	}
+
+	answer, err := c.Get(getFundsPath + "?" + params.Encode())
+	if err != nil {
+		failGetFunds(err)
+		return
+	}
+	var resp faucetResponse
+	if err = json.Unmarshal(answer, &resp); err != nil {
+		failGetFunds(fmt.Errorf("%s", answer))
+		return
+	}
+	if resp.Error.Error != "" {
+		failGetFunds(fmt.Errorf("%s", resp.Error.Error))
+		return
+	}
+	txid, err := base.TransactionIDFromHexString(resp.TxID)
+	if err != nil {
+		failGetFunds(err)
+		return
+	}
+	glb.Infof("Funds requested successfully! %s tokens will be sent from the faucet %s, transaction %s",
+		util.Th(resp.Amount), resp.Source, txid.String())
+	if resp.Source == faucetSourceSequencer {
+		glb.Infof("the transaction is a withdrawal command to the faucet sequencer. Funds will come with its next milestone")
 	}
+	if !glb.NoWait() {
+		glb.TrackTxInclusion(txid, time.Second)
+	}
+	glb.PrintResult(&getFundsResult{
+		TxResult: glb.NewTxResult(txid, !glb.NoWait()),
+		Faucet:   faucetURL,
+		Account:  walletData.Account.String(),
+		Amount:   resp.Amount,
+	})
+}
+
+// getFaucetChallenge returns challenge required by the faucet. Faucets without challenges are treated as 'none'
+func getFaucetChallenge(c *client.APIClient) (*faucetChallenge, error) {
+	answer, err := c.Get(faucetChallengePath)
+	if err != nil {
+		return nil, err
+	}
+	var ret faucetChallenge
+	if err = json.Unmarshal(answer, &ret); err != nil || ret.Type == "" {
+		return &faucetChallenge{Type: faucetChallengeNone}, nil
+	}
+	return &ret, nil
+}
+
+func failGetFunds(err error) {
+	glb.Infof("error requesting funds from: %s", err.Error())
+	if glb.IsJSONOutput() {
+		glb.Fatalf("error requesting funds from faucet: %v", err)
+	}
+}
+
+type getFundsResult struct {
+	*glb.TxResult
+	Faucet  string `json:"faucet"`
+	Account string `json:"account"`
+	Amount  uint64 `json:"amount"`
 }
@@ -0,0 +1,56 @@
+package node_cmd
+
+import (
+	"net/http"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const faucetMetricsPath = "/metrics"
+
+// results of faucet requests, values of the 'result' label
+const (
+	faucetResultSent       = "sent"
+	faucetResultBadRequest = "bad_request"
+	faucetResultChallenge  = "challenge_failed"
+	faucetResultQuota      = "quota_exceeded"
+	faucetResultDrained    = "drained"
+	faucetResultFailed     = "failed"
+)
+
+type faucetMetrics struct {
+	registry      *prometheus.Registry
+	requests      *prometheus.CounterVec
+	sentAmount    prometheus.Counter
+	sourceBalance prometheus.Gauge
+	topUps        *prometheus.CounterVec
+}
+
+func newFaucetMetrics() *faucetMetrics {
+	ret := &faucetMetrics{
+		registry: prometheus.NewRegistry(),
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "proxi_faucet_requests",
+			Help: "number of faucet requests by result",
+		}, []string{"result"}),
+		sentAmount: prometheus.NewCounter(prometheus.CounterOpts{
+			Name: "proxi_faucet_sent_amount",
+			Help: "total amount of tokens sent by the faucet",
+		}),
+		sourceBalance: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "proxi_faucet_source_balance",
+			Help: "balance of the source of funds: own sequencer chain or the wallet",
+		}),
+		topUps: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "proxi_faucet_top_ups",
+			Help: "number of top-ups of the wallet from the own sequencer chain by result",
+		}, []string{"result"}),
+	}
+	ret.registry.MustRegister(ret.requests, ret.sentAmount, ret.sourceBalance, ret.topUps)
+	return ret
+}
+
+func (m *faucetMetrics) handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{Registry: m.registry})
+}
@@ -0,0 +1,136 @@
+package node_cmd
+
+import (
+	"encoding/json"
+	"errors"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/lunfardo314/proxima/util"
+)
+
+// faucet quota store keeps times of accepted requests per target account and per remote host for the last 24 hours.
+// It is saved to the JSON file after each accepted request, so quotas survive restart of the faucet
+
+type (
+	faucetQuotas struct {
+		mutex sync.Mutex
+		fname string
+		// unix seconds of accepted requests
+		Accounts map[string][]int64 `json:"accounts"`
+		Hosts    map[string][]int64 `json:"hosts"`
+	}
+)
+
+const defaultFaucetQuotaFile = "faucet_quotas.json"
+
+// openFaucetQuotas reads quota store from the file or creates empty one if file does not exist
+func openFaucetQuotas(fname string) (*faucetQuotas, error) {
+	ret := &faucetQuotas{
+		fname:    fname,
+		Accounts: make(map[string][]int64),
+		Hosts:    make(map[string][]int64),
+	}
+	data, err := os.ReadFile(fname)
+	if errors.Is(err, os.ErrNotExist) {
+		return ret, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err = json.Unmarshal(data, ret); err != nil {
+		return nil, err
+	}
+	if ret.Accounts == nil {
+		ret.Accounts = make(map[string][]int64)
+	}
+	if ret.Hosts == nil {
+		ret.Hosts = make(map[string][]int64)
+	}
+	ret.purge(time.Now())
+	return ret, nil
+}
+
+// checkAndAdd accepts request if both the account and the host are within quotas and records it
+func (q *faucetQuotas) checkAndAdd(account, host string, perHour, perDay int, now time.Time) (bool, error) {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	q.purge(now)
+	for _, lst := range [][]int64{q.Accounts[account], q.Hosts[host]} {
+		if !withinQuota(lst, perHour, perDay, now) {
+			return false, nil
+		}
+	}
+	q.Accounts[account] = append(q.Accounts[account], now.Unix())
+	q.Hosts[host] = append(q.Hosts[host], now.Unix())
+	return true, q.save()
+}
+
+// rollback removes request recorded by checkAndAdd at the same time, when funds were not sent
+func (q *faucetQuotas) rollback(account, host string, now time.Time) error {
+	q.mutex.Lock()
+	defer q.mutex.Unlock()
+
+	for _, e := range []struct {
+		m   map[string][]int64
+		key string
+	}{{q.Accounts, account}, {q.Hosts, host}} {
+		lst := e.m[e.key]
+		for i := len(lst) - 1; i >= 0; i-- {
+			if lst[i] == now.Unix() {
+				lst = append(lst[:i], lst[i+1:]...)
+				break
+			}
+		}
+		if len(lst) == 0 {
+			delete(e.m, e.key)
+		} else {
+			e.m[e.key] = lst
+		}
+	}
+	return q.save()
+}
+
+func withinQuota(lst []int64, perHour, perDay int, now time.Time) bool {
+	if len(lst) >= perDay {
+		return false
+	}
+	lastHour := 0
+	for _, when := range lst {
+		if now.Sub(time.Unix(when, 0)) <= time.Hour {
+			lastHour++
+		}
+	}
+	return lastHour < perHour
+}
+
+// purge removes requests older than 24 hours
+func (q *faucetQuotas) purge(now time.Time) {
+	for _, m := range []map[string][]int64{q.Accounts, q.Hosts} {
+		for k, lst := range m {
+			lst = util.PurgeSlice(lst, func(when int64) bool {
+				return now.Sub(time.Unix(when, 0)) <= 24*time.Hour
+			})
+			if len(lst) == 0 {
+				delete(m, k)
+			} else {
+				m[k] = lst
+			}
+		}
+	}
+}
+
+// save writes the store to the temporary file and renames it, so the file is never left half-written
+func (q *faucetQuotas) save() error {
+	data, err := json.MarshalIndent(q, "", "  ")
+	if err != nil {
+		return err
+	}
+	tmp := q.fname + ".tmp"
+	if err = os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, q.fname)
+}
@@ -7,6 +7,7 @@ import (
 	"net"
 	"net/http"
 	"os"
+	"strconv"
 	"sync"
 	"time"
 
@@ -23,7 +24,12 @@ import (
 	"github.com/spf13/viper"
 )
 
-const getFundsPath = "/"
+const (
+	getFundsPath        = "/"
+	faucetChallengePath = "/challenge"
+	faucetStatusPath    = "/status"
+	faucetTokenHeader   = "X-Faucet-Token"
+)
 
 type (
 	faucetServerConfig struct {
@@ -33,29 +39,84 @@ type (
 		maxRequestsPerHour uint
 		maxRequestsPerDay  uint
 		bottom             uint64
+		quotaFile          string
+		challenge          string
+		powDifficulty      int
+		tokens             []string
+		// top-up of the wallet from the own sequencer chain. 0 means top-up is disabled
+		topUpAmount    uint64
+		topUpThreshold uint64
 	}
 
 	faucetServer struct {
-		cfg                faucetServerConfig
-		walletData         glb.WalletData
-		mutex              sync.Mutex
-		accountRequestList map[string][]time.Time
-		addressRequestList map[string][]time.Time
-		client             *client.APIClient
+		cfg        faucetServerConfig
+		walletData glb.WalletData
+		quotas     *faucetQuotas
+		pow        *powChallenges
+		metrics    *faucetMetrics
+		client     *client.APIClient
+		// serializes top-ups
+		topUpMutex sync.Mutex
+		lastTopUp  time.Time
+		// serializes transactions spending wallet outputs: requests and top-ups
+		walletMutex sync.Mutex
+	}
+
+	// faucetResponse is the JSON response of the faucet to the funds request
+	faucetResponse struct {
+		api.Error
+		// hex-encoded ID of the submitted transaction. When funds are drawn from the sequencer chain,
+		// it is the transaction with the withdrawal command to the sequencer
+		TxID   string `json:"txid,omitempty"`
+		Amount uint64 `json:"amount,omitempty"`
+		Target string `json:"target,omitempty"`
+		// 'sequencer' or 'wallet'
+		Source string `json:"source,omitempty"`
+		Status string `json:"status,omitempty"`
+	}
+
+	// faucetTxStatus is the JSON response of the faucet on the status path
+	faucetTxStatus struct {
+		api.Error
+		TxID   string `json:"txid"`
+		Status string `json:"status"`
+		LRBID  string `json:"lrbid,omitempty"`
+		// depth of the LRB where transaction was found. -1 if it is not in the LRB yet
+		FoundAtDepth int `json:"found_at_depth"`
 	}
 )
 
+// finality status of the faucet transaction
+const (
+	faucetTxSubmitted = "submitted"
+	faucetTxPending   = "pending"
+	faucetTxFinal     = "final"
+)
+
+// source of funds in faucetResponse
+const (
+	faucetSourceSequencer = "sequencer"
+	faucetSourceWallet    = "wallet"
+)
+
 const (
-	minAmount         = 1_000_000
-	defaultFaucetPort = 9500
+	minAmount             = 1_000_000
+	defaultFaucetPort     = 9500
+	topUpCheckPeriod      = 30 * time.Second
+	topUpMinInterval      = 2 * time.Minute
+	defaultTopUpThreshold = 10 // in faucet amounts above bottom
 )
 
 func initFaucetServerCmd() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "faucet",
 		Short: `starts a faucet server on the wallet`,
-		Args:  cobra.NoArgs,
-		Run:   runFaucetServerCmd,
+		Long: `starts a faucet server on the wallet. Funds are drawn from the own sequencer chain or from the wallet.
+Quotas of requests per target account and per remote host are kept in the file, so they survive restarts.
+Requests can be protected by the proof-of-work or access token challenge. Prometheus metrics are exposed on '/metrics'.
+See 'faucet' section of the profile`,
+		Args: cobra.NoArgs,
+		Run:  runFaucetServerCmd,
 	}
 	return cmd
 }
@@ -69,13 +130,19 @@ func runFaucetServerCmd(_ *cobra.Command, _ []string) {
 	glb.Assertf(glb.GetTagAlongSequencerID() != nil, "tag-along sequencer not specified")
 
 	fct := &faucetServer{
-		walletData:         walletData,
-		accountRequestList: make(map[string][]time.Time),
-		addressRequestList: make(map[string][]time.Time),
-		client:             glb.GetClient(),
+		walletData: walletData,
+		metrics:    newFaucetMetrics(),
+		client:     glb.GetClient(),
 	}
 	fct.readFaucetServerConfigIn()
 
+	var err error
+	fct.quotas, err = openFaucetQuotas(fct.cfg.quotaFile)
+	glb.AssertNoError(err)
+	if fct.cfg.challenge == faucetChallengePoW {
+		fct.pow = newPoWChallenges(fct.cfg.powDifficulty)
+	}
+
 	fct.displayFaucetConfig()
 
 	if fct.cfg.fromChain {
@@ -110,6 +177,39 @@ func (fct *faucetServer) readFaucetServerConfigIn() {
 	if fct.cfg.bottom < fct.absoluteBottom() {
 		fct.cfg.bottom = fct.absoluteBottom()
 	}
+	if fct.cfg.quotaFile = sub.GetString("quota_file"); fct.cfg.quotaFile == "" {
+		fct.cfg.quotaFile = defaultFaucetQuotaFile
+	}
+
+	if fct.cfg.challenge = sub.GetString("challenge"); fct.cfg.challenge == "" {
+		fct.cfg.challenge = faucetChallengeNone
+	}
+	switch fct.cfg.challenge {
+	case faucetChallengeNone:
+	case faucetChallengePoW:
+		if fct.cfg.powDifficulty = sub.GetInt("pow_difficulty"); fct.cfg.powDifficulty == 0 {
+			fct.cfg.powDifficulty = defaultPoWDifficulty
+		}
+		glb.Assertf(fct.cfg.powDifficulty > 0 && fct.cfg.powDifficulty <= maxPoWDifficulty,
+			"pow_difficulty must be between 1 and %d", maxPoWDifficulty)
+	case faucetChallengeToken:
+		fct.cfg.tokens = sub.GetStringSlice("tokens")
+		glb.Assertf(len(fct.cfg.tokens) > 0, "access tokens must be specified with challenge '%s'", faucetChallengeToken)
+	default:
+		glb.Fatalf("wrong challenge '%s'. Must be one of '%s', '%s' or '%s'",
+			fct.cfg.challenge, faucetChallengeNone, faucetChallengePoW, faucetChallengeToken)
+	}
+
+	fct.cfg.topUpAmount = sub.GetUint64("top_up.amount")
+	if fct.cfg.topUpAmount > 0 {
+		glb.Assertf(!fct.cfg.fromChain, "top-up is only possible when the wallet is the source of funds")
+		glb.Assertf(fct.cfg.topUpAmount >= fct.cfg.amount, "top-up amount must be at least the faucet amount")
+		if fct.cfg.topUpThreshold = sub.GetUint64("top_up.threshold"); fct.cfg.topUpThreshold == 0 {
+			fct.cfg.topUpThreshold = fct.cfg.bottom + defaultTopUpThreshold*fct.cfg.amount
+		}
+		// top-up may be needed right away
+		fct.checkTopUp()
+	}
 
 	err := fct.checkBottom()
 	glb.AssertNoError(err)
@@ -123,6 +223,7 @@ func (fct *faucetServer) absoluteBottom() uint64 {
 	return fct.cfg.amount + glb.GetTagAlongFee()
 }
 
+// checkBottom checks the balance of the source of funds and updates the metric
 func (fct *faucetServer) checkBottom() error {
 	abs := fct.absoluteBottom()
 	if fct.cfg.fromChain {
@@ -130,6 +231,7 @@ func (fct *faucetServer) checkBottom() error {
 		if err != nil {
 			return err
 		}
+		fct.metrics.sourceBalance.Set(float64(o.Output.Amount()))
 		if o.Output.Amount() < abs {
 			return fmt.Errorf("not enough balance on own sequencer %s. Must be at least %s, got %s",
 				fct.walletData.Sequencer.String(), util.Th(abs), util.Th(o.Output.Amount()))
@@ -139,6 +241,7 @@ func (fct *faucetServer) checkBottom() error {
 		if err != nil {
 			return err
 		}
+		fct.metrics.sourceBalance.Set(float64(balance))
 		if balance < abs {
 			return fmt.Errorf("not enough balance on source address %s. Must be at least %s, got %s",
 				fct.walletData.Account.String(), util.Th(abs), util.Th(balance))
@@ -168,42 +271,84 @@ func (fct *faucetServer) displayFaucetConfig() {
 	} else {
 		glb.Infof("     funds will be drawn from: %s (balance %s)", fct.walletData.Account.String(), util.Th(walletBalance))
 	}
+	if fct.cfg.topUpAmount > 0 {
+		glb.Infof("     top-up:                   %s from %s when wallet balance is below %s",
+			util.Th(fct.cfg.topUpAmount), fct.walletData.Sequencer.String(), util.Th(fct.cfg.topUpThreshold))
+	}
 	glb.Infof("     maximum number of requests per hour: %d, per day: %d", fct.cfg.maxRequestsPerHour, fct.cfg.maxRequestsPerDay)
+	glb.Infof("     quotas are kept in:       %s", fct.cfg.quotaFile)
+	switch fct.cfg.challenge {
+	case faucetChallengePoW:
+		glb.Infof("     challenge:                proof of work, difficulty %d bits", fct.cfg.powDifficulty)
+	case faucetChallengeToken:
+		glb.Infof("     challenge:                access token (%d tokens configured)", len(fct.cfg.tokens))
+	default:
+		glb.Infof("     challenge:                none")
+	}
 }
 
 func (fct *faucetServer) handler(w http.ResponseWriter, r *http.Request) {
-	err := fct.checkBottom()
-	if err != nil {
-		glb.Infof("error from checkBottom: %s", err.Error())
-		writeResponse(w, err.Error())
+	if r.URL.Path != getFundsPath {
+		http.NotFound(w, r)
 		return
 	}
+	remoteHost, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		remoteHost = r.RemoteAddr
+	}
 
 	targetStr, ok := r.URL.Query()["addr"]
 	if !ok || len(targetStr) != 1 {
-		writeResponse(w, "wrong parameter 'addr' in request 'get_funds'")
+		fct.refuse(w, http.StatusBadRequest, faucetResultBadRequest, "wrong parameter 'addr' in request 'get_funds'")
+		return
+	}
+	targetLock, err := ledger.AccountableFromSource(targetStr[0])
+	if err != nil {
+		glb.Infof("error from AccountableFromSource: %s", err.Error())
+		fct.refuse(w, http.StatusBadRequest, faucetResultBadRequest, err.Error())
 		return
 	}
 
-	if !fct.checkAndUpdateRequestTime(targetStr[0], r.RemoteAddr) {
-		glb.Infof("funds refused to send to %s (remote = %s)", targetStr[0], r.RemoteAddr)
-		writeResponse(w, fmt.Sprintf("maximum %d requests per hour and %d per day are allowed", fct.cfg.maxRequestsPerHour, fct.cfg.maxRequestsPerDay))
+	if err = fct.checkChallenge(r, targetStr[0]); err != nil {
+		glb.Infof("funds refused to send to %s (remote = %s): %v", targetStr[0], r.RemoteAddr, err)
+		fct.refuse(w, http.StatusForbidden, faucetResultChallenge, err.Error())
 		return
 	}
 
-	targetLock, err := ledger.AccountableFromSource(targetStr[0])
+	if err = fct.checkBottom(); err != nil {
+		glb.Infof("error from checkBottom: %s", err.Error())
+		go fct.checkTopUp()
+		fct.refuse(w, http.StatusServiceUnavailable, faucetResultDrained, err.Error())
+		return
+	}
+
+	requestTime := time.Now()
+	accepted, err := fct.quotas.checkAndAdd(targetLock.String(), remoteHost,
+		int(fct.cfg.maxRequestsPerHour), int(fct.cfg.maxRequestsPerDay), requestTime)
 	if err != nil {
-		glb.Infof("error from AccountableFromSource: %s", err.Error())
-		writeResponse(w, err.Error())
+		// request is accepted in memory anyway
+		glb.Infof("failed to save faucet quotas to %s: %v", fct.cfg.quotaFile, err)
+	}
+	if !accepted {
+		glb.Infof("funds refused to send to %s (remote = %s)", targetStr[0], r.RemoteAddr)
+		fct.refuse(w, http.StatusTooManyRequests, faucetResultQuota,
+			fmt.Sprintf("maximum %d requests per hour and %d per day are allowed", fct.cfg.maxRequestsPerHour, fct.cfg.maxRequestsPerDay))
 		return
 	}
+
+	resp := &faucetResponse{
+		Amount: fct.cfg.amount,
+		Target: targetLock.String(),
+	}
 	var txid base.TransactionID
 	var fromStr string
 	if fct.cfg.fromChain {
 		fromStr = "sequencer " + fct.walletData.Sequencer.StringShort()
-		txid, err = fct.redrawFromChain(targetLock)
+		resp.Source = faucetSourceSequencer
+		txid, err = fct.redrawFromChain(targetLock, fct.cfg.amount)
 	} else {
 		fromStr = "wallet address " + fct.walletData.Account.String()
+		resp.Source = faucetSourceWallet
 		txid, err = fct.redrawFromAccount(targetLock)
 	}
 
@@ -211,30 +356,156 @@ func (fct *faucetServer) handler(w http.ResponseWriter, r *http.Request) {
 		glb.Infof("requested faucet transfer of %s tokens to %s from %s (remote = %s)",
 			util.Th(fct.cfg.amount), targetLock.String(), fromStr, r.RemoteAddr)
 		glb.Infof("             transaction %s (hex = %s)", txid.String(), txid.StringHex())
-		writeResponse(w, "")
+		glb.PrintResult(&transferResult{
+			TxResult: glb.NewTxResult(txid, false),
+			Amount:   fct.cfg.amount,
+			Target:   targetLock.String(),
+			Fee:      glb.GetTagAlongFee(),
+		})
+		fct.metrics.requests.WithLabelValues(faucetResultSent).Inc()
+		fct.metrics.sentAmount.Add(float64(fct.cfg.amount))
+		resp.TxID = txid.StringHex()
+		resp.Status = faucetTxSubmitted
+		writeFaucetJSON(w, http.StatusOK, resp)
 	} else {
 		glb.Infof("failed faucet transfer of %s tokens to %s from %s (remote = %s): err = %v",
 			util.Th(fct.cfg.amount), targetLock.String(), fromStr, r.RemoteAddr, err)
-		writeResponse(w, err.Error())
+		// failed transfer does not count in quotas
+		if errRollback := fct.quotas.rollback(targetLock.String(), remoteHost, requestTime); errRollback != nil {
+			glb.Infof("failed to save faucet quotas to %s: %v", fct.cfg.quotaFile, errRollback)
+		}
+		fct.metrics.requests.WithLabelValues(faucetResultFailed).Inc()
+		resp.Error.Error = err.Error()
+		writeFaucetJSON(w, http.StatusInternalServerError, resp)
 	}
 
 	logRequest(targetStr[0], r.RemoteAddr, fct.cfg.amount, err)
 }
 
-func (fct *faucetServer) redrawFromChain(targetLock ledger.Accountable) (base.TransactionID, error) {
+func (fct *faucetServer) refuse(w http.ResponseWriter, status int, result, errStr string) {
+	fct.metrics.requests.WithLabelValues(result).Inc()
+	writeFaucetJSON(w, status, &faucetResponse{Error: api.Error{Error: errStr}})
+}
+
+// checkChallenge checks proof of work or access token in the request, according to the configuration
+func (fct *faucetServer) checkChallenge(r *http.Request, addr string) error {
+	switch fct.cfg.challenge {
+	case faucetChallengePoW:
+		challenge := r.URL.Query().Get("challenge")
+		nonce, err := strconv.ParseUint(r.URL.Query().Get("nonce"), 10, 64)
+		if challenge == "" || err != nil {
+			return fmt.Errorf("proof of work is required: take challenge from '%s' and provide 'challenge' and 'nonce' parameters", faucetChallengePath)
+		}
+		return fct.pow.verify(challenge, addr, nonce, time.Now())
+	case faucetChallengeToken:
+		token := r.Header.Get(faucetTokenHeader)
+		if token == "" {
+			token = r.URL.Query().Get("token")
+		}
+		if !checkFaucetToken(token, fct.cfg.tokens) {
+			return fmt.Errorf("valid access token is required")
+		}
+	}
+	return nil
+}
+
+func (fct *faucetServer) challengeHandler(w http.ResponseWriter, r *http.Request) {
+	if fct.cfg.challenge != faucetChallengePoW {
+		writeFaucetJSON(w, http.StatusOK, &faucetChallenge{Type: fct.cfg.challenge})
+		return
+	}
+	remoteHost, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		remoteHost = r.RemoteAddr
+	}
+	ch, err := fct.pow.issue(remoteHost, time.Now())
+	if err != nil {
+		writeFaucetJSON(w, http.StatusTooManyRequests, &api.Error{Error: err.Error()})
+		return
+	}
+	writeFaucetJSON(w, http.StatusOK, ch)
+}
+
+// statusHandler returns finality status of the transaction: 'final' if it is included in the LRB, otherwise 'pending'
+func (fct *faucetServer) statusHandler(w http.ResponseWriter, r *http.Request) {
+	txid, err := base.TransactionIDFromHexString(r.URL.Query().Get("txid"))
+	if err != nil {
+		writeFaucetJSON(w, http.StatusBadRequest, &faucetTxStatus{Error: api.Error{Error: "wrong parameter 'txid': " + err.Error()}})
+		return
+	}
+	resp := &faucetTxStatus{TxID: txid.StringHex()}
+	lrbid, foundAtDepth, err := fct.client.CheckTransactionIDInLRB(txid, glb.GetTargetInclusionDepth())
+	if err != nil {
+		resp.Error.Error = err.Error()
+		writeFaucetJSON(w, http.StatusInternalServerError, resp)
+		return
+	}
+	resp.LRBID = lrbid.StringHex()
+	resp.FoundAtDepth = foundAtDepth
+	resp.Status = faucetTxPending
+	if foundAtDepth >= 0 {
+		resp.Status = faucetTxFinal
+	}
+	writeFaucetJSON(w, http.StatusOK, resp)
+}
+
+// checkTopUp withdraws tokens from the own sequencer chain to the wallet when wallet balance goes below the threshold.
+// Next top-up is not attempted earlier than topUpMinInterval, to let the previous one to be settled
+func (fct *faucetServer) checkTopUp() {
+	if fct.cfg.topUpAmount == 0 {
+		return
+	}
+	fct.topUpMutex.Lock()
+	defer fct.topUpMutex.Unlock()
+
+	if time.Since(fct.lastTopUp) < topUpMinInterval {
+		return
+	}
+	balance, _, err := fct.client.GetNonChainBalance(fct.walletData.Account)
+	if err != nil {
+		glb.Infof("top-up: can't get wallet balance: %v", err)
+		return
+	}
+	if balance >= fct.cfg.topUpThreshold {
+		return
+	}
+	fct.lastTopUp = time.Now()
+	txid, err := fct.redrawFromChain(fct.walletData.Account, fct.cfg.topUpAmount)
+	if err != nil {
+		glb.Infof("top-up: failed to withdraw %s from %s: %v", util.Th(fct.cfg.topUpAmount), fct.walletData.Sequencer.StringShort(), err)
+		fct.metrics.topUps.WithLabelValues(faucetResultFailed).Inc()
+		return
+	}
+	glb.Infof("top-up: wallet balance %s is below %s. Requested withdrawal of %s from %s, transaction %s",
+		util.Th(balance), util.Th(fct.cfg.topUpThreshold), util.Th(fct.cfg.topUpAmount), fct.walletData.Sequencer.StringShort(), txid.String())
+	fct.metrics.topUps.WithLabelValues(faucetResultSent).Inc()
+}
+
+func (fct *faucetServer) topUpLoop() {
+	for {
+		time.Sleep(topUpCheckPeriod)
+		fct.checkTopUp()
+	}
+}
+
+// redrawFromChain sends withdrawal command to the own sequencer
+func (fct *faucetServer) redrawFromChain(targetLock ledger.Accountable, amount uint64) (base.TransactionID, error) {
+	fct.walletMutex.Lock()
+	defer fct.walletMutex.Unlock()
+
 	clnt := glb.GetClient()
 	o, _, _, err := clnt.GetChainOutput(*glb.GetOwnSequencerID())
 	if err != nil {
 		return base.TransactionID{}, err
 	}
-	if o.Output.Amount() < ledger.L().ID.MinimumAmountOnSequencer+fct.cfg.amount {
+	if o.Output.Amount() < ledger.L().ID.MinimumAmountOnSequencer+amount {
 		return base.TransactionID{}, fmt.Errorf("not enough tokens on the sequencer %s", glb.GetOwnSequencerID().String())
 	}
 	walletOutputs, _, _, err := clnt.GetOutputsForAmount(fct.walletData.Account, glb.GetTagAlongFee())
 	if err != nil {
 		return base.TransactionID{}, err
 	}
-	withdrawCmd, err := commands.NewWithdrawCommandData(fct.cfg.amount, targetLock.AsLock())
+	withdrawCmd, err := commands.NewWithdrawCommandData(amount, targetLock.AsLock())
 	if err != nil {
 		return base.TransactionID{}, err
 	}
@@ -261,6 +532,9 @@ func (fct *faucetServer) redrawFromChain(targetLock ledger.Accountable) (base.Tr
 }
 
 func (fct *faucetServer) redrawFromAccount(targetLock ledger.Accountable) (base.TransactionID, error) {
+	fct.walletMutex.Lock()
+	defer fct.walletMutex.Unlock()
+
 	txCtx, err := glb.GetClient().TransferFromED25519Wallet(client.TransferFromED25519WalletParams{
 		WalletPrivateKey: fct.walletData.PrivateKey,
 		TagAlongSeqID:    glb.GetTagAlongSequencerID(),
@@ -275,55 +549,6 @@ func (fct *faucetServer) redrawFromAccount(targetLock ledger.Accountable) (base.
 	return txCtx.TransactionID(), nil
 }
 
-func _trimToLastDay(lst []time.Time) ([]time.Time, int) {
-	ret := util.PurgeSlice(lst, func(when time.Time) bool {
-		return time.Since(when) <= 24*time.Hour
-	})
-	lastHour := 0
-	for _, when := range ret {
-		if time.Since(when) <= time.Hour {
-			lastHour++
-		}
-	}
-	return ret, lastHour
-}
-
-func (fct *faucetServer) checkAndUpdateRequestTime(account string, addr string) bool {
-	fct.mutex.Lock()
-	defer fct.mutex.Unlock()
-
-	var lastHour int
-
-	lst, ok := fct.accountRequestList[account]
-	if ok {
-		lst, lastHour = _trimToLastDay(lst)
-		if len(lst) >= int(fct.cfg.maxRequestsPerDay) || lastHour >= int(fct.cfg.maxRequestsPerHour) {
-			return false
-		}
-		lst = append(lst, time.Now())
-	} else {
-		lst = []time.Time{time.Now()}
-	}
-	fct.accountRequestList[account] = lst
-
-	remoteHost, _, err := net.SplitHostPort(addr)
-	if err != nil {
-		remoteHost = addr
-	}
-	lst, ok = fct.addressRequestList[remoteHost]
-	if ok {
-		lst, lastHour = _trimToLastDay(lst)
-		if len(lst) >= int(fct.cfg.maxRequestsPerDay) || lastHour >= int(fct.cfg.maxRequestsPerHour) {
-			return false
-		}
-		lst = append(lst, time.Now())
-	} else {
-		lst = []time.Time{time.Now()}
-	}
-	fct.addressRequestList[remoteHost] = lst
-	return true
-}
-
 const faucetLogName = "faucet_requests.log"
 
 func logRequest(account string, remote string, funds uint64, err error) {
@@ -347,24 +572,26 @@ func logRequest(account string, remote string, funds uint64, err error) {
 	}
 }
 
-func writeResponse(w http.ResponseWriter, respStr string) {
-	var respBytes []byte
-	var err error
-	if len(respStr) > 0 {
-		respBytes, err = json.Marshal(&api.Error{Error: respStr})
-	} else {
-		respBytes, err = json.Marshal(&api.Error{})
-	}
+func writeFaucetJSON(w http.ResponseWriter, status int, resp any) {
+	respBytes, err := json.Marshal(resp)
 	if err != nil {
 		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
 	_, err = w.Write(respBytes)
 	util.AssertNoError(err)
 }
 
 func (fct *faucetServer) run() {
 	http.HandleFunc(getFundsPath, fct.handler) // Route for the handler function
+	http.HandleFunc(faucetChallengePath, fct.challengeHandler)
+	http.HandleFunc(faucetStatusPath, fct.statusHandler)
+	http.Handle(faucetMetricsPath, fct.metrics.handler())
+	if fct.cfg.topUpAmount > 0 {
+		go fct.topUpLoop()
+	}
 	sport := fmt.Sprintf(":%d", fct.cfg.port)
 	glb.Infof("\nrunning proxi faucet server on %s. Press Ctrl-C to stop..\n", sport)
 	glb.AssertNoError(http.ListenAndServe(sport, nil))
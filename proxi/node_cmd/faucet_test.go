@@ -0,0 +1,139 @@
+package node_cmd
+
+import (
+	"encoding/hex"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFaucetQuotas(t *testing.T) {
+	fname := filepath.Join(t.TempDir(), "quotas.json")
+	now := time.Now().Truncate(time.Second)
+
+	q, err := openFaucetQuotas(fname)
+	require.NoError(t, err)
+	ok, err := q.checkAndAdd("a(0x01)", "1.2.3.4", 1, 2, now)
+	require.NoError(t, err)
+	require.True(t, ok)
+	// per hour quota of the account
+	ok, err = q.checkAndAdd("a(0x01)", "5.6.7.8", 1, 2, now.Add(time.Minute))
+	require.NoError(t, err)
+	require.False(t, ok)
+	// per hour quota of the host
+	ok, err = q.checkAndAdd("a(0x02)", "1.2.3.4", 1, 2, now.Add(time.Minute))
+	require.NoError(t, err)
+	require.False(t, ok)
+
+	// quotas survive restart
+	q, err = openFaucetQuotas(fname)
+	require.NoError(t, err)
+	ok, err = q.checkAndAdd("a(0x01)", "5.6.7.8", 1, 2, now.Add(time.Minute))
+	require.NoError(t, err)
+	require.False(t, ok)
+
+	// per day quota
+	ok, err = q.checkAndAdd("a(0x01)", "5.6.7.8", 1, 2, now.Add(2*time.Hour))
+	require.NoError(t, err)
+	require.True(t, ok)
+	ok, err = q.checkAndAdd("a(0x01)", "9.9.9.9", 1, 2, now.Add(4*time.Hour))
+	require.NoError(t, err)
+	require.False(t, ok)
+
+	// old requests are purged
+	ok, err = q.checkAndAdd("a(0x01)", "9.9.9.9", 1, 2, now.Add(27*time.Hour))
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.Equal(t, 1, len(q.Accounts["a(0x01)"]))
+	require.Equal(t, 1, len(q.Hosts))
+
+	// failed transfer is rolled back and does not count
+	later := now.Add(30 * time.Hour)
+	ok, err = q.checkAndAdd("a(0x03)", "7.7.7.7", 1, 2, later)
+	require.NoError(t, err)
+	require.True(t, ok)
+	require.NoError(t, q.rollback("a(0x03)", "7.7.7.7", later))
+	q, err = openFaucetQuotas(fname)
+	require.NoError(t, err)
+	require.Equal(t, 0, len(q.Accounts["a(0x03)"]))
+	ok, err = q.checkAndAdd("a(0x03)", "7.7.7.7", 1, 2, later.Add(time.Minute))
+	require.NoError(t, err)
+	require.True(t, ok)
+}
+
+func TestFaucetPoW(t *testing.T) {
+	const addr = "a(0x01)"
+	now := time.Now()
+	p := newPoWChallenges(8)
+
+	ch, err := p.issue("1.2.3.4", now)
+	require.NoError(t, err)
+	require.Equal(t, faucetChallengePoW, ch.Type)
+	require.Equal(t, 8, ch.Difficulty)
+
+	nonce, err := solvePoW(ch.Challenge, addr, ch.Difficulty)
+	require.NoError(t, err)
+	// solution is bound to the address
+	if powLeadingZeroBitsHex(t, ch.Challenge, "a(0x02)", nonce) < ch.Difficulty {
+		require.Error(t, p.verify(ch.Challenge, "a(0x02)", nonce, now))
+	}
+	require.NoError(t, p.verify(ch.Challenge, addr, nonce, now))
+	// challenge can be used only once
+	require.Error(t, p.verify(ch.Challenge, addr, nonce, now))
+
+	// expired challenge
+	ch, err = p.issue("1.2.3.4", now)
+	require.NoError(t, err)
+	nonce, err = solvePoW(ch.Challenge, addr, ch.Difficulty)
+	require.NoError(t, err)
+	require.Error(t, p.verify(ch.Challenge, addr, nonce, now.Add(powChallengeTTL+time.Second)))
+}
+
+func TestFaucetPoWPerHost(t *testing.T) {
+	const addr = "a(0x01)"
+	now := time.Now()
+	p := newPoWChallenges(1)
+
+	challenges := make([]*faucetChallenge, 0)
+	for i := 0; i < maxOutstandingPoWHost; i++ {
+		ch, err := p.issue("1.2.3.4", now)
+		require.NoError(t, err)
+		challenges = append(challenges, ch)
+	}
+	_, err := p.issue("1.2.3.4", now)
+	require.Error(t, err)
+	// other hosts are not affected
+	_, err = p.issue("5.6.7.8", now)
+	require.NoError(t, err)
+
+	// solved challenge releases the slot
+	nonce, err := solvePoW(challenges[0].Challenge, addr, challenges[0].Difficulty)
+	require.NoError(t, err)
+	require.NoError(t, p.verify(challenges[0].Challenge, addr, nonce, now))
+	_, err = p.issue("1.2.3.4", now)
+	require.NoError(t, err)
+	_, err = p.issue("1.2.3.4", now)
+	require.Error(t, err)
+
+	// expired challenges release slots
+	_, err = p.issue("1.2.3.4", now.Add(powChallengeTTL+time.Second))
+	require.NoError(t, err)
+	require.Equal(t, 1, len(p.perHost))
+	require.Equal(t, 1, p.perHost["1.2.3.4"])
+}
+
+func powLeadingZeroBitsHex(t *testing.T, challenge, addr string, nonce uint64) int {
+	ch, err := hex.DecodeString(challenge)
+	require.NoError(t, err)
+	return powLeadingZeroBits(ch, addr, nonce)
+}
+
+func TestFaucetToken(t *testing.T) {
+	tokens := []string{"alpha", "beta"}
+	require.True(t, checkFaucetToken("beta", tokens))
+	require.False(t, checkFaucetToken("gamma", tokens))
+	require.False(t, checkFaucetToken("", tokens))
+	require.False(t, checkFaucetToken("", []string{""}))
+}
@@ -27,4 +27,5 @@ func runGetChainOutputCmd(_ *cobra.Command, args []string) {
 	glb.AssertNoError(err)
 
 	glb.Infof(o.String())
+	glb.PrintResult(glb.NewUTXOResult(&o.OutputWithID))
 }
@@ -25,4 +25,5 @@ func dbNodeLedgerIDCmd(_ *cobra.Command, _ []string) {
 	err = os.WriteFile(glb.LedgerIDFileName, yamlData, 0644)
 	glb.AssertNoError(err)
 	glb.Infof("ledger definitions has been saved to '%s'", glb.LedgerIDFileName)
+	glb.PrintResult(&glb.FileResult{File: glb.LedgerIDFileName})
 }
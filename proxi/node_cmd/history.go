@@ -0,0 +1,100 @@
+package node_cmd
+
+import (
+	"fmt"
+
+	"github.com/lunfardo314/proxima/api"
+	"github.com/lunfardo314/proxima/ledger"
+	"github.com/lunfardo314/proxima/ledger/base"
+	"github.com/lunfardo314/proxima/proxi/glb"
+	"github.com/lunfardo314/proxima/util"
+	"github.com/spf13/cobra"
+)
+
+const defaultHistoryRecords = 50
+
+var (
+	historyAccount string
+	historySince   uint32
+	historyMax     int
+)
+
+func initHistoryCmd() *cobra.Command {
+	historyCmd := &cobra.Command{
+		Use:   "history",
+		Short: `lists transactions touching the account, the latest first`,
+		Long: `lists transactions touching the account, the latest first. Transactions are taken from the latest reliable branch (final)
+and from the latest heaviest branch (pending). The node keeps transaction IDs in the ledger state only for some time,
+so the history does not go deeper than that. Sequencer transactions are not listed`,
+		Args: cobra.NoArgs,
+		Run:  runHistoryCmd,
+	}
+	historyCmd.PersistentFlags().StringVar(&historyAccount, "account", "", "ED25519 address in EasyFL format 'a(0x....)'. Default is the wallet's account")
+	historyCmd.PersistentFlags().Uint32Var(&historySince, "since", 0, "the earliest slot")
+	historyCmd.PersistentFlags().IntVar(&historyMax, "max", defaultHistoryRecords, "maximum number of listed transactions")
+
+	historyCmd.InitDefaultHelpCmd()
+	return historyCmd
+}
+
+func runHistoryCmd(_ *cobra.Command, _ []string) {
+	glb.InitLedgerFromNode()
+
+	var addr ledger.AddressED25519
+	var err error
+	if historyAccount != "" {
+		addr, err = ledger.AddressED25519FromSource(historyAccount)
+		glb.AssertNoError(err)
+	} else {
+		addr = glb.GetWalletData().Account
+	}
+	glb.Infof("account: %s", addr.String())
+
+	res, err := glb.GetClient().GetAccountHistory(addr, base.Slot(historySince), historyMax)
+	glb.AssertNoError(err)
+	glb.PrintResult(res)
+
+	lrbid, err := base.TransactionIDFromHexString(res.LRBID)
+	glb.AssertNoError(err)
+	glb.PrintLRB(&lrbid)
+
+	if len(res.Transactions) == 0 {
+		glb.Infof("no transactions found since slot %d", res.SinceSlot)
+		return
+	}
+	if res.Truncated {
+		glb.Infof("latest %d transactions since slot %d:", len(res.Transactions), res.SinceSlot)
+	} else {
+		glb.Infof("%d transactions since slot %d:", len(res.Transactions), res.SinceSlot)
+	}
+	for i := range res.Transactions {
+		displayAccountTx(&res.Transactions[i])
+	}
+}
+
+func displayAccountTx(rec *api.AccountTx) {
+	txid, err := base.TransactionIDFromHexString(rec.TxID)
+	glb.AssertNoError(err)
+
+	var counterparty string
+	switch len(rec.Counterparties) {
+	case 0:
+	case 1:
+		counterparty = rec.Counterparties[0]
+	default:
+		counterparty = fmt.Sprintf("%s and %d more", rec.Counterparties[0], len(rec.Counterparties)-1)
+	}
+	switch rec.Direction {
+	case api.AccountTxIn:
+		glb.Infof("  %s %-4s %s from %s (%s)", txid.String(), rec.Direction, util.Th(rec.Amount), counterparty, rec.Status)
+	case api.AccountTxOut:
+		glb.Infof("  %s %-4s %s to %s, fee %s (%s)", txid.String(), rec.Direction, util.Th(rec.Amount), counterparty, util.Th(rec.Fee), rec.Status)
+	default:
+		glb.Infof("  %s %-4s %s, fee %s (%s)", txid.String(), rec.Direction, util.Th(rec.Amount), util.Th(rec.Fee), rec.Status)
+	}
+	if glb.IsVerbose() {
+		for _, c := range rec.Counterparties[min(1, len(rec.Counterparties)):] {
+			glb.Infof("         %s", c)
+		}
+	}
+}
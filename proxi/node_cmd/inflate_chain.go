@@ -126,5 +126,10 @@ func inflateChain(chainTransitionPeriodSlots base.Slot, chainId base.ChainID) {
 		}
 		tsOut = chainOutput.Timestamp().AddSlots(chainTransitionPeriodSlots)
 		glb.Infof("amount on chain: %s", util.Th(chainOutput.Output.Amount()))
+		// one line of JSON per loop
+		glb.PrintResult(&chainTxResult{
+			TxResult: glb.NewTxResult(txid, chainOutput.ID.TransactionID() == txid),
+			ChainID:  chainId.StringHex(),
+		})
 	}
 }
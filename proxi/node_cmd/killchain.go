@@ -5,7 +5,6 @@ import (
 	"crypto/ed25519"
 	"errors"
 	"fmt"
-	"os"
 	"sync"
 	"time"
 
@@ -37,7 +36,11 @@ func runKillChainCmd(_ *cobra.Command, args []string) {
 
 	chainID, err := base.ChainIDFromHexString(args[0])
 	glb.AssertNoError(err)
+	endChain(chainID)
+}
 
+// endChain destroys the chain output in the nearest closed delegation slot of the chain
+func endChain(chainID base.ChainID) {
 	walletData := glb.GetWalletData()
 
 	var tagAlongSeqID base.ChainID
@@ -58,8 +61,7 @@ func runKillChainCmd(_ *cobra.Command, args []string) {
 
 	prompt := fmt.Sprintf("discontinue chain %s?", chainID.String())
 	if !glb.YesNoPrompt(prompt, true, glb.BypassYesNoPrompt()) {
-		glb.Infof("exit")
-		os.Exit(0)
+		glb.Abort()
 	}
 	var wg sync.WaitGroup
 	ctx, cancel := context.WithCancel(context.Background())
@@ -73,8 +75,9 @@ func runKillChainCmd(_ *cobra.Command, args []string) {
 
 	time.Sleep(500 * time.Millisecond)
 
+	var submitted []string
 	go func() {
-		makeTransactionLoop(killChainParams{
+		submitted = makeTransactionLoop(killChainParams{
 			chainID:       chainID,
 			privateKey:    walletData.PrivateKey,
 			tagAlongSeqID: tagAlongSeqID,
@@ -86,6 +89,17 @@ func runKillChainCmd(_ *cobra.Command, args []string) {
 		wg.Done()
 	}()
 	wg.Wait()
+
+	glb.PrintResult(&killChainResult{
+		ChainID:   chainID.StringHex(),
+		Submitted: submitted,
+	})
+}
+
+// killChainResult contains all transactions submitted to end the chain. Only one of them is included
+type killChainResult struct {
+	ChainID   string   `json:"chain_id"`
+	Submitted []string `json:"submitted"`
 }
 
 type killChainParams struct {
@@ -125,10 +139,12 @@ func checkChainLoop(chainID base.ChainID, repeatPeriod time.Duration, ctx contex
 
 // makeTransactionLoop periodically issues new killchain transaction for each new LRB which has new delegation output
 // the transaction's timestamp is at the nearest liquidity window timestamp.
-// Multiple transactions are issued until one succeeds. The rest are double-spends and are orphaned
-func makeTransactionLoop(par killChainParams) {
+// Multiple transactions are issued until one succeeds. The rest are double-spends and are orphaned.
+// Returns IDs of submitted transactions
+func makeTransactionLoop(par killChainParams) []string {
 	clnt := glb.GetClient()
 	consumedOutputs := set.New[base.OutputID]()
+	submitted := make([]string, 0)
 
 	attempt := 1
 	for {
@@ -138,7 +154,7 @@ func makeTransactionLoop(par killChainParams) {
 			glb.AssertNoError(err)
 			glb.Infof("chain %s not found. LRB (latest reliable branch) is %s (%d slots behind from now)",
 				par.chainID.StringShort(), lrbid.String(), ledger.TimeNow().Slot-lrbid.Slot())
-			return
+			return submitted
 		}
 		glb.AssertNoError(err)
 
@@ -168,15 +184,15 @@ func makeTransactionLoop(par killChainParams) {
 				attempt, tx.IDString(), lrbBehindTicks, lrbBehindTicks/256, time.Duration(lrbBehindTicks)*ledger.TickDuration())
 			glb.Verbosef("-------------- transaction --------------\n%s", tx.String())
 
+			submitted = append(submitted, tx.IDStringHex())
 			consumedOutputs.Insert(o.ID)
 			attempt++
 		}
 
 		select {
 		case <-par.ctx.Done():
-			return
+			return submitted
 		case <-time.After(par.repeatPeriod):
 		}
 	}
-
 }
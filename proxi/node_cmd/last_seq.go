@@ -49,4 +49,5 @@ func runLastMilestonesCmd(_ *cobra.Command, _ []string) {
 			glb.Infof("    %s    %s   %5d  %5d", chainID.String(), txid.String(), sd.MilestoneCount, activity)
 		}
 	}
+	glb.PrintResult(lastSeq)
 }
@@ -0,0 +1,229 @@
+package loadtest
+
+import (
+	"crypto/ed25519"
+	"encoding/binary"
+	"fmt"
+	"time"
+
+	"github.com/lunfardo314/proxima/ledger"
+	"github.com/lunfardo314/proxima/ledger/base"
+	"github.com/lunfardo314/proxima/ledger/transaction"
+	"github.com/lunfardo314/proxima/ledger/txbuilder"
+	"golang.org/x/crypto/blake2b"
+)
+
+// account is a source of the transactions. It keeps outputs produced by its own transactions,
+// so it can issue the next transaction without waiting for the previous one to be included into the ledger state.
+// When the chain of transactions breaks (rejection, timeout, not enough tokens) the account is synced with the
+// latest reliable branch after all its pending transactions are settled
+type account struct {
+	idx        int
+	privateKey ed25519.PrivateKey
+	addr       ledger.AddressED25519
+	// known transferable outputs, not consumed by the submitted transactions
+	outs []*ledger.OutputWithID
+	// known chain output, if any
+	chain *ledger.OutputWithChainID
+	// number of submitted transactions not yet settled
+	pending  int
+	needSync bool
+	lastSync time.Time
+}
+
+const (
+	// maximum number of inputs consumed by one transaction
+	maxInputs = 100
+	// remainder left on the account must not be smaller than that
+	minRemainder = 1000
+)
+
+// deriveAccountKey deterministically derives private key of the generated account from the wallet key and the seed
+func deriveAccountKey(walletKey ed25519.PrivateKey, seed string, idx int) ed25519.PrivateKey {
+	var idxBin [4]byte
+	binary.BigEndian.PutUint32(idxBin[:], uint32(idx))
+	h, _ := blake2b.New256(nil)
+	h.Write(walletKey)
+	h.Write([]byte("loadtest"))
+	h.Write([]byte(seed))
+	h.Write(idxBin[:])
+	return ed25519.NewKeyFromSeed(h.Sum(nil))
+}
+
+func newAccount(idx int, privateKey ed25519.PrivateKey) *account {
+	return &account{
+		idx:        idx,
+		privateKey: privateKey,
+		addr:       ledger.AddressED25519FromPrivateKey(privateKey),
+		needSync:   true,
+	}
+}
+
+func (a *account) balance() (ret uint64) {
+	for _, o := range a.outs {
+		ret += o.Output.Amount()
+	}
+	return
+}
+
+// nextTimestamp returns the earliest timestamp of the transaction consuming outputs
+func nextTimestamp(outs ...*ledger.OutputWithID) base.LedgerTime {
+	ret := ledger.TimeNow()
+	for _, o := range outs {
+		ret = base.MaximumTime(ret, o.Timestamp().AddTicks(ledger.TransactionPace()))
+	}
+	if ret.IsSlotBoundary() {
+		ret = ret.AddTicks(1)
+	}
+	return ret
+}
+
+// selectInputs returns prefix of known outputs which covers the amount
+func (a *account) selectInputs(amount uint64) ([]*ledger.OutputWithID, bool) {
+	sum := uint64(0)
+	for i, o := range a.outs {
+		if i >= maxInputs {
+			break
+		}
+		sum += o.Output.Amount()
+		if sum >= amount {
+			return a.outs[:i+1], true
+		}
+	}
+	return nil, false
+}
+
+// canTransitChain returns true if the known chain output can pay the fee
+func (a *account) canTransitChain(fee uint64) bool {
+	return a.chain != nil && a.chain.Output.Amount() > fee+minRemainder
+}
+
+// readyAt returns the earliest timestamp of the transaction spending the amount from the account
+func (a *account) readyAt(kind string, amount, fee uint64) (base.LedgerTime, bool) {
+	if kind == KindChain && a.canTransitChain(fee) {
+		return nextTimestamp(&a.chain.OutputWithID), true
+	}
+	ins, ok := a.selectInputs(amount + fee + minRemainder)
+	if !ok {
+		return base.LedgerTime{}, false
+	}
+	return nextTimestamp(ins...), true
+}
+
+// makeTx makes transaction which consumes known outputs and produces the main output with the amount, tag-along output
+// and remainder. The known outputs are updated
+func (a *account) makeTx(amount uint64, mainOutput func(ts base.LedgerTime) *ledger.Output, tagAlongSeqID base.ChainID, fee uint64) ([]byte, error) {
+	ins, ok := a.selectInputs(amount + fee + minRemainder)
+	if !ok {
+		return nil, fmt.Errorf("not enough tokens on account #%d", a.idx)
+	}
+	ts := nextTimestamp(ins...)
+	main := mainOutput(ts)
+	txb := txbuilder.New()
+	inTotal, _, err := txb.ConsumeOutputs(ins...)
+	if err != nil {
+		return nil, err
+	}
+	if err = txb.PutStandardInputUnlocks(len(ins)); err != nil {
+		return nil, err
+	}
+	if _, err = txb.ProduceOutput(main); err != nil {
+		return nil, err
+	}
+	feeOut := ledger.NewOutput(func(o *ledger.OutputBuilder) {
+		o.WithAmount(fee).WithLock(ledger.ChainLockFromChainID(tagAlongSeqID))
+	})
+	if _, err = txb.ProduceOutput(feeOut); err != nil {
+		return nil, err
+	}
+	remainder := ledger.NewOutput(func(o *ledger.OutputBuilder) {
+		o.WithAmount(inTotal - main.Amount() - fee).WithLock(a.addr)
+	})
+	if _, err = txb.ProduceOutput(remainder); err != nil {
+		return nil, err
+	}
+	txb.TransactionData.Timestamp = ts
+	txb.TransactionData.InputCommitment = ledger.HashOutputs(txb.ConsumedOutputs...)
+	txb.SignED25519(a.privateKey)
+
+	txBytes := txb.TransactionData.Bytes()
+	produced, err := transaction.OutputsWithIDFromTransactionBytes(txBytes)
+	if err != nil {
+		return nil, err
+	}
+	a.outs = a.outs[len(ins):]
+	for _, o := range produced {
+		if o.Output.NumConstraints() == 2 && ledger.EqualConstraints(o.Output.Lock(), a.addr) {
+			a.outs = append(a.outs, o)
+		}
+	}
+	return txBytes, nil
+}
+
+func (a *account) makeTransfer(target ledger.Lock, amount uint64, tagAlongSeqID base.ChainID, fee uint64) ([]byte, error) {
+	return a.makeTx(amount, func(_ base.LedgerTime) *ledger.Output {
+		return ledger.NewOutput(func(o *ledger.OutputBuilder) {
+			o.WithAmount(amount).WithLock(target)
+		})
+	}, tagAlongSeqID, fee)
+}
+
+func (a *account) makeDelegation(seqID base.ChainID, amount uint64, tagAlongSeqID base.ChainID, fee uint64) ([]byte, error) {
+	return a.makeTx(amount, func(ts base.LedgerTime) *ledger.Output {
+		return ledger.NewOutput(func(o *ledger.OutputBuilder) {
+			o.WithAmount(amount)
+			o.WithLock(ledger.NewDelegationLock(a.addr, ledger.ChainLockFromChainID(seqID), 2, ts, amount))
+			o.MustPushConstraint(ledger.NewChainOrigin().Bytes())
+		})
+	}, tagAlongSeqID, fee)
+}
+
+// makeChainTx transits the chain of the account paying fee from the chain. If there is no chain or the chain can't
+// pay the fee, new chain origin is created
+func (a *account) makeChainTx(amount uint64, tagAlongSeqID base.ChainID, fee uint64) ([]byte, error) {
+	if a.canTransitChain(fee) {
+		txBytes, _, _, err := txbuilder.MakeChainSuccessorTransaction(&txbuilder.MakeChainSuccTransactionParams{
+			ChainInput:     a.chain,
+			Timestamp:      nextTimestamp(&a.chain.OutputWithID),
+			WithdrawTarget: ledger.ChainLockFromChainID(tagAlongSeqID),
+			WithdrawAmount: fee,
+			PrivateKey:     a.privateKey,
+		})
+		if err != nil {
+			return nil, err
+		}
+		if a.chain, err = findChainOutput(txBytes, a.chain.ChainID); err != nil {
+			return nil, err
+		}
+		return txBytes, nil
+	}
+	// the exhausted chain, if any, is abandoned
+	txBytes, err := a.makeTx(amount, func(_ base.LedgerTime) *ledger.Output {
+		return ledger.NewOutput(func(o *ledger.OutputBuilder) {
+			o.WithAmount(amount).WithLock(a.addr)
+			o.MustPushConstraint(ledger.NewChainOrigin().Bytes())
+		})
+	}, tagAlongSeqID, fee)
+	if err != nil {
+		return nil, err
+	}
+	o, err := transaction.OutputWithIDFromTransactionBytes(txBytes, 0)
+	if err != nil {
+		return nil, err
+	}
+	a.chain, err = o.AsChainOutput()
+	return txBytes, err
+}
+
+func findChainOutput(txBytes []byte, chainID base.ChainID) (*ledger.OutputWithChainID, error) {
+	outs, err := transaction.OutputsWithIDFromTransactionBytes(txBytes)
+	if err != nil {
+		return nil, err
+	}
+	for _, o := range outs {
+		if id, _, ok := o.ExtractChainID(); ok && id == chainID {
+			return o.AsChainOutput()
+		}
+	}
+	return nil, fmt.Errorf("chain output %s not found in the transaction", chainID.StringShort())
+}
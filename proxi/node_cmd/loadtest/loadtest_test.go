@@ -0,0 +1,126 @@
+package loadtest
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+const testScenario = `
+name: test
+accounts: 10
+funding_amount: 1000000
+mix:
+    transfer: 3
+    chain: 1
+chain:
+    amount: 100000
+ramp:
+    - tps: 2
+      to_tps: 6
+      duration: 10s
+    - tps: 6
+      duration: 5s
+`
+
+func TestScenario(t *testing.T) {
+	t.Run("parse", func(t *testing.T) {
+		sc, err := ParseScenario([]byte(testScenario))
+		require.NoError(t, err)
+		require.Equal(t, "test", sc.Name)
+		require.Equal(t, 10, sc.Accounts)
+		require.EqualValues(t, defaultTransferAmount, sc.Transfer.Amount)
+		require.Equal(t, defaultFinalitySlots, sc.FinalitySlots)
+		require.Equal(t, []string{KindTransfer, KindChain}, sc.Kinds())
+		require.Equal(t, 15*time.Second, sc.Duration())
+		require.EqualValues(t, 6, sc.Ramp[1].ToTPS)
+	})
+	t.Run("errors", func(t *testing.T) {
+		_, err := ParseScenario([]byte("name: x\nunknown_field: 1\nramp:\n  - tps: 1\n"))
+		require.Error(t, err)
+		_, err = ParseScenario([]byte("name: x\n"))
+		require.Error(t, err)
+		_, err = ParseScenario([]byte("accounts: 5\nramp:\n  - tps: 1\n"))
+		require.Error(t, err)
+		_, err = ParseScenario([]byte("ramp:\n  - tps: 1\n  - tps: 2\n    duration: 1m\n"))
+		require.Error(t, err)
+		_, err = ParseScenario([]byte("ramp:\n  - tps: 1\n    duration: xyz\n"))
+		require.Error(t, err)
+		_, err = ParseScenario([]byte("mix:\n  delegation: 1\nramp:\n  - tps: 1\n"))
+		require.Error(t, err)
+	})
+	t.Run("unlimited", func(t *testing.T) {
+		sc, err := ParseScenario([]byte("ramp:\n  - tps: 1\n    duration: 1m\n  - tps: 4\n"))
+		require.NoError(t, err)
+		require.EqualValues(t, 0, sc.Duration())
+		require.EqualValues(t, 4, sc.TPSAt(time.Hour))
+		require.Equal(t, 60+4*60, sc.Due(2*time.Minute))
+		// transfers by default
+		require.Equal(t, []string{KindTransfer}, sc.Kinds())
+	})
+	t.Run("pick kind", func(t *testing.T) {
+		sc, err := ParseScenario([]byte(testScenario))
+		require.NoError(t, err)
+		require.Equal(t, KindTransfer, sc.PickKind(0))
+		require.Equal(t, KindTransfer, sc.PickKind(0.74))
+		require.Equal(t, KindChain, sc.PickKind(0.75))
+		require.Equal(t, KindChain, sc.PickKind(0.999))
+	})
+	t.Run("rand seed", func(t *testing.T) {
+		sc1, err := ParseScenario([]byte(testScenario + "seed: abc\n"))
+		require.NoError(t, err)
+		sc2, err := ParseScenario([]byte(testScenario + "seed: abc\n"))
+		require.NoError(t, err)
+		sc3, err := ParseScenario([]byte(testScenario + "seed: xyz\n"))
+		require.NoError(t, err)
+		require.Equal(t, sc1.RandSeed(), sc2.RandSeed())
+		require.NotEqual(t, sc1.RandSeed(), sc3.RandSeed())
+	})
+}
+
+func TestRamp(t *testing.T) {
+	sc, err := ParseScenario([]byte(testScenario))
+	require.NoError(t, err)
+
+	require.EqualValues(t, 2, sc.TPSAt(0))
+	require.EqualValues(t, 4, sc.TPSAt(5*time.Second))
+	require.EqualValues(t, 6, sc.TPSAt(12*time.Second))
+	require.EqualValues(t, 0, sc.TPSAt(15*time.Second))
+
+	require.Equal(t, 0, sc.Due(0))
+	// 2*5 + 4*5*5/(2*10)
+	require.Equal(t, 15, sc.Due(5*time.Second))
+	// (2+6)/2*10
+	require.Equal(t, 40, sc.Due(10*time.Second))
+	require.Equal(t, 70, sc.Due(15*time.Second))
+	require.Equal(t, 70, sc.Due(time.Hour))
+
+	prev := 0
+	for d := time.Duration(0); d < 20*time.Second; d += 100 * time.Millisecond {
+		due := sc.Due(d)
+		require.GreaterOrEqual(t, due, prev)
+		prev = due
+	}
+}
+
+func TestLatencyStats(t *testing.T) {
+	require.EqualValues(t, LatencyStats{}, latencyStats(nil))
+
+	latencies := make([]time.Duration, 0, 100)
+	for i := 100; i > 0; i-- {
+		latencies = append(latencies, time.Duration(i)*time.Millisecond)
+	}
+	st := latencyStats(latencies)
+	require.EqualValues(t, 1, st.Min)
+	require.EqualValues(t, 100, st.Max)
+	require.EqualValues(t, 50, st.Mean)
+	require.EqualValues(t, 50, st.P50)
+	require.EqualValues(t, 90, st.P90)
+	require.EqualValues(t, 99, st.P99)
+	// input is not modified
+	require.EqualValues(t, 100*time.Millisecond, latencies[0])
+
+	st = latencyStats([]time.Duration{7 * time.Millisecond})
+	require.EqualValues(t, LatencyStats{Min: 7, Mean: 7, P50: 7, P90: 7, P99: 7, Max: 7}, st)
+}
@@ -0,0 +1,50 @@
+package loadtest
+
+import (
+	"math"
+	"time"
+)
+
+// Duration returns total duration of the ramp. 0 means unlimited
+func (s *Scenario) Duration() (ret time.Duration) {
+	for _, st := range s.Ramp {
+		if st.duration == 0 {
+			return 0
+		}
+		ret += st.duration
+	}
+	return
+}
+
+// TPSAt returns target TPS at the moment since the beginning of the test. 0 after the end of the ramp
+func (s *Scenario) TPSAt(elapsed time.Duration) float64 {
+	for _, st := range s.Ramp {
+		if st.duration == 0 {
+			return st.TPS
+		}
+		if elapsed < st.duration {
+			return st.TPS + (st.ToTPS-st.TPS)*elapsed.Seconds()/st.duration.Seconds()
+		}
+		elapsed -= st.duration
+	}
+	return 0
+}
+
+// Due returns number of transactions which must be submitted from the beginning of the test
+// until the moment, i.e. integral of the target TPS
+func (s *Scenario) Due(elapsed time.Duration) int {
+	ret := 0.0
+	for _, st := range s.Ramp {
+		if st.duration == 0 {
+			ret += st.TPS * elapsed.Seconds()
+			break
+		}
+		d := min(elapsed, st.duration).Seconds()
+		ret += st.TPS*d + (st.ToTPS-st.TPS)*d*d/(2*st.duration.Seconds())
+		elapsed -= st.duration
+		if elapsed <= 0 {
+			break
+		}
+	}
+	return int(math.Floor(ret + 1e-9))
+}
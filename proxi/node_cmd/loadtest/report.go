@@ -0,0 +1,127 @@
+package loadtest
+
+import (
+	"encoding/json"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/lunfardo314/proxima/util"
+	"github.com/lunfardo314/proxima/util/lines"
+)
+
+type (
+	// Report is the result of the load test. It is stable JSON, intended for comparison of runs in CI
+	Report struct {
+		Scenario    string    `json:"scenario"`
+		Start       time.Time `json:"start"`
+		DurationSec float64   `json:"duration_sec"`
+		Accounts    int       `json:"accounts"`
+		Counters
+		// average target TPS of the ramp during the test
+		TargetTPS    float64      `json:"target_tps"`
+		SubmittedTPS float64      `json:"submitted_tps"`
+		IncludedTPS  float64      `json:"included_tps"`
+		LatencyMs    LatencyStats `json:"latency_ms"`
+		// fees paid by transactions included into the latest reliable branch
+		FeesPaid uint64 `json:"fees_paid"`
+		// fees of all submitted transactions
+		FeesSubmitted    uint64               `json:"fees_submitted"`
+		ByKind           map[string]*Counters `json:"by_kind"`
+		RejectionReasons map[string]int       `json:"rejection_reasons,omitempty"`
+		// first rejected transactions, at most maxRejectionsInReport
+		Rejections []Rejection `json:"rejections,omitempty"`
+	}
+
+	Counters struct {
+		Submitted int `json:"submitted"`
+		Included  int `json:"included"`
+		Rejected  int `json:"rejected"`
+		TimedOut  int `json:"timed_out"`
+		// transactions refused by the API or not submitted because of an error
+		SubmitErrors int `json:"submit_errors"`
+	}
+
+	// LatencyStats is time from submit until the transaction is seen in the latest reliable branch
+	LatencyStats struct {
+		Min  int64 `json:"min"`
+		Mean int64 `json:"mean"`
+		P50  int64 `json:"p50"`
+		P90  int64 `json:"p90"`
+		P99  int64 `json:"p99"`
+		Max  int64 `json:"max"`
+	}
+
+	Rejection struct {
+		TxID   string `json:"txid"`
+		Kind   string `json:"kind"`
+		Reason string `json:"reason"`
+	}
+)
+
+const maxRejectionsInReport = 100
+
+// latencyStats calculates statistics of latencies with nearest-rank percentiles
+func latencyStats(latencies []time.Duration) (ret LatencyStats) {
+	if len(latencies) == 0 {
+		return
+	}
+	sorted := make([]time.Duration, len(latencies))
+	copy(sorted, latencies)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i] < sorted[j]
+	})
+	var sum time.Duration
+	for _, l := range sorted {
+		sum += l
+	}
+	ret.Min = sorted[0].Milliseconds()
+	ret.Max = sorted[len(sorted)-1].Milliseconds()
+	ret.Mean = (sum / time.Duration(len(sorted))).Milliseconds()
+	ret.P50 = percentile(sorted, 50).Milliseconds()
+	ret.P90 = percentile(sorted, 90).Milliseconds()
+	ret.P99 = percentile(sorted, 99).Milliseconds()
+	return
+}
+
+// percentile of the sorted non-empty slice
+func percentile(sorted []time.Duration, p int) time.Duration {
+	rank := (p*len(sorted) + 99) / 100
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
+
+func (r *Report) JSON() []byte {
+	ret, err := json.MarshalIndent(r, "", "  ")
+	util.AssertNoError(err)
+	return ret
+}
+
+func (r *Report) Save(fname string) error {
+	return os.WriteFile(fname, r.JSON(), 0644)
+}
+
+func (r *Report) Lines(prefix ...string) *lines.Lines {
+	ret := lines.New(prefix...)
+	ret.Add("scenario: %s, accounts: %d, duration: %.1f sec", r.Scenario, r.Accounts, r.DurationSec).
+		Add("submitted: %d, included: %d, rejected: %d, timed out: %d, submit errors: %d",
+			r.Submitted, r.Included, r.Rejected, r.TimedOut, r.SubmitErrors).
+		Add("TPS: target %.2f, submitted %.2f, included %.2f", r.TargetTPS, r.SubmittedTPS, r.IncludedTPS).
+		Add("latency ms: min %d, mean %d, p50 %d, p90 %d, p99 %d, max %d",
+			r.LatencyMs.Min, r.LatencyMs.Mean, r.LatencyMs.P50, r.LatencyMs.P90, r.LatencyMs.P99, r.LatencyMs.Max).
+		Add("fees paid: %s, fees submitted: %s", util.Th(r.FeesPaid), util.Th(r.FeesSubmitted))
+	for _, kind := range util.KeysSorted(r.ByKind, func(k1, k2 string) bool { return k1 < k2 }) {
+		c := r.ByKind[kind]
+		ret.Add("   %s: submitted %d, included %d, rejected %d, timed out %d, submit errors %d",
+			kind, c.Submitted, c.Included, c.Rejected, c.TimedOut, c.SubmitErrors)
+	}
+	if len(r.RejectionReasons) > 0 {
+		ret.Add("rejection reasons:")
+		for _, reason := range util.KeysSorted(r.RejectionReasons, func(k1, k2 string) bool { return k1 < k2 }) {
+			ret.Add("   %d: %s", r.RejectionReasons[reason], reason)
+		}
+	}
+	return ret
+}
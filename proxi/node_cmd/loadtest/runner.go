@@ -0,0 +1,546 @@
+package loadtest
+
+import (
+	"context"
+	"fmt"
+	"math/rand"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/lunfardo314/proxima/api/client"
+	"github.com/lunfardo314/proxima/ledger"
+	"github.com/lunfardo314/proxima/ledger/base"
+	"github.com/lunfardo314/proxima/ledger/transaction"
+	"github.com/lunfardo314/proxima/ledger/txbuilder"
+	"github.com/lunfardo314/proxima/proxi/glb"
+	"github.com/lunfardo314/proxima/util"
+)
+
+type (
+	// Runner submits transactions according to the scenario and tracks them until they reach
+	// the latest reliable branch (LRB), are rejected by the node or time out
+	Runner struct {
+		sc              *Scenario
+		clnt            *client.APIClient
+		wallet          glb.WalletData
+		tagAlongSeqID   base.ChainID
+		delegationSeqID base.ChainID
+		// nil means round-robin among generated accounts
+		transferTarget ledger.Lock
+		rnd            *rand.Rand
+
+		mutex     sync.Mutex
+		accounts  []*account
+		nextAcc   int
+		attempted int
+		// not settled transactions in the order of submission
+		pending   []*txRecord
+		latencies []time.Duration
+		report    Report
+	}
+
+	txRecord struct {
+		txid      base.TransactionID
+		kind      string
+		acc       *account
+		fee       uint64
+		submitted time.Time
+	}
+)
+
+const (
+	loopPeriod        = 20 * time.Millisecond
+	trackPeriod       = time.Second
+	progressPeriod    = 10 * time.Second
+	maxChecksPerRound = 200
+	// number of latest rejections fetched from the node in one round
+	rejectionsPerRound = 1000
+	// number of outputs funded by one funding transaction
+	fundingChunk = 100
+	// account is synced with the LRB not more often
+	syncPeriod = 2 * time.Second
+)
+
+// NewRunner creates runner of the scenario. Tag-along sequencer is used when it is not specified in the scenario
+func NewRunner(sc *Scenario, clnt *client.APIClient, wallet glb.WalletData, tagAlongSeqID base.ChainID) (*Runner, error) {
+	ret := &Runner{
+		sc:              sc,
+		clnt:            clnt,
+		wallet:          wallet,
+		tagAlongSeqID:   tagAlongSeqID,
+		delegationSeqID: tagAlongSeqID,
+		rnd:             rand.New(rand.NewSource(sc.RandSeed())),
+	}
+	if sc.TagAlong.Fee == 0 {
+		return nil, fmt.Errorf("tag-along fee must be specified")
+	}
+	if sc.Mix.Chain > 0 && sc.Chain.Amount <= sc.TagAlong.Fee+minRemainder {
+		return nil, fmt.Errorf("chain amount must be bigger than tag-along fee + %d", minRemainder)
+	}
+	var err error
+	if sc.TagAlong.SequencerID != "" {
+		if ret.tagAlongSeqID, err = base.ChainIDFromHexString(sc.TagAlong.SequencerID); err != nil {
+			return nil, fmt.Errorf("wrong tag-along sequencer ID: %w", err)
+		}
+		ret.delegationSeqID = ret.tagAlongSeqID
+	}
+	if sc.Delegation.SequencerID != "" {
+		if ret.delegationSeqID, err = base.ChainIDFromHexString(sc.Delegation.SequencerID); err != nil {
+			return nil, fmt.Errorf("wrong delegation sequencer ID: %w", err)
+		}
+	}
+	if sc.Mix.Delegation > 0 && sc.Delegation.Amount < ledger.MinimumDelegationAmount(ledger.TimeNow().Slot) {
+		return nil, fmt.Errorf("delegation amount must be >= %d", ledger.MinimumDelegationAmount(ledger.TimeNow().Slot))
+	}
+	if sc.Transfer.Target != "" {
+		target, err := ledger.AccountableFromSource(sc.Transfer.Target)
+		if err != nil {
+			return nil, fmt.Errorf("wrong transfer target: %w", err)
+		}
+		ret.transferTarget = target.AsLock()
+	}
+	if sc.Accounts == 0 {
+		ret.accounts = []*account{newAccount(0, wallet.PrivateKey)}
+	} else {
+		ret.accounts = make([]*account, sc.Accounts)
+		for i := range ret.accounts {
+			ret.accounts[i] = newAccount(i, deriveAccountKey(wallet.PrivateKey, sc.Seed, i))
+		}
+	}
+	ret.report = Report{
+		Scenario: sc.Name,
+		Accounts: len(ret.accounts),
+		ByKind:   make(map[string]*Counters),
+	}
+	for _, kind := range sc.Kinds() {
+		ret.report.ByKind[kind] = &Counters{}
+	}
+	return ret, nil
+}
+
+// Run funds accounts, runs the load and waits until submitted transactions are settled.
+// Cancelling the context stops the load, the report is still produced
+func (r *Runner) Run(ctx context.Context) (*Report, error) {
+	if r.sc.Accounts > 0 {
+		if err := r.fundAccounts(ctx); err != nil {
+			return nil, err
+		}
+	}
+	trackerCtx, stopTracker := context.WithCancel(context.Background())
+	trackerDone := make(chan struct{})
+	go func() {
+		r.trackLoop(trackerCtx)
+		close(trackerDone)
+	}()
+
+	start := time.Now()
+	r.report.Start = start
+	glb.Infof("load test '%s' started with %d accounts", r.sc.Name, len(r.accounts))
+
+	loadDuration := r.runLoad(ctx, start)
+	glb.Infof("load stopped after %v. Waiting for the pending transactions to settle..", loadDuration.Truncate(time.Second))
+	r.waitSettled(ctx)
+	stopTracker()
+	<-trackerDone
+
+	return r.makeReport(loadDuration), nil
+}
+
+func (r *Runner) runLoad(ctx context.Context, start time.Time) time.Duration {
+	totalDuration := r.sc.Duration()
+	lastProgress := start
+	for {
+		select {
+		case <-ctx.Done():
+			return time.Since(start)
+		case <-time.After(loopPeriod):
+		}
+		elapsed := time.Since(start)
+		if totalDuration > 0 && elapsed >= totalDuration {
+			return elapsed
+		}
+		due := r.sc.Due(elapsed)
+		if r.sc.MaxTransactions > 0 {
+			due = min(due, r.sc.MaxTransactions)
+		}
+		r.mutex.Lock()
+		for r.attempted < due && r.submitNext() {
+		}
+		limitReached := r.sc.MaxTransactions > 0 && r.attempted >= r.sc.MaxTransactions
+		if time.Since(lastProgress) >= progressPeriod {
+			glb.Infof("%v: target TPS %.2f, submitted %d, included %d, rejected %d, timed out %d, pending %d, behind schedule %d",
+				elapsed.Truncate(time.Second), r.sc.TPSAt(elapsed), r.report.Submitted, r.report.Included, r.report.Rejected,
+				r.report.TimedOut, len(r.pending), due-r.attempted)
+			lastProgress = time.Now()
+		}
+		r.mutex.Unlock()
+
+		if limitReached {
+			return time.Since(start)
+		}
+	}
+}
+
+// submitNext makes and submits the next transaction from the first available account.
+// Returns false if no account is ready. Must be called with locked mutex
+func (r *Runner) submitNext() bool {
+	kind := r.sc.PickKind(r.rnd.Float64())
+	for i := 0; i < len(r.accounts); i++ {
+		acc := r.accounts[(r.nextAcc+i)%len(r.accounts)]
+		if !r.accountReady(acc, kind) {
+			continue
+		}
+		r.nextAcc = (acc.idx + 1) % len(r.accounts)
+		r.submit(acc, kind)
+		return true
+	}
+	return false
+}
+
+// accountReady checks if account can issue the transaction of the kind now. Syncs the account if necessary
+func (r *Runner) accountReady(acc *account, kind string) bool {
+	if acc.needSync {
+		if acc.pending > 0 || time.Since(acc.lastSync) < syncPeriod {
+			return false
+		}
+		if err := r.syncAccount(acc); err != nil {
+			glb.Verbosef("failed to sync account #%d: %v", acc.idx, err)
+			return false
+		}
+	}
+	ts, ok := acc.readyAt(kind, r.amount(acc, kind), r.sc.TagAlong.Fee)
+	if !ok {
+		if acc.pending == 0 {
+			// maybe more tokens are available in the ledger state
+			acc.needSync = true
+		}
+		return false
+	}
+	// account can't go ahead of the clock more than transaction pace
+	return !ledger.ClockTime(ts).After(time.Now().Add(time.Duration(ledger.TransactionPace()) * ledger.TickDuration()))
+}
+
+func (r *Runner) amount(acc *account, kind string) uint64 {
+	switch kind {
+	case KindChain:
+		if acc.canTransitChain(r.sc.TagAlong.Fee) {
+			return 0
+		}
+		return r.sc.Chain.Amount
+	case KindDelegation:
+		return r.sc.Delegation.Amount
+	default:
+		return r.sc.Transfer.Amount
+	}
+}
+
+func (r *Runner) submit(acc *account, kind string) {
+	r.attempted++
+	fee := r.sc.TagAlong.Fee
+
+	var txBytes []byte
+	var err error
+	switch kind {
+	case KindTransfer:
+		txBytes, err = acc.makeTransfer(r.transferTargetFor(acc), r.sc.Transfer.Amount, r.tagAlongSeqID, fee)
+	case KindChain:
+		txBytes, err = acc.makeChainTx(r.sc.Chain.Amount, r.tagAlongSeqID, fee)
+	case KindDelegation:
+		txBytes, err = acc.makeDelegation(r.delegationSeqID, r.sc.Delegation.Amount, r.tagAlongSeqID, fee)
+	}
+	if err == nil {
+		err = r.clnt.SubmitTransaction(txBytes)
+	}
+	if err != nil {
+		// local state of the account is not consistent anymore
+		acc.needSync = true
+		r.report.SubmitErrors++
+		r.report.ByKind[kind].SubmitErrors++
+		r.addRejection("", kind, "submit: "+err.Error())
+		glb.Verbosef("account #%d failed to submit %s transaction: %v", acc.idx, kind, err)
+		return
+	}
+	txid, err := transaction.IDFromParsedTransactionBytes(txBytes)
+	util.AssertNoError(err)
+
+	acc.pending++
+	r.pending = append(r.pending, &txRecord{
+		txid:      txid,
+		kind:      kind,
+		acc:       acc,
+		fee:       fee,
+		submitted: time.Now(),
+	})
+	r.report.Submitted++
+	r.report.ByKind[kind].Submitted++
+	r.report.FeesSubmitted += fee
+	glb.Verbosef("account #%d submitted %s %s", acc.idx, kind, txid.StringShort())
+}
+
+func (r *Runner) transferTargetFor(acc *account) ledger.Lock {
+	switch {
+	case r.transferTarget != nil:
+		return r.transferTarget
+	case len(r.accounts) > 1:
+		return r.accounts[(acc.idx+1)%len(r.accounts)].addr
+	default:
+		return r.wallet.Account
+	}
+}
+
+// syncAccount reads outputs of the account from the LRB
+func (r *Runner) syncAccount(acc *account) error {
+	acc.lastSync = time.Now()
+	outs, _, _, err := r.clnt.GetTransferableOutputs(acc.addr, maxInputs)
+	if err != nil {
+		return err
+	}
+	acc.outs = outs
+	if acc.chain != nil {
+		chainOut, _, _, err := r.clnt.GetChainOutput(acc.chain.ChainID)
+		if err != nil {
+			// chain will be created anew
+			acc.chain = nil
+		} else {
+			acc.chain = chainOut
+		}
+	}
+	acc.needSync = false
+	return nil
+}
+
+func (r *Runner) addRejection(txid, kind, reason string) {
+	if r.report.RejectionReasons == nil {
+		r.report.RejectionReasons = make(map[string]int)
+	}
+	r.report.RejectionReasons[reason]++
+	if len(r.report.Rejections) < maxRejectionsInReport {
+		r.report.Rejections = append(r.report.Rejections, Rejection{TxID: txid, Kind: kind, Reason: reason})
+	}
+}
+
+func (r *Runner) finalityTimeout() time.Duration {
+	return time.Duration(r.sc.FinalitySlots) * ledger.SlotDuration()
+}
+
+func (r *Runner) trackLoop(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(trackPeriod):
+			r.trackRound()
+		}
+	}
+}
+
+// trackRound checks the oldest pending transactions and settles them
+func (r *Runner) trackRound() {
+	r.mutex.Lock()
+	toCheck := slices.Clone(r.pending[:min(len(r.pending), maxChecksPerRound)])
+	r.mutex.Unlock()
+
+	if len(toCheck) == 0 {
+		return
+	}
+	rejected := make(map[base.TransactionID]string)
+	if rej, err := r.clnt.GetLatestTxRejections(rejectionsPerRound); err == nil {
+		for _, rec := range rej.Rejections {
+			if txid, err1 := base.TransactionIDFromHexString(rec.TxID); err1 == nil {
+				rejected[txid] = rec.Reason
+			}
+		}
+	} else {
+		glb.Verbosef("failed to fetch rejections: %v", err)
+	}
+
+	settled := make(map[*txRecord]func())
+	for _, rec := range toCheck {
+		if reason, found := rejected[rec.txid]; found {
+			settled[rec] = func() {
+				r.report.Rejected++
+				r.report.ByKind[rec.kind].Rejected++
+				r.addRejection(rec.txid.StringHex(), rec.kind, reason)
+				rec.acc.needSync = true
+			}
+			continue
+		}
+		_, depth, err := r.clnt.CheckTransactionIDInLRB(rec.txid, 0)
+		switch {
+		case err == nil && depth >= 0:
+			latency := time.Since(rec.submitted)
+			settled[rec] = func() {
+				r.report.Included++
+				r.report.ByKind[rec.kind].Included++
+				r.report.FeesPaid += rec.fee
+				r.latencies = append(r.latencies, latency)
+			}
+		case time.Since(rec.submitted) > r.finalityTimeout():
+			settled[rec] = func() {
+				r.report.TimedOut++
+				r.report.ByKind[rec.kind].TimedOut++
+				rec.acc.needSync = true
+			}
+		}
+	}
+	if len(settled) == 0 {
+		return
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	for rec, settle := range settled {
+		settle()
+		rec.acc.pending--
+	}
+	r.pending = util.PurgeSlice(r.pending, func(rec *txRecord) bool {
+		_, found := settled[rec]
+		return !found
+	})
+}
+
+// waitSettled waits until all pending transactions are settled. Transactions time out, so it does not wait forever
+func (r *Runner) waitSettled(ctx context.Context) {
+	for {
+		r.mutex.Lock()
+		n := len(r.pending)
+		r.mutex.Unlock()
+		if n == 0 {
+			return
+		}
+		select {
+		case <-ctx.Done():
+			glb.Infof("interrupted. %d transactions remain pending", n)
+			return
+		case <-time.After(trackPeriod):
+		}
+	}
+}
+
+func (r *Runner) makeReport(loadDuration time.Duration) *Report {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	ret := r.report
+	ret.DurationSec = loadDuration.Seconds()
+	if sec := loadDuration.Seconds(); sec > 0 {
+		ret.TargetTPS = float64(r.sc.Due(loadDuration)) / sec
+		ret.SubmittedTPS = float64(ret.Submitted) / sec
+		ret.IncludedTPS = float64(ret.Included) / sec
+	}
+	ret.LatencyMs = latencyStats(r.latencies)
+	return &ret
+}
+
+// fundAccounts transfers funding amount from the wallet to each generated account with the balance below half of it
+func (r *Runner) fundAccounts(ctx context.Context) error {
+	toFund := make([]*account, 0)
+	for _, acc := range r.accounts {
+		if err := r.syncAccount(acc); err != nil {
+			return err
+		}
+		if acc.balance() < r.sc.FundingAmount/2 {
+			toFund = append(toFund, acc)
+		}
+	}
+	if len(toFund) == 0 {
+		return nil
+	}
+	glb.Infof("funding %d accounts with %s each from the wallet %s", len(toFund), util.Th(r.sc.FundingAmount), r.wallet.Account.String())
+
+	for len(toFund) > 0 {
+		chunk := toFund[:min(len(toFund), fundingChunk)]
+		toFund = toFund[len(chunk):]
+
+		txid, err := r.submitFunding(chunk)
+		if err != nil {
+			return fmt.Errorf("funding failed: %w", err)
+		}
+		glb.Infof("funding transaction %s submitted for %d accounts", txid.StringShort(), len(chunk))
+		if err = r.waitInclusion(ctx, txid); err != nil {
+			return fmt.Errorf("funding failed: %w", err)
+		}
+	}
+	for _, acc := range r.accounts {
+		acc.needSync = true
+		acc.lastSync = time.Time{}
+	}
+	return nil
+}
+
+func (r *Runner) submitFunding(accounts []*account) (base.TransactionID, error) {
+	required := r.sc.FundingAmount*uint64(len(accounts)) + r.sc.TagAlong.Fee
+	walletOuts, _, _, err := r.clnt.GetTransferableOutputs(r.wallet.Account, maxInputs)
+	if err != nil {
+		return base.TransactionID{}, err
+	}
+	sum := uint64(0)
+	walletOuts = util.PurgeSlice(walletOuts, func(o *ledger.OutputWithID) bool {
+		if sum >= required {
+			return false
+		}
+		sum += o.Output.Amount()
+		return true
+	})
+	if sum < required {
+		return base.TransactionID{}, fmt.Errorf("not enough tokens in the wallet. Required %s, available in %d outputs %s",
+			util.Th(required), len(walletOuts), util.Th(sum))
+	}
+
+	txb := txbuilder.New()
+	inTotal, _, err := txb.ConsumeOutputs(walletOuts...)
+	if err != nil {
+		return base.TransactionID{}, err
+	}
+	if err = txb.PutStandardInputUnlocks(len(walletOuts)); err != nil {
+		return base.TransactionID{}, err
+	}
+	for _, acc := range accounts {
+		if _, err = txb.ProduceOutput(ledger.NewOutput(func(o *ledger.OutputBuilder) {
+			o.WithAmount(r.sc.FundingAmount).WithLock(acc.addr)
+		})); err != nil {
+			return base.TransactionID{}, err
+		}
+	}
+	if _, err = txb.ProduceOutput(ledger.NewOutput(func(o *ledger.OutputBuilder) {
+		o.WithAmount(r.sc.TagAlong.Fee).WithLock(ledger.ChainLockFromChainID(r.tagAlongSeqID))
+	})); err != nil {
+		return base.TransactionID{}, err
+	}
+	if inTotal > required {
+		if _, err = txb.ProduceOutput(ledger.NewOutput(func(o *ledger.OutputBuilder) {
+			o.WithAmount(inTotal - required).WithLock(r.wallet.Account)
+		})); err != nil {
+			return base.TransactionID{}, err
+		}
+	}
+	txb.TransactionData.Timestamp = nextTimestamp(walletOuts...)
+	txb.TransactionData.InputCommitment = ledger.HashOutputs(txb.ConsumedOutputs...)
+	txb.SignED25519(r.wallet.PrivateKey)
+
+	txBytes, txid, failedTx, err := txb.BytesWithValidation()
+	if err != nil {
+		return base.TransactionID{}, fmt.Errorf("funding transaction invalid: %v\n%s", err, failedTx)
+	}
+	return txid, r.clnt.SubmitTransaction(txBytes)
+}
+
+func (r *Runner) waitInclusion(ctx context.Context, txid base.TransactionID) error {
+	deadline := time.Now().Add(r.finalityTimeout())
+	for {
+		_, depth, err := r.clnt.CheckTransactionIDInLRB(txid, 0)
+		if err == nil && depth >= 0 {
+			return nil
+		}
+		if time.Now().After(deadline) {
+			return fmt.Errorf("transaction %s did not reach the LRB in %d slots", txid.StringShort(), r.sc.FinalitySlots)
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(trackPeriod):
+		}
+	}
+}
@@ -0,0 +1,236 @@
+package loadtest
+
+import (
+	"encoding/binary"
+	"fmt"
+	"os"
+	"time"
+
+	"golang.org/x/crypto/blake2b"
+	"gopkg.in/yaml.v2"
+)
+
+// Scenario describes the load test. It is read from the YAML file, for example:
+//
+//	name: ramp_100
+//	accounts: 120
+//	funding_amount: 2000000
+//	mix:
+//	    transfer: 80
+//	    chain: 15
+//	    delegation: 5
+//	transfer:
+//	    amount: 1000
+//	chain:
+//	    amount: 200000
+//	delegation:
+//	    amount: 100000000
+//	tag_along:
+//	    fee: 200
+//	ramp:
+//	    - tps: 10
+//	      to_tps: 100
+//	      duration: 2m
+//	    - tps: 100
+//	      duration: 5m
+//	finality_slots: 6
+//	report: ramp_100.json
+type (
+	Scenario struct {
+		Name string `yaml:"name"`
+		// number of accounts generated from the wallet private key. Each account issues its own chain of transactions.
+		// The rate of transactions per account is limited by the ledger, so high TPS requires many accounts.
+		// 0 means the wallet account is the only account and nothing is funded
+		Accounts int `yaml:"accounts"`
+		// makes set of generated accounts different for different scenarios with the same wallet.
+		// Also seeds the random mix of transaction types, so runs of the same scenario issue the same sequence
+		Seed string `yaml:"seed"`
+		// before the test the wallet transfers the amount to each generated account with smaller balance
+		FundingAmount uint64 `yaml:"funding_amount"`
+		// relative weights of transaction types
+		Mix        Mix              `yaml:"mix"`
+		Transfer   TransferParams   `yaml:"transfer"`
+		Chain      ChainParams      `yaml:"chain"`
+		Delegation DelegationParams `yaml:"delegation"`
+		TagAlong   TagAlongParams   `yaml:"tag_along"`
+		// target TPS stages, run one after another
+		Ramp []Stage `yaml:"ramp"`
+		// 0 means no limit
+		MaxTransactions int `yaml:"max_transactions"`
+		// transaction which does not reach the latest reliable branch in finality_slots is counted as timed out
+		FinalitySlots int `yaml:"finality_slots"`
+		// file name of the JSON report. Empty means no file
+		Report string `yaml:"report"`
+	}
+
+	Mix struct {
+		Transfer   int `yaml:"transfer"`
+		Chain      int `yaml:"chain"`
+		Delegation int `yaml:"delegation"`
+	}
+
+	TransferParams struct {
+		Amount uint64 `yaml:"amount"`
+		// target lock in EasyFL format. Empty means transfers go round-robin to other generated accounts
+		Target string `yaml:"target"`
+	}
+
+	ChainParams struct {
+		// amount on the chain origin. Chain transitions pay tag-along fee from the chain until it is exhausted,
+		// then new chain is created
+		Amount uint64 `yaml:"amount"`
+	}
+
+	DelegationParams struct {
+		Amount uint64 `yaml:"amount"`
+		// hex encoded. Empty means tag-along sequencer
+		SequencerID string `yaml:"sequencer_id"`
+	}
+
+	TagAlongParams struct {
+		// hex encoded. Empty means tag-along sequencer of the wallet profile
+		SequencerID string `yaml:"sequencer_id"`
+		// paid by each transaction. 0 means tag-along fee of the wallet profile
+		Fee uint64 `yaml:"fee"`
+	}
+
+	// Stage is a period with linearly changing target TPS, from TPS to ToTPS.
+	// ToTPS = 0 means constant TPS. Empty duration means unlimited, only allowed for the last stage
+	Stage struct {
+		TPS      float64 `yaml:"tps"`
+		ToTPS    float64 `yaml:"to_tps"`
+		Duration string  `yaml:"duration"`
+
+		duration time.Duration
+	}
+)
+
+const (
+	KindTransfer   = "transfer"
+	KindChain      = "chain"
+	KindDelegation = "delegation"
+)
+
+const (
+	defaultFinalitySlots  = 5
+	defaultTransferAmount = 1000
+)
+
+// LoadScenario reads scenario from the YAML file and checks it
+func LoadScenario(fname string) (*Scenario, error) {
+	data, err := os.ReadFile(fname)
+	if err != nil {
+		return nil, err
+	}
+	return ParseScenario(data)
+}
+
+func ParseScenario(data []byte) (*Scenario, error) {
+	ret := &Scenario{}
+	if err := yaml.UnmarshalStrict(data, ret); err != nil {
+		return nil, fmt.Errorf("failed to parse scenario: %w", err)
+	}
+	if err := ret.Validate(); err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
+
+// Validate checks consistency of the scenario and sets defaults
+func (s *Scenario) Validate() error {
+	if s.Name == "" {
+		s.Name = "noname"
+	}
+	if s.Accounts < 0 {
+		return fmt.Errorf("wrong number of accounts %d", s.Accounts)
+	}
+	if s.Accounts > 0 && s.FundingAmount == 0 {
+		return fmt.Errorf("funding_amount must be specified for generated accounts")
+	}
+	if s.Mix.Transfer < 0 || s.Mix.Chain < 0 || s.Mix.Delegation < 0 {
+		return fmt.Errorf("mix weights can't be negative")
+	}
+	if s.Mix.Transfer+s.Mix.Chain+s.Mix.Delegation == 0 {
+		// transfers only by default
+		s.Mix.Transfer = 1
+	}
+	if s.Transfer.Amount == 0 {
+		s.Transfer.Amount = defaultTransferAmount
+	}
+	if s.Mix.Delegation > 0 && s.Delegation.Amount == 0 {
+		return fmt.Errorf("delegation amount must be specified")
+	}
+	if len(s.Ramp) == 0 {
+		return fmt.Errorf("ramp must contain at least one stage")
+	}
+	for i := range s.Ramp {
+		st := &s.Ramp[i]
+		if st.TPS < 0 || st.ToTPS < 0 {
+			return fmt.Errorf("stage %d: TPS can't be negative", i)
+		}
+		if st.ToTPS == 0 {
+			st.ToTPS = st.TPS
+		}
+		if st.TPS == 0 && st.ToTPS == 0 {
+			return fmt.Errorf("stage %d: TPS must be specified", i)
+		}
+		if st.Duration == "" {
+			if i != len(s.Ramp)-1 {
+				return fmt.Errorf("stage %d: only the last stage can be unlimited", i)
+			}
+			if st.ToTPS != st.TPS {
+				return fmt.Errorf("stage %d: unlimited stage must have constant TPS", i)
+			}
+			continue
+		}
+		var err error
+		if st.duration, err = time.ParseDuration(st.Duration); err != nil {
+			return fmt.Errorf("stage %d: %w", i, err)
+		}
+		if st.duration <= 0 {
+			return fmt.Errorf("stage %d: duration must be positive", i)
+		}
+	}
+	if s.MaxTransactions < 0 {
+		return fmt.Errorf("wrong max_transactions %d", s.MaxTransactions)
+	}
+	if s.FinalitySlots <= 0 {
+		s.FinalitySlots = defaultFinalitySlots
+	}
+	return nil
+}
+
+// Kinds returns kinds of transactions with non-zero weights
+func (s *Scenario) Kinds() []string {
+	ret := make([]string, 0, 3)
+	if s.Mix.Transfer > 0 {
+		ret = append(ret, KindTransfer)
+	}
+	if s.Mix.Chain > 0 {
+		ret = append(ret, KindChain)
+	}
+	if s.Mix.Delegation > 0 {
+		ret = append(ret, KindDelegation)
+	}
+	return ret
+}
+
+// PickKind returns kind of the transaction for the random number in [0, 1)
+func (s *Scenario) PickKind(r float64) string {
+	total := s.Mix.Transfer + s.Mix.Chain + s.Mix.Delegation
+	w := int(r * float64(total))
+	switch {
+	case w < s.Mix.Transfer:
+		return KindTransfer
+	case w < s.Mix.Transfer+s.Mix.Chain:
+		return KindChain
+	default:
+		return KindDelegation
+	}
+}
+
+// RandSeed returns seed of the random choice of transaction types derived from the scenario seed
+func (s *Scenario) RandSeed() int64 {
+	h := blake2b.Sum256([]byte(s.Seed))
+	return int64(binary.BigEndian.Uint64(h[:8]))
+}
@@ -2,10 +2,17 @@ package node_cmd
 
 import (
 	"github.com/lunfardo314/proxima/ledger"
+	"github.com/lunfardo314/proxima/ledger/multistate"
 	"github.com/lunfardo314/proxima/proxi/glb"
 	"github.com/spf13/cobra"
 )
 
+type lrbResult struct {
+	ID         string                         `json:"id"`
+	SlotsBack  int                            `json:"slots_back"`
+	RootRecord *multistate.RootRecordJSONAble `json:"root_record"`
+}
+
 func initReliableBranchCmd() *cobra.Command {
 	reliableBranchCmd := &cobra.Command{
 		Use:     "lrb",
@@ -33,4 +40,9 @@ func runReliableBranchCmd(_ *cobra.Command, _ []string) {
 	} else {
 		glb.Infof("   root record:\n%s", rootRecord.Lines("     ").String())
 	}
+	glb.PrintResult(&lrbResult{
+		ID:         branchID.StringHex(),
+		SlotsBack:  int(nowis.Slot - branchID.Slot()),
+		RootRecord: rootRecord.JSONAble(),
+	})
 }
@@ -1,7 +1,6 @@
 package node_cmd
 
 import (
-	"os"
 	"strconv"
 	"time"
 
@@ -14,6 +13,12 @@ import (
 	"github.com/spf13/cobra"
 )
 
+// chainTxResult is the transaction which creates or transits the chain
+type chainTxResult struct {
+	*glb.TxResult
+	ChainID string `json:"chain_id"`
+}
+
 func initMakeChainCmd() *cobra.Command {
 	makeChainCmd := &cobra.Command{
 		Use:   "mkchain <initial on-chain balance>",
@@ -55,8 +60,7 @@ func MakeChain(onChainAmount uint64) (*transaction.TxContext, base.ChainID, erro
 	glb.Infof("   chain controller: %s", target)
 
 	if !glb.YesNoPrompt("proceed?:", true, glb.BypassYesNoPrompt()) {
-		glb.Infof("exit")
-		os.Exit(0)
+		glb.Abort()
 	}
 
 	inps, lrbid, totalInputs, err := glb.GetClient().GetTransferableOutputs(walletData.Account)
@@ -102,4 +106,8 @@ func runMakeChainCmd(_ *cobra.Command, args []string) {
 	if !glb.NoWait() {
 		glb.TrackTxInclusion(txCtx.TransactionID(), time.Second)
 	}
+	glb.PrintResult(&chainTxResult{
+		TxResult: glb.NewTxResult(txCtx.TransactionID(), !glb.NoWait()),
+		ChainID:  chainID.StringHex(),
+	})
 }
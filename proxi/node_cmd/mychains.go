@@ -3,7 +3,6 @@ package node_cmd
 import (
 	"bytes"
 	"fmt"
-	"os"
 	"sort"
 
 	"github.com/lunfardo314/proxima/ledger"
@@ -15,6 +14,30 @@ import (
 
 var delegationOnly bool
 
+type (
+	chainListResult struct {
+		LRBID  string        `json:"lrbid"`
+		Chains []chainResult `json:"chains"`
+	}
+
+	chainResult struct {
+		*glb.UTXOResult
+		IsSequencer   bool   `json:"is_sequencer"`
+		SequencerName string `json:"sequencer_name,omitempty"`
+	}
+)
+
+func newChainResult(o *ledger.OutputWithChainID) chainResult {
+	ret := chainResult{UTXOResult: glb.NewUTXOResult(&o.OutputWithID)}
+	if sd, _ := o.Output.SequencerOutputData(); sd != nil {
+		ret.IsSequencer = true
+		if md := sd.MilestoneData; md != nil {
+			ret.SequencerName = md.Name
+		}
+	}
+	return ret
+}
+
 func initChainsCmd() *cobra.Command {
 	chainsCmd := &cobra.Command{
 		Use:   "mychains",
@@ -38,9 +61,11 @@ func runChainsCmd(_ *cobra.Command, _ []string) {
 	glb.AssertNoError(err)
 
 	glb.PrintLRB(lrbid)
+	res := &chainListResult{LRBID: lrbid.StringHex(), Chains: []chainResult{}}
 	if len(outs) == 0 {
 		glb.Infof("no chains have been found controlled by %s", wallet.Account.String())
-		os.Exit(0)
+		glb.PrintResult(res)
+		return
 	}
 
 	sort.Slice(outs, func(i, j int) bool {
@@ -48,13 +73,15 @@ func runChainsCmd(_ *cobra.Command, _ []string) {
 	})
 
 	if delegationOnly {
-		listDelegations(wallet.Account, outs)
+		res.Chains = listDelegations(wallet.Account, outs)
 	} else {
-		listChainedOutputs(wallet.Account, outs)
+		res.Chains = listChainedOutputs(wallet.Account, outs)
 	}
+	glb.PrintResult(res)
 }
 
-func listChainedOutputs(addr ledger.AddressED25519, outs []*ledger.OutputWithChainID) {
+func listChainedOutputs(addr ledger.AddressED25519, outs []*ledger.OutputWithChainID) []chainResult {
+	ret := make([]chainResult, 0, len(outs))
 	glb.Infof("\nlist of %d chain(s) indexed in the account %s",
 		len(outs), addr.String())
 	for i, o := range outs {
@@ -94,10 +121,13 @@ func listChainedOutputs(addr ledger.AddressED25519, outs []*ledger.OutputWithCha
 			glb.Infof("      master      : %s"+thisControls, l.OwnerLock.String())
 			glb.Infof("      delegated to: %s"+delegatedToThis, l.TargetLock.String())
 		}
+		ret = append(ret, newChainResult(o))
 	}
+	return ret
 }
 
-func listDelegations(addr ledger.AddressED25519, outs []*ledger.OutputWithChainID) {
+func listDelegations(addr ledger.AddressED25519, outs []*ledger.OutputWithChainID) []chainResult {
+	ret := make([]chainResult, 0, len(outs))
 	sort.Slice(outs, func(i, j int) bool {
 		return bytes.Compare(outs[i].ChainID[:], outs[j].ChainID[:]) < 0
 	})
@@ -129,6 +159,8 @@ func listDelegations(addr ledger.AddressED25519, outs []*ledger.OutputWithChainI
 		)
 
 		total += o.Output.Amount()
+		ret = append(ret, newChainResult(o))
 	}
 	glb.Infof("\nTotal delegated in %d outputs: %s", len(outs), util.Th(total))
+	return ret
 }
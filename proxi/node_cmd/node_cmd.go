@@ -50,6 +50,7 @@ func Init() *cobra.Command {
 		initCompactOutputsCmd(),
 		initBalanceCmd(),
 		initTransferCmd(),
+		initBatchTransferCmd(),
 		initSpamCmd(),
 		initMakeChainCmd(),
 		//initDeleteChainCmd(),
@@ -68,6 +69,9 @@ func Init() *cobra.Command {
 		initDelegateCmd(),
 		initAllChainsCmd(),
 		initNodeGetLedgerIDCmd(),
+		initWhyCmd(),
+		initHistoryCmd(),
+		initChainHistoryCmd(),
 	)
 	return nodeCmd
 }
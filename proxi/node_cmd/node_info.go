@@ -1,12 +1,18 @@
 package node_cmd
 
 import (
+	"github.com/lunfardo314/proxima/global"
 	"github.com/lunfardo314/proxima/ledger"
 	"github.com/lunfardo314/proxima/proxi/glb"
 	"github.com/lunfardo314/proxima/util/lines"
 	"github.com/spf13/cobra"
 )
 
+type nodeInfoResult struct {
+	Node *global.NodeInfo `json:"node"`
+	LRB  lrbResult        `json:"lrb"`
+}
+
 func initNodeInfoCmd() *cobra.Command {
 	getNodeInfoCmd := &cobra.Command{
 		Use:   "info",
@@ -39,4 +45,13 @@ func runNodeInfoCmd(_ *cobra.Command, _ []string) {
 
 	glb.Infof("\nLedger id (ledger constants):")
 	glb.Infof(ledger.L().ID.Lines("    ").String())
+
+	glb.PrintResult(&nodeInfoResult{
+		Node: nodeInfo,
+		LRB: lrbResult{
+			ID:         branchID.StringHex(),
+			SlotsBack:  int(ledger.TimeNow().Slot - branchID.Slot()),
+			RootRecord: rootRecord.JSONAble(),
+		},
+	})
 }
@@ -1,6 +1,8 @@
 package node_cmd
 
 import (
+	"fmt"
+
 	"github.com/lunfardo314/proxima/proxi/glb"
 	"github.com/spf13/cobra"
 )
@@ -23,6 +25,14 @@ func runPeersInfoCmd(_ *cobra.Command, _ []string) {
 	peersInfo, err := glb.GetClient().GetPeersInfo()
 	glb.AssertNoError(err)
 	for p := range peersInfo.Peers {
-		glb.Infof("        %s : %s", peersInfo.Peers[p].ID, peersInfo.Peers[p].MultiAddresses[0])
+		score := "n/a"
+		if r := peersInfo.Peers[p].Reputation; r != nil {
+			score = fmt.Sprintf("%.1f", r.Score)
+		}
+		glb.Infof("        %s : %s, reputation score: %s", peersInfo.Peers[p].ID, peersInfo.Peers[p].MultiAddresses[0], score)
+	}
+	for id, reason := range peersInfo.Blacklist {
+		glb.Infof("        blacklisted %s : %s", id, reason)
 	}
+	glb.PrintResult(peersInfo)
 }
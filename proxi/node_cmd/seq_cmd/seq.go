@@ -32,5 +32,5 @@ func Init() *cobra.Command {
 }
 
 func getClient() *client.APIClient {
-	return client.NewWithGoogleDNS(viper.GetString("api.endpoint"))
+	return client.NewWithGoogleDNS(viper.GetString("api.endpoint")).WithAPIKey(glb.GetAPIKey())
 }
@@ -61,8 +61,7 @@ func runSeqWithdrawCmd(_ *cobra.Command, args []string) {
 	prompt := fmt.Sprintf("withdraw %s from %s to the target %s?",
 		util.Th(amount), walletData.Sequencer.StringShort(), targetLock.String())
 	if !glb.YesNoPrompt(prompt, false) {
-		glb.Infof("exit")
-		return
+		glb.Abort()
 	}
 
 	// create command with withdraw request to the target lock
@@ -88,11 +87,11 @@ func runSeqWithdrawCmd(_ *cobra.Command, args []string) {
 	err = getClient().SubmitTransaction(txBytes)
 	glb.AssertNoError(err)
 
-	if glb.NoWait() {
-		return
-	}
 	txid, err := transaction.IDFromParsedTransactionBytes(txBytes)
 	glb.AssertNoError(err)
 
-	glb.TrackTxInclusion(txid, time.Second)
+	if !glb.NoWait() {
+		glb.TrackTxInclusion(txid, time.Second)
+	}
+	glb.PrintResult(glb.NewTxResult(txid, !glb.NoWait()))
 }
@@ -46,8 +46,7 @@ func runSeqSetupCmd(_ *cobra.Command, args []string) {
 
 		glb.Infof("amount: %s", util.Th(amount))
 		if amount < ledger.L().Const().MinimumAmountOnSequencer() {
-			glb.Infof("minimum amout required: %d", ledger.L().Const().MinimumAmountOnSequencer())
-			return
+			glb.Fatalf("minimum amout required: %d", ledger.L().Const().MinimumAmountOnSequencer())
 		}
 
 		// wait for available funds
@@ -76,9 +75,15 @@ func runSeqSetupCmd(_ *cobra.Command, args []string) {
 
 		// update proxima.yaml
 		updateNodeConfig(name, walletData.PrivateKey, *chainId)
+		glb.PrintResult(&setupSeqResult{Name: name, SequencerID: chainId.StringHex()})
 	}
 }
 
+type setupSeqResult struct {
+	Name        string `json:"name"`
+	SequencerID string `json:"sequencer_id"`
+}
+
 func getChainIdForAccount(account ledger.Accountable) *base.ChainID {
 	clnt := glb.GetClient()
 	chains, _, err := clnt.GetAllChains()
@@ -1,240 +1,171 @@
 package node_cmd
 
 import (
-	"math"
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
 	"time"
 
-	"github.com/lunfardo314/proxima/api/client"
 	"github.com/lunfardo314/proxima/ledger"
-	"github.com/lunfardo314/proxima/ledger/base"
-	"github.com/lunfardo314/proxima/ledger/transaction"
 	"github.com/lunfardo314/proxima/proxi/glb"
-	"github.com/lunfardo314/proxima/util"
+	"github.com/lunfardo314/proxima/proxi/node_cmd/loadtest"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
 
-type spammerConfig struct {
-	outputAmount      uint64
-	bundleSize        int
-	pace              int
-	maxTransactions   int
-	maxDuration       time.Duration
-	tagAlongSequencer base.ChainID
-	tagAlongFee       uint64
-	target            ledger.Accountable
-	finalitySlots     int
-}
+var (
+	spamReportFile string
+	spamJSONOutput bool
+)
 
 func initSpamCmd() *cobra.Command {
 	spamCmd := &cobra.Command{
-		Use:   "spam",
-		Short: `spams the ledger according to spammer.scenario`,
-		Args:  cobra.NoArgs,
-		Run:   runSpamCmd,
+		Use:   "spam [--scenario <scenario file>]",
+		Short: `load test: submits transactions according to the scenario, tracks their inclusion into the LRB and reports throughput and latencies`,
+		Long: `load test: submits transactions according to the scenario file, tracks their inclusion into the latest reliable branch
+and reports throughput, latency percentiles, rejections and fees paid.
+Without the scenario file, 'spammer' section of the profile is used: the wallet sends transfers to the target at the constant pace`,
+		Args: cobra.NoArgs,
+		Run:  runSpamCmd,
 	}
 
+	spamCmd.PersistentFlags().String("scenario", "", "YAML file with the load test scenario")
+	err := viper.BindPFlag("spammer.scenario", spamCmd.PersistentFlags().Lookup("scenario"))
+	glb.AssertNoError(err)
+
+	spamCmd.PersistentFlags().StringVar(&spamReportFile, "report", "", "file name of the JSON report. Overrides the scenario")
+	spamCmd.PersistentFlags().BoolVar(&spamJSONOutput, "json", false, "print JSON report to stdout")
+
 	spamCmd.PersistentFlags().Int("spammer.max_transactions", 0, "number of transaction limit")
-	err := viper.BindPFlag("spammer.max_transactions", spamCmd.PersistentFlags().Lookup("spammer.max_transactions"))
+	err = viper.BindPFlag("spammer.max_transactions", spamCmd.PersistentFlags().Lookup("spammer.max_transactions"))
 	glb.AssertNoError(err)
 
 	spamCmd.PersistentFlags().Int("spammer.max_duration_minutes", 0, "time limit in minutes")
 	err = viper.BindPFlag("spammer.max_duration_minutes", spamCmd.PersistentFlags().Lookup("spammer.max_duration_minutes"))
 	glb.AssertNoError(err)
 
-	spamCmd.PersistentFlags().Uint64("spammer.output_amount", 500, "amount on the output")
+	spamCmd.PersistentFlags().Uint64("spammer.output_amount", 1000, "amount on the output")
 	err = viper.BindPFlag("spammer.output_amount", spamCmd.PersistentFlags().Lookup("spammer.output_amount"))
 	glb.AssertNoError(err)
 
-	spamCmd.PersistentFlags().Uint64("spammer.bundle_size", 30, "transaction bundle size")
-	err = viper.BindPFlag("spammer.bundle_size", spamCmd.PersistentFlags().Lookup("spammer.bundle_size"))
-	glb.AssertNoError(err)
-
-	spamCmd.PersistentFlags().Uint64("spammer.pace", 3, "pace in ticks")
+	spamCmd.PersistentFlags().Uint64("spammer.pace", 25, "pace in ticks")
 	err = viper.BindPFlag("spammer.pace", spamCmd.PersistentFlags().Lookup("spammer.pace"))
 	glb.AssertNoError(err)
 
-	spamCmd.PersistentFlags().String("spammer.tag_along.sequencer", "", "spammer.tag_along.sequencer")
-	err = viper.BindPFlag("spammer.tag_along.sequencer", spamCmd.PersistentFlags().Lookup("spammer.tag_along.sequencer"))
+	spamCmd.PersistentFlags().String("spammer.tag_along.sequencer_id", "", "tag-along sequencer ID")
+	err = viper.BindPFlag("spammer.tag_along.sequencer_id", spamCmd.PersistentFlags().Lookup("spammer.tag_along.sequencer_id"))
 	glb.AssertNoError(err)
 
-	spamCmd.PersistentFlags().Uint64("spammer.tag_along.fee", 500, "spammer.tag_along.sequencer")
+	spamCmd.PersistentFlags().Uint64("spammer.tag_along.fee", 500, "tag-along fee")
 	err = viper.BindPFlag("spammer.tag_along.fee", spamCmd.PersistentFlags().Lookup("spammer.tag_along.fee"))
 	glb.AssertNoError(err)
 
 	return spamCmd
 }
 
-func readSpammerConfigIn(sub *viper.Viper) (ret spammerConfig) {
+// scenarioFromSpammerConfig makes the scenario equivalent to the legacy spammer:
+// transfers from the wallet to the target at constant pace
+func scenarioFromSpammerConfig(sub *viper.Viper) *loadtest.Scenario {
 	glb.Assertf(sub != nil, "spammer configuration is not available")
-	ret.outputAmount = sub.GetUint64("output_amount")
-	ret.bundleSize = sub.GetInt("bundle_size")
-	ret.pace = sub.GetInt("pace")
-	ret.maxTransactions = sub.GetInt("max_transactions")
-	ret.maxDuration = time.Duration(sub.GetInt("max_duration_minutes")) * time.Minute
-	ret.tagAlongFee = sub.GetUint64("tag_along.fee")
 
-	var err error
-
-	seqStr := sub.GetString("tag_along.sequencer_id")
-	if seqStr != "" {
-		ret.tagAlongSequencer, err = base.ChainIDFromHexString(seqStr)
-		glb.AssertNoError(err)
-	} else {
-		glb.Infof("tag-along for spamming is not specified. Using default")
-		defaultTgaSeq := glb.GetTagAlongSequencerID()
-		glb.Assertf(defaultTgaSeq != nil, "tag-along sequencer not specified")
-		if defaultTgaSeq != nil {
-			ret.tagAlongSequencer = *defaultTgaSeq
-		}
+	pace := sub.GetInt("pace")
+	glb.Assertf(pace > 0, "spammer.pace must be positive")
+	stage := loadtest.Stage{
+		TPS: 1 / (time.Duration(pace) * ledger.TickDuration()).Seconds(),
 	}
-
-	ret.target, err = ledger.AddressED25519FromSource(sub.GetString("target"))
-	glb.AssertNoError(err)
-	ret.finalitySlots = sub.GetInt("finality_slots")
-	if ret.finalitySlots <= 1 {
-		ret.finalitySlots = 5
+	if minutes := sub.GetInt("max_duration_minutes"); minutes > 0 {
+		stage.Duration = fmt.Sprintf("%dm", minutes)
+	}
+	target := sub.GetString("target")
+	glb.Assertf(target != "", "spammer.target must be specified")
+
+	return &loadtest.Scenario{
+		Name: "spammer",
+		Mix:  loadtest.Mix{Transfer: 1},
+		Transfer: loadtest.TransferParams{
+			Amount: sub.GetUint64("output_amount"),
+			Target: target,
+		},
+		TagAlong: loadtest.TagAlongParams{
+			SequencerID: sub.GetString("tag_along.sequencer_id"),
+			Fee:         sub.GetUint64("tag_along.fee"),
+		},
+		Ramp:            []loadtest.Stage{stage},
+		MaxTransactions: sub.GetInt("max_transactions"),
+		FinalitySlots:   sub.GetInt("finality_slots"),
 	}
-	return
-}
-
-func displaySpammerConfig() spammerConfig {
-	cfg := readSpammerConfigIn(viper.Sub("spammer"))
-	glb.Infof("\nspammer configuration:")
-	glb.Infof("     output amount: %d", cfg.outputAmount)
-	glb.Infof("     bundle size: %d", cfg.bundleSize)
-	glb.Infof("     pace: %d", cfg.pace)
-	//glb.Infof("max transactions: %d", cfg.maxTransactions)
-	//glb.Infof("max duration: %v", cfg.maxDuration)
-	glb.Infof("     tag-along sequencer: %s", cfg.tagAlongSequencer.String())
-	glb.Infof("     tag-along fee: %d", cfg.tagAlongFee)
-	//glb.Infof("     finality slots: %d", cfg.finalitySlots)
-
-	walletData := glb.GetWalletData()
-	glb.Infof("     source account (wallet): %s", walletData.Account.String())
-	glb.Infof("     target account: %s", cfg.target.String())
-
-	glb.Assertf(glb.YesNoPrompt("\nstart spamming?", true, glb.BypassYesNoPrompt()), "exit")
-
-	return cfg
 }
 
 func runSpamCmd(_ *cobra.Command, _ []string) {
 	glb.InitLedgerFromNode()
-	cfg := displaySpammerConfig()
-	doSpamming(cfg)
-}
-
-const minimumBalance = 1000
-
-func doSpamming(cfg spammerConfig) {
-	walletData := glb.GetWalletData()
 
-	txCounter := 0
-	deadline := time.Unix(0, math.MaxInt64)
-	if cfg.maxDuration > 0 {
-		deadline = time.Now().Add(cfg.maxDuration)
+	var sc *loadtest.Scenario
+	var err error
+	if fname := viper.GetString("spammer.scenario"); fname != "" {
+		sc, err = loadtest.LoadScenario(fname)
+		glb.AssertNoError(err)
+	} else {
+		glb.Infof("scenario file not specified. Using 'spammer' section of the profile")
+		sc = scenarioFromSpammerConfig(viper.Sub("spammer"))
+		glb.AssertNoError(sc.Validate())
+	}
+	if sc.TagAlong.Fee == 0 {
+		sc.TagAlong.Fee = glb.GetTagAlongFee()
+	}
+	if spamReportFile != "" {
+		sc.Report = spamReportFile
 	}
 
-	beginTime := time.Now()
-	for {
-		time.Sleep(time.Duration(cfg.pace) * ledger.TickDuration())
-
-		glb.Assertf(cfg.maxTransactions == 0 || txCounter < cfg.maxTransactions, "maximum transaction limit %d has been reached", cfg.maxTransactions)
-		glb.Assertf(time.Now().Before(deadline), "spam duration limit has been reached")
-
-		outs, _, balance, err := glb.GetClient().GetTransferableOutputs(walletData.Account, 256)
-		glb.AssertNoError(err)
+	walletData := glb.GetWalletData()
+	tagAlongSeqID := glb.GetTagAlongSequencerID()
+	glb.Assertf(tagAlongSeqID != nil, "tag-along sequencer not specified")
 
-		glb.Verbosef("Fetched inputs from account %s:\n%s", walletData.Account.String(), glb.LinesOutputsWithIDs(outs).String())
+	runner, err := loadtest.NewRunner(sc, glb.GetClient(), walletData, *tagAlongSeqID)
+	glb.AssertNoError(err)
 
-		glb.Infof("transferable balance: %s, number of outputs: %d", util.Th(balance), len(outs))
-		requiredBalance := minimumBalance + cfg.outputAmount*uint64(cfg.bundleSize) + cfg.tagAlongFee
-		if balance < requiredBalance {
-			glb.Infof("transferable balance (%s) is too small for the bundle (required is %s). Waiting for more..",
-				util.Th(balance), util.Th(requiredBalance))
-			continue
-		}
+	displayScenario(sc, walletData)
+	if !glb.YesNoPrompt("\nstart load test?", true, glb.BypassYesNoPrompt()) {
+		glb.Abort()
+	}
 
-		bundle, oid := prepareBundle(walletData, cfg)
-		bundlePace := cfg.pace * len(bundle)
-		bundleDuration := time.Duration(bundlePace) * ledger.TickDuration()
-		glb.Infof("submitting bundle of %d transactions, total duration %d ticks, %v", len(bundle), bundlePace, bundleDuration)
-
-		for i, txBytes := range bundle {
-			err = glb.GetClient().SubmitTransaction(txBytes)
-			glb.AssertNoError(err)
-			txid, err := transaction.IDFromParsedTransactionBytes(txBytes)
-			glb.AssertNoError(err)
-			if i == len(bundle)-1 {
-				glb.Verbosef("%2d: submitted %s -> tag-along", i, txid.StringShort())
-			} else {
-				glb.Verbosef("%2d: submitted %s", i, txid.StringShort())
-			}
-		}
+	// ctrl-C stops the load and produces the report
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
 
-		glb.TrackTxInclusion(oid.TransactionID(), time.Second)
+	report, err := runner.Run(ctx)
+	glb.AssertNoError(err)
 
-		txCounter += len(bundle)
-		timeSinceBeginning := time.Since(beginTime)
-		glb.Infof("tx counter: %d, TPS avg: %2f", txCounter, float32(txCounter)/float32(timeSinceBeginning/time.Second))
+	glb.Infof("\n------------ load test report\n%s", report.Lines("   ").String())
+	if sc.Report != "" {
+		glb.AssertNoError(report.Save(sc.Report))
+		glb.Infof("JSON report saved to %s", sc.Report)
 	}
-}
-
-func maxTimestamp(outs []*ledger.OutputWithID) (ret base.LedgerTime) {
-	for _, o := range outs {
-		ret = base.MaximumTime(ret, o.Timestamp())
+	if spamJSONOutput && !glb.IsJSONOutput() {
+		fmt.Println(string(report.JSON()))
 	}
-	return
+	glb.PrintResult(report)
 }
 
-func prepareBundle(walletData glb.WalletData, cfg spammerConfig) ([][]byte, base.OutputID) {
-	ret := make([][]byte, 0)
-	txCtx, err := glb.GetClient().MakeCompactTransaction(walletData.PrivateKey, nil, 0, cfg.bundleSize*3)
-	glb.AssertNoError(err)
-
-	numTx := cfg.bundleSize
-	var lastOuts []*ledger.OutputWithID
-	if txCtx != nil {
-		ret = append(ret, txCtx.TransactionBytes())
-		lastOut, _ := txCtx.ProducedOutput(0)
-		lastOuts = []*ledger.OutputWithID{lastOut}
-		numTx--
+func displayScenario(sc *loadtest.Scenario, walletData glb.WalletData) {
+	glb.Infof("\nload test scenario '%s':", sc.Name)
+	if sc.Accounts == 0 {
+		glb.Infof("     source account (wallet): %s", walletData.Account.String())
 	} else {
-		lastOuts, _, _, err = glb.GetClient().GetTransferableOutputs(walletData.Account, cfg.bundleSize)
-		glb.AssertNoError(err)
+		glb.Infof("     generated accounts: %d, funded from the wallet %s", sc.Accounts, walletData.Account.String())
 	}
-
-	for i := 0; i < numTx; i++ {
-		fee := uint64(0)
-		if i == numTx-1 {
-			fee = cfg.tagAlongFee
+	glb.Infof("     mix: transfer %d, chain %d, delegation %d", sc.Mix.Transfer, sc.Mix.Chain, sc.Mix.Delegation)
+	for i, st := range sc.Ramp {
+		duration := st.Duration
+		if duration == "" {
+			duration = "unlimited"
 		}
-		ts := base.MaximumTime(maxTimestamp(lastOuts).AddTicks(cfg.pace), ledger.TimeNow())
-		txBytes, err := client.MakeTransferTransaction(client.MakeTransferTransactionParams{
-			Inputs:        lastOuts,
-			Target:        cfg.target.AsLock(),
-			Amount:        cfg.outputAmount,
-			Remainder:     walletData.Account,
-			PrivateKey:    walletData.PrivateKey,
-			TagAlongSeqID: &cfg.tagAlongSequencer,
-			TagAlongFee:   fee,
-			Timestamp:     ts,
-		})
-		glb.AssertNoError(err)
-
-		ret = append(ret, txBytes)
-
-		lastOuts, err = transaction.OutputsWithIDFromTransactionBytes(txBytes)
-		glb.AssertNoError(err)
-		lastOuts = util.PurgeSlice(lastOuts, func(o *ledger.OutputWithID) bool {
-			return ledger.EqualConstraints(o.Output.Lock(), walletData.Account)
-		})
+		glb.Infof("     stage %d: TPS %.2f -> %.2f, duration %s", i, st.TPS, st.ToTPS, duration)
 	}
-	glb.Verbosef("last outputs in the bundle:")
-	for i, o := range lastOuts {
-		glb.Verbosef("--- %d:\n%s", i, o.String())
+	if sc.MaxTransactions > 0 {
+		glb.Infof("     max transactions: %d", sc.MaxTransactions)
 	}
-
-	return ret, lastOuts[0].ID
+	glb.Infof("     tag-along fee: %d", sc.TagAlong.Fee)
+	glb.Infof("     finality slots: %d", sc.FinalitySlots)
 }
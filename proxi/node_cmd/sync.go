@@ -27,4 +27,12 @@ func runSyncInfoCmd(_ *cobra.Command, _ []string) {
 	glb.Infof("  current slot: %v", syncInfo.CurrentSlot)
 	glb.Infof("  LRB slot:     %v", syncInfo.LrbSlot)
 	glb.Infof("  ledger coverage:     %s", util.Th(syncInfo.LedgerCoverage))
+	if fork := syncInfo.Fork; fork != nil {
+		glb.Infof("  fork detected: %v, on minority: %v, competing branches: %d for %d slots",
+			fork.Detected, fork.OnMinority, len(fork.Competing), fork.NumSlots)
+		for _, br := range fork.Competing {
+			glb.Infof("      %s (%s) coverage: %s", br.ID, br.Source, util.Th(br.Coverage))
+		}
+	}
+	glb.PrintResult(syncInfo)
 }
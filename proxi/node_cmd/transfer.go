@@ -2,7 +2,6 @@ package node_cmd
 
 import (
 	"fmt"
-	"os"
 	"strconv"
 	"time"
 
@@ -12,6 +11,13 @@ import (
 	"github.com/spf13/cobra"
 )
 
+type transferResult struct {
+	*glb.TxResult
+	Amount uint64 `json:"amount"`
+	Target string `json:"target"`
+	Fee    uint64 `json:"fee"`
+}
+
 func initTransferCmd() *cobra.Command {
 	transferCmd := &cobra.Command{
 		Use:   "transfer <amount>",
@@ -52,9 +58,8 @@ func runTransferCmd(_ *cobra.Command, args []string) {
 	}
 	prompt := fmt.Sprintf("transfer will cost %d of fees paid to the tag-along sequencer %s. Proceed?", feeAmount, tagAlongSeqID.StringShort())
 
-	if !glb.YesNoPrompt(prompt, true) {
-		glb.Infof("exit")
-		os.Exit(0)
+	if !glb.YesNoPrompt(prompt, true, glb.BypassYesNoPrompt()) {
+		glb.Abort()
 	}
 
 	txCtx, err := glb.GetClient().TransferFromED25519Wallet(client.TransferFromED25519WalletParams{
@@ -72,8 +77,13 @@ func runTransferCmd(_ *cobra.Command, args []string) {
 	glb.Assertf(txCtx != nil, "inconsistency: txCtx == nil")
 	glb.Infof("transaction submitted successfully")
 
-	if glb.NoWait() {
-		return
+	if !glb.NoWait() {
+		glb.TrackTxInclusion(txCtx.TransactionID(), time.Second)
 	}
-	glb.TrackTxInclusion(txCtx.TransactionID(), time.Second)
+	glb.PrintResult(&transferResult{
+		TxResult: glb.NewTxResult(txCtx.TransactionID(), !glb.NoWait()),
+		Amount:   amount,
+		Target:   target.String(),
+		Fee:      feeAmount,
+	})
 }
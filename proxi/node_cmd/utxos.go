@@ -1,6 +1,7 @@
 package node_cmd
 
 import (
+	"github.com/lunfardo314/proxima/api"
 	"github.com/lunfardo314/proxima/ledger/base"
 	"github.com/lunfardo314/proxima/proxi/glb"
 	"github.com/lunfardo314/proxima/util"
@@ -30,8 +31,10 @@ func runGetOutputsCmd(_ *cobra.Command, _ []string) {
 
 	if outs == nil || len(outs.Outputs) == 0 {
 		glb.Infof("no outputs found")
+		glb.PrintResult(&api.ParsedOutputList{})
 		return
 	}
+	glb.PrintResult(outs)
 	lrbid, err := base.TransactionIDFromHexString(outs.LRBID)
 	glb.AssertNoError(err)
 	glb.PrintLRB(&lrbid)
@@ -0,0 +1,81 @@
+package node_cmd
+
+import (
+	"time"
+
+	"github.com/lunfardo314/proxima/api"
+	"github.com/lunfardo314/proxima/ledger/base"
+	"github.com/lunfardo314/proxima/proxi/glb"
+	"github.com/spf13/cobra"
+)
+
+const defaultLatestRejections = 20
+
+func initWhyCmd() *cobra.Command {
+	whyCmd := &cobra.Command{
+		Use:   "why [<transaction id hex encoded>]",
+		Short: `explains why the transaction was rejected by the node. Without arguments lists the latest rejections`,
+		Args:  cobra.MaximumNArgs(1),
+		Run:   runWhyCmd,
+	}
+	whyCmd.InitDefaultHelpCmd()
+	return whyCmd
+}
+
+func runWhyCmd(_ *cobra.Command, args []string) {
+	glb.InitLedgerFromNode()
+
+	if len(args) == 0 {
+		res, err := glb.GetClient().GetLatestTxRejections(defaultLatestRejections)
+		glb.AssertNoError(err)
+		glb.Infof("latest %d of total %d rejections recorded by the node:", len(res.Rejections), res.Total)
+		for i := range res.Rejections {
+			displayTxRejection(&res.Rejections[i])
+		}
+		glb.PrintResult(res)
+		return
+	}
+
+	txid, err := base.TransactionIDFromHexString(args[0])
+	glb.AssertNoError(err)
+
+	res, err := glb.GetClient().GetTxRejections(txid)
+	glb.AssertNoError(err)
+
+	if len(res.Rejections) > 0 {
+		glb.Infof("transaction %s has been rejected by the node %d time(s):", txid.String(), len(res.Rejections))
+		for i := range res.Rejections {
+			displayTxRejection(&res.Rejections[i])
+		}
+		glb.PrintResult(&whyResult{TxID: txid.StringHex(), Rejections: res.Rejections})
+		return
+	}
+
+	// no record about rejection. Check if transaction is in the LRB
+	lrbID, foundAtDepth, err := glb.GetClient().CheckTransactionIDInLRB(txid, 0)
+	glb.AssertNoError(err)
+	if foundAtDepth >= 0 {
+		glb.Infof("transaction %s has not been rejected. It is included in the latest reliable branch %s",
+			txid.String(), lrbID.String())
+		glb.PrintResult(&whyResult{TxID: txid.StringHex(), Included: true, LRBID: lrbID.StringHex()})
+		return
+	}
+	glb.Infof("transaction %s has not been rejected by the node and it is not included in the latest reliable branch %s.\n"+
+		"The transaction may still be pending, it may be unknown to the node or it may have been not included into the ledger "+
+		"by sequencers, for example because of the conflict with another transaction", txid.String(), lrbID.String())
+	glb.PrintResult(&whyResult{TxID: txid.StringHex(), LRBID: lrbID.StringHex()})
+}
+
+type whyResult struct {
+	TxID       string            `json:"txid"`
+	Included   bool              `json:"included"`
+	LRBID      string            `json:"lrbid,omitempty"`
+	Rejections []api.TxRejection `json:"rejections,omitempty"`
+}
+
+func displayTxRejection(rej *api.TxRejection) {
+	txid, err := base.TransactionIDFromHexString(rej.TxID)
+	glb.AssertNoError(err)
+	glb.Infof("  %s (slot %d, %s, source: %s)\n      %s",
+		txid.String(), rej.Slot, time.Unix(rej.Time, 0).Format(time.RFC3339), rej.Source, rej.Reason)
+}
@@ -51,16 +51,23 @@ func runSnapshotCheckCmd(_ *cobra.Command, args []string) {
 	fromYAML, err := easyfl.ReadLibraryFromYAML(ssData.ledgerIDData)
 	glb.AssertNoError(err)
 
-	h := ledger.L().LibraryHash()
+	res := &snapshotCheckResult{
+		snapshotResult: newSnapshotResult(fname, ssData.fmtVersion, ssData.branchID, &ssData.rootRecord, nil),
+	}
+	h := ledger.L().LedgerIDHash()
 	if fromYAML.Hash != hex.EncodeToString(h[:]) {
 		glb.Infof("ledger id hash in snapshot file %s is not equal to the ledger id hash on the node on '%s'.\nThe snapshot file CANNOT BE USED to start a node",
 			fname, viper.GetString("api.endpoint"))
+		glb.PrintResult(res)
 		return
 	}
+	res.LedgerIDMatches = true
 
 	lrbID, foundAtDepth, err := clnt.CheckTransactionIDInLRB(ssData.branchID, 0)
 	glb.AssertNoError(err)
 	glb.Infof("\n-----------------------\nlatest reliable branch (LRB) is %s", lrbID.String())
+	res.LRBID = lrbID.StringHex()
+	res.Included = foundAtDepth >= 0
 	if foundAtDepth >= 0 {
 		glb.Infof("the snapshot:")
 		glb.Infof("      - is INCLUDED in the current LRB of the network. It CAN BE USED to start a node")
@@ -68,6 +75,15 @@ func runSnapshotCheckCmd(_ *cobra.Command, args []string) {
 	} else {
 		glb.Infof("the snapshot is NOT INCLUDED in the current LRB of the network. It CANNOT BE USED to start a node")
 	}
+	glb.PrintResult(res)
+}
+
+// snapshotCheckResult: the snapshot can be used to start a node if ledger ids match and it is included in the LRB
+type snapshotCheckResult struct {
+	*snapshotResult
+	LedgerIDMatches bool   `json:"ledger_id_matches"`
+	LRBID           string `json:"lrbid,omitempty"`
+	Included        bool   `json:"included"`
 }
 
 type _snapshotFileData struct {
@@ -3,7 +3,6 @@ package snapshot_cmd
 import (
 	"context"
 	"io"
-	"os"
 	"strconv"
 
 	"github.com/lunfardo314/proxima/global"
@@ -32,7 +31,7 @@ func runSnapshotCmd(_ *cobra.Command, args []string) {
 
 	console := io.Discard
 	if glb.IsVerbose() {
-		console = os.Stdout
+		console = glb.Console()
 	}
 
 	slotsBackFromLRB := defaultSlotsBackFromLRB
@@ -50,4 +49,5 @@ func runSnapshotCmd(_ *cobra.Command, args []string) {
 	glb.Infof("latest reliable state has been saved to the snapshot file %s", fname)
 	glb.Infof("branch data:\n%s", snapshotBranch.LinesVerbose("   ").String())
 	glb.Infof("%s", stats.Lines("     ").String())
+	glb.PrintResult(newSnapshotResult(fname, "", snapshotBranch.Stem.ID.TransactionID(), &snapshotBranch.RootRecord, stats.ByPartition))
 }
@@ -48,9 +48,10 @@ func runSnapshotInfoCmd(_ *cobra.Command, args []string) {
 	glb.Infof("root record:\n%s", kvStream.RootRecord.Lines("    ").String())
 	glb.Infof("ledger id:\n%s", kvStream.LedgerIDParams.Lines("    ").String())
 
+	var counters map[byte]int
 	switch glb.VerbosityLevel() {
 	case 1:
-		counters := make(map[byte]int)
+		counters = make(map[byte]int)
 		total := 0
 		for pair := range kvStream.InChan {
 			counters[pair.Key[0]] = counters[pair.Key[0]] + 1
@@ -64,10 +65,11 @@ func runSnapshotInfoCmd(_ *cobra.Command, args []string) {
 	case 2:
 		counter := 0
 		for pair := range kvStream.InChan {
-			_outKVPair(pair.Key, pair.Value, counter, os.Stdout)
+			_outKVPair(pair.Key, pair.Value, counter, glb.Console())
 			counter++
 		}
 	}
+	glb.PrintResult(newSnapshotResult(fname, kvStream.Header.Version, kvStream.BranchID, &kvStream.RootRecord, counters))
 }
 
 func findLatestSnapshotFile() (string, bool) {
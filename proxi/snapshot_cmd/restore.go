@@ -4,16 +4,13 @@ import (
 	"encoding/hex"
 	"fmt"
 	"io"
-	"os"
 	"time"
 
-	"github.com/dgraph-io/badger/v4"
 	"github.com/lunfardo314/proxima/global"
 	"github.com/lunfardo314/proxima/ledger"
 	"github.com/lunfardo314/proxima/ledger/multistate"
 	"github.com/lunfardo314/proxima/proxi/glb"
 	"github.com/lunfardo314/proxima/util"
-	"github.com/lunfardo314/unitrie/adaptors/badger_adaptor"
 	"github.com/lunfardo314/unitrie/common"
 	"github.com/lunfardo314/unitrie/immutable"
 	"github.com/spf13/cobra"
@@ -72,8 +69,7 @@ func runRestoreCmd(_ *cobra.Command, _ []string) {
 
 	start := time.Now()
 
-	stateDb := badger_adaptor.MustCreateOrOpenBadgerDB(global.MultiStateDBName, badger.DefaultOptions(global.MultiStateDBName))
-	stateStore := badger_adaptor.New(stateDb)
+	stateStore := glb.MustOpenDB(global.MultiStateDBName)
 	defer func() { _ = stateStore.Close() }()
 
 	emptyRoot, err := multistate.CommitEmptyRootWithLedgerIdentity(kvStream.LedgerIDData, stateStore)
@@ -88,7 +84,7 @@ func runRestoreCmd(_ *cobra.Command, _ []string) {
 
 	console := io.Discard
 	if glb.IsVerbose() {
-		console = os.Stdout
+		console = glb.Console()
 	}
 	total := 0
 	verbosityLevel := glb.VerbosityLevel()
@@ -141,9 +137,16 @@ func runRestoreCmd(_ *cobra.Command, _ []string) {
 		"inconsistency: final root %s is not equal to the root in the root record %s",
 		lastRoot.String(), kvStream.RootRecord.Root.String())
 
+	if upgradesYAML := multistate.LibraryUpgradesBytesFromRoot(stateStore, lastRoot); len(upgradesYAML) > 0 {
+		upgrades, err := ledger.ParseLibraryUpgradesYAML(upgradesYAML)
+		glb.AssertNoError(err)
+		glb.Infof("ledger library upgrades committed in the state:\n%s", upgrades.Lines("    ").String())
+	}
+
 	glb.Infof("Success\nTotal %d records. By type:", total)
 	for _, k := range util.KeysSorted(counters, func(k1, k2 byte) bool { return k1 < k2 }) {
 		glb.Infof("    %s: %d", multistate.PartitionToString(k), counters[k])
 	}
 	glb.Infof("it took %v, %d records/sec", time.Since(start), time.Duration(total)*time.Second/time.Since(start))
+	glb.PrintResult(newSnapshotResult(fname, kvStream.Header.Version, kvStream.BranchID, &kvStream.RootRecord, counters))
 }
@@ -1,11 +1,39 @@
 package snapshot_cmd
 
 import (
+	"github.com/lunfardo314/proxima/ledger/base"
+	"github.com/lunfardo314/proxima/ledger/multistate"
 	"github.com/lunfardo314/proxima/proxi/glb"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
 
+// snapshotResult is the snapshot file in the results of commands
+type snapshotResult struct {
+	File          string                         `json:"file"`
+	FormatVersion string                         `json:"format_version,omitempty"`
+	BranchID      string                         `json:"branch_id"`
+	RootRecord    *multistate.RootRecordJSONAble `json:"root_record"`
+	// number of records by partition
+	Records map[string]int `json:"records,omitempty"`
+}
+
+func newSnapshotResult(fname, fmtVersion string, branchID base.TransactionID, rr *multistate.RootRecord, counters map[byte]int) *snapshotResult {
+	ret := &snapshotResult{
+		File:          fname,
+		FormatVersion: fmtVersion,
+		BranchID:      branchID.StringHex(),
+		RootRecord:    rr.JSONAble(),
+	}
+	if len(counters) > 0 {
+		ret.Records = make(map[string]int, len(counters))
+		for k, n := range counters {
+			ret.Records[multistate.PartitionToString(k)] = n
+		}
+	}
+	return ret
+}
+
 func Init() *cobra.Command {
 	snapshotCmd := &cobra.Command{
 		Use:   "snapshot [<subcommand>]",
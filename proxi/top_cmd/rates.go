@@ -0,0 +1,133 @@
+package top_cmd
+
+import (
+	"sync"
+	"time"
+
+	"github.com/lunfardo314/proxima/api"
+)
+
+// txRates counts vertices of the DAG vertex stream in the sliding window
+
+type (
+	txRates struct {
+		mutex  sync.Mutex
+		window time.Duration
+		// hex-encoded ID of the own sequencer. Empty if sequencer is not configured on the node
+		ownSeqID string
+		records  []vertexRecord
+		total    txRatesData
+		err      error
+	}
+
+	vertexRecord struct {
+		when      time.Time
+		sequencer bool
+		branch    bool
+		own       bool
+		inflation uint64
+	}
+
+	txRatesData struct {
+		Transactions uint64 `json:"transactions"`
+		Sequencer    uint64 `json:"sequencer"`
+		Branches     uint64 `json:"branches"`
+		Own          uint64 `json:"own"`
+		OwnInflation uint64 `json:"own_inflation"`
+	}
+
+	// txRatesResult is per second in the window, except totals
+	txRatesResult struct {
+		WindowSec   int     `json:"window_sec"`
+		TxPerSec    float64 `json:"tx_per_sec"`
+		SeqTxPerSec float64 `json:"seq_tx_per_sec"`
+		// branches and inflation of the own sequencer in the window
+		Branches     int    `json:"branches"`
+		Own          int    `json:"own"`
+		OwnInflation uint64 `json:"own_inflation"`
+		// since the start of the stream
+		Total       txRatesData `json:"total"`
+		StreamError string      `json:"stream_error,omitempty"`
+	}
+)
+
+func newTxRates(window time.Duration, ownSeqID string) *txRates {
+	return &txRates{
+		window:   window,
+		ownSeqID: ownSeqID,
+		records:  make([]vertexRecord, 0),
+	}
+}
+
+func (r *txRates) add(v *api.VertexWithDependencies, now time.Time) {
+	rec := vertexRecord{
+		when:      now,
+		sequencer: v.SequencerID != "",
+		branch:    v.StemInputTxIndex != nil,
+		own:       r.ownSeqID != "" && v.SequencerID == r.ownSeqID,
+	}
+	if rec.own {
+		rec.inflation = v.TotalInflation
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.records = append(r.records, rec)
+	r.total.Transactions++
+	if rec.sequencer {
+		r.total.Sequencer++
+	}
+	if rec.branch {
+		r.total.Branches++
+	}
+	if rec.own {
+		r.total.Own++
+		r.total.OwnInflation += rec.inflation
+	}
+	r.purge(now)
+}
+
+func (r *txRates) setError(err error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.err = err
+}
+
+func (r *txRates) purge(now time.Time) {
+	i := 0
+	for ; i < len(r.records) && now.Sub(r.records[i].when) > r.window; i++ {
+	}
+	r.records = r.records[i:]
+}
+
+func (r *txRates) result(now time.Time) *txRatesResult {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.purge(now)
+	ret := &txRatesResult{
+		WindowSec: int(r.window / time.Second),
+		Total:     r.total,
+	}
+	var numSeq int
+	for _, rec := range r.records {
+		if rec.sequencer {
+			numSeq++
+		}
+		if rec.branch {
+			ret.Branches++
+		}
+		if rec.own {
+			ret.Own++
+			ret.OwnInflation += rec.inflation
+		}
+	}
+	ret.TxPerSec = float64(len(r.records)) / r.window.Seconds()
+	ret.SeqTxPerSec = float64(numSeq) / r.window.Seconds()
+	if r.err != nil {
+		ret.StreamError = r.err.Error()
+	}
+	return ret
+}
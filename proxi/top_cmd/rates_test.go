@@ -0,0 +1,49 @@
+package top_cmd
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/lunfardo314/proxima/api"
+	"github.com/lunfardo314/proxima/util"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTxRates(t *testing.T) {
+	const ownSeqID = "aa"
+	r := newTxRates(10*time.Second, ownSeqID)
+	start := time.Now()
+
+	res := r.result(start)
+	require.EqualValues(t, 10, res.WindowSec)
+	require.EqualValues(t, 0, res.TxPerSec)
+
+	for i := 0; i < 10; i++ {
+		r.add(&api.VertexWithDependencies{ID: "01"}, start.Add(time.Duration(i)*time.Second))
+	}
+	r.add(&api.VertexWithDependencies{ID: "02", SequencerID: "bb"}, start.Add(9*time.Second))
+	r.add(&api.VertexWithDependencies{ID: "03", SequencerID: ownSeqID, TotalInflation: 100, StemInputTxIndex: util.Ref(byte(0))}, start.Add(9*time.Second))
+
+	res = r.result(start.Add(9 * time.Second))
+	require.EqualValues(t, 1.2, res.TxPerSec)
+	require.EqualValues(t, 0.2, res.SeqTxPerSec)
+	require.EqualValues(t, 1, res.Branches)
+	require.EqualValues(t, 1, res.Own)
+	require.EqualValues(t, 100, res.OwnInflation)
+	require.EqualValues(t, 12, res.Total.Transactions)
+
+	// older records leave the window, totals remain
+	res = r.result(start.Add(15 * time.Second))
+	require.EqualValues(t, 0.7, res.TxPerSec)
+	require.EqualValues(t, 12, res.Total.Transactions)
+	require.EqualValues(t, 100, res.Total.OwnInflation)
+
+	res = r.result(start.Add(time.Minute))
+	require.EqualValues(t, 0, res.TxPerSec)
+	require.EqualValues(t, 0, res.Own)
+	require.Equal(t, "", res.StreamError)
+
+	r.setError(errors.New("closed"))
+	require.Equal(t, "closed", r.result(start.Add(time.Minute)).StreamError)
+}
@@ -0,0 +1,275 @@
+package top_cmd
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"sort"
+	"syscall"
+	"time"
+
+	"github.com/lunfardo314/proxima/api"
+	"github.com/lunfardo314/proxima/core/core_modules/tippool"
+	"github.com/lunfardo314/proxima/global"
+	"github.com/lunfardo314/proxima/ledger"
+	"github.com/lunfardo314/proxima/ledger/base"
+	"github.com/lunfardo314/proxima/proxi/glb"
+	"github.com/lunfardo314/proxima/util"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+type (
+	// topSnapshot is the state of the node at one refresh. In JSON mode it is printed as one line
+	topSnapshot struct {
+		Time      int64                                             `json:"time"`
+		Node      *global.NodeInfo                                  `json:"node,omitempty"`
+		Sync      *api.SyncInfo                                     `json:"sync,omitempty"`
+		LRB       *lrbSnapshot                                      `json:"lrb,omitempty"`
+		Peers     *api.PeersInfo                                    `json:"peers,omitempty"`
+		Tips      map[string]tippool.LatestSequencerTipDataJSONAble `json:"tips,omitempty"`
+		Sequencer *api.SequencerStats                               `json:"sequencer,omitempty"`
+		Rates     *txRatesResult                                    `json:"rates"`
+		// errors of API calls during the refresh
+		Errors []string `json:"errors,omitempty"`
+	}
+
+	lrbSnapshot struct {
+		ID            string `json:"id"`
+		Slot          uint32 `json:"slot"`
+		SlotsBack     int    `json:"slots_back"`
+		CoverageDelta uint64 `json:"coverage_delta"`
+		Supply        uint64 `json:"supply"`
+		SlotInflation uint64 `json:"slot_inflation"`
+		Healthy       bool   `json:"healthy"`
+	}
+)
+
+const (
+	defaultRefreshSec = 2
+	ratesWindow       = 30 * time.Second
+	maxDisplayedPeers = 20
+	maxDisplayedTips  = 20
+)
+
+var refreshSec int
+
+func Init() *cobra.Command {
+	topCmd := &cobra.Command{
+		Use:   "top",
+		Short: "live dashboard of the node in the terminal",
+		Long: `live dashboard of the node in the terminal: sync status, latest reliable branch, peers, sequencer tips,
+own sequencer and transaction rates. It is fed by the node API and by the DAG vertex stream. Exit with Ctrl-C.
+With '--output json' prints one line of JSON per refresh`,
+		Args: cobra.NoArgs,
+		PersistentPreRun: func(_ *cobra.Command, _ []string) {
+			glb.ReadInConfig()
+		},
+		Run: runTopCmd,
+	}
+	topCmd.PersistentFlags().StringP("config", "c", "", "proxi config profile name")
+	err := viper.BindPFlag("config", topCmd.PersistentFlags().Lookup("config"))
+	glb.AssertNoError(err)
+
+	topCmd.PersistentFlags().String("api.endpoint", "", "<DNS name>:port")
+	err = viper.BindPFlag("api.endpoint", topCmd.PersistentFlags().Lookup("api.endpoint"))
+	glb.AssertNoError(err)
+
+	topCmd.PersistentFlags().IntVar(&refreshSec, "refresh", defaultRefreshSec, "refresh period in seconds")
+
+	topCmd.InitDefaultHelpCmd()
+	return topCmd
+}
+
+func runTopCmd(_ *cobra.Command, _ []string) {
+	glb.InitLedgerFromNode()
+	glb.Assertf(refreshSec > 0, "refresh period must be positive")
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer cancel()
+
+	clnt := glb.GetClient()
+	nodeInfo, err := clnt.GetNodeInfo()
+	glb.AssertNoError(err)
+
+	ownSeqID := ""
+	if nodeInfo.Sequencer != nil {
+		ownSeqID = nodeInfo.Sequencer.StringHex()
+	}
+	rates := newTxRates(ratesWindow, ownSeqID)
+	go func() {
+		err := clnt.StreamDAGVertices(ctx, func(v *api.VertexWithDependencies) bool {
+			rates.add(v, time.Now())
+			return true
+		})
+		if err != nil {
+			rates.setError(err)
+		}
+	}()
+
+	if !glb.IsJSONOutput() {
+		// hide cursor while running
+		fmt.Print("\033[?25l")
+		defer fmt.Print("\033[?25h\n")
+	}
+	ticker := time.NewTicker(time.Duration(refreshSec) * time.Second)
+	defer ticker.Stop()
+
+	for {
+		snap := takeSnapshot(rates)
+		if glb.IsJSONOutput() {
+			glb.PrintResult(snap)
+		} else {
+			// clear the screen and write the whole frame at once
+			_, _ = os.Stdout.Write(append([]byte("\033[H\033[2J"), renderSnapshot(snap).Bytes()...))
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func takeSnapshot(rates *txRates) *topSnapshot {
+	clnt := glb.GetClient()
+	ret := &topSnapshot{
+		Time:  time.Now().Unix(),
+		Rates: rates.result(time.Now()),
+	}
+	var err error
+	addErr := func(what string, err error) {
+		ret.Errors = append(ret.Errors, fmt.Sprintf("%s: %v", what, err))
+	}
+	if ret.Node, err = clnt.GetNodeInfo(); err != nil {
+		addErr("node info", err)
+	}
+	if ret.Sync, err = clnt.GetSyncInfo(); err != nil {
+		addErr("sync info", err)
+	}
+	if rr, branchID, err := clnt.GetLatestReliableBranch(); err != nil {
+		addErr("LRB", err)
+	} else {
+		ret.LRB = &lrbSnapshot{
+			ID:            branchID.StringHex(),
+			Slot:          uint32(branchID.Slot()),
+			SlotsBack:     int(ledger.TimeNow().Slot - branchID.Slot()),
+			CoverageDelta: rr.CoverageDelta,
+			Supply:        rr.Supply,
+			SlotInflation: rr.SlotInflation,
+			Healthy:       global.IsHealthyCoverageDelta(rr.CoverageDelta, rr.Supply, global.FractionHealthyBranch),
+		}
+	}
+	if ret.Peers, err = clnt.GetPeersInfo(); err != nil {
+		addErr("peers", err)
+	}
+	if ret.Tips, err = clnt.GetLastKnownSequencerData(); err != nil {
+		addErr("sequencer tips", err)
+	}
+	if ret.Sequencer, err = clnt.GetSequencerStats(); err != nil {
+		addErr("sequencer stats", err)
+	}
+	return ret
+}
+
+func renderSnapshot(snap *topSnapshot) *bytes.Buffer {
+	buf := new(bytes.Buffer)
+	out := func(format string, args ...any) {
+		_, _ = fmt.Fprintf(buf, format+"\n", args...)
+	}
+	out("proxi top: %s, refresh every %ds, Ctrl-C to exit", viper.GetString("api.endpoint"), refreshSec)
+	out("%s, ledger time %s", time.Unix(snap.Time, 0).Format(time.DateTime), ledger.TimeNow().String())
+
+	if snap.Node != nil {
+		seqStr := "<none>"
+		if snap.Node.Sequencer != nil {
+			seqStr = snap.Node.Sequencer.StringShort()
+		}
+		out("\nNODE %s, version %s, sequencer: %s, clock offset: %v",
+			snap.Node.ID.String(), snap.Node.Version, seqStr, time.Duration(snap.Node.ClockOffsetNs))
+	}
+	if snap.Sync != nil {
+		out("SYNC synced: %v, current slot: %d, LRB slot: %d", snap.Sync.Synced, snap.Sync.CurrentSlot, snap.Sync.LrbSlot)
+		if f := snap.Sync.Fork; f != nil && (f.Detected || f.OnMinority) {
+			out("     FORK detected: %v, on minority: %v, since slot %d (%d slots)", f.Detected, f.OnMinority, f.SinceSlot, f.NumSlots)
+		}
+	}
+	if snap.LRB != nil {
+		out("LRB  slot %d (%d slots back), coverage delta: %s, healthy: %v, supply: %s, slot inflation: %s",
+			snap.LRB.Slot, snap.LRB.SlotsBack, util.Th(snap.LRB.CoverageDelta), snap.LRB.Healthy,
+			util.Th(snap.LRB.Supply), util.Th(snap.LRB.SlotInflation))
+	}
+
+	r := snap.Rates
+	out("\nTX RATES (last %ds): %.2f tx/s, %.2f seq tx/s, %d branches. Total since start: %d tx, %d seq tx, %d branches",
+		r.WindowSec, r.TxPerSec, r.SeqTxPerSec, r.Branches, r.Total.Transactions, r.Total.Sequencer, r.Total.Branches)
+	if r.StreamError != "" {
+		out("     DAG vertex stream: %s", r.StreamError)
+	}
+
+	if s := snap.Sequencer; s != nil && s.SequencerID != "" {
+		out("\nOWN SEQUENCER %s", s.SequencerID)
+		out("     milestones: %d, branches: %d, targets: %d, backlog: %d outputs, own milestones: %d",
+			s.Milestones, s.Branches, s.Targets, s.BacklogSize, s.OwnMilestones)
+		out("     inflation last %ds: %s on %d milestones, since start: %s on %d milestones",
+			r.WindowSec, util.Th(r.OwnInflation), r.Own, util.Th(r.Total.OwnInflation), r.Total.Own)
+		strategies := util.KeysSorted(s.Proposals, func(k1, k2 string) bool { return k1 < k2 })
+		line := "     proposals (best/all):"
+		for _, st := range strategies {
+			line += fmt.Sprintf(" %s: %d/%d", st, s.BestProposals[st], s.Proposals[st])
+		}
+		out("%s", line)
+	}
+
+	if snap.Peers != nil {
+		alive := 0
+		for i := range snap.Peers.Peers {
+			if snap.Peers.Peers[i].IsAlive {
+				alive++
+			}
+		}
+		out("\nPEERS %d, alive %d, blacklisted %d", len(snap.Peers.Peers), alive, len(snap.Peers.Blacklist))
+		peers := snap.Peers.Peers
+		sort.Slice(peers, func(i, j int) bool { return peers[i].ID < peers[j].ID })
+		for i := range util.TrimSlice(peers, maxDisplayedPeers) {
+			p := &peers[i]
+			lastHB := "never"
+			if p.LastHeartbeatReceived > 0 {
+				lastHB = time.Since(time.Unix(0, p.LastHeartbeatReceived)).Truncate(time.Millisecond).String() + " ago"
+			}
+			out("     %-52s alive: %-5v static: %-5v HB: %5d, last %s, HB diff median: %v, clock diff median: %v",
+				p.ID, p.IsAlive, p.IsStatic, p.NumIncomingHB, lastHB,
+				time.Duration(p.HBMsgDifferencesQuartiles[1]), time.Duration(p.ClockDifferencesQuartiles[1]))
+		}
+		if len(peers) > maxDisplayedPeers {
+			out("     ... and %d more", len(peers)-maxDisplayedPeers)
+		}
+	}
+
+	if len(snap.Tips) > 0 {
+		out("\nSEQUENCER TIPS %d", len(snap.Tips))
+		seqIDs := util.KeysSorted(snap.Tips, func(k1, k2 string) bool {
+			return snap.Tips[k1].LastActivityUnixNano > snap.Tips[k2].LastActivityUnixNano
+		})
+		for _, seqID := range util.TrimSlice(seqIDs, maxDisplayedTips) {
+			tip := snap.Tips[seqID]
+			tipStr := tip.LatestMilestoneTxID
+			if txid, err := base.TransactionIDFromHexString(tip.LatestMilestoneTxID); err == nil {
+				tipStr = txid.StringShort()
+			}
+			out("     %s  milestones: %6d, latest: %s, active %v ago",
+				seqID[:16]+"..", tip.MilestoneCount, tipStr,
+				time.Since(time.Unix(0, tip.LastActivityUnixNano)).Truncate(time.Millisecond))
+		}
+		if len(seqIDs) > maxDisplayedTips {
+			out("     ... and %d more", len(seqIDs)-maxDisplayedTips)
+		}
+	}
+
+	for _, e := range snap.Errors {
+		out("\nERROR %s", e)
+	}
+	return buf
+}
@@ -4,6 +4,15 @@ import (
 	"github.com/spf13/cobra"
 )
 
+// ledgerIDResult is the ledger ID file in the results of commands
+type ledgerIDResult struct {
+	File string `json:"file"`
+	Hash string `json:"hash"`
+	Size int    `json:"size,omitempty"`
+	// nil if wallet is not available
+	GenesisKeyMatches *bool `json:"genesis_key_matches,omitempty"`
+}
+
 func Init() *cobra.Command {
 	genCmd := &cobra.Command{
 		Use:   "util",
@@ -18,6 +27,8 @@ func Init() *cobra.Command {
 		genIDCmd(),
 		verifyIDCmd(),
 		compileIDCmd(),
+		compileUpgradesCmd(),
+		verifyUpgradesCmd(),
 		initParseTx(),
 		initParseBytecode(),
 	)
@@ -52,4 +52,5 @@ func runGenCompileLedgerIDCommand(_ *cobra.Command, _ []string) {
 
 	err = os.WriteFile(glb.LedgerIDFileName, yamlData1, 0755)
 	glb.AssertNoError(err)
+	glb.PrintResult(&ledgerIDResult{File: glb.LedgerIDFileName, Hash: hex.EncodeToString(h[:])})
 }
@@ -0,0 +1,75 @@
+package util_cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/lunfardo314/proxima/ledger"
+	"github.com/lunfardo314/proxima/proxi/glb"
+	"github.com/spf13/cobra"
+)
+
+func compileUpgradesCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:  "compile_upgrades [<file name>]",
+		Args: cobra.MaximumNArgs(1),
+		Short: fmt.Sprintf("compiles schedule of ledger library upgrades (default file '%s') on top of the ledger identity in '%s'. "+
+			"Writes hashes of all library versions to the file", glb.LibraryUpgradesFileName, glb.LedgerIDFileName),
+		Run: runCompileUpgradesCmd,
+	}
+	return cmd
+}
+
+type (
+	// upgradesResult is the schedule of ledger library upgrades in the results of commands
+	upgradesResult struct {
+		File     string                 `json:"file"`
+		Versions []libraryVersionResult `json:"versions"`
+	}
+
+	libraryVersionResult struct {
+		Version        int    `json:"version"`
+		ActivationSlot uint32 `json:"activation_slot"`
+		Hash           string `json:"hash"`
+		NumFunctions   int    `json:"num_functions"`
+	}
+)
+
+func newUpgradesResult(fname string, upgrades *ledger.LibraryUpgrades) *upgradesResult {
+	ret := &upgradesResult{
+		File:     fname,
+		Versions: make([]libraryVersionResult, len(upgrades.Upgrades)),
+	}
+	for i, up := range upgrades.Upgrades {
+		ret.Versions[i] = libraryVersionResult{
+			Version:        up.Version,
+			ActivationSlot: up.ActivationSlot,
+			Hash:           up.Hash,
+			NumFunctions:   len(up.Functions),
+		}
+	}
+	return ret
+}
+
+func upgradesFileName(args []string) string {
+	if len(args) > 0 {
+		return args[0]
+	}
+	return glb.LibraryUpgradesFileName
+}
+
+func runCompileUpgradesCmd(_ *cobra.Command, args []string) {
+	fname := upgradesFileName(args)
+	idData, err := os.ReadFile(glb.LedgerIDFileName)
+	glb.AssertNoError(err)
+	upgradesYAML, err := os.ReadFile(fname)
+	glb.AssertNoError(err)
+
+	upgrades, err := ledger.CompileLibraryUpgradesYAML(idData, upgradesYAML)
+	glb.AssertNoError(err)
+
+	err = os.WriteFile(fname, upgrades.YAML(), 0644)
+	glb.AssertNoError(err)
+	glb.Infof("compiled library upgrades have been saved to '%s':\n%s", fname, upgrades.Lines("    ").String())
+	glb.PrintResult(newUpgradesResult(fname, upgrades))
+}
@@ -32,7 +32,12 @@ func runGenHostIdCmd(_ *cobra.Command, _ []string) {
 	util.AssertNoError(err)
 
 	hid, err := peer.IDFromPrivateKey(pklpp)
+	glb.AssertNoError(err)
 	glb.Infof("------>")
 	glb.Infof("libp2p host private key: %s", hex.EncodeToString(privateKey))
 	glb.Infof("libp2p host id: %s", hid.String())
+	glb.PrintResult(&struct {
+		PrivateKey string `json:"private_key"`
+		HostID     string `json:"host_id"`
+	}{hex.EncodeToString(privateKey), hid.String()})
 }
@@ -33,7 +33,7 @@ func genIDCmd() *cobra.Command {
 func runGenLedgerIDCommand(_ *cobra.Command, _ []string) {
 	if glb.FileExists(glb.LedgerIDFileName) {
 		if !glb.YesNoPrompt(fmt.Sprintf("file '%s' already exists. Overwrite?", glb.LedgerIDFileName), false) {
-			os.Exit(0)
+			glb.Abort()
 		}
 	}
 	privKey := glb.MustGetPrivateKey()
@@ -50,4 +50,5 @@ func runGenLedgerIDCommand(_ *cobra.Command, _ []string) {
 	h := lib.LibraryHash()
 	glb.Infof("library hash: %s", hex.EncodeToString(h[:]))
 	glb.Infof("ledger ID parameters:\n--------------\n%s\n", idParams.Lines("    ").String())
+	glb.PrintResult(&ledgerIDResult{File: glb.LedgerIDFileName, Hash: hex.EncodeToString(h[:])})
 }
@@ -39,4 +39,8 @@ func runParseBytecode(_ *cobra.Command, args []string) {
 	glb.AssertNoError(err)
 
 	glb.Infof("Parsed bytecode:\n    string: %s\n    source: %s", c.String(), c.Source())
+	glb.PrintResult(&struct {
+		String string `json:"string"`
+		Source string `json:"source"`
+	}{c.String(), c.Source()})
 }
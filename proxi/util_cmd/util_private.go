@@ -33,4 +33,9 @@ func runGenEd25519Cmd(_ *cobra.Command, _ []string) {
 	glb.Infof("Priv: %s", hex.EncodeToString(privateKey))
 	glb.Infof("Pub: %s", hex.EncodeToString(publicKey))
 	glb.Infof("Address: %s", addr.String())
+	glb.PrintResult(&struct {
+		PrivateKey string `json:"private_key"`
+		PublicKey  string `json:"public_key"`
+		Address    string `json:"address"`
+	}{hex.EncodeToString(privateKey), hex.EncodeToString(publicKey), addr.String()})
 }
@@ -39,8 +39,11 @@ func runGenVerifyLedgerIDCommand(_ *cobra.Command, _ []string) {
 	h := lib.LibraryHash()
 	glb.Infof("hash of the library: %s", hex.EncodeToString(h[:]))
 
+	res := &ledgerIDResult{File: glb.LedgerIDFileName, Hash: hex.EncodeToString(h[:]), Size: len(yamlData)}
 	if pk, ok := glb.GetPrivateKey(); ok {
-		if idParams.GenesisControllerPublicKey.Equal(pk.Public()) {
+		matches := idParams.GenesisControllerPublicKey.Equal(pk.Public())
+		res.GenesisKeyMatches = &matches
+		if matches {
 			glb.Infof("Genesis public key MATCHES public key of the wallet")
 		} else {
 			glb.Infof("Genesis public key DOES NOT MATCH public key of the wallet")
@@ -48,4 +51,5 @@ func runGenVerifyLedgerIDCommand(_ *cobra.Command, _ []string) {
 	}
 	glb.Infof("ledger ID data in '%s' is OK. Size: %d bytes\nMain ledger parameters:\n-------------------\n%s",
 		glb.LedgerIDFileName, len(yamlData), idParams.Lines("      ").String())
+	glb.PrintResult(res)
 }
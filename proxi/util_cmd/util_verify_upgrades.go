@@ -0,0 +1,57 @@
+package util_cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/lunfardo314/proxima/ledger"
+	"github.com/lunfardo314/proxima/ledger/base"
+	"github.com/lunfardo314/proxima/proxi/glb"
+	"github.com/spf13/cobra"
+)
+
+func verifyUpgradesCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:  "verify_upgrades [<file name>]",
+		Args: cobra.MaximumNArgs(1),
+		Short: fmt.Sprintf("checks that schedule of ledger library upgrades (default file '%s') is compiled and consistent "+
+			"with the ledger identity in '%s'", glb.LibraryUpgradesFileName, glb.LedgerIDFileName),
+		Run: runVerifyUpgradesCmd,
+	}
+	return cmd
+}
+
+func runVerifyUpgradesCmd(_ *cobra.Command, args []string) {
+	fname := upgradesFileName(args)
+	idData, err := os.ReadFile(glb.LedgerIDFileName)
+	glb.AssertNoError(err)
+	upgradesYAML, err := os.ReadFile(fname)
+	glb.AssertNoError(err)
+
+	upgrades, err := ledger.ParseLibraryUpgradesYAML(upgradesYAML)
+	glb.AssertNoError(err)
+	glb.AssertNoError(upgrades.CheckCompiled())
+	// compiles all versions and checks hashes
+	_, err = ledger.CompileLibraryUpgrades(idData, upgrades)
+	glb.AssertNoError(err)
+
+	ledger.MustInitSingleton(idData)
+	nowSlot := ledger.TimeNow().Slot
+	glb.Infof("library upgrades in '%s' are consistent with the ledger identity in '%s':", fname, glb.LedgerIDFileName)
+	for _, up := range upgrades.Upgrades {
+		activation := ledger.ClockTime(base.NewLedgerTime(base.Slot(up.ActivationSlot), 0))
+		status := "scheduled"
+		if base.Slot(up.ActivationSlot) <= nowSlot {
+			status = "ACTIVE"
+		}
+		glb.Infof("    version %d: %s, activation slot %d (%s), hash: %s, %d new functions",
+			up.Version, status, up.ActivationSlot, activation.Format("2006-01-02 15:04:05 MST"), up.Hash, len(up.Functions))
+		if up.Description != "" {
+			glb.Infof("        %s", up.Description)
+		}
+		for _, f := range up.Functions {
+			glb.Verbosef("        %s/%d", f.Sym, f.NumArgs)
+		}
+	}
+	glb.PrintResult(newUpgradesResult(fname, upgrades))
+}
@@ -6,6 +6,7 @@ import (
 	"errors"
 	"fmt"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/lunfardo314/proxima/core/attacher"
@@ -38,6 +39,8 @@ type (
 		MustEnsureBranch(txid base.TransactionID) *vertex.WrappedTx
 		OwnSequencerMilestoneIn(txBytes []byte, meta *txmetadata.TransactionMetadata, txid base.TransactionID)
 		LatestReliableState() (multistate.SugaredStateReader, error)
+		// ClockSkew is the skew of the ledger time with respect to peers
+		ClockSkew() (skew time.Duration, tooBig bool)
 	}
 
 	Sequencer struct {
@@ -68,6 +71,10 @@ type (
 
 		slotData           *task.SlotData
 		wontSubmitBranchID base.TransactionID
+		// paused sequencer does not produce milestones
+		paused atomic.Bool
+		// sequencer does not produce milestones while the clock skew is too big. Accessed by the sequencer loop only
+		clockSkewAlarm bool
 
 		metrics *sequencerMetrics
 	}
@@ -302,6 +309,41 @@ func (seq *Sequencer) Stop() {
 	seq.stopFun()
 }
 
+const pausedCheckPeriod = 500 * time.Millisecond
+
+// Pause stops producing milestones without stopping the sequencer. Sequencer can be resumed
+func (seq *Sequencer) Pause() {
+	if !seq.paused.Swap(true) {
+		seq.log.Infof("sequencer has been PAUSED")
+	}
+}
+
+// Resume resumes producing milestones after Pause
+func (seq *Sequencer) Resume() {
+	if seq.paused.Swap(false) {
+		seq.log.Infof("sequencer has been RESUMED")
+	}
+}
+
+func (seq *Sequencer) IsPaused() bool {
+	return seq.paused.Load()
+}
+
+// clockSkewTooBig checks if the ledger time of the node is too far from the time of peers.
+// Milestones produced with the wrong clock are attached late or rejected by other nodes
+func (seq *Sequencer) clockSkewTooBig() bool {
+	skew, tooBig := seq.ClockSkew()
+	if tooBig != seq.clockSkewAlarm {
+		if tooBig {
+			seq.log.Errorf("clock skew %v with respect to peers is too big. Sequencer STOPS producing milestones until the clock is fixed", skew)
+		} else {
+			seq.log.Infof("clock skew %v is back within limits. Sequencer RESUMES producing milestones", skew)
+		}
+		seq.clockSkewAlarm = tooBig
+	}
+	return tooBig
+}
+
 func (seq *Sequencer) Backlog() *backlog.TagAlongBacklog {
 	return seq.backlog
 }
@@ -339,6 +381,14 @@ func (seq *Sequencer) sequencerLoop() {
 		case <-seq.Ctx().Done():
 			return
 		default:
+			if seq.paused.Load() {
+				time.Sleep(pausedCheckPeriod)
+				continue
+			}
+			if seq.clockSkewTooBig() {
+				time.Sleep(pausedCheckPeriod)
+				continue
+			}
 			start := time.Now()
 			if !seq.doSequencerStep() {
 				return
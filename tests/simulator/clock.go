@@ -0,0 +1,55 @@
+package simulator
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Clock is the clock of the simulated network. It is injected into the Network and decides when
+// messages in flight are delivered. It runs with the speed of the local clock, but it can be moved forward
+// without waiting, which delivers delayed messages earlier. It does not move ledger time: nodes take
+// ledger time from the local clock
+type Clock struct {
+	offset   atomic.Int64
+	mutex    sync.Mutex
+	onChange []func()
+}
+
+func newClock() *Clock {
+	return &Clock{}
+}
+
+// Now returns current time of the clock
+func (c *Clock) Now() time.Time {
+	return time.Now().Add(time.Duration(c.offset.Load()))
+}
+
+// Until returns duration of the local clock until the time t of the clock
+func (c *Clock) Until(t time.Time) time.Duration {
+	return t.Sub(c.Now())
+}
+
+// Set moves the clock to the time t
+func (c *Clock) Set(t time.Time) {
+	c.mutex.Lock()
+	c.offset.Store(int64(time.Until(t)))
+	onChange := c.onChange
+	c.mutex.Unlock()
+
+	for _, fun := range onChange {
+		fun()
+	}
+}
+
+// Advance moves the clock forward
+func (c *Clock) Advance(d time.Duration) {
+	c.Set(c.Now().Add(d))
+}
+
+func (c *Clock) onClockChange(fun func()) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.onChange = append(c.onChange, fun)
+}
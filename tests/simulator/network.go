@@ -0,0 +1,273 @@
+package simulator
+
+import (
+	"container/heap"
+	"context"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+type (
+	// LinkConfig is the model of the directed link between two nodes
+	LinkConfig struct {
+		// Latency is the minimum delay of the message
+		Latency time.Duration
+		// Jitter is the maximum random delay added to the latency
+		Jitter time.Duration
+		// Loss is the probability of the message to be lost, from 0 to 1
+		Loss float64
+	}
+
+	// NetworkStats counts messages of the network
+	NetworkStats struct {
+		Sent      int
+		Delivered int
+		// Lost by the link model
+		Lost int
+		// Blocked by the partition or because one of nodes is disconnected
+		Blocked int
+	}
+
+	// Network is the in-memory transport between nodes of the simulation.
+	// Each directed link has its own random generator seeded from the seed of the network, so the same
+	// sequence of messages on the link is delayed and lost the same way in every run.
+	// Messages are delivered by one goroutine in the order of the delivery time of the simulation clock
+	Network struct {
+		mutex       sync.Mutex
+		clock       *Clock
+		seed        int64
+		numNodes    int
+		defaultLink LinkConfig
+		links       map[linkKey]*link
+		// partition group of each node. Nodes communicate only within the same group
+		group        []int
+		disconnected []bool
+		queue        messageQueue
+		seq          uint64
+		wakeup       chan struct{}
+		stats        NetworkStats
+	}
+
+	linkKey struct {
+		from, to int
+	}
+
+	link struct {
+		cfg LinkConfig
+		rnd *rand.Rand
+	}
+
+	message struct {
+		deliverAt time.Time
+		seq       uint64
+		from, to  int
+		deliver   func()
+	}
+
+	messageQueue []*message
+)
+
+func newNetwork(numNodes int, seed int64, defaultLink LinkConfig, clock *Clock) *Network {
+	ret := &Network{
+		clock:        clock,
+		seed:         seed,
+		numNodes:     numNodes,
+		defaultLink:  defaultLink,
+		links:        make(map[linkKey]*link),
+		group:        make([]int, numNodes),
+		disconnected: make([]bool, numNodes),
+		wakeup:       make(chan struct{}, 1),
+	}
+	clock.onClockChange(ret.wake)
+	return ret
+}
+
+func (n *Network) _link(from, to int) *link {
+	key := linkKey{from: from, to: to}
+	ret, found := n.links[key]
+	if !found {
+		ret = &link{
+			cfg: n.defaultLink,
+			rnd: rand.New(rand.NewSource(n.seed*1_000_003 + int64(from)*1_009 + int64(to))),
+		}
+		n.links[key] = ret
+	}
+	return ret
+}
+
+// SetLink changes model of the link between two nodes in both directions
+func (n *Network) SetLink(node1, node2 int, cfg LinkConfig) {
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+
+	n._link(node1, node2).cfg = cfg
+	n._link(node2, node1).cfg = cfg
+}
+
+// Partition splits the network into groups of nodes. Nodes not listed in any group make one more group.
+// Messages between groups are blocked. Messages already in flight are delivered
+func (n *Network) Partition(groups ...[]int) {
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+
+	for i := range n.group {
+		n.group[i] = len(groups)
+	}
+	for g, nodes := range groups {
+		for _, i := range nodes {
+			n.group[i] = g
+		}
+	}
+}
+
+// Heal removes partitions
+func (n *Network) Heal() {
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+
+	for i := range n.group {
+		n.group[i] = 0
+	}
+}
+
+// Disconnect blocks all messages from and to the node. Messages in flight to the node are dropped
+func (n *Network) Disconnect(node int) {
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+
+	n.disconnected[node] = true
+}
+
+func (n *Network) Connect(node int) {
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+
+	n.disconnected[node] = false
+}
+
+// Connected returns true if messages from node1 can reach node2
+func (n *Network) Connected(node1, node2 int) bool {
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+
+	return n._connected(node1, node2)
+}
+
+func (n *Network) _connected(node1, node2 int) bool {
+	return node1 != node2 && !n.disconnected[node1] && !n.disconnected[node2] && n.group[node1] == n.group[node2]
+}
+
+func (n *Network) Stats() NetworkStats {
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+
+	return n.stats
+}
+
+// send schedules delivery of the message according to the model of the link. Returns false if the message
+// is blocked. Lost message is reported as sent
+func (n *Network) send(from, to int, deliver func()) bool {
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+
+	n.stats.Sent++
+	if !n._connected(from, to) {
+		n.stats.Blocked++
+		return false
+	}
+	lnk := n._link(from, to)
+	// random values are drawn for each message, so that the sequence does not depend on the loss
+	lost := lnk.rnd.Float64() < lnk.cfg.Loss
+	jitter := time.Duration(lnk.rnd.Int63n(int64(lnk.cfg.Jitter) + 1))
+	if lost {
+		n.stats.Lost++
+		return true
+	}
+	n.seq++
+	heap.Push(&n.queue, &message{
+		deliverAt: n.clock.Now().Add(lnk.cfg.Latency + jitter),
+		seq:       n.seq,
+		from:      from,
+		to:        to,
+		deliver:   deliver,
+	})
+	n.wake()
+	return true
+}
+
+func (n *Network) wake() {
+	select {
+	case n.wakeup <- struct{}{}:
+	default:
+	}
+}
+
+// nextDue pops the message if it is due. Otherwise, returns how long to wait
+func (n *Network) nextDue() (*message, time.Duration) {
+	n.mutex.Lock()
+	defer n.mutex.Unlock()
+
+	if len(n.queue) == 0 {
+		return nil, time.Hour
+	}
+	if wait := n.clock.Until(n.queue[0].deliverAt); wait > 0 {
+		return nil, wait
+	}
+	msg := heap.Pop(&n.queue).(*message)
+	if n.disconnected[msg.to] {
+		n.stats.Blocked++
+		return nil, 0
+	}
+	n.stats.Delivered++
+	return msg, 0
+}
+
+// run delivers messages until the context is cancelled
+func (n *Network) run(ctx context.Context) {
+	for {
+		msg, wait := n.nextDue()
+		if msg != nil {
+			msg.deliver()
+			continue
+		}
+		if wait == 0 {
+			continue
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-n.wakeup:
+		case <-time.After(wait):
+		}
+	}
+}
+
+// messageQueue implements heap.Interface ordered by delivery time, then by sequence number
+
+func (q messageQueue) Len() int {
+	return len(q)
+}
+
+func (q messageQueue) Less(i, j int) bool {
+	if q[i].deliverAt.Equal(q[j].deliverAt) {
+		return q[i].seq < q[j].seq
+	}
+	return q[i].deliverAt.Before(q[j].deliverAt)
+}
+
+func (q messageQueue) Swap(i, j int) {
+	q[i], q[j] = q[j], q[i]
+}
+
+func (q *messageQueue) Push(x any) {
+	*q = append(*q, x.(*message))
+}
+
+func (q *messageQueue) Pop() any {
+	old := *q
+	ret := old[len(old)-1]
+	old[len(old)-1] = nil
+	*q = old[:len(old)-1]
+	return ret
+}
@@ -0,0 +1,160 @@
+package simulator
+
+import (
+	"crypto/ed25519"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/lunfardo314/proxima/core/workflow"
+	"github.com/lunfardo314/proxima/global"
+	"github.com/lunfardo314/proxima/ledger/base"
+	"github.com/lunfardo314/proxima/ledger/multistate"
+	"github.com/lunfardo314/proxima/peering"
+	"github.com/lunfardo314/proxima/sequencer"
+)
+
+// Node is a full node of the simulation: workflow with in-memory stores and, optionally, the sequencer.
+// It implements environment of the workflow
+type Node struct {
+	*global.Global
+	idx          int
+	stateStore   multistate.StateStore
+	txBytesStore global.TxBytesStore
+	peers        *simPeers
+	workflow     *workflow.Workflow
+	// sequencer can be stopped (crashed) and started again
+	seqMutex     sync.RWMutex
+	sequencer    *sequencer.Sequencer
+	seqChainID   *base.ChainID
+	seqKey       ed25519.PrivateKey
+	seqOptions   []sequencer.ConfigOption
+	seqRestarted int
+}
+
+func (n *Node) Index() int {
+	return n.idx
+}
+
+func (n *Node) Workflow() *workflow.Workflow {
+	return n.workflow
+}
+
+// Sequencer returns running sequencer or nil
+func (n *Node) Sequencer() *sequencer.Sequencer {
+	n.seqMutex.RLock()
+	defer n.seqMutex.RUnlock()
+
+	return n.sequencer
+}
+
+// LatestReliableBranch returns LRB of the node or nil if it does not exist
+func (n *Node) LatestReliableBranch() *multistate.BranchData {
+	return multistate.FindLatestReliableBranch(n.stateStore, global.FractionHealthyBranch)
+}
+
+// KnowsBranch returns true if the branch is committed in the LRB state of the node
+func (n *Node) KnowsBranch(branchID base.TransactionID) bool {
+	lrb := n.LatestReliableBranch()
+	if lrb == nil {
+		return false
+	}
+	return lrb.TxID() == branchID || multistate.MustNewReadable(n.stateStore, lrb.Root, 0).KnowsCommittedTransaction(branchID)
+}
+
+// startSequencer starts the sequencer on the chain of the node. Previous instance must be stopped
+func (n *Node) startSequencer() error {
+	n.seqMutex.Lock()
+	defer n.seqMutex.Unlock()
+
+	if n.seqChainID == nil {
+		return fmt.Errorf("node %d does not have sequencer chain", n.idx)
+	}
+	if n.sequencer != nil {
+		return fmt.Errorf("sequencer of node %d is already running", n.idx)
+	}
+	name := fmt.Sprintf("seq%d", n.idx)
+	if n.seqRestarted > 0 {
+		name = fmt.Sprintf("seq%d.%d", n.idx, n.seqRestarted)
+	}
+	opts := append([]sequencer.ConfigOption{sequencer.WithName(name)}, n.seqOptions...)
+	seq, err := sequencer.New(n.workflow, *n.seqChainID, n.seqKey, opts...)
+	if err != nil {
+		return err
+	}
+	n.seqRestarted++
+	n.sequencer = seq
+	seq.Start()
+	return nil
+}
+
+// stopSequencer stops the sequencer without any cleanup, like it crashed
+func (n *Node) stopSequencer() {
+	n.seqMutex.Lock()
+	defer n.seqMutex.Unlock()
+
+	if n.sequencer != nil {
+		n.sequencer.Stop()
+		n.sequencer = nil
+	}
+}
+
+// workflow environment
+
+func (n *Node) StateStore() multistate.StateStore {
+	return n.stateStore
+}
+
+func (n *Node) TxBytesStore() global.TxBytesStore {
+	return n.txBytesStore
+}
+
+func (n *Node) PullFromNPeers(nPeers int, txid base.TransactionID) int {
+	return n.peers.pullFromNPeers(nPeers, &peering.PullBatch{TxIDs: []base.TransactionID{txid}})
+}
+
+func (n *Node) PullWithPastConeFromNPeers(nPeers int, txid base.TransactionID, downToSlot base.Slot) int {
+	return n.peers.pullFromNPeers(nPeers, &peering.PullBatch{
+		TxIDs:              []base.TransactionID{txid},
+		WithPastCone:       true,
+		PastConeDownToSlot: downToSlot,
+	})
+}
+
+func (n *Node) GetOwnSequencerID() *base.ChainID {
+	return n.seqChainID
+}
+
+func (n *Node) EvidencePastConeSize(_ int) {}
+
+func (n *Node) EvidenceNumberOfTxDependencies(_ int) {}
+
+func (n *Node) SnapshotBranchID() base.TransactionID {
+	return base.GenesisTransactionID()
+}
+
+func (n *Node) DurationSinceLastMessageFromPeer() time.Duration {
+	return time.Since(time.Unix(0, n.peers.lastMessage.Load()))
+}
+
+func (n *Node) SelfPeerID() peer.ID {
+	return nodePeerID(n.idx)
+}
+
+func (n *Node) EvidenceTxValidationStats(_ time.Duration, _, _ int) {}
+
+func (n *Node) LatestReliableState() (multistate.SugaredStateReader, error) {
+	lrb := n.LatestReliableBranch()
+	if lrb == nil {
+		return multistate.SugaredStateReader{}, fmt.Errorf("LatestReliableState: can't find latest reliable branch")
+	}
+	return multistate.MakeSugared(multistate.MustNewReadable(n.stateStore, lrb.Root, 0)), nil
+}
+
+func (n *Node) EvidenceBranchInflationBonus(_ uint64) {}
+
+// ClockSkew is always 0: all nodes use the same local clock
+func (n *Node) ClockSkew() (time.Duration, bool) {
+	return 0, false
+}
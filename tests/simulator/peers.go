@@ -0,0 +1,169 @@
+package simulator
+
+import (
+	"fmt"
+	"math/rand"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/libp2p/go-libp2p/core/peer"
+	"github.com/lunfardo314/proxima/core/txmetadata"
+	"github.com/lunfardo314/proxima/ledger/base"
+	"github.com/lunfardo314/proxima/peering"
+)
+
+// simPeers is the network layer of one node of the simulation. It implements workflow.Peers over the Network.
+// Transactions and pull requests are delivered to the handlers of the receiving node
+type simPeers struct {
+	mutex     sync.RWMutex
+	net       *Network
+	self      int
+	all       []*simPeers
+	rnd       *rand.Rand
+	onTx      func(from peer.ID, txBytes []byte, metadata *txmetadata.TransactionMetadata, txIDPrefix base.TransactionID)
+	onPull    func(from peer.ID, txid base.TransactionID)
+	onBatch   func(from peer.ID, batch *peering.PullBatch)
+	isKnownTx func(txid base.TransactionID) bool
+	// unix nano of the last message received from peers
+	lastMessage atomic.Int64
+}
+
+func newSimPeers(net *Network, self int, seed int64) *simPeers {
+	return &simPeers{
+		net:  net,
+		self: self,
+		rnd:  rand.New(rand.NewSource(seed + int64(self))),
+	}
+}
+
+func nodePeerID(idx int) peer.ID {
+	return peer.ID(fmt.Sprintf("sim-node-%d", idx))
+}
+
+func (sp *simPeers) nodeIndex(id peer.ID) (int, bool) {
+	for i := range sp.all {
+		if nodePeerID(i) == id {
+			return i, true
+		}
+	}
+	return 0, false
+}
+
+func (sp *simPeers) OnReceiveTxBytes(fun func(from peer.ID, txBytes []byte, metadata *txmetadata.TransactionMetadata, txIDPrefix base.TransactionID)) {
+	sp.mutex.Lock()
+	defer sp.mutex.Unlock()
+
+	sp.onTx = fun
+}
+
+func (sp *simPeers) OnReceivePullTxRequest(fun func(from peer.ID, txid base.TransactionID)) {
+	sp.mutex.Lock()
+	defer sp.mutex.Unlock()
+
+	sp.onPull = fun
+}
+
+func (sp *simPeers) OnReceivePullTxBatchRequest(fun func(from peer.ID, batch *peering.PullBatch)) {
+	sp.mutex.Lock()
+	defer sp.mutex.Unlock()
+
+	sp.onBatch = fun
+}
+
+func (sp *simPeers) SetKnownTransactionFilter(fun func(txid base.TransactionID) bool) {
+	sp.mutex.Lock()
+	defer sp.mutex.Unlock()
+
+	sp.isKnownTx = fun
+}
+
+func (sp *simPeers) PeerName(id peer.ID) string {
+	return string(id)
+}
+
+func (sp *simPeers) EvidenceUsefulTx(_ peer.ID) {}
+
+func (sp *simPeers) EvidenceInvalidTx(_ peer.ID, _ string) {}
+
+func (sp *simPeers) ChargePullResponse(_ peer.ID, _ int) bool { return true }
+
+// sendTx sends the transaction to the node. Metadata is serialized, the same way as in the real network
+func (sp *simPeers) sendTx(to int, txBytes []byte, metadata *txmetadata.TransactionMetadata, txid base.TransactionID) bool {
+	metadataBytes := metadata.Bytes()
+	from := nodePeerID(sp.self)
+	target := sp.all[to]
+	return sp.net.send(sp.self, to, func() {
+		target.lastMessage.Store(time.Now().UnixNano())
+		target.mutex.RLock()
+		onTx := target.onTx
+		target.mutex.RUnlock()
+
+		if onTx == nil {
+			return
+		}
+		meta, err := txmetadata.TransactionMetadataFromBytes(metadataBytes)
+		if err != nil {
+			return
+		}
+		onTx(from, txBytes, meta, txid)
+	})
+}
+
+func (sp *simPeers) GossipTxBytesToPeers(txBytes []byte, metadata *txmetadata.TransactionMetadata, txid base.TransactionID, except ...peer.ID) {
+	for i := range sp.all {
+		if i == sp.self || (len(except) > 0 && nodePeerID(i) == except[0]) {
+			continue
+		}
+		sp.sendTx(i, txBytes, metadata, txid)
+	}
+}
+
+func (sp *simPeers) SendTxBytesWithMetadataToPeer(id peer.ID, txBytes []byte, metadata *txmetadata.TransactionMetadata, txid base.TransactionID) bool {
+	to, ok := sp.nodeIndex(id)
+	if !ok {
+		return false
+	}
+	return sp.sendTx(to, txBytes, metadata, txid)
+}
+
+// choosePullTargets chooses random connected peers with the generator seeded from the seed of the simulation
+func (sp *simPeers) choosePullTargets(n int) []int {
+	candidates := make([]int, 0, len(sp.all))
+	for i := range sp.all {
+		if sp.net.Connected(sp.self, i) {
+			candidates = append(candidates, i)
+		}
+	}
+	sp.mutex.Lock()
+	sp.rnd.Shuffle(len(candidates), func(i, j int) {
+		candidates[i], candidates[j] = candidates[j], candidates[i]
+	})
+	sp.mutex.Unlock()
+
+	return candidates[:min(n, len(candidates))]
+}
+
+func (sp *simPeers) pullFromNPeers(nPeers int, batch *peering.PullBatch) int {
+	from := nodePeerID(sp.self)
+	targets := sp.choosePullTargets(nPeers)
+	for _, to := range targets {
+		target := sp.all[to]
+		sp.net.send(sp.self, to, func() {
+			target.lastMessage.Store(time.Now().UnixNano())
+			target.mutex.RLock()
+			onPull, onBatch := target.onPull, target.onBatch
+			target.mutex.RUnlock()
+
+			switch {
+			case onBatch != nil:
+				onBatch(from, batch)
+			case onPull != nil:
+				for _, txid := range batch.TxIDs {
+					onPull(from, txid)
+				}
+			}
+		})
+	}
+	return len(targets)
+}
@@ -0,0 +1,281 @@
+package simulator
+
+import (
+	"context"
+	"crypto/ed25519"
+	"fmt"
+	"time"
+
+	"github.com/lunfardo314/proxima/core/txmetadata"
+	"github.com/lunfardo314/proxima/core/workflow"
+	"github.com/lunfardo314/proxima/global"
+	"github.com/lunfardo314/proxima/ledger"
+	"github.com/lunfardo314/proxima/ledger/base"
+	"github.com/lunfardo314/proxima/ledger/multistate"
+	"github.com/lunfardo314/proxima/ledger/transaction"
+	"github.com/lunfardo314/proxima/ledger/txbuilder"
+	"github.com/lunfardo314/proxima/sequencer"
+	"github.com/lunfardo314/proxima/txstore"
+	"github.com/lunfardo314/proxima/util"
+	"github.com/lunfardo314/proxima/util/testutil"
+	"github.com/lunfardo314/unitrie/common"
+)
+
+// Simulation runs N full nodes in one process. Nodes are connected by the in-memory Network, delivery of
+// messages is timed by the simulation Clock. Delays, losses and choice of pull targets are drawn from random
+// generators seeded from Config.Seed, so the same sequence of network conditions is drawn in every run.
+// The seed does not reproduce the run: ledger time and timers of nodes are taken from the local clock and
+// goroutines are scheduled by the Go runtime. Transaction timestamps and the exact DAG differ from run to run,
+// so scenarios must assert properties, not particular transactions
+//
+// The ledger identity must be initialized before the simulation, usually with
+// ledger.InitWithTestingLedgerIDData(...)
+
+type (
+	Config struct {
+		// Seed of all random generators of the simulation
+		Seed     int64
+		NumNodes int
+		// NumSequencers first nodes run sequencers. Node 0 runs the bootstrap sequencer on the genesis chain,
+		// others run sequencers on chains created by the genesis distribution
+		NumSequencers int
+		// SequencerBalance is the initial balance of each non-bootstrap sequencer chain.
+		// By default, it is 1/(2*NumSequencers) of the initial supply
+		SequencerBalance  uint64
+		GenesisPrivateKey ed25519.PrivateKey
+		// Link is the default model of links between nodes
+		Link LinkConfig
+		// SequencerOptions are added to the default options of each sequencer
+		SequencerOptions []sequencer.ConfigOption
+		// WorkflowOptions are options of workflows of all nodes
+		WorkflowOptions []workflow.ConfigOption
+	}
+
+	Simulation struct {
+		cfg              Config
+		clock            *Clock
+		net              *Network
+		nodes            []*Node
+		distributionTxID base.TransactionID
+		ctx              context.Context
+		stop             context.CancelFunc
+	}
+)
+
+// sequencerKeyBase is the index of testing private key of the sequencer of node 1
+const sequencerKeyBase = 1000
+
+func New(cfg Config) (*Simulation, error) {
+	if cfg.NumNodes <= 0 {
+		return nil, fmt.Errorf("simulator: number of nodes must be positive")
+	}
+	if cfg.NumSequencers > cfg.NumNodes {
+		return nil, fmt.Errorf("simulator: number of sequencers cannot exceed number of nodes")
+	}
+	if cfg.SequencerBalance == 0 && cfg.NumSequencers > 0 {
+		cfg.SequencerBalance = ledger.L().ID.InitialSupply / uint64(2*cfg.NumSequencers)
+	}
+	ret := &Simulation{
+		cfg:   cfg,
+		clock: newClock(),
+		nodes: make([]*Node, cfg.NumNodes),
+	}
+	ret.ctx, ret.stop = context.WithCancel(context.Background())
+	ret.net = newNetwork(cfg.NumNodes, cfg.Seed, cfg.Link, ret.clock)
+
+	distrib, seqKeys := ret.genesisDistribution()
+	allPeers := make([]*simPeers, cfg.NumNodes)
+	for i := range ret.nodes {
+		n, err := ret.newNode(i, distrib)
+		if err != nil {
+			return nil, err
+		}
+		ret.nodes[i] = n
+		allPeers[i] = n.peers
+	}
+	for _, n := range ret.nodes {
+		n.peers.all = allPeers
+	}
+	if err := ret.assignSequencerChains(seqKeys); err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
+
+func (s *Simulation) genesisDistribution() ([]ledger.LockBalance, []ed25519.PrivateKey) {
+	distrib := make([]ledger.LockBalance, 0)
+	keys := make([]ed25519.PrivateKey, 0)
+	for i := 1; i < s.cfg.NumSequencers; i++ {
+		key := testutil.GetTestingPrivateKey(sequencerKeyBase + i)
+		keys = append(keys, key)
+		distrib = append(distrib, ledger.LockBalance{
+			Lock:        ledger.AddressED25519FromPrivateKey(key),
+			Balance:     s.cfg.SequencerBalance,
+			ChainOrigin: true,
+		})
+	}
+	return distrib, keys
+}
+
+// newNode creates node with genesis state and distribution. Distribution transaction is the same in all nodes
+func (s *Simulation) newNode(idx int, distrib []ledger.LockBalance) (*Node, error) {
+	ret := &Node{
+		Global:       global.NewDefault(false),
+		idx:          idx,
+		stateStore:   common.NewInMemoryKVStore(),
+		txBytesStore: txstore.NewSimpleTxBytesStore(common.NewInMemoryKVStore()),
+		peers:        newSimPeers(s.net, idx, s.cfg.Seed),
+	}
+	ret.Global.SugaredLogger = ret.Global.SugaredLogger.Named(fmt.Sprintf("node%d", idx))
+
+	multistate.InitStateStoreWithGlobalLedgerIdentity(ret.stateStore)
+	txBytes, txid, err := txbuilder.DistributeInitialSupplyExt(ret.stateStore, s.cfg.GenesisPrivateKey, distrib)
+	if err != nil {
+		return nil, err
+	}
+	if _, err = ret.txBytesStore.PersistTxBytesWithMetadata(txBytes, nil); err != nil {
+		return nil, err
+	}
+	s.distributionTxID = txid
+
+	ret.workflow = workflow.Start(ret, ret.peers, s.cfg.WorkflowOptions...)
+	if err = ret.workflow.EnsureLatestBranches(); err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
+
+// assignSequencerChains finds chain IDs of sequencers in the distribution transaction
+func (s *Simulation) assignSequencerChains(seqKeys []ed25519.PrivateKey) error {
+	if s.cfg.NumSequencers == 0 {
+		return nil
+	}
+	bootstrap := s.nodes[0]
+	stateID, _, err := multistate.ScanGenesisState(bootstrap.stateStore)
+	if err != nil {
+		return err
+	}
+	bootstrap.seqChainID = util.Ref(stateID.OriginChainID())
+	bootstrap.seqKey = s.cfg.GenesisPrivateKey
+
+	txBytes := bootstrap.txBytesStore.GetTxBytesWithMetadata(&s.distributionTxID)
+	_, txBytes, err = txmetadata.SplitTxBytesWithMetadata(txBytes)
+	if err != nil {
+		return err
+	}
+	tx, err := transaction.FromBytes(txBytes, transaction.MainTxValidationOptions...)
+	if err != nil {
+		return err
+	}
+	for i, key := range seqKeys {
+		addr := ledger.AddressED25519FromPrivateKey(key)
+		n := s.nodes[i+1]
+		tx.ForEachProducedOutput(func(_ byte, o *ledger.Output, oid base.OutputID) bool {
+			if cc, idx := o.ChainConstraint(); idx != 0xff && cc.IsOrigin() && ledger.EqualConstraints(o.Lock(), addr) {
+				n.seqChainID = util.Ref(base.MakeOriginChainID(oid))
+				n.seqKey = key
+				return false
+			}
+			return true
+		})
+		if n.seqChainID == nil {
+			return fmt.Errorf("simulator: can't find chain origin of the sequencer of node %d", i+1)
+		}
+	}
+	for _, n := range s.nodes[:s.cfg.NumSequencers] {
+		n.seqOptions = s.cfg.SequencerOptions
+	}
+	return nil
+}
+
+// Start starts delivery of messages and sequencers
+func (s *Simulation) Start() error {
+	go s.net.run(s.ctx)
+	for _, n := range s.nodes[:s.cfg.NumSequencers] {
+		if err := n.startSequencer(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Stop stops all nodes
+func (s *Simulation) Stop(timeout ...time.Duration) {
+	for _, n := range s.nodes {
+		n.stopSequencer()
+		n.Stop()
+	}
+	for _, n := range s.nodes {
+		n.WaitAllWorkProcessesStop(timeout...)
+	}
+	s.stop()
+}
+
+func (s *Simulation) Clock() *Clock {
+	return s.clock
+}
+
+func (s *Simulation) Network() *Network {
+	return s.net
+}
+
+func (s *Simulation) Node(idx int) *Node {
+	return s.nodes[idx]
+}
+
+func (s *Simulation) Nodes() []*Node {
+	return s.nodes
+}
+
+func (s *Simulation) DistributionTxID() base.TransactionID {
+	return s.distributionTxID
+}
+
+// CrashSequencer stops the sequencer of the node without any cleanup
+func (s *Simulation) CrashSequencer(idx int) {
+	s.nodes[idx].stopSequencer()
+}
+
+// RestartSequencer starts new instance of the crashed sequencer from the current state of the node
+func (s *Simulation) RestartSequencer(idx int) error {
+	return s.nodes[idx].startSequencer()
+}
+
+// CurrentSlot is the current slot of the ledger time
+func (s *Simulation) CurrentSlot() base.Slot {
+	return ledger.TimeNow().Slot
+}
+
+// WaitSlots waits until the ledger time reaches the beginning of the n-th slot from the current one.
+// Returns false if the simulation was stopped
+func (s *Simulation) WaitSlots(n int) bool {
+	target := ledger.ClockTime(base.NewLedgerTime(s.CurrentSlot()+base.Slot(n), 0))
+	select {
+	case <-s.ctx.Done():
+		return false
+	case <-time.After(time.Until(target)):
+		return true
+	}
+}
+
+// Converged returns true if LRBs of all connected nodes exist and are in the same lineage, i.e. each later LRB
+// contains the earlier one
+func (s *Simulation) Converged() bool {
+	lrbs := make([]*multistate.BranchData, len(s.nodes))
+	for i, n := range s.nodes {
+		if lrbs[i] = n.LatestReliableBranch(); lrbs[i] == nil {
+			return false
+		}
+	}
+	for i := range s.nodes {
+		for j := range s.nodes {
+			if lrbs[i].Stem.ID.Slot() < lrbs[j].Stem.ID.Slot() || !s.net.Connected(i, j) {
+				continue
+			}
+			if !s.nodes[i].KnowsBranch(lrbs[j].TxID()) {
+				return false
+			}
+		}
+	}
+	return true
+}
@@ -0,0 +1,159 @@
+package simulator
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/lunfardo314/proxima/ledger"
+	"github.com/lunfardo314/proxima/sequencer"
+	"github.com/stretchr/testify/require"
+)
+
+// runLink sends n messages from node 0 to node 1 and returns indices of delivered messages in the order of delivery
+func runLink(t *testing.T, seed int64, cfg LinkConfig, n int) ([]int, NetworkStats) {
+	net := newNetwork(2, seed, cfg, newClock())
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go net.run(ctx)
+
+	var mutex sync.Mutex
+	delivered := make([]int, 0)
+	for i := 0; i < n; i++ {
+		idx := i
+		net.send(0, 1, func() {
+			mutex.Lock()
+			defer mutex.Unlock()
+			delivered = append(delivered, idx)
+		})
+	}
+	require.Eventually(t, func() bool {
+		st := net.Stats()
+		return st.Delivered+st.Lost == n
+	}, 5*time.Second, 10*time.Millisecond)
+
+	mutex.Lock()
+	defer mutex.Unlock()
+	return delivered, net.Stats()
+}
+
+func TestNetwork(t *testing.T) {
+	t.Run("deterministic", func(t *testing.T) {
+		cfg := LinkConfig{Latency: 5 * time.Millisecond, Jitter: 20 * time.Millisecond, Loss: 0.3}
+		const n = 200
+		delivered1, stats1 := runLink(t, 42, cfg, n)
+		delivered2, stats2 := runLink(t, 42, cfg, n)
+		require.EqualValues(t, stats1, stats2)
+		t.Logf("stats: %+v", stats1)
+		require.True(t, stats1.Lost > 0 && stats1.Lost < n)
+		// lost messages are the same. Order of delivery may differ only for messages with very close delivery time
+		require.ElementsMatch(t, delivered1, delivered2)
+
+		delivered3, _ := runLink(t, 43, cfg, n)
+		require.NotEqualValues(t, delivered1, delivered3)
+	})
+	t.Run("no loss keeps order without jitter", func(t *testing.T) {
+		delivered, stats := runLink(t, 1, LinkConfig{Latency: time.Millisecond}, 100)
+		require.EqualValues(t, 100, stats.Delivered)
+		for i := range delivered {
+			require.EqualValues(t, i, delivered[i])
+		}
+	})
+	t.Run("partition", func(t *testing.T) {
+		net := newNetwork(4, 1, LinkConfig{}, newClock())
+		require.True(t, net.Connected(0, 3))
+		net.Partition([]int{0, 1}, []int{2})
+		require.True(t, net.Connected(0, 1))
+		require.False(t, net.Connected(1, 2))
+		require.False(t, net.Connected(0, 3))
+		require.False(t, net.Connected(2, 3))
+		require.False(t, net.send(0, 2, func() {}))
+		require.EqualValues(t, 1, net.Stats().Blocked)
+		net.Heal()
+		require.True(t, net.Connected(1, 2))
+
+		net.Disconnect(3)
+		require.False(t, net.Connected(0, 3))
+		require.False(t, net.Connected(3, 0))
+		net.Connect(3)
+		require.True(t, net.Connected(3, 0))
+	})
+	t.Run("clock", func(t *testing.T) {
+		c := newClock()
+		before := c.Now()
+		c.Advance(time.Hour)
+		require.True(t, c.Now().Sub(before) >= time.Hour)
+		require.True(t, c.Until(time.Now()) <= -time.Hour+time.Minute)
+	})
+	t.Run("clock advance delivers", func(t *testing.T) {
+		c := newClock()
+		net := newNetwork(2, 1, LinkConfig{Latency: time.Hour}, c)
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		go net.run(ctx)
+
+		done := make(chan struct{})
+		net.send(0, 1, func() { close(done) })
+		c.Advance(time.Hour)
+		select {
+		case <-done:
+		case <-time.After(5 * time.Second):
+			t.Fatal("message not delivered after the clock was advanced")
+		}
+	})
+}
+
+// TestPartitionHealing splits 4 nodes with 4 sequencers into two halves for some slots, then heals the network.
+// The half with the bootstrap sequencer has the majority of the supply, so only its LRB advances during the partition.
+// Both halves must converge to one lineage after healing
+func TestPartitionHealing(t *testing.T) {
+	if testing.Short() {
+		t.Skip("long scenario")
+	}
+	genesisPrivateKey := ledger.InitWithTestingLedgerIDData(
+		ledger.WithTickDuration(8*time.Millisecond),
+		ledger.WithTransactionPace(3),
+		ledger.WithSequencerPace(3))
+
+	sim, err := New(Config{
+		Seed:              2024,
+		NumNodes:          4,
+		NumSequencers:     4,
+		GenesisPrivateKey: genesisPrivateKey,
+		Link:              LinkConfig{Latency: 20 * time.Millisecond, Jitter: 30 * time.Millisecond, Loss: 0.01},
+		SequencerOptions: []sequencer.ConfigOption{
+			sequencer.WithMaxInputs(50, 30),
+			sequencer.WithPace(5),
+		},
+	})
+	require.NoError(t, err)
+	require.NoError(t, sim.Start())
+	defer sim.Stop(10 * time.Second)
+
+	require.True(t, sim.WaitSlots(5))
+	require.True(t, sim.Converged())
+
+	sim.Network().Partition([]int{0, 1}, []int{2, 3})
+	require.True(t, sim.WaitSlots(5))
+
+	// halves diverged: the minority does not know the LRB of the majority
+	lrbMajority, lrbMinority := sim.Node(0).LatestReliableBranch(), sim.Node(2).LatestReliableBranch()
+	require.NotNil(t, lrbMajority)
+	require.NotNil(t, lrbMinority)
+	require.NotEqualValues(t, lrbMajority.TxID(), lrbMinority.TxID())
+	require.True(t, lrbMajority.Slot() > lrbMinority.Slot())
+	require.False(t, sim.Node(2).KnowsBranch(lrbMajority.TxID()))
+	require.False(t, sim.Node(3).KnowsBranch(lrbMajority.TxID()))
+
+	sim.Network().Heal()
+	require.Eventually(t, sim.Converged, 20*ledger.SlotDuration(), ledger.SlotDuration())
+	t.Logf("network stats: %+v", sim.Network().Stats())
+
+	// crash one sequencer and restart it
+	sim.CrashSequencer(3)
+	require.True(t, sim.WaitSlots(2))
+	require.NoError(t, sim.RestartSequencer(3))
+	require.True(t, sim.WaitSlots(3))
+	require.True(t, sim.Converged())
+}
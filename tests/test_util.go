@@ -11,6 +11,7 @@ import (
 	"github.com/libp2p/go-libp2p/core/peer"
 	"github.com/lunfardo314/proxima/api"
 	"github.com/lunfardo314/proxima/core/attacher"
+	"github.com/lunfardo314/proxima/core/core_modules/delegation_history"
 	"github.com/lunfardo314/proxima/core/core_modules/tippool"
 	"github.com/lunfardo314/proxima/core/memdag"
 	"github.com/lunfardo314/proxima/core/txmetadata"
@@ -54,6 +55,11 @@ func (w *workflowDummyEnvironment) PullFromNPeers(_ int, txid base.TransactionID
 	return 0
 }
 
+func (w *workflowDummyEnvironment) PullWithPastConeFromNPeers(_ int, txid base.TransactionID, _ base.Slot) int {
+	w.Log().Warnf(">>>>>> PullWithPastConeFromNPeers not implemented: %s", txid.StringShort())
+	return 0
+}
+
 func (w *workflowDummyEnvironment) EvidencePastConeSize(_ int) {}
 
 func (w *workflowDummyEnvironment) EvidenceNumberOfTxDependencies(_ int) {}
@@ -115,6 +121,18 @@ func (p *workflowDummyEnvironment) EvidenceTxValidationStats(took time.Duration,
 func (p *workflowDummyEnvironment) EvidenceBranchInflationBonus(ib uint64) {
 }
 
+func (p *workflowDummyEnvironment) ClockSkew() (time.Duration, bool) {
+	return 0, false
+}
+
+func (p *workflowDummyEnvironment) GetDelegationHistory(_ base.ChainID) (*delegation_history.History, error) {
+	return nil, delegation_history.ErrNotFound
+}
+
+func (p *workflowDummyEnvironment) GetTxRejections(_ *base.TransactionID, _ int) (uint64, []*txstore.TxRejection) {
+	return 0, nil
+}
+
 func newWorkflowDummyEnvironment(stateStore multistate.StateStore, txStore global.TxBytesStore) *workflowDummyEnvironment {
 	ret := &workflowDummyEnvironment{
 		Global:       global.NewDefault(false),
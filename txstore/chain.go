@@ -0,0 +1,41 @@
+package txstore
+
+import (
+	"fmt"
+
+	"github.com/lunfardo314/proxima/global"
+	"github.com/lunfardo314/proxima/ledger"
+	"github.com/lunfardo314/proxima/ledger/base"
+	"github.com/lunfardo314/proxima/ledger/transaction"
+)
+
+// IterateChainBackwards starts from the chain output with the given ID and walks the chain back to its origin
+// by following predecessor inputs of the chain constraint. Transactions are loaded from the transaction store.
+// Iteration stops when the origin is reached or when fun returns false.
+// Returns error wrapping ErrTransactionNotFound if the chain can't be followed further because the
+// transaction is not in the store (for example, it is before the snapshot)
+func IterateChainBackwards(store global.TxBytesGet, oid base.OutputID, fun func(tx *transaction.Transaction, o *ledger.OutputWithID, cc *ledger.ChainConstraint) bool) error {
+	for {
+		tx, _, err := LoadAndParseTransaction(store, oid.TransactionID())
+		if err != nil {
+			return fmt.Errorf("IterateChainBackwards: can't load transaction of %s: %w", oid.StringShort(), err)
+		}
+		o, err := tx.ProducedOutputAt(oid.Index())
+		if err != nil {
+			return fmt.Errorf("IterateChainBackwards: %w", err)
+		}
+		cc, idx := o.ChainConstraint()
+		if idx == 0xff {
+			return fmt.Errorf("IterateChainBackwards: %s is not a chain output", oid.StringShort())
+		}
+		if !fun(tx, &ledger.OutputWithID{ID: oid, Output: o}, cc) {
+			return nil
+		}
+		if cc.IsOrigin() {
+			return nil
+		}
+		if oid, err = tx.InputAt(cc.PredecessorInputIndex); err != nil {
+			return fmt.Errorf("IterateChainBackwards: %w", err)
+		}
+	}
+}
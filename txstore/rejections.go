@@ -0,0 +1,232 @@
+package txstore
+
+import (
+	"encoding/binary"
+	"fmt"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/lunfardo314/proxima/core/txmetadata"
+	"github.com/lunfardo314/proxima/ledger/base"
+	"github.com/lunfardo314/unitrie/common"
+)
+
+// TxRejections is a bounded ring of records about transactions rejected by the node.
+// It is persisted in the KV store together with transaction bytes. Keys of the ring do not collide
+// with transaction IDs because of different length.
+// Key of the ring head is the prefix. It contains the total number of records ever put and the size of the ring.
+// Key of the record is the prefix + 4 bytes of the position in the ring.
+// When the ring is full, the oldest record is overwritten. If the size of the ring changes, positions
+// of records do not match the new size, so the ring is reset.
+// Records are buffered in memory and written to the store by Flush, which is called periodically.
+// Buffer is flushed in Put too when it is full
+
+type (
+	TxRejections struct {
+		mutex sync.Mutex
+		s     common.KVStore
+		size  int
+		count uint64
+		// records not written to the store yet, by position in the ring
+		pending map[uint32][]byte
+	}
+
+	TxRejection struct {
+		TxID base.TransactionID
+		// reason of the rejection. Truncated to maxRejectionReasonLength
+		Reason string
+		// slot of the ledger time when the transaction was rejected
+		Slot base.Slot
+		// where the transaction came from
+		Source txmetadata.SourceType
+		Time   time.Time
+	}
+)
+
+const (
+	DefaultTxRejectionsRingSize = 10_000
+	maxRejectionReasonLength    = 1024
+	// txid + slot + unix nano + source
+	rejectionFixedLength = base.TransactionIDLength + 4 + 8 + 1
+	// count + size
+	rejectionsHeaderLength = 8 + 4
+	// Put flushes the buffer when it reaches the size
+	maxPendingRejections = 1000
+)
+
+var rejectionsPrefix = []byte{0xff, 'r', 'e', 'j'}
+
+// NewTxRejections opens the ring in the store. Ring size <= 0 means default
+func NewTxRejections(store common.KVStore, size int) *TxRejections {
+	if size <= 0 {
+		size = DefaultTxRejectionsRingSize
+	}
+	ret := &TxRejections{
+		s:       store,
+		size:    size,
+		pending: make(map[uint32][]byte),
+	}
+	data := store.Get(rejectionsPrefix)
+	switch {
+	case len(data) == rejectionsHeaderLength && int(binary.BigEndian.Uint32(data[8:])) == size:
+		ret.count = binary.BigEndian.Uint64(data)
+	case len(data) > 0:
+		ret.reset()
+	}
+	return ret
+}
+
+func rejectionKey(pos uint32) []byte {
+	ret := make([]byte, len(rejectionsPrefix)+4)
+	copy(ret, rejectionsPrefix)
+	binary.BigEndian.PutUint32(ret[len(rejectionsPrefix):], pos)
+	return ret
+}
+
+// reset deletes all records of the ring from the store
+func (r *TxRejections) reset() {
+	keys := make([][]byte, 0)
+	r.s.Iterator(rejectionsPrefix).IterateKeys(func(k []byte) bool {
+		keys = append(keys, slices.Clone(k))
+		return true
+	})
+	batch := r.s.BatchedWriter()
+	for _, k := range keys {
+		batch.Set(k, nil)
+	}
+	_ = batch.Commit()
+	r.count = 0
+}
+
+// Put adds the record to the ring, overwriting the oldest one if the ring is full
+func (r *TxRejections) Put(rec *TxRejection) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.pending[uint32(r.count%uint64(r.size))] = rec.Bytes()
+	r.count++
+	if len(r.pending) < maxPendingRejections {
+		return nil
+	}
+	return r._flush()
+}
+
+// Flush writes buffered records to the store
+func (r *TxRejections) Flush() error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	return r._flush()
+}
+
+func (r *TxRejections) _flush() error {
+	if len(r.pending) == 0 {
+		return nil
+	}
+	var header [rejectionsHeaderLength]byte
+	binary.BigEndian.PutUint64(header[:8], r.count)
+	binary.BigEndian.PutUint32(header[8:], uint32(r.size))
+
+	batch := r.s.BatchedWriter()
+	for pos, data := range r.pending {
+		batch.Set(rejectionKey(pos), data)
+	}
+	batch.Set(rejectionsPrefix, header[:])
+	if err := batch.Commit(); err != nil {
+		return err
+	}
+	r.pending = make(map[uint32][]byte)
+	return nil
+}
+
+// Count total number of records ever put into the ring
+func (r *TxRejections) Count() uint64 {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	return r.count
+}
+
+func (r *TxRejections) _record(pos uint32) []byte {
+	if data, ok := r.pending[pos]; ok {
+		return data
+	}
+	return r.s.Get(rejectionKey(pos))
+}
+
+// Latest returns at most maxRecords latest records, the latest first
+func (r *TxRejections) Latest(maxRecords int) []*TxRejection {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	ret := make([]*TxRejection, 0)
+	for i := r.count; i > 0 && len(ret) < maxRecords && r.count-i < uint64(r.size); i-- {
+		rec, err := TxRejectionFromBytes(r._record(uint32((i - 1) % uint64(r.size))))
+		if err != nil {
+			continue
+		}
+		ret = append(ret, rec)
+	}
+	return ret
+}
+
+// Get returns all records for the transaction ID in the ring, the latest first
+func (r *TxRejections) Get(txid base.TransactionID) []*TxRejection {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	ret := make([]*TxRejection, 0)
+	add := func(data []byte) {
+		if len(data) < base.TransactionIDLength || txid != base.TransactionID(data[:base.TransactionIDLength]) {
+			return
+		}
+		if rec, err := TxRejectionFromBytes(data); err == nil {
+			ret = append(ret, rec)
+		}
+	}
+	r.s.Iterator(rejectionsPrefix).Iterate(func(k, v []byte) bool {
+		if len(k) != len(rejectionsPrefix)+4 {
+			return true
+		}
+		if _, overwritten := r.pending[binary.BigEndian.Uint32(k[len(rejectionsPrefix):])]; !overwritten {
+			add(v)
+		}
+		return true
+	})
+	for _, data := range r.pending {
+		add(data)
+	}
+	sort.Slice(ret, func(i, j int) bool {
+		return ret[i].Time.After(ret[j].Time)
+	})
+	return ret
+}
+
+func (rec *TxRejection) Bytes() []byte {
+	reason := rec.Reason
+	if len(reason) > maxRejectionReasonLength {
+		reason = reason[:maxRejectionReasonLength]
+	}
+	ret := make([]byte, rejectionFixedLength, rejectionFixedLength+len(reason))
+	copy(ret, rec.TxID[:])
+	binary.BigEndian.PutUint32(ret[base.TransactionIDLength:], uint32(rec.Slot))
+	binary.BigEndian.PutUint64(ret[base.TransactionIDLength+4:], uint64(rec.Time.UnixNano()))
+	ret[base.TransactionIDLength+12] = byte(rec.Source)
+	return append(ret, reason...)
+}
+
+func TxRejectionFromBytes(data []byte) (*TxRejection, error) {
+	if len(data) < rejectionFixedLength {
+		return nil, fmt.Errorf("TxRejectionFromBytes: wrong data length %d", len(data))
+	}
+	ret := &TxRejection{
+		Slot:   base.Slot(binary.BigEndian.Uint32(data[base.TransactionIDLength:])),
+		Time:   time.Unix(0, int64(binary.BigEndian.Uint64(data[base.TransactionIDLength+4:]))),
+		Source: txmetadata.SourceType(data[base.TransactionIDLength+12]),
+		Reason: string(data[rejectionFixedLength:]),
+	}
+	copy(ret.TxID[:], data[:base.TransactionIDLength])
+	return ret, nil
+}
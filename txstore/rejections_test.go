@@ -0,0 +1,124 @@
+package txstore
+
+import (
+	"testing"
+	"time"
+
+	"github.com/lunfardo314/proxima/core/txmetadata"
+	"github.com/lunfardo314/proxima/ledger/base"
+	"github.com/lunfardo314/unitrie/common"
+	"github.com/stretchr/testify/require"
+)
+
+func testRejection(i int, txid ...base.TransactionID) *TxRejection {
+	ret := &TxRejection{
+		TxID:   base.RandomTransactionID(true, 1),
+		Reason: "reason",
+		Slot:   base.Slot(i),
+		Source: txmetadata.SourceTypePeer,
+		Time:   time.Unix(1_700_000_000+int64(i), 0),
+	}
+	if len(txid) > 0 {
+		ret.TxID = txid[0]
+	}
+	return ret
+}
+
+func TestTxRejectionBytes(t *testing.T) {
+	rec := testRejection(1337)
+	back, err := TxRejectionFromBytes(rec.Bytes())
+	require.NoError(t, err)
+	require.EqualValues(t, rec.TxID, back.TxID)
+	require.EqualValues(t, rec.Reason, back.Reason)
+	require.EqualValues(t, rec.Slot, back.Slot)
+	require.EqualValues(t, rec.Source, back.Source)
+	require.True(t, rec.Time.Equal(back.Time))
+
+	rec.Reason = string(make([]byte, 2*maxRejectionReasonLength))
+	back, err = TxRejectionFromBytes(rec.Bytes())
+	require.NoError(t, err)
+	require.EqualValues(t, maxRejectionReasonLength, len(back.Reason))
+
+	_, err = TxRejectionFromBytes(rec.Bytes()[:rejectionFixedLength-1])
+	require.Error(t, err)
+}
+
+func TestTxRejections(t *testing.T) {
+	t.Run("wraparound", func(t *testing.T) {
+		r := NewTxRejections(common.NewInMemoryKVStore(), 5)
+		for i := 0; i < 12; i++ {
+			require.NoError(t, r.Put(testRejection(i)))
+		}
+		require.EqualValues(t, 12, r.Count())
+		latest := r.Latest(100)
+		require.EqualValues(t, 5, len(latest))
+		for i, rec := range latest {
+			require.EqualValues(t, 11-i, rec.Slot)
+		}
+		require.EqualValues(t, 2, len(r.Latest(2)))
+	})
+	t.Run("get", func(t *testing.T) {
+		r := NewTxRejections(common.NewInMemoryKVStore(), 5)
+		txid := base.RandomTransactionID(true, 1)
+		require.NoError(t, r.Put(testRejection(0, txid)))
+		require.NoError(t, r.Put(testRejection(1)))
+		require.NoError(t, r.Flush())
+		require.NoError(t, r.Put(testRejection(2, txid)))
+
+		// flushed and pending records, the latest first
+		recs := r.Get(txid)
+		require.EqualValues(t, 2, len(recs))
+		require.EqualValues(t, 2, recs[0].Slot)
+		require.EqualValues(t, 0, recs[1].Slot)
+
+		// the first record is overwritten in the buffer
+		for i := 3; i < 6; i++ {
+			require.NoError(t, r.Put(testRejection(i)))
+		}
+		recs = r.Get(txid)
+		require.EqualValues(t, 1, len(recs))
+		require.EqualValues(t, 2, recs[0].Slot)
+		require.EqualValues(t, 0, len(r.Get(base.RandomTransactionID(true, 1))))
+	})
+	t.Run("persist", func(t *testing.T) {
+		store := common.NewInMemoryKVStore()
+		r := NewTxRejections(store, 5)
+		for i := 0; i < 7; i++ {
+			require.NoError(t, r.Put(testRejection(i)))
+		}
+		// not flushed yet
+		require.EqualValues(t, 0, NewTxRejections(store, 5).Count())
+		require.NoError(t, r.Flush())
+
+		r = NewTxRejections(store, 5)
+		require.EqualValues(t, 7, r.Count())
+		latest := r.Latest(100)
+		require.EqualValues(t, 5, len(latest))
+		require.EqualValues(t, 6, latest[0].Slot)
+		require.EqualValues(t, 2, latest[4].Slot)
+	})
+	t.Run("buffer is flushed when full", func(t *testing.T) {
+		store := common.NewInMemoryKVStore()
+		r := NewTxRejections(store, 2*maxPendingRejections)
+		for i := 0; i < maxPendingRejections; i++ {
+			require.NoError(t, r.Put(testRejection(i)))
+		}
+		require.EqualValues(t, maxPendingRejections, NewTxRejections(store, 2*maxPendingRejections).Count())
+	})
+	t.Run("size change resets", func(t *testing.T) {
+		store := common.NewInMemoryKVStore()
+		r := NewTxRejections(store, 5)
+		txid := base.RandomTransactionID(true, 1)
+		for i := 0; i < 7; i++ {
+			require.NoError(t, r.Put(testRejection(i, txid)))
+		}
+		require.NoError(t, r.Flush())
+
+		r = NewTxRejections(store, 3)
+		require.EqualValues(t, 0, r.Count())
+		require.EqualValues(t, 0, len(r.Latest(100)))
+		require.EqualValues(t, 0, len(r.Get(txid)))
+		require.NoError(t, r.Put(testRejection(7, txid)))
+		require.EqualValues(t, 1, len(r.Get(txid)))
+	})
+}
@@ -143,10 +143,12 @@ func (s DummyTxBytesStore) HasTxBytes(_ *base.TransactionID) bool {
 	return false
 }
 
+var ErrTransactionNotFound = errors.New("transaction not found")
+
 func LoadAndParseTransaction(store global.TxBytesGet, txid base.TransactionID) (*transaction.Transaction, *txmetadata.TransactionMetadata, error) {
 	txBytesWithMetadata := store.GetTxBytesWithMetadata(&txid)
 	if len(txBytesWithMetadata) == 0 {
-		return nil, nil, errors.New("transaction not found")
+		return nil, nil, ErrTransactionNotFound
 	}
 	txBytes, metadata, err := txmetadata.ParseTxMetadata(txBytesWithMetadata)
 	if err != nil {